@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"github.com/perbu/ostresser/stresser"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -19,48 +21,286 @@ var (
 	// Configuration
 	configPath = flag.String("config", "", "Path to YAML config file (optional, overrides env vars)")
 
+	// Storage backend
+	backend = flag.String("backend", stresser.BackendS3, "Storage backend: 's3' (any S3-compatible endpoint) or 'gcs' (Google Cloud Storage)")
+
+	// Endpoints
+	endpoints      = flag.String("endpoints", "", "Spread operations over these comma-separated endpoints (gateway nodes) instead of the configured one, each 'url' or 'url=weight'")
+	endpointSelect = flag.String("endpoint-select", "", "With -endpoints: 'round-robin' or 'random', both honoring the weights (default: round-robin)")
+
+	// Buckets
+	buckets      = flag.String("buckets", "", "Spread operations over these comma-separated buckets instead of the configured one; ranges like 'bench-{0..15}' are expanded")
+	bucketSelect = flag.String("bucket-select", "", "With -buckets: 'round-robin', 'random' or 'hash' of the key, so a key always goes to the same bucket (default: round-robin)")
+
 	// Test Parameters
 	duration    = flag.String("d", "1m", "Duration of the test (e.g., 30s, 5m, 1h)")
+	warmup      = flag.String("warmup", "", "Run at full load this long before the measured duration and exclude those results from stats (e.g. 30s)")
+	warmupRes   = flag.Bool("warmup-results", false, "Also write warm-up results to the detailed output, flagged in a Warmup column")
 	concurrency = flag.Int("c", 10, "Number of concurrent workers")
 	randomize   = flag.Bool("r", false, "Randomize access to keys in the manifest for READ ops (default: sequential)")
-	opType      = flag.String("op", stresser.DefaultOperationType, "Operation type: 'read', 'write', or 'mixed'")
-	putSizeKB   = flag.Int("putsize", stresser.DefaultPutSizeKB, "Size of objects to upload in KB for 'write' or 'mixed' mode")
+	keyTemplate = flag.String("key-template", "", "Template for keys generated by PUTs, e.g. 'bench/{date}/{worker}/{seq}-{rand}' (placeholders: worker, seq, rand[:N], shard:N, ts, date, hour, run)")
+	keyDist     = flag.String("distribution", "", "Key selection for reads: 'sequential', 'uniform' (same as -r), 'zipf:<s>' with s > 1, e.g. zipf:1.1 (first manifest keys are hottest), or 'weighted' by the weights of the manifest")
+	opType      = flag.String("op", stresser.DefaultOperationType, "Operation type: 'read', 'write', 'mixed', 'head', 'list', 'revalidate', 'copy', 'tagging', 'versioned', 'consistency', 'contention', 'append', 'replay' or 'scenario' (set by -scenario)")
+	putSizeKB   = flag.Int("putsize", stresser.DefaultPutSizeKB, "Size of objects to upload in KB for 'write', 'mixed', 'consistency' or 'contention' mode")
+	putSizeDist = flag.String("putsize-dist", "", "Distribution of PUT object sizes instead of -putsize: 'uniform:4K-64M', 'lognormal:<median>:<sigma>' or a weighted list like '4K:50%,1M:40%,64M:10%'")
+	dataPattern = flag.String("data-pattern", "", "Content of PUT bodies: 'random', 'zeroes', 'compressible:<ratio>' (e.g. compressible:3) or 'dedupe[:<blocks>]' built from a pool of 64 KiB blocks (default: random)")
 	fileCount   = flag.Int("files", stresser.DefaultFileCount, "Number of files to generate for 'write' mode")
 	genManifest = flag.Bool("genmf", true, "Generate manifest file with created objects in 'write' mode")
+	revalStale  = flag.Float64("revalidate-stale", 0, "Fraction (0-1) of conditional GETs in 'revalidate' mode sent with outdated validators, so they return the object instead of a 304")
+	consDelays  = flag.String("consistency-delays", "", "Delays after each PUT at which 'consistency' mode reads the object back (default: '"+stresser.DefaultConsistencyDelays+"')")
+	keyPool     = flag.Int("keypool", 0, "Number of keys all workers race for with conditional PUTs in 'contention' mode (default: 16), replace with -write-mode overwrite (default: 1000), or logs per worker in 'append' mode (default: 1)")
+	appendSize  = flag.Int("append-size", 0, "KB that 'append' mode adds to a log with every GET + PUT (default: 4)")
+	appendMax   = flag.Int("append-max", 0, "Size in KB at which 'append' mode starts a log over (default: 16384)")
+	writeMode   = flag.String("write-mode", "", "Keys of 'write' mode PUTs: 'unique' creates a new key for every PUT, 'overwrite' replaces the objects of a pool of -keypool keys for the duration of the run (default: unique)")
+	consProbe   = flag.String("consistency-probe", "get", "How 'consistency' mode reads objects back: 'get' compares the content, 'head' only the size, 'list' looks for the key in a listing of its prefix")
+	listPrefix  = flag.String("list-prefix", "", "Only list keys under this prefix in 'list' mode (default: whole bucket)")
+	listPage    = flag.Int("list-page-size", stresser.DefaultListPageSize, "Keys per ListObjectsV2 page in 'list' mode (1-1000)")
+	copyPart    = flag.Int("copy-part-size", 0, "In 'copy' mode, copy objects larger than this many MB with multipart UploadPartCopy in parts of this size (0: single CopyObject up to 5 GiB)")
+	scenario    = flag.String("scenario", "", "YAML file of weighted operation groups, each with its own key space and sizes, run concurrently with per-group stats (sets -op scenario)")
+	versionMix  = flag.String("version-mix", "", "Weights of PUTs, GETs of a version and DELETEs of a version in 'versioned' mode (default: '"+stresser.DefaultVersionMix+"')")
+	verify      = flag.Bool("verify", false, "Store a SHA-256 checksum with every PUT and verify GET bodies against it, reporting corruption separately")
+	checksum    = flag.String("checksum", "", "Additional checksum the SDK adds to PUTs and checks GET bodies against: 'crc32', 'crc32c', 'crc64nvme', 'sha1', 'sha256', or 'none' to turn off the SDK's default checksums")
+
+	// Server-side encryption
+	sse         = flag.String("sse", "", "Server-side encryption of written objects: 'none', 'sse-s3', 'sse-kms' or 'sse-c' (default: none)")
+	sseKMSKeyID = flag.String("sse-kms-key-id", "", "KMS key id for -sse sse-kms (default: the store's default key)")
+	sseCKey     = flag.String("sse-c-key", "", "Base64-encoded 256-bit customer key for -sse sse-c, also sent with GETs and HEADs")
+
+	// Compressed GETs
+	acceptEncoding = flag.String("accept-encoding", "", "Ask for compressed GET responses with these content encodings: 'gzip', 'deflate' and/or 'zstd', e.g. 'gzip,zstd'; bodies are decoded and reported as wire and decoded bytes")
+
+	// Attributes of written objects
+	metadata     = flag.String("metadata", "", "User metadata set on every PUT as 'name=value' pairs, e.g. 'owner=etl,stage=raw' (merged with YAML metadata)")
+	contentType  = flag.String("content-type", "", "Content-Type of written objects, e.g. 'application/octet-stream'")
+	cacheControl = flag.String("cache-control", "", "Cache-Control of written objects, e.g. 'max-age=3600'")
+	tags         = flag.String("tags", "", "Object tags set on every PUT as 'name=value' pairs, e.g. 'tier=hot,team=data' (merged with YAML tags)")
+
+	// Replay
+	replayFile   = flag.String("replay", "", "S3 access log, CSV (op,key,size,timestamp) or trace file to re-issue in 'replay' mode")
+	replayFormat = flag.String("replay-format", "", "Replay file format: 'csv', 's3log' or 'trace' (default: inferred from extension)")
+	replayTiming = flag.String("replay-timing", stresser.DefaultReplayTiming, "Replay timing: 'original' offsets, 'scaled' by -replay-speed, or 'asap' (ignore timing)")
+	recordTrace  = flag.String("record", "", "Record every executed operation to this trace file for later replay")
+	replaySpeed  = flag.Float64("replay-speed", stresser.DefaultReplaySpeed, "Replay time scaling factor (2.0 replays twice as fast)")
+
+	// Load generator protection
+	cpuBudget = flag.Float64("cpu-budget", 0, "Reduce active workers while client CPU exceeds this percentage of available cores (0 disables)")
+
+	// Go scheduler
+	goMaxProcs  = flag.Int("gomaxprocs", 0, "Run Go code on at most this many OS threads at once, like GOMAXPROCS (0 = the Go default, the usable cores)")
+	lockThreads = flag.Bool("lock-threads", false, "Run the operations of the workers on a pool of OS threads locked with runtime.LockOSThread, for very high worker counts")
+	threadPool  = flag.Int("thread-pool", 0, fmt.Sprintf("With -lock-threads: size of the thread pool, at most %d (0 = one thread per worker)", stresser.MaxThreadPool))
+
+	// Distributed runs
+	agents        = flag.String("agents", "", "Run the test on these agents ('ostresser agent') and combine their results, e.g. 'host1,host2:7001'")
+	agentToken    = flag.String("agent-token", "", "Token the agents were started with, required with -agents (default: $STRESSER_AGENT_TOKEN)")
+	agentInsecure = flag.Bool("agent-insecure", false, "Allow sending credentials to agents over plain HTTP instead of requiring https:// agent URLs")
+	shard         = flag.String("shard", "", "Read only this slice of the manifest as 'index/count', e.g. '3/8', so independent instances on other machines read different keys")
+
+	// Load shaping
+	ramp      = flag.String("ramp", "", "Change active workers over time as 'from..to/duration' stages, e.g. '0..100/5m' or '10..100/5m,100..100/2m' (sets -c and -d)")
+	targetP99 = flag.String("target-p99", "", "Adjust the active workers (up to -c) to find the highest throughput that keeps P99 latency within this duration, e.g. 50ms")
+
+	// Pacing
+	jitter        = flag.String("jitter", "", "Random delay before each request: 'uniform:<d>', 'exponential:<d>' or 'fixed:<d>' (e.g. uniform:50ms)")
+	thinkTime     = flag.String("think-time", "", "Pause of each worker after every operation, like an application between requests: 'fixed:<d>', 'uniform:<d>' or 'exponential:<d>'")
+	rps           = flag.Float64("rps", 0, "Maximum requests per second across all workers, for a fixed offered load (0 = unlimited)")
+	workerRPS     = flag.Float64("worker-rps", 0, "Maximum requests per second for each worker (0 = unlimited)")
+	arrivalRate   = flag.Float64("arrival-rate", 0, "Open loop: start this many operations per second whether or not earlier ones finished, with at most -c outstanding (0 = closed loop)")
+	bandwidth     = flag.Float64("bandwidth-limit", 0, "Maximum combined upload and download throughput of all connections in MiB/s (0 = unlimited)")
+	hedgeQuantile = flag.Float64("hedge-quantile", 0, "Send a second GET when the first hasn't answered within this TTFB percentile, e.g. 95 (0 disables)")
+
+	// Failure simulation
+	secondaryEndpoint = flag.String("secondary-endpoint", "", "Fail over to this endpoint when the primary keeps failing, and measure time to recover")
+	failoverThreshold = flag.Int("failover-threshold", stresser.DefaultFailoverThreshold, "Consecutive primary failures (5xx or connection errors) that trigger the failover")
+	disconnectAt      = flag.Float64("disconnect-at", 0, "Cut PUT connections after this fraction (0-1) of the body has been sent and verify no partial object is visible (0 disables)")
+
+	// Sweeps
+	sizeSweep        = flag.String("size-sweep", "", "Run the workload once per object size, e.g. '4K,64K,1M,16M,256M' (each for -d)")
+	concurrencySweep = flag.String("concurrency-sweep", "", "Run the workload once per worker count, e.g. '1,2,4,8,16,32' (each for -d)")
+
+	// Test plans
+	plan = flag.String("plan", "", "YAML file of phases run one after another, each with its own settings and outputs, e.g. load, then read, then mixed")
+
+	// Connection management
+	dnsRefresh      = flag.String("dns-refresh", "", "Re-resolve the endpoint this often and spread new connections over all its addresses (e.g. 30s)")
+	connMaxRequests = flag.Int("conn-max-requests", 0, "Close each connection after it has served this many requests (0 = unlimited)")
+	connMaxAge      = flag.String("conn-max-age", "", "Close connections once they are older than this (e.g. 1m)")
+	maxIdleConns    = flag.Int("max-idle-conns", 0, "Idle connections kept open for reuse across all hosts (0 = Go default of 100)")
+	maxIdlePerHost  = flag.Int("max-idle-conns-per-host", 0, "Idle connections kept open for reuse per host (0 = Go default of 2; connections beyond it are closed after their request)")
+	maxConnsPerHost = flag.Int("max-conns-per-host", 0, "Maximum connections per host, requests beyond it wait for a free connection (0 = unlimited)")
+	idleConnTimeout = flag.String("idle-conn-timeout", "", "Close connections that have been idle this long (default: 90s)")
+	retryMode       = flag.String("retry-mode", "", "SDK retry mode: 'standard', 'adaptive' (client-side throttling) or 'off' (default: standard)")
+	retryAttempts   = flag.Int("retry-max-attempts", 0, "Attempts per request including the first (0 = SDK default of 3, 1 disables retries)")
+	retryMaxBackoff = flag.String("retry-max-backoff", "", "Upper bound of the backoff between attempts (default: 20s)")
+	ipFamily        = flag.String("ip-family", stresser.IPFamilyBoth, "IP family for connections: 'ipv4', 'ipv6' or 'both' (happy eyeballs)")
+	httpVersion     = flag.String("http-version", stresser.HTTPVersionAuto, "HTTP protocol version: '1.1', '2' (also h2c for http:// endpoints) or 'auto' (HTTP/2 when offered over TLS)")
+	tlsCAFile       = flag.String("tls-ca-file", "", "PEM bundle of CA certificates to trust for the endpoint, in addition to the system roots")
+	tlsCertFile     = flag.String("tls-cert-file", "", "PEM client certificate for mutual TLS (requires -tls-key-file)")
+	tlsKeyFile      = flag.String("tls-key-file", "", "PEM private key of the client certificate")
+	tlsMinVer       = flag.String("tls-min-version", "", "Minimum TLS version: '1.0', '1.1', '1.2' or '1.3' (default: 1.2)")
+	signing         = flag.String("signing", stresser.SigningV4, "Request signing: 'v4', 'v2' (legacy gateways) or 'none' (anonymous requests to public buckets)")
+	noSign          = flag.Bool("no-sign", false, "Send unsigned, anonymous requests (same as -signing none)")
+	roleARN         = flag.String("role-arn", "", "Assume this IAM role with the configured credentials, refreshing it before the session expires")
+	externalID      = flag.String("external-id", "", "External ID for assuming -role-arn")
+	roleSession     = flag.String("role-session-name", "", "Session name for assuming -role-arn (default: ostresser)")
+	roleDur         = flag.String("role-duration", "", "Session duration of -role-arn, 15m to 12h (default: 1h)")
+	stsEndpoint     = flag.String("sts-endpoint", "", "STS endpoint for assuming -role-arn (default: AWS STS)")
+	headers         headerList
+	userAgent       = flag.String("user-agent", "", "Custom suffix for the User-Agent header (always starts with 'ostresser/<version> run/<run id>')")
+	runID           = flag.String("run-id", "", "Identifier for this run, sent in the User-Agent, shown in the summary and results and part of the default keys; reuse a prior run's id to write to its prefix (default: generated)")
+	presign         = flag.Bool("presign", false, "Send GETs and PUTs to presigned URLs with a plain HTTP client, bypassing the SDK request pipeline")
+	preflight       = flag.Bool("preflight", false, "Before starting workers, check the endpoint, credentials and bucket with HeadBucket, and write permission with a canary PUT/DELETE for writing workloads")
+	createBucket    = flag.Bool("create-bucket", false, "Create the bucket if it does not exist (runs the -preflight checks)")
+	noKeepAlive     = flag.Bool("disable-keepalive", false, "Disable HTTP keep-alive so every request opens a new TCP+TLS connection")
+	clientPerWorker = flag.Bool("client-per-worker", false, "Give every worker an S3 client with an HTTP transport and connection pool of its own instead of one shared client")
 
 	// Output
-	outputFile = flag.String("o", "stress_results.csv", "Output CSV file path for detailed results")
+	outputFile    = flag.String("o", "stress_results.csv", "Output file path for detailed results ('-' for stdout)")
+	summaryFile   = flag.String("summary", "", "Write the summary to this file ('-' for stdout; default: stdout, or stderr with '-o -')")
+	outputFormat  = flag.String("format", "", "Detailed results format: 'csv', 'jsonl', 'json' (JSON lines plus summary), 'parquet', 'sql' (SQLite script) or 'sqlite' (SQLite database file) (default: inferred from -o extension, else csv)")
+	summaryFormat = flag.String("summary-format", stresser.DefaultSummaryFormat, "Summary format: 'text', 'json' or 'yaml'")
+	checkpoint    = flag.String("checkpoint", "", "Stream detailed results to -o during the run, flushing them to disk this often (e.g. 10s), instead of keeping them in memory until the end")
+	sampleRate    = flag.Float64("sample-rate", 0, "Keep only this fraction (0-1) of the results for the detailed output of long runs; the summary still covers every request (0 keeps all)")
+	sampleMax     = flag.Int("sample-max", 0, "Keep at most this many results for the detailed output, a uniform random sample of the run, to bound memory in soak tests (0: no limit)")
+
+	prefixDepth  = flag.Int("prefix-depth", 0, "Report per-prefix stats grouped by this many key path segments (0 disables)")
+	outlierCount = flag.Int("outliers", stresser.DefaultOutlierCount, "Report this many of the slowest requests with full context (0 disables)")
+	trackWrites  = flag.Bool("track-writes", false, "Remember every key written to report unique objects, overwrites and net new data (memory grows with the keys written)")
+	slowThresh   = flag.String("slow-threshold", "", "Log full details (key, timings, request id) of every request that takes longer than this (e.g. 2s) as it happens")
+	slowLog      = flag.String("slow-log", "", "Also write the requests slower than -slow-threshold to this file as JSON lines")
+	nicInterface = flag.String("nic", "", "Sample this network interface (e.g. eth0) and report link utilization (Linux only)")
+	hdrLog       = flag.String("hdr-log", "", "Write the latency histograms to this file in HdrHistogram log format, for merging and plotting with HDR tools")
+	sloSpec      = flag.String("slo", "", "SLOs as name=threshold pairs, e.g. 'p99GetTtfbMs=100,errorRatePct=0.5'; the exit code is 2 if one is missed")
+	timeSeries   = flag.String("timeseries", "", "Write per-second req/s, MiB/s, errors/s and p99 latency to this file (JSON for .json paths, else CSV)")
+	htmlReport   = flag.String("html-report", "", "Write a self-contained HTML report with latency, throughput and error charts to this file")
 
 	// Logging
-	logLevel = flag.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	logLevel  = flag.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	progress  = flag.String("progress", stresser.DefaultProgressInterval, "Print interim throughput, errors and latency to stderr this often during the run (0 disables)")
+	tui       = flag.Bool("tui", false, "Show a live terminal dashboard (rates, throughput, latency, errors) on stderr during the run instead of progress lines")
+	control   = flag.String("control", "", "Accept pause, resume, concurrency and stop commands during the run on this loopback address (e.g. 127.0.0.1:7070) or unix socket path")
+	pprofAddr = flag.String("pprof-addr", "", "Serve net/http/pprof profiles of ostresser itself during the run on this loopback address (e.g. 127.0.0.1:6060)")
+	quiet     = flag.Bool("quiet", false, "Only log warnings and print one machine-parsable result line (JSON with -summary-format json) instead of the summary")
 
 	// Meta
 	showVersion = flag.Bool("version", false, "Show version information and exit")
 )
 
+// headerList collects repeated -header flags.
+type headerList []string
+
+func (h *headerList) String() string { return strings.Join(*h, ", ") }
+
+func (h *headerList) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// commands are the subcommands besides 'run', each parsing its own flags.
+var commands = map[string]func(args []string) error{
+	"generate": runGenerate,
+	"cleanup":  runCleanup,
+	"scan":     runScan,
+	"report":   runReport,
+	"agent":    runAgent,
+}
+
 func main() {
 	// Configure flag usage message
 	info, _ := debug.ReadBuildInfo()
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <manifest.txt>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [run] [options] [<manifest.txt>]   Run a stress test (the default command)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s generate [options] <manifest.txt>   Upload objects and write their keys to a manifest\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s cleanup [options] [<manifest.txt>]  Delete the objects in a manifest or under a prefix\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s scan [options] <manifest.txt>       Write the keys of existing objects to a manifest\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s report [options] <results>          Print the summary of saved detailed results\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s agent [options]                     Serve test shards for a distributed run\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Use '%s <command> -h' for the options of a command.\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Object Store Stress Tester (Version: %q, Go: %q)\n\n", info.Main.Version, info.GoVersion)
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
 		fmt.Fprintf(os.Stderr, "  <manifest.txt>   Path to the text file containing object keys (one per line).\n")
-		fmt.Fprintf(os.Stderr, "                   Required for 'read' and 'mixed' modes. Ignored for 'write' mode.\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "                   Read by 'read', 'mixed', 'head', 'revalidate', 'copy' and 'tagging' modes, and written by 'write'\n")
+		fmt.Fprintf(os.Stderr, "                   mode unless -genmf=false. Not used by 'list' and 'replay' modes.\n\n")
+		fmt.Fprintf(os.Stderr, "Options of 'run':\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nConfiguration Precedence: Flags > Environment Variables > YAML Config File\n")
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
 		fmt.Fprintf(os.Stderr, "  AWS_ENDPOINT_URL, AWS_REGION, S3_BUCKET\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_ENDPOINTS (e.g. 'http://gw1:9000=2,http://gw2:9000'), STRESSER_ENDPOINT_SELECTION ('round-robin'|'random')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_BUCKETS (e.g. 'bench-{0..15},archive'), STRESSER_BUCKET_SELECTION ('round-robin'|'random'|'hash')\n")
 		fmt.Fprintf(os.Stderr, "  AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY (or use default credential chain)\n")
-		fmt.Fprintf(os.Stderr, "  STRESSER_OPERATION_TYPE ('read'|'write'|'mixed')\n")
-		fmt.Fprintf(os.Stderr, "  STRESSER_PUT_SIZE_KB (integer)\n")
-		fmt.Fprintf(os.Stderr, "  STRESSER_INSECURE_SKIP_VERIFY ('true'|'false')\n")
-		fmt.Fprintf(os.Stderr, "  STRESSER_LOG_LEVEL ('debug'|'info'|'warn'|'error')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_BACKEND ('s3'|'gcs'), STRESSER_GCS_CREDENTIALS_FILE (or GOOGLE_APPLICATION_CREDENTIALS)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_OPERATION_TYPE ('read'|'write'|'mixed'|'replay')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_REPLAY_FILE, STRESSER_REPLAY_SPEED (float)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_REPLAY_TIMING ('original'|'scaled'|'asap'), STRESSER_TRACE_FILE\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_PUT_SIZE_KB (integer), STRESSER_VERIFY ('true'|'false'), STRESSER_CHECKSUM\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SSE ('none'|'sse-s3'|'sse-kms'|'sse-c'), STRESSER_SSE_KMS_KEY_ID, STRESSER_SSE_C_KEY (base64)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_ACCEPT_ENCODING ('gzip'|'deflate'|'gzip,deflate')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_METADATA, STRESSER_TAGS ('name=value' pairs separated by ','), STRESSER_CONTENT_TYPE, STRESSER_CACHE_CONTROL\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_PUT_SIZE_DIST (e.g. 'uniform:4K-64M', 'lognormal:1M:1.5', '4K:50%%,1M:40%%,64M:10%%')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_DATA_PATTERN ('random'|'zeroes'|'compressible:<ratio>'|'dedupe[:<blocks>]')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_KEY_DISTRIBUTION ('sequential'|'uniform'|'zipf:<s>'|'weighted'), STRESSER_KEY_TEMPLATE (e.g. 'bench/{date}/{worker}/{seq}-{rand}')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_LIST_PREFIX, STRESSER_LIST_PAGE_SIZE (integer), STRESSER_REVALIDATE_STALE (float, 0-1)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_CONSISTENCY_DELAYS (e.g. '0,100ms,1s,5s'), STRESSER_CONSISTENCY_PROBE ('get'|'head'|'list')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_KEYPOOL (integer)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_WRITE_MODE ('unique'|'overwrite')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_APPEND_SIZE_KB (integer)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_APPEND_MAX_KB (integer)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_COPY_PART_SIZE_MB (integer), STRESSER_VERSION_MIX (e.g. 'put=30,get=60,delete=10'), STRESSER_SCENARIO\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_WARMUP (duration), STRESSER_WARMUP_RESULTS ('true'|'false')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_INSECURE_SKIP_VERIFY ('true'|'false'), STRESSER_TLS_MIN_VERSION ('1.0'|'1.1'|'1.2'|'1.3')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_TLS_CA_FILE (path), STRESSER_TLS_CERT_FILE (path), STRESSER_TLS_KEY_FILE (path)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SIGNING ('v4'|'v2'|'none'), STRESSER_ROLE_ARN, STRESSER_ROLE_EXTERNAL_ID, STRESSER_ROLE_SESSION_NAME\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_ROLE_DURATION (duration), STRESSER_STS_ENDPOINT (URL)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_DISABLE_KEEPALIVE ('true'|'false'), STRESSER_IP_FAMILY ('ipv4'|'ipv6'|'both')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_HTTP_VERSION ('1.1'|'2'|'auto')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_PRESIGN ('true'|'false'), STRESSER_PREFLIGHT ('true'|'false'), STRESSER_CREATE_BUCKET ('true'|'false')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_HEADERS ('Name: value' pairs separated by ';'), STRESSER_USER_AGENT, STRESSER_RUN_ID\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_DNS_REFRESH (duration), STRESSER_CONN_MAX_REQUESTS (integer), STRESSER_CONN_MAX_AGE (duration)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_MAX_IDLE_CONNS, STRESSER_MAX_IDLE_CONNS_PER_HOST, STRESSER_MAX_CONNS_PER_HOST (integers), STRESSER_IDLE_CONN_TIMEOUT (duration)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_CLIENT_PER_WORKER ('true'|'false')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_RETRY_MODE ('standard'|'adaptive'|'off'), STRESSER_RETRY_MAX_ATTEMPTS (integer), STRESSER_RETRY_MAX_BACKOFF (duration)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_LOG_LEVEL ('debug'|'info'|'warn'|'error'), STRESSER_QUIET ('true'|'false'), STRESSER_PROGRESS (duration)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_TUI ('true'|'false'), STRESSER_CONTROL (e.g. '127.0.0.1:7070' or '/tmp/ostresser.sock')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_PPROF_ADDR (e.g. '127.0.0.1:6060')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SIZE_SWEEP (e.g. '4K,64K,1M'), STRESSER_CONCURRENCY_SWEEP (e.g. '1,4,16'), STRESSER_PLAN\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_OUTPUT_FORMAT ('csv'|'jsonl'|'json'|'sql'), STRESSER_SUMMARY_FORMAT ('text'|'json'|'yaml'), STRESSER_CHECKPOINT (duration)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SAMPLE_RATE (float, 0-1), STRESSER_SAMPLE_MAX (integer)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_PREFIX_DEPTH (integer), STRESSER_OUTLIER_COUNT (integer), STRESSER_NIC_INTERFACE, STRESSER_HDR_LOG\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SLOW_THRESHOLD (duration), STRESSER_SLOW_LOG\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_TIMESERIES, STRESSER_HTML_REPORT, STRESSER_SLO (e.g. 'p99GetTtfbMs=100,errorRatePct=0.5')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_CPU_BUDGET (percent), STRESSER_TARGET_P99 (duration), STRESSER_RAMP (e.g. '0..100/5m'), STRESSER_AGENTS (e.g. 'host1,host2')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_GOMAXPROCS (integer), STRESSER_LOCK_THREADS ('true'|'false')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SHARD (e.g. '3/8')\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_JITTER (e.g. 'uniform:50ms'), STRESSER_THINK_TIME (e.g. 'exponential:200ms'), STRESSER_RPS (float), STRESSER_WORKER_RPS (float), STRESSER_HEDGE_QUANTILE (float)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_ARRIVAL_RATE (float), STRESSER_BANDWIDTH_LIMIT (float, MiB/s)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_DISCONNECT_FRACTION (float, 0-1)\n")
+		fmt.Fprintf(os.Stderr, "  STRESSER_SECONDARY_ENDPOINT, STRESSER_FAILOVER_THRESHOLD (integer)\n")
+	}
+
+	// Other commands have their own flags; without a command the arguments are those of 'run'
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			if err := command(args[1:]); err != nil {
+				if errors.Is(err, stresser.ErrRegression) {
+					slog.Error("Candidate run regressed from the baseline", "error", err)
+					os.Exit(2)
+				}
+				slog.Error("Error running "+args[0], "error", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if args[0] == "run" {
+			args = args[1:]
+		}
 	}
 
 	// Parse command line flags
-	flag.Parse()
+	flag.Var(&headers, "header", "Extra header sent with every request, as 'Name: value' (repeatable)")
+	flag.BoolVar(noKeepAlive, "no-keepalive", false, "Alias for -disable-keepalive")
+	flag.CommandLine.Parse(args)
 
 	// Handle version flag
 	if *showVersion {
@@ -68,9 +308,9 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Check for required manifest argument (conditionally required based on opType later)
-	if flag.NArg() != 1 {
-		fmt.Fprintln(os.Stderr, "Error: Manifest file path argument is required.")
+	// The manifest argument is required by some modes only, checked by Config.Validate
+	if flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "Error: Only one manifest file path argument is accepted.")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -85,6 +325,10 @@ func main() {
 	// --- Run the application logic ---
 	// Keep main() minimal, delegate to run() function
 	if err := run(ctx, manifestPath); err != nil {
+		if errors.Is(err, stresser.ErrSLOViolation) {
+			slog.Error("Stress test completed, but missed its SLOs", "error", err)
+			os.Exit(2)
+		}
 		slog.Error("Error running stress test", "error", err)
 		os.Exit(1)
 	}
@@ -102,24 +346,67 @@ func run(ctx context.Context, manifestPath string) error {
 
 	// 2. Apply Flag overrides to Config
 	cfg.ApplyFlags(*duration, *concurrency, *randomize, manifestPath, *outputFile, *opType, *putSizeKB, *fileCount, *genManifest, *logLevel)
+	applyExplicitFlags(cfg)
+	return execute(ctx, cfg, flag.Usage)
+}
 
+// execute validates the configuration, runs the test and writes its outputs. usage is shown
+// when the configuration is invalid.
+func execute(ctx context.Context, cfg *stresser.Config, usage func()) error {
 	// 3. Configure Logger based on Config
+	if cfg.Quiet && (cfg.LogLevel == "debug" || cfg.LogLevel == "info") {
+		cfg.LogLevel = "warn"
+	}
 	setupLogger(cfg.LogLevel)
 
+	// One run id for the whole invocation: the phases of a plan and the steps of a sweep share it
+	if cfg.RunID == "" {
+		cfg.RunID = stresser.NewRunID()
+	}
+
 	// 4. Validate Final Configuration
 	if err := cfg.Validate(); err != nil {
 		// Provide usage context if validation fails
-		flag.Usage()
+		usage()
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	// Sweeps run the workload repeatedly and report one row per step instead of detailed results
+	if cfg.SizeSweep != "" {
+		slog.Info("Starting object size sweep", "sizes", cfg.SizeSweep, "stepDuration", cfg.Duration)
+		points, err := stresser.RunSizeSweep(ctx, cfg)
+		return reportSweep(cfg, "SizeKB", points, err)
+	}
+	if cfg.ConcurrencySweep != "" {
+		slog.Info("Starting concurrency sweep", "levels", cfg.ConcurrencySweep, "stepDuration", cfg.Duration)
+		points, err := stresser.RunConcurrencySweep(ctx, cfg)
+		return reportSweep(cfg, "Concurrency", points, err)
+	}
+
+	// Test plans run and report every phase as a run of its own
+	if len(cfg.Phases) > 0 {
+		slog.Info("Starting test plan", "phases", len(cfg.Phases))
+		phases, err := stresser.RunPlan(ctx, cfg, runAndReport)
+		return reportPlan(cfg, phases, err)
+	}
+	_, err := runAndReport(ctx, cfg)
+	return err
+}
+
+// runAndReport runs the stress test of a validated configuration and writes its outputs.
+// It returns the statistics of the run, also when it missed its SLOs.
+func runAndReport(ctx context.Context, cfg *stresser.Config) (*stresser.Stats, error) {
 	// 5. Execute the Stress Test
 	slog.Info("Starting stress test run...",
 		"duration", cfg.Duration,
 		"concurrency", cfg.Concurrency,
 		"operation", cfg.OperationType)
 
-	results, stats, err := stresser.RunStressTest(ctx, cfg)
+	runTest := stresser.RunStressTest
+	if cfg.Agents != "" {
+		runTest = stresser.RunDistributed
+	}
+	results, stats, err := runTest(ctx, cfg)
 	if err != nil {
 		// Check if the error was due to context cancellation (timeout or signal) - this is expected
 		if errors.Is(ctx.Err(), context.Canceled) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
@@ -127,7 +414,7 @@ func run(ctx context.Context, manifestPath string) error {
 			// Proceed to report results collected so far
 		} else {
 			// A different, unexpected error occurred during the run
-			return fmt.Errorf("stress test execution failed: %w", err)
+			return nil, fmt.Errorf("stress test execution failed: %w", err)
 		}
 	}
 
@@ -147,26 +434,761 @@ func run(ctx context.Context, manifestPath string) error {
 		}
 	}
 
-	// 6. Print Summary Statistics to Console
+	// 6. Print Summary Statistics
 	if stats != nil {
-		stats.PrintSummary(os.Stdout)
+		if err := writeSummary(cfg, stats); err != nil {
+			slog.Error("Error writing summary", "error", err, "format", cfg.SummaryFormat)
+		}
 	}
 
-	// 7. Write Detailed Results to CSV
-	if len(results) > 0 {
-		if err := stresser.WriteResultsCSV(results, cfg.OutputFile); err != nil {
-			// Log CSV writing error but don't necessarily fail the whole run
-			slog.Error("Error writing results CSV", "error", err, "file", cfg.OutputFile)
-			// return fmt.Errorf("failed to write results CSV: %w", err) // Optionally make this fatal
+	// 7. Write Detailed Results, unless they were streamed to the output during the run
+	if cfg.Checkpoint != "" {
+		slog.Debug("Detailed results were written during the run", "file", cfg.OutputFile)
+	} else if len(results) > 0 {
+		if err := stresser.WriteResultsWithStats(results, stats, cfg.OutputFile, cfg.OutputFormat); err != nil {
+			// Log writing error but don't necessarily fail the whole run
+			slog.Error("Error writing results", "error", err, "file", cfg.OutputFile, "format", cfg.OutputFormat)
 		}
 	} else {
-		slog.Warn("No results collected, skipping CSV output")
+		slog.Warn("No results collected, skipping results output")
+	}
+	if cfg.HDRLog != "" && stats != nil {
+		if err := stresser.WriteHDRLogFile(stats, cfg.HDRLog); err != nil {
+			slog.Error("Error writing HDR histogram log", "error", err, "file", cfg.HDRLog)
+		}
+	}
+	if cfg.TimeSeries != "" && stats != nil {
+		if err := stresser.WriteTimeSeriesFile(stats, cfg.TimeSeries); err != nil {
+			slog.Error("Error writing time series", "error", err, "file", cfg.TimeSeries)
+		}
+	}
+	if cfg.HTMLReport != "" && stats != nil {
+		if err := stresser.WriteHTMLReportFile(stats, cfg.HTMLReport); err != nil {
+			slog.Error("Error writing HTML report", "error", err, "file", cfg.HTMLReport)
+		}
+	}
+
+	// The run itself succeeded; it still fails if it missed its SLOs
+	return stats, stats.CheckSLOs()
+}
+
+// runAgent serves stress test shards to a coordinator until interrupted.
+func runAgent(args []string) error {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	listen := fs.String("listen", ":"+stresser.DefaultAgentPort, "Address to accept runs from a coordinator on")
+	token := fs.String("token", os.Getenv("STRESSER_AGENT_TOKEN"), "Shared secret coordinators must present with -agent-token (default: $STRESSER_AGENT_TOKEN)")
+	tlsCert := fs.String("tls-cert", "", "Serve HTTPS with this PEM certificate, so credentials from the coordinator are encrypted (requires -tls-key)")
+	tlsKey := fs.String("tls-key", "", "PEM private key of -tls-cert")
+	level := fs.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	fs.Parse(args)
+	setupLogger(*level)
+	if *token == "" {
+		return fmt.Errorf("agent requires a shared token (-token or STRESSER_AGENT_TOKEN)")
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return fmt.Errorf("-tls-cert and -tls-key must be given together")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: *listen, Handler: stresser.NewAgent(*token)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	var err error
+	if *tlsCert != "" {
+		slog.Info("Agent listening", "address", *listen, "tls", true)
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		slog.Warn("Agent listening without TLS; coordinators refuse to send it credentials unless run with -agent-insecure", "address", *listen)
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// runCleanup deletes the keys of a generated manifest, or all keys under a prefix.
+func runCleanup(args []string) error {
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	config := fs.String("config", "", "Path to YAML config file with the connection details (optional, overrides env vars)")
+	backendName := fs.String("backend", "", "Storage backend: 's3' or 'gcs' (default: from config, else s3)")
+	prefix := fs.String("prefix", "", "Delete every object under this prefix instead of the keys in the manifest")
+	runID := fs.String("run-id", "", "Delete every object a run wrote with the default key templates, under stresser/<run id>/")
+	batch := fs.Int("batch", stresser.DefaultCleanupBatchSize, "Keys per DeleteObjects request (1-1000)")
+	workers := fs.Int("c", 4, "Number of DeleteObjects requests in flight")
+	every := fs.Duration("progress", 5*time.Second, "Log progress this often (0 disables)")
+	level := fs.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s cleanup [options] [<manifest.txt>]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Deletes the objects listed in the manifest generated by a 'write' run, with -prefix every\n")
+		fmt.Fprintf(fs.Output(), "object under a prefix, or with -run-id every object of one run. Connection details come\n")
+		fmt.Fprintf(fs.Output(), "from -config and the environment.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	setupLogger(*level)
+
+	if *runID != "" {
+		if *prefix != "" || strings.ContainsAny(*runID, " \t\r\n/") {
+			fs.Usage()
+			return fmt.Errorf("-run-id takes a run id without '/' and cannot be combined with -prefix")
+		}
+		*prefix = stresser.RunPrefix(*runID)
+	}
+	if (*prefix == "") == (fs.NArg() == 0) || fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("either a manifest file, -prefix or -run-id is required")
 	}
+	if *batch < 1 || *batch > stresser.DefaultCleanupBatchSize {
+		return fmt.Errorf("batch size (-batch) must be between 1 and %d", stresser.DefaultCleanupBatchSize)
+	}
+	cfg, err := stresser.LoadConfig(*config)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *backendName != "" {
+		cfg.Backend = *backendName
+	}
+	cfg.ManifestPath = fs.Arg(0)
 
-	// If we reached here without returning an unexpected error from RunStressTest, it's a success.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := stresser.RunCleanup(ctx, cfg, stresser.CleanupOptions{Prefix: *prefix, BatchSize: *batch, Workers: *workers, Progress: *every})
+	slog.Info("Cleanup finished", "deleted", report.Deleted, "failed", report.Failed, "duration", report.Duration.Round(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d objects could not be deleted", report.Failed)
+	}
 	return nil
 }
 
+// runScan lists the objects of a bucket or prefix into a manifest for read runs.
+func runScan(args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	config := fs.String("config", "", "Path to YAML config file with the connection details (optional, overrides env vars)")
+	backendName := fs.String("backend", "", "Storage backend: 's3' or 'gcs' (default: from config, else s3)")
+	prefix := fs.String("prefix", "", "List only the objects under this prefix (default: the whole bucket)")
+	limit := fs.Int("limit", 0, "Stop after this many objects (0: no limit)")
+	minSize := fs.Int64("min-size", 0, "Leave out objects smaller than this many bytes (1 skips empty objects)")
+	every := fs.Duration("progress", 5*time.Second, "Log progress this often (0 disables)")
+	level := fs.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s scan [options] <manifest.txt>\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Lists the objects of the bucket, or with -prefix of a prefix, and writes their keys to the\n")
+		fmt.Fprintf(fs.Output(), "manifest for read runs. Manifests ending in .csv or .json/.jsonl also get the size and ETag\n")
+		fmt.Fprintf(fs.Output(), "of every object, which reads then validate. Connection details come from -config and the environment.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	setupLogger(*level)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("the manifest file path argument is required")
+	}
+	if *limit < 0 || *minSize < 0 {
+		return fmt.Errorf("-limit and -min-size must not be negative")
+	}
+	cfg, err := stresser.LoadConfig(*config)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *backendName != "" {
+		cfg.Backend = *backendName
+	}
+	cfg.ManifestPath = fs.Arg(0)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := stresser.RunScan(ctx, cfg, stresser.ScanOptions{Prefix: *prefix, Limit: *limit, MinSize: *minSize, Progress: *every})
+	slog.Info("Scan finished", "objects", report.Objects, "bytes", report.Bytes, "skipped", report.Skipped,
+		"duration", report.Duration.Round(time.Millisecond), "path", cfg.ManifestPath)
+	return err
+}
+
+// runGenerate uploads a fixed number of objects and writes their keys to a manifest, to
+// prepare a dataset for read runs. It is 'run -op write -files N' with only the flags that apply.
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	config := fs.String("config", "", "Path to YAML config file with the connection details (optional, overrides env vars)")
+	backendName := fs.String("backend", "", "Storage backend: 's3' or 'gcs' (default: from config, else s3)")
+	endpointList := fs.String("endpoints", "", "Spread the uploads over these comma-separated endpoints, each 'url' or 'url=weight'")
+	bucketList := fs.String("buckets", "", "Spread the objects over these comma-separated buckets; ranges like 'bench-{0..15}' are expanded")
+	bucketMode := fs.String("bucket-select", "", "With -buckets: 'round-robin', 'random' or 'hash' (use 'hash' to read the objects back with -buckets)")
+	files := fs.Int("files", stresser.DefaultFileCount, "Number of objects to upload")
+	size := fs.Int("putsize", stresser.DefaultPutSizeKB, "Size of the objects in KB")
+	sizeDist := fs.String("putsize-dist", "", "Distribution of object sizes instead of -putsize: 'uniform:4K-64M', 'lognormal:<median>:<sigma>' or a weighted list like '4K:50%,1M:40%,64M:10%'")
+	pattern := fs.String("data-pattern", "", "Content of the objects: 'random', 'zeroes', 'compressible:<ratio>' or 'dedupe[:<blocks>]' (default: random)")
+	template := fs.String("key-template", "", "Template for the object keys, e.g. 'bench/{shard:16}/{seq}-{rand}' (default: 'stresser/generated/{seq}-{rand}.dat')")
+	metadata := fs.String("metadata", "", "User metadata set on every object as 'name=value' pairs (merged with YAML metadata)")
+	contentType := fs.String("content-type", "", "Content-Type of the objects")
+	cacheControl := fs.String("cache-control", "", "Cache-Control of the objects")
+	tags := fs.String("tags", "", "Object tags set on every object as 'name=value' pairs (merged with YAML tags)")
+	sse := fs.String("sse", "", "Server-side encryption of the objects: 'none', 'sse-s3', 'sse-kms' or 'sse-c'")
+	sseKMSKeyID := fs.String("sse-kms-key-id", "", "KMS key id for -sse sse-kms")
+	sseCKey := fs.String("sse-c-key", "", "Base64-encoded 256-bit customer key for -sse sse-c")
+	workers := fs.Int("c", 10, "Number of concurrent uploads")
+	limit := fs.String("d", "1h", "Stop after this long even if not all objects were uploaded")
+	output := fs.String("o", "generate_results.csv", "Output file path for detailed results ('-' for stdout)")
+	summaryFormat := fs.String("summary-format", stresser.DefaultSummaryFormat, "Summary format: 'text', 'json' or 'yaml'")
+	level := fs.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s generate [options] <manifest.txt>\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Uploads -files objects and writes their keys to the manifest, for later read runs.\n")
+		fmt.Fprintf(fs.Output(), "Connection details come from -config and the environment.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("the manifest file path argument is required")
+	}
+
+	cfg, err := stresser.LoadConfig(*config)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.ApplyFlags(*limit, *workers, false, fs.Arg(0), *output, "write", *size, *files, true, *level)
+	cfg.FileCount = *files
+	cfg.SummaryFormat = *summaryFormat
+	if *backendName != "" {
+		cfg.Backend = *backendName
+	}
+	if *sizeDist != "" {
+		cfg.PutSizeDistribution = *sizeDist
+	}
+	if *pattern != "" {
+		cfg.DataPattern = *pattern
+	}
+	if *template != "" {
+		cfg.KeyTemplate = *template
+	}
+	if *metadata != "" {
+		cfg.MetadataSpec = *metadata
+	}
+	if *contentType != "" {
+		cfg.ContentType = *contentType
+	}
+	if *cacheControl != "" {
+		cfg.CacheControl = *cacheControl
+	}
+	if *tags != "" {
+		cfg.TagSpec = *tags
+	}
+	if *sse != "" {
+		cfg.SSE = strings.ToLower(*sse)
+	}
+	if *sseKMSKeyID != "" {
+		cfg.SSEKMSKeyID = *sseKMSKeyID
+	}
+	if *sseCKey != "" {
+		cfg.SSECustomerKey = *sseCKey
+	}
+	if *endpointList != "" {
+		cfg.EndpointSpec = *endpointList
+	}
+	if *bucketList != "" {
+		cfg.BucketSpec = *bucketList
+	}
+	if *bucketMode != "" {
+		cfg.BucketSelection = strings.ToLower(*bucketMode)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return execute(ctx, cfg, fs.Usage)
+}
+
+// runReport prints the summary of detailed results saved by an earlier run.
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "", "Results format: 'csv', 'jsonl', 'json' or 'parquet' (default: inferred from the extension, else csv)")
+	summaryFile := fs.String("summary", "", "Write the summary to this file (default: stdout)")
+	summaryFormat := fs.String("summary-format", stresser.DefaultSummaryFormat, "Summary format: 'text', 'json' or 'yaml'")
+	prefixDepth := fs.Int("prefix-depth", 0, "Report per-prefix stats grouped by this many key path segments (0 disables)")
+	outlierCount := fs.Int("outliers", stresser.DefaultOutlierCount, "Report this many of the slowest requests (0 disables)")
+	htmlReport := fs.String("html", "", "Also write a self-contained HTML report with latency, throughput and error charts to this file")
+	compare := fs.Bool("compare", false, "Compare two runs, <baseline> <candidate>, given as detailed results or JSON/YAML summaries")
+	threshold := fs.Float64("threshold", stresser.DefaultRegressionThreshold, "With -compare: percent by which throughput or latency may get worse before it counts as a regression")
+	level := fs.String("log-level", stresser.DefaultLogLevel, "Log level: debug, info, warn, error")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s report [options] <results>\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "       %s report -compare [options] <baseline> <candidate>\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Prints the summary of the detailed results (-o) of an earlier run. CSV results hold fewer\n")
+		fmt.Fprintf(fs.Output(), "fields than JSON results, so some summary sections may be missing. With -compare, prints\n")
+		fmt.Fprintf(fs.Output(), "the changes between two runs and exits with code 2 if the candidate regressed.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	setupLogger(*level)
+	if *compare {
+		if fs.NArg() != 2 {
+			fs.Usage()
+			return fmt.Errorf("-compare needs the baseline and candidate files")
+		}
+		return compareRuns(fs.Arg(0), fs.Arg(1), *format, *threshold, &stresser.Config{SummaryFile: *summaryFile, SummaryFormat: *summaryFormat})
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("the results file argument is required")
+	}
+
+	results, err := stresser.LoadResults(fs.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+	slog.Info("Loaded results", "count", len(results), "path", fs.Arg(0))
+	stats := stresser.NewStats()
+	stats.PrefixDepth = *prefixDepth
+	stats.OutlierCount = *outlierCount
+	stats.TrackWrites = true // The results are in memory already
+	stats.CalculateFromResults(results)
+	if *htmlReport != "" {
+		if err := stresser.WriteHTMLReportFile(stats, *htmlReport); err != nil {
+			return err
+		}
+		slog.Info("Wrote HTML report", "path", *htmlReport)
+	}
+	return writeSummary(&stresser.Config{SummaryFile: *summaryFile, SummaryFormat: *summaryFormat}, stats)
+}
+
+// compareRuns writes the changes from the baseline run to the candidate run to the summary
+// output of cfg, returning an error wrapping ErrRegression if the candidate regressed.
+func compareRuns(baselinePath, candidatePath, format string, threshold float64, cfg *stresser.Config) error {
+	baseline, err := stresser.LoadSummary(baselinePath, format)
+	if err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+	candidate, err := stresser.LoadSummary(candidatePath, format)
+	if err != nil {
+		return fmt.Errorf("candidate: %w", err)
+	}
+	comparison := stresser.CompareSummaries(baseline, candidate, threshold)
+	out, err := cfg.SummaryOutput()
+	if err != nil {
+		return err
+	}
+	if err := stresser.WriteComparison(out, comparison, cfg.SummaryFormat); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return comparison.Check()
+}
+
+// reportSweep prints and saves the steps that completed, even if the sweep ended early.
+func reportSweep(cfg *stresser.Config, param string, points []stresser.SweepPoint, sweepErr error) error {
+	if len(points) > 0 {
+		if out, err := cfg.SummaryOutput(); err != nil {
+			slog.Error("Error opening summary output", "error", err)
+		} else {
+			stresser.PrintSweepTable(out, param, points)
+			out.Close()
+		}
+		if err := stresser.WriteSweepCSV(points, param, cfg.OutputFile); err != nil {
+			slog.Error("Error writing sweep CSV", "error", err, "file", cfg.OutputFile)
+		}
+	}
+	if sweepErr != nil {
+		return fmt.Errorf("sweep failed: %w", sweepErr)
+	}
+	return nil
+}
+
+// reportPlan prints a table of the phases that completed, even if the plan ended early.
+// Each phase has already written its own summary and results.
+func reportPlan(cfg *stresser.Config, phases []stresser.PhaseResult, planErr error) error {
+	if len(phases) > 0 && cfg.SummaryFormat == stresser.DefaultSummaryFormat && !cfg.Quiet {
+		if out, err := cfg.SummaryOutput(); err != nil {
+			slog.Error("Error opening summary output", "error", err)
+		} else {
+			stresser.PrintPlanTable(out, phases)
+			out.Close()
+		}
+	}
+	if planErr != nil {
+		if errors.Is(planErr, stresser.ErrSLOViolation) {
+			return planErr
+		}
+		return fmt.Errorf("test plan failed: %w", planErr)
+	}
+	return nil
+}
+
+// writeSummary writes the run summary to its configured destination.
+func writeSummary(cfg *stresser.Config, stats *stresser.Stats) error {
+	out, err := cfg.SummaryOutput()
+	if err != nil {
+		return err
+	}
+	write := stresser.WriteSummary
+	if cfg.Quiet {
+		write = stresser.WriteResultLine
+	}
+	if err := write(out, stats, cfg.SummaryFormat); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// applyExplicitFlags overrides config values only for flags that were explicitly set on the
+// command line, so values from YAML or environment variables survive otherwise.
+func applyExplicitFlags(cfg *stresser.Config) {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if set["backend"] {
+		cfg.Backend = *backend
+	}
+	if set["endpoints"] {
+		cfg.EndpointSpec = *endpoints
+	}
+	if set["endpoint-select"] {
+		cfg.EndpointSelection = strings.ToLower(*endpointSelect)
+	}
+	if set["buckets"] {
+		cfg.BucketSpec = *buckets
+	}
+	if set["bucket-select"] {
+		cfg.BucketSelection = strings.ToLower(*bucketSelect)
+	}
+	if set["user-agent"] {
+		cfg.UserAgent = *userAgent
+	}
+	if set["run-id"] {
+		cfg.RunID = *runID
+	}
+	if set["header"] {
+		cfg.Headers = stresser.HeaderList(headers)
+	}
+	if set["hedge-quantile"] {
+		cfg.HedgeQuantile = *hedgeQuantile
+	}
+	if set["ip-family"] {
+		cfg.IPFamily = *ipFamily
+	}
+	if set["http-version"] {
+		cfg.HTTPVersion = *httpVersion
+	}
+	if set["tls-ca-file"] {
+		cfg.TLSCAFile = *tlsCAFile
+	}
+	if set["tls-cert-file"] {
+		cfg.TLSCertFile = *tlsCertFile
+	}
+	if set["tls-key-file"] {
+		cfg.TLSKeyFile = *tlsKeyFile
+	}
+	if set["tls-min-version"] {
+		cfg.TLSMinVersion = *tlsMinVer
+	}
+	if set["signing"] {
+		cfg.Signing = *signing
+	}
+	if set["no-sign"] && *noSign {
+		cfg.Signing = stresser.SigningNone
+	}
+	if set["role-arn"] {
+		cfg.RoleARN = *roleARN
+	}
+	if set["external-id"] {
+		cfg.RoleExternalID = *externalID
+	}
+	if set["role-session-name"] {
+		cfg.RoleSessionName = *roleSession
+	}
+	if set["role-duration"] {
+		cfg.RoleDuration = *roleDur
+	}
+	if set["sts-endpoint"] {
+		cfg.STSEndpoint = *stsEndpoint
+	}
+	if set["presign"] {
+		cfg.Presign = *presign
+	}
+	if set["preflight"] {
+		cfg.Preflight = *preflight
+	}
+	if set["create-bucket"] {
+		cfg.CreateBucket = *createBucket
+	}
+	if set["disable-keepalive"] || set["no-keepalive"] {
+		cfg.DisableKeepAlive = *noKeepAlive
+	}
+	if set["client-per-worker"] {
+		cfg.ClientPerWorker = *clientPerWorker
+	}
+	if set["dns-refresh"] {
+		cfg.DNSRefresh = *dnsRefresh
+	}
+	if set["conn-max-requests"] {
+		cfg.ConnMaxRequests = *connMaxRequests
+	}
+	if set["conn-max-age"] {
+		cfg.ConnMaxAge = *connMaxAge
+	}
+	if set["max-idle-conns"] {
+		cfg.MaxIdleConns = *maxIdleConns
+	}
+	if set["max-idle-conns-per-host"] {
+		cfg.MaxIdleConnsPerHost = *maxIdlePerHost
+	}
+	if set["max-conns-per-host"] {
+		cfg.MaxConnsPerHost = *maxConnsPerHost
+	}
+	if set["idle-conn-timeout"] {
+		cfg.IdleConnTimeout = *idleConnTimeout
+	}
+	if set["retry-mode"] {
+		cfg.RetryMode = *retryMode
+	}
+	if set["retry-max-attempts"] {
+		cfg.RetryMaxAttempts = *retryAttempts
+	}
+	if set["retry-max-backoff"] {
+		cfg.RetryMaxBackoff = *retryMaxBackoff
+	}
+	if set["replay"] {
+		cfg.ReplayFile = *replayFile
+	}
+	if set["replay-format"] {
+		cfg.ReplayFormat = *replayFormat
+	}
+	if set["replay-speed"] {
+		cfg.ReplaySpeed = *replaySpeed
+	}
+	if set["replay-timing"] {
+		cfg.ReplayTiming = *replayTiming
+	}
+	if set["record"] {
+		cfg.TraceFile = *recordTrace
+	}
+	if set["size-sweep"] {
+		cfg.SizeSweep = *sizeSweep
+	}
+	if set["concurrency-sweep"] {
+		cfg.ConcurrencySweep = *concurrencySweep
+	}
+	if set["plan"] {
+		cfg.Plan = *plan
+	}
+	if set["prefix-depth"] {
+		cfg.PrefixDepth = *prefixDepth
+	}
+	if set["tui"] {
+		cfg.TUI = *tui
+	}
+	if set["control"] {
+		cfg.Control = *control
+	}
+	if set["pprof-addr"] {
+		cfg.PprofAddr = *pprofAddr
+	}
+	if set["quiet"] {
+		cfg.Quiet = *quiet
+	}
+	if set["progress"] {
+		cfg.Progress = *progress
+	}
+	if set["summary"] {
+		cfg.SummaryFile = *summaryFile
+	}
+	if set["format"] {
+		cfg.OutputFormat = *outputFormat
+	}
+	if set["summary-format"] {
+		cfg.SummaryFormat = *summaryFormat
+	}
+	if set["checkpoint"] {
+		cfg.Checkpoint = *checkpoint
+	}
+	if set["sample-rate"] {
+		cfg.SampleRate = *sampleRate
+	}
+	if set["sample-max"] {
+		cfg.SampleMax = *sampleMax
+	}
+	if set["outliers"] {
+		cfg.OutlierCount = *outlierCount
+	}
+	if set["track-writes"] {
+		cfg.TrackWrites = *trackWrites
+	}
+	if set["slow-threshold"] {
+		cfg.SlowThreshold = *slowThresh
+	}
+	if set["slow-log"] {
+		cfg.SlowLog = *slowLog
+	}
+	if set["hdr-log"] {
+		cfg.HDRLog = *hdrLog
+	}
+	if set["slo"] {
+		cfg.SLOSpec = *sloSpec
+	}
+	if set["timeseries"] {
+		cfg.TimeSeries = *timeSeries
+	}
+	if set["html-report"] {
+		cfg.HTMLReport = *htmlReport
+	}
+	if set["nic"] {
+		cfg.NICInterface = *nicInterface
+	}
+	if set["target-p99"] {
+		cfg.TargetP99 = *targetP99
+	}
+	if set["cpu-budget"] {
+		cfg.CPUBudget = *cpuBudget
+	}
+	if set["gomaxprocs"] {
+		cfg.GoMaxProcs = *goMaxProcs
+	}
+	if set["lock-threads"] {
+		cfg.LockThreads = *lockThreads
+	}
+	if set["thread-pool"] {
+		cfg.ThreadPool = *threadPool
+	}
+	if set["warmup"] {
+		cfg.Warmup = *warmup
+	}
+	if set["warmup-results"] {
+		cfg.WarmupResults = *warmupRes
+	}
+	if set["jitter"] {
+		cfg.Jitter = *jitter
+	}
+	if set["think-time"] {
+		cfg.ThinkTime = *thinkTime
+	}
+	if set["putsize-dist"] {
+		cfg.PutSizeDistribution = *putSizeDist
+	}
+	if set["data-pattern"] {
+		cfg.DataPattern = *dataPattern
+	}
+	if set["key-template"] {
+		cfg.KeyTemplate = *keyTemplate
+	}
+	if set["distribution"] {
+		cfg.KeyDistribution = *keyDist
+	}
+	if set["revalidate-stale"] {
+		cfg.RevalidateStale = *revalStale
+	}
+	if set["consistency-delays"] {
+		cfg.ConsistencyDelays = *consDelays
+	}
+	if set["consistency-probe"] {
+		cfg.ConsistencyProbe = *consProbe
+	}
+	if set["keypool"] {
+		cfg.KeyPool = *keyPool
+	}
+	if set["write-mode"] {
+		cfg.WriteMode = *writeMode
+	}
+	if set["append-size"] {
+		cfg.AppendSizeKB = *appendSize
+	}
+	if set["append-max"] {
+		cfg.AppendMaxKB = *appendMax
+	}
+	if set["list-prefix"] {
+		cfg.ListPrefix = *listPrefix
+	}
+	if set["list-page-size"] {
+		cfg.ListPageSize = *listPage
+	}
+	if set["copy-part-size"] {
+		cfg.CopyPartSizeMB = *copyPart
+	}
+	if set["scenario"] {
+		cfg.Scenario = *scenario
+	}
+	if set["version-mix"] {
+		cfg.VersionMix = *versionMix
+	}
+	if set["verify"] {
+		cfg.Verify = *verify
+	}
+	if set["checksum"] {
+		cfg.Checksum = *checksum
+	}
+	if set["sse"] {
+		cfg.SSE = strings.ToLower(*sse)
+	}
+	if set["sse-kms-key-id"] {
+		cfg.SSEKMSKeyID = *sseKMSKeyID
+	}
+	if set["sse-c-key"] {
+		cfg.SSECustomerKey = *sseCKey
+	}
+	if set["accept-encoding"] {
+		cfg.AcceptEncoding = *acceptEncoding
+	}
+	if set["metadata"] {
+		cfg.MetadataSpec = *metadata
+	}
+	if set["content-type"] {
+		cfg.ContentType = *contentType
+	}
+	if set["cache-control"] {
+		cfg.CacheControl = *cacheControl
+	}
+	if set["tags"] {
+		cfg.TagSpec = *tags
+	}
+	if set["agents"] {
+		cfg.Agents = *agents
+	}
+	if set["agent-token"] {
+		cfg.AgentToken = *agentToken
+	}
+	if set["agent-insecure"] {
+		cfg.AgentInsecure = *agentInsecure
+	}
+	if set["shard"] {
+		cfg.Shard = *shard
+	}
+	if set["ramp"] {
+		cfg.Ramp = *ramp
+	}
+	if set["rps"] {
+		cfg.RPS = *rps
+	}
+	if set["worker-rps"] {
+		cfg.WorkerRPS = *workerRPS
+	}
+	if set["arrival-rate"] {
+		cfg.ArrivalRate = *arrivalRate
+	}
+	if set["bandwidth-limit"] {
+		cfg.BandwidthLimit = *bandwidth
+	}
+	if set["secondary-endpoint"] {
+		cfg.SecondaryEndpoint = *secondaryEndpoint
+	}
+	if set["failover-threshold"] {
+		cfg.FailoverThreshold = *failoverThreshold
+	}
+	if set["disconnect-at"] {
+		cfg.DisconnectFraction = *disconnectAt
+	}
+}
+
 // setupLogger configures the slog logger based on the log level
 func setupLogger(level string) {
 	var logLevel slog.Level
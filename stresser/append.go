@@ -0,0 +1,217 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Defaults of 'append' mode.
+const (
+	DefaultAppendSizeKB = 4     // Chunk added by every append
+	DefaultAppendMaxKB  = 16384 // Size at which a log starts over
+)
+
+// Outcomes of the PUT of an append in 'append' mode, stored in Result.Append.
+const (
+	AppendCreated  = "created"  // First write of the log in the run, sent without a GET
+	AppendAppended = "appended" // The chunk was added to the end of the log
+	AppendRolled   = "rolled"   // The log would have grown past AppendMaxKB and was replaced by the chunk
+)
+
+// validateAppend checks the parameters of 'append' mode. Called from Validate.
+func (c *Config) validateAppend() error {
+	if c.AppendSizeKB < 0 || c.AppendMaxKB < 0 {
+		return fmt.Errorf("append chunk (-append-size) and log size (-append-max) must not be negative")
+	}
+	if c.OperationType != "append" {
+		return nil
+	}
+	if c.AppendSizeKB == 0 {
+		c.AppendSizeKB = DefaultAppendSizeKB
+	}
+	if c.AppendMaxKB == 0 {
+		c.AppendMaxKB = DefaultAppendMaxKB
+	}
+	if c.AppendMaxKB < c.AppendSizeKB {
+		return fmt.Errorf("log size (-append-max) %d KB must be at least the append chunk (-append-size) %d KB", c.AppendMaxKB, c.AppendSizeKB)
+	}
+	if c.KeyPool == 0 {
+		c.KeyPool = 1
+	}
+	if c.KeyDistribution == KeyDistWeighted {
+		return fmt.Errorf("'append' mode has no manifest weights to pick logs by (-distribution weighted)")
+	}
+	if c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support 'append' mode")
+	}
+	if c.DisconnectFraction > 0 {
+		return fmt.Errorf("'append' mode cannot be combined with -disconnect-at")
+	}
+	if c.AcceptEncoding != "" {
+		return fmt.Errorf("'append' mode writes back the bytes it reads and cannot be combined with -accept-encoding")
+	}
+	return nil
+}
+
+// appendLogs returns the keys of the logs a worker appends to. Every worker has logs of its
+// own, so no two workers read and write the same object and appends are never lost.
+func appendLogs(runID string, worker, n int) []string {
+	return poolKeys(runID, fmt.Sprintf("append/w%04d", worker), n)
+}
+
+// logAppender holds the logs of a worker in 'append' mode and remembers which of them it
+// has written in the run.
+type logAppender struct {
+	logs      []string
+	picker    *keyPicker
+	written   map[string]bool // By bucket and key
+	chunkSize int64
+	maxSize   int64
+}
+
+func newLogAppender(cfg *Config, id int, r *rand.Rand, dist keyDistribution) *logAppender {
+	return &logAppender{
+		logs:      appendLogs(cfg.RunID, id, cfg.KeyPool),
+		picker:    dist.newPicker(r, cfg.KeyPool, 0, nil),
+		written:   make(map[string]bool),
+		chunkSize: int64(cfg.AppendSizeKB) * 1024,
+		maxSize:   int64(cfg.AppendMaxKB) * 1024,
+	}
+}
+
+// pick returns the log to append to next, picked by the key distribution.
+func (a *logAppender) pick() string {
+	return a.logs[a.picker.pick()]
+}
+
+// append adds a chunk of pattern to log key in bucket, see performAppendOperation.
+func (a *logAppender) append(ctx context.Context, s3Client S3ClientAPI, cfg *Config, bucket, key string, pattern dataPattern, r *rand.Rand) (get, put Result, ok bool) {
+	chunk, _ := io.ReadAll(pattern.payload(a.chunkSize, r)) // Generated in memory, reading cannot fail
+	get, put, ok = performAppendOperation(ctx, s3Client, bucket, key, chunk, a.maxSize, !a.written[bucket+"/"+key], cfg.Verify, cfg.putAttributes())
+	if ok && put.Error == "" {
+		a.written[bucket+"/"+key] = true
+	}
+	return get, put, ok
+}
+
+// performAppendOperation models appending to a log on object storage, which has no append:
+// a GET reads the whole log, the chunk is added to its end and a PUT writes it back. Logs
+// that would grow past maxSize start over with the chunk, like a rotated log file. A log
+// not written yet in the run (created) is written with a PUT of the chunk alone.
+// It returns the results of the GET, if one was sent, and of the PUT, whose RMW latency
+// covers both. ok is false if the GET failed and no PUT was sent.
+func performAppendOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string, chunk []byte, maxSize int64, created, verify bool, attrs putAttributes) (get, put Result, ok bool) {
+	start := time.Now()
+	var log bytes.Buffer
+	if !created {
+		a := startGet(ctx, s3Client, bucket, key)
+		if a.resp != nil {
+			// Keep a copy of the body as it is read
+			body := a.resp.Body
+			a.resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(body, &log), body}
+		}
+		if get = a.finish(a.start, verify); get.Error != "" {
+			return get, Result{}, false
+		}
+	}
+
+	outcome := AppendAppended
+	switch {
+	case created:
+		outcome = AppendCreated
+	case int64(log.Len()+len(chunk)) > maxSize:
+		log.Reset()
+		outcome = AppendRolled
+	}
+	log.Write(chunk)
+	put = sendPut(ctx, s3Client, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, bytes.NewReader(log.Bytes()), verify, attrs)
+	if put.Error == "" {
+		put.Append = outcome
+		put.RMW = time.Since(start)
+	}
+	return get, put, true
+}
+
+// AppendReport summarizes the read-modify-write appends of 'append' mode. The GETs and
+// PUTs are also counted in the GET and PUT figures.
+type AppendReport struct {
+	Appends     int64           `json:"appends" yaml:"appends"`         // Appends to an existing log, including rolled ones
+	Created     int64           `json:"created" yaml:"created"`         // Logs written for the first time, without a GET
+	Rolled      int64           `json:"rolled" yaml:"rolled"`           // Logs that started over at -append-max
+	AvgLogBytes float64         `json:"avgLogBytes" yaml:"avgLogBytes"` // Average size of the logs written back
+	RMW         *LatencySummary `json:"rmwMs,omitempty" yaml:"rmwMs,omitempty"`
+}
+
+// appendStats holds the end-to-end latencies of appends and the bytes they wrote back.
+type appendStats struct {
+	rmw      *Histogram
+	created  int64
+	rolled   int64
+	logBytes int64
+}
+
+// addAppendResult records the PUT of an append. Called from AddResult.
+func (s *Stats) addAppendResult(r Result) {
+	if r.Operation != "PUT" || r.Append == "" {
+		return
+	}
+	if s.appends == nil {
+		s.appends = &appendStats{rmw: NewHistogram()}
+	}
+	s.appends.logBytes += r.BytesUploaded
+	switch r.Append {
+	case AppendCreated:
+		s.appends.created++
+		return // Only a PUT, not a read-modify-write
+	case AppendRolled:
+		s.appends.rolled++
+	}
+	s.appends.rmw.Record(r.RMW)
+}
+
+// Appends returns the figures of 'append' mode, or nil if no append was made.
+func (s *Stats) Appends() *AppendReport {
+	if s.appends == nil {
+		return nil
+	}
+	ar := &AppendReport{
+		Appends: s.appends.rmw.Count(),
+		Created: s.appends.created,
+		Rolled:  s.appends.rolled,
+		RMW:     histogramSummary(s.appends.rmw),
+	}
+	if puts := ar.Appends + ar.Created; puts > 0 {
+		ar.AvgLogBytes = float64(s.appends.logBytes) / float64(puts)
+	}
+	return ar
+}
+
+// printAppendSummary prints the end-to-end latency of appends as part of PrintSummary.
+func (s *Stats) printAppendSummary(w io.Writer) {
+	ar := s.Appends()
+	if ar == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nRead-Modify-Write Appends (GET + PUT):\n")
+	fmt.Fprintf(w, "  Appends:      %d (logs created: %d, rolled over: %d)\n", ar.Appends, ar.Created, ar.Rolled)
+	fmt.Fprintf(w, "  Avg Log Size: %.2f KiB per PUT\n", ar.AvgLogBytes/1024)
+	if l := ar.RMW; l != nil {
+		fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+		fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+		fmt.Fprintf(w, "  %-13s |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			"GET + PUT", l.Min, l.Avg, l.P50, l.P90, l.P99, l.P999, l.Max)
+	}
+}
@@ -0,0 +1,86 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAppend(t *testing.T) {
+	c := &Config{OperationType: "append"}
+	if err := c.validateAppend(); err != nil {
+		t.Fatalf("validateAppend failed: %v", err)
+	}
+	if c.AppendSizeKB != DefaultAppendSizeKB || c.AppendMaxKB != DefaultAppendMaxKB || c.KeyPool != 1 {
+		t.Errorf("Expected the defaults, got %+v", c)
+	}
+	for _, c := range []*Config{
+		{OperationType: "append", AppendSizeKB: -1},
+		{OperationType: "append", AppendSizeKB: 64, AppendMaxKB: 32},
+		{OperationType: "append", KeyDistribution: KeyDistWeighted},
+		{OperationType: "append", Backend: BackendGCS},
+		{OperationType: "append", AcceptEncoding: "gzip"},
+	} {
+		if err := c.validateAppend(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+}
+
+func TestAppendOperation(t *testing.T) {
+	client := &memS3Client{bodies: map[string][]byte{}, metadata: map[string]map[string]string{}}
+	cfg := &Config{RunID: "test", OperationType: "append", AppendSizeKB: 1, AppendMaxKB: 3, Verify: true}
+	if err := cfg.validateAppend(); err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	dist, _ := parseKeyDistribution("", false)
+	appender := newLogAppender(cfg, 7, r, dist)
+	pattern, _ := parseDataPattern("")
+
+	key := appender.pick()
+	if key != "stresser/test/append/w0007/key0000" {
+		t.Errorf("Unexpected log key %q", key)
+	}
+	prev := 0 // Size of the log before the append
+	for i, want := range []struct {
+		outcome string
+		size    int
+	}{{AppendCreated, 1024}, {AppendAppended, 2048}, {AppendAppended, 3072}, {AppendRolled, 1024}} {
+		get, put, ok := appender.append(context.Background(), client, cfg, "bucket", key, pattern, r)
+		if !ok || put.Error != "" || put.Append != want.outcome || len(client.bodies[key]) != want.size {
+			t.Fatalf("Append %d: expected %s with %d bytes, got %+v with %d bytes", i, want.outcome, want.size, put, len(client.bodies[key]))
+		}
+		switch {
+		case want.outcome == AppendCreated && get.Operation != "":
+			t.Errorf("Expected no GET for a new log, got %+v", get)
+		case want.outcome != AppendCreated && (get.Error != "" || get.BytesDownloaded != int64(prev) || get.Integrity != IntegrityOK):
+			t.Errorf("Append %d: expected a verified GET of %d bytes, got %+v", i, prev, get)
+		case put.RMW < get.TTLB:
+			t.Errorf("Append %d: RMW latency %v shorter than the GET %v", i, put.RMW, get.TTLB)
+		}
+		prev = want.size
+	}
+}
+
+func TestAppendStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: time.Millisecond, BytesUploaded: 1024, Append: AppendCreated})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: time.Millisecond, BytesUploaded: 2048, Append: AppendAppended, RMW: 3 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: time.Millisecond, BytesUploaded: 3072, Append: AppendRolled, RMW: 5 * time.Millisecond})
+	stats.Calculate(now, now.Add(time.Second))
+
+	ar := stats.Summary().Appends
+	if ar == nil || ar.Appends != 2 || ar.Created != 1 || ar.Rolled != 1 || ar.AvgLogBytes != 2048 || ar.RMW == nil || ar.RMW.Max < 4.9 {
+		t.Fatalf("Unexpected append report %+v", ar)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Read-Modify-Write Appends") || !strings.Contains(buf.String(), "logs created: 1, rolled over: 1") {
+		t.Errorf("Summary lacks the append figures:\n%s", buf.String())
+	}
+}
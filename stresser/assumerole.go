@@ -0,0 +1,92 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	// DefaultRoleSessionName identifies the sessions of assumed roles in CloudTrail.
+	DefaultRoleSessionName = "ostresser"
+	// roleCredentialsExpiryWindow is how long before they expire assumed role credentials are
+	// refreshed, so requests that are in flight or being retried are never signed with
+	// expiring credentials.
+	roleCredentialsExpiryWindow = 5 * time.Minute
+)
+
+// validateAssumeRole checks the assume role settings.
+func (c *Config) validateAssumeRole() error {
+	if c.RoleARN == "" {
+		if c.RoleExternalID != "" || c.RoleSessionName != "" || c.RoleDuration != "" || c.STSEndpoint != "" {
+			return fmt.Errorf("-external-id, -role-session-name, -role-duration and -sts-endpoint require a role to assume (-role-arn)")
+		}
+		return nil
+	}
+	if c.Backend == BackendGCS || c.Signing == SigningNone {
+		return fmt.Errorf("assuming a role (-role-arn) is not supported with the gcs backend or unsigned requests")
+	}
+	if c.RoleDuration != "" {
+		d, err := time.ParseDuration(c.RoleDuration)
+		if err != nil {
+			return fmt.Errorf("invalid role session duration (-role-duration): %w", err)
+		}
+		// STS accepts sessions of 15 minutes up to the maximum session duration of the role
+		if d < 15*time.Minute || d > 12*time.Hour {
+			return fmt.Errorf("role session duration (-role-duration) must be between 15m and 12h, got %s", d)
+		}
+	}
+	return nil
+}
+
+// assumeRoleCredentials returns credentials of the role cfg.RoleARN, assumed with the
+// credentials of awsCfg. They are cached and assumed again shortly before they expire, so
+// runs can last longer than a role session.
+func assumeRoleCredentials(awsCfg aws.Config, cfg *Config) aws.CredentialsProvider {
+	// STS has its own endpoint, not the one of the store
+	stsCfg := awsCfg.Copy()
+	stsCfg.EndpointResolverWithOptions = nil
+	client := sts.NewFromConfig(stsCfg, func(o *sts.Options) {
+		if cfg.STSEndpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.STSEndpoint)
+		}
+	})
+	provider := stscreds.NewAssumeRoleProvider(client, cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = cfg.RoleSessionName
+		if o.RoleSessionName == "" {
+			o.RoleSessionName = DefaultRoleSessionName
+		}
+		if cfg.RoleExternalID != "" {
+			o.ExternalID = aws.String(cfg.RoleExternalID)
+		}
+		if cfg.RoleDuration != "" {
+			o.Duration, _ = time.ParseDuration(cfg.RoleDuration) // Checked by Validate
+		}
+	})
+	return aws.NewCredentialsCache(&loggingCredentials{provider: provider, role: cfg.RoleARN}, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = roleCredentialsExpiryWindow
+		o.ExpiryWindowJitterFrac = 0.5 // Workers of several agents don't all refresh at once
+	})
+}
+
+// loggingCredentials logs every time the role is assumed, so refreshes during long runs
+// and their failures show up in the log.
+type loggingCredentials struct {
+	provider aws.CredentialsProvider
+	role     string
+}
+
+func (l *loggingCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := l.provider.Retrieve(ctx)
+	if err != nil {
+		slog.Error("Failed to assume role", "role", l.role, "error", err)
+		return creds, err
+	}
+	slog.Info("Assumed role", "role", l.role, "expires", creds.Expires)
+	return creds, nil
+}
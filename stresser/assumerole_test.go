@@ -0,0 +1,77 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestAssumeRole(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	var assumed int
+	var form string
+	var auth, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/" {
+			// STS AssumeRole, with credentials that are due for refresh right away
+			r.ParseForm()
+			form = r.Form.Encode()
+			assumed++
+			fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult>
+<Credentials><AccessKeyId>ASIA%d</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token%d</SessionToken>
+<Expiration>%s</Expiration></Credentials>
+<AssumedRoleUser><Arn>arn:aws:sts::123456789012:assumed-role/bench/ostresser</Arn><AssumedRoleId>AROA:ostresser</AssumedRoleId></AssumedRoleUser>
+</AssumeRoleResult></AssumeRoleResponse>`, assumed, assumed, time.Now().Add(time.Minute).UTC().Format(time.RFC3339))
+			return
+		}
+		auth, token = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Security-Token")
+	}))
+	defer server.Close()
+
+	cfg := &Config{Endpoint: server.URL, Region: "us-east-1", AccessKey: "key", SecretKey: "secret",
+		RoleARN: "arn:aws:iam::123456789012:role/bench", RoleExternalID: "ext", RoleDuration: "15m", STSEndpoint: server.URL}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		if _, err := client.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}); err != nil {
+			t.Fatalf("HeadObject failed: %v", err)
+		}
+		if !strings.Contains(auth, fmt.Sprintf("Credential=ASIA%d/", i)) || token != fmt.Sprintf("token%d", i) {
+			t.Errorf("Request %d not signed with the role's fresh credentials: %q, token %q", i, auth, token)
+		}
+	}
+	if assumed != 2 {
+		t.Errorf("Expected the role to be assumed again before its credentials expire, assumed %d times", assumed)
+	}
+	for _, want := range []string{"ExternalId=ext", "DurationSeconds=900", "RoleSessionName=ostresser"} {
+		if !strings.Contains(form, want) {
+			t.Errorf("AssumeRole request lacks %s: %s", want, form)
+		}
+	}
+}
+
+func TestValidateAssumeRole(t *testing.T) {
+	for _, c := range []Config{
+		{RoleExternalID: "ext"},
+		{RoleARN: "arn", RoleDuration: "5m"},
+		{RoleARN: "arn", RoleDuration: "soon"},
+		{RoleARN: "arn", Signing: SigningNone},
+	} {
+		if err := c.validateAssumeRole(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+	c := Config{RoleARN: "arn", RoleDuration: "12h", STSEndpoint: "https://sts.example.com"}
+	if err := c.validateAssumeRole(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,143 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// idleWorkerPoll is how often a worker above the active limit checks whether it may resume.
+	idleWorkerPoll = 50 * time.Millisecond
+	// cpuRecoveryFactor is the fraction of the CPU budget below which workers are re-enabled.
+	cpuRecoveryFactor = 0.8
+)
+
+// workerLimit caps how many workers may issue requests. Workers with an id at or
+// above the limit idle until it is raised again; while paused, all workers idle.
+type workerLimit struct {
+	limit  atomic.Int64
+	paused atomic.Bool
+}
+
+func newWorkerLimit(n int) *workerLimit {
+	l := &workerLimit{}
+	l.limit.Store(int64(n))
+	return l
+}
+
+// allows reports whether worker id may issue requests. A nil limit allows all workers.
+func (l *workerLimit) allows(id int) bool {
+	return l == nil || (int64(id) < l.limit.Load() && !l.paused.Load())
+}
+
+// Get returns the current number of active workers.
+func (l *workerLimit) Get() int {
+	return int(l.limit.Load())
+}
+
+// Set changes the number of active workers.
+func (l *workerLimit) Set(n int) {
+	l.limit.Store(int64(n))
+}
+
+// Paused reports whether all workers are held idle.
+func (l *workerLimit) Paused() bool {
+	return l.paused.Load()
+}
+
+// SetPaused holds all workers idle, or lets them continue at the current limit. Pausing
+// is independent of the limit, so the ramp and autoscaler don't undo it.
+func (l *workerLimit) SetPaused(paused bool) {
+	l.paused.Store(paused)
+}
+
+// ScalingEvent records a change of the active worker count during a run.
+type ScalingEvent struct {
+	Time       time.Time
+	From       int
+	To         int
+	CPUPercent float64 // Client CPU use that triggered the change
+}
+
+// cpuAutoscaler lowers the active worker count while client CPU exceeds a budget,
+// and raises it again once CPU drops well below the budget.
+type cpuAutoscaler struct {
+	limit     *workerLimit
+	maxActive int
+	budget    float64
+	mu        sync.Mutex
+	events    []ScalingEvent
+}
+
+// run samples CPU every interval until ctx is done. On platforms without CPU
+// accounting it logs a warning and returns immediately.
+func (a *cpuAutoscaler) run(ctx context.Context, interval time.Duration) {
+	lastCPU, ok := processCPUTime()
+	if !ok {
+		slog.Warn("CPU budget autoscaling is not supported on this platform")
+		return
+	}
+	lastWall := time.Now()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			cpu, ok := processCPUTime()
+			if !ok {
+				continue
+			}
+			percent := cpuPercent(cpu-lastCPU, now.Sub(lastWall))
+			lastWall, lastCPU = now, cpu
+			a.adjust(now, percent)
+		}
+	}
+}
+
+// adjust applies one scaling decision for the given CPU sample.
+func (a *cpuAutoscaler) adjust(now time.Time, percent float64) {
+	current := a.limit.Get()
+	next := current
+	switch {
+	case percent > a.budget && current > 1:
+		next = max(1, current-max(1, current/10)) // Shed 10% of workers, at least one
+	case percent < a.budget*cpuRecoveryFactor && current < a.maxActive:
+		next = current + 1
+	}
+	if next == current {
+		return
+	}
+
+	a.limit.Set(next)
+	a.mu.Lock()
+	a.events = append(a.events, ScalingEvent{Time: now, From: current, To: next, CPUPercent: percent})
+	a.mu.Unlock()
+	slog.Info("Autoscaled active workers", "from", current, "to", next, "cpuPercent", fmt.Sprintf("%.1f", percent), "budget", a.budget)
+}
+
+// Events returns the scaling decisions made so far.
+func (a *cpuAutoscaler) Events() []ScalingEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]ScalingEvent(nil), a.events...)
+}
+
+// printScalingSummary lists autoscaling actions as part of PrintSummary.
+func (s *Stats) printScalingSummary(w io.Writer) {
+	if len(s.ScalingEvents) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nAutoscaling (%d adjustments):\n", len(s.ScalingEvents))
+	for _, e := range s.ScalingEvents {
+		fmt.Fprintf(w, "  +%-10s workers %d -> %d (CPU %.1f%%)\n",
+			e.Time.Sub(s.startTime).Round(time.Second), e.From, e.To, e.CPUPercent)
+	}
+}
@@ -0,0 +1,56 @@
+package stresser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkerLimit(t *testing.T) {
+	var unlimited *workerLimit
+	if !unlimited.allows(100) {
+		t.Error("A nil limit should allow every worker")
+	}
+
+	limit := newWorkerLimit(3)
+	if !limit.allows(2) || limit.allows(3) {
+		t.Error("Limit of 3 should allow workers 0-2 only")
+	}
+	limit.Set(1)
+	if limit.allows(1) || limit.Get() != 1 {
+		t.Error("Limit of 1 should allow worker 0 only")
+	}
+}
+
+func TestCPUAutoscalerAdjust(t *testing.T) {
+	limit := newWorkerLimit(20)
+	a := &cpuAutoscaler{limit: limit, maxActive: 20, budget: 80}
+	now := time.Now()
+
+	a.adjust(now, 95) // Over budget: shed 10%
+	if limit.Get() != 18 {
+		t.Errorf("Expected 18 active workers after overload, got %d", limit.Get())
+	}
+	a.adjust(now, 70) // Within the hysteresis band: no change
+	if limit.Get() != 18 {
+		t.Errorf("Expected no change inside hysteresis band, got %d", limit.Get())
+	}
+	a.adjust(now, 30) // Well below budget: add one worker
+	if limit.Get() != 19 {
+		t.Errorf("Expected 19 active workers after recovery, got %d", limit.Get())
+	}
+
+	events := a.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 scaling events, got %d", len(events))
+	}
+	if events[0].From != 20 || events[0].To != 18 || events[0].CPUPercent != 95 {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+
+	single := newWorkerLimit(1)
+	b := &cpuAutoscaler{limit: single, maxActive: 1, budget: 50}
+	b.adjust(now, 100)
+	if single.Get() != 1 {
+		t.Errorf("Autoscaler must keep at least one worker active, got %d", single.Get())
+	}
+}
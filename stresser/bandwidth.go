@@ -0,0 +1,66 @@
+package stresser
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// bandwidthChunk is the most bytes a throttled connection moves per reservation, so a
+// large write can't take a long stretch of the shared budget in one go.
+const bandwidthChunk = 32 * 1024
+
+// throttledConn paces the bytes read from and written to a connection with a token bucket
+// of one token per byte. The bucket is shared by all connections of the client, so it caps
+// their combined throughput, including request headers and TLS overhead.
+type throttledConn struct {
+	net.Conn
+	bucket *tokenBucket
+}
+
+// Read waits after the bytes arrived; the pause slows the reads that follow, and TCP flow
+// control slows the sender down to match.
+func (c *throttledConn) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunk {
+		p = p[:bandwidthChunk]
+	}
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		time.Sleep(c.bucket.reserve(float64(n)))
+	}
+	return n, err
+}
+
+func (c *throttledConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), bandwidthChunk)]
+		time.Sleep(c.bucket.reserve(float64(len(chunk))))
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// NetConn returns the underlying connection, like tls.Conn does.
+func (c *throttledConn) NetConn() net.Conn { return c.Conn }
+
+// newBandwidthLimiter returns a bucket for a limit in MiB/s.
+func newBandwidthLimiter(mibPerSec float64) *tokenBucket {
+	return newTokenBucket(mibPerSec*1024*1024, bandwidthChunk)
+}
+
+// throttledDialContext wraps the connections made by dial so they share bucket.
+func throttledDialContext(bucket *tokenBucket, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &throttledConn{Conn: conn, bucket: bucket}, nil
+	}
+}
@@ -0,0 +1,38 @@
+package stresser
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestThrottledConn(t *testing.T) {
+	bucket := newBandwidthLimiter(1) // 1 MiB/s
+	client, server := net.Pipe()
+	conn := &throttledConn{Conn: client, bucket: bucket}
+	defer conn.Close()
+
+	data := bytes.Repeat([]byte("x"), 256*1024)
+	received := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(server)
+		received <- b
+	}()
+
+	start := time.Now()
+	if n, err := conn.Write(data); err != nil || n != len(data) {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+	if got := <-received; !bytes.Equal(got, data) {
+		t.Fatalf("Received %d bytes, expected %d", len(got), len(data))
+	}
+
+	// 256 KiB at 1 MiB/s takes 250ms, less the initial burst of one chunk
+	if elapsed < 180*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Writing 256 KiB at 1 MiB/s took %v", elapsed)
+	}
+}
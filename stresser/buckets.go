@@ -0,0 +1,223 @@
+package stresser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Ways of spreading operations over multiple buckets (-bucket-select).
+const (
+	BucketSelectRoundRobin = "round-robin" // Each operation goes to the next bucket, across all workers
+	BucketSelectRandom     = "random"      // Each operation goes to a random bucket
+	BucketSelectHash       = "hash"        // Each key always goes to the same bucket, so reads find earlier writes
+)
+
+// maxBuckets bounds how many buckets a template may expand to.
+const maxBuckets = 10000
+
+// bucketRange matches the "{first..last}" range of a bucket template.
+var bucketRange = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// ParseBuckets parses a comma-separated bucket list as given to -buckets.
+func ParseBuckets(spec string) []string {
+	var buckets []string
+	for _, b := range strings.Split(spec, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			buckets = append(buckets, b)
+		}
+	}
+	return buckets
+}
+
+// expandBuckets expands bucket templates such as "bench-{0..15}" into one bucket per
+// number. A first number with leading zeros pads all numbers to its width, so
+// "bench-{00..15}" gives bench-00 to bench-15. Duplicates are rejected.
+func expandBuckets(patterns []string) ([]string, error) {
+	var buckets []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		names := []string{pattern}
+		if m := bucketRange.FindStringSubmatchIndex(pattern); m != nil {
+			firstText := pattern[m[2]:m[3]]
+			first, err1 := strconv.Atoi(firstText)
+			last, err2 := strconv.Atoi(pattern[m[4]:m[5]])
+			if err1 != nil || err2 != nil || last < first || last-first >= maxBuckets {
+				return nil, fmt.Errorf("invalid bucket range in %q: want {first..last} with first <= last and at most %d buckets", pattern, maxBuckets)
+			}
+			width := 0
+			if len(firstText) > 1 && firstText[0] == '0' {
+				width = len(firstText)
+			}
+			names = names[:0]
+			for n := first; n <= last; n++ {
+				names = append(names, pattern[:m[0]]+fmt.Sprintf("%0*d", width, n)+pattern[m[1]:])
+			}
+		}
+		for _, name := range names {
+			if strings.ContainsAny(name, "{}/ ") {
+				return nil, fmt.Errorf("invalid bucket name %q", name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("bucket %q is listed twice", name)
+			}
+			seen[name] = true
+			buckets = append(buckets, name)
+		}
+	}
+	if len(buckets) > maxBuckets {
+		return nil, fmt.Errorf("%d buckets given, at most %d are supported", len(buckets), maxBuckets)
+	}
+	return buckets, nil
+}
+
+// validateBuckets expands the bucket list of -buckets or the YAML buckets, and uses its
+// first bucket as Bucket when none is set. Called from Validate.
+func (c *Config) validateBuckets() error {
+	if c.BucketSpec != "" {
+		c.Buckets = ParseBuckets(c.BucketSpec)
+	}
+	if len(c.Buckets) > 0 {
+		buckets, err := expandBuckets(c.Buckets)
+		if err != nil {
+			return fmt.Errorf("%w (-buckets)", err)
+		}
+		c.Buckets = buckets
+		if c.Bucket == "" {
+			c.Bucket = buckets[0] // Where a single bucket is needed, e.g. in log messages
+		}
+	}
+	if c.Bucket == "" {
+		return fmt.Errorf("bucket name is required (set via -config file, S3_BUCKET env var or -buckets)")
+	}
+	switch c.BucketSelection {
+	case "":
+		c.BucketSelection = BucketSelectRoundRobin
+	case BucketSelectRoundRobin, BucketSelectRandom, BucketSelectHash:
+	default:
+		return fmt.Errorf("invalid bucket selection (-bucket-select): %s. Must be 'round-robin', 'random' or 'hash'", c.BucketSelection)
+	}
+	return nil
+}
+
+// bucketPicker chooses the bucket of each operation. It is shared by all workers.
+type bucketPicker struct {
+	buckets   []string
+	selection string
+	next      atomic.Uint64 // Round-robin position
+}
+
+// newBucketPicker returns a picker over the buckets of cfg: the single Bucket, or Buckets
+// when set.
+func newBucketPicker(cfg *Config) *bucketPicker {
+	if len(cfg.Buckets) == 0 {
+		return &bucketPicker{buckets: []string{cfg.Bucket}}
+	}
+	return &bucketPicker{buckets: cfg.Buckets, selection: cfg.BucketSelection}
+}
+
+// multiple reports whether operations are spread over more than one bucket.
+func (p *bucketPicker) multiple() bool {
+	return len(p.buckets) > 1
+}
+
+// pick returns the bucket for an operation on key. Operations without a key yet, such as
+// writes to a generated key, pass the key they are about to use.
+func (p *bucketPicker) pick(r *rand.Rand, key string) string {
+	if len(p.buckets) == 1 {
+		return p.buckets[0]
+	}
+	switch p.selection {
+	case BucketSelectRandom:
+		return p.buckets[r.Intn(len(p.buckets))]
+	case BucketSelectHash:
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return p.buckets[h.Sum32()%uint32(len(p.buckets))]
+	}
+	return p.buckets[(p.next.Add(1)-1)%uint64(len(p.buckets))]
+}
+
+// BucketStats aggregates the results of the requests sent to one bucket.
+type BucketStats struct {
+	Bucket        string  `json:"bucket" yaml:"bucket"`
+	Requests      int64   `json:"requests" yaml:"requests"`
+	Errors        int64   `json:"errors" yaml:"errors"`
+	Bytes         int64   `json:"bytes" yaml:"bytes"` // Bytes transferred in either direction by successful requests
+	ThroughputMiB float64 `json:"throughputMiBps" yaml:"throughputMiBps"`
+	P50TTLBMs     float64 `json:"p50TtlbMs" yaml:"p50TtlbMs"`
+	P99TTLBMs     float64 `json:"p99TtlbMs" yaml:"p99TtlbMs"`
+	ttlbs         *Histogram
+}
+
+// addBucketResult records r against its bucket. Called from AddResult; results of
+// single-bucket runs carry no bucket and are skipped.
+func (s *Stats) addBucketResult(r Result) {
+	if r.Bucket == "" {
+		return
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[string]*BucketStats)
+	}
+	bs, ok := s.buckets[r.Bucket]
+	if !ok {
+		bs = &BucketStats{Bucket: r.Bucket, ttlbs: NewHistogram()}
+		s.buckets[r.Bucket] = bs
+	}
+	bs.Requests++
+	if r.Error != "" {
+		bs.Errors++
+		return
+	}
+	bs.Bytes += r.BytesDownloaded + r.BytesUploaded
+	bs.ttlbs.Record(r.TTLB)
+}
+
+// calculateBucketStats computes per-bucket throughput and latency. Called from Calculate.
+func (s *Stats) calculateBucketStats() {
+	secs := s.actualDuration.Seconds()
+	for _, bs := range s.buckets {
+		if secs > 0 {
+			bs.ThroughputMiB = float64(bs.Bytes) / (1024 * 1024) / secs
+		}
+		if bs.ttlbs.Count() > 0 {
+			bs.P50TTLBMs = ms(bs.ttlbs.Percentile(50))
+			bs.P99TTLBMs = ms(bs.ttlbs.Percentile(99))
+		}
+	}
+}
+
+// BucketStats returns the per-bucket aggregates ordered by bucket name, nil for
+// single-bucket runs.
+func (s *Stats) BucketStats() []*BucketStats {
+	if len(s.buckets) == 0 {
+		return nil
+	}
+	list := make([]*BucketStats, 0, len(s.buckets))
+	for _, bs := range s.buckets {
+		list = append(list, bs)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Bucket < list[j].Bucket })
+	return list
+}
+
+// printBucketSummary prints per-bucket figures as part of PrintSummary, so a bucket that
+// is throttled or slower than the others stands out.
+func (s *Stats) printBucketSummary(w io.Writer) {
+	if len(s.buckets) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nBuckets (%d):\n", len(s.buckets))
+	fmt.Fprintf(w, "  Requests | Errors |  MiB/s  | P50 (ms) | P99 (ms) | Bucket\n")
+	fmt.Fprintf(w, "  ---------|--------|---------|----------|----------|--------\n")
+	for _, bs := range s.BucketStats() {
+		fmt.Fprintf(w, "  %8d | %6d | %7.2f | %8.2f | %8.2f | %s\n",
+			bs.Requests, bs.Errors, bs.ThroughputMiB, bs.P50TTLBMs, bs.P99TTLBMs, bs.Bucket)
+	}
+}
@@ -0,0 +1,125 @@
+package stresser
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandBuckets(t *testing.T) {
+	buckets, err := expandBuckets(ParseBuckets(" logs , bench-{8..10}, pad-{08..10}-x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "logs,bench-8,bench-9,bench-10,pad-08-x,pad-09-x,pad-10-x"
+	if got := strings.Join(buckets, ","); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	for _, patterns := range [][]string{
+		{"a", "a"},
+		{"bench-{0..3}", "bench-2"},
+		{"bench-{3..1}"},
+		{"bench-{0..20000}"},
+		{"bench-{a..b}"},
+		{"path/bucket"},
+	} {
+		if _, err := expandBuckets(patterns); err == nil {
+			t.Errorf("Expected an error for %q", patterns)
+		}
+	}
+}
+
+func TestBucketPicker(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	single := newBucketPicker(&Config{Bucket: "only"})
+	if single.multiple() || single.pick(r, "k") != "only" {
+		t.Error("Expected a single-bucket picker to always return the bucket")
+	}
+
+	cfg := &Config{Bucket: "a", Buckets: []string{"a", "b", "c"}, BucketSelection: BucketSelectRoundRobin}
+	p := newBucketPicker(cfg)
+	var got []string
+	for range 4 {
+		got = append(got, p.pick(r, "k"))
+	}
+	if strings.Join(got, ",") != "a,b,c,a" {
+		t.Errorf("Expected round-robin order, got %v", got)
+	}
+
+	cfg.BucketSelection = BucketSelectHash
+	p = newBucketPicker(cfg)
+	first := p.pick(r, "some/key")
+	for range 10 {
+		if b := p.pick(r, "some/key"); b != first {
+			t.Fatalf("Expected the key to stay in %s, got %s", first, b)
+		}
+	}
+
+	cfg.BucketSelection = BucketSelectRandom
+	p = newBucketPicker(cfg)
+	seen := make(map[string]bool)
+	for range 100 {
+		seen[p.pick(r, "k")] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected random picks to reach every bucket, got %v", seen)
+	}
+}
+
+func TestValidateBuckets(t *testing.T) {
+	base := Config{Endpoint: "https://test-endpoint.com", Region: "us-east-1", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "read"}
+
+	cfg := base
+	cfg.Buckets = []string{"yaml-bucket"}
+	cfg.BucketSpec = "bench-{0..3}"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(cfg.Buckets) != 4 || cfg.Bucket != "bench-0" || cfg.BucketSelection != BucketSelectRoundRobin {
+		t.Errorf("Unexpected buckets %v, bucket %q, selection %q", cfg.Buckets, cfg.Bucket, cfg.BucketSelection)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"no bucket":          func(c *Config) {},
+		"invalid selection":  func(c *Config) { c.BucketSpec = "a,b"; c.BucketSelection = "sticky" },
+		"duplicate buckets":  func(c *Config) { c.BucketSpec = "a,a" },
+		"versioned multiple": func(c *Config) { c.BucketSpec = "a,b"; c.OperationType = "versioned" },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestBucketStats(t *testing.T) {
+	start := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", Bucket: "a", BytesDownloaded: 1024 * 1024, TTFB: time.Millisecond, TTLB: 2 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", Bucket: "b", BytesDownloaded: 1024 * 1024, TTFB: time.Millisecond, TTLB: 4 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", Bucket: "b", Error: "SlowDown"})
+	stats.Calculate(start, start.Add(time.Second))
+
+	list := stats.BucketStats()
+	if len(list) != 2 || list[0].Bucket != "a" || list[1].Requests != 2 || list[1].Errors != 1 || list[1].ThroughputMiB != 1 {
+		t.Fatalf("Unexpected bucket stats %+v", list)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Buckets (2):") {
+		t.Errorf("Summary missing bucket section:\n%s", buf.String())
+	}
+	if sum := stats.Summary(); len(sum.Buckets) != 2 {
+		t.Errorf("Expected 2 buckets in the summary, got %+v", sum.Buckets)
+	}
+
+	single := NewStats()
+	single.AddResult(Result{Timestamp: start, Operation: "GET", TTLB: time.Millisecond})
+	if single.BucketStats() != nil {
+		t.Error("Expected no bucket stats for a single-bucket run")
+	}
+}
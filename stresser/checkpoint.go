@@ -0,0 +1,104 @@
+package stresser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// resultStream writes detailed results to the output as they are collected instead of
+// keeping them in memory until the end of the run. The collector flushes the buffered results
+// to disk every checkpoint interval, whether or not results keep coming, so a crashed or
+// killed run loses at most one interval. Results are written from the collecting goroutine
+// only, so it needs no locking.
+type resultStream struct {
+	path     string
+	format   string
+	out      io.WriteCloser
+	buf      *bufio.Writer
+	csv      *csv.Writer   // FormatCSV
+	json     *json.Encoder // FormatJSONL and FormatJSON
+	interval time.Duration // Time between checkpoints
+	written  int
+}
+
+// newResultStream creates the output at path and writes the CSV header. The optional CSV
+// columns are always included, as it isn't known up front which results will need them.
+func newResultStream(path, format string, interval time.Duration) (*resultStream, error) {
+	out, err := OpenOutput(path)
+	if err != nil {
+		return nil, err
+	}
+	rs := &resultStream{path: path, format: format, out: out, buf: bufio.NewWriter(out), interval: interval}
+	switch format {
+	case FormatCSV:
+		rs.csv = csv.NewWriter(rs.buf)
+		if err := rs.csv.Write(allCSVColumns.header()); err != nil {
+			out.Close()
+			return nil, fmt.Errorf("failed to write csv header: %w", err)
+		}
+	case FormatJSONL, FormatJSON:
+		rs.json = json.NewEncoder(rs.buf)
+	default:
+		out.Close()
+		return nil, fmt.Errorf("results can't be checkpointed in %s format", format)
+	}
+	return rs, nil
+}
+
+// write appends r to the buffer, which checkpoint puts on disk.
+func (rs *resultStream) write(r Result) error {
+	if rs.csv != nil {
+		if err := rs.csv.Write(allCSVColumns.row(r)); err != nil {
+			return fmt.Errorf("failed to write csv row: %w", err)
+		}
+	} else if err := rs.json.Encode(NewResultRecord(r)); err != nil {
+		return fmt.Errorf("failed to write jsonl record: %w", err)
+	}
+	rs.written++
+	return nil
+}
+
+// checkpoint flushes the buffered results and syncs them to disk.
+func (rs *resultStream) checkpoint() error {
+	if rs.csv != nil {
+		rs.csv.Flush()
+		if err := rs.csv.Error(); err != nil {
+			return fmt.Errorf("failed to flush csv results: %w", err)
+		}
+	}
+	if err := rs.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to flush results to %s: %w", rs.path, err)
+	}
+	if file, ok := rs.out.(*os.File); ok {
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync results to %s: %w", rs.path, err)
+		}
+	}
+	return nil
+}
+
+// close writes the remaining results, followed by the summary line for FormatJSON if s is
+// not nil, and closes the output.
+func (rs *resultStream) close(s *Stats) error {
+	if rs.format == FormatJSON && s != nil {
+		if err := encodeSummaryLine(rs.buf, s); err != nil {
+			rs.out.Close()
+			return err
+		}
+	}
+	if err := rs.checkpoint(); err != nil {
+		rs.out.Close()
+		return err
+	}
+	if err := rs.out.Close(); err != nil {
+		return fmt.Errorf("failed to close output file %s: %w", rs.path, err)
+	}
+	slog.Info("Detailed results written", "file", rs.path, "format", rs.format, "count", rs.written)
+	return nil
+}
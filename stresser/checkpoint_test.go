@@ -0,0 +1,137 @@
+package stresser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResultStream(t *testing.T) {
+	start := time.Now()
+	results := []Result{
+		{Timestamp: start, Operation: "GET", ObjectKey: "a", BytesDownloaded: 1024, TTFB: time.Millisecond, TTLB: 2 * time.Millisecond},
+		{Timestamp: start.Add(time.Millisecond), Operation: "PUT", ObjectKey: "b", Bucket: "b2", BytesUploaded: 2048, TTFB: -1, TTLB: 5 * time.Millisecond},
+	}
+	stats := NewStats()
+	for _, r := range results {
+		stats.AddResult(r)
+	}
+	stats.Calculate(start, start.Add(time.Second))
+
+	for _, format := range []string{FormatCSV, FormatJSONL, FormatJSON} {
+		path := filepath.Join(t.TempDir(), "results."+format)
+		rs, err := newResultStream(path, format, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rs.write(results[0]); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); strings.Contains(string(data), "GET") {
+			t.Errorf("%s: expected the first result to be buffered until the checkpoint", format)
+		}
+		if err := rs.checkpoint(); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); !strings.Contains(string(data), "GET") {
+			t.Errorf("%s: expected the first result to be checkpointed, got %q", format, data)
+		}
+		if err := rs.write(results[1]); err != nil {
+			t.Fatal(err)
+		}
+		if err := rs.close(stats); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadResults(path, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(loaded) != 2 || loaded[1].Bucket != "b2" || loaded[1].BytesUploaded != 2048 {
+			t.Errorf("%s: unexpected results %+v", format, loaded)
+		}
+		data, _ := os.ReadFile(path)
+		if hasSummary := strings.Contains(string(data), `"summary"`); hasSummary != (format == FormatJSON) {
+			t.Errorf("%s: summary line present: %v", format, hasSummary)
+		}
+	}
+
+	if _, err := newResultStream(filepath.Join(t.TempDir(), "results.sql"), FormatSQL, time.Second); err == nil {
+		t.Error("Expected an error for a format that can't be streamed")
+	}
+}
+
+func TestValidateCheckpoint(t *testing.T) {
+	base := Config{Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.jsonl", OperationType: "read", Checkpoint: "10s"}
+
+	cfg := base
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for name, mutate := range map[string]func(*Config){
+		"invalid interval": func(c *Config) { c.Checkpoint = "soon" },
+		"zero interval":    func(c *Config) { c.Checkpoint = "0s" },
+		"sql output":       func(c *Config) { c.OutputFormat = FormatSQL },
+		"sweep":            func(c *Config) { c.ConcurrencySweep = "1,2" },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestRunCheckpointsWithoutNewResults(t *testing.T) {
+	release := make(chan struct{}) // Holds every PUT after the first until the test ends
+	var puts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if puts.Add(1) > 1 {
+			<-release
+		}
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+	defer close(release)
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	cfg := &Config{
+		Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret",
+		Duration: "1s", Concurrency: 1, OutputFile: path, OutputFormat: FormatJSONL, OperationType: "write",
+		PutObjectSizeKB: 1, Checkpoint: "10ms",
+	}
+	runner, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := runner.Run(context.Background())
+		done <- err
+	}()
+
+	// The first result must reach the disk while the second PUT hangs
+	deadline := time.Now().Add(500 * time.Millisecond) // Well before the run ends
+	for {
+		if data, _ := os.ReadFile(path); strings.Contains(string(data), `"PUT"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first result to be checkpointed while no further results arrived")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ChecksumNone turns off the checksums the SDK adds by default (Config.Checksum).
+const ChecksumNone = "none"
+
+// checksumAlgorithms are the additional checksums -checksum accepts.
+var checksumAlgorithms = map[string]types.ChecksumAlgorithm{
+	"crc32":     types.ChecksumAlgorithmCrc32,
+	"crc32c":    types.ChecksumAlgorithmCrc32c,
+	"crc64nvme": types.ChecksumAlgorithmCrc64nvme,
+	"sha1":      types.ChecksumAlgorithmSha1,
+	"sha256":    types.ChecksumAlgorithmSha256,
+}
+
+// Checksum outcomes of a GET, stored in Result.Checksum.
+const (
+	ChecksumOK       = "ok"       // Body matched the x-amz-checksum-* of the response
+	ChecksumMismatch = "mismatch" // Body did not match it
+)
+
+// checksumMismatch is how the SDK reports a body that does not match its checksum header.
+const checksumMismatch = "checksum did not match"
+
+// validateChecksum checks -checksum. Called from Validate.
+func (c *Config) validateChecksum() error {
+	c.Checksum = strings.ToLower(c.Checksum)
+	if c.Checksum == "" {
+		return nil
+	}
+	if _, ok := checksumAlgorithms[c.Checksum]; !ok && c.Checksum != ChecksumNone {
+		return fmt.Errorf("invalid checksum (-checksum): %s. Must be 'crc32', 'crc32c', 'crc64nvme', 'sha1', 'sha256' or 'none'", c.Checksum)
+	}
+	if c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support -checksum")
+	}
+	if c.Presign {
+		return fmt.Errorf("presigned requests (-presign) cannot carry additional checksums (-checksum)")
+	}
+	return nil
+}
+
+// withChecksums returns an SDK API option that has the SDK compute the checksum of every
+// PUT body with algorithm, which the store checks and stores with the object, and ask for
+// the stored checksum with every GET, which the SDK checks the body against as it is read.
+// The parameters are set first thing, before the SDK's checksum middleware looks at them.
+func withChecksums(algorithm types.ChecksumAlgorithm) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("StresserChecksums",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				switch p := in.Parameters.(type) {
+				case *s3.PutObjectInput:
+					params := *p
+					params.ChecksumAlgorithm = algorithm
+					in.Parameters = &params
+				case *s3.GetObjectInput:
+					params := *p
+					params.ChecksumMode = types.ChecksumModeEnabled
+					in.Parameters = &params
+				}
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
+
+// checksumOptions applies -checksum to the options of the SDK client.
+func checksumOptions(checksum string, o *s3.Options) {
+	if checksum == ChecksumNone {
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	}
+}
+
+// responseChecksum returns ChecksumOK if a GET response carried a checksum of the whole
+// object, which the SDK verified while the body was read. Checksums of multipart objects
+// cover the parts ("<checksum>-<parts>") and are not verified.
+func responseChecksum(resp *s3.GetObjectOutput) string {
+	for _, sum := range []*string{resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumCRC64NVME, resp.ChecksumSHA1, resp.ChecksumSHA256} {
+		if v := aws.ToString(sum); v != "" && !strings.Contains(v, "-") {
+			return ChecksumOK
+		}
+	}
+	return ""
+}
+
+// ChecksumReport summarizes GETs whose body was checked against the store's checksum.
+type ChecksumReport struct {
+	Validated  int64 `json:"validated" yaml:"validated"`   // GETs whose body matched the checksum
+	Mismatched int64 `json:"mismatched" yaml:"mismatched"` // GETs whose body did not match, also counted as errors
+}
+
+// addChecksumResult counts checksum outcomes. Called from AddResult.
+func (s *Stats) addChecksumResult(r Result) {
+	switch r.Checksum {
+	case ChecksumOK:
+		s.checksums.Validated++
+	case ChecksumMismatch:
+		s.checksums.Mismatched++
+	}
+}
+
+// Checksums returns the checksum outcomes, or nil if no GET body was checked.
+func (s *Stats) Checksums() *ChecksumReport {
+	if s.checksums == (ChecksumReport{}) {
+		return nil
+	}
+	report := s.checksums
+	return &report
+}
+
+// printChecksumSummary prints checksum outcomes as part of PrintSummary.
+func (s *Stats) printChecksumSummary(w io.Writer) {
+	cr := s.Checksums()
+	if cr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nAdditional Checksums:\n")
+	fmt.Fprintf(w, "  Validated GETs: %d\n", cr.Validated)
+	fmt.Fprintf(w, "  Mismatched:     %d\n", cr.Mismatched)
+	if unchecked := s.GetTTLBHist.Count() - cr.Validated; unchecked > 0 {
+		fmt.Fprintf(w, "  Unchecked GETs: %d (no checksum of the whole object, e.g. multipart uploads)\n", unchecked)
+	}
+	if cr.Mismatched > 0 {
+		fmt.Fprintf(w, "  WARNING: %d GETs returned a body that does not match the store's checksum\n", cr.Mismatched)
+	}
+}
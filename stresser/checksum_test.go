@@ -0,0 +1,113 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidateChecksum(t *testing.T) {
+	for _, value := range []string{"", "crc32c", "SHA256", "none"} {
+		c := &Config{Checksum: value}
+		if err := c.validateChecksum(); err != nil {
+			t.Errorf("validateChecksum(%q) failed: %v", value, err)
+		}
+		if c.Checksum != strings.ToLower(value) {
+			t.Errorf("Expected %q to be lowercased, got %q", value, c.Checksum)
+		}
+	}
+	for _, c := range []*Config{
+		{Checksum: "md5"},
+		{Checksum: "crc32c", Backend: BackendGCS},
+		{Checksum: "sha256", Presign: true},
+	} {
+		if err := c.validateChecksum(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+}
+
+// checksumServer stores PUT bodies with their CRC32C header and returns it to GETs that
+// ask for checksums. The body of key "corrupt" is altered after it was stored.
+func checksumServer(t *testing.T) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	sums := make(map[string]string)
+	var seen []string // Checksum headers of the requests, e.g. "PUT crc32c=..."
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		for name := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-checksum-") || strings.EqualFold(name, "x-amz-sdk-checksum-algorithm") {
+				seen = append(seen, r.Method+" "+strings.ToLower(name))
+			}
+		}
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			if strings.HasSuffix(r.URL.Path, "/corrupt") {
+				body[0] ^= 0xff
+			}
+			objects[r.URL.Path] = body
+			sums[r.URL.Path] = r.Header.Get("X-Amz-Checksum-Crc32c")
+			w.Header().Set("ETag", `"etag"`)
+		case http.MethodGet:
+			if sum := sums[r.URL.Path]; sum != "" && r.Header.Get("X-Amz-Checksum-Mode") == "ENABLED" {
+				w.Header().Set("X-Amz-Checksum-Crc32c", sum)
+			}
+			w.Write(objects[r.URL.Path])
+		}
+	}))
+	return server, &seen
+}
+
+func TestChecksums(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	server, seen := checksumServer(t)
+	defer server.Close()
+	data := []byte(strings.Repeat("checksummed ", 1000))
+
+	for _, checksum := range []string{"crc32c", ChecksumNone} {
+		*seen = nil
+		cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", RunID: "test", Checksum: checksum}
+		client, err := NewS3Client(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("NewS3Client failed: %v", err)
+		}
+		stats := NewStats()
+		for _, key := range []string{"good", "corrupt"} {
+			if put := performPutOperation(context.Background(), client, cfg.Bucket, key, bytes.NewReader(data), false, putAttributes{}); put.Error != "" {
+				t.Fatalf("PUT failed: %s", put.Error)
+			}
+			stats.AddResult(performGetOperation(context.Background(), client, cfg.Bucket, key, false))
+		}
+		stats.Calculate(time.Now().Add(-time.Second), time.Now())
+
+		if checksum == ChecksumNone {
+			if len(*seen) > 0 || stats.Checksums() != nil {
+				t.Errorf("Expected no checksums with -checksum none, sent %v and got %+v", *seen, stats.Checksums())
+			}
+			continue
+		}
+		if !strings.Contains(strings.Join(*seen, ","), "PUT x-amz-checksum-crc32c") || !strings.Contains(strings.Join(*seen, ","), "GET x-amz-checksum-mode") {
+			t.Errorf("Expected a CRC32C with the PUTs and the checksum mode with the GETs, sent %v", *seen)
+		}
+		if cr := stats.Checksums(); cr == nil || cr.Validated != 1 || cr.Mismatched != 1 {
+			t.Errorf("Expected a validated and a mismatched GET, got %+v", cr)
+		}
+		if classes := stats.ErrorClasses(); len(classes) != 1 || classes[0].Class != ErrClassIntegrity {
+			t.Errorf("Expected the mismatch to be an integrity error, got %+v", classes)
+		}
+		var buf bytes.Buffer
+		stats.PrintSummary(&buf)
+		if !strings.Contains(buf.String(), "Additional Checksums:") || !strings.Contains(buf.String(), "Mismatched:     1") {
+			t.Errorf("Summary lacks the checksum outcomes:\n%s", buf.String())
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DefaultCleanupBatchSize is the number of keys removed per DeleteObjects call, the S3 maximum.
+const DefaultCleanupBatchSize = 1000
+
+// cleanupClient is the part of the S3 API cleanup uses.
+type cleanupClient interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+}
+
+// CleanupOptions selects what 'ostresser cleanup' deletes and how.
+type CleanupOptions struct {
+	Prefix    string        // Delete every key under this prefix instead of the keys in Config.ManifestPath
+	BatchSize int           // Keys per DeleteObjects call (default: DefaultCleanupBatchSize)
+	Workers   int           // DeleteObjects calls in flight (default: 1)
+	Progress  time.Duration // Log progress this often (0 disables)
+}
+
+// CleanupReport summarizes a cleanup.
+type CleanupReport struct {
+	Deleted  int64
+	Failed   int64
+	Duration time.Duration
+}
+
+// RunCleanup deletes the objects a write run left behind: the keys of the manifest it
+// generated, or all keys under a prefix. Keys are removed in batches with DeleteObjects;
+// keys that could not be deleted are logged and counted, and do not stop the cleanup.
+func RunCleanup(ctx context.Context, cfg *Config, opts CleanupOptions) (CleanupReport, error) {
+	client, closeClient, err := newBucketClient(ctx, cfg)
+	if err != nil {
+		return CleanupReport{}, err
+	}
+	defer closeClient()
+
+	var keys []string
+	if opts.Prefix == "" {
+		if keys, err = LoadManifest(cfg.ManifestPath); err != nil {
+			return CleanupReport{}, err
+		}
+		slog.Info("Deleting the objects in the manifest", "count", len(keys), "path", cfg.ManifestPath, "bucket", cfg.Bucket)
+	} else {
+		slog.Info("Deleting all objects under prefix", "prefix", opts.Prefix, "bucket", cfg.Bucket)
+	}
+	return cleanup(ctx, client, cfg.Bucket, keys, opts)
+}
+
+// newBucketClient connects to the bucket of cfg for the commands that work on the bucket as
+// a whole, cleanup and scan. The returned function closes the client.
+func newBucketClient(ctx context.Context, cfg *Config) (cleanupClient, func(), error) {
+	if cfg.Backend != BackendGCS && cfg.Endpoint == "" {
+		return nil, nil, fmt.Errorf("endpoint URL is required (set via -config file, AWS_ENDPOINT_URL env var)")
+	}
+	if cfg.Bucket == "" {
+		return nil, nil, fmt.Errorf("bucket name is required (set via -config file, S3_BUCKET env var)")
+	}
+	if cfg.Backend == BackendGCS {
+		gcsClient, err := NewGCSClient(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gcsClient, func() { gcsClient.Close() }, nil
+	}
+	s3Client, err := NewS3Client(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return s3Client, func() {}, nil
+}
+
+// cleanup deletes keys, or with opts.Prefix set, the keys listed under it.
+func cleanup(ctx context.Context, client cleanupClient, bucket string, keys []string, opts CleanupOptions) (CleanupReport, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCleanupBatchSize
+	}
+	workers := max(opts.Workers, 1)
+
+	start := time.Now()
+	var deleted, failed atomic.Int64
+	batches := make(chan []string)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				n, err := deleteBatch(ctx, client, bucket, batch)
+				deleted.Add(int64(n))
+				failed.Add(int64(len(batch) - n))
+				if err != nil {
+					slog.Warn("DeleteObjects failed", "keys", len(batch), "firstKey", batch[0], "error", err)
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	if opts.Progress > 0 {
+		go func() {
+			ticker := time.NewTicker(opts.Progress)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					d := deleted.Load()
+					slog.Info("Cleanup progress", "deleted", d, "failed", failed.Load(),
+						"objectsPerSec", fmt.Sprintf("%.1f", float64(d)/time.Since(start).Seconds()))
+				}
+			}
+		}()
+	}
+
+	var err error
+	if opts.Prefix == "" {
+		for i := 0; i < len(keys) && ctx.Err() == nil; i += batchSize {
+			select {
+			case batches <- keys[i:min(i+batchSize, len(keys))]:
+			case <-ctx.Done():
+			}
+		}
+	} else {
+		err = listBatches(ctx, client, bucket, opts.Prefix, batchSize, batches)
+	}
+	close(batches)
+	wg.Wait()
+	close(done)
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return CleanupReport{Deleted: deleted.Load(), Failed: failed.Load(), Duration: time.Since(start)}, err
+}
+
+// listBatches lists the keys under prefix and sends them in batches of up to batchSize.
+func listBatches(ctx context.Context, client cleanupClient, bucket, prefix string, batchSize int, batches chan<- []string) error {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(prefix), MaxKeys: aws.Int32(int32(batchSize))}
+	for {
+		resp, err := client.ListObjectsV2(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to list keys under %q: %w", prefix, err)
+		}
+		if len(resp.Contents) > 0 {
+			batch := make([]string, len(resp.Contents))
+			for i, o := range resp.Contents {
+				batch[i] = aws.ToString(o.Key)
+			}
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if !aws.ToBool(resp.IsTruncated) {
+			return nil
+		}
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
+
+// deleteBatch removes keys with one DeleteObjects call and returns how many were deleted.
+func deleteBatch(ctx context.Context, client cleanupClient, bucket string, keys []string) (int, error) {
+	objects := make([]types.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+	}
+	resp, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range resp.Errors {
+		slog.Warn("Failed to delete object", "key", aws.ToString(e.Key), "code", aws.ToString(e.Code), "message", aws.ToString(e.Message))
+	}
+	return len(keys) - len(resp.Errors), nil
+}
@@ -0,0 +1,111 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// deletingS3Client holds a set of keys, lists them and deletes them in batches.
+type deletingS3Client struct {
+	mu      sync.Mutex
+	keys    map[string]bool
+	batches []int
+	failKey string // Reported as not deleted
+}
+
+func newDeletingS3Client(keys []string) *deletingS3Client {
+	c := &deletingS3Client{keys: make(map[string]bool)}
+	for _, k := range keys {
+		c.keys[k] = true
+	}
+	return c
+}
+
+func (c *deletingS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var keys []string
+	for k := range c.keys {
+		if strings.HasPrefix(k, aws.ToString(params.Prefix)) && k > aws.ToString(params.ContinuationToken) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	out := &s3.ListObjectsV2Output{}
+	if n := int(aws.ToInt32(params.MaxKeys)); len(keys) > n {
+		keys = keys[:n]
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(keys[n-1])
+	}
+	for _, k := range keys {
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(k)})
+	}
+	return out, nil
+}
+
+func (c *deletingS3Client) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, len(params.Delete.Objects))
+	out := &s3.DeleteObjectsOutput{}
+	for _, o := range params.Delete.Objects {
+		if aws.ToString(o.Key) == c.failKey {
+			out.Errors = append(out.Errors, types.Error{Key: o.Key, Code: aws.String("AccessDenied")})
+			continue
+		}
+		delete(c.keys, aws.ToString(o.Key))
+	}
+	return out, nil
+}
+
+func cleanupKeys(prefix string, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%04d.dat", prefix, i)
+	}
+	return keys
+}
+
+func TestCleanupManifestKeys(t *testing.T) {
+	keys := cleanupKeys("stresser/generated/", 25)
+	client := newDeletingS3Client(append(keys, "keep/me.dat"))
+	client.failKey = keys[3]
+
+	report, err := cleanup(context.Background(), client, "b", keys, CleanupOptions{BatchSize: 10, Workers: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Deleted != 24 || report.Failed != 1 {
+		t.Errorf("report = %+v, want 24 deleted and 1 failed", report)
+	}
+	sort.Ints(client.batches)
+	if fmt.Sprint(client.batches) != "[5 10 10]" {
+		t.Errorf("batch sizes = %v, want [5 10 10]", client.batches)
+	}
+	if len(client.keys) != 2 || !client.keys["keep/me.dat"] || !client.keys[keys[3]] {
+		t.Errorf("remaining keys = %v", client.keys)
+	}
+}
+
+func TestCleanupPrefix(t *testing.T) {
+	client := newDeletingS3Client(append(cleanupKeys("bench/", 23), cleanupKeys("other/", 5)...))
+
+	report, err := cleanup(context.Background(), client, "b", nil, CleanupOptions{Prefix: "bench/", BatchSize: 10, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Deleted != 23 || report.Failed != 0 {
+		t.Errorf("report = %+v, want 23 deleted", report)
+	}
+	if len(client.keys) != 5 {
+		t.Errorf("%d keys left, want the 5 outside the prefix", len(client.keys))
+	}
+}
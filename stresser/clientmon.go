@@ -0,0 +1,223 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Thresholds above which the client itself is considered the bottleneck.
+const (
+	clientCPUWarnPercent = 90.0
+	clientGCWarnPercent  = 10.0
+	connChurnFactor      = 2 // Warn when more than this many connections per worker were opened
+	clientSampleInterval = time.Second
+)
+
+// connectionStats counts connections dialed by the S3 HTTP transport.
+// It is process-wide because the transport outlives a single test run (e.g. in sweeps).
+type connectionStats struct {
+	open       atomic.Int64 // Currently open connections
+	peak       atomic.Int64 // Highest number of simultaneously open connections
+	opened     atomic.Int64 // Total connections successfully dialed
+	dialErrors atomic.Int64 // Failed dial attempts (socket errors)
+}
+
+var connStats connectionStats
+
+// trackedConn decrements the open connection count exactly once when closed.
+// It also carries the age and usage that connection recycling is based on.
+type trackedConn struct {
+	net.Conn
+	once     sync.Once
+	created  time.Time
+	requests atomic.Int64 // Requests sent on this connection, counted by recyclingTransport
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() { connStats.open.Add(-1) })
+	return c.Conn.Close()
+}
+
+// trackingDialContext wraps a dial function so every connection is counted in connStats.
+func trackingDialContext(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			connStats.dialErrors.Add(1)
+			return nil, err
+		}
+		connStats.opened.Add(1)
+		open := connStats.open.Add(1)
+		for {
+			peak := connStats.peak.Load()
+			if open <= peak || connStats.peak.CompareAndSwap(peak, open) {
+				break
+			}
+		}
+		return &trackedConn{Conn: conn, created: time.Now()}, nil
+	}
+}
+
+// ClientReport summarizes load generator health during a run.
+type ClientReport struct {
+	CPUAvailable  bool          // False if process CPU time can't be read on this platform
+	GOMAXPROCS    int           // Cores the Go scheduler used during the run
+	LockedThreads int           // Size of the pool of locked OS threads the operations ran on (-lock-threads), 0 without
+	AvgCPUPercent float64       // Average CPU use as a percentage of GOMAXPROCS cores
+	MaxCPUPercent float64       // Highest per-interval CPU use
+	NumGC         uint32        // Garbage collections during the run
+	GCPauseTotal  time.Duration // Total stop-the-world GC pause time during the run
+	GCCPUPercent  float64       // Share of CPU spent in GC since process start
+	PeakOpenConns int64         // Highest number of simultaneously open connections
+	ConnsOpened   int64         // Connections dialed during the run
+	DialErrors    int64         // Failed connection attempts during the run
+	Warnings      []string      // Reasons the results are likely client-limited
+}
+
+// clientMonitor samples process CPU usage while a test runs.
+type clientMonitor struct {
+	stop     chan struct{}
+	done     chan struct{}
+	start    time.Time
+	startCPU time.Duration
+	startMem runtime.MemStats
+	opened   int64
+	dialErrs int64
+	cpuOK    bool
+	samples  []float64 // CPU percent per interval
+}
+
+// startClientMonitor begins sampling CPU usage every interval until Stop is called.
+func startClientMonitor(interval time.Duration) *clientMonitor {
+	m := &clientMonitor{
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		start:    time.Now(),
+		opened:   connStats.opened.Load(),
+		dialErrs: connStats.dialErrors.Load(),
+	}
+	runtime.ReadMemStats(&m.startMem)
+	m.startCPU, m.cpuOK = processCPUTime()
+	connStats.peak.Store(connStats.open.Load())
+
+	go m.loop(interval)
+	return m
+}
+
+func (m *clientMonitor) loop(interval time.Duration) {
+	defer close(m.done)
+	if !m.cpuOK {
+		<-m.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastWall, lastCPU := m.start, m.startCPU
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			cpu, ok := processCPUTime()
+			if !ok {
+				continue
+			}
+			m.samples = append(m.samples, cpuPercent(cpu-lastCPU, now.Sub(lastWall)))
+			lastWall, lastCPU = now, cpu
+		}
+	}
+}
+
+// cpuPercent converts CPU time used over a wall-clock interval into a percentage of available cores.
+func cpuPercent(cpu, wall time.Duration) float64 {
+	if wall <= 0 {
+		return 0
+	}
+	return float64(cpu) / float64(wall) / float64(runtime.GOMAXPROCS(0)) * 100
+}
+
+// Stop ends sampling and evaluates the run made with cfg.
+func (m *clientMonitor) Stop(cfg *Config) ClientReport {
+	close(m.stop)
+	<-m.done
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	report := ClientReport{
+		CPUAvailable:  m.cpuOK,
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumGC:         mem.NumGC - m.startMem.NumGC,
+		GCPauseTotal:  time.Duration(mem.PauseTotalNs - m.startMem.PauseTotalNs),
+		GCCPUPercent:  mem.GCCPUFraction * 100,
+		PeakOpenConns: connStats.peak.Load(),
+		ConnsOpened:   connStats.opened.Load() - m.opened,
+		DialErrors:    connStats.dialErrors.Load() - m.dialErrs,
+	}
+
+	if m.cpuOK {
+		if cpu, ok := processCPUTime(); ok {
+			report.AvgCPUPercent = cpuPercent(cpu-m.startCPU, time.Since(m.start))
+		}
+		for _, sample := range m.samples {
+			if sample > report.MaxCPUPercent {
+				report.MaxCPUPercent = sample
+			}
+		}
+	}
+
+	report.Warnings = clientWarnings(report, cfg)
+	return report
+}
+
+// clientWarnings lists the signs that the load generator, not the store, limited the results.
+// Connection churn is expected, and not reported, when cfg closes connections on purpose.
+func clientWarnings(r ClientReport, cfg *Config) []string {
+	concurrency := cfg.Concurrency
+	recycled := cfg.DisableKeepAlive || cfg.ConnMaxRequests > 0 || cfg.ConnMaxAge != ""
+	var warnings []string
+	if r.MaxCPUPercent > clientCPUWarnPercent {
+		warnings = append(warnings, fmt.Sprintf("client CPU peaked at %.0f%% of %d cores", r.MaxCPUPercent, r.GOMAXPROCS))
+	}
+	if r.GCCPUPercent > clientGCWarnPercent {
+		warnings = append(warnings, fmt.Sprintf("garbage collection used %.1f%% of client CPU", r.GCCPUPercent))
+	}
+	if concurrency > 0 && !recycled && r.ConnsOpened > int64(concurrency*connChurnFactor) {
+		warnings = append(warnings, fmt.Sprintf("%d connections opened for %d workers: connection churn suggests transport pool limits are being hit", r.ConnsOpened, concurrency))
+	}
+	if r.DialErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d connection attempts failed with socket errors", r.DialErrors))
+	}
+	return warnings
+}
+
+// printClientSummary prints client health and any bottleneck warnings as part of PrintSummary.
+func (s *Stats) printClientSummary(w io.Writer) {
+	if s.Client == nil {
+		return
+	}
+	c := s.Client
+	fmt.Fprintf(w, "\nClient:\n")
+	if c.CPUAvailable {
+		fmt.Fprintf(w, "  CPU:            %.1f%% avg, %.1f%% peak (%d cores)\n", c.AvgCPUPercent, c.MaxCPUPercent, c.GOMAXPROCS)
+	} else {
+		fmt.Fprintf(w, "  CPU:            unavailable on this platform\n")
+	}
+	if c.LockedThreads > 0 {
+		fmt.Fprintf(w, "  Scheduler:      operations on a pool of %d locked OS threads\n", c.LockedThreads)
+	}
+	fmt.Fprintf(w, "  GC:             %d cycles, %s paused\n", c.NumGC, c.GCPauseTotal.Round(time.Microsecond))
+	fmt.Fprintf(w, "  Connections:    %d opened, %d peak open, %d dial errors\n", c.ConnsOpened, c.PeakOpenConns, c.DialErrors)
+	for _, warning := range c.Warnings {
+		fmt.Fprintf(w, "  WARNING: %s\n", warning)
+	}
+	if len(c.Warnings) > 0 {
+		fmt.Fprintf(w, "  Results are likely limited by the client, not the object store.\n")
+	}
+}
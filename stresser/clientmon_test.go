@@ -0,0 +1,94 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackingDialContext(t *testing.T) {
+	openBefore := connStats.open.Load()
+	openedBefore := connStats.opened.Load()
+	errorsBefore := connStats.dialErrors.Load()
+
+	dial := trackingDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == "fail:80" {
+			return nil, errors.New("connection refused")
+		}
+		client, _ := net.Pipe()
+		return client, nil
+	})
+
+	conn, err := dial(context.Background(), "tcp", "ok:80")
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	if got := connStats.open.Load() - openBefore; got != 1 {
+		t.Errorf("Expected 1 open connection, got %d", got)
+	}
+	conn.Close()
+	conn.Close() // Double close must not decrement twice
+	if got := connStats.open.Load() - openBefore; got != 0 {
+		t.Errorf("Expected 0 open connections after close, got %d", got)
+	}
+
+	if _, err := dial(context.Background(), "tcp", "fail:80"); err == nil {
+		t.Fatal("Expected dial error")
+	}
+	if got := connStats.opened.Load() - openedBefore; got != 1 {
+		t.Errorf("Expected 1 opened connection, got %d", got)
+	}
+	if got := connStats.dialErrors.Load() - errorsBefore; got != 1 {
+		t.Errorf("Expected 1 dial error, got %d", got)
+	}
+}
+
+func TestClientWarnings(t *testing.T) {
+	healthy := ClientReport{MaxCPUPercent: 40, GCCPUPercent: 1, ConnsOpened: 10}
+	cfg := &Config{Concurrency: 10}
+	if warnings := clientWarnings(healthy, cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for a healthy client, got %v", warnings)
+	}
+
+	saturated := ClientReport{MaxCPUPercent: 97, GCCPUPercent: 15, ConnsOpened: 500, DialErrors: 3}
+	if warnings := clientWarnings(saturated, cfg); len(warnings) != 4 {
+		t.Errorf("Expected 4 warnings for a saturated client, got %v", warnings)
+	}
+
+	// Closing connections on purpose churns them without any pool limit being hit
+	for name, recycle := range map[string]func(*Config){
+		"keep-alive off":     func(c *Config) { c.DisableKeepAlive = true },
+		"max requests":       func(c *Config) { c.ConnMaxRequests = 100 },
+		"max connection age": func(c *Config) { c.ConnMaxAge = "1m" },
+	} {
+		recycled := *cfg
+		recycle(&recycled)
+		if warnings := clientWarnings(saturated, &recycled); len(warnings) != 3 {
+			t.Errorf("%s: expected no churn warning, got %v", name, warnings)
+		}
+	}
+}
+
+func TestPrintClientSummary(t *testing.T) {
+	stats := NewStats()
+	stats.Client = &ClientReport{
+		CPUAvailable:  true,
+		AvgCPUPercent: 85,
+		MaxCPUPercent: 95,
+		GCPauseTotal:  time.Millisecond,
+		Warnings:      []string{"client CPU peaked at 95% of 4 cores"},
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	output := buf.String()
+	if !strings.Contains(output, "WARNING: client CPU peaked") {
+		t.Errorf("Summary is missing the client warning:\n%s", output)
+	}
+	if !strings.Contains(output, "likely limited by the client") {
+		t.Errorf("Summary is missing the client-limited notice:\n%s", output)
+	}
+}
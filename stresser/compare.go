@@ -0,0 +1,219 @@
+package stresser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRegressionThreshold is the relative change, in percent, beyond which a worse
+	// throughput or latency counts as a regression.
+	DefaultRegressionThreshold = 10.0
+	// errorRateTolerance is the rise of the error rate, in percentage points, beyond which
+	// it counts as a regression. Error rates are compared in absolute terms, since a rise
+	// from 0.01% to 0.02% doubles the rate without mattering.
+	errorRateTolerance = 0.1
+)
+
+// ErrRegression is returned by Comparison.Check when the candidate run regressed.
+var ErrRegression = errors.New("performance regression")
+
+// MetricChange is the change of one figure between the baseline and the candidate run.
+type MetricChange struct {
+	Metric         string  `json:"metric" yaml:"metric"`
+	Baseline       float64 `json:"baseline" yaml:"baseline"`
+	Candidate      float64 `json:"candidate" yaml:"candidate"`
+	ChangePct      float64 `json:"changePct" yaml:"changePct"` // Relative change; for errorRatePct the change in percentage points
+	HigherIsBetter bool    `json:"higherIsBetter" yaml:"higherIsBetter"`
+	Regression     bool    `json:"regression" yaml:"regression"`
+	Improvement    bool    `json:"improvement" yaml:"improvement"`
+}
+
+// Comparison holds the changes between a baseline and a candidate run.
+type Comparison struct {
+	ThresholdPct float64        `json:"thresholdPct" yaml:"thresholdPct"`
+	Metrics      []MetricChange `json:"metrics" yaml:"metrics"`
+	Regressions  int            `json:"regressions" yaml:"regressions"`
+}
+
+// LoadSummary reads the summary of a run: either a JSON or YAML summary as written with
+// -summary-format, or detailed results in any format LoadResults reads, whose summary is
+// calculated.
+func LoadSummary(path, format string) (Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if format == "" {
+		var sum Summary
+		trimmed := bytes.TrimSpace(data)
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			// A summary is one JSON document; detailed JSON results are one document per line
+			if json.Valid(trimmed) && bytes.HasPrefix(trimmed, []byte("{")) && bytes.Contains(trimmed, []byte(`"totalRequests"`)) {
+				if err := json.Unmarshal(trimmed, &sum); err != nil {
+					return Summary{}, fmt.Errorf("failed to parse summary %s: %w", path, err)
+				}
+				return sum, nil
+			}
+		case ".yaml", ".yml":
+			if err := yaml.Unmarshal(data, &sum); err != nil {
+				return Summary{}, fmt.Errorf("failed to parse summary %s: %w", path, err)
+			}
+			return sum, nil
+		}
+	}
+	results, err := LoadResults(path, format)
+	if err != nil {
+		return Summary{}, err
+	}
+	stats := NewStats()
+	stats.CalculateFromResults(results)
+	return stats.Summary(), nil
+}
+
+// CompareSummaries compares the candidate run with the baseline. Throughput, request rate
+// and latency percentiles that got worse by more than thresholdPct percent, and an error
+// rate that rose by more than 0.1 percentage points, are flagged as regressions. Figures
+// missing from the baseline are left out.
+func CompareSummaries(baseline, candidate Summary, thresholdPct float64) Comparison {
+	c := Comparison{ThresholdPct: thresholdPct}
+	add := func(metric string, base, cand float64, higherIsBetter bool) {
+		if base == 0 {
+			return // Not measured in the baseline, e.g. P99.9 of summaries without it
+		}
+		m := MetricChange{Metric: metric, Baseline: base, Candidate: cand, HigherIsBetter: higherIsBetter}
+		m.ChangePct = (cand - base) / base * 100
+		worse := m.ChangePct
+		if higherIsBetter {
+			worse = -worse
+		}
+		m.Regression = worse > thresholdPct
+		m.Improvement = worse < -thresholdPct
+		if m.Regression {
+			c.Regressions++
+		}
+		c.Metrics = append(c.Metrics, m)
+	}
+	addLatency := func(prefix string, base, cand *LatencySummary) {
+		if base == nil || cand == nil {
+			return
+		}
+		add("p50"+prefix, base.P50, cand.P50, false)
+		add("p90"+prefix, base.P90, cand.P90, false)
+		add("p99"+prefix, base.P99, cand.P99, false)
+		add("p999"+prefix, base.P999, cand.P999, false)
+	}
+	addOperation := func(name string, base, cand *OperationSummary) {
+		if base == nil || cand == nil || base.Total == 0 || cand.Total == 0 {
+			return
+		}
+		add(strings.ToLower(name)+"ThroughputMiBps", base.ThroughputMiB, cand.ThroughputMiB, true)
+		addLatency(name+"TtfbMs", base.TTFB, cand.TTFB)
+		addLatency(name+"TtlbMs", base.TTLB, cand.TTLB)
+	}
+
+	add("requestsPerSec", baseline.RequestsPerSec, candidate.RequestsPerSec, true)
+	baseRate, candRate := errorRatePct(baseline), errorRatePct(candidate)
+	if baseRate != 0 || candRate != 0 {
+		m := MetricChange{Metric: "errorRatePct", Baseline: baseRate, Candidate: candRate, ChangePct: candRate - baseRate}
+		m.Regression = m.ChangePct > errorRateTolerance
+		m.Improvement = m.ChangePct < -errorRateTolerance
+		if m.Regression {
+			c.Regressions++
+		}
+		c.Metrics = append(c.Metrics, m)
+	}
+	addOperation("Get", &baseline.Get, &candidate.Get)
+	addOperation("Put", &baseline.Put, &candidate.Put)
+	addOperation("Head", baseline.Head, candidate.Head)
+	addOperation("List", baseline.List, candidate.List)
+	addOperation("Copy", baseline.Copy, candidate.Copy)
+	addOperation("Tagging", baseline.Tagging, candidate.Tagging)
+	addOperation("Delete", baseline.Delete, candidate.Delete)
+	return c
+}
+
+// errorRatePct returns the percentage of failed requests of a run.
+func errorRatePct(sum Summary) float64 {
+	if sum.TotalRequests == 0 {
+		return 0
+	}
+	return float64(sum.TotalErrors) / float64(sum.TotalRequests) * 100
+}
+
+// Check returns an error wrapping ErrRegression listing the regressed figures.
+func (c Comparison) Check() error {
+	var regressed []string
+	for _, m := range c.Metrics {
+		if m.Regression {
+			regressed = append(regressed, fmt.Sprintf("%s %.3f -> %.3f", m.Metric, m.Baseline, m.Candidate))
+		}
+	}
+	if len(regressed) > 0 {
+		return fmt.Errorf("%w: %s", ErrRegression, strings.Join(regressed, ", "))
+	}
+	return nil
+}
+
+// WriteComparison writes the comparison as a text table, JSON or YAML.
+func WriteComparison(w io.Writer, c Comparison, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(c); err != nil {
+			return fmt.Errorf("failed to write json comparison: %w", err)
+		}
+		return nil
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		if err := enc.Encode(c); err != nil {
+			return fmt.Errorf("failed to write yaml comparison: %w", err)
+		}
+		return nil
+	case FormatText:
+		c.print(w)
+		return nil
+	}
+	return fmt.Errorf("unknown comparison format %q, must be one of text, json, yaml", format)
+}
+
+// print writes the comparison as a table, marking regressions and improvements.
+func (c Comparison) print(w io.Writer) {
+	fmt.Fprintf(w, "\n--- Baseline Comparison (threshold %g%%) ---\n", c.ThresholdPct)
+	if len(c.Metrics) == 0 {
+		fmt.Fprintln(w, "  No figures found in both runs.")
+		return
+	}
+	fmt.Fprintf(w, "  %-24s | %12s | %12s | %9s |\n", "Metric", "Baseline", "Candidate", "Change")
+	fmt.Fprintf(w, "  %s|%s|%s|%s|\n", strings.Repeat("-", 25), strings.Repeat("-", 14), strings.Repeat("-", 14), strings.Repeat("-", 11))
+	for _, m := range c.Metrics {
+		change := fmt.Sprintf("%+.1f%%", m.ChangePct)
+		if m.Metric == "errorRatePct" {
+			change = fmt.Sprintf("%+.2fpp", m.ChangePct)
+		}
+		verdict := ""
+		switch {
+		case m.Regression:
+			verdict = " REGRESSION"
+		case m.Improvement:
+			verdict = " improved"
+		}
+		fmt.Fprintf(w, "  %-24s | %12.3f | %12.3f | %9s |%s\n", m.Metric, m.Baseline, m.Candidate, change, verdict)
+	}
+	if c.Regressions > 0 {
+		fmt.Fprintf(w, "\n  %d regression(s) beyond the threshold.\n", c.Regressions)
+	} else {
+		fmt.Fprintf(w, "\n  No regressions beyond the threshold.\n")
+	}
+}
@@ -0,0 +1,100 @@
+package stresser
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareSummaries(t *testing.T) {
+	baseline := Summary{TotalRequests: 1000, TotalErrors: 0, RequestsPerSec: 100,
+		Get: OperationSummary{Total: 1000, ThroughputMiB: 50, TTFB: &LatencySummary{P50: 10, P90: 20, P99: 40}, TTLB: &LatencySummary{P50: 20, P90: 30, P99: 60}}}
+	candidate := Summary{TotalRequests: 1000, TotalErrors: 5, RequestsPerSec: 95,
+		Get: OperationSummary{Total: 1000, ThroughputMiB: 70, TTFB: &LatencySummary{P50: 10.5, P90: 20, P99: 80}, TTLB: &LatencySummary{P50: 20, P90: 30, P99: 60}}}
+
+	c := CompareSummaries(baseline, candidate, DefaultRegressionThreshold)
+	byName := make(map[string]MetricChange)
+	for _, m := range c.Metrics {
+		byName[m.Metric] = m
+	}
+	if m := byName["p99GetTtfbMs"]; !m.Regression || m.ChangePct != 100 {
+		t.Errorf("Expected a doubled P99 TTFB to regress, got %+v", m)
+	}
+	if m := byName["getThroughputMiBps"]; !m.Improvement || m.Regression {
+		t.Errorf("Expected higher throughput to improve, got %+v", m)
+	}
+	if m := byName["requestsPerSec"]; m.Regression || m.Improvement {
+		t.Errorf("Expected a 5%% drop to stay within the threshold, got %+v", m)
+	}
+	if m := byName["errorRatePct"]; !m.Regression || math.Abs(m.ChangePct-0.5) > 1e-9 {
+		t.Errorf("Expected a 0.5pp error rate rise to regress, got %+v", m)
+	}
+	if _, ok := byName["p999GetTtfbMs"]; ok {
+		t.Error("Expected figures missing from the baseline to be left out")
+	}
+	if c.Regressions != 2 {
+		t.Errorf("Expected 2 regressions, got %d", c.Regressions)
+	}
+	if err := c.Check(); !errors.Is(err, ErrRegression) {
+		t.Errorf("Expected ErrRegression, got %v", err)
+	}
+	if err := CompareSummaries(baseline, baseline, DefaultRegressionThreshold).Check(); err != nil {
+		t.Errorf("Expected no regression against itself, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteComparison(&buf, c, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "REGRESSION") || !strings.Contains(buf.String(), "improved") {
+		t.Errorf("Comparison missing verdicts:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := WriteComparison(&buf, c, FormatJSON); err != nil || !strings.Contains(buf.String(), `"regressions": 2`) {
+		t.Errorf("Unexpected JSON comparison (%v):\n%s", err, buf.String())
+	}
+}
+
+func TestLoadSummary(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", BytesDownloaded: 1024, TTFB: time.Millisecond, TTLB: 2 * time.Millisecond})
+	stats.Calculate(start, start.Add(time.Second))
+
+	for name, format := range map[string]string{"summary.json": FormatJSON, "summary.yaml": FormatYAML} {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteSummary(f, stats, format); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		sum, err := LoadSummary(path, "")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if sum.TotalRequests != 1 || sum.Get.TTFB == nil || sum.Get.TTFB.P50 != 1 {
+			t.Errorf("%s: unexpected summary %+v", name, sum)
+		}
+	}
+
+	path := filepath.Join(dir, "results.csv")
+	if err := WriteResults([]Result{{Timestamp: start, Operation: "PUT", BytesUploaded: 1024, TTFB: -1, TTLB: 5 * time.Millisecond}}, path, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := LoadSummary(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Put.Total != 1 || sum.Put.TTLB == nil {
+		t.Errorf("Unexpected summary from results %+v", sum)
+	}
+}
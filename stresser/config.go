@@ -2,36 +2,228 @@ package stresser
 
 import (
 	"fmt"
+	"github.com/aws/smithy-go/middleware"
 	"gopkg.in/yaml.v3"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 )
 
 // Config holds the application configuration.
 type Config struct {
+	// Storage backend
+	Backend            string `yaml:"backend"`            // "s3" or "gcs" (default: s3)
+	GCSCredentials     string `yaml:"gcsCredentials"`     // Service account JSON for the gcs backend (optional)
+	GCSCredentialsFile string `yaml:"gcsCredentialsFile"` // Path to a service account JSON file for the gcs backend (optional)
+
 	// S3 Connection
-	Endpoint           string `yaml:"endpoint"`
-	Region             string `yaml:"region"` // Needed for AWS SDK proper function even with custom endpoint
-	Bucket             string `yaml:"bucket"`
-	AccessKey          string `yaml:"accessKey"` // Optional if using env vars/instance profile
-	SecretKey          string `yaml:"secretKey"` // Optional if using env vars/instance profile
-	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+	Endpoint            string     `yaml:"endpoint"`
+	Endpoints           []string   `yaml:"endpoints"`         // Spread operations over these endpoints instead, as "url" or "url=weight"
+	EndpointSpec        string     `yaml:"-"`                 // Comma-separated endpoints from -endpoints or STRESSER_ENDPOINTS, replaces Endpoints
+	EndpointSelection   string     `yaml:"endpointSelection"` // With Endpoints: "round-robin" or "random", both by weight (default: round-robin)
+	Region              string     `yaml:"region"`            // Needed for AWS SDK proper function even with custom endpoint
+	Bucket              string     `yaml:"bucket"`
+	Buckets             []string   `yaml:"buckets"`         // Spread operations over these buckets instead; entries may be ranges like "bench-{0..15}"
+	BucketSpec          string     `yaml:"-"`               // Comma-separated buckets from -buckets or STRESSER_BUCKETS, replaces Buckets
+	BucketSelection     string     `yaml:"bucketSelection"` // With Buckets: "round-robin", "random" or "hash" of the key (default: round-robin)
+	AccessKey           string     `yaml:"accessKey"`       // Optional if using env vars/instance profile
+	SecretKey           string     `yaml:"secretKey"`       // Optional if using env vars/instance profile
+	InsecureSkipVerify  bool       `yaml:"insecureSkipVerify"`
+	TLSCAFile           string     `yaml:"tlsCAFile"`           // PEM bundle of CA certificates trusted for the endpoint, in addition to the system roots
+	TLSCertFile         string     `yaml:"tlsCertFile"`         // PEM client certificate presented for mutual TLS (requires TLSKeyFile)
+	TLSKeyFile          string     `yaml:"tlsKeyFile"`          // PEM private key of TLSCertFile
+	TLSMinVersion       string     `yaml:"tlsMinVersion"`       // Minimum TLS version: "1.0", "1.1", "1.2" or "1.3" (default: Go default of 1.2)
+	Signing             string     `yaml:"signing"`             // Request signing: "v4", "v2" (legacy gateways) or "none" (anonymous, public buckets) (default: v4)
+	RoleARN             string     `yaml:"roleArn"`             // Assume this IAM role with the configured credentials, refreshing it before its session expires
+	RoleExternalID      string     `yaml:"roleExternalId"`      // External ID required by the role's trust policy (optional)
+	RoleSessionName     string     `yaml:"roleSessionName"`     // Session name of the assumed role (default: ostresser)
+	RoleDuration        string     `yaml:"roleDuration"`        // Session duration of the assumed role, 15m to 12h (default: STS default of 1h)
+	STSEndpoint         string     `yaml:"stsEndpoint"`         // STS endpoint to assume the role at (default: the AWS STS endpoint of Region)
+	DNSRefresh          string     `yaml:"dnsRefresh"`          // Re-resolve the endpoint this often and spread new connections over all addresses (e.g. "30s")
+	ConnMaxRequests     int        `yaml:"connMaxRequests"`     // Close connections after this many requests (0 = unlimited)
+	ConnMaxAge          string     `yaml:"connMaxAge"`          // Close connections older than this (e.g. "1m", empty = unlimited)
+	MaxIdleConns        int        `yaml:"maxIdleConns"`        // Idle connections kept open across all hosts (0 = Go default of 100)
+	MaxIdleConnsPerHost int        `yaml:"maxIdleConnsPerHost"` // Idle connections kept open per host (0 = Go default of 2)
+	MaxConnsPerHost     int        `yaml:"maxConnsPerHost"`     // Connections per host including active ones, requests beyond wait (0 = unlimited)
+	IdleConnTimeout     string     `yaml:"idleConnTimeout"`     // Close idle connections after this long (e.g. "30s", default: Go default of 90s)
+	RetryMode           string     `yaml:"retryMode"`           // SDK retry mode: "standard", "adaptive" or "off" (default: standard)
+	RetryMaxAttempts    int        `yaml:"retryMaxAttempts"`    // Attempts per request including the first (0 = SDK default of 3, 1 disables retries)
+	RetryMaxBackoff     string     `yaml:"retryMaxBackoff"`     // Upper bound of the backoff between attempts (default: SDK default of 20s)
+	DisableKeepAlive    bool       `yaml:"disableKeepAlive"`    // Open a new connection for every request
+	ClientPerWorker     bool       `yaml:"clientPerWorker"`     // Give every worker an S3 client and HTTP transport of its own instead of sharing one
+	IPFamily            string     `yaml:"ipFamily"`            // "ipv4", "ipv6" or "both" (default: both, happy eyeballs)
+	HTTPVersion         string     `yaml:"httpVersion"`         // "1.1", "2" or "auto" (default: auto, HTTP/2 when the server offers it over TLS)
+	Headers             HeaderList `yaml:"headers"`             // Extra "Name: value" headers sent with every request; a list or a name: value map in YAML
+	UserAgent           string     `yaml:"userAgent"`           // Custom suffix appended to the "ostresser/<version> run/<id>" User-Agent
+	RunID               string     `yaml:"runId"`               // Identifies this run in the User-Agent, summary, results and default keys; reuse one to write to its prefix again (default: generated by New or the command line tool)
+
+	// Pre-flight checks
+	Preflight    bool `yaml:"preflight"`    // Check endpoint, credentials, bucket and (for writing workloads) write permission before starting workers
+	CreateBucket bool `yaml:"createBucket"` // Create the bucket if it does not exist (implies Preflight)
+
+	// APIOptions are appended to the S3 client's middleware stack, e.g. WithRequestHook.
+	// Library use only; they let embedders mutate every request without building their own client.
+	APIOptions []func(*middleware.Stack) error `yaml:"-" json:"-"`
 
 	// Test Parameters (populated from flags/args, overriding YAML/Env)
 	Duration        string `yaml:"-"` // Exclude from YAML marshalling
 	Concurrency     int    `yaml:"-"`
 	Randomize       bool   `yaml:"-"`
 	ManifestPath    string `yaml:"-"`
-	OutputFile      string `yaml:"-"`
-	OperationType   string `yaml:"operationType"`   // "read", "write", "mixed"
+	OutputFile      string `yaml:"-"`               // "-" writes detailed results to stdout
+	SummaryFile     string `yaml:"-"`               // Summary destination, "-" for stdout (default: stdout, or stderr if OutputFile is stdout)
+	OperationType   string `yaml:"operationType"`   // "read", "write", "mixed", "head", "list", "revalidate", "copy", "tagging", "versioned", "consistency", "contention", "append", "replay" or "scenario" (set by Scenario/Groups)
 	PutObjectSizeKB int    `yaml:"putObjectSizeKB"` // Size in KB for PUT operations
 
+	PutSizeDistribution string `yaml:"putSizeDistribution"` // PUT object sizes instead of PutObjectSizeKB: "uniform:4K-64M", "lognormal:1M:1.5" or "4K:50%,1M:40%,64M:10%"
+	DataPattern         string `yaml:"dataPattern"`         // Content of PUT bodies: "random", "zeroes", "compressible:<ratio>" or "dedupe[:<blocks>]" (default: random)
+
+	// Distributed runs
+	Agents          string          `yaml:"agents"`        // Comma-separated agent addresses to run the test on, e.g. "host1,host2:7001"
+	AgentToken      string          `yaml:"agentToken"`    // Shared secret the agents were started with, required with Agents
+	AgentInsecure   bool            `yaml:"agentInsecure"` // Allow sending credentials to agents over plain HTTP instead of https:// agent URLs
+	Shard           string          `yaml:"shard"`         // Read only this round-robin slice of the manifest, e.g. "3/8", so independent instances read disjoint keys
+	Keys            []string        `yaml:"-"`             // Object keys to use instead of loading ManifestPath (set for agent shards)
+	KeyWeights      []float64       `yaml:"-"`             // Read weights of Keys, nil if the manifest has none (set for agent shards)
+	KeyExpectations []ManifestEntry `yaml:"-"`             // Expected sizes and ETags of Keys, nil if the manifest has none (set for agent shards)
+
+	// Warm-up
+	Warmup        string `yaml:"warmup"`        // Run at full load this long before the measured duration, excluded from stats (e.g. "30s")
+	WarmupResults bool   `yaml:"warmupResults"` // Also write warm-up results to the detailed output, flagged as warm-up
+
+	// Key selection
+	KeyTemplate     string `yaml:"keyTemplate"`     // Template for keys generated by PUTs and copies, e.g. "bench/{date}/{worker}/{seq}-{rand}" (default: "stresser/{run}/worker{worker}/{ts}-{rand}.dat", "stresser/{run}/copy/worker{worker}/{ts}-{rand}.dat" for copies)
+	KeyDistribution string `yaml:"keyDistribution"` // How reads pick manifest keys: "sequential", "uniform", "zipf:<s>" or "weighted" (default: sequential, or uniform with -r)
+
+	// Revalidate mode parameters
+	RevalidateStale float64 `yaml:"revalidateStale"` // Fraction (0-1) of revalidations sent with outdated validators, which return the full object instead of a 304
+
+	// Consistency mode parameters
+	ConsistencyDelays string `yaml:"consistencyDelays"` // Delays after each PUT at which the object is read back, e.g. "0,100ms,1s,5s" (the default)
+	ConsistencyProbe  string `yaml:"consistencyProbe"`  // How the object is read back: "get" compares the content, "head" only the size, "list" looks for the key in a listing of its prefix (default: get)
+
+	// Contention mode and -write-mode overwrite parameters
+	KeyPool   int    `yaml:"keyPool"`   // Number of keys 'contention' mode races for (default: 16), that overwriting writes replace (default: 1000), or logs per worker in 'append' mode (default: 1)
+	WriteMode string `yaml:"writeMode"` // 'write' mode: "unique" keys for every PUT (the default) or "overwrite" the keys of a pool
+
+	// Append mode parameters
+	AppendSizeKB int `yaml:"appendSizeKB"` // Chunk every read-modify-write adds to a log in KB (default: 4)
+	AppendMaxKB  int `yaml:"appendMaxKB"`  // Size in KB at which a log starts over (default: 16384)
+
+	// List mode parameters
+	ListPrefix   string `yaml:"listPrefix"`   // Only list keys under this prefix (default: whole bucket)
+	ListPageSize int    `yaml:"listPageSize"` // Keys per ListObjectsV2 page (default: 1000)
+
+	// Copy mode parameters
+	CopyPartSizeMB int `yaml:"copyPartSizeMB"` // Copy objects larger than this with multipart UploadPartCopy in parts of this size (default: 0, a single CopyObject up to 5 GiB, larger objects in 512 MB parts)
+
+	// Versioned mode parameters
+	VersionMix string `yaml:"versionMix"` // Weights of PUTs, GETs of a version and DELETEs of a version, e.g. "put=30,get=60,delete=10" (the default)
+
+	// Scenario mode parameters
+	Scenario string           `yaml:"scenario"` // YAML file with weighted operation groups to run concurrently (overrides Groups)
+	Groups   []OperationGroup `yaml:"groups"`   // Weighted operation groups, each with its own key space and sizes
+
+	// Test plans
+	Plan   string  `yaml:"plan"`   // YAML file with phases to run one after another, each with its own settings (overrides Phases)
+	Phases []Phase `yaml:"phases"` // Phases run one after another, e.g. loading a dataset, then reading it
+
 	// File generation parameters for write mode
 	FileCount        int  `yaml:"fileCount"`        // Number of files to generate in write mode (default: 1000)
 	GenerateManifest bool `yaml:"generateManifest"` // Whether to write generated keys to manifest file
 
+	// Replay parameters
+	ReplayFile   string  `yaml:"replayFile"`   // Access log, CSV (op,key,size,timestamp) or trace to replay
+	ReplayFormat string  `yaml:"replayFormat"` // "csv", "s3log" or "trace"; inferred from the file extension if empty
+	ReplaySpeed  float64 `yaml:"replaySpeed"`  // Time scaling factor, 2.0 replays twice as fast (default: 1.0)
+	ReplayTiming string  `yaml:"replayTiming"` // "original", "scaled" (by ReplaySpeed) or "asap" (default: scaled)
+
+	// Sweeps
+	SizeSweep        string `yaml:"sizeSweep"`        // Comma-separated object sizes to sweep, e.g. "4K,64K,1M,16M"
+	ConcurrencySweep string `yaml:"concurrencySweep"` // Comma-separated worker counts to sweep, e.g. "1,4,16,64"
+
+	// Reporting
+	OutputFormat  string  `yaml:"outputFormat"`  // Detailed results format: "csv", "jsonl", "json", "parquet", "sql" or "sqlite" (default: inferred from OutputFile, else csv)
+	Checkpoint    string  `yaml:"checkpoint"`    // Stream detailed results to OutputFile during the run, flushing this often, instead of writing them at the end (optional)
+	SampleRate    float64 `yaml:"sampleRate"`    // Fraction (0-1) of results kept for the detailed output; the stats use all (0 keeps all)
+	SampleMax     int     `yaml:"sampleMax"`     // Keep at most this many results for the detailed output, a uniform sample of the run (0: no limit)
+	SummaryFormat string  `yaml:"summaryFormat"` // Summary format: "text", "json" or "yaml" (default: text)
+	PrefixDepth   int     `yaml:"prefixDepth"`   // Key path segments to group per-prefix stats by (0 disables)
+	NICInterface  string  `yaml:"nicInterface"`  // Host network interface to sample for link utilization (optional)
+	OutlierCount  int     `yaml:"outlierCount"`  // Slowest requests to report with full context (default: 10, 0 disables)
+	TrackWrites   bool    `yaml:"trackWrites"`   // Remember every key written to report unique objects, overwrites and net new data
+	SlowThreshold string  `yaml:"slowThreshold"` // Log full details of every request that takes longer than this, e.g. "2s" (optional)
+	SlowLog       string  `yaml:"slowLog"`       // Also write those requests to this file as JSON lines (optional, requires SlowThreshold)
+	HDRLog        string  `yaml:"hdrLog"`        // Write latency histograms in HdrHistogram log format to this file (optional)
+	TimeSeries    string  `yaml:"timeSeries"`    // Write per-second throughput and latency to this file, JSON for .json paths, else CSV (optional)
+	HTMLReport    string  `yaml:"htmlReport"`    // Write a self-contained HTML page with charts of the run to this file (optional)
+
+	// Pass/fail criteria
+	SLOs    map[string]float64 `yaml:"slos"` // Thresholds by metric name, e.g. p99GetTtfbMs: 100 or errorRatePct: 0.5
+	SLOSpec string             `yaml:"-"`    // "name=threshold,..." from -slo or STRESSER_SLO, merged into SLOs by Validate
+
+	// Trace recording
+	TraceFile string `yaml:"traceFile"` // Record every executed operation to this trace file (optional)
+
+	// Load generator protection
+	CPUBudget float64 `yaml:"cpuBudget"` // Reduce active workers while client CPU exceeds this percentage (0 disables)
+	TargetP99 string  `yaml:"targetP99"` // Adjust active workers to find the highest throughput with P99 latency within this duration (optional)
+
+	// Go scheduler
+	GoMaxProcs  int  `yaml:"goMaxProcs"`  // OS threads running Go code at once (0 = the Go default, the usable cores)
+	LockThreads bool `yaml:"lockThreads"` // Run the operations of the workers on a pool of locked OS threads
+	ThreadPool  int  `yaml:"threadPool"`  // Locked OS threads with LockThreads (0 = one per worker, up to MaxThreadPool)
+
+	// Data integrity
+	Verify   bool   `yaml:"verify"`   // Store a SHA-256 with every PUT and check GET bodies against it
+	Checksum string `yaml:"checksum"` // Additional checksum of PUTs, checked by GETs: "crc32", "crc32c", "crc64nvme", "sha1", "sha256" or "none" (default: SDK default)
+
+	// Server-side encryption
+	SSE            string `yaml:"sse"`            // Encrypt written objects: "none", "sse-s3", "sse-kms" or "sse-c" (default: none)
+	SSEKMSKeyID    string `yaml:"sseKmsKeyId"`    // KMS key for sse-kms (default: the store's default key)
+	SSECustomerKey string `yaml:"sseCustomerKey"` // Base64-encoded 256-bit key for sse-c, also sent with every GET and HEAD
+
+	// Compressed GETs
+	AcceptEncoding string `yaml:"acceptEncoding"` // Content encodings GETs accept, e.g. "gzip", "zstd" or "gzip,deflate"; compressed bodies are decoded (optional)
+
+	// Attributes of written objects
+	Metadata     map[string]string `yaml:"metadata"`     // User metadata (x-amz-meta-*) set on every PUT
+	MetadataSpec string            `yaml:"-"`            // "name=value,..." from -metadata or STRESSER_METADATA, merged into Metadata by Validate
+	ContentType  string            `yaml:"contentType"`  // Content-Type of written objects (default: SDK default)
+	CacheControl string            `yaml:"cacheControl"` // Cache-Control of written objects (optional)
+	Tags         map[string]string `yaml:"tags"`         // Object tags set on every PUT
+	TagSpec      string            `yaml:"-"`            // "name=value,..." from -tags or STRESSER_TAGS, merged into Tags by Validate
+
+	// Request path
+	Presign bool `yaml:"presign"` // Send GETs and PUTs to presigned URLs with a plain HTTP client instead of the SDK
+
+	// Load shaping
+	Ramp   string  `yaml:"ramp"`   // Stages as "from..to/duration" list, e.g. "0..100/5m,100..0/1m" (overrides Stages)
+	Stages []Stage `yaml:"stages"` // Stages that change the active worker count over the run
+
+	// Request pacing
+	Jitter        string  `yaml:"jitter"`        // Random delay before each request, e.g. "uniform:50ms" or "exponential:20ms"
+	ThinkTime     string  `yaml:"thinkTime"`     // Pause of each worker after every operation, e.g. "fixed:1s" or "exponential:200ms", to model application behavior
+	RPS           float64 `yaml:"rps"`           // Maximum requests per second across all workers (0 = unlimited)
+	WorkerRPS     float64 `yaml:"workerRps"`     // Maximum requests per second for each individual worker (0 = unlimited)
+	ArrivalRate   float64 `yaml:"arrivalRate"`   // Open loop: start this many operations per second regardless of completions, with at most Concurrency outstanding (0 = closed loop)
+	HedgeQuantile float64 `yaml:"hedgeQuantile"` // Hedge GETs slower than this TTFB percentile, e.g. 95 (0 disables)
+
+	BandwidthLimit float64 `yaml:"bandwidthLimit"` // Maximum upload plus download throughput of all connections in MiB/s (0 = unlimited)
+
+	// Failure simulation
+	DisconnectFraction float64 `yaml:"disconnectFraction"` // Cut PUT connections after this fraction of the body (0 disables)
+	SecondaryEndpoint  string  `yaml:"secondaryEndpoint"`  // Endpoint to fail over to when the primary keeps failing (optional)
+	FailoverThreshold  int     `yaml:"failoverThreshold"`  // Consecutive primary failures that trigger a failover (default: 3)
+
 	// Logging configuration
-	LogLevel string `yaml:"logLevel"` // Log level: debug, info, warn, error (default: info)
+	LogLevel  string `yaml:"logLevel"`  // Log level: debug, info, warn, error (default: info)
+	Quiet     bool   `yaml:"quiet"`     // Only log warnings and print a single result line instead of the summary
+	Progress  string `yaml:"progress"`  // Print interim stats to stderr this often during the run, "0" disables (default: 5s)
+	TUI       bool   `yaml:"tui"`       // Show a live dashboard on stderr instead of the progress lines
+	Control   string `yaml:"control"`   // Serve pause/resume/concurrency/stop commands on this loopback host:port or unix socket path (optional)
+	PprofAddr string `yaml:"pprofAddr"` // Serve net/http/pprof profiles of ostresser on this loopback host:port while the test runs (optional)
 }
 
 const (
@@ -39,6 +231,9 @@ const (
 	DefaultPutSizeKB     = 1024 // 1 MiB
 	DefaultFileCount     = 1000 // Default number of files to generate
 	DefaultLogLevel      = "info"
+	DefaultReplaySpeed   = 1.0
+	DefaultReplayTiming  = "scaled"
+	DefaultOutlierCount  = 10
 )
 
 // LoadConfig loads configuration from a YAML file path or environment variables.
@@ -47,12 +242,22 @@ const (
 func LoadConfig(configPath string) (*Config, error) {
 	// Set defaults
 	cfg := &Config{
-		Region:           "us-east-1", // Default region if not specified
-		OperationType:    DefaultOperationType,
-		PutObjectSizeKB:  DefaultPutSizeKB,
-		FileCount:        DefaultFileCount,
-		GenerateManifest: true, // By default, generate manifest file when in write mode
-		LogLevel:         DefaultLogLevel,
+		Region:            "us-east-1", // Default region if not specified
+		Backend:           BackendS3,
+		OperationType:     DefaultOperationType,
+		PutObjectSizeKB:   DefaultPutSizeKB,
+		FileCount:         DefaultFileCount,
+		GenerateManifest:  true, // By default, generate manifest file when in write mode
+		LogLevel:          DefaultLogLevel,
+		ReplaySpeed:       DefaultReplaySpeed,
+		ReplayTiming:      DefaultReplayTiming,
+		OutlierCount:      DefaultOutlierCount,
+		SummaryFormat:     DefaultSummaryFormat,
+		FailoverThreshold: DefaultFailoverThreshold,
+		IPFamily:          IPFamilyBoth,
+		HTTPVersion:       HTTPVersionAuto,
+		ListPageSize:      DefaultListPageSize,
+		Progress:          DefaultProgressInterval,
 	}
 
 	// 1. Load from YAML file if provided
@@ -70,6 +275,12 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	// 2. Override with environment variables
+	if envBackend := os.Getenv("STRESSER_BACKEND"); envBackend != "" {
+		cfg.Backend = strings.ToLower(envBackend)
+	}
+	if envGCSCreds := os.Getenv("STRESSER_GCS_CREDENTIALS_FILE"); envGCSCreds != "" {
+		cfg.GCSCredentialsFile = envGCSCreds
+	}
 	if envEndpoint := os.Getenv("AWS_ENDPOINT_URL"); envEndpoint != "" {
 		cfg.Endpoint = envEndpoint
 	}
@@ -79,6 +290,18 @@ func LoadConfig(configPath string) (*Config, error) {
 	if envBucket := os.Getenv("S3_BUCKET"); envBucket != "" { // Using S3_BUCKET to avoid clash with AWS CLI profile buckets
 		cfg.Bucket = envBucket
 	}
+	if envEndpoints := os.Getenv("STRESSER_ENDPOINTS"); envEndpoints != "" {
+		cfg.EndpointSpec = envEndpoints
+	}
+	if envSelection := os.Getenv("STRESSER_ENDPOINT_SELECTION"); envSelection != "" {
+		cfg.EndpointSelection = strings.ToLower(envSelection)
+	}
+	if envBuckets := os.Getenv("STRESSER_BUCKETS"); envBuckets != "" {
+		cfg.BucketSpec = envBuckets
+	}
+	if envSelection := os.Getenv("STRESSER_BUCKET_SELECTION"); envSelection != "" {
+		cfg.BucketSelection = strings.ToLower(envSelection)
+	}
 	if envKey := os.Getenv("AWS_ACCESS_KEY_ID"); envKey != "" {
 		cfg.AccessKey = envKey
 	}
@@ -95,6 +318,36 @@ func LoadConfig(configPath string) (*Config, error) {
 			cfg.InsecureSkipVerify = false
 		}
 	}
+	if envCAFile := os.Getenv("STRESSER_TLS_CA_FILE"); envCAFile != "" {
+		cfg.TLSCAFile = envCAFile
+	}
+	if envCertFile := os.Getenv("STRESSER_TLS_CERT_FILE"); envCertFile != "" {
+		cfg.TLSCertFile = envCertFile
+	}
+	if envKeyFile := os.Getenv("STRESSER_TLS_KEY_FILE"); envKeyFile != "" {
+		cfg.TLSKeyFile = envKeyFile
+	}
+	if envMinVersion := os.Getenv("STRESSER_TLS_MIN_VERSION"); envMinVersion != "" {
+		cfg.TLSMinVersion = envMinVersion
+	}
+	if envSigning := os.Getenv("STRESSER_SIGNING"); envSigning != "" {
+		cfg.Signing = strings.ToLower(envSigning)
+	}
+	if envRole := os.Getenv("STRESSER_ROLE_ARN"); envRole != "" {
+		cfg.RoleARN = envRole
+	}
+	if envExternalID := os.Getenv("STRESSER_ROLE_EXTERNAL_ID"); envExternalID != "" {
+		cfg.RoleExternalID = envExternalID
+	}
+	if envSessionName := os.Getenv("STRESSER_ROLE_SESSION_NAME"); envSessionName != "" {
+		cfg.RoleSessionName = envSessionName
+	}
+	if envRoleDuration := os.Getenv("STRESSER_ROLE_DURATION"); envRoleDuration != "" {
+		cfg.RoleDuration = envRoleDuration
+	}
+	if envSTSEndpoint := os.Getenv("STRESSER_STS_ENDPOINT"); envSTSEndpoint != "" {
+		cfg.STSEndpoint = envSTSEndpoint
+	}
 
 	if envOpType := os.Getenv("STRESSER_OPERATION_TYPE"); envOpType != "" {
 		cfg.OperationType = envOpType
@@ -104,19 +357,190 @@ func LoadConfig(configPath string) (*Config, error) {
 		if _, err := fmt.Sscan(envPutSize, &size); err == nil && size > 0 {
 			cfg.PutObjectSizeKB = size
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: Invalid STRESSER_PUT_SIZE_KB value '%s', using default %d KB\n", envPutSize, DefaultPutSizeKB)
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_PUT_SIZE_KB value '%s', using default %d KB", envPutSize, DefaultPutSizeKB))
+		}
+	}
+	if envPutSizeDist := os.Getenv("STRESSER_PUT_SIZE_DIST"); envPutSizeDist != "" {
+		cfg.PutSizeDistribution = envPutSizeDist
+	}
+	if envDataPattern := os.Getenv("STRESSER_DATA_PATTERN"); envDataPattern != "" {
+		cfg.DataPattern = envDataPattern
+	}
+	if envKeyTemplate := os.Getenv("STRESSER_KEY_TEMPLATE"); envKeyTemplate != "" {
+		cfg.KeyTemplate = envKeyTemplate
+	}
+	if envKeyDist := os.Getenv("STRESSER_KEY_DISTRIBUTION"); envKeyDist != "" {
+		cfg.KeyDistribution = envKeyDist
+	}
+	if envStale := os.Getenv("STRESSER_REVALIDATE_STALE"); envStale != "" {
+		var fraction float64
+		if _, err := fmt.Sscan(envStale, &fraction); err == nil {
+			cfg.RevalidateStale = fraction
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_REVALIDATE_STALE value '%s', using 0", envStale))
+		}
+	}
+	if envDelays := os.Getenv("STRESSER_CONSISTENCY_DELAYS"); envDelays != "" {
+		cfg.ConsistencyDelays = envDelays
+	}
+	if envProbe := os.Getenv("STRESSER_CONSISTENCY_PROBE"); envProbe != "" {
+		cfg.ConsistencyProbe = envProbe
+	}
+	if envKeyPool := os.Getenv("STRESSER_KEYPOOL"); envKeyPool != "" {
+		var n int
+		if _, err := fmt.Sscan(envKeyPool, &n); err == nil && n > 0 {
+			cfg.KeyPool = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_KEYPOOL value '%s', using the default", envKeyPool))
+		}
+	}
+	if envWriteMode := os.Getenv("STRESSER_WRITE_MODE"); envWriteMode != "" {
+		cfg.WriteMode = envWriteMode
+	}
+	if envAppendSize := os.Getenv("STRESSER_APPEND_SIZE_KB"); envAppendSize != "" {
+		var n int
+		if _, err := fmt.Sscan(envAppendSize, &n); err == nil && n > 0 {
+			cfg.AppendSizeKB = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_APPEND_SIZE_KB value '%s', using the default", envAppendSize))
 		}
 	}
+	if envAppendMax := os.Getenv("STRESSER_APPEND_MAX_KB"); envAppendMax != "" {
+		var n int
+		if _, err := fmt.Sscan(envAppendMax, &n); err == nil && n > 0 {
+			cfg.AppendMaxKB = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_APPEND_MAX_KB value '%s', using the default", envAppendMax))
+		}
+	}
+	if envListPrefix := os.Getenv("STRESSER_LIST_PREFIX"); envListPrefix != "" {
+		cfg.ListPrefix = envListPrefix
+	}
+	if envPageSize := os.Getenv("STRESSER_LIST_PAGE_SIZE"); envPageSize != "" {
+		var size int
+		if _, err := fmt.Sscan(envPageSize, &size); err == nil && size > 0 {
+			cfg.ListPageSize = size
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_LIST_PAGE_SIZE value '%s', using default %d", envPageSize, DefaultListPageSize))
+		}
+	}
+	if envPartSize := os.Getenv("STRESSER_COPY_PART_SIZE_MB"); envPartSize != "" {
+		var size int
+		if _, err := fmt.Sscan(envPartSize, &size); err == nil && size > 0 {
+			cfg.CopyPartSizeMB = size
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_COPY_PART_SIZE_MB value '%s', using single CopyObject calls up to 5 GiB", envPartSize))
+		}
+	}
+	if envVersionMix := os.Getenv("STRESSER_VERSION_MIX"); envVersionMix != "" {
+		cfg.VersionMix = envVersionMix
+	}
+	if envScenario := os.Getenv("STRESSER_SCENARIO"); envScenario != "" {
+		cfg.Scenario = envScenario
+	}
+	if envPlan := os.Getenv("STRESSER_PLAN"); envPlan != "" {
+		cfg.Plan = envPlan
+	}
 	if envFileCount := os.Getenv("STRESSER_FILE_COUNT"); envFileCount != "" {
 		var count int
 		if _, err := fmt.Sscan(envFileCount, &count); err == nil && count > 0 {
 			cfg.FileCount = count
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: Invalid STRESSER_FILE_COUNT value '%s', using default %d\n", envFileCount, DefaultFileCount)
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_FILE_COUNT value '%s', using default %d", envFileCount, DefaultFileCount))
 		}
 	}
 
 	// Handle boolean for generate manifest
+	if keepAlive := os.Getenv("STRESSER_DISABLE_KEEPALIVE"); keepAlive != "" {
+		if keepAlive == "true" {
+			cfg.DisableKeepAlive = true
+		} else if keepAlive == "false" {
+			cfg.DisableKeepAlive = false
+		}
+	}
+	if perWorker := os.Getenv("STRESSER_CLIENT_PER_WORKER"); perWorker != "" {
+		if perWorker == "true" {
+			cfg.ClientPerWorker = true
+		} else if perWorker == "false" {
+			cfg.ClientPerWorker = false
+		}
+	}
+	if envHeaders := os.Getenv("STRESSER_HEADERS"); envHeaders != "" {
+		cfg.Headers = nil
+		for _, h := range strings.Split(envHeaders, ";") {
+			if h = strings.TrimSpace(h); h != "" {
+				cfg.Headers = append(cfg.Headers, h)
+			}
+		}
+	}
+	if envUA := os.Getenv("STRESSER_USER_AGENT"); envUA != "" {
+		cfg.UserAgent = envUA
+	}
+	if envRunID := os.Getenv("STRESSER_RUN_ID"); envRunID != "" {
+		cfg.RunID = envRunID
+	}
+	if envFamily := os.Getenv("STRESSER_IP_FAMILY"); envFamily != "" {
+		cfg.IPFamily = strings.ToLower(envFamily)
+	}
+	if envHTTPVersion := os.Getenv("STRESSER_HTTP_VERSION"); envHTTPVersion != "" {
+		cfg.HTTPVersion = strings.ToLower(envHTTPVersion)
+	}
+	if envDNSRefresh := os.Getenv("STRESSER_DNS_REFRESH"); envDNSRefresh != "" {
+		cfg.DNSRefresh = envDNSRefresh
+	}
+	if envMaxRequests := os.Getenv("STRESSER_CONN_MAX_REQUESTS"); envMaxRequests != "" {
+		var n int
+		if _, err := fmt.Sscan(envMaxRequests, &n); err == nil && n >= 0 {
+			cfg.ConnMaxRequests = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_CONN_MAX_REQUESTS value '%s', connections are not recycled by request count", envMaxRequests))
+		}
+	}
+	if envMaxAge := os.Getenv("STRESSER_CONN_MAX_AGE"); envMaxAge != "" {
+		cfg.ConnMaxAge = envMaxAge
+	}
+	if envIdle := os.Getenv("STRESSER_MAX_IDLE_CONNS"); envIdle != "" {
+		var n int
+		if _, err := fmt.Sscan(envIdle, &n); err == nil && n >= 0 {
+			cfg.MaxIdleConns = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_MAX_IDLE_CONNS value '%s', using the Go default", envIdle))
+		}
+	}
+	if envIdlePerHost := os.Getenv("STRESSER_MAX_IDLE_CONNS_PER_HOST"); envIdlePerHost != "" {
+		var n int
+		if _, err := fmt.Sscan(envIdlePerHost, &n); err == nil && n >= 0 {
+			cfg.MaxIdleConnsPerHost = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_MAX_IDLE_CONNS_PER_HOST value '%s', using the Go default", envIdlePerHost))
+		}
+	}
+	if envConnsPerHost := os.Getenv("STRESSER_MAX_CONNS_PER_HOST"); envConnsPerHost != "" {
+		var n int
+		if _, err := fmt.Sscan(envConnsPerHost, &n); err == nil && n >= 0 {
+			cfg.MaxConnsPerHost = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_MAX_CONNS_PER_HOST value '%s', connections per host are not limited", envConnsPerHost))
+		}
+	}
+	if envIdleTimeout := os.Getenv("STRESSER_IDLE_CONN_TIMEOUT"); envIdleTimeout != "" {
+		cfg.IdleConnTimeout = envIdleTimeout
+	}
+	if envRetryMode := os.Getenv("STRESSER_RETRY_MODE"); envRetryMode != "" {
+		cfg.RetryMode = strings.ToLower(envRetryMode)
+	}
+	if envAttempts := os.Getenv("STRESSER_RETRY_MAX_ATTEMPTS"); envAttempts != "" {
+		var n int
+		if _, err := fmt.Sscan(envAttempts, &n); err == nil && n >= 0 {
+			cfg.RetryMaxAttempts = n
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_RETRY_MAX_ATTEMPTS value '%s', using the SDK default", envAttempts))
+		}
+	}
+	if envBackoff := os.Getenv("STRESSER_RETRY_MAX_BACKOFF"); envBackoff != "" {
+		cfg.RetryMaxBackoff = envBackoff
+	}
+
 	if genManifest := os.Getenv("STRESSER_GENERATE_MANIFEST"); genManifest != "" {
 		if genManifest == "true" {
 			cfg.GenerateManifest = true
@@ -125,6 +549,307 @@ func LoadConfig(configPath string) (*Config, error) {
 		}
 	}
 
+	if quiet := os.Getenv("STRESSER_QUIET"); quiet != "" {
+		if quiet == "true" {
+			cfg.Quiet = true
+		} else if quiet == "false" {
+			cfg.Quiet = false
+		}
+	}
+
+	if envReplayFile := os.Getenv("STRESSER_REPLAY_FILE"); envReplayFile != "" {
+		cfg.ReplayFile = envReplayFile
+	}
+	if envReplaySpeed := os.Getenv("STRESSER_REPLAY_SPEED"); envReplaySpeed != "" {
+		var speed float64
+		if _, err := fmt.Sscan(envReplaySpeed, &speed); err == nil && speed > 0 {
+			cfg.ReplaySpeed = speed
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_REPLAY_SPEED value '%s', using default %v", envReplaySpeed, DefaultReplaySpeed))
+		}
+	}
+	if envReplayTiming := os.Getenv("STRESSER_REPLAY_TIMING"); envReplayTiming != "" {
+		cfg.ReplayTiming = strings.ToLower(envReplayTiming)
+	}
+	if envSizeSweep := os.Getenv("STRESSER_SIZE_SWEEP"); envSizeSweep != "" {
+		cfg.SizeSweep = envSizeSweep
+	}
+	if envConcurrencySweep := os.Getenv("STRESSER_CONCURRENCY_SWEEP"); envConcurrencySweep != "" {
+		cfg.ConcurrencySweep = envConcurrencySweep
+	}
+	if envPrefixDepth := os.Getenv("STRESSER_PREFIX_DEPTH"); envPrefixDepth != "" {
+		var depth int
+		if _, err := fmt.Sscan(envPrefixDepth, &depth); err == nil && depth >= 0 {
+			cfg.PrefixDepth = depth
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_PREFIX_DEPTH value '%s', per-prefix stats disabled", envPrefixDepth))
+		}
+	}
+	if envOutputFormat := os.Getenv("STRESSER_OUTPUT_FORMAT"); envOutputFormat != "" {
+		cfg.OutputFormat = strings.ToLower(envOutputFormat)
+	}
+	if envCheckpoint := os.Getenv("STRESSER_CHECKPOINT"); envCheckpoint != "" {
+		cfg.Checkpoint = envCheckpoint
+	}
+	if envSampleRate := os.Getenv("STRESSER_SAMPLE_RATE"); envSampleRate != "" {
+		var rate float64
+		if _, err := fmt.Sscan(envSampleRate, &rate); err == nil {
+			cfg.SampleRate = rate
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_SAMPLE_RATE value '%s', keeping all results", envSampleRate))
+		}
+	}
+	if envSampleMax := os.Getenv("STRESSER_SAMPLE_MAX"); envSampleMax != "" {
+		var limit int
+		if _, err := fmt.Sscan(envSampleMax, &limit); err == nil {
+			cfg.SampleMax = limit
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_SAMPLE_MAX value '%s', keeping all results", envSampleMax))
+		}
+	}
+	if envSummaryFormat := os.Getenv("STRESSER_SUMMARY_FORMAT"); envSummaryFormat != "" {
+		cfg.SummaryFormat = strings.ToLower(envSummaryFormat)
+	}
+	if envOutliers := os.Getenv("STRESSER_OUTLIER_COUNT"); envOutliers != "" {
+		var count int
+		if _, err := fmt.Sscan(envOutliers, &count); err == nil && count >= 0 {
+			cfg.OutlierCount = count
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_OUTLIER_COUNT value '%s', using default %d", envOutliers, DefaultOutlierCount))
+		}
+	}
+	if trackWrites := os.Getenv("STRESSER_TRACK_WRITES"); trackWrites != "" {
+		if trackWrites == "true" {
+			cfg.TrackWrites = true
+		} else if trackWrites == "false" {
+			cfg.TrackWrites = false
+		}
+	}
+	if envSlowThreshold := os.Getenv("STRESSER_SLOW_THRESHOLD"); envSlowThreshold != "" {
+		cfg.SlowThreshold = envSlowThreshold
+	}
+	if envSlowLog := os.Getenv("STRESSER_SLOW_LOG"); envSlowLog != "" {
+		cfg.SlowLog = envSlowLog
+	}
+	if envNIC := os.Getenv("STRESSER_NIC_INTERFACE"); envNIC != "" {
+		cfg.NICInterface = envNIC
+	}
+	if envHDRLog := os.Getenv("STRESSER_HDR_LOG"); envHDRLog != "" {
+		cfg.HDRLog = envHDRLog
+	}
+	if envSLO := os.Getenv("STRESSER_SLO"); envSLO != "" {
+		cfg.SLOSpec = envSLO
+	}
+	if envTimeSeries := os.Getenv("STRESSER_TIMESERIES"); envTimeSeries != "" {
+		cfg.TimeSeries = envTimeSeries
+	}
+	if envHTMLReport := os.Getenv("STRESSER_HTML_REPORT"); envHTMLReport != "" {
+		cfg.HTMLReport = envHTMLReport
+	}
+	if envTraceFile := os.Getenv("STRESSER_TRACE_FILE"); envTraceFile != "" {
+		cfg.TraceFile = envTraceFile
+	}
+	if verify := os.Getenv("STRESSER_VERIFY"); verify != "" {
+		if verify == "true" {
+			cfg.Verify = true
+		} else if verify == "false" {
+			cfg.Verify = false
+		}
+	}
+	if envChecksum := os.Getenv("STRESSER_CHECKSUM"); envChecksum != "" {
+		cfg.Checksum = envChecksum
+	}
+	if envSSE := os.Getenv("STRESSER_SSE"); envSSE != "" {
+		cfg.SSE = strings.ToLower(envSSE)
+	}
+	if envKMSKey := os.Getenv("STRESSER_SSE_KMS_KEY_ID"); envKMSKey != "" {
+		cfg.SSEKMSKeyID = envKMSKey
+	}
+	if envCustomerKey := os.Getenv("STRESSER_SSE_C_KEY"); envCustomerKey != "" {
+		cfg.SSECustomerKey = envCustomerKey
+	}
+	if envAcceptEncoding := os.Getenv("STRESSER_ACCEPT_ENCODING"); envAcceptEncoding != "" {
+		cfg.AcceptEncoding = envAcceptEncoding
+	}
+	if envMetadata := os.Getenv("STRESSER_METADATA"); envMetadata != "" {
+		cfg.MetadataSpec = envMetadata
+	}
+	if envContentType := os.Getenv("STRESSER_CONTENT_TYPE"); envContentType != "" {
+		cfg.ContentType = envContentType
+	}
+	if envCacheControl := os.Getenv("STRESSER_CACHE_CONTROL"); envCacheControl != "" {
+		cfg.CacheControl = envCacheControl
+	}
+	if envTags := os.Getenv("STRESSER_TAGS"); envTags != "" {
+		cfg.TagSpec = envTags
+	}
+	if envAgents := os.Getenv("STRESSER_AGENTS"); envAgents != "" {
+		cfg.Agents = envAgents
+	}
+	if envToken := os.Getenv("STRESSER_AGENT_TOKEN"); envToken != "" {
+		cfg.AgentToken = envToken
+	}
+	if insecure := os.Getenv("STRESSER_AGENT_INSECURE"); insecure != "" {
+		if insecure == "true" {
+			cfg.AgentInsecure = true
+		} else if insecure == "false" {
+			cfg.AgentInsecure = false
+		}
+	}
+	if envShard := os.Getenv("STRESSER_SHARD"); envShard != "" {
+		cfg.Shard = envShard
+	}
+	if presign := os.Getenv("STRESSER_PRESIGN"); presign != "" {
+		if presign == "true" {
+			cfg.Presign = true
+		} else if presign == "false" {
+			cfg.Presign = false
+		}
+	}
+	if preflight := os.Getenv("STRESSER_PREFLIGHT"); preflight != "" {
+		if preflight == "true" {
+			cfg.Preflight = true
+		} else if preflight == "false" {
+			cfg.Preflight = false
+		}
+	}
+	if createBucket := os.Getenv("STRESSER_CREATE_BUCKET"); createBucket != "" {
+		if createBucket == "true" {
+			cfg.CreateBucket = true
+		} else if createBucket == "false" {
+			cfg.CreateBucket = false
+		}
+	}
+	if envRamp := os.Getenv("STRESSER_RAMP"); envRamp != "" {
+		cfg.Ramp = envRamp
+	}
+	if envTargetP99 := os.Getenv("STRESSER_TARGET_P99"); envTargetP99 != "" {
+		cfg.TargetP99 = envTargetP99
+	}
+	if envCPUBudget := os.Getenv("STRESSER_CPU_BUDGET"); envCPUBudget != "" {
+		var budget float64
+		if _, err := fmt.Sscan(envCPUBudget, &budget); err == nil {
+			cfg.CPUBudget = budget
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_CPU_BUDGET value '%s', autoscaling disabled", envCPUBudget))
+		}
+	}
+	if envGoMaxProcs := os.Getenv("STRESSER_GOMAXPROCS"); envGoMaxProcs != "" {
+		var procs int
+		if _, err := fmt.Sscan(envGoMaxProcs, &procs); err == nil {
+			cfg.GoMaxProcs = procs
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_GOMAXPROCS value '%s', using the Go default", envGoMaxProcs))
+		}
+	}
+	if lockThreads := os.Getenv("STRESSER_LOCK_THREADS"); lockThreads != "" {
+		if lockThreads == "true" {
+			cfg.LockThreads = true
+		} else if lockThreads == "false" {
+			cfg.LockThreads = false
+		}
+	}
+	if envThreadPool := os.Getenv("STRESSER_THREAD_POOL"); envThreadPool != "" {
+		var threads int
+		if _, err := fmt.Sscan(envThreadPool, &threads); err == nil {
+			cfg.ThreadPool = threads
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_THREAD_POOL value '%s', using one thread per worker", envThreadPool))
+		}
+	}
+	if envWarmup := os.Getenv("STRESSER_WARMUP"); envWarmup != "" {
+		cfg.Warmup = envWarmup
+	}
+	if warmupResults := os.Getenv("STRESSER_WARMUP_RESULTS"); warmupResults != "" {
+		if warmupResults == "true" {
+			cfg.WarmupResults = true
+		} else if warmupResults == "false" {
+			cfg.WarmupResults = false
+		}
+	}
+	if envJitter := os.Getenv("STRESSER_JITTER"); envJitter != "" {
+		cfg.Jitter = envJitter
+	}
+	if envThinkTime := os.Getenv("STRESSER_THINK_TIME"); envThinkTime != "" {
+		cfg.ThinkTime = envThinkTime
+	}
+	if envHedge := os.Getenv("STRESSER_HEDGE_QUANTILE"); envHedge != "" {
+		var q float64
+		if _, err := fmt.Sscan(envHedge, &q); err == nil && q >= 0 {
+			cfg.HedgeQuantile = q
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_HEDGE_QUANTILE value '%s', hedging disabled", envHedge))
+		}
+	}
+	if envRPS := os.Getenv("STRESSER_RPS"); envRPS != "" {
+		var rps float64
+		if _, err := fmt.Sscan(envRPS, &rps); err == nil && rps >= 0 {
+			cfg.RPS = rps
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_RPS value '%s', rate limit disabled", envRPS))
+		}
+	}
+	if envArrival := os.Getenv("STRESSER_ARRIVAL_RATE"); envArrival != "" {
+		var rate float64
+		if _, err := fmt.Sscan(envArrival, &rate); err == nil && rate >= 0 {
+			cfg.ArrivalRate = rate
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_ARRIVAL_RATE value '%s', open-loop mode disabled", envArrival))
+		}
+	}
+	if envBandwidth := os.Getenv("STRESSER_BANDWIDTH_LIMIT"); envBandwidth != "" {
+		var limit float64
+		if _, err := fmt.Sscan(envBandwidth, &limit); err == nil && limit >= 0 {
+			cfg.BandwidthLimit = limit
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_BANDWIDTH_LIMIT value '%s', bandwidth limit disabled", envBandwidth))
+		}
+	}
+	if envWorkerRPS := os.Getenv("STRESSER_WORKER_RPS"); envWorkerRPS != "" {
+		var rps float64
+		if _, err := fmt.Sscan(envWorkerRPS, &rps); err == nil && rps >= 0 {
+			cfg.WorkerRPS = rps
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_WORKER_RPS value '%s', per-worker rate limit disabled", envWorkerRPS))
+		}
+	}
+	if envSecondary := os.Getenv("STRESSER_SECONDARY_ENDPOINT"); envSecondary != "" {
+		cfg.SecondaryEndpoint = envSecondary
+	}
+	if envThreshold := os.Getenv("STRESSER_FAILOVER_THRESHOLD"); envThreshold != "" {
+		var threshold int
+		if _, err := fmt.Sscan(envThreshold, &threshold); err == nil && threshold > 0 {
+			cfg.FailoverThreshold = threshold
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_FAILOVER_THRESHOLD value '%s', using default %d", envThreshold, DefaultFailoverThreshold))
+		}
+	}
+	if envDisconnect := os.Getenv("STRESSER_DISCONNECT_FRACTION"); envDisconnect != "" {
+		var fraction float64
+		if _, err := fmt.Sscan(envDisconnect, &fraction); err == nil {
+			cfg.DisconnectFraction = fraction
+		} else {
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_DISCONNECT_FRACTION value '%s', disconnect simulation disabled", envDisconnect))
+		}
+	}
+
+	if progress := os.Getenv("STRESSER_PROGRESS"); progress != "" {
+		cfg.Progress = progress
+	}
+	if control := os.Getenv("STRESSER_CONTROL"); control != "" {
+		cfg.Control = control
+	}
+	if pprofAddr := os.Getenv("STRESSER_PPROF_ADDR"); pprofAddr != "" {
+		cfg.PprofAddr = pprofAddr
+	}
+	if tui := os.Getenv("STRESSER_TUI"); tui != "" {
+		if tui == "true" {
+			cfg.TUI = true
+		} else if tui == "false" {
+			cfg.TUI = false
+		}
+	}
+
 	// Handle log level environment variable
 	if logLevel := os.Getenv("STRESSER_LOG_LEVEL"); logLevel != "" {
 		// Validate the log level
@@ -132,17 +857,12 @@ func LoadConfig(configPath string) (*Config, error) {
 		case "debug", "info", "warn", "error":
 			cfg.LogLevel = strings.ToLower(logLevel)
 		default:
-			fmt.Fprintf(os.Stderr, "Warning: Invalid STRESSER_LOG_LEVEL value '%s', using default '%s'\n", logLevel, DefaultLogLevel)
+			slog.Warn(fmt.Sprintf("Invalid STRESSER_LOG_LEVEL value '%s', using default '%s'", logLevel, DefaultLogLevel))
 		}
 	}
 
-	// Basic validation (before applying flags)
-	if cfg.Endpoint == "" {
-		return nil, fmt.Errorf("endpoint URL is required (set via -config file, AWS_ENDPOINT_URL env var)")
-	}
-	if cfg.Bucket == "" {
-		return nil, fmt.Errorf("bucket name is required (set via -config file, S3_BUCKET env var)")
-	}
+	// The bucket and endpoint are checked in Validate, since -buckets may still supply the
+	// bucket and the gcs backend doesn't need an endpoint
 	// Note: AccessKey/SecretKey might not be required if using EC2 instance profiles, etc.
 	// SDK handles this, so we don't enforce it here.
 
@@ -180,6 +900,11 @@ func (c *Config) ApplyFlags(duration string, concurrency int, randomize bool, ma
 
 // Validate ensures the final configuration (after flags) is valid.
 func (c *Config) Validate() error {
+	// The phases of a test plan are validated as configurations of their own
+	if c.Plan != "" || len(c.Phases) > 0 {
+		return c.validatePlan()
+	}
+
 	// Required fields from flags/args
 	if c.Duration == "" {
 		return fmt.Errorf("duration (-d) is required")
@@ -187,26 +912,536 @@ func (c *Config) Validate() error {
 	if c.Concurrency <= 0 {
 		return fmt.Errorf("concurrency (-c) must be greater than 0")
 	}
-	if c.ManifestPath == "" {
-		return fmt.Errorf("manifest file path argument is required")
-	}
 	if c.OutputFile == "" {
-		return fmt.Errorf("output csv file path (-o) is required")
+		return fmt.Errorf("output file path (-o) is required")
+	}
+	if c.OutputFile == StdoutPath && c.SummaryFile == StdoutPath {
+		return fmt.Errorf("detailed results (-o) and summary (-summary) cannot both be written to stdout")
+	}
+
+	if err := c.validateEndpoints(); err != nil {
+		return err
+	}
+	switch strings.ToLower(c.Backend) {
+	case "", BackendS3:
+		c.Backend = BackendS3
+		if c.Endpoint == "" {
+			return fmt.Errorf("endpoint URL is required (set via -config file, AWS_ENDPOINT_URL env var or -endpoints)")
+		}
+	case BackendGCS:
+		c.Backend = BackendGCS
+		if c.Presign || c.SecondaryEndpoint != "" || len(c.Endpoints) > 0 || c.DisconnectFraction > 0 || c.BandwidthLimit > 0 {
+			return fmt.Errorf("the gcs backend (-backend) does not support -presign, -secondary-endpoint, -endpoints, -disconnect-at or -bandwidth-limit")
+		}
+	default:
+		return fmt.Errorf("invalid backend (-backend): %s. Must be 's3' or 'gcs'", c.Backend)
+	}
+
+	if err := c.validateBuckets(); err != nil {
+		return err
+	}
+	if err := c.validateScenario(); err != nil {
+		return err
 	}
 
 	// Validate OperationType
 	opLower := strings.ToLower(c.OperationType)
 	switch opLower {
-	case "read", "write", "mixed":
+	case "read", "write", "mixed", "head", "list", "revalidate", "copy", "tagging", "versioned", "consistency", "contention", "append", "replay", "scenario":
 		c.OperationType = opLower // Normalize
 	default:
-		return fmt.Errorf("invalid operation type (-op): %s. Must be 'read', 'write', or 'mixed'", c.OperationType)
+		return fmt.Errorf("invalid operation type (-op): %s. Must be 'read', 'write', 'mixed', 'head', 'list', 'revalidate', 'copy', 'tagging', 'versioned', 'consistency', 'contention', 'append', 'replay' or 'scenario'", c.OperationType)
+	}
+	if c.RevalidateStale < 0 || c.RevalidateStale > 1 {
+		return fmt.Errorf("revalidate stale fraction (-revalidate-stale) must be in the range [0, 1], got %v", c.RevalidateStale)
+	}
+	// Only modes that pick keys from a manifest, or write one, need its path
+	if c.ManifestPath == "" && len(c.Keys) == 0 {
+		switch c.OperationType {
+		case "read", "mixed", "head", "revalidate", "copy", "tagging", "versioned":
+			return fmt.Errorf("manifest file path argument is required for '%s' mode", c.OperationType)
+		case "write":
+			if c.GenerateManifest {
+				return fmt.Errorf("manifest file path argument is required for 'write' mode unless manifest generation is disabled (-genmf=false)")
+			}
+		}
+	}
+	if c.CreateBucket && c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support creating buckets (-create-bucket)")
+	}
+	if c.OperationType == "revalidate" && c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support 'revalidate' mode")
+	}
+	if c.OperationType == "tagging" && c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support 'tagging' mode")
+	}
+	if c.OperationType == "versioned" {
+		if c.Backend == BackendGCS {
+			return fmt.Errorf("the gcs backend (-backend) does not support 'versioned' mode")
+		}
+		if len(c.Buckets) > 1 {
+			return fmt.Errorf("'versioned' mode supports a single bucket, not -buckets")
+		}
+		if _, err := parseVersionMix(c.VersionMix); err != nil {
+			return fmt.Errorf("%w (-version-mix)", err)
+		}
+	}
+	if c.OperationType == "copy" {
+		if c.Backend == BackendGCS || c.Presign {
+			return fmt.Errorf("'copy' mode is not supported with the gcs backend (-backend) or -presign")
+		}
+		if c.CopyPartSizeMB < 0 || (c.CopyPartSizeMB > 0 && c.CopyPartSizeMB < 5) {
+			return fmt.Errorf("copy part size (-copy-part-size) must be at least 5 MB, the S3 minimum part size, or 0 to copy objects up to 5 GiB in one call")
+		}
+		if c.DisconnectFraction > 0 {
+			return fmt.Errorf("'copy' mode cannot be combined with -disconnect-at, which cuts single-request PUTs only, not copies or multipart uploads")
+		}
+	}
+	if _, err := parseKeyTemplate(c.KeyTemplate, defaultWorkerKeyTemplate); err != nil {
+		return fmt.Errorf("%w (-key-template)", err)
+	}
+	keyDist, err := parseKeyDistribution(c.KeyDistribution, c.Randomize)
+	if err != nil {
+		return fmt.Errorf("%w (-distribution)", err)
+	}
+	c.KeyDistribution = keyDist.String()
+
+	if c.ListPageSize == 0 {
+		c.ListPageSize = DefaultListPageSize
+	}
+	if c.ListPageSize < 0 || c.ListPageSize > DefaultListPageSize {
+		return fmt.Errorf("list page size (-list-page-size) must be between 1 and %d", DefaultListPageSize)
+	}
+
+	// Validate replay parameters
+	if c.OperationType == "replay" {
+		if c.ReplayFile == "" {
+			return fmt.Errorf("replay file (-replay) is required for 'replay' mode")
+		}
+		if c.ReplaySpeed <= 0 {
+			return fmt.Errorf("replay speed (-replay-speed) must be greater than 0")
+		}
+		switch c.ReplayTiming {
+		case "":
+			c.ReplayTiming = DefaultReplayTiming
+		case "original", "scaled", "asap":
+		default:
+			return fmt.Errorf("invalid replay timing (-replay-timing): %s. Must be 'original', 'scaled' or 'asap'", c.ReplayTiming)
+		}
+		switch c.ReplayFormat {
+		case "", "csv", "s3log", "trace":
+		default:
+			return fmt.Errorf("invalid replay format (-replay-format): %s. Must be 'csv', 's3log' or 'trace'", c.ReplayFormat)
+		}
+	}
+
+	// Validate consistency parameters
+	if _, err := parseConsistencyDelays(c.ConsistencyDelays); err != nil {
+		return fmt.Errorf("invalid consistency delays (-consistency-delays): %w", err)
+	}
+	c.ConsistencyProbe = strings.ToLower(c.ConsistencyProbe)
+	switch c.ConsistencyProbe {
+	case "":
+		c.ConsistencyProbe = "get"
+	case "get", "head", "list":
+	default:
+		return fmt.Errorf("invalid consistency probe (-consistency-probe): %s. Must be 'get', 'head' or 'list'", c.ConsistencyProbe)
+	}
+	if c.OperationType == "consistency" && c.DisconnectFraction > 0 {
+		return fmt.Errorf("'consistency' mode cannot be combined with -disconnect-at, interrupted PUTs leave nothing to read back")
+	}
+
+	if err := c.validateContention(); err != nil {
+		return err
+	}
+	if err := c.validateWriteMode(); err != nil {
+		return err
+	}
+	if err := c.validateAppend(); err != nil {
+		return err
 	}
 
 	// Validate PutObjectSizeKB if relevant
-	if c.OperationType == "write" || c.OperationType == "mixed" {
+	if c.OperationType == "write" || c.OperationType == "mixed" || c.OperationType == "consistency" || c.OperationType == "contention" {
 		if c.PutObjectSizeKB <= 0 {
-			return fmt.Errorf("put object size (-putsize) must be greater than 0 KB for 'write' or 'mixed' mode")
+			return fmt.Errorf("put object size (-putsize) must be greater than 0 KB for 'write', 'mixed', 'consistency' or 'contention' mode")
+		}
+	}
+
+	if c.PutSizeDistribution != "" {
+		d, err := parseSizeDistribution(c.PutSizeDistribution, c.PutObjectSizeKB)
+		if err != nil {
+			return fmt.Errorf("invalid PUT size distribution (-putsize-dist): %w", err)
+		}
+		c.PutSizeDistribution = d.String()
+	}
+	pattern, err := parseDataPattern(c.DataPattern)
+	if err != nil {
+		return fmt.Errorf("invalid data pattern (-data-pattern): %w", err)
+	}
+	if c.DataPattern != "" {
+		c.DataPattern = pattern.String()
+	}
+
+	// Validate size sweep
+	if c.SizeSweep != "" {
+		if c.PutSizeDistribution != "" {
+			return fmt.Errorf("size sweep (-size-sweep) and PUT size distribution (-putsize-dist) cannot be combined")
+		}
+		if _, err := ParseSizeList(c.SizeSweep); err != nil {
+			return fmt.Errorf("invalid size sweep (-size-sweep): %w", err)
+		}
+		if c.OperationType != "write" && c.OperationType != "mixed" {
+			return fmt.Errorf("size sweep (-size-sweep) requires 'write' or 'mixed' mode")
+		}
+	}
+
+	// Validate concurrency sweep
+	if c.ConcurrencySweep != "" {
+		if _, err := ParseIntList(c.ConcurrencySweep); err != nil {
+			return fmt.Errorf("invalid concurrency sweep (-concurrency-sweep): %w", err)
+		}
+		if c.SizeSweep != "" {
+			return fmt.Errorf("size sweep (-size-sweep) and concurrency sweep (-concurrency-sweep) cannot be combined")
+		}
+	}
+
+	if c.Agents != "" {
+		agents, err := ParseAgentList(c.Agents)
+		if err != nil {
+			return fmt.Errorf("invalid agent list (-agents): %w", err)
+		}
+		if c.AgentToken == "" {
+			return fmt.Errorf("distributed runs (-agents) require the token the agents were started with (-agent-token)")
+		}
+		if secrets := c.SecretKey != "" || c.SSECustomerKey != "" || c.GCSCredentials != ""; secrets && !c.AgentInsecure {
+			for _, agent := range agents {
+				if !strings.HasPrefix(agent, "https://") {
+					return fmt.Errorf("agent %s is not an https:// URL and the run carries credentials; start the agents with -tls-cert/-tls-key or allow plain HTTP with -agent-insecure", agent)
+				}
+			}
+		}
+		if c.OperationType == "replay" || c.OperationType == "versioned" || c.SizeSweep != "" || c.ConcurrencySweep != "" {
+			return fmt.Errorf("distributed runs (-agents) cannot be combined with 'replay' or 'versioned' mode or sweeps")
+		}
+	}
+
+	if c.PrefixDepth < 0 {
+		return fmt.Errorf("prefix depth (-prefix-depth) must not be negative")
+	}
+
+	if c.OutputFormat == "" {
+		c.OutputFormat = InferResultFormat(c.OutputFile)
+	}
+	c.OutputFormat = strings.ToLower(c.OutputFormat)
+	if err := checkResultFormat(c.OutputFormat); err != nil {
+		return fmt.Errorf("invalid output format (-format): %w", err)
+	}
+	if c.OutputFormat == FormatSQLite && c.OutputFile == StdoutPath {
+		return fmt.Errorf("sqlite output (-format sqlite) is a database file and cannot be written to stdout (-o -), use 'sql' for a script")
+	}
+	if c.Checkpoint != "" {
+		if d, err := time.ParseDuration(c.Checkpoint); err != nil || d <= 0 {
+			return fmt.Errorf("invalid checkpoint interval (-checkpoint) %q: must be a positive duration", c.Checkpoint)
+		}
+		if c.OutputFormat != FormatCSV && c.OutputFormat != FormatJSONL && c.OutputFormat != FormatJSON {
+			return fmt.Errorf("checkpointing (-checkpoint) requires csv, jsonl or json output (-format), got %q", c.OutputFormat)
+		}
+		if c.Agents != "" || c.SizeSweep != "" || c.ConcurrencySweep != "" {
+			return fmt.Errorf("checkpointing (-checkpoint) is not supported with -agents or sweeps")
+		}
+	}
+	if err := c.validateSampling(); err != nil {
+		return err
+	}
+	if err := c.validateTLS(); err != nil {
+		return err
+	}
+	if err := c.validateSigning(); err != nil {
+		return err
+	}
+	if err := c.validateAssumeRole(); err != nil {
+		return err
+	}
+	if err := c.validateSlowLog(); err != nil {
+		return err
+	}
+	if err := c.validateShard(); err != nil {
+		return err
+	}
+	if c.SummaryFormat == "" {
+		c.SummaryFormat = DefaultSummaryFormat
+	}
+	c.SummaryFormat = strings.ToLower(c.SummaryFormat)
+	if err := checkSummaryFormat(c.SummaryFormat); err != nil {
+		return fmt.Errorf("invalid summary format (-summary-format): %w", err)
+	}
+
+	if c.OutlierCount < 0 {
+		return fmt.Errorf("outlier count (-outliers) must not be negative")
+	}
+
+	switch c.SSE {
+	case "":
+		c.SSE = SSENone
+	case SSENone, SSES3, SSEKMS, SSEC:
+	default:
+		return fmt.Errorf("invalid server-side encryption (-sse): %s. Must be 'none', 'sse-s3', 'sse-kms' or 'sse-c'", c.SSE)
+	}
+	if c.SSEKMSKeyID != "" && c.SSE != SSEKMS {
+		return fmt.Errorf("a KMS key (-sse-kms-key-id) requires -sse sse-kms")
+	}
+	if c.SSE == SSEC {
+		if _, err := parseSSECustomerKey(c.SSECustomerKey); err != nil {
+			return fmt.Errorf("invalid SSE-C key (-sse-c-key): %w", err)
+		}
+	} else if c.SSECustomerKey != "" {
+		return fmt.Errorf("a customer key (-sse-c-key) requires -sse sse-c")
+	}
+	if c.SSE != SSENone && c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support server-side encryption options (-sse)")
+	}
+	if err := c.validateChecksum(); err != nil {
+		return err
+	}
+	if err := c.validateAcceptEncoding(); err != nil {
+		return err
+	}
+
+	if c.MetadataSpec != "" {
+		md, err := ParseKeyValues(c.MetadataSpec)
+		if err != nil {
+			return fmt.Errorf("invalid metadata (-metadata): %w", err)
+		}
+		if c.Metadata == nil {
+			c.Metadata = make(map[string]string)
+		}
+		for k, v := range md {
+			c.Metadata[k] = v
+		}
+		c.MetadataSpec = ""
+	}
+	if err := validateMetadata(c.Metadata); err != nil {
+		return fmt.Errorf("%w (-metadata)", err)
+	}
+	if c.TagSpec != "" {
+		tags, err := ParseKeyValues(c.TagSpec)
+		if err != nil {
+			return fmt.Errorf("invalid tags (-tags): %w", err)
+		}
+		if c.Tags == nil {
+			c.Tags = make(map[string]string)
+		}
+		for k, v := range tags {
+			c.Tags[k] = v
+		}
+		c.TagSpec = ""
+	}
+	if err := validateTags(c.Tags); err != nil {
+		return fmt.Errorf("%w (-tags)", err)
+	}
+	if len(c.Tags) > 0 && c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support object tags (-tags)")
+	}
+
+	if c.SLOSpec != "" {
+		slos, err := ParseSLOs(c.SLOSpec)
+		if err != nil {
+			return fmt.Errorf("invalid SLOs (-slo): %w", err)
+		}
+		if c.SLOs == nil {
+			c.SLOs = make(map[string]float64)
+		}
+		for name, threshold := range slos {
+			c.SLOs[name] = threshold
+		}
+		c.SLOSpec = ""
+	}
+	if err := validateSLOs(c.SLOs); err != nil {
+		return err
+	}
+
+	// Validate load shape; it determines worker count and duration
+	if c.Ramp != "" {
+		stages, err := ParseRamp(c.Ramp)
+		if err != nil {
+			return fmt.Errorf("invalid ramp (-ramp): %w", err)
+		}
+		c.Stages = stages
+	}
+	if len(c.Stages) > 0 {
+		peak, total, err := validateStages(c.Stages)
+		if err != nil {
+			return fmt.Errorf("invalid load stages (-ramp): %w", err)
+		}
+		if c.CPUBudget > 0 || c.ConcurrencySweep != "" {
+			return fmt.Errorf("load stages (-ramp) cannot be combined with -cpu-budget or -concurrency-sweep")
+		}
+		if c.OperationType == "replay" || (c.OperationType == "write" && c.FileCount > 0) {
+			return fmt.Errorf("load stages (-ramp) require continuous workers, not 'replay' mode or -files generation")
+		}
+		c.Concurrency = peak
+		c.Duration = total.String()
+	}
+
+	if c.Warmup != "" {
+		if d, err := time.ParseDuration(c.Warmup); err != nil || d < 0 {
+			return fmt.Errorf("invalid warm-up period (-warmup) %q: must be a non-negative duration", c.Warmup)
+		}
+		if len(c.Stages) > 0 {
+			return fmt.Errorf("warm-up period (-warmup) cannot be combined with load stages (-ramp), use a first stage instead")
+		}
+	}
+
+	if c.CPUBudget < 0 || c.CPUBudget > 100 {
+		return fmt.Errorf("cpu budget (-cpu-budget) must be between 0 and 100 percent, got %v", c.CPUBudget)
+	}
+	if err := c.validateScheduler(); err != nil {
+		return err
+	}
+	if c.TargetP99 != "" {
+		if d, err := time.ParseDuration(c.TargetP99); err != nil || d <= 0 {
+			return fmt.Errorf("invalid latency target (-target-p99) %q: must be a positive duration", c.TargetP99)
+		}
+		if c.CPUBudget > 0 || len(c.Stages) > 0 || c.ArrivalRate > 0 || c.SizeSweep != "" || c.ConcurrencySweep != "" || c.Agents != "" {
+			return fmt.Errorf("the latency target (-target-p99) cannot be combined with -cpu-budget, -ramp, -arrival-rate, sweeps or -agents")
+		}
+		if c.OperationType == "replay" || (c.OperationType == "write" && c.FileCount > 0) {
+			return fmt.Errorf("the latency target (-target-p99) requires continuous workers, not 'replay' mode or -files generation")
+		}
+	}
+
+	// Validate jitter distribution
+	if _, err := parseDelayDistribution(c.Jitter); err != nil {
+		return fmt.Errorf("invalid jitter (-jitter): %w", err)
+	}
+	if think, err := parseDelayDistribution(c.ThinkTime); err != nil {
+		return fmt.Errorf("invalid think time (-think-time): %w", err)
+	} else if think.enabled() {
+		if c.ArrivalRate > 0 || c.OperationType == "replay" {
+			return fmt.Errorf("think time (-think-time) paces closed-loop workers and cannot be combined with -arrival-rate or 'replay' mode")
+		}
+		c.ThinkTime = think.String() // Canonical form for the summary, e.g. "exp:" becomes "exponential:"
+	} else {
+		c.ThinkTime = ""
+	}
+
+	if c.HedgeQuantile < 0 || c.HedgeQuantile >= 100 {
+		return fmt.Errorf("hedge quantile (-hedge-quantile) must be in the range [0, 100), got %v", c.HedgeQuantile)
+	}
+	if c.RPS < 0 {
+		return fmt.Errorf("request rate (-rps) must not be negative")
+	}
+	if c.WorkerRPS < 0 {
+		return fmt.Errorf("per-worker rate (-worker-rps) must not be negative")
+	}
+	if c.ArrivalRate < 0 {
+		return fmt.Errorf("arrival rate (-arrival-rate) must not be negative")
+	}
+	if c.ArrivalRate > 0 {
+		if c.RPS > 0 || c.WorkerRPS > 0 {
+			return fmt.Errorf("open-loop mode (-arrival-rate) cannot be combined with -rps or -worker-rps")
+		}
+		if c.OperationType == "replay" || (c.OperationType == "write" && c.FileCount > 0) {
+			return fmt.Errorf("open-loop mode (-arrival-rate) does not apply to 'replay' mode or -files generation")
+		}
+	}
+	if c.BandwidthLimit < 0 {
+		return fmt.Errorf("bandwidth limit (-bandwidth-limit) must not be negative")
+	}
+
+	// Validate disconnect simulation: the cut must happen before the body is complete
+	if c.DisconnectFraction < 0 || c.DisconnectFraction >= 1 {
+		return fmt.Errorf("disconnect fraction (-disconnect-at) must be in the range [0, 1), got %v", c.DisconnectFraction)
+	}
+
+	switch strings.ToLower(c.IPFamily) {
+	case "":
+		c.IPFamily = IPFamilyBoth
+	case IPFamilyBoth, IPFamilyV4, IPFamilyV6:
+		c.IPFamily = strings.ToLower(c.IPFamily)
+	default:
+		return fmt.Errorf("invalid IP family (-ip-family): %s. Must be 'ipv4', 'ipv6' or 'both'", c.IPFamily)
+	}
+	switch strings.ToLower(c.HTTPVersion) {
+	case "":
+		c.HTTPVersion = HTTPVersionAuto
+	case HTTPVersionAuto, HTTPVersion1, HTTPVersion2:
+		c.HTTPVersion = strings.ToLower(c.HTTPVersion)
+	default:
+		return fmt.Errorf("invalid HTTP version (-http-version): %s. Must be '1.1', '2' or 'auto'", c.HTTPVersion)
+	}
+
+	if strings.ContainsAny(c.RunID, " \t\r\n/") {
+		return fmt.Errorf("invalid run id (-run-id) %q: must not contain whitespace or '/'", c.RunID)
+	}
+	if strings.ContainsAny(c.UserAgent, "\r\n") {
+		return fmt.Errorf("invalid User-Agent suffix (-user-agent): must be a single line")
+	}
+
+	for _, h := range c.Headers {
+		if _, _, err := parseHeader(h); err != nil {
+			return fmt.Errorf("%w (-header)", err)
+		}
+	}
+
+	if c.TUI && (c.Quiet || c.Agents != "") {
+		return fmt.Errorf("the dashboard (-tui) cannot be combined with -quiet or distributed runs (-agents)")
+	}
+	if err := c.validateControl(); err != nil {
+		return err
+	}
+	if err := c.validatePprof(); err != nil {
+		return err
+	}
+	if c.Progress != "" && c.Progress != "0" {
+		if d, err := time.ParseDuration(c.Progress); err != nil || d <= 0 {
+			return fmt.Errorf("invalid progress interval (-progress) %q: must be a positive duration or 0", c.Progress)
+		}
+	}
+	if c.DNSRefresh != "" {
+		if d, err := time.ParseDuration(c.DNSRefresh); err != nil || d <= 0 {
+			return fmt.Errorf("invalid DNS refresh interval (-dns-refresh) %q: must be a positive duration", c.DNSRefresh)
+		}
+	}
+	if err := c.validateClientPerWorker(); err != nil {
+		return err
+	}
+	if c.ConnMaxRequests < 0 {
+		return fmt.Errorf("connection max requests (-conn-max-requests) must not be negative")
+	}
+	if c.ConnMaxAge != "" {
+		if d, err := time.ParseDuration(c.ConnMaxAge); err != nil || d <= 0 {
+			return fmt.Errorf("invalid connection max age (-conn-max-age) %q: must be a positive duration", c.ConnMaxAge)
+		}
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConnsPerHost < 0 || c.MaxConnsPerHost < 0 {
+		return fmt.Errorf("connection pool limits (-max-idle-conns, -max-idle-conns-per-host, -max-conns-per-host) must not be negative")
+	}
+	if c.IdleConnTimeout != "" {
+		if d, err := time.ParseDuration(c.IdleConnTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid idle connection timeout (-idle-conn-timeout) %q: must be a positive duration", c.IdleConnTimeout)
+		}
+	}
+
+	c.RetryMode = strings.ToLower(c.RetryMode)
+	if err := c.validateRetry(); err != nil {
+		return err
+	}
+
+	if c.Presign && c.DisconnectFraction > 0 {
+		return fmt.Errorf("presigned requests (-presign) cannot be combined with disconnect simulation (-disconnect-at)")
+	}
+
+	if c.SecondaryEndpoint != "" {
+		if c.FailoverThreshold == 0 {
+			c.FailoverThreshold = DefaultFailoverThreshold
+		}
+		if c.FailoverThreshold < 0 {
+			return fmt.Errorf("failover threshold (-failover-threshold) must be greater than 0")
+		}
+		if c.SecondaryEndpoint == c.Endpoint {
+			return fmt.Errorf("secondary endpoint (-secondary-endpoint) must differ from the primary endpoint")
 		}
 	}
 
@@ -102,6 +102,21 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "List mode without ManifestPath",
+			config: Config{
+				Endpoint:        "https://test-endpoint.com",
+				Region:          "us-east-1",
+				Bucket:          "test-bucket",
+				Duration:        "30s",
+				Concurrency:     5,
+				ManifestPath:    "",
+				OutputFile:      "results.csv",
+				OperationType:   "list",
+				PutObjectSizeKB: 256,
+			},
+			expectError: false,
+		},
 		{
 			name: "Missing OutputFile",
 			config: Config{
@@ -162,6 +177,83 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Invalid DisconnectFraction",
+			config: Config{
+				Endpoint:           "https://test-endpoint.com",
+				Region:             "us-east-1",
+				Bucket:             "test-bucket",
+				Duration:           "30s",
+				Concurrency:        5,
+				ManifestPath:       "manifest.txt",
+				OutputFile:         "results.csv",
+				OperationType:      "write",
+				PutObjectSizeKB:    256,
+				DisconnectFraction: 1.0,
+			},
+			expectError: true,
+		},
+		{
+			name: "Results and summary both on stdout",
+			config: Config{
+				Endpoint:      "https://test-endpoint.com",
+				Region:        "us-east-1",
+				Bucket:        "test-bucket",
+				Duration:      "30s",
+				Concurrency:   5,
+				ManifestPath:  "manifest.txt",
+				OutputFile:    "-",
+				SummaryFile:   "-",
+				OperationType: "read",
+			},
+			expectError: true,
+		},
+		{
+			name: "Negative RPS",
+			config: Config{
+				Endpoint:      "https://test-endpoint.com",
+				Region:        "us-east-1",
+				Bucket:        "test-bucket",
+				Duration:      "30s",
+				Concurrency:   5,
+				ManifestPath:  "manifest.txt",
+				OutputFile:    "results.csv",
+				OperationType: "read",
+				RPS:           -10,
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid warm-up",
+			config: Config{
+				Endpoint:      "https://test-endpoint.com",
+				Region:        "us-east-1",
+				Bucket:        "test-bucket",
+				Duration:      "30s",
+				Concurrency:   5,
+				ManifestPath:  "manifest.txt",
+				OutputFile:    "results.csv",
+				OperationType: "read",
+				Warmup:        "-5s",
+			},
+			expectError: true,
+		},
+		{
+			name: "Warm-up with ramp",
+			config: Config{
+				Endpoint:      "https://test-endpoint.com",
+				Region:        "us-east-1",
+				Bucket:        "test-bucket",
+				Duration:      "30s",
+				Concurrency:   5,
+				ManifestPath:  "manifest.txt",
+				OutputFile:    "results.csv",
+				OperationType: "read",
+				Warmup:        "10s",
+				Ramp:          "1..10/1m",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -301,7 +393,7 @@ insecureSkipVerify: true
 	}
 
 	// Test applying flag values
-	cfg.ApplyFlags("2m", 15, true, "flag-manifest.txt", "flag-output.csv", "write", 4096, 500, true)
+	cfg.ApplyFlags("2m", 15, true, "flag-manifest.txt", "flag-output.csv", "write", 4096, 500, true, "debug")
 
 	// Verify flag values override environment variables
 	if cfg.Duration != "2m" {
@@ -0,0 +1,132 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// dialFunc matches net.Dialer.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dnsCache resolves hostnames itself and spreads new connections round-robin over all
+// returned addresses. Entries are re-resolved once they are older than ttl, so new
+// connections follow changes behind a DNS-based load balancer. The standard dialer
+// instead always tries the addresses in resolver order and pins load to the first one.
+type dnsCache struct {
+	resolver *net.Resolver
+	ttl      time.Duration
+	mu       sync.Mutex
+	entries  map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	addrs    []string
+	resolved time.Time
+	next     int
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{resolver: net.DefaultResolver, ttl: ttl, entries: make(map[string]*dnsEntry)}
+}
+
+// addresses returns the IPs for host, rotated so successive calls start at successive addresses.
+func (c *dnsCache) addresses(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	if !ok || time.Since(entry.resolved) >= c.ttl {
+		c.mu.Unlock()
+		addrs, err := c.resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		entry = &dnsEntry{addrs: addrs, resolved: time.Now()}
+		c.entries[host] = entry
+	}
+	start := entry.next % len(entry.addrs)
+	entry.next++
+	rotated := append(append([]string(nil), entry.addrs[start:]...), entry.addrs[:start]...)
+	c.mu.Unlock()
+	return rotated, nil
+}
+
+// dialContext wraps dial so connections to hostnames go through the cache.
+func (c *dnsCache) dialContext(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+		addrs, err := c.addresses(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		tried := 0
+		for _, ip := range addrs {
+			if !familyMatches(network, ip) {
+				continue
+			}
+			tried++
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if tried == 0 {
+			return nil, fmt.Errorf("dial %s: no %s address among %v", host, network, addrs)
+		}
+		return nil, fmt.Errorf("dial %s: all %d addresses failed: %w", host, tried, lastErr)
+	}
+}
+
+// recyclingTransport closes HTTP/1.1 connections once they have served maxRequests requests
+// or are older than maxAge. The request that hits the limit is sent with "Connection: close",
+// so the connection is retired cleanly after its response instead of being cut mid-request.
+type recyclingTransport struct {
+	base        http.RoundTripper
+	maxRequests int64         // 0 = unlimited
+	maxAge      time.Duration // 0 = unlimited
+}
+
+func (t *recyclingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// GotConn runs on this goroutine before the request is written. The transport may copy
+	// requests with a body, but the copy shares the header map, so the header always reaches
+	// the server; the server's "Connection: close" reply then retires the connection.
+	var traced *http.Request
+	traced = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn := unwrapTrackedConn(info.Conn)
+			if conn == nil {
+				return
+			}
+			served := conn.requests.Add(1)
+			if (t.maxRequests > 0 && served >= t.maxRequests) || (t.maxAge > 0 && time.Since(conn.created) >= t.maxAge) {
+				traced.Close = true
+				traced.Header.Set("Connection", "close")
+			}
+		},
+	}))
+	return t.base.RoundTrip(traced)
+}
+
+// unwrapTrackedConn returns the trackedConn under a possibly TLS-wrapped connection.
+func unwrapTrackedConn(conn net.Conn) *trackedConn {
+	for conn != nil {
+		switch c := conn.(type) {
+		case *trackedConn:
+			return c
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSCacheRoundRobin(t *testing.T) {
+	cache := newDNSCache(time.Hour)
+	cache.entries["s3.example.com"] = &dnsEntry{addrs: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, resolved: time.Now()}
+
+	var dialed []string
+	dial := cache.dialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, nil
+	})
+	for i := 0; i < 4; i++ {
+		dial(context.Background(), "tcp", "s3.example.com:443")
+	}
+	dial(context.Background(), "tcp", "192.168.1.1:80") // IPs bypass the cache
+
+	expected := []string{"10.0.0.1:443", "10.0.0.2:443", "10.0.0.3:443", "10.0.0.1:443", "192.168.1.1:80"}
+	if strings.Join(dialed, ",") != strings.Join(expected, ",") {
+		t.Errorf("dialed %v, expected %v", dialed, expected)
+	}
+}
+
+func TestDNSCacheTriesAllAddresses(t *testing.T) {
+	cache := newDNSCache(time.Hour)
+	cache.entries["s3.example.com"] = &dnsEntry{addrs: []string{"10.0.0.1", "10.0.0.2"}, resolved: time.Now()}
+
+	var attempts int
+	dial := cache.dialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		attempts++
+		if addr == "10.0.0.1:443" {
+			return nil, errors.New("connection refused")
+		}
+		return nil, nil
+	})
+	if _, err := dial(context.Background(), "tcp", "s3.example.com:443"); err != nil || attempts != 2 {
+		t.Errorf("expected fallback to the second address, got err=%v after %d attempts", err, attempts)
+	}
+}
+
+func TestRecyclingTransport(t *testing.T) {
+	var conns atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	dialer := &net.Dialer{}
+	base := &http.Transport{DialContext: trackingDialContext(dialer.DialContext)}
+	defer base.CloseIdleConnections()
+	client := &http.Client{Transport: &recyclingTransport{base: base, maxRequests: 2}}
+
+	for i := 0; i < 6; i++ {
+		req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("body"))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	if got := conns.Load(); got != 3 {
+		t.Errorf("expected 3 connections for 6 requests with 2 requests per connection, got %d", got)
+	}
+}
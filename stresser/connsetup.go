@@ -0,0 +1,38 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+)
+
+// addConnSetup records the connection wait of requests that had to open a new connection,
+// which covers DNS lookup, TCP connect and TLS handshake. Called from AddResult.
+func (s *Stats) addConnSetup(r Result) {
+	if r.RemoteAddr == "" || r.ConnReused {
+		return
+	}
+	if s.connSetups == nil {
+		s.connSetups = NewHistogram()
+	}
+	s.connSetups.Record(r.ConnWait)
+}
+
+// connSetupLatency summarizes connection setup times, or returns nil if no connection was opened.
+func (s *Stats) connSetupLatency() *LatencySummary {
+	if s.connSetups == nil {
+		return nil
+	}
+	return histogramSummary(s.connSetups)
+}
+
+// printConnSetupSummary prints connection setup latency as part of PrintSummary.
+func (s *Stats) printConnSetupSummary(w io.Writer) {
+	l := s.connSetupLatency()
+	if l == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nConnection Setup (%d new connections, DNS + TCP + TLS):\n", s.connSetups.Count())
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------\n")
+	fmt.Fprintf(w, "  Setup         |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n", l.Min, l.Avg, l.P50, l.P90, l.P99, l.Max)
+}
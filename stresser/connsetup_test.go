@@ -0,0 +1,29 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnSetupStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "a", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, RemoteAddr: "10.0.0.1:443", ConnWait: 30 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "b", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, RemoteAddr: "10.0.0.1:443", ConnReused: true, ConnWait: time.Microsecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "c", TTFB: -1, TTLB: -1, RemoteAddr: "10.0.0.1:443", ConnWait: 10 * time.Millisecond, Error: "failed"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "d", TTFB: -1, TTLB: -1, Error: "dial failed"})
+	stats.Calculate(now, now.Add(time.Second))
+
+	l := stats.connSetupLatency()
+	if l == nil || l.Min != 10 || l.Max != 30 || l.Avg != 20 {
+		t.Fatalf("unexpected connection setup latency: %+v", l)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Connection Setup (2 new connections") {
+		t.Errorf("summary missing connection setup section:\n%s", buf.String())
+	}
+}
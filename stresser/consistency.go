@@ -0,0 +1,297 @@
+package stresser
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// DefaultConsistencyDelays are the probe delays of 'consistency' mode when -consistency-delays is not set.
+	DefaultConsistencyDelays = "0,100ms,1s,5s"
+	// consistencyListPageSize is the page size of LIST probes. The listing starts just before
+	// the key, so it is found on the first page unless that many keys sort in between.
+	consistencyListPageSize = 100
+)
+
+// Outcomes of a read-after-write probe ('consistency' mode), stored in Result.Consistency.
+const (
+	ConsistencyMatch   = "match"   // The object was there with the content written
+	ConsistencyStale   = "stale"   // The object was there, or listed, with different content or size
+	ConsistencyMissing = "missing" // The object was not found (404), or not listed
+	ConsistencyFailed  = "failed"  // The probe failed for another reason
+)
+
+// parseConsistencyDelays parses a comma-separated list of delays such as "0,100ms,1s,5s"
+// and returns them in ascending order.
+func parseConsistencyDelays(spec string) ([]time.Duration, error) {
+	if spec == "" {
+		spec = DefaultConsistencyDelays
+	}
+	var delays []time.Duration
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if item == "0" {
+			item = "0s"
+		}
+		d, err := time.ParseDuration(item)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid delay %q in list %q", item, spec)
+		}
+		if slices.Contains(delays, d) {
+			return nil, fmt.Errorf("delay %s is listed twice in %q", d, spec)
+		}
+		delays = append(delays, d)
+	}
+	if len(delays) == 0 {
+		return nil, fmt.Errorf("delay list %q is empty", spec)
+	}
+	slices.Sort(delays)
+	return delays, nil
+}
+
+// consistencyCheck runs the write-then-probe sequences of 'consistency' mode: each object is
+// written once and read back at every delay after the PUT completed, through an endpoint
+// picked per probe, so multi-endpoint runs also see how long gateways disagree.
+type consistencyCheck struct {
+	cfg    *Config
+	delays []time.Duration
+	probe  string // Config.ConsistencyProbe: "get", "head" or "list"
+}
+
+// newConsistencyCheck returns the check configured by cfg.
+func newConsistencyCheck(cfg *Config) *consistencyCheck {
+	delays, _ := parseConsistencyDelays(cfg.ConsistencyDelays) // Already validated in Config.Validate
+	return &consistencyCheck{cfg: cfg, delays: delays, probe: cfg.ConsistencyProbe}
+}
+
+// run writes body to key through the put endpoint, then probes the key through endpoints
+// returned by pick. The PUT and every probe but the last are handed to send with the endpoint
+// they went to. It returns the last probe, or the PUT if it failed, and its endpoint; ok is
+// false if ctx was cancelled or send gave up.
+func (cc *consistencyCheck) run(ctx context.Context, put *endpointTarget, pick func() *endpointTarget, bucket, key string, body io.ReadSeeker, send func(Result, *endpointTarget) bool) (result Result, target *endpointTarget, ok bool) {
+	want, err := bodySHA256(body)
+	if err != nil {
+		return Result{Timestamp: time.Now(), Operation: "PUT", ObjectKey: key, TTFB: -1, Error: fmt.Sprintf("failed to hash body: %v", err)}, put, true
+	}
+	result, target = uploadObject(ctx, put.client, cc.cfg, bucket, key, body), put
+	if result.Error != "" {
+		return result, target, true
+	}
+	written, size := time.Now(), result.BytesUploaded
+	visibleAfter := time.Duration(-1)
+	for _, delay := range cc.delays {
+		if !send(result, target) || !sleepContext(ctx, time.Until(written.Add(delay))) {
+			return result, target, false
+		}
+		target = pick()
+		result = cc.read(ctx, target.client, bucket, key, want, size)
+		result.ProbeDelay = delay
+		if result.Consistency == ConsistencyMatch && visibleAfter < 0 {
+			visibleAfter = max(time.Nanosecond, result.Timestamp.Add(result.TTLB).Sub(written)) // Zero marks probes that aren't last
+		}
+	}
+	result.VisibleAfter = visibleAfter
+	return result, target, true
+}
+
+// read probes key and classifies what it found against the SHA-256 and size written.
+func (cc *consistencyCheck) read(ctx context.Context, s3Client S3ClientAPI, bucket, key, want string, size int64) Result {
+	var result Result
+	var err error
+	stale, listed := false, true
+	switch cc.probe {
+	case "head":
+		var resp *s3.HeadObjectOutput
+		result, resp, err = headObject(ctx, s3Client, bucket, key)
+		stale = resp != nil && aws.ToInt64(resp.ContentLength) != size
+	case "list":
+		var obj *types.Object
+		result, obj, err = listKey(ctx, s3Client, bucket, key)
+		listed = obj != nil
+		stale = listed && aws.ToInt64(obj.Size) != size
+	default:
+		attempt := startGet(ctx, s3Client, bucket, key)
+		var h hash.Hash
+		result, h = attempt.read(attempt.start, true)
+		err = attempt.err
+		stale = h != nil && hex.EncodeToString(h.Sum(nil)) != want
+	}
+	switch {
+	case result.Error == "" && !listed:
+		result.Consistency = ConsistencyMissing
+	case result.Error == "" && stale:
+		result.Consistency = ConsistencyStale
+	case result.Error == "":
+		result.Consistency = ConsistencyMatch
+	case responseStatus(err) == http.StatusNotFound:
+		result.Consistency = ConsistencyMissing
+	default:
+		result.Consistency = ConsistencyFailed
+	}
+	return result
+}
+
+// listKey lists the directory of key, up to the last '/', starting just before key, and
+// returns the listing entry of key, nil if it isn't listed.
+func listKey(ctx context.Context, s3Client S3ClientAPI, bucket, key string) (Result, *types.Object, error) {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "LIST",
+		ObjectKey: key,
+		TTFB:      -1, // Not measured, the page is parsed before the SDK returns
+		TTLB:      -1,
+	}
+	input := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(bucket),
+		Prefix:     aws.String(key[:strings.LastIndexByte(key, '/')+1]),
+		StartAfter: aws.String(key[:len(key)-1]),
+		MaxKeys:    aws.Int32(consistencyListPageSize),
+	}
+	reqStartTime := time.Now()
+	resp, err := s3Client.ListObjectsV2(traceConnection(ctx, &result, reqStartTime), input)
+	if err != nil {
+		result.Error = err.Error()
+		recordErrorRequestID(&result, err)
+		return result, nil, err
+	}
+	recordResponseMetadata(&result, resp.ResultMetadata)
+	result.TTLB = time.Since(reqStartTime)
+	result.Keys = len(resp.Contents)
+	for i, obj := range resp.Contents {
+		if aws.ToString(obj.Key) == key {
+			return result, &resp.Contents[i], nil
+		}
+	}
+	return result, nil, nil
+}
+
+// ConsistencyProbeStats counts the read-after-write probes made at one delay after the PUT.
+type ConsistencyProbeStats struct {
+	DelayMs  float64 `json:"delayMs" yaml:"delayMs"`
+	Probes   int64   `json:"probes" yaml:"probes"`
+	Match    int64   `json:"match" yaml:"match"`
+	Stale    int64   `json:"stale" yaml:"stale"`
+	Missing  int64   `json:"missing" yaml:"missing"`
+	Failed   int64   `json:"failed" yaml:"failed"`
+	MatchPct float64 `json:"matchPct" yaml:"matchPct"` // Share of probes that saw the content written
+}
+
+// ConsistencyReport summarizes 'consistency' mode: per probe delay how often the object was
+// visible with the right content, and how long after the PUT it first was.
+type ConsistencyReport struct {
+	Probe        string                  `json:"probe" yaml:"probe"`               // Operation of the probes: GET, HEAD or LIST
+	Objects      int64                   `json:"objects" yaml:"objects"`           // Objects probed at every delay
+	Visible      int64                   `json:"visible" yaml:"visible"`           // Objects a probe saw with the content written
+	NeverVisible int64                   `json:"neverVisible" yaml:"neverVisible"` // Objects no probe saw with the content written
+	VisibleAfter *LatencySummary         `json:"visibleAfterMs,omitempty" yaml:"visibleAfterMs,omitempty"`
+	Probes       []ConsistencyProbeStats `json:"probes" yaml:"probes"` // By delay, ascending
+}
+
+// consistencyStats holds the probe outcomes of 'consistency' mode.
+type consistencyStats struct {
+	probe        string
+	delays       map[time.Duration]*ConsistencyProbeStats
+	objects      int64
+	neverVisible int64
+	visibleAfter *Histogram
+}
+
+// addConsistencyResult records a read-after-write probe. Called from AddResult.
+func (s *Stats) addConsistencyResult(r Result) {
+	if r.Consistency == "" {
+		return
+	}
+	if s.consistency == nil {
+		s.consistency = &consistencyStats{delays: make(map[time.Duration]*ConsistencyProbeStats), visibleAfter: NewHistogram()}
+	}
+	cs := s.consistency
+	cs.probe = r.Operation
+	ps := cs.delays[r.ProbeDelay]
+	if ps == nil {
+		ps = &ConsistencyProbeStats{DelayMs: ms(r.ProbeDelay)}
+		cs.delays[r.ProbeDelay] = ps
+	}
+	ps.Probes++
+	switch r.Consistency {
+	case ConsistencyMatch:
+		ps.Match++
+	case ConsistencyStale:
+		ps.Stale++
+	case ConsistencyMissing:
+		ps.Missing++
+	default:
+		ps.Failed++
+	}
+	// Only the last probe of an object carries the outcome of the whole sequence
+	switch {
+	case r.VisibleAfter > 0:
+		cs.objects++
+		cs.visibleAfter.Record(r.VisibleAfter)
+	case r.VisibleAfter < 0:
+		cs.objects++
+		cs.neverVisible++
+	}
+}
+
+// Consistency returns the read-after-write figures, or nil if no probes were made.
+func (s *Stats) Consistency() *ConsistencyReport {
+	cs := s.consistency
+	if cs == nil {
+		return nil
+	}
+	cr := &ConsistencyReport{
+		Probe:        cs.probe,
+		Objects:      cs.objects,
+		Visible:      cs.objects - cs.neverVisible,
+		NeverVisible: cs.neverVisible,
+		VisibleAfter: histogramSummary(cs.visibleAfter),
+	}
+	delays := make([]time.Duration, 0, len(cs.delays))
+	for d := range cs.delays {
+		delays = append(delays, d)
+	}
+	slices.Sort(delays)
+	for _, d := range delays {
+		ps := *cs.delays[d]
+		ps.MatchPct = percentOf(ps.Match, ps.Probes)
+		cr.Probes = append(cr.Probes, ps)
+	}
+	return cr
+}
+
+// printConsistencySummary prints the visibility of written objects by probe delay as part of PrintSummary.
+func (s *Stats) printConsistencySummary(w io.Writer) {
+	cr := s.Consistency()
+	if cr == nil {
+		return
+	}
+	kind := "Read"
+	if cr.Probe == "LIST" {
+		kind = "List"
+	}
+	fmt.Fprintf(w, "\n%s-after-write Consistency (%s probes):\n", kind, cr.Probe)
+	fmt.Fprintf(w, "  Objects: %d (visible: %d, never visible: %d)\n", cr.Objects, cr.Visible, cr.NeverVisible)
+	if v := cr.VisibleAfter; v != nil {
+		fmt.Fprintf(w, "  Visible after PUT (ms): Min %.2f | Avg %.2f | P50 %.2f | P90 %.2f | P99 %.2f | Max %.2f\n",
+			v.Min, v.Avg, v.P50, v.P90, v.P99, v.Max)
+	}
+	fmt.Fprintf(w, "  %-10s %8s %8s %8s %8s %8s %8s\n", "Delay", "Probes", "Match", "Stale", "Missing", "Failed", "Match %")
+	for _, ps := range cr.Probes {
+		fmt.Fprintf(w, "  %-10s %8d %8d %8d %8d %8d %7.2f%%\n",
+			fromMs(ps.DelayMs), ps.Probes, ps.Match, ps.Stale, ps.Missing, ps.Failed, ps.MatchPct)
+	}
+}
@@ -0,0 +1,214 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// laggyS3Client plays an eventually consistent store: reads of an object return the scripted
+// outcomes in turn ("missing", "stale" or "current"), then the current content.
+type laggyS3Client struct {
+	stubS3Client
+	objects map[string][]byte
+	script  []string
+	reads   int
+}
+
+func (c *laggyS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.objects[aws.ToString(params.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+// read returns what the next read of key sees, nil if the object is missing.
+func (c *laggyS3Client) read(key string) []byte {
+	outcome := "current"
+	if c.reads < len(c.script) {
+		outcome = c.script[c.reads]
+	}
+	c.reads++
+	switch outcome {
+	case "missing":
+		return nil
+	case "stale":
+		return []byte("old content")
+	}
+	return c.objects[key]
+}
+
+func (c *laggyS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	body := c.read(aws.ToString(params.Key))
+	if body == nil {
+		return nil, statusError(http.StatusNotFound)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (c *laggyS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	body := c.read(aws.ToString(params.Key))
+	if body == nil {
+		return nil, statusError(http.StatusNotFound)
+	}
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(body)))}, nil
+}
+
+func (c *laggyS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	out := &s3.ListObjectsV2Output{}
+	for key := range c.objects {
+		if !strings.HasPrefix(key, aws.ToString(params.Prefix)) || key <= aws.ToString(params.StartAfter) {
+			continue
+		}
+		if body := c.read(key); body != nil {
+			out.Contents = append(out.Contents, types.Object{Key: aws.String(key), Size: aws.Int64(int64(len(body)))})
+		}
+	}
+	return out, nil
+}
+
+func TestParseConsistencyDelays(t *testing.T) {
+	delays, err := parseConsistencyDelays("1s, 0,100ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(delays, []time.Duration{0, 100 * time.Millisecond, time.Second}) {
+		t.Errorf("Expected the delays in ascending order, got %v", delays)
+	}
+	if delays, _ := parseConsistencyDelays(""); len(delays) != 4 {
+		t.Errorf("Expected the default delays, got %v", delays)
+	}
+	for _, spec := range []string{"soon", "-1s", "1s,1000ms", ","} {
+		if _, err := parseConsistencyDelays(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	ctx := context.Background()
+	stats := NewStats()
+
+	for _, tc := range []struct {
+		probe    string
+		script   []string
+		outcomes []string
+		visible  bool
+	}{
+		{"get", []string{"missing", "stale"}, []string{ConsistencyMissing, ConsistencyStale, ConsistencyMatch}, true},
+		{"get", []string{"missing", "missing", "missing"}, []string{ConsistencyMissing, ConsistencyMissing, ConsistencyMissing}, false},
+		{"head", []string{"missing"}, []string{ConsistencyMissing, ConsistencyMatch, ConsistencyMatch}, true},
+	} {
+		client := &laggyS3Client{objects: make(map[string][]byte), script: tc.script}
+		target := &endpointTarget{url: "http://a", client: client}
+		cc := newConsistencyCheck(&Config{ConsistencyDelays: "0,5ms,10ms", ConsistencyProbe: tc.probe})
+
+		var sent []Result
+		body := newPayload(1024, rand.New(rand.NewSource(1)))
+		last, lastTarget, ok := cc.run(ctx, target, func() *endpointTarget { return target }, "bucket", "key", body,
+			func(r Result, _ *endpointTarget) bool {
+				sent = append(sent, r)
+				return true
+			})
+		if !ok || lastTarget != target {
+			t.Fatalf("%s %v: run gave up", tc.probe, tc.script)
+		}
+		if len(sent) != 3 || sent[0].Operation != "PUT" {
+			t.Fatalf("%s %v: expected the PUT and two probes to be sent, got %+v", tc.probe, tc.script, sent)
+		}
+		probes := append(sent[1:], last)
+		for i, p := range probes {
+			if p.Consistency != tc.outcomes[i] {
+				t.Errorf("%s %v: probe %d is %q, expected %q", tc.probe, tc.script, i, p.Consistency, tc.outcomes[i])
+			}
+			if p.ProbeDelay != []time.Duration{0, 5 * time.Millisecond, 10 * time.Millisecond}[i] {
+				t.Errorf("%s %v: probe %d has delay %s", tc.probe, tc.script, i, p.ProbeDelay)
+			}
+		}
+		if visible := last.VisibleAfter > 0; visible != tc.visible || (!visible && last.VisibleAfter != -1) {
+			t.Errorf("%s %v: unexpected visible after %s", tc.probe, tc.script, last.VisibleAfter)
+		}
+		if sent[1].VisibleAfter != 0 {
+			t.Errorf("Only the last probe should carry the visibility, got %s", sent[1].VisibleAfter)
+		}
+		for _, r := range append(sent, last) {
+			stats.AddResult(r)
+		}
+	}
+	stats.Calculate(time.Now().Add(-time.Second), time.Now())
+
+	cr := stats.Consistency()
+	if cr == nil || cr.Objects != 3 || cr.Visible != 2 || cr.NeverVisible != 1 || cr.VisibleAfter == nil {
+		t.Fatalf("Unexpected consistency report: %+v", cr)
+	}
+	if len(cr.Probes) != 3 || cr.Probes[0].DelayMs != 0 || cr.Probes[0].Missing != 3 || cr.Probes[1].Stale != 1 || cr.Probes[2].Match != 2 {
+		t.Errorf("Unexpected probes by delay: %+v", cr.Probes)
+	}
+	if stats.TotalErrors != 5 {
+		t.Errorf("Expected the 404s to count as errors, got %d", stats.TotalErrors)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Objects: 3 (visible: 2, never visible: 1)") {
+		t.Errorf("Summary is missing the consistency section:\n%s", buf.String())
+	}
+
+	// The outcome of an object survives the detailed results
+	never := NewResultRecord(Result{Operation: "GET", Consistency: ConsistencyMissing, ProbeDelay: time.Second, VisibleAfter: -1})
+	if never.VisibleAfterMs != -1 {
+		t.Errorf("Expected -1 for an object that never became visible, got %v", never.VisibleAfterMs)
+	}
+	if r := never.Result(); r.VisibleAfter != -1 || r.ProbeDelay != time.Second || r.Consistency != ConsistencyMissing {
+		t.Errorf("Unexpected result from record: %+v", r)
+	}
+}
+
+func TestListAfterWrite(t *testing.T) {
+	client := &laggyS3Client{objects: map[string][]byte{"other/key": nil}, script: []string{"missing", "stale"}}
+	target := &endpointTarget{url: "http://a", client: client}
+	cc := newConsistencyCheck(&Config{ConsistencyDelays: "0,1ms,2ms", ConsistencyProbe: "list"})
+
+	stats := NewStats()
+	last, _, ok := cc.run(context.Background(), target, func() *endpointTarget { return target }, "bucket", "dir/key", newPayload(1024, rand.New(rand.NewSource(1))),
+		func(r Result, _ *endpointTarget) bool {
+			stats.AddResult(r)
+			return true
+		})
+	if !ok {
+		t.Fatal("run gave up")
+	}
+	stats.AddResult(last)
+	stats.Calculate(time.Now().Add(-time.Second), time.Now())
+
+	if last.Operation != "LIST" || last.Consistency != ConsistencyMatch || last.VisibleAfter <= 0 {
+		t.Errorf("Expected the key to be listed by the last probe, got %+v", last)
+	}
+	if client.reads != 3 {
+		t.Errorf("Expected only keys under the prefix to be listed, got %d reads", client.reads)
+	}
+	cr := stats.Consistency()
+	if cr == nil || cr.Probe != "LIST" || len(cr.Probes) != 3 || cr.Probes[0].Missing != 1 || cr.Probes[1].Stale != 1 || cr.Probes[2].MatchPct != 100 {
+		t.Fatalf("Unexpected consistency report: %+v", cr)
+	}
+	if stats.TotalErrors != 0 || stats.TotalLists != 3 {
+		t.Errorf("Unlisted keys must not count as errors: %d errors, %d LISTs", stats.TotalErrors, stats.TotalLists)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "List-after-write Consistency (LIST probes)") {
+		t.Errorf("Summary is missing the consistency section:\n%s", buf.String())
+	}
+}
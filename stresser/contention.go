@@ -0,0 +1,196 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DefaultContentionKeys is the number of keys 'contention' mode writes to when -keypool is not set.
+const DefaultContentionKeys = 16
+
+// Outcomes of a conditional PUT in 'contention' mode, stored in Result.Conditional.
+const (
+	ConditionalWon                = "won"                 // The PUT replaced the version it was conditional on
+	ConditionalPreconditionFailed = "precondition-failed" // 412, another writer replaced that version first
+	ConditionalConflict           = "conflict"            // 409, a concurrent conditional write to the key was in progress
+)
+
+// validateContention checks the parameters of 'contention' mode. Called from Validate.
+func (c *Config) validateContention() error {
+	if c.KeyPool < 0 {
+		return fmt.Errorf("key pool (-keypool) must not be negative")
+	}
+	if c.OperationType != "contention" {
+		return nil
+	}
+	if c.KeyPool == 0 {
+		c.KeyPool = DefaultContentionKeys
+	}
+	if c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support 'contention' mode")
+	}
+	if c.DisconnectFraction > 0 {
+		return fmt.Errorf("'contention' mode cannot be combined with -disconnect-at")
+	}
+	return nil
+}
+
+// poolKeys returns n keys under dir of the run's prefix, for the key pools of 'contention'
+// mode and -write-mode overwrite. They are the same for every worker, so workers share them.
+func poolKeys(runID, dir string, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%s/key%04d", RunPrefix(runID), dir, i)
+	}
+	return keys
+}
+
+// seedContentionKeys creates the keys of the pool that do not exist yet in each bucket, so
+// every contended PUT has a version to be conditional on. The PUTs are not measured.
+func seedContentionKeys(ctx context.Context, client S3ClientAPI, buckets, keys []string, body func() io.ReadSeeker) error {
+	for _, bucket := range buckets {
+		for _, key := range keys {
+			_, err := client.PutObject(ctx, &s3.PutObjectInput{
+				Bucket:      aws.String(bucket),
+				Key:         aws.String(key),
+				Body:        body(),
+				IfNoneMatch: aws.String("*"), // Keys left by an earlier run with the same id are kept
+			})
+			var respErr *awshttp.ResponseError
+			if err != nil && !(errors.As(err, &respErr) && conditionalOutcome(respErr.HTTPStatusCode()) != "") {
+				return fmt.Errorf("failed to create contended key %s in bucket %s: %w", key, bucket, err)
+			}
+		}
+	}
+	slog.Info("Created the contended keys", "keys", len(keys), "buckets", len(buckets))
+	return nil
+}
+
+// performContendedPut models an optimistic concurrency update: a HEAD reads the current
+// ETag of key, then a PUT replaces the object only if it still has that ETag (If-Match).
+// Another worker replacing the object in between makes the PUT fail with 412 Precondition
+// Failed, which is an outcome of the race rather than an error, like a 409 some stores
+// return while a conditional write to the key is still in progress.
+// It returns both results; ok is false if the HEAD failed and no PUT was sent.
+func performContendedPut(ctx context.Context, s3Client S3ClientAPI, bucket, key string, body io.ReadSeeker, verify bool, attrs putAttributes) (head, put Result, ok bool) {
+	head, resp, _ := headObject(ctx, s3Client, bucket, key)
+	if resp == nil {
+		return head, Result{}, false
+	}
+
+	start := time.Now()
+	put = sendPut(ctx, s3Client, &s3.PutObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		IfMatch: resp.ETag,
+	}, body, verify, attrs)
+	if put.Error == "" {
+		put.Conditional = ConditionalWon
+	} else if outcome := conditionalOutcome(put.StatusCode); outcome != "" {
+		put.Error = ""
+		put.TTLB = time.Since(start)
+		put.Conditional = outcome
+	}
+	return head, put, true
+}
+
+// conditionalOutcome returns the outcome of a conditional write that lost a race with the
+// HTTP status of its response, or "" for other statuses.
+func conditionalOutcome(status int) string {
+	switch status {
+	case http.StatusPreconditionFailed:
+		return ConditionalPreconditionFailed
+	case http.StatusConflict:
+		return ConditionalConflict
+	}
+	return ""
+}
+
+// ContentionReport summarizes the conditional PUTs of 'contention' mode. The HEADs and
+// PUTs are also counted in the HEAD and PUT figures.
+type ContentionReport struct {
+	Attempts           int64           `json:"attempts" yaml:"attempts"`
+	Won                int64           `json:"won" yaml:"won"`
+	PreconditionFailed int64           `json:"preconditionFailed" yaml:"preconditionFailed"`
+	Conflicts          int64           `json:"conflicts" yaml:"conflicts"`
+	FailedPct          float64         `json:"failedPct" yaml:"failedPct"` // Attempts that lost the race (412 or 409)
+	WonTTLB            *LatencySummary `json:"wonTtlbMs,omitempty" yaml:"wonTtlbMs,omitempty"`
+	FailedTTLB         *LatencySummary `json:"failedTtlbMs,omitempty" yaml:"failedTtlbMs,omitempty"`
+}
+
+// contentionStats holds the latencies of conditional PUTs by outcome.
+type contentionStats struct {
+	won       *Histogram
+	failed    *Histogram
+	conflicts int64
+}
+
+// addContentionResult records a conditional PUT that won or lost. Called from AddResult.
+func (s *Stats) addContentionResult(r Result) {
+	if r.Operation != "PUT" || r.Conditional == "" {
+		return
+	}
+	if s.contention == nil {
+		s.contention = &contentionStats{won: NewHistogram(), failed: NewHistogram()}
+	}
+	switch r.Conditional {
+	case ConditionalWon:
+		s.contention.won.Record(r.TTLB)
+	case ConditionalConflict:
+		s.contention.conflicts++
+		s.contention.failed.Record(r.TTLB)
+	default:
+		s.contention.failed.Record(r.TTLB)
+	}
+}
+
+// Contention returns the conditional PUT figures, or nil if none were sent.
+func (s *Stats) Contention() *ContentionReport {
+	if s.contention == nil {
+		return nil
+	}
+	won, failed := s.contention.won, s.contention.failed
+	cr := &ContentionReport{
+		Attempts:           won.Count() + failed.Count(),
+		Won:                won.Count(),
+		PreconditionFailed: failed.Count() - s.contention.conflicts,
+		Conflicts:          s.contention.conflicts,
+		WonTTLB:            histogramSummary(won),
+		FailedTTLB:         histogramSummary(failed),
+	}
+	cr.FailedPct = percentOf(failed.Count(), cr.Attempts)
+	return cr
+}
+
+// printContentionSummary prints the precondition failure rate and latencies by outcome as
+// part of PrintSummary.
+func (s *Stats) printContentionSummary(w io.Writer) {
+	cr := s.Contention()
+	if cr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nWrite Contention (HEAD + PUT If-Match):\n")
+	fmt.Fprintf(w, "  Conditional PUTs: %d (won: %d, lost: %d = %.2f%%)\n", cr.Attempts, cr.Won, cr.Attempts-cr.Won, cr.FailedPct)
+	fmt.Fprintf(w, "  Lost Races:       412 Precondition Failed: %d, 409 Conflict: %d\n", cr.PreconditionFailed, cr.Conflicts)
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	for _, row := range []struct {
+		label string
+		l     *LatencySummary
+	}{{"Won", cr.WonTTLB}, {"Lost", cr.FailedTTLB}} {
+		if row.l == nil {
+			continue
+		}
+		fmt.Fprintf(w, "  %-13s |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			row.label, row.l.Min, row.l.Avg, row.l.P50, row.l.P90, row.l.P99, row.l.P999, row.l.Max)
+	}
+}
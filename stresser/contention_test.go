@@ -0,0 +1,150 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestValidateContention(t *testing.T) {
+	c := &Config{OperationType: "contention"}
+	if err := c.validateContention(); err != nil || c.KeyPool != DefaultContentionKeys {
+		t.Errorf("Expected the default key pool, got %d, %v", c.KeyPool, err)
+	}
+	if c := (&Config{OperationType: "write"}); c.validateContention() != nil || c.KeyPool != 0 {
+		t.Errorf("Expected no key pool outside 'contention' mode, got %d", c.KeyPool)
+	}
+	for _, c := range []*Config{
+		{OperationType: "contention", KeyPool: -1},
+		{OperationType: "contention", Backend: BackendGCS},
+		{OperationType: "contention", DisconnectFraction: 0.5},
+	} {
+		if err := c.validateContention(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+}
+
+// etagServer stores objects under an ETag that changes with every PUT and honors If-Match
+// and If-None-Match on PUTs. Key "busy" always exists, but PUTs to it get 409 Conflict.
+func etagServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	etags := make(map[string]string)
+	version := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		etag, exists := etags[r.URL.Path]
+		if strings.HasSuffix(r.URL.Path, "/busy") {
+			etag, exists = `"busy"`, true
+		}
+		switch r.Method {
+		case http.MethodHead:
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", etag)
+		case http.MethodPut:
+			io.Copy(io.Discard, r.Body)
+			if strings.HasSuffix(r.URL.Path, "/busy") {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if m := r.Header.Get("If-Match"); m != "" && m != etag || r.Header.Get("If-None-Match") == "*" && exists {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			version++
+			etags[r.URL.Path] = fmt.Sprintf(`"v%d"`, version)
+			w.Header().Set("ETag", etags[r.URL.Path])
+		}
+	}))
+}
+
+func TestContendedPut(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	server := etagServer(t)
+	defer server.Close()
+	cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", RunID: "test"}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	ctx := context.Background()
+	body := func() io.ReadSeeker { return bytes.NewReader([]byte("contended")) }
+	keys := append(poolKeys(cfg.RunID, "contention", 2), "busy")
+	if keys[0] != "stresser/test/contention/key0000" {
+		t.Errorf("Unexpected key %q", keys[0])
+	}
+	// Seeding twice keeps the objects of the first round
+	for range 2 {
+		if err := seedContentionKeys(ctx, client, []string{cfg.Bucket}, keys[:2], body); err != nil {
+			t.Fatalf("seedContentionKeys failed: %v", err)
+		}
+	}
+
+	head, put, ok := performContendedPut(ctx, client, cfg.Bucket, keys[0], body(), false, putAttributes{})
+	if !ok || head.Error != "" || put.Error != "" || put.Conditional != ConditionalWon {
+		t.Fatalf("Expected an uncontended PUT to win, got %+v then %+v", head, put)
+	}
+
+	// Another writer replaces the object between the HEAD and the PUT
+	stale, _, _ := headObject(ctx, client, cfg.Bucket, keys[1])
+	performContendedPut(ctx, client, cfg.Bucket, keys[1], body(), false, putAttributes{})
+	_, lost, _ := performContendedPut(ctx, &staleHeadClient{S3ClientAPI: client, etag: stale.ETag}, cfg.Bucket, keys[1], body(), false, putAttributes{})
+	if lost.Error != "" || lost.Conditional != ConditionalPreconditionFailed || lost.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected a lost race without an error, got %+v", lost)
+	}
+	if _, busy, _ := performContendedPut(ctx, client, cfg.Bucket, keys[2], body(), false, putAttributes{}); busy.Error != "" || busy.Conditional != ConditionalConflict {
+		t.Errorf("Expected a 409 to be a lost race, got %+v", busy)
+	}
+
+	if _, _, ok := performContendedPut(ctx, client, cfg.Bucket, "missing", body(), false, putAttributes{}); ok {
+		t.Error("Expected no PUT after a failed HEAD")
+	}
+}
+
+// staleHeadClient answers HEADs with an ETag read earlier, as if another writer replaced
+// the object after the HEAD.
+type staleHeadClient struct {
+	S3ClientAPI
+	etag string
+}
+
+func (c *staleHeadClient) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{ETag: aws.String(c.etag)}, nil
+}
+
+func TestContentionStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	for _, outcome := range []string{ConditionalWon, ConditionalWon, ConditionalPreconditionFailed, ConditionalConflict} {
+		stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: 5 * time.Millisecond, Conditional: outcome})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: time.Millisecond, Conditional: ConditionalNotModified})
+	stats.Calculate(now, now.Add(time.Second))
+
+	cr := stats.Summary().Contention
+	if cr == nil || cr.Attempts != 4 || cr.Won != 2 || cr.PreconditionFailed != 1 || cr.Conflicts != 1 || cr.FailedPct != 50 {
+		t.Fatalf("Unexpected contention report %+v", cr)
+	}
+	if stats.Revalidation() == nil || stats.Revalidation().Requests != 1 {
+		t.Errorf("Expected the conditional PUTs to stay out of the revalidation figures, got %+v", stats.Revalidation())
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Write Contention") || !strings.Contains(buf.String(), "409 Conflict: 1") {
+		t.Errorf("Summary lacks the contention figures:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,191 @@
+package stresser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Control API paths served on -control while a test runs. Commands are POSTs; the status
+// is a GET returning a ControlStatus.
+const (
+	controlStatusPath      = "/v1/status"
+	controlPausePath       = "/v1/pause"
+	controlResumePath      = "/v1/resume"
+	controlStopPath        = "/v1/stop"
+	controlConcurrencyPath = "/v1/concurrency" // ?workers=N
+)
+
+// ControlStatus is the state of a running test as reported by the control API.
+type ControlStatus struct {
+	Paused         bool    `json:"paused"`
+	Workers        int     `json:"workers"`    // Active workers
+	MaxWorkers     int     `json:"maxWorkers"` // Workers started, the upper bound for /v1/concurrency
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	Requests       int64   `json:"requests"`
+	Errors         int64   `json:"errors"`
+}
+
+// isUnixSocket reports whether a -control address is a unix socket path rather than host:port.
+func isUnixSocket(addr string) bool {
+	return strings.Contains(addr, "/")
+}
+
+// validateControl checks the -control address. TCP addresses must be on a loopback
+// interface, since anyone who can reach the API can stop the test.
+func (c *Config) validateControl() error {
+	if c.Control == "" {
+		return nil
+	}
+	if c.Agents != "" {
+		return fmt.Errorf("the control API (-control) is not supported with -agents")
+	}
+	if isUnixSocket(c.Control) {
+		return nil
+	}
+	loopback, err := isLoopbackAddr(c.Control)
+	if err != nil {
+		return fmt.Errorf("invalid control address (-control) %q: %w", c.Control, err)
+	}
+	if !loopback {
+		return fmt.Errorf("control address (-control) %q must be on a loopback interface, e.g. 127.0.0.1:7070, or a unix socket path", c.Control)
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether a host:port address is on a loopback interface.
+func isLoopbackAddr(addr string) (bool, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false, err
+	}
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback()), nil
+}
+
+// controlServer serves the control API for one run. Pausing idles the workers through
+// the worker limit; changing the concurrency moves the limit within the started workers.
+type controlServer struct {
+	limit      *workerLimit
+	maxWorkers int
+	adjustable bool // False while load stages, the CPU budget or the latency target drive the worker count
+	pausable   bool // False for modes whose operations don't run on the workers
+	stop       context.CancelFunc
+	start      time.Time
+
+	requests atomic.Int64
+	errors   atomic.Int64
+
+	server *http.Server
+	socket string // Unix socket path to remove on close
+}
+
+// startControlServer listens on addr and serves the control API until close is called.
+func startControlServer(addr string, cs *controlServer) error {
+	network := "tcp"
+	if isUnixSocket(addr) {
+		network = "unix"
+		cs.socket = addr
+	}
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for control commands on %s: %w", addr, err)
+	}
+	cs.server = &http.Server{Handler: cs, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := cs.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Control API failed", "error", err)
+		}
+	}()
+	slog.Info("Control API listening", "address", addr)
+	return nil
+}
+
+// add counts a collected result for the status.
+func (cs *controlServer) add(r Result) {
+	cs.requests.Add(1)
+	if r.Error != "" {
+		cs.errors.Add(1)
+	}
+}
+
+// close stops serving and removes the unix socket.
+func (cs *controlServer) close() {
+	cs.server.Close()
+	if cs.socket != "" {
+		os.Remove(cs.socket)
+	}
+}
+
+func (cs *controlServer) status() ControlStatus {
+	return ControlStatus{
+		Paused:         cs.limit.Paused(),
+		Workers:        cs.limit.Get(),
+		MaxWorkers:     cs.maxWorkers,
+		ElapsedSeconds: time.Since(cs.start).Seconds(),
+		Requests:       cs.requests.Load(),
+		Errors:         cs.errors.Load(),
+	}
+}
+
+// ServeHTTP implements the control API. Every command answers with the resulting status.
+func (cs *controlServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == controlStatusPath {
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		cs.writeStatus(w)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	switch r.URL.Path {
+	case controlPausePath, controlResumePath:
+		if !cs.pausable {
+			http.Error(w, "this mode can't be paused", http.StatusConflict)
+			return
+		}
+		paused := r.URL.Path == controlPausePath
+		cs.limit.SetPaused(paused)
+		slog.Info("Control command", "command", strings.TrimPrefix(r.URL.Path, "/v1/"))
+	case controlStopPath:
+		slog.Info("Control command", "command", "stop")
+		cs.stop()
+	case controlConcurrencyPath:
+		workers, err := strconv.Atoi(r.URL.Query().Get("workers"))
+		if err != nil || workers < 1 || workers > cs.maxWorkers {
+			http.Error(w, fmt.Sprintf("workers must be between 1 and %d (-c)", cs.maxWorkers), http.StatusBadRequest)
+			return
+		}
+		if !cs.adjustable {
+			http.Error(w, "the worker count is driven by -ramp, -cpu-budget or -target-p99", http.StatusConflict)
+			return
+		}
+		from := cs.limit.Get()
+		cs.limit.Set(workers)
+		slog.Info("Control command", "command", "concurrency", "from", from, "to", workers)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	cs.writeStatus(w)
+}
+
+func (cs *controlServer) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(cs.status()); err != nil {
+		slog.Warn("Failed to write control status", "error", err)
+	}
+}
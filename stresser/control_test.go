@@ -0,0 +1,120 @@
+package stresser
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValidateControl(t *testing.T) {
+	for addr, valid := range map[string]bool{
+		"":                    true,
+		"127.0.0.1:7070":      true,
+		"localhost:7070":      true,
+		"[::1]:7070":          true,
+		"/tmp/ostresser.sock": true,
+		":7070":               false,
+		"0.0.0.0:7070":        false,
+		"10.0.0.1:7070":       false,
+		"127.0.0.1":           false,
+		"example.com:7070":    false,
+	} {
+		cfg := Config{Control: addr}
+		if err := cfg.validateControl(); (err == nil) != valid {
+			t.Errorf("%q: valid %v, got error %v", addr, valid, err)
+		}
+	}
+	cfg := Config{Control: "127.0.0.1:7070", Agents: "host1"}
+	if err := cfg.validateControl(); err == nil {
+		t.Error("Expected an error with -agents")
+	}
+}
+
+func TestControlServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	limit := newWorkerLimit(8)
+	cs := &controlServer{limit: limit, maxWorkers: 8, adjustable: true, pausable: true, stop: cancel, start: time.Now()}
+	cs.add(Result{Operation: "GET"})
+	cs.add(Result{Operation: "GET", Error: "timeout"})
+
+	do := func(method, path string) (*httptest.ResponseRecorder, ControlStatus) {
+		rec := httptest.NewRecorder()
+		cs.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		var status ControlStatus
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+				t.Fatalf("%s %s: %v", method, path, err)
+			}
+		}
+		return rec, status
+	}
+
+	if rec, status := do(http.MethodGet, controlStatusPath); rec.Code != http.StatusOK || status.Workers != 8 || status.Requests != 2 || status.Errors != 1 {
+		t.Errorf("Unexpected status %d %+v", rec.Code, status)
+	}
+	if _, status := do(http.MethodPost, controlPausePath); !status.Paused || limit.allows(0) {
+		t.Error("Expected all workers to be paused")
+	}
+	limit.Set(4) // The ramp or autoscaler moving the limit doesn't resume the workers
+	if limit.allows(0) {
+		t.Error("Expected the pause to survive a limit change")
+	}
+	if _, status := do(http.MethodPost, controlResumePath); status.Paused || !limit.allows(3) || limit.allows(4) {
+		t.Error("Expected workers below the limit to resume")
+	}
+	if _, status := do(http.MethodPost, controlConcurrencyPath+"?workers=6"); status.Workers != 6 {
+		t.Errorf("Expected 6 workers, got %+v", status)
+	}
+	for _, path := range []string{controlConcurrencyPath + "?workers=9", controlConcurrencyPath + "?workers=0", controlConcurrencyPath} {
+		if rec, _ := do(http.MethodPost, path); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+	if rec, _ := do(http.MethodGet, controlPausePath); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected commands to require POST, got %d", rec.Code)
+	}
+
+	cs.adjustable, cs.pausable = false, false
+	for _, path := range []string{controlConcurrencyPath + "?workers=2", controlPausePath} {
+		if rec, _ := do(http.MethodPost, path); rec.Code != http.StatusConflict {
+			t.Errorf("%s: expected 409, got %d", path, rec.Code)
+		}
+	}
+
+	do(http.MethodPost, controlStopPath)
+	if ctx.Err() == nil {
+		t.Error("Expected stop to cancel the run")
+	}
+}
+
+func TestControlServerUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "control.sock")
+	cs := &controlServer{limit: newWorkerLimit(2), maxWorkers: 2, stop: func() {}, start: time.Now()}
+	if err := startControlServer(socket, cs); err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://control" + controlStatusPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	cs.close()
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed, got %v", err)
+	}
+}
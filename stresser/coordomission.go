@@ -0,0 +1,130 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Coordinated omission: a paced client that waits for slow responses sends fewer requests
+// while the store is slow, so the slowdown is under-represented in latencies measured from
+// when requests were actually sent. With a target rate (-rps, -worker-rps or -arrival-rate),
+// every operation records when it was due, and corrected latencies are measured from then,
+// as a user arriving on schedule would experience them.
+
+// scheduleDelay returns how long after its intended start the operation began, 0 if it
+// was not paced.
+func (r Result) scheduleDelay() time.Duration {
+	if r.IntendedStart.IsZero() {
+		return 0
+	}
+	return max(0, r.Timestamp.Sub(r.IntendedStart))
+}
+
+// later returns the later of two times.
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// correctedMetrics are the latencies reported with coordinated omission correction, in order.
+var correctedMetrics = []struct {
+	op, metric string
+}{
+	{"GET", "TTFB"}, {"GET", "TTLB"}, {"PUT", "TTLB"}, {"HEAD", "TTLB"}, {"LIST", "TTLB"}, {"COPY", "TTLB"}, {"TAGGING", "TTLB"}, {"DELETE", "TTLB"},
+}
+
+// correctedStats holds raw and corrected latencies of successful paced operations.
+type correctedStats struct {
+	delays    *Histogram            // Schedule delay of every paced operation, failed ones included
+	raw       map[string]*Histogram // Keyed by "<op> <metric>"
+	corrected map[string]*Histogram
+}
+
+// CorrectedLatency compares the latency of one operation metric as measured from the actual
+// start (Raw) and from the intended start (Corrected).
+type CorrectedLatency struct {
+	Operation string          `json:"operation" yaml:"operation"`
+	Metric    string          `json:"metric" yaml:"metric"` // "TTFB" or "TTLB"
+	Raw       *LatencySummary `json:"rawMs" yaml:"rawMs"`
+	Corrected *LatencySummary `json:"correctedMs" yaml:"correctedMs"`
+}
+
+// addCorrectedResult records the latencies of a paced operation. Called from AddResult.
+func (s *Stats) addCorrectedResult(r Result) {
+	if r.IntendedStart.IsZero() {
+		return
+	}
+	if s.corrected == nil {
+		s.corrected = &correctedStats{delays: NewHistogram(), raw: map[string]*Histogram{}, corrected: map[string]*Histogram{}}
+	}
+	c := s.corrected
+	delay := r.scheduleDelay()
+	c.delays.Record(delay)
+	if r.Error != "" {
+		return
+	}
+	record := func(metric string, d time.Duration) {
+		key := r.Operation + " " + metric
+		if c.raw[key] == nil {
+			c.raw[key], c.corrected[key] = NewHistogram(), NewHistogram()
+		}
+		c.raw[key].Record(d)
+		c.corrected[key].Record(d + delay)
+	}
+	if r.Operation == "GET" {
+		record("TTFB", r.TTFB)
+	}
+	record("TTLB", r.TTLB)
+}
+
+// ScheduleDelay summarizes how far behind their intended start paced operations began, or
+// returns nil if no operation was paced.
+func (s *Stats) ScheduleDelay() *LatencySummary {
+	if s.corrected == nil {
+		return nil
+	}
+	return histogramSummary(s.corrected.delays)
+}
+
+// CorrectedLatencies returns raw and corrected latencies per operation metric, or nil if no
+// operation was paced.
+func (s *Stats) CorrectedLatencies() []CorrectedLatency {
+	if s.corrected == nil {
+		return nil
+	}
+	var out []CorrectedLatency
+	for _, m := range correctedMetrics {
+		key := m.op + " " + m.metric
+		if s.corrected.raw[key] == nil {
+			continue
+		}
+		out = append(out, CorrectedLatency{
+			Operation: m.op,
+			Metric:    m.metric,
+			Raw:       histogramSummary(s.corrected.raw[key]),
+			Corrected: histogramSummary(s.corrected.corrected[key]),
+		})
+	}
+	return out
+}
+
+// printCorrectedSummary prints raw and corrected percentiles side by side as part of PrintSummary.
+func (s *Stats) printCorrectedSummary(w io.Writer) {
+	delay := s.ScheduleDelay()
+	if delay == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nCoordinated Omission Correction (latency from intended start):\n")
+	fmt.Fprintf(w, "  Schedule Delay: avg %.2f ms, P99 %.2f ms, max %.2f ms\n", delay.Avg, delay.P99, delay.Max)
+	fmt.Fprintf(w, "  Latency (ms): |       P50       |       P90       |       P99       |      P99.9      |       Max\n")
+	fmt.Fprintf(w, "                |   Raw  |  Corr  |   Raw  |  Corr  |   Raw  |  Corr  |   Raw  |  Corr  |   Raw  |  Corr\n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------|--------|--------|--------\n")
+	for _, c := range s.CorrectedLatencies() {
+		fmt.Fprintf(w, "  %-13s |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f\n",
+			c.Operation+" "+c.Metric, c.Raw.P50, c.Corrected.P50, c.Raw.P90, c.Corrected.P90,
+			c.Raw.P99, c.Corrected.P99, c.Raw.P999, c.Corrected.P999, c.Raw.Max, c.Corrected.Max)
+	}
+}
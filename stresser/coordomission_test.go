@@ -0,0 +1,53 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCorrectedLatencies(t *testing.T) {
+	start := time.Now()
+	stats := NewStats()
+	// Nine GETs on schedule, one that started 500ms late because the workers were busy
+	for i := 0; i < 10; i++ {
+		due := start.Add(time.Duration(i) * 10 * time.Millisecond)
+		began := due
+		if i == 9 {
+			began = due.Add(500 * time.Millisecond)
+		}
+		stats.AddResult(Result{Timestamp: began, IntendedStart: due, Operation: "GET", ObjectKey: "k", TTFB: 5 * time.Millisecond, TTLB: 10 * time.Millisecond, BytesDownloaded: 1})
+	}
+	// Unpaced results are left out
+	stats.AddResult(Result{Timestamp: start, Operation: "PUT", ObjectKey: "p", TTFB: -1, TTLB: time.Second})
+	stats.Calculate(start, start.Add(time.Second))
+
+	delay := stats.ScheduleDelay()
+	if delay == nil || delay.Max < 499 || delay.P50 > 1 {
+		t.Fatalf("Unexpected schedule delay: %+v", delay)
+	}
+	latencies := stats.CorrectedLatencies()
+	if len(latencies) != 2 || latencies[0].Metric != "TTFB" || latencies[1].Metric != "TTLB" {
+		t.Fatalf("Expected GET TTFB and TTLB only, got %+v", latencies)
+	}
+	ttlb := latencies[1]
+	if ttlb.Raw.Max > 11 || ttlb.Corrected.Max < 509 || ttlb.Corrected.P50 > 11 {
+		t.Errorf("Unexpected GET TTLB raw %+v, corrected %+v", ttlb.Raw, ttlb.Corrected)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Coordinated Omission Correction") {
+		t.Errorf("Summary is missing the corrected latencies:\n%s", buf.String())
+	}
+}
+
+func TestCorrectedLatenciesUnpaced(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "k", TTFB: time.Millisecond, TTLB: time.Millisecond})
+	if stats.ScheduleDelay() != nil || stats.CorrectedLatencies() != nil {
+		t.Error("Expected no correction without a target rate")
+	}
+}
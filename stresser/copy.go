@@ -0,0 +1,231 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// DefaultCopyPartSizeMB is the part size of copies beyond the CopyObject limit when
+	// -copy-part-size is not set.
+	DefaultCopyPartSizeMB = 512
+	// maxCopyObjectSize is the largest object a single CopyObject call can copy.
+	maxCopyObjectSize = 5 << 30
+	// maxCopyParts is the largest number of parts of a multipart upload.
+	maxCopyParts = 10000
+)
+
+// copyClient is the part of the S3 API 'copy' mode uses on top of S3ClientAPI.
+type copyClient interface {
+	S3ClientAPI
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// objectSizes caches the sizes of copy sources, so each source is looked up with one HEAD
+// however often it is copied. The size decides between CopyObject and a multipart copy,
+// and gives the copy throughput.
+type objectSizes struct {
+	mu    sync.Mutex
+	sizes map[string]int64
+}
+
+func newObjectSizes() *objectSizes {
+	return &objectSizes{sizes: make(map[string]int64)}
+}
+
+// size returns the size of key, sending a HEAD the first time the key is seen.
+func (o *objectSizes) size(ctx context.Context, client S3ClientAPI, bucket, key string) (int64, error) {
+	o.mu.Lock()
+	size, ok := o.sizes[key]
+	o.mu.Unlock()
+	if ok {
+		return size, nil
+	}
+	resp, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return 0, err
+	}
+	size = aws.ToInt64(resp.ContentLength)
+	o.mu.Lock()
+	o.sizes[key] = size
+	o.mu.Unlock()
+	return size, nil
+}
+
+// copySource returns the URL-encoded "bucket/key" CopySource of key.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
+
+// performCopyOperation copies the object at src to dst within bucket and measures how long
+// the server took. Objects larger than partSize are copied with a multipart upload of
+// UploadPartCopy calls, sent one after the other. With partSize 0, objects up to the 5 GiB
+// CopyObject limit are copied in one call and larger ones in DefaultCopyPartSizeMB parts.
+// The result carries the source key, and the size of the copy in BytesCopied.
+func performCopyOperation(ctx context.Context, client copyClient, sizes *objectSizes, bucket, src, dst string, partSize int64) Result {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "COPY",
+		ObjectKey: src,
+		TTFB:      -1, // Not applicable, the data never passes through the client
+		TTLB:      -1,
+	}
+
+	size, err := sizes.size(ctx, client, bucket, src)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to look up copy source size: %v", err)
+		recordErrorRequestID(&result, err)
+		return result
+	}
+	threshold := int64(maxCopyObjectSize)
+	if partSize > 0 {
+		threshold = min(partSize, maxCopyObjectSize)
+	} else {
+		partSize = DefaultCopyPartSizeMB << 20
+	}
+
+	reqStartTime := time.Now()
+	if size <= threshold {
+		resp, err := client.CopyObject(traceConnection(ctx, &result, reqStartTime), &s3.CopyObjectInput{
+			Bucket:     aws.String(bucket),
+			Key:        aws.String(dst),
+			CopySource: aws.String(copySource(bucket, src)),
+		})
+		if err != nil {
+			result.Error = err.Error()
+			recordErrorRequestID(&result, err)
+			return result
+		}
+		recordResponseMetadata(&result, resp.ResultMetadata)
+	} else {
+		if err := multipartCopy(ctx, client, &result, bucket, src, dst, size, partSize, reqStartTime); err != nil {
+			result.Error = err.Error()
+			recordErrorRequestID(&result, err)
+			return result
+		}
+	}
+	result.TTLB = time.Since(reqStartTime)
+	result.BytesCopied = size
+	return result
+}
+
+// multipartCopy copies size bytes of src to dst in parts of partSize, or larger parts if
+// the object would need more than 10000. A failed copy is aborted so it leaves no parts behind.
+func multipartCopy(ctx context.Context, client copyClient, result *Result, bucket, src, dst string, size, partSize int64, start time.Time) error {
+	partSize = min(partSize, maxCopyObjectSize)
+	if (size+partSize-1)/partSize > maxCopyParts {
+		partSize = (size + maxCopyParts - 1) / maxCopyParts
+	}
+	create, err := client.CreateMultipartUpload(traceConnection(ctx, result, start), &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(dst),
+	})
+	if err != nil {
+		return err
+	}
+	uploadID := create.UploadId
+
+	var parts []types.CompletedPart
+	source := copySource(bucket, src)
+	for offset, number := int64(0), int32(1); offset < size; offset, number = offset+partSize, number+1 {
+		end := min(offset+partSize, size) - 1
+		resp, err := client.UploadPartCopy(traceConnection(ctx, result, start), &s3.UploadPartCopyInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(dst),
+			UploadId:        uploadID,
+			PartNumber:      aws.Int32(number),
+			CopySource:      aws.String(source),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+		})
+		if err != nil {
+			abortMultipartCopy(ctx, client, bucket, dst, uploadID)
+			return fmt.Errorf("part %d: %w", number, err)
+		}
+		part := types.CompletedPart{PartNumber: aws.Int32(number)}
+		if resp.CopyPartResult != nil {
+			part.ETag = resp.CopyPartResult.ETag
+		}
+		parts = append(parts, part)
+	}
+
+	resp, err := client.CompleteMultipartUpload(traceConnection(ctx, result, start), &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(dst),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		abortMultipartCopy(ctx, client, bucket, dst, uploadID)
+		return err
+	}
+	recordResponseMetadata(result, resp.ResultMetadata)
+	return nil
+}
+
+// abortMultipartCopy aborts a failed multipart copy, also when the run has just ended.
+func abortMultipartCopy(ctx context.Context, client copyClient, bucket, key string, uploadID *string) {
+	_, err := client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if err != nil {
+		slog.Warn("Failed to abort multipart copy, its parts remain until the upload is aborted", "key", key, "uploadId", aws.ToString(uploadID), "error", err)
+	}
+}
+
+// printCopySummary prints copy latency and server-side copy throughput as part of PrintSummary.
+func (s *Stats) printCopySummary(w io.Writer) {
+	if s.TotalCopies == 0 {
+		return
+	}
+	h := s.CopyTTLBHist
+	fmt.Fprintf(w, "\nCOPY Operations (%d total):\n", s.TotalCopies)
+	fmt.Fprintf(w, "  Success:        %d\n", h.Count())
+	fmt.Fprintf(w, "  Bytes Copied:   %d (%.2f MiB)\n", s.TotalBytesCopied, float64(s.TotalBytesCopied)/(1024*1024))
+	if s.actualDuration.Seconds() > 0 {
+		fmt.Fprintf(w, "  Avg Throughput: %.2f MiB/s\n", float64(s.TotalBytesCopied)/(1024*1024)/s.actualDuration.Seconds())
+	}
+	if h.Count() == 0 {
+		fmt.Fprintln(w, "  No successful COPYs to calculate latency.")
+		return
+	}
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	fmt.Fprintf(w, "  TTLB (total)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+		ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Percentile(99.9)), ms(h.Max()))
+}
+
+// copySummary returns the COPY figures for Summary, nil if nothing was copied.
+func (s *Stats) copySummary() *OperationSummary {
+	if s.TotalCopies == 0 {
+		return nil
+	}
+	h := s.CopyTTLBHist
+	sum := &OperationSummary{Total: s.TotalCopies, Success: h.Count(), Bytes: s.TotalBytesCopied}
+	if secs := s.actualDuration.Seconds(); secs > 0 {
+		sum.ThroughputMiB = float64(s.TotalBytesCopied) / (1024 * 1024) / secs
+	}
+	if h.Count() > 0 {
+		sum.TTLB = &LatencySummary{ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Max()), ms(h.Percentile(99.9))}
+	}
+	return sum
+}
@@ -0,0 +1,131 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// copyingS3Client records copy calls against objects of a fixed size.
+type copyingS3Client struct {
+	stubS3Client
+	size      int64
+	heads     int
+	copies    []*s3.CopyObjectInput
+	parts     []*s3.UploadPartCopyInput
+	completed *s3.CompleteMultipartUploadInput
+	aborted   bool
+	partErr   error
+}
+
+func (c *copyingS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	c.heads++
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(c.size)}, nil
+}
+
+func (c *copyingS3Client) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	c.copies = append(c.copies, params)
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (c *copyingS3Client) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+
+func (c *copyingS3Client) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	if c.partErr != nil {
+		return nil, c.partErr
+	}
+	c.parts = append(c.parts, params)
+	return &s3.UploadPartCopyOutput{CopyPartResult: &types.CopyPartResult{ETag: aws.String("etag")}}, nil
+}
+
+func (c *copyingS3Client) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	c.completed = params
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (c *copyingS3Client) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	c.aborted = true
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func TestCopySource(t *testing.T) {
+	if got := copySource("bucket", "dir/a b+c.dat"); got != "bucket/dir/a%20b+c.dat" {
+		t.Errorf("copySource = %q", got)
+	}
+}
+
+func TestPerformCopyOperation(t *testing.T) {
+	ctx := context.Background()
+	client := &copyingS3Client{size: 1 << 20}
+	sizes := newObjectSizes()
+
+	for range 2 {
+		result := performCopyOperation(ctx, client, sizes, "bucket", "src/a", "dst/a", 0)
+		if result.Error != "" || result.Operation != "COPY" || result.ObjectKey != "src/a" || result.TTLB < 0 || result.BytesCopied != 1<<20 {
+			t.Fatalf("Unexpected result: %+v", result)
+		}
+	}
+	if client.heads != 1 {
+		t.Errorf("Expected the source size to be looked up once, got %d HEADs", client.heads)
+	}
+	if len(client.copies) != 2 || aws.ToString(client.copies[0].CopySource) != "bucket/src/a" || aws.ToString(client.copies[0].Key) != "dst/a" {
+		t.Errorf("Unexpected CopyObject calls: %+v", client.copies)
+	}
+}
+
+func TestPerformCopyOperationMultipart(t *testing.T) {
+	ctx := context.Background()
+	client := &copyingS3Client{size: 12 << 20}
+
+	result := performCopyOperation(ctx, client, newObjectSizes(), "bucket", "src/a", "dst/a", 5<<20)
+	if result.Error != "" || result.BytesCopied != 12<<20 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if len(client.copies) != 0 {
+		t.Errorf("Expected a multipart copy, got %d CopyObject calls", len(client.copies))
+	}
+	var ranges []string
+	for _, p := range client.parts {
+		ranges = append(ranges, aws.ToString(p.CopySourceRange))
+	}
+	want := "bytes=0-5242879,bytes=5242880-10485759,bytes=10485760-12582911"
+	if strings.Join(ranges, ",") != want {
+		t.Errorf("Expected part ranges %s, got %v", want, ranges)
+	}
+	if client.completed == nil || len(client.completed.MultipartUpload.Parts) != 3 {
+		t.Errorf("Expected the upload to be completed with 3 parts, got %+v", client.completed)
+	}
+
+	failing := &copyingS3Client{size: 12 << 20, partErr: statusError(500)}
+	result = performCopyOperation(ctx, failing, newObjectSizes(), "bucket", "src/a", "dst/a", 5<<20)
+	if result.Error == "" || !failing.aborted {
+		t.Errorf("Expected a failed part to fail the copy and abort the upload, got %+v (aborted %v)", result, failing.aborted)
+	}
+}
+
+func TestCopySummary(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "COPY", ObjectKey: "a", TTFB: -1, TTLB: 20 * time.Millisecond, BytesCopied: 1 << 20})
+	stats.AddResult(Result{Timestamp: now, Operation: "COPY", ObjectKey: "b", TTFB: -1, TTLB: -1, Error: "boom"})
+	stats.Calculate(now, now.Add(time.Second))
+
+	sum := stats.Summary()
+	if sum.Copy == nil || sum.Copy.Total != 2 || sum.Copy.Success != 1 || sum.Copy.Bytes != 1<<20 || sum.Copy.ThroughputMiB != 1 {
+		t.Errorf("Unexpected copy summary: %+v", sum.Copy)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "COPY Operations (2 total)") || !strings.Contains(buf.String(), "Avg Throughput: 1.00 MiB/s") {
+		t.Errorf("Summary missing copy section:\n%s", buf.String())
+	}
+}
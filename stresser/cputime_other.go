@@ -0,0 +1,10 @@
+//go:build !unix
+
+package stresser
+
+import "time"
+
+// processCPUTime is not implemented on this platform; CPU usage is reported as unavailable.
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
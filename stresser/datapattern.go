@@ -0,0 +1,126 @@
+package stresser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Data patterns of PUT bodies, the kinds of -data-pattern.
+const (
+	DataPatternRandom       = "random"       // Incompressible and unique, the default
+	DataPatternZeroes       = "zeroes"       // All zero bytes
+	DataPatternCompressible = "compressible" // Text-like data compressing by about a given ratio
+	DataPatternDedupe       = "dedupe"       // Chunks drawn from a small pool shared by all bodies
+)
+
+const (
+	// compressibleSegmentWords is the span over which compressible bodies mix random and
+	// text words, small enough for every compressor's window.
+	compressibleSegmentWords = 512 // 4 KiB
+	// defaultDedupeBlocks is the pool size of 'dedupe' without a count.
+	defaultDedupeBlocks = 16
+)
+
+// compressibleText fills the compressible part of bodies, repeated word by word.
+var compressibleText = textWords("the quick brown fox jumps over the lazy dog while storage systems count bytes. ")
+
+// textWords packs s into little-endian words, padding the last one with spaces.
+func textWords(s string) []uint64 {
+	for len(s)%8 != 0 {
+		s += " "
+	}
+	words := make([]uint64, len(s)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64([]byte(s[i*8 : i*8+8]))
+	}
+	return words
+}
+
+// dataPattern selects the content of PUT bodies. Stores with inline compression or
+// deduplication store, and often perform, very differently depending on it. The zero
+// value is DataPatternRandom.
+type dataPattern struct {
+	kind        string
+	ratio       float64 // DataPatternCompressible: target compression ratio
+	randomWords int64   // DataPatternCompressible: random words per segment, the rest is text
+	blocks      int     // DataPatternDedupe: distinct chunks all bodies are made of
+}
+
+// parseDataPattern parses "random", "zeroes", "compressible:<ratio>" or "dedupe[:<blocks>]".
+func parseDataPattern(spec string) (dataPattern, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	kind, value, hasValue := strings.Cut(spec, ":")
+	switch kind {
+	case "", DataPatternRandom:
+		if hasValue {
+			return dataPattern{}, fmt.Errorf("invalid data pattern %q: random takes no value", spec)
+		}
+		return dataPattern{}, nil
+	case DataPatternZeroes, "zeros":
+		if hasValue {
+			return dataPattern{}, fmt.Errorf("invalid data pattern %q: zeroes takes no value", spec)
+		}
+		return dataPattern{kind: DataPatternZeroes}, nil
+	case DataPatternCompressible:
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 1 || math.IsInf(ratio, 0) {
+			return dataPattern{}, fmt.Errorf("invalid data pattern %q: expected compressible:<ratio> with a ratio of at least 1, e.g. compressible:3", spec)
+		}
+		words := max(1, int64(math.Round(compressibleSegmentWords/ratio)))
+		return dataPattern{kind: DataPatternCompressible, ratio: ratio, randomWords: words}, nil
+	case DataPatternDedupe:
+		blocks := defaultDedupeBlocks
+		if hasValue {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return dataPattern{}, fmt.Errorf("invalid data pattern %q: expected dedupe:<blocks> with at least 1 block, e.g. dedupe:16", spec)
+			}
+			blocks = n
+		}
+		return dataPattern{kind: DataPatternDedupe, blocks: blocks}, nil
+	}
+	return dataPattern{}, fmt.Errorf("invalid data pattern %q: must be random, zeroes, compressible:<ratio> or dedupe[:<blocks>]", spec)
+}
+
+// String returns the spec of d in the form parseDataPattern accepts.
+func (d dataPattern) String() string {
+	switch d.kind {
+	case DataPatternZeroes:
+		return DataPatternZeroes
+	case DataPatternCompressible:
+		return DataPatternCompressible + ":" + strconv.FormatFloat(d.ratio, 'g', -1, 64)
+	case DataPatternDedupe:
+		return DataPatternDedupe + ":" + strconv.Itoa(d.blocks)
+	}
+	return DataPatternRandom
+}
+
+// payload returns a body of size bytes with this pattern; see newPayload.
+func (d dataPattern) payload(size int64, r *rand.Rand) *payloadReader {
+	p := newPayload(size, r)
+	p.pattern = d
+	return p
+}
+
+// patternWord returns the i-th eight bytes of a body whose pattern isn't random.
+func (p *payloadReader) patternWord(i int64) uint64 {
+	switch p.pattern.kind {
+	case DataPatternZeroes:
+		return 0
+	case DataPatternCompressible:
+		if i%compressibleSegmentWords < p.pattern.randomWords {
+			return p.randomWord(i)
+		}
+		return compressibleText[i%int64(len(compressibleText))]
+	default: // DataPatternDedupe
+		// Every chunk is one of the pool's blocks, picked by the body's seed, so the same
+		// chunks recur within and across bodies at chunk-aligned offsets
+		const chunkWords = payloadChunk / 8
+		block := splitmix64(p.seed^uint64(i/chunkWords)) % uint64(p.pattern.blocks)
+		return p.block[(block*chunkWords+uint64(i%chunkWords))%payloadBlockWords] ^ splitmix64(block)
+	}
+}
@@ -0,0 +1,82 @@
+package stresser
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestParseDataPattern(t *testing.T) {
+	for spec, want := range map[string]string{
+		"":                 DataPatternRandom,
+		"random":           DataPatternRandom,
+		"zeros":            DataPatternZeroes,
+		"Compressible:2.5": "compressible:2.5",
+		"dedupe":           "dedupe:16",
+		"dedupe:4":         "dedupe:4",
+	} {
+		d, err := parseDataPattern(spec)
+		if err != nil {
+			t.Errorf("%q: %v", spec, err)
+		} else if d.String() != want {
+			t.Errorf("%q: got %q, expected %q", spec, d.String(), want)
+		}
+	}
+	for _, spec := range []string{"noise", "random:1", "zeroes:1", "compressible", "compressible:0.5", "dedupe:0"} {
+		if _, err := parseDataPattern(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+// readPattern returns a body of size bytes with pattern spec.
+func readPattern(t *testing.T, spec string, size int64, r *rand.Rand) []byte {
+	t.Helper()
+	d, err := parseDataPattern(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(d.payload(size, r))
+	if err != nil || int64(len(body)) != size {
+		t.Fatalf("%s: read %d of %d bytes (%v)", spec, len(body), size, err)
+	}
+	return body
+}
+
+func TestDataPatterns(t *testing.T) {
+	const size = 4 * payloadChunk
+
+	// The random pattern is the plain payload
+	random := readPattern(t, "random", size, rand.New(rand.NewSource(1)))
+	plain, _ := io.ReadAll(newPayload(size, rand.New(rand.NewSource(1))))
+	if !bytes.Equal(random, plain) {
+		t.Error("The random pattern differs from the default payload")
+	}
+
+	if zeroes := readPattern(t, "zeroes", size, rand.New(rand.NewSource(1))); !bytes.Equal(zeroes, make([]byte, size)) {
+		t.Error("Expected only zero bytes")
+	}
+
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	w.Write(readPattern(t, "compressible:3", size, rand.New(rand.NewSource(1))))
+	w.Close()
+	if ratio := float64(size) / float64(compressed.Len()); ratio < 2 || ratio > 4 {
+		t.Errorf("Expected a compression ratio of about 3, got %.2f", ratio)
+	}
+
+	// Dedupe bodies are made of the pool's blocks only, whatever the body
+	r := rand.New(rand.NewSource(1))
+	chunks := make(map[string]bool)
+	for range 5 {
+		body := readPattern(t, "dedupe:2", size, r)
+		for off := 0; off < size; off += payloadChunk {
+			chunks[string(body[off:off+payloadChunk])] = true
+		}
+	}
+	if len(chunks) != 2 {
+		t.Errorf("Expected 2 distinct chunks, got %d", len(chunks))
+	}
+}
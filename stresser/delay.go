@@ -0,0 +1,88 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// delayDistribution describes a random delay, parsed from a "<kind>:<duration>" spec.
+// Supported kinds:
+//   - fixed:<d>        always d
+//   - uniform:<d>      uniformly distributed in [0, d)
+//   - exponential:<d>  exponentially distributed with mean d (Poisson-like arrivals)
+type delayDistribution struct {
+	kind  string
+	scale time.Duration
+}
+
+// parseDelayDistribution parses a delay spec. An empty spec yields a zero
+// distribution that never delays.
+func parseDelayDistribution(spec string) (delayDistribution, error) {
+	if spec == "" {
+		return delayDistribution{}, nil
+	}
+	kind, value, found := strings.Cut(spec, ":")
+	if !found {
+		return delayDistribution{}, fmt.Errorf("invalid delay spec %q: expected <kind>:<duration>", spec)
+	}
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "fixed", "uniform", "exponential":
+	case "exp":
+		kind = "exponential"
+	default:
+		return delayDistribution{}, fmt.Errorf("invalid delay spec %q: unknown distribution %q (use fixed, uniform or exponential)", spec, kind)
+	}
+	scale, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return delayDistribution{}, fmt.Errorf("invalid delay spec %q: %w", spec, err)
+	}
+	if scale < 0 {
+		return delayDistribution{}, fmt.Errorf("invalid delay spec %q: duration must not be negative", spec)
+	}
+	return delayDistribution{kind: kind, scale: scale}, nil
+}
+
+// enabled reports whether the distribution produces any delay at all.
+func (d delayDistribution) enabled() bool {
+	return d.scale > 0
+}
+
+// sample draws one delay from the distribution using the caller's random source.
+func (d delayDistribution) sample(r *rand.Rand) time.Duration {
+	switch d.kind {
+	case "fixed":
+		return d.scale
+	case "uniform":
+		return time.Duration(r.Int63n(int64(d.scale)))
+	case "exponential":
+		return time.Duration(r.ExpFloat64() * float64(d.scale))
+	}
+	return 0
+}
+
+// String renders the distribution in the same form it was parsed from.
+func (d delayDistribution) String() string {
+	if !d.enabled() {
+		return "none"
+	}
+	return fmt.Sprintf("%s:%s", d.kind, d.scale)
+}
+
+// sleepContext sleeps for d or until ctx is done. It returns false if the context ended first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+package stresser
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDelayDistribution(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expectError bool
+		expected    string
+	}{
+		{spec: "", expected: "none"},
+		{spec: "uniform:50ms", expected: "uniform:50ms"},
+		{spec: "exp:20ms", expected: "exponential:20ms"},
+		{spec: "fixed:1s", expected: "fixed:1s"},
+		{spec: "uniform", expectError: true},
+		{spec: "gaussian:10ms", expectError: true},
+		{spec: "uniform:abc", expectError: true},
+		{spec: "uniform:-5ms", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			d, err := parseDelayDistribution(tt.spec)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseDelayDistribution(%q) error = %v, expectError %v", tt.spec, err, tt.expectError)
+			}
+			if err == nil && d.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, d.String())
+			}
+		})
+	}
+}
+
+func TestDelayDistributionSample(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	fixed, _ := parseDelayDistribution("fixed:10ms")
+	if got := fixed.sample(r); got != 10*time.Millisecond {
+		t.Errorf("Expected fixed sample of 10ms, got %v", got)
+	}
+
+	uniform, _ := parseDelayDistribution("uniform:10ms")
+	for i := 0; i < 1000; i++ {
+		if got := uniform.sample(r); got < 0 || got >= 10*time.Millisecond {
+			t.Fatalf("Uniform sample out of range: %v", got)
+		}
+	}
+
+	exponential, _ := parseDelayDistribution("exponential:10ms")
+	var total time.Duration
+	for i := 0; i < 10000; i++ {
+		total += exponential.sample(r)
+	}
+	mean := total / 10000
+	if mean < 8*time.Millisecond || mean > 12*time.Millisecond {
+		t.Errorf("Expected exponential mean near 10ms, got %v", mean)
+	}
+}
+
+func TestThinkTimeValidation(t *testing.T) {
+	base := Config{
+		Endpoint:      "https://test-endpoint.com",
+		Region:        "us-east-1",
+		Bucket:        "test-bucket",
+		Duration:      "30s",
+		Concurrency:   5,
+		ManifestPath:  "manifest.txt",
+		OutputFile:    "results.csv",
+		OperationType: "read",
+	}
+	c := base
+	c.ThinkTime = "EXP:200ms"
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if c.ThinkTime != "exponential:200ms" {
+		t.Errorf("Expected the canonical think time, got %q", c.ThinkTime)
+	}
+
+	for _, think := range []string{"sometimes", "uniform:-1s"} {
+		c := base
+		c.ThinkTime = think
+		if err := c.Validate(); err == nil {
+			t.Errorf("Expected an error for think time %q", think)
+		}
+	}
+	c = base
+	c.ThinkTime = "fixed:1s"
+	c.ArrivalRate = 10
+	if err := c.Validate(); err == nil {
+		t.Error("Expected an error for think time with open-loop arrivals")
+	}
+}
+
+func TestThinkTimeSummary(t *testing.T) {
+	stats := NewStats()
+	stats.ThinkTime = "uniform:500ms"
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "k", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond})
+	stats.Calculate(now, now.Add(time.Second))
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Think Time:     uniform:500ms") {
+		t.Errorf("Summary is missing the think time:\n%s", buf.String())
+	}
+	if sum := stats.Summary(); sum.ThinkTime != "uniform:500ms" {
+		t.Errorf("Expected the think time in the machine-readable summary, got %q", sum.ThinkTime)
+	}
+}
@@ -0,0 +1,168 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Outcomes of PUTs cut by -disconnect-at, see Result.Disconnect.
+const (
+	DisconnectAborted = "aborted" // The upload was cut and no object became visible
+	DisconnectVisible = "visible" // A partial object became visible after the cut, also reported as an error
+)
+
+// errSimulatedDisconnect is returned by the request body once the configured
+// number of bytes has been sent, which makes net/http tear down the connection.
+var errSimulatedDisconnect = errors.New("simulated mid-upload disconnect")
+
+// disconnectingHTTPClient wraps the SDK HTTP client and truncates every request
+// body after cutAfter bytes. The transport aborts the request when the body
+// returns an error, so the server sees the connection drop mid-upload.
+type disconnectingHTTPClient struct {
+	next     s3.HTTPClient
+	cutAfter int64
+	sent     *int64 // Bytes handed to the transport before the cut
+}
+
+func (c disconnectingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &cutReader{ReadCloser: req.Body, remaining: c.cutAfter, sent: c.sent}
+	}
+	return c.next.Do(req)
+}
+
+// cutReader passes through reads until remaining reaches zero, then fails.
+type cutReader struct {
+	io.ReadCloser
+	remaining int64
+	sent      *int64
+}
+
+func (r *cutReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, errSimulatedDisconnect
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	*r.sent += int64(n)
+	return n, err
+}
+
+// performDisconnectedPutOperation starts a PUT, cuts the connection after the
+// configured fraction of the body has been sent, and then verifies with a HEAD
+// request that no partial object became visible under the key. The result's Disconnect
+// tells an upload that was aborted cleanly from one that left a partial object behind;
+// neither is a successful PUT.
+func performDisconnectedPutOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string, body io.ReadSeeker, fraction float64) Result {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "PUT",
+		ObjectKey: key,
+		TTFB:      -1,
+		TTLB:      -1,
+		Error:     "",
+	}
+
+	size, err := bodySize(body)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid PUT body: %v", err)
+		return result
+	}
+	var sent int64
+	cutAfter := int64(float64(size) * fraction)
+	reqStartTime := time.Now()
+	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	}, func(o *s3.Options) {
+		o.HTTPClient = disconnectingHTTPClient{next: o.HTTPClient, cutAfter: cutAfter, sent: &sent}
+		o.RetryMaxAttempts = 1 // A retry would resend the body and hide the disconnect
+	})
+	result.TTLB = time.Since(reqStartTime)
+	result.BytesUploaded = sent
+
+	if err == nil {
+		result.Error = "disconnect simulation: upload completed before the connection was cut"
+		return result
+	}
+	if !errors.Is(err, errSimulatedDisconnect) {
+		// The request failed for some other reason, report it as-is
+		result.Error = err.Error()
+		return result
+	}
+
+	// The upload was aborted; the object must not exist now
+	_, err = s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	switch {
+	case errors.As(err, &notFound):
+		// Expected: nothing visible after the aborted upload
+		result.Disconnect = DisconnectAborted
+	case err == nil:
+		result.Disconnect = DisconnectVisible
+		result.Error = fmt.Sprintf("partial object visible after disconnect at %d/%d bytes", sent, size)
+		slog.Warn("Partial object visible after aborted upload", "bucket", bucket, "key", key, "sentBytes", sent, "totalBytes", size)
+	default:
+		result.Error = fmt.Sprintf("visibility check failed: %v", err)
+	}
+	return result
+}
+
+// DisconnectReport counts the PUTs cut by -disconnect-at. They are kept out of the PUT
+// figures: an aborted upload is neither a successful nor a failed PUT.
+type DisconnectReport struct {
+	Aborted   int64 `json:"aborted" yaml:"aborted"`     // Uploads cut that left no object behind
+	Visible   int64 `json:"visible" yaml:"visible"`     // Uploads cut that left a partial object visible, also counted as errors
+	BytesSent int64 `json:"bytesSent" yaml:"bytesSent"` // Body bytes sent before the cuts
+}
+
+// addDisconnectResult counts a PUT cut by -disconnect-at. Called from AddResult.
+func (s *Stats) addDisconnectResult(r Result) {
+	if r.Disconnect == "" {
+		return
+	}
+	if r.Disconnect == DisconnectAborted {
+		s.disconnects.Aborted++
+	} else {
+		s.disconnects.Visible++
+	}
+	s.disconnects.BytesSent += r.BytesUploaded
+}
+
+// Disconnects returns the PUTs cut by -disconnect-at, or nil if none were.
+func (s *Stats) Disconnects() *DisconnectReport {
+	if s.disconnects.Aborted+s.disconnects.Visible == 0 {
+		return nil
+	}
+	report := s.disconnects
+	return &report
+}
+
+// printDisconnectSummary prints the outcomes of the cut uploads as part of PrintSummary.
+func (s *Stats) printDisconnectSummary(w io.Writer) {
+	dr := s.Disconnects()
+	if dr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nSimulated Disconnects:\n")
+	fmt.Fprintf(w, "  Aborted PUTs:   %d (no object left behind, not counted as PUTs)\n", dr.Aborted)
+	fmt.Fprintf(w, "  Partial Object: %d (visible after the cut, counted as PUT errors)\n", dr.Visible)
+	fmt.Fprintf(w, "  Bytes Sent:     %.2f MiB before the cuts\n", float64(dr.BytesSent)/(1024*1024))
+}
@@ -0,0 +1,100 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bodyReadingClient drains the request body like a transport would.
+type bodyReadingClient struct {
+	read []byte
+}
+
+func (c *bodyReadingClient) Do(req *http.Request) (*http.Response, error) {
+	data, err := io.ReadAll(req.Body)
+	c.read = data
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestDisconnectingHTTPClient(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 1000)
+	next := &bodyReadingClient{}
+	var sent int64
+	client := disconnectingHTTPClient{next: next, cutAfter: 250, sent: &sent}
+
+	req, err := http.NewRequest(http.MethodPut, "http://example.com/bucket/key", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	_, err = client.Do(req)
+	if !errors.Is(err, errSimulatedDisconnect) {
+		t.Fatalf("Expected simulated disconnect error, got %v", err)
+	}
+	if len(next.read) != 250 {
+		t.Errorf("Expected 250 bytes to reach the transport, got %d", len(next.read))
+	}
+	if sent != 250 {
+		t.Errorf("Expected sent counter to be 250, got %d", sent)
+	}
+}
+
+func TestDisconnectedPutOutcomes(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	// Whether the server keeps a partial object
+	visible := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.Method == http.MethodHead && !visible {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret"}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	payload := bytes.Repeat([]byte("x"), 1000)
+
+	aborted := performDisconnectedPutOperation(context.Background(), client, cfg.Bucket, "key", bytes.NewReader(payload), 0.5)
+	if aborted.Error != "" || aborted.Disconnect != DisconnectAborted {
+		t.Fatalf("Expected a clean abort, got %+v", aborted)
+	}
+	visible = true
+	partial := performDisconnectedPutOperation(context.Background(), client, cfg.Bucket, "key", bytes.NewReader(payload), 0.5)
+	if partial.Error == "" || partial.Disconnect != DisconnectVisible {
+		t.Fatalf("Expected a visible partial object to be an error, got %+v", partial)
+	}
+
+	stats := NewStats()
+	stats.AddResult(aborted)
+	stats.AddResult(partial)
+	stats.AddResult(Result{Timestamp: time.Now(), Operation: "PUT", ObjectKey: "other", TTLB: time.Millisecond, BytesUploaded: 100})
+	if stats.TotalPuts != 2 || stats.PutTTLBHist.Count() != 1 || stats.TotalBytesUp != 100 || stats.TotalErrors != 1 {
+		t.Errorf("Expected the aborted upload out of the PUT figures, got %d PUTs, %d successful, %d bytes, %d errors",
+			stats.TotalPuts, stats.PutTTLBHist.Count(), stats.TotalBytesUp, stats.TotalErrors)
+	}
+	dr := stats.Disconnects()
+	if dr == nil || dr.Aborted != 1 || dr.Visible != 1 || dr.BytesSent != aborted.BytesUploaded+partial.BytesUploaded {
+		t.Errorf("Unexpected disconnect report: %+v", dr)
+	}
+}
+
+func TestValidateDisconnectCopy(t *testing.T) {
+	cfg := Config{Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "copy", DisconnectFraction: 0.5}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "-disconnect-at") {
+		t.Errorf("Expected copies to be rejected with -disconnect-at, got %v", err)
+	}
+}
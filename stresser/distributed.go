@@ -0,0 +1,426 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAgentPort is used for agent addresses given without a port.
+const DefaultAgentPort = "7000"
+
+// Agent API paths. The coordinator posts an AgentRunRequest to agentRunPath and gets an
+// AgentRunResponse back once the shard has finished; agentStopPath ends the run early.
+const (
+	agentRunPath    = "/v1/run"
+	agentStopPath   = "/v1/stop"
+	agentHealthPath = "/v1/health"
+)
+
+// AgentRunRequest is the work a coordinator hands to one agent.
+type AgentRunRequest struct {
+	Config *Config `json:"config"`
+	Agent  int     `json:"agent"`  // Index of the agent, used in logs
+	Agents int     `json:"agents"` // Number of agents taking part
+}
+
+// AgentRunResponse carries the results of one agent's shard back to the coordinator.
+type AgentRunResponse struct {
+	Results        []Result `json:"results"`
+	ClientWarnings []string `json:"clientWarnings,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// Agent runs stress test shards on behalf of a remote coordinator. It runs one shard at a time.
+type Agent struct {
+	// run executes a shard, RunStressTest unless replaced by tests.
+	run func(ctx context.Context, cfg *Config) ([]Result, *Stats, error)
+
+	token string // Bearer token coordinators must present to run or stop a shard
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // Ends the shard in progress, nil when idle
+}
+
+// NewAgent creates an agent that only accepts runs from coordinators presenting token, which
+// must not be empty. Serve it with http.ListenAndServe(addr, agent), or ListenAndServeTLS
+// since the runs carry the coordinator's credentials.
+func NewAgent(token string) *Agent {
+	return &Agent{run: RunStressTest, token: token}
+}
+
+// ServeHTTP implements the agent API. Everything but the health check requires the token.
+func (a *Agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != agentHealthPath && !a.authorized(r) {
+		http.Error(w, "missing or invalid agent token", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.URL.Path == agentHealthPath && r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Path == agentRunPath && r.Method == http.MethodPost:
+		a.handleRun(w, r)
+	case r.URL.Path == agentStopPath && r.Method == http.MethodPost:
+		a.mu.Lock()
+		if a.cancel != nil {
+			a.cancel()
+		}
+		a.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorized reports whether r carries the agent's bearer token.
+func (a *Agent) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
+
+func (a *Agent) handleRun(w http.ResponseWriter, r *http.Request) {
+	var req AgentRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Config == nil {
+		http.Error(w, "invalid run request", http.StatusBadRequest)
+		return
+	}
+	// A coordinator never sends these, so a run that does is not allowed to touch this host
+	if settings := hostSettings(req.Config); len(settings) > 0 {
+		http.Error(w, "run request sets host-local settings: "+strings.Join(settings, ", "), http.StatusBadRequest)
+		return
+	}
+
+	// The run is not tied to the request, so a stop request can still return partial results
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+	a.mu.Lock()
+	if a.cancel != nil {
+		a.mu.Unlock()
+		http.Error(w, "agent is busy with another run", http.StatusConflict)
+		return
+	}
+	a.cancel = cancel
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.cancel = nil
+		a.mu.Unlock()
+	}()
+
+	slog.Info("Starting shard", "agent", req.Agent+1, "of", req.Agents, "operation", req.Config.OperationType,
+		"keys", len(req.Config.Keys), "concurrency", req.Config.Concurrency, "duration", req.Config.Duration)
+	results, stats, err := a.run(ctx, req.Config)
+	resp := AgentRunResponse{Results: results}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if stats != nil && stats.Client != nil {
+		resp.ClientWarnings = stats.Client.Warnings
+	}
+	slog.Info("Shard finished", "results", len(results), "error", resp.Error)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("Failed to send shard results", "error", err)
+	}
+}
+
+// ParseAgentList parses a comma-separated list of agent addresses such as "host1,host2:7001"
+// into base URLs. Addresses without a port use DefaultAgentPort.
+func ParseAgentList(list string) ([]string, error) {
+	var urls []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "://") {
+			urls = append(urls, strings.TrimSuffix(item, "/"))
+			continue
+		}
+		if !strings.Contains(item, ":") || strings.HasSuffix(item, "]") {
+			item += ":" + DefaultAgentPort
+		}
+		urls = append(urls, "http://"+item)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("agent list %q is empty", list)
+	}
+	return urls, nil
+}
+
+// shardKeys splits keys, or their weights, round-robin into n shards so each agent reads
+// a distinct subset.
+func shardKeys[T any](keys []T, n int) [][]T {
+	shards := make([][]T, n)
+	for i, key := range keys {
+		shards[i%n] = append(shards[i%n], key)
+	}
+	return shards
+}
+
+// hostSettings returns the flags of the settings in c that read or write files, sample
+// interfaces or open listeners on the host running it. Agents refuse runs that set any.
+func hostSettings(c *Config) []string {
+	var flags []string
+	for _, s := range []struct {
+		flag string
+		set  bool
+	}{
+		{"-manifest", c.ManifestPath != ""},
+		{"-genmf", c.GenerateManifest},
+		{"-o", c.OutputFile != ""},
+		{"-summary", c.SummaryFile != ""},
+		{"-checkpoint", c.Checkpoint != ""},
+		{"-trace", c.TraceFile != ""},
+		{"-slow-log", c.SlowLog != ""},
+		{"-hdr-log", c.HDRLog != ""},
+		{"-timeseries", c.TimeSeries != ""},
+		{"-html-report", c.HTMLReport != ""},
+		{"-nic", c.NICInterface != ""},
+		{"-pprof-addr", c.PprofAddr != ""},
+		{"-control", c.Control != ""},
+	} {
+		if s.set {
+			flags = append(flags, s.flag)
+		}
+	}
+	return flags
+}
+
+// clearHostSettings clears the settings hostSettings reports.
+func clearHostSettings(c *Config) {
+	c.ManifestPath = ""
+	c.GenerateManifest = false
+	c.OutputFile = ""
+	c.SummaryFile = ""
+	c.Checkpoint = ""
+	c.TraceFile = ""
+	c.SlowLog = ""
+	c.HDRLog = ""
+	c.TimeSeries = ""
+	c.HTMLReport = ""
+	c.NICInterface = ""
+	c.PprofAddr = ""
+	c.Control = ""
+}
+
+// agentConfigs returns the per-agent copies of cfg. Read manifests are split into shards and
+// fixed file counts divided between the agents; settings that refer to this host are cleared
+// because the coordinator writes all outputs, and the agent token is sent as a header only.
+func agentConfigs(cfg *Config, keys []string, n int) []*Config {
+	shards := shardKeys(keys, n)
+	configs := make([]*Config, n)
+	for i := range configs {
+		c := *cfg
+		c.Agents = ""
+		c.AgentToken = ""
+		c.Keys = shards[i]
+		c.Shard = ""
+		clearHostSettings(&c)
+		c.Quiet = true
+		if c.FileCount > 0 {
+			c.FileCount = cfg.FileCount / n
+			if i < cfg.FileCount%n {
+				c.FileCount++
+			}
+		}
+		configs[i] = &c
+	}
+	return configs
+}
+
+// RunDistributed runs the stress test on the agents in cfg.Agents and combines their results
+// into one Stats, as if a single client had generated the load. Every agent runs
+// cfg.Concurrency workers. Agents that fail are reported, the results of the others are kept.
+func RunDistributed(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
+	agents, err := ParseAgentList(cfg.Agents)
+	if err != nil {
+		return nil, nil, err
+	}
+	var keys []string
+	var weights []float64
+	var expected []ManifestEntry
+	if cfg.OperationType == "read" || cfg.OperationType == "mixed" || cfg.OperationType == "head" || cfg.OperationType == "revalidate" || cfg.OperationType == "copy" || cfg.OperationType == "tagging" {
+		entries, err := LoadManifestEntries(cfg.ManifestPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load manifest for %s mode: %w", cfg.OperationType, err)
+		}
+		// A sharded coordinator splits its own slice between its agents
+		if entries, err = manifestShard(entries, cfg.Shard); err != nil {
+			return nil, nil, err
+		}
+		keys, weights = splitManifest(entries)
+		expected = manifestExpectations(entries)
+		if len(keys) < len(agents) {
+			return nil, nil, fmt.Errorf("manifest has %d keys, fewer than the %d agents", len(keys), len(agents))
+		}
+	}
+	var warmup time.Duration
+	if cfg.Warmup != "" {
+		warmup, _ = time.ParseDuration(cfg.Warmup) // Already validated in Config.Validate
+	}
+	configs := agentConfigs(cfg, keys, len(agents))
+	if weights != nil {
+		// Every agent picks from its shard by weight, so hot keys stay hot overall
+		for i, shard := range shardKeys(weights, len(agents)) {
+			configs[i].KeyWeights = shard
+		}
+	}
+	if expected != nil {
+		for i, shard := range shardKeys(expected, len(agents)) {
+			configs[i].KeyExpectations = shard
+		}
+	}
+	client := &http.Client{}
+
+	// Tell the agents to stop early if we are interrupted; they then return partial results
+	stopAgents := context.AfterFunc(ctx, func() {
+		for _, agent := range agents {
+			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			req, _ := http.NewRequestWithContext(stopCtx, http.MethodPost, agent+agentStopPath, nil)
+			req.Header.Set("Authorization", "Bearer "+cfg.AgentToken)
+			if resp, err := client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+			cancel()
+		}
+	})
+	defer stopAgents()
+
+	slog.Info("Starting distributed run", "agents", len(agents), "concurrencyPerAgent", cfg.Concurrency)
+	startTime := time.Now()
+	responses := make([]AgentRunResponse, len(agents))
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := runAgent(context.WithoutCancel(ctx), client, agent, cfg.AgentToken, AgentRunRequest{Config: configs[i], Agent: i, Agents: len(agents)})
+			if err != nil {
+				resp.Error = err.Error()
+			}
+			responses[i] = resp
+		}()
+	}
+	wg.Wait()
+	endTime := time.Now()
+
+	var allResults []Result
+	var errs []error
+	for i, resp := range responses {
+		if resp.Error != "" {
+			slog.Error("Agent failed", "agent", agents[i], "error", resp.Error)
+			errs = append(errs, fmt.Errorf("agent %s: %s", agents[i], resp.Error))
+		}
+		for _, warning := range resp.ClientWarnings {
+			slog.Warn("Possible client-side bottleneck on agent", "agent", agents[i], "reason", warning)
+		}
+		slog.Info("Collected agent results", "agent", agents[i], "count", len(resp.Results))
+		allResults = append(allResults, resp.Results...)
+	}
+	if len(errs) == len(agents) {
+		return nil, nil, fmt.Errorf("all agents failed: %w", errors.Join(errs...))
+	}
+
+	// Manifests generated by the agents' PUTs are written here, in one place
+	if cfg.OperationType == "write" && cfg.GenerateManifest {
+		if err := writeResultManifest(cfg.ManifestPath, allResults); err != nil {
+			slog.Error("Failed to write manifest", "error", err)
+		}
+	}
+
+	// The agents log their slow requests as they happen, the slow log is written here
+	if cfg.SlowLog != "" {
+		slow, err := newSlowLog(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		slow.log = false
+		for _, res := range allResults {
+			slow.add(res)
+		}
+		if err := slow.close(); err != nil {
+			slog.Error("Failed to close slow log", "error", err, "file", cfg.SlowLog)
+		}
+	}
+
+	stats := NewStats()
+	stats.Concurrency = cfg.Concurrency * len(agents)
+	stats.PrefixDepth = cfg.PrefixDepth
+	stats.OutlierCount = cfg.OutlierCount
+	stats.TrackWrites = cfg.TrackWrites
+	stats.IPFamily = cfg.IPFamily
+	stats.HTTPVersion = cfg.HTTPVersion
+	stats.RunID = cfg.RunID
+	stats.ThinkTime = cfg.ThinkTime
+	stats.SLOs = cfg.SLOs
+	stats.Agents = len(agents)
+	if len(cfg.Stages) > 0 {
+		stats.SetStages(cfg.Stages, startTime)
+	}
+	for _, res := range allResults {
+		if res.Warmup {
+			continue
+		}
+		stats.AddResult(res)
+	}
+	measureStart := startTime.Add(warmup)
+	if measureStart.After(endTime) {
+		measureStart = endTime
+	}
+	stats.Calculate(measureStart, endTime)
+	return allResults, stats, nil
+}
+
+// runAgent sends a shard to one agent and waits for its results.
+func runAgent(ctx context.Context, client *http.Client, agent, token string, runReq AgentRunRequest) (AgentRunResponse, error) {
+	var resp AgentRunResponse
+	body, err := json.Marshal(runReq)
+	if err != nil {
+		return resp, fmt.Errorf("failed to encode run request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent+agentRunPath, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return resp, fmt.Errorf("failed to reach agent: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return resp, fmt.Errorf("agent returned %s", httpResp.Status)
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("failed to decode agent results: %w", err)
+	}
+	return resp, nil
+}
+
+// writeResultManifest writes the keys of successful PUTs to a manifest at path.
+func writeResultManifest(path string, results []Result) error {
+	mw, err := NewManifestWriter(path)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Operation == "PUT" && r.Error == "" {
+			if err := mw.AddEntry(ManifestEntry{Key: r.ObjectKey, VersionID: r.VersionID, Size: r.BytesUploaded, ETag: r.ETag}); err != nil {
+				mw.Close()
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,165 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAgentList(t *testing.T) {
+	urls, err := ParseAgentList("host1, host2:7001,http://host3:8000/,[::1]")
+	if err != nil {
+		t.Fatalf("ParseAgentList failed: %v", err)
+	}
+	expected := []string{"http://host1:7000", "http://host2:7001", "http://host3:8000", "http://[::1]:7000"}
+	if strings.Join(urls, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+	if _, err := ParseAgentList(" , "); err == nil {
+		t.Error("Expected error for empty agent list")
+	}
+}
+
+func TestAgentConfigs(t *testing.T) {
+	cfg := &Config{ManifestPath: "keys.txt", FileCount: 5, GenerateManifest: true, TraceFile: "trace.jsonl", OutputFile: "out.csv", Control: "127.0.0.1:7070", Agents: "a,b", AgentToken: "secret"}
+	configs := agentConfigs(cfg, []string{"k0", "k1", "k2"}, 2)
+
+	if got := strings.Join(configs[0].Keys, ","); got != "k0,k2" {
+		t.Errorf("Expected first shard k0,k2, got %s", got)
+	}
+	if got := strings.Join(configs[1].Keys, ","); got != "k1" {
+		t.Errorf("Expected second shard k1, got %s", got)
+	}
+	if configs[0].FileCount != 3 || configs[1].FileCount != 2 {
+		t.Errorf("Expected file counts 3 and 2, got %d and %d", configs[0].FileCount, configs[1].FileCount)
+	}
+	for _, c := range configs {
+		if c.Agents != "" || c.AgentToken != "" || len(hostSettings(c)) > 0 {
+			t.Errorf("Agent config still refers to coordinator settings: %+v", c)
+		}
+	}
+	if cfg.FileCount != 5 || cfg.Keys != nil {
+		t.Error("agentConfigs modified the coordinator config")
+	}
+}
+
+func TestRunDistributed(t *testing.T) {
+	var servers []string
+	for i := 0; i < 2; i++ {
+		agent := NewAgent("secret")
+		agent.run = func(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
+			var results []Result
+			for _, key := range cfg.Keys {
+				results = append(results, Result{Timestamp: time.Now(), Operation: "GET", ObjectKey: key, TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 100})
+			}
+			return results, nil, nil
+		}
+		server := httptest.NewServer(agent)
+		defer server.Close()
+		servers = append(servers, server.URL)
+	}
+
+	manifest := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(manifest, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{OperationType: "read", Concurrency: 4, Duration: "1s", ManifestPath: manifest, Agents: strings.Join(servers, ","), AgentToken: "secret"}
+	results, stats, err := RunDistributed(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("RunDistributed failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results from the agents, got %d", len(results))
+	}
+	if stats.TotalGets != 3 || stats.TotalBytesDown != 300 {
+		t.Errorf("Expected 3 GETs and 300 bytes, got %d and %d", stats.TotalGets, stats.TotalBytesDown)
+	}
+	if stats.Concurrency != 8 || stats.Agents != 2 {
+		t.Errorf("Expected 8 workers on 2 agents, got %d on %d", stats.Concurrency, stats.Agents)
+	}
+}
+
+func TestAgentRejectsRuns(t *testing.T) {
+	agent := NewAgent("secret")
+	agent.run = func(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
+		t.Error("Agent ran a rejected request")
+		return nil, nil, nil
+	}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	post := func(token string, cfg *Config) int {
+		body, _ := json.Marshal(AgentRunRequest{Config: cfg, Agents: 1})
+		req, _ := http.NewRequest(http.MethodPost, server.URL+agentRunPath, bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("", &Config{}); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", code)
+	}
+	if code := post("wrong", &Config{}); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", code)
+	}
+	if code := post("secret", &Config{OutputFile: "/etc/passwd"}); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a run writing a file on the agent, got %d", code)
+	}
+	if code := post("secret", &Config{PprofAddr: "127.0.0.1:6060"}); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a run opening a listener on the agent, got %d", code)
+	}
+
+	resp, err := http.Get(server.URL + agentHealthPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the health check to work without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestValidateAgents(t *testing.T) {
+	base := Config{Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "read", Agents: "host1,https://host2:7000", AgentToken: "secret"}
+
+	cfg := base
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	cfg.Agents = "https://host1,https://host2"
+	cfg.SecretKey = "key"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected credentials to be allowed over https, got %v", err)
+	}
+	cfg = base
+	cfg.SecretKey = "key"
+	cfg.AgentInsecure = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected -agent-insecure to allow plain HTTP agents, got %v", err)
+	}
+	for name, mutate := range map[string]func(*Config){
+		"no token":        func(c *Config) { c.AgentToken = "" },
+		"secret key":      func(c *Config) { c.SecretKey = "key" },
+		"sse-c key":       func(c *Config) { c.SSECustomerKey = "key" },
+		"gcs credentials": func(c *Config) { c.GCSCredentials = "{}" },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
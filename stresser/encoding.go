@@ -0,0 +1,127 @@
+package stresser
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encodings of GET responses (Config.AcceptEncoding).
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingZstd    = "zstd"
+)
+
+// contentDecoders maps a Content-Encoding to a reader decoding it. GET bodies with one of
+// these encodings are decoded; adding an encoding only requires registering it here.
+var contentDecoders = map[string]func(io.Reader) (io.ReadCloser, error){
+	EncodingGzip:    func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	EncodingDeflate: func(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) }, // HTTP deflate is zlib-wrapped
+	EncodingZstd:    newZstdReader,
+}
+
+// newZstdReader decodes a zstd stream synchronously, without the decoder's background
+// goroutines, which would compete with the workers for CPU.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// validateAcceptEncoding checks -accept-encoding and normalizes it to the header value sent.
+func (c *Config) validateAcceptEncoding() error {
+	if c.AcceptEncoding == "" {
+		return nil
+	}
+	var encodings []string
+	for _, e := range strings.Split(c.AcceptEncoding, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		switch {
+		case e == "":
+			continue
+		case contentDecoders[e] == nil:
+			return fmt.Errorf("invalid accept encoding (-accept-encoding) %q: must be %q, %q or %q", e, EncodingGzip, EncodingDeflate, EncodingZstd)
+		}
+		if !slices.Contains(encodings, e) {
+			encodings = append(encodings, e)
+		}
+	}
+	if len(encodings) == 0 {
+		return fmt.Errorf("accept encoding (-accept-encoding) %q names no encoding", c.AcceptEncoding)
+	}
+	if c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support -accept-encoding")
+	}
+	c.AcceptEncoding = strings.Join(encodings, ", ")
+	return nil
+}
+
+// withAcceptEncoding returns an SDK API option that asks for compressed GetObject responses.
+// The SDK sends "Accept-Encoding: identity" to S3 early in the finalize step, so the header
+// is replaced right after that, still before the request is signed. Setting the header also
+// keeps the HTTP transport from decompressing the body transparently, so the GET reads the
+// bytes on the wire and decodes them itself.
+func withAcceptEncoding(value string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		mw := middleware.FinalizeMiddlewareFunc("StresserAcceptEncoding",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if req, ok := in.Request.(*smithyhttp.Request); ok && awsmiddleware.GetOperationName(ctx) == "GetObject" {
+					req.Header.Set("Accept-Encoding", value)
+				}
+				return next.HandleFinalize(ctx, in)
+			})
+		if _, ok := stack.Finalize.Get("DisableAcceptEncodingGzip"); ok {
+			return stack.Finalize.Insert(mw, "DisableAcceptEncodingGzip", middleware.After)
+		}
+		return stack.Finalize.Add(mw, middleware.Before)
+	}
+}
+
+// wireCounter counts the bytes read from a response body before they are decoded.
+type wireCounter struct {
+	r io.Reader
+	n int64
+}
+
+func (w *wireCounter) Read(p []byte) (int, error) {
+	n, err := w.r.Read(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// decodedBody returns a reader of the decoded body for a known Content-Encoding, and the
+// counter of the bytes read from the wire; nil for bodies that are not encoded. Closing the
+// reader releases the decoder, not body.
+func decodedBody(body io.Reader, contentEncoding string) (io.ReadCloser, *wireCounter, error) {
+	decode := contentDecoders[strings.ToLower(strings.TrimSpace(contentEncoding))]
+	if decode == nil {
+		return io.NopCloser(body), nil, nil
+	}
+	wire := &wireCounter{r: body}
+	decoded, err := decode(wire)
+	if err != nil {
+		return nil, wire, fmt.Errorf("failed to decode %s body: %w", contentEncoding, err)
+	}
+	return decoded, wire, nil
+}
+
+// objectBytes returns the size of the object a GET read: the decoded size of a compressed
+// body, otherwise the bytes downloaded.
+func (r Result) objectBytes() int64 {
+	if r.BytesDecoded > 0 {
+		return r.BytesDecoded
+	}
+	return r.BytesDownloaded
+}
@@ -0,0 +1,138 @@
+package stresser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestValidateAcceptEncoding(t *testing.T) {
+	for value, want := range map[string]string{
+		"":               "",
+		"gzip":           "gzip",
+		" GZIP, deflate": "gzip, deflate",
+		"gzip,gzip,":     "gzip",
+		"zstd, gzip":     "zstd, gzip",
+	} {
+		c := &Config{AcceptEncoding: value}
+		if err := c.validateAcceptEncoding(); err != nil || c.AcceptEncoding != want {
+			t.Errorf("validateAcceptEncoding(%q) = %q, %v, want %q", value, c.AcceptEncoding, err, want)
+		}
+	}
+	for _, value := range []string{"br", "gzip,br", ","} {
+		if err := (&Config{AcceptEncoding: value}).validateAcceptEncoding(); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+	if err := (&Config{AcceptEncoding: "gzip", Backend: BackendGCS}).validateAcceptEncoding(); err == nil {
+		t.Error("Expected an error for the gcs backend")
+	}
+}
+
+// gzipServer returns object bodies gzip-compressed to clients that accept gzip.
+func gzipServer(t *testing.T, object []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), EncodingGzip) {
+			w.Write(object)
+			return
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(object)
+		zw.Close()
+		w.Header().Set("Content-Encoding", EncodingGzip)
+		w.Write(buf.Bytes())
+	}))
+}
+
+func TestAcceptEncodingGet(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	object := bytes.Repeat([]byte("compressible "), 10000)
+	server := gzipServer(t, object)
+	defer server.Close()
+
+	for _, encoding := range []string{"", "gzip, deflate"} {
+		cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", RunID: "test", AcceptEncoding: encoding}
+		client, err := NewS3Client(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("NewS3Client failed: %v", err)
+		}
+		get := performGetOperation(context.Background(), client, cfg.Bucket, "a/key.dat", false)
+		if get.Error != "" {
+			t.Fatalf("GET failed: %s", get.Error)
+		}
+		if encoding == "" {
+			if get.BytesDownloaded != int64(len(object)) || get.BytesDecoded != 0 {
+				t.Errorf("Expected %d uncompressed bytes, got %d (decoded %d)", len(object), get.BytesDownloaded, get.BytesDecoded)
+			}
+			continue
+		}
+		if get.BytesDecoded != int64(len(object)) || get.BytesDownloaded <= 0 || get.BytesDownloaded >= get.BytesDecoded {
+			t.Errorf("Expected %d decoded bytes from fewer on the wire, got %d from %d", len(object), get.BytesDecoded, get.BytesDownloaded)
+		}
+		checkExpected(&get, ManifestEntry{Key: "a/key.dat", Size: int64(len(object))})
+		if get.Integrity == IntegrityCorrupt {
+			t.Errorf("Expected the decoded size to match the manifest: %s", get.Error)
+		}
+	}
+}
+
+func TestDecodedBodyError(t *testing.T) {
+	client := &memS3Client{bodies: map[string][]byte{"a": []byte("not gzip")}}
+	a := startGet(context.Background(), client, "bucket", "a")
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	a.resp.ContentEncoding = aws.String(EncodingGzip)
+	if result := a.finish(time.Now(), false); result.Error == "" {
+		t.Error("Expected an error for a body that is not gzip-encoded")
+	}
+}
+
+func TestDecodedBodyZstd(t *testing.T) {
+	object := bytes.Repeat([]byte("compressible "), 10000)
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &memS3Client{bodies: map[string][]byte{"a": enc.EncodeAll(object, nil)}}
+	enc.Close()
+	a := startGet(context.Background(), client, "bucket", "a")
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	a.resp.ContentEncoding = aws.String(EncodingZstd)
+	result := a.finish(time.Now(), false)
+	if result.Error != "" {
+		t.Fatalf("GET failed: %s", result.Error)
+	}
+	if result.BytesDecoded != int64(len(object)) || result.BytesDownloaded != int64(len(client.bodies["a"])) {
+		t.Errorf("Expected %d decoded bytes from %d on the wire, got %d from %d", len(object), len(client.bodies["a"]), result.BytesDecoded, result.BytesDownloaded)
+	}
+}
+
+func TestDecodedStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 1 << 20, BytesDecoded: 4 << 20})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 1 << 20})
+	stats.Calculate(now, now.Add(time.Second))
+
+	sum := stats.Summary()
+	if sum.Get.Bytes != 2<<20 || sum.Get.DecodedBytes != 5<<20 || sum.Get.DecodedThroughputMiB != 5 {
+		t.Errorf("Expected 2 MiB on the wire and 5 MiB decoded, got %+v", sum.Get)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Encoded Bodies: 1") || !strings.Contains(buf.String(), "2.50x") {
+		t.Errorf("Summary lacks the decoded bytes:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,268 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Ways of spreading operations over multiple endpoints (-endpoint-select). Both honor the
+// endpoint weights.
+const (
+	EndpointSelectRoundRobin = "round-robin" // Endpoints take turns, a weight 2 endpoint getting two turns per cycle
+	EndpointSelectRandom     = "random"      // Each operation goes to a random endpoint, chosen by weight
+)
+
+// maxEndpointWeight bounds the weight of one endpoint, which keeps the round-robin cycle short.
+const maxEndpointWeight = 1000
+
+// ParseEndpoints parses a comma-separated endpoint list as given to -endpoints.
+func ParseEndpoints(spec string) []string {
+	var endpoints []string
+	for _, e := range strings.Split(spec, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// parseEndpoint splits an endpoint entry, "url" or "url=weight", into its URL and weight.
+// The weight defaults to 1.
+func parseEndpoint(entry string) (string, int, error) {
+	endpoint, weight := entry, 1
+	if i := strings.LastIndex(entry, "="); i >= 0 {
+		w, err := strconv.Atoi(entry[i+1:])
+		if err != nil || w < 1 || w > maxEndpointWeight {
+			return "", 0, fmt.Errorf("invalid weight in endpoint %q: must be between 1 and %d", entry, maxEndpointWeight)
+		}
+		endpoint, weight = entry[:i], w
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", 0, fmt.Errorf("invalid endpoint %q: must be an http or https URL", endpoint)
+	}
+	return endpoint, weight, nil
+}
+
+// validateEndpoints checks the endpoint list of -endpoints or the YAML endpoints, which
+// replaces Endpoint. Called from Validate.
+func (c *Config) validateEndpoints() error {
+	if c.EndpointSpec != "" {
+		c.Endpoints = ParseEndpoints(c.EndpointSpec)
+	}
+	if len(c.Endpoints) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, entry := range c.Endpoints {
+		endpoint, _, err := parseEndpoint(entry)
+		if err != nil {
+			return fmt.Errorf("%w (-endpoints)", err)
+		}
+		if seen[endpoint] {
+			return fmt.Errorf("endpoint %q is listed twice (-endpoints)", endpoint)
+		}
+		seen[endpoint] = true
+	}
+	c.Endpoint, _, _ = parseEndpoint(c.Endpoints[0]) // Where a single endpoint is needed, e.g. in log messages
+	switch c.EndpointSelection {
+	case "":
+		c.EndpointSelection = EndpointSelectRoundRobin
+	case EndpointSelectRoundRobin, EndpointSelectRandom:
+	default:
+		return fmt.Errorf("invalid endpoint selection (-endpoint-select): %s. Must be 'round-robin' or 'random'", c.EndpointSelection)
+	}
+	if len(c.Endpoints) > 1 && c.SecondaryEndpoint != "" {
+		return fmt.Errorf("multiple endpoints (-endpoints) cannot be combined with failover (-secondary-endpoint)")
+	}
+	if len(c.Endpoints) > 1 && c.BandwidthLimit > 0 {
+		return fmt.Errorf("multiple endpoints (-endpoints) cannot be combined with -bandwidth-limit, each endpoint has its own connections")
+	}
+	return nil
+}
+
+// endpointTarget is an endpoint with the client that sends requests to it.
+type endpointTarget struct {
+	url    string
+	weight int
+	client S3ClientAPI
+	sdk    *s3.Client // The SDK client under client, nil for the gcs backend and failover
+}
+
+// endpointPicker chooses the endpoint of each operation. It is shared by all workers.
+type endpointPicker struct {
+	targets []*endpointTarget
+	random  bool
+	cycle   []int         // Round-robin order, each target appearing weight times
+	total   int           // Sum of the weights
+	next    atomic.Uint64 // Round-robin position
+}
+
+// singleEndpoint returns a picker that sends everything through client.
+func singleEndpoint(client S3ClientAPI) *endpointPicker {
+	return newEndpointPicker([]*endpointTarget{{weight: 1, client: client}}, EndpointSelectRoundRobin)
+}
+
+// newEndpointPicker returns a picker over targets. The round-robin cycle interleaves the
+// targets by weight, so weights 2 and 1 give a, b, a rather than a, a, b.
+func newEndpointPicker(targets []*endpointTarget, selection string) *endpointPicker {
+	p := &endpointPicker{targets: targets, random: selection == EndpointSelectRandom}
+	for _, t := range targets {
+		p.total += t.weight
+	}
+	// Smooth weighted round-robin: each turn goes to the target with the most accumulated credit
+	credit := make([]int, len(targets))
+	for range p.total {
+		best := 0
+		for i, t := range targets {
+			credit[i] += t.weight
+			if credit[i] > credit[best] {
+				best = i
+			}
+		}
+		credit[best] -= p.total
+		p.cycle = append(p.cycle, best)
+	}
+	return p
+}
+
+// multiple reports whether operations are spread over more than one endpoint.
+func (p *endpointPicker) multiple() bool {
+	return len(p.targets) > 1
+}
+
+// pick returns the endpoint for the next operation.
+func (p *endpointPicker) pick(r *rand.Rand) *endpointTarget {
+	if len(p.targets) == 1 {
+		return p.targets[0]
+	}
+	if p.random {
+		n := r.Intn(p.total)
+		for _, t := range p.targets {
+			if n < t.weight {
+				return t
+			}
+			n -= t.weight
+		}
+	}
+	return p.targets[p.cycle[(p.next.Add(1)-1)%uint64(len(p.cycle))]]
+}
+
+// newEndpointTargets creates a client for each endpoint of cfg.Endpoints, running the
+// pre-flight checks against every one of them.
+func newEndpointTargets(ctx context.Context, cfg *Config) ([]*endpointTarget, error) {
+	var targets []*endpointTarget
+	for _, entry := range cfg.Endpoints {
+		endpoint, weight, _ := parseEndpoint(entry) // Checked by Validate
+		endpointCfg := *cfg
+		endpointCfg.Endpoint = endpoint
+		client, err := NewS3Client(ctx, &endpointCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client for %s: %w", endpoint, err)
+		}
+		if cfg.Preflight || cfg.CreateBucket {
+			if err := runPreflightBuckets(ctx, client, &endpointCfg); err != nil {
+				return nil, fmt.Errorf("endpoint %s: %w", endpoint, err)
+			}
+		}
+		targets = append(targets, &endpointTarget{url: endpoint, weight: weight, client: workloadClient(client, cfg), sdk: client})
+		slog.Info("S3 client configured", "endpoint", endpoint, "weight", weight, "bucket", cfg.Bucket)
+	}
+	return targets, nil
+}
+
+// EndpointStats aggregates the results of the requests sent to one endpoint.
+type EndpointStats struct {
+	Endpoint      string  `json:"endpoint" yaml:"endpoint"`
+	Requests      int64   `json:"requests" yaml:"requests"`
+	Errors        int64   `json:"errors" yaml:"errors"`
+	Bytes         int64   `json:"bytes" yaml:"bytes"` // Bytes transferred in either direction by successful requests
+	ThroughputMiB float64 `json:"throughputMiBps" yaml:"throughputMiBps"`
+	P50TTFBMs     float64 `json:"p50TtfbMs" yaml:"p50TtfbMs"`
+	P50TTLBMs     float64 `json:"p50TtlbMs" yaml:"p50TtlbMs"`
+	P99TTLBMs     float64 `json:"p99TtlbMs" yaml:"p99TtlbMs"`
+	ttfbs         *Histogram
+	ttlbs         *Histogram
+}
+
+// addEndpointResult records r against its endpoint. Called from AddResult; results of
+// single-endpoint runs carry no endpoint and are skipped.
+func (s *Stats) addEndpointResult(r Result) {
+	if r.Endpoint == "" {
+		return
+	}
+	if s.endpoints == nil {
+		s.endpoints = make(map[string]*EndpointStats)
+	}
+	es, ok := s.endpoints[r.Endpoint]
+	if !ok {
+		es = &EndpointStats{Endpoint: r.Endpoint, ttfbs: NewHistogram(), ttlbs: NewHistogram()}
+		s.endpoints[r.Endpoint] = es
+	}
+	es.Requests++
+	if r.Error != "" {
+		es.Errors++
+		return
+	}
+	es.Bytes += r.BytesDownloaded + r.BytesUploaded
+	if r.TTFB >= 0 {
+		es.ttfbs.Record(r.TTFB)
+	}
+	es.ttlbs.Record(r.TTLB)
+}
+
+// calculateEndpointStats computes per-endpoint throughput and latency. Called from Calculate.
+func (s *Stats) calculateEndpointStats() {
+	secs := s.actualDuration.Seconds()
+	for _, es := range s.endpoints {
+		if secs > 0 {
+			es.ThroughputMiB = float64(es.Bytes) / (1024 * 1024) / secs
+		}
+		if es.ttfbs.Count() > 0 {
+			es.P50TTFBMs = ms(es.ttfbs.Percentile(50))
+		}
+		if es.ttlbs.Count() > 0 {
+			es.P50TTLBMs = ms(es.ttlbs.Percentile(50))
+			es.P99TTLBMs = ms(es.ttlbs.Percentile(99))
+		}
+	}
+}
+
+// EndpointStats returns the per-endpoint aggregates ordered by endpoint, nil for
+// single-endpoint runs.
+func (s *Stats) EndpointStats() []*EndpointStats {
+	if len(s.endpoints) == 0 {
+		return nil
+	}
+	list := make([]*EndpointStats, 0, len(s.endpoints))
+	for _, es := range s.endpoints {
+		list = append(list, es)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Endpoint < list[j].Endpoint })
+	return list
+}
+
+// printEndpointSummary prints per-endpoint figures as part of PrintSummary, so a gateway
+// that is slower than the rest of the cluster stands out.
+func (s *Stats) printEndpointSummary(w io.Writer) {
+	if len(s.endpoints) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nEndpoints (%d):\n", len(s.endpoints))
+	fmt.Fprintf(w, "  Requests | Errors |  MiB/s  | P50 TTFB | P50 TTLB | P99 TTLB | Endpoint\n")
+	fmt.Fprintf(w, "  ---------|--------|---------|----------|----------|----------|----------\n")
+	for _, es := range s.EndpointStats() {
+		fmt.Fprintf(w, "  %8d | %6d | %7.2f | %8.2f | %8.2f | %8.2f | %s\n",
+			es.Requests, es.Errors, es.ThroughputMiB, es.P50TTFBMs, es.P50TTLBMs, es.P99TTLBMs, es.Endpoint)
+	}
+}
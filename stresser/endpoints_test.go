@@ -0,0 +1,106 @@
+package stresser
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	endpoint, weight, err := parseEndpoint("http://gw1:9000=3")
+	if err != nil || endpoint != "http://gw1:9000" || weight != 3 {
+		t.Errorf("Unexpected endpoint %q weight %d (%v)", endpoint, weight, err)
+	}
+	endpoint, weight, err = parseEndpoint("https://gw2")
+	if err != nil || endpoint != "https://gw2" || weight != 1 {
+		t.Errorf("Unexpected endpoint %q weight %d (%v)", endpoint, weight, err)
+	}
+	for _, entry := range []string{"gw1:9000", "http://gw1=0", "http://gw1=x", "http://gw1=1001", "ftp://gw1"} {
+		if _, _, err := parseEndpoint(entry); err == nil {
+			t.Errorf("Expected an error for %q", entry)
+		}
+	}
+}
+
+func TestEndpointPicker(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := &endpointTarget{url: "a", weight: 2}
+	b := &endpointTarget{url: "b", weight: 1}
+
+	p := newEndpointPicker([]*endpointTarget{a, b}, EndpointSelectRoundRobin)
+	var got []string
+	for range 6 {
+		got = append(got, p.pick(r).url)
+	}
+	if strings.Join(got, ",") != "a,b,a,a,b,a" {
+		t.Errorf("Expected interleaved weighted turns, got %v", got)
+	}
+
+	p = newEndpointPicker([]*endpointTarget{a, b}, EndpointSelectRandom)
+	counts := make(map[string]int)
+	for range 3000 {
+		counts[p.pick(r).url]++
+	}
+	if counts["a"] < 1800 || counts["a"] > 2200 {
+		t.Errorf("Expected about two thirds of the picks on a, got %v", counts)
+	}
+
+	if single := singleEndpoint(&stubS3Client{}); single.multiple() || single.pick(r).client == nil {
+		t.Error("Expected a single endpoint picker to always return its client")
+	}
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	base := Config{Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "read"}
+
+	cfg := base
+	cfg.Endpoint = "http://old:9000"
+	cfg.Endpoints = []string{"http://yaml:9000"}
+	cfg.EndpointSpec = "http://gw1:9000=2, http://gw2:9000"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(cfg.Endpoints) != 2 || cfg.Endpoint != "http://gw1:9000" || cfg.EndpointSelection != EndpointSelectRoundRobin {
+		t.Errorf("Unexpected endpoints %v, endpoint %q, selection %q", cfg.Endpoints, cfg.Endpoint, cfg.EndpointSelection)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"no endpoint":        func(c *Config) {},
+		"duplicate":          func(c *Config) { c.EndpointSpec = "http://a,http://a=2" },
+		"invalid selection":  func(c *Config) { c.EndpointSpec = "http://a,http://b"; c.EndpointSelection = "least-loaded" },
+		"with failover":      func(c *Config) { c.EndpointSpec = "http://a,http://b"; c.SecondaryEndpoint = "http://c" },
+		"with bandwidth cap": func(c *Config) { c.EndpointSpec = "http://a,http://b"; c.BandwidthLimit = 100 },
+		"gcs backend":        func(c *Config) { c.EndpointSpec = "http://a,http://b"; c.Backend = BackendGCS },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestEndpointStats(t *testing.T) {
+	start := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", Endpoint: "http://gw1", BytesDownloaded: 1024 * 1024, TTFB: time.Millisecond, TTLB: 2 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: start, Operation: "PUT", Endpoint: "http://gw2", BytesUploaded: 1024 * 1024, TTFB: -1, TTLB: 40 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", Endpoint: "http://gw2", Error: "connection refused"})
+	stats.Calculate(start, start.Add(time.Second))
+
+	list := stats.EndpointStats()
+	if len(list) != 2 || list[0].Endpoint != "http://gw1" || list[1].Requests != 2 || list[1].Errors != 1 || list[1].P50TTFBMs != 0 {
+		t.Fatalf("Unexpected endpoint stats %+v", list)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Endpoints (2):") {
+		t.Errorf("Summary missing endpoint section:\n%s", buf.String())
+	}
+	if sum := stats.Summary(); len(sum.Endpoints) != 2 {
+		t.Errorf("Expected 2 endpoints in the summary, got %+v", sum.Endpoints)
+	}
+}
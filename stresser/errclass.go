@@ -0,0 +1,169 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error classes for failures that did not get an HTTP error response.
+const (
+	ErrClassTimeout     = "timeout"
+	ErrClassConnReset   = "connection reset"
+	ErrClassConnRefused = "connection refused"
+	ErrClassDNS         = "dns"
+	ErrClassTLS         = "tls"
+	ErrClassCanceled    = "canceled"
+	ErrClassIntegrity   = "integrity"
+	ErrClassOther       = "other"
+)
+
+var (
+	// errStatusPattern finds the status of the SDK's "https response error StatusCode: 503, ..."
+	// messages; transport failures are reported with StatusCode 0.
+	errStatusPattern = regexp.MustCompile(`StatusCode: (\d+)`)
+	// errCodePatterns find the S3 error code in SDK messages ("HostID: ..., NoSuchKey: ...",
+	// "api error SlowDown: ...") and in raw error bodies of presigned requests.
+	errCodePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`HostID: [^,]*, (?:api error )?([A-Za-z][A-Za-z0-9]*):`),
+		regexp.MustCompile(`<Code>([A-Za-z0-9]+)</Code>`),
+	}
+	// errKeywords map fragments of transport error messages to their class, checked in order.
+	errKeywords = []struct {
+		fragment string
+		class    string
+	}{
+		{ErrCorruptObject.Error(), ErrClassIntegrity},
+		{"context canceled", ErrClassCanceled},
+		{"deadline exceeded", ErrClassTimeout},
+		{"timeout", ErrClassTimeout},
+		{"tls:", ErrClassTLS},
+		{"x509:", ErrClassTLS},
+		{"certificate", ErrClassTLS},
+		{"connection reset", ErrClassConnReset},
+		{"broken pipe", ErrClassConnReset},
+		{"unexpected eof", ErrClassConnReset},
+		{"server closed idle connection", ErrClassConnReset},
+		{"connection refused", ErrClassConnRefused},
+		{"no such host", ErrClassDNS},
+		{"lookup ", ErrClassDNS},
+	}
+)
+
+// ClassifyError returns the category of a Result error message: "<status> <S3 code>" for
+// error responses, such as "404 NoSuchKey" or "503 SlowDown", or one of the ErrClass
+// constants for failures below HTTP. Classification works on the message rather than the
+// error value, so results recorded by agents or read back from files classify the same way.
+func ClassifyError(msg string) string {
+	if m := errStatusPattern.FindStringSubmatch(msg); m != nil {
+		if status, _ := strconv.Atoi(m[1]); status >= 400 {
+			for _, p := range errCodePatterns {
+				if c := p.FindStringSubmatch(msg); c != nil {
+					return m[1] + " " + c[1]
+				}
+			}
+			return m[1] + " " + strings.ReplaceAll(http.StatusText(status), " ", "")
+		}
+	}
+	lower := strings.ToLower(msg)
+	for _, k := range errKeywords {
+		if strings.Contains(lower, strings.ToLower(k.fragment)) {
+			return k.class
+		}
+	}
+	return ErrClassOther
+}
+
+// ErrorClassStats counts the failures of one operation type in one error class.
+type ErrorClassStats struct {
+	Operation string  `json:"operation" yaml:"operation"`
+	Class     string  `json:"class" yaml:"class"`
+	Count     int64   `json:"count" yaml:"count"`
+	PerSec    float64 `json:"perSec" yaml:"perSec"`
+	Percent   float64 `json:"percentOfRequests" yaml:"percentOfRequests"` // Of all requests of the operation type
+}
+
+// addErrorClassResult counts a failed result in its error class. Called from AddResult.
+func (s *Stats) addErrorClassResult(r Result) {
+	if r.Error == "" {
+		return
+	}
+	if s.errorClasses == nil {
+		s.errorClasses = make(map[string]map[string]int64)
+	}
+	classes, ok := s.errorClasses[r.Operation]
+	if !ok {
+		classes = make(map[string]int64)
+		s.errorClasses[r.Operation] = classes
+	}
+	classes[ClassifyError(r.Error)]++
+}
+
+// ErrorClasses returns the error counts per operation type and class, ordered by operation
+// and then by descending count. It returns nil if no request failed.
+func (s *Stats) ErrorClasses() []ErrorClassStats {
+	var list []ErrorClassStats
+	secs := s.actualDuration.Seconds()
+	for op, classes := range s.errorClasses {
+		total := s.operationTotal(op)
+		for class, count := range classes {
+			ec := ErrorClassStats{Operation: op, Class: class, Count: count}
+			if secs > 0 {
+				ec.PerSec = float64(count) / secs
+			}
+			if total > 0 {
+				ec.Percent = 100 * float64(count) / float64(total)
+			}
+			list = append(list, ec)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Operation != list[j].Operation {
+			return list[i].Operation < list[j].Operation
+		}
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Class < list[j].Class
+	})
+	return list
+}
+
+// operationTotal returns the number of requests of an operation type.
+func (s *Stats) operationTotal(op string) int64 {
+	switch op {
+	case "GET":
+		return s.TotalGets
+	case "PUT":
+		return s.TotalPuts
+	case "HEAD":
+		return s.TotalHeads
+	case "LIST":
+		return s.TotalLists
+	case "COPY":
+		return s.TotalCopies
+	case "TAGGING":
+		return s.TotalTaggings
+	case "DELETE":
+		return s.TotalDeletes
+	}
+	return 0
+}
+
+// printErrorClassSummary prints the error breakdown as part of PrintSummary.
+func (s *Stats) printErrorClassSummary(w io.Writer) {
+	classes := s.ErrorClasses()
+	if classes == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nErrors by Class:\n")
+	fmt.Fprintf(w, "  Op   | Class                        |  Count | Errors/s | %% of Op\n")
+	fmt.Fprintf(w, "  -----|------------------------------|--------|----------|--------\n")
+	for _, ec := range classes {
+		fmt.Fprintf(w, "  %-4s | %-28s | %6d | %8.2f | %6.2f%%\n", ec.Operation, ec.Class, ec.Count, ec.PerSec, ec.Percent)
+	}
+}
@@ -0,0 +1,68 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected string
+	}{
+		{"operation error S3: GetObject, https response error StatusCode: 404, RequestID: RID, HostID: , NoSuchKey: ", "404 NoSuchKey"},
+		{"operation error S3: PutObject, https response error StatusCode: 403, RequestID: RID, HostID: h, api error AccessDenied: Access Denied", "403 AccessDenied"},
+		{"https response error StatusCode: 503, RequestID: , presigned GET: 503 Service Unavailable: <Error><Code>SlowDown</Code></Error>", "503 SlowDown"},
+		{"https response error StatusCode: 500, RequestID: , googleapi: Error 500", "500 InternalServerError"},
+		{"operation error S3: GetObject, https response error StatusCode: 0, RequestID: , HostID: , request send failed, Get \"http://s3\": context deadline exceeded", ErrClassTimeout},
+		{"read tcp 10.0.0.1:5000->10.0.0.2:443: read: connection reset by peer", ErrClassConnReset},
+		{"body read error: unexpected EOF", ErrClassConnReset},
+		{"request send failed, Head \"https://s3\": tls: failed to verify certificate: x509: certificate signed by unknown authority", ErrClassTLS},
+		{"dial tcp 127.0.0.1:1: connect: connection refused", ErrClassConnRefused},
+		{"dial tcp: lookup s3.invalid: no such host", ErrClassDNS},
+		{"data integrity violation: sha256 ab, expected cd (10 bytes read)", ErrClassIntegrity},
+		{"something odd", ErrClassOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.msg); got != tt.expected {
+			t.Errorf("ClassifyError(%q) = %q, expected %q", tt.msg, got, tt.expected)
+		}
+	}
+}
+
+func TestErrorClasses(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	notFound := "https response error StatusCode: 404, RequestID: r, HostID: h, NoSuchKey: "
+	for i := 0; i < 6; i++ {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond})
+	}
+	for i := 0; i < 3; i++ {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: -1, TTLB: -1, Error: notFound})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: -1, TTLB: -1, Error: "i/o timeout"})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: -1, Error: "connection reset by peer"})
+	stats.Calculate(now, now.Add(2*time.Second))
+
+	classes := stats.ErrorClasses()
+	if len(classes) != 3 {
+		t.Fatalf("Expected 3 error classes, got %+v", classes)
+	}
+	if c := classes[0]; c.Operation != "GET" || c.Class != "404 NoSuchKey" || c.Count != 3 || c.PerSec != 1.5 || c.Percent != 30 {
+		t.Errorf("Unexpected first class: %+v", c)
+	}
+	if c := classes[2]; c.Operation != "PUT" || c.Class != ErrClassConnReset || c.Percent != 100 {
+		t.Errorf("Unexpected PUT class: %+v", c)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Errors by Class:") || !strings.Contains(buf.String(), "404 NoSuchKey") {
+		t.Errorf("Summary lacks the error breakdown:\n%s", buf.String())
+	}
+	if len(stats.Summary().ErrorsByClass) != 3 {
+		t.Error("Expected the error breakdown in the machine-readable summary")
+	}
+}
@@ -0,0 +1,255 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DefaultFailoverThreshold is the number of consecutive primary failures that trigger a failover.
+const DefaultFailoverThreshold = 3
+
+// FailoverReport describes a switch from the primary to the secondary endpoint.
+type FailoverReport struct {
+	Primary          string
+	Secondary        string
+	FailedOver       bool
+	FirstFailure     time.Time     // Start of the failure streak that triggered the failover
+	SwitchedAt       time.Time     // When requests were redirected to the secondary
+	FirstSuccess     time.Time     // First successful request on the secondary (zero if none)
+	TimeToRecover    time.Duration // FirstSuccess - FirstFailure, 0 if the secondary never succeeded
+	TransitionErrors int64         // Failed requests between FirstFailure and FirstSuccess
+}
+
+// failoverClient sends requests to the primary endpoint until it fails threshold times in
+// a row, then switches all traffic to the secondary and measures how long recovery took.
+// Failover is one-way: a drill ends with traffic on the secondary.
+type failoverClient struct {
+	primary   S3ClientAPI
+	secondary S3ClientAPI
+	threshold int
+
+	mu          sync.Mutex
+	onSecondary bool
+	consecutive int // Consecutive primary failures
+	streakErrs  int64
+	report      FailoverReport
+}
+
+func newFailoverClient(primary, secondary S3ClientAPI, threshold int, primaryURL, secondaryURL string) *failoverClient {
+	return &failoverClient{
+		primary:   primary,
+		secondary: secondary,
+		threshold: threshold,
+		report:    FailoverReport{Primary: primaryURL, Secondary: secondaryURL},
+	}
+}
+
+// pick returns the client for the next request and whether it is the secondary.
+func (c *failoverClient) pick() (S3ClientAPI, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.onSecondary {
+		return c.secondary, true
+	}
+	return c.primary, false
+}
+
+// observe records the outcome of a request sent to the primary or secondary.
+func (c *failoverClient) observe(ctx context.Context, secondary bool, err error) {
+	// Requests aborted because the run ended say nothing about the endpoint
+	if ctx.Err() != nil {
+		return
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if secondary {
+		if c.report.FirstSuccess.IsZero() {
+			if err != nil {
+				c.report.TransitionErrors++
+			} else {
+				c.report.FirstSuccess = now
+				c.report.TimeToRecover = now.Sub(c.report.FirstFailure)
+				slog.Info("First successful request after failover", "endpoint", c.report.Secondary, "timeToRecover", c.report.TimeToRecover)
+			}
+		}
+		return
+	}
+	if c.onSecondary {
+		return // Request was in flight on the primary when we switched
+	}
+
+	if err == nil || !isEndpointFailure(err) {
+		c.consecutive = 0
+		c.streakErrs = 0
+		return
+	}
+	if c.consecutive == 0 {
+		c.report.FirstFailure = now
+	}
+	c.consecutive++
+	c.streakErrs++
+	if c.consecutive >= c.threshold {
+		c.onSecondary = true
+		c.report.FailedOver = true
+		c.report.SwitchedAt = now
+		c.report.TransitionErrors = c.streakErrs
+		slog.Warn("Primary endpoint failing, switching to secondary", "primary", c.report.Primary,
+			"secondary", c.report.Secondary, "consecutiveFailures", c.consecutive, "error", err)
+	}
+}
+
+// isEndpointFailure reports whether err indicates the endpoint itself is unhealthy:
+// a server error or a request that never got an HTTP response. Client errors such
+// as 404 or 403 don't trigger a failover.
+func isEndpointFailure(err error) bool {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() >= 500
+	}
+	return true
+}
+
+// Report returns the failover measurements so far.
+func (c *failoverClient) Report() FailoverReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.report
+}
+
+func (c *failoverClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	client, secondary := c.pick()
+	out, err := client.GetObject(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	client, secondary := c.pick()
+	out, err := client.PutObject(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	client, secondary := c.pick()
+	out, err := client.HeadObject(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	client, secondary := c.pick()
+	out, err := client.ListObjectsV2(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	client, secondary := c.pick()
+	copier, ok := client.(copyClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support CopyObject", client)
+	}
+	out, err := copier.CopyObject(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	client, secondary := c.pick()
+	copier, ok := client.(copyClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support CreateMultipartUpload", client)
+	}
+	out, err := copier.CreateMultipartUpload(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	client, secondary := c.pick()
+	copier, ok := client.(copyClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support UploadPartCopy", client)
+	}
+	out, err := copier.UploadPartCopy(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	client, secondary := c.pick()
+	copier, ok := client.(copyClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support CompleteMultipartUpload", client)
+	}
+	out, err := copier.CompleteMultipartUpload(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	client, secondary := c.pick()
+	copier, ok := client.(copyClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support AbortMultipartUpload", client)
+	}
+	out, err := copier.AbortMultipartUpload(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) GetObjectTagging(ctx context.Context, params *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	client, secondary := c.pick()
+	tagger, ok := client.(taggingClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support GetObjectTagging", client)
+	}
+	out, err := tagger.GetObjectTagging(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+func (c *failoverClient) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	client, secondary := c.pick()
+	deleter, ok := client.(deleteClient)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support DeleteObject", client)
+	}
+	out, err := deleter.DeleteObject(ctx, params, optFns...)
+	c.observe(ctx, secondary, err)
+	return out, err
+}
+
+// printFailoverSummary prints the failover measurements as part of PrintSummary.
+func (s *Stats) printFailoverSummary(w io.Writer) {
+	if s.Failover == nil {
+		return
+	}
+	f := s.Failover
+	fmt.Fprintf(w, "\nFailover (%s -> %s):\n", f.Primary, f.Secondary)
+	if !f.FailedOver {
+		fmt.Fprintf(w, "  Primary stayed healthy, no failover happened.\n")
+		return
+	}
+	fmt.Fprintf(w, "  First failure:  +%s\n", f.FirstFailure.Sub(s.startTime).Round(time.Millisecond))
+	fmt.Fprintf(w, "  Switched:       +%s\n", f.SwitchedAt.Sub(s.startTime).Round(time.Millisecond))
+	if f.FirstSuccess.IsZero() {
+		fmt.Fprintf(w, "  Recovered:      never (no successful request on the secondary)\n")
+	} else {
+		fmt.Fprintf(w, "  Recovered:      +%s\n", f.FirstSuccess.Sub(s.startTime).Round(time.Millisecond))
+		fmt.Fprintf(w, "  Time to recover: %s\n", f.TimeToRecover.Round(time.Millisecond))
+	}
+	fmt.Fprintf(w, "  Errors during transition: %d\n", f.TransitionErrors)
+}
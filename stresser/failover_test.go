@@ -0,0 +1,105 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// stubS3Client returns err from every call.
+type stubS3Client struct {
+	err   error
+	calls int
+}
+
+func (c *stubS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	c.calls++
+	return &s3.GetObjectOutput{}, c.err
+}
+
+func (c *stubS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.calls++
+	return &s3.PutObjectOutput{}, c.err
+}
+
+func (c *stubS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	c.calls++
+	return &s3.HeadObjectOutput{}, c.err
+}
+
+func (c *stubS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	c.calls++
+	return &s3.ListObjectsV2Output{}, c.err
+}
+
+func statusError(code int) error {
+	return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: code}},
+		Err:      errors.New("status error"),
+	}}
+}
+
+func TestIsEndpointFailure(t *testing.T) {
+	if isEndpointFailure(statusError(404)) {
+		t.Error("404 should not count as an endpoint failure")
+	}
+	if !isEndpointFailure(statusError(503)) {
+		t.Error("503 should count as an endpoint failure")
+	}
+	if !isEndpointFailure(errors.New("connection refused")) {
+		t.Error("connection errors should count as endpoint failures")
+	}
+}
+
+func TestFailoverClient(t *testing.T) {
+	ctx := context.Background()
+	primary := &stubS3Client{err: errors.New("connection refused")}
+	secondary := &stubS3Client{err: statusError(503)}
+	client := newFailoverClient(primary, secondary, 3, "http://primary", "http://secondary")
+
+	for i := 0; i < 3; i++ {
+		client.GetObject(ctx, &s3.GetObjectInput{})
+	}
+	if primary.calls != 3 || secondary.calls != 0 {
+		t.Fatalf("expected 3 primary calls before failover, got primary=%d secondary=%d", primary.calls, secondary.calls)
+	}
+
+	// Secondary is still warming up, then recovers
+	client.PutObject(ctx, &s3.PutObjectInput{})
+	secondary.err = nil
+	client.GetObject(ctx, &s3.GetObjectInput{})
+	client.GetObject(ctx, &s3.GetObjectInput{})
+
+	report := client.Report()
+	if !report.FailedOver || primary.calls != 3 || secondary.calls != 3 {
+		t.Fatalf("unexpected failover state: %+v (primary=%d secondary=%d)", report, primary.calls, secondary.calls)
+	}
+	if report.TransitionErrors != 4 {
+		t.Errorf("expected 4 errors during transition, got %d", report.TransitionErrors)
+	}
+	if report.FirstSuccess.IsZero() || report.TimeToRecover < 0 || report.TimeToRecover != report.FirstSuccess.Sub(report.FirstFailure) {
+		t.Errorf("unexpected recovery timing: %+v", report)
+	}
+}
+
+func TestFailoverClientStreakReset(t *testing.T) {
+	ctx := context.Background()
+	primary := &stubS3Client{err: errors.New("timeout")}
+	client := newFailoverClient(primary, &stubS3Client{}, 3, "a", "b")
+
+	client.GetObject(ctx, &s3.GetObjectInput{})
+	client.GetObject(ctx, &s3.GetObjectInput{})
+	primary.err = statusError(404) // Client errors end the streak
+	client.GetObject(ctx, &s3.GetObjectInput{})
+	primary.err = errors.New("timeout")
+	client.GetObject(ctx, &s3.GetObjectInput{})
+
+	if client.Report().FailedOver {
+		t.Error("failover should require consecutive endpoint failures")
+	}
+}
@@ -0,0 +1,195 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// Storage backends (-backend).
+const (
+	BackendS3  = "s3"
+	BackendGCS = "gcs"
+)
+
+// GCSClient serves the S3 operations used by the workers from Google Cloud Storage, so GCS
+// runs use the same workloads and reporting as S3 runs. Object user metadata, listing
+// pages and error status codes are translated to their S3 equivalents.
+type GCSClient struct {
+	client *storage.Client
+}
+
+// NewGCSClient creates a GCS client using the service account credentials in cfg, or
+// Application Default Credentials if none are configured.
+func NewGCSClient(ctx context.Context, cfg *Config) (*GCSClient, error) {
+	opts := []option.ClientOption{option.WithUserAgent(cfg.UserAgentString())}
+	switch {
+	case cfg.GCSCredentials != "":
+		opts = append(opts, option.WithCredentialsJSON([]byte(cfg.GCSCredentials)))
+		slog.Info("Using GCS service account credentials from configuration")
+	case cfg.GCSCredentialsFile != "":
+		opts = append(opts, option.WithCredentialsFile(cfg.GCSCredentialsFile))
+		slog.Info("Using GCS service account credentials file", "path", cfg.GCSCredentialsFile)
+	default:
+		slog.Info("Using Google Application Default Credentials")
+	}
+	if cfg.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	slog.Info("GCS client created successfully", "bucket", cfg.Bucket, "userAgent", cfg.UserAgentString())
+	return &GCSClient{client: client}, nil
+}
+
+// Close releases the connections of the client.
+func (c *GCSClient) Close() error {
+	return c.client.Close()
+}
+
+func (c *GCSClient) object(bucket, key *string) *storage.ObjectHandle {
+	return c.client.Bucket(aws.ToString(bucket)).Object(aws.ToString(key))
+}
+
+func (c *GCSClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	r, err := c.object(params.Bucket, params.Key).NewReader(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return &s3.GetObjectOutput{
+		Body:          r,
+		ContentLength: aws.Int64(r.Attrs.Size),
+		ContentType:   aws.String(r.Attrs.ContentType),
+		Metadata:      r.Metadata(),
+	}, nil
+}
+
+func (c *GCSClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	w := c.object(params.Bucket, params.Key).NewWriter(ctx)
+	w.Metadata = params.Metadata
+	w.ChunkSize = 0 // Upload in a single request, like a PutObject
+	if params.ContentType != nil {
+		w.ContentType = *params.ContentType
+	}
+	if params.CacheControl != nil {
+		w.CacheControl = *params.CacheControl
+	}
+	if _, err := io.Copy(w, params.Body); err != nil {
+		w.Close()
+		return nil, gcsError(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, gcsError(err)
+	}
+	return &s3.PutObjectOutput{ETag: aws.String(w.Attrs().Etag)}, nil
+}
+
+func (c *GCSClient) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	attrs, err := c.object(params.Bucket, params.Key).Attrs(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(attrs.Size),
+		ContentType:   aws.String(attrs.ContentType),
+		ETag:          aws.String(attrs.Etag),
+		LastModified:  aws.Time(attrs.Updated),
+		Metadata:      attrs.Metadata,
+	}, nil
+}
+
+func (c *GCSClient) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	it := c.client.Bucket(aws.ToString(params.Bucket)).Objects(ctx, &storage.Query{Prefix: aws.ToString(params.Prefix)})
+	var objects []*storage.ObjectAttrs
+	next, err := iterator.NewPager(it, int(aws.ToInt32(params.MaxKeys)), aws.ToString(params.ContinuationToken)).NextPage(&objects)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	out := &s3.ListObjectsV2Output{
+		IsTruncated: aws.Bool(next != ""),
+		KeyCount:    aws.Int32(int32(len(objects))),
+	}
+	if next != "" {
+		out.NextContinuationToken = aws.String(next)
+	}
+	for _, o := range objects {
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(o.Name), Size: aws.Int64(o.Size), ETag: aws.String(o.Etag)})
+	}
+	return out, nil
+}
+
+func (c *GCSClient) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if err := c.object(params.Bucket, params.Key).Delete(ctx); err != nil {
+		return nil, gcsError(err)
+	}
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// DeleteObjects deletes the objects one by one, GCS has no batch delete in its JSON API.
+// Like S3, missing objects count as deleted.
+func (c *GCSClient) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	out := &s3.DeleteObjectsOutput{}
+	for _, o := range params.Delete.Objects {
+		err := c.object(params.Bucket, o.Key).Delete(ctx)
+		switch {
+		case err == nil, errors.Is(err, storage.ErrObjectNotExist):
+			out.Deleted = append(out.Deleted, types.DeletedObject{Key: o.Key})
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		default:
+			out.Errors = append(out.Errors, types.Error{Key: o.Key, Message: aws.String(err.Error())})
+		}
+	}
+	return out, nil
+}
+
+func (c *GCSClient) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	attrs, err := c.client.Bucket(aws.ToString(params.Bucket)).Attrs(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return &s3.HeadBucketOutput{BucketRegion: aws.String(attrs.Location)}, nil
+}
+
+// CreateBucket is not supported: GCS buckets belong to a project, which S3 requests do not name.
+func (c *GCSClient) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	return nil, errors.New("creating buckets is not supported by the gcs backend")
+}
+
+// gcsError converts GCS errors with an HTTP status into the error type the S3 SDK returns,
+// so failover decisions and error reporting treat both backends alike.
+func gcsError(err error) error {
+	status := 0
+	var apiErr *googleapi.Error
+	switch {
+	case errors.Is(err, storage.ErrObjectNotExist), errors.Is(err, storage.ErrBucketNotExist):
+		status = http.StatusNotFound
+	case errors.As(err, &apiErr):
+		status = apiErr.Code
+	default:
+		return err
+	}
+	resp := &http.Response{StatusCode: status, Status: fmt.Sprintf("%d %s", status, http.StatusText(status)), Header: http.Header{}}
+	if apiErr != nil {
+		resp.Header = apiErr.Header
+	}
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{Response: &smithyhttp.Response{Response: resp}, Err: err},
+		RequestID:     resp.Header.Get("X-Guploader-Uploadid"),
+	}
+}
@@ -0,0 +1,63 @@
+package stresser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+)
+
+func TestGCSError(t *testing.T) {
+	notFound := gcsError(fmt.Errorf("reading: %w", storage.ErrObjectNotExist))
+	if isEndpointFailure(notFound) {
+		t.Errorf("Missing object should not count as an endpoint failure: %v", notFound)
+	}
+	if !errors.Is(notFound, storage.ErrObjectNotExist) {
+		t.Errorf("Converted error lost its cause: %v", notFound)
+	}
+
+	unavailable := gcsError(&googleapi.Error{Code: http.StatusServiceUnavailable, Header: http.Header{"X-Guploader-Uploadid": {"upload-1"}}})
+	if !isEndpointFailure(unavailable) {
+		t.Errorf("503 should count as an endpoint failure: %v", unavailable)
+	}
+	var r Result
+	recordErrorRequestID(&r, unavailable)
+	if r.RequestID != "upload-1" {
+		t.Errorf("Expected request id upload-1, got %q", r.RequestID)
+	}
+
+	plain := errors.New("connection reset")
+	if gcsError(plain) != plain {
+		t.Error("Errors without a status should be returned unchanged")
+	}
+}
+
+func TestValidateBackend(t *testing.T) {
+	base := Config{Bucket: "b", Duration: "1s", Concurrency: 1, ManifestPath: "m.txt", OutputFile: "out.csv", OperationType: "read"}
+
+	gcs := base
+	gcs.Backend = "GCS"
+	if err := gcs.Validate(); err != nil || gcs.Backend != BackendGCS {
+		t.Errorf("Expected gcs backend without endpoint to be valid, got %v (backend %q)", err, gcs.Backend)
+	}
+
+	s3 := base
+	if err := s3.Validate(); err == nil {
+		t.Error("Expected error for s3 backend without endpoint")
+	}
+
+	presign := gcs
+	presign.Presign = true
+	if err := presign.Validate(); err == nil {
+		t.Error("Expected error for -presign with the gcs backend")
+	}
+
+	unknown := base
+	unknown.Backend = "azure"
+	if err := unknown.Validate(); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+}
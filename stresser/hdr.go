@@ -0,0 +1,338 @@
+package stresser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Latency histogram parameters: nanosecond values up to an hour with three significant
+// digits, i.e. at most 0.1% error. Counts are only allocated for the range of recorded
+// values, 8 KiB per power of two, so at most about 270 KiB per histogram regardless of
+// the number of recorded values.
+const (
+	histogramMaxValue = int64(time.Hour)
+	histogramSigFigs  = 3
+)
+
+// coarseSigFigs is the precision of histograms of which a run may hold many, e.g. one per
+// time-series interval or key prefix: at most 1% error in 1 KiB per power of two.
+const coarseSigFigs = 2
+
+// HDR encoding cookies (V2 format, with the word size bits used by the reference implementations).
+const (
+	hdrEncodingCookie           = 0x1c849303 | 0x10
+	hdrCompressedEncodingCookie = 0x1c849304 | 0x10
+)
+
+// Histogram is a High Dynamic Range histogram of latencies in nanoseconds. Values are
+// bucketed with a fixed relative precision, so memory use is constant and any percentile
+// can be read back. Min, max, count and mean are tracked exactly.
+type Histogram struct {
+	highest                     int64
+	sigFigs                     int
+	subBucketHalfCountMagnitude int
+	subBucketHalfCount          int
+	subBucketCount              int
+	subBucketMask               int64
+	bucketCount                 int
+	counts                      []int64 // Counts from index offset on, grown as values are recorded
+	offset                      int
+
+	total int64
+	sum   int64
+	min   int64
+	max   int64
+}
+
+// NewHistogram returns an empty latency histogram.
+func NewHistogram() *Histogram {
+	return newHistogram(histogramMaxValue, histogramSigFigs)
+}
+
+// newCoarseHistogram returns an empty latency histogram of lower precision, for breakdowns
+// into many groups, see coarseSigFigs.
+func newCoarseHistogram() *Histogram {
+	return newHistogram(histogramMaxValue, coarseSigFigs)
+}
+
+// newHistogram creates a histogram tracking values from 1 to highest with sigFigs
+// significant decimal digits, laid out like the reference HdrHistogram with a lowest
+// discernible value of 1.
+func newHistogram(highest int64, sigFigs int) *Histogram {
+	largestSingleUnit := 2 * int64(math.Pow10(sigFigs))
+	subBucketCountMagnitude := int(math.Ceil(math.Log2(float64(largestSingleUnit))))
+	subBucketHalfCountMagnitude := max(subBucketCountMagnitude, 1) - 1
+	subBucketCount := 1 << (subBucketHalfCountMagnitude + 1)
+
+	bucketCount := 1
+	for smallestUntrackable := int64(subBucketCount); smallestUntrackable <= highest; smallestUntrackable <<= 1 {
+		if smallestUntrackable > math.MaxInt64/2 {
+			bucketCount++
+			break
+		}
+		bucketCount++
+	}
+
+	return &Histogram{
+		highest:                     highest,
+		sigFigs:                     sigFigs,
+		subBucketHalfCountMagnitude: subBucketHalfCountMagnitude,
+		subBucketHalfCount:          subBucketCount / 2,
+		subBucketCount:              subBucketCount,
+		subBucketMask:               int64(subBucketCount - 1),
+		bucketCount:                 bucketCount,
+		min:                         math.MaxInt64,
+	}
+}
+
+// Record adds a latency. Negative values are recorded as 0, values beyond an hour as an hour.
+func (h *Histogram) Record(d time.Duration) {
+	v := min(max(int64(d), 0), h.highest)
+	i := h.countsIndex(v)
+	if i < h.offset || i >= h.offset+len(h.counts) {
+		h.grow(i)
+	}
+	h.counts[i-h.offset]++
+	h.total++
+	h.sum += v
+	h.min = min(h.min, v)
+	h.max = max(h.max, v)
+}
+
+// grow extends the counts to cover index i, in whole half buckets so that values close to
+// the recorded ones don't grow them again.
+func (h *Histogram) grow(i int) {
+	lo, hi := i, i+1
+	if len(h.counts) > 0 {
+		lo, hi = min(lo, h.offset), max(hi, h.offset+len(h.counts))
+	}
+	lo -= lo % h.subBucketHalfCount
+	hi = min(hi+h.subBucketHalfCount-1-(hi-1)%h.subBucketHalfCount, (h.bucketCount+1)*h.subBucketHalfCount)
+	counts := make([]int64, hi-lo)
+	if len(h.counts) > 0 {
+		copy(counts[h.offset-lo:], h.counts)
+	}
+	h.counts, h.offset = counts, lo
+}
+
+// count returns the count at index i.
+func (h *Histogram) count(i int) int64 {
+	if i < h.offset || i >= h.offset+len(h.counts) {
+		return 0
+	}
+	return h.counts[i-h.offset]
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 { return h.total }
+
+// Min returns the smallest recorded value, 0 if empty.
+func (h *Histogram) Min() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.min)
+}
+
+// Max returns the largest recorded value, 0 if empty.
+func (h *Histogram) Max() time.Duration { return time.Duration(h.max) }
+
+// Mean returns the exact average of the recorded values, 0 if empty.
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / h.total)
+}
+
+// Percentile returns the value below which q percent of the recorded values fall, using
+// the nearest rank. The result is the upper end of the bucket holding that rank, clamped
+// to the exact min and max, and within the histogram precision of the true value.
+func (h *Histogram) Percentile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(min(q, 100)/100*float64(h.total))), 1)
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			return h.bucketValue(h.offset + i)
+		}
+	}
+	return time.Duration(h.max)
+}
+
+// Each calls f for every bucket holding recorded values, in increasing order, with the
+// value the bucket stands for (as returned by Percentile) and its count.
+func (h *Histogram) Each(f func(v time.Duration, count int64)) {
+	for i, c := range h.counts {
+		if c > 0 {
+			f(h.bucketValue(h.offset+i), c)
+		}
+	}
+}
+
+// bucketValue returns the upper end of the bucket at index i, clamped to the exact min and max.
+func (h *Histogram) bucketValue(i int) time.Duration {
+	v := h.highestEquivalentValue(h.valueFromIndex(i))
+	return time.Duration(min(max(v, h.min), h.max))
+}
+
+func (h *Histogram) bucketIndex(v int64) int {
+	pow2Ceiling := 64 - bits.LeadingZeros64(uint64(v|h.subBucketMask))
+	return pow2Ceiling - (h.subBucketHalfCountMagnitude + 1)
+}
+
+func (h *Histogram) countsIndex(v int64) int {
+	bucket := h.bucketIndex(v)
+	subBucket := int(v >> bucket)
+	return (bucket+1)<<h.subBucketHalfCountMagnitude + subBucket - h.subBucketHalfCount
+}
+
+func (h *Histogram) valueFromIndex(i int) int64 {
+	bucket := i>>h.subBucketHalfCountMagnitude - 1
+	subBucket := i&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucket < 0 {
+		subBucket -= h.subBucketHalfCount
+		bucket = 0
+	}
+	return int64(subBucket) << bucket
+}
+
+// highestEquivalentValue returns the largest value that falls in the same bucket as v.
+func (h *Histogram) highestEquivalentValue(v int64) int64 {
+	bucket := h.bucketIndex(v)
+	subBucket := int(v >> bucket)
+	size := int64(1) << bucket
+	if subBucket >= h.subBucketCount {
+		size <<= 1
+	}
+	return int64(subBucket)<<bucket + size - 1
+}
+
+// encode returns the histogram in the compressed V2 HdrHistogram encoding, as used in
+// HDR log files and understood by the reference implementations.
+func (h *Histogram) encode() ([]byte, error) {
+	// Counts are zig-zag LEB128 encoded up to the highest used index, runs of zeros as a negative run length
+	used := 0
+	if h.total > 0 {
+		used = h.countsIndex(h.max) + 1
+	}
+	var payload []byte
+	for i := 0; i < used; {
+		if c := h.count(i); c != 0 {
+			payload = appendZigZag(payload, c)
+			i++
+			continue
+		}
+		zeros := int64(0)
+		for i < used && h.count(i) == 0 {
+			zeros++
+			i++
+		}
+		if zeros > 1 {
+			payload = appendZigZag(payload, -zeros)
+		} else {
+			payload = appendZigZag(payload, 0)
+		}
+	}
+
+	var raw bytes.Buffer
+	for _, v := range []any{int32(hdrEncodingCookie), int32(len(payload)), int32(0), int32(h.sigFigs), int64(1), h.highest, float64(1)} {
+		binary.Write(&raw, binary.BigEndian, v) // Writes to a bytes.Buffer can't fail
+	}
+	raw.Write(payload)
+
+	var compressed bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(raw.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.BigEndian, int32(hdrCompressedEncodingCookie))
+	binary.Write(&out, binary.BigEndian, int32(compressed.Len()))
+	out.Write(compressed.Bytes())
+	return out.Bytes(), nil
+}
+
+// appendZigZag appends v as a zig-zag LEB128 varint. Counts stay far below 2^56, where the
+// HdrHistogram variant and the standard encoding are identical.
+func appendZigZag(buf []byte, v int64) []byte {
+	return binary.AppendUvarint(buf, uint64((v<<1)^(v>>63)))
+}
+
+// Histograms returns the latency histograms of successful operations, keyed by the tag
+// used in HDR logs ("GET-TTFB", "GET-BODY-TTFB", "GET-TTLB", "PUT-TTLB", "HEAD-TTLB"). Empty
+// ones are left out.
+func (s *Stats) Histograms() map[string]*Histogram {
+	all := map[string]*Histogram{
+		"GET-TTFB":      s.GetTTFBHist,
+		"GET-BODY-TTFB": s.GetBodyTTFBHist,
+		"GET-TTLB":      s.GetTTLBHist,
+		"PUT-TTLB":      s.PutTTLBHist,
+		"HEAD-TTLB":     s.HeadTTLBHist,
+	}
+	for tag, h := range all {
+		if h.Count() == 0 {
+			delete(all, tag)
+		}
+	}
+	return all
+}
+
+// hdrLogTags fixes the order of histograms in HDR logs.
+var hdrLogTags = []string{"GET-TTFB", "GET-BODY-TTFB", "GET-TTLB", "PUT-TTLB", "HEAD-TTLB"}
+
+// WriteHDRLog writes the latency histograms of a calculated Stats in the HdrHistogram log
+// format (version 1.3), one tagged interval covering the whole run per histogram. Values
+// are in nanoseconds; the Interval_Max column is in milliseconds.
+func WriteHDRLog(w io.Writer, s *Stats) error {
+	start := s.startTime
+	fmt.Fprintf(w, "#[Histogram log format version 1.3]\n")
+	fmt.Fprintf(w, "#[StartTime: %.3f (seconds since epoch), %s]\n", float64(start.UnixMilli())/1000, start.Format(time.UnixDate))
+	fmt.Fprintf(w, "\"StartTimestamp\",\"Interval_Length\",\"Interval_Max\",\"Interval_Compressed_Histogram\"\n")
+	histograms := s.Histograms()
+	for _, tag := range hdrLogTags {
+		h, ok := histograms[tag]
+		if !ok {
+			continue
+		}
+		encoded, err := h.encode()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s histogram: %w", tag, err)
+		}
+		if _, err := fmt.Fprintf(w, "Tag=%s,%.3f,%.3f,%.3f,%s\n", tag, 0.0, s.actualDuration.Seconds(),
+			ms(h.Max()), base64.StdEncoding.EncodeToString(encoded)); err != nil {
+			return fmt.Errorf("failed to write hdr log: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteHDRLogFile writes the HDR log to path, "-" for stdout.
+func WriteHDRLogFile(s *Stats, path string) error {
+	out, err := OpenOutput(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteHDRLog(out, s); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
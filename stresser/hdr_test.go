@@ -0,0 +1,161 @@
+package stresser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogramPercentiles(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 100000; i++ {
+		h.Record(time.Duration(i) * time.Microsecond)
+	}
+
+	if h.Count() != 100000 || h.Min() != time.Microsecond || h.Max() != 100*time.Millisecond {
+		t.Errorf("Unexpected count/min/max: %d %v %v", h.Count(), h.Min(), h.Max())
+	}
+	if want := 50000500 * time.Nanosecond; h.Mean() != want {
+		t.Errorf("Expected exact mean %v, got %v", want, h.Mean())
+	}
+	for _, c := range []struct {
+		q    float64
+		want time.Duration
+	}{{50, 50 * time.Millisecond}, {99, 99 * time.Millisecond}, {99.9, 99900 * time.Microsecond}, {100, 100 * time.Millisecond}} {
+		got := h.Percentile(c.q)
+		if diff := got - c.want; diff < 0 || float64(diff) > float64(c.want)*0.001 {
+			t.Errorf("P%v = %v, want %v within 0.1%%", c.q, got, c.want)
+		}
+	}
+
+	empty := NewHistogram()
+	if empty.Percentile(99) != 0 || empty.Min() != 0 || empty.Mean() != 0 {
+		t.Error("An empty histogram should report zeros")
+	}
+}
+
+func TestHistogramGrowsWithRange(t *testing.T) {
+	h := newCoarseHistogram()
+	for _, d := range []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond} {
+		h.Record(d)
+	}
+	if len(h.counts) != h.subBucketHalfCount*2 {
+		t.Errorf("Expected counts for two powers of two, got %d", len(h.counts))
+	}
+	h.Record(time.Microsecond)
+	h.Record(time.Hour)
+	if h.Min() != time.Microsecond || h.Percentile(50) < 10*time.Millisecond || h.Percentile(50) > 10100*time.Microsecond || h.Percentile(100) != time.Hour {
+		t.Errorf("Unexpected min and percentiles after growing: %v %v %v", h.Min(), h.Percentile(50), h.Percentile(100))
+	}
+	var counts []int64
+	h.Each(func(v time.Duration, count int64) { counts = append(counts, count) })
+	if len(counts) != 4 || counts[1] != 2 {
+		t.Errorf("Expected 4 buckets with the two 10ms values in one, got %v", counts)
+	}
+}
+
+// decodeHistogramCounts decodes the compressed V2 encoding back into counts.
+func decodeHistogramCounts(t *testing.T, encoded []byte) (sigFigs int32, highest int64, counts []int64) {
+	t.Helper()
+	r := bytes.NewReader(encoded)
+	var cookie, length int32
+	binary.Read(r, binary.BigEndian, &cookie)
+	binary.Read(r, binary.BigEndian, &length)
+	if cookie != hdrCompressedEncodingCookie || int(length) != r.Len() {
+		t.Fatalf("Bad compressed header: cookie %x, length %d of %d", cookie, length, r.Len())
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		t.Fatalf("Bad zlib stream: %v", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Bad zlib stream: %v", err)
+	}
+
+	rr := bytes.NewReader(raw)
+	var payloadLen, normalizingOffset int32
+	var lowest int64
+	var ratio float64
+	for _, v := range []any{&cookie, &payloadLen, &normalizingOffset, &sigFigs, &lowest, &highest, &ratio} {
+		binary.Read(rr, binary.BigEndian, v)
+	}
+	if cookie != hdrEncodingCookie || int(payloadLen) != rr.Len() || lowest != 1 || ratio != 1 {
+		t.Fatalf("Bad encoding header: cookie %x, payload %d of %d, lowest %d, ratio %v", cookie, payloadLen, rr.Len(), lowest, ratio)
+	}
+	for rr.Len() > 0 {
+		u, err := binary.ReadUvarint(rr)
+		if err != nil {
+			t.Fatalf("Bad varint: %v", err)
+		}
+		v := int64(u>>1) ^ -int64(u&1)
+		if v < 0 {
+			counts = append(counts, make([]int64, -v)...)
+		} else {
+			counts = append(counts, v)
+		}
+	}
+	return sigFigs, highest, counts
+}
+
+func TestHistogramEncode(t *testing.T) {
+	h := NewHistogram()
+	for _, d := range []time.Duration{time.Microsecond, time.Millisecond, time.Millisecond, 2 * time.Second} {
+		h.Record(d)
+	}
+	encoded, err := h.encode()
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	sigFigs, highest, counts := decodeHistogramCounts(t, encoded)
+	if sigFigs != histogramSigFigs || highest != histogramMaxValue {
+		t.Errorf("Unexpected parameters: %d significant digits, highest %d", sigFigs, highest)
+	}
+	if len(counts) != h.countsIndex(int64(2*time.Second))+1 {
+		t.Fatalf("Expected counts up to the max value, got %d", len(counts))
+	}
+	for i, c := range counts {
+		if c != h.count(i) {
+			t.Fatalf("Count %d differs after decoding: %d != %d", i, c, h.count(i))
+		}
+	}
+}
+
+func TestWriteHDRLog(t *testing.T) {
+	start := time.Now()
+	s := NewStats()
+	s.AddResult(Result{Operation: "GET", TTFB: 5 * time.Millisecond, TTLB: 10 * time.Millisecond})
+	s.AddResult(Result{Operation: "PUT", TTLB: 20 * time.Millisecond})
+	s.Calculate(start, start.Add(2*time.Second))
+
+	var buf bytes.Buffer
+	if err := WriteHDRLog(&buf, s); err != nil {
+		t.Fatalf("WriteHDRLog failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || lines[0] != "#[Histogram log format version 1.3]" || !strings.HasPrefix(lines[1], "#[StartTime: ") {
+		t.Fatalf("Unexpected log:\n%s", buf.String())
+	}
+	for i, tag := range []string{"GET-TTFB", "GET-TTLB", "PUT-TTLB"} {
+		fields := strings.Split(lines[3+i], ",")
+		if len(fields) != 5 || fields[0] != "Tag="+tag || fields[1] != "0.000" || fields[2] != "2.000" || !strings.HasPrefix(fields[4], "HISTF") {
+			t.Errorf("Unexpected %s line: %s", tag, lines[3+i])
+			continue
+		}
+		encoded, err := base64.StdEncoding.DecodeString(fields[4])
+		if err != nil {
+			t.Fatalf("Bad base64: %v", err)
+		}
+		if _, _, counts := decodeHistogramCounts(t, encoded); len(counts) == 0 {
+			t.Errorf("%s histogram decoded empty", tag)
+		}
+	}
+	if fields := strings.Split(lines[5], ","); fields[3] != "20.000" {
+		t.Errorf("Expected PUT interval max of 20ms, got %s", fields[3])
+	}
+}
@@ -0,0 +1,87 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"gopkg.in/yaml.v3"
+)
+
+// RequestHook mutates an outgoing HTTP request before it is signed. ctx is the
+// operation context, so hooks can pick up tracing spans or per-request values.
+type RequestHook func(ctx context.Context, req *http.Request) error
+
+// WithRequestHook returns an SDK API option that runs hook on every request. Append it to
+// Config.APIOptions to add dynamic headers or other per-request changes without building
+// the client yourself. The hook runs in the build step, so anything it adds is signed.
+func WithRequestHook(id string, hook RequestHook) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc(id,
+			func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+				if req, ok := in.Request.(*smithyhttp.Request); ok {
+					if err := hook(ctx, req.Request); err != nil {
+						return middleware.BuildOutput{}, middleware.Metadata{}, fmt.Errorf("request hook %s: %w", id, err)
+					}
+				}
+				return next.HandleBuild(ctx, in)
+			}), middleware.After)
+	}
+}
+
+// HeaderList holds "Name: value" header specifications. In YAML it is either a list of such
+// strings or a map from header name to value, e.g. {X-Tenant: qa, X-Route: blue}.
+type HeaderList []string
+
+// UnmarshalYAML accepts a list of "Name: value" strings or a map, keeping the map's order.
+func (h *HeaderList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*h = list
+		return nil
+	}
+	list := make(HeaderList, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: value of header %q must be a string", value.Line, name.Value)
+		}
+		list = append(list, name.Value+": "+value.Value)
+	}
+	*h = list
+	return nil
+}
+
+// parseHeader splits a "Name: value" header specification.
+func parseHeader(spec string) (string, string, error) {
+	name, value, ok := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid header %q: expected 'Name: value'", spec)
+	}
+	return http.CanonicalHeaderKey(name), strings.TrimSpace(value), nil
+}
+
+// staticHeaders returns a hook setting the given "Name: value" headers on every request.
+func staticHeaders(specs []string) (RequestHook, error) {
+	header := make(http.Header)
+	for _, spec := range specs {
+		name, value, err := parseHeader(spec)
+		if err != nil {
+			return nil, err
+		}
+		header.Add(name, value)
+	}
+	return func(ctx context.Context, req *http.Request) error {
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		return nil
+	}, nil
+}
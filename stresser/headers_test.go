@@ -0,0 +1,89 @@
+package stresser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := parseHeader("x-trace-id:  abc 123 ")
+	if err != nil || name != "X-Trace-Id" || value != "abc 123" {
+		t.Errorf("unexpected parse result %q %q %v", name, value, err)
+	}
+	for _, spec := range []string{"no-colon", ": value", "bad name: value"} {
+		if _, _, err := parseHeader(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestRequestHooks(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	calls := 0
+	cfg := &Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		AccessKey: "key",
+		SecretKey: "secret",
+		Headers:   []string{"X-Static: one"},
+		RunID:     "run1",
+		UserAgent: "team-qa",
+		APIOptions: []func(*middleware.Stack) error{
+			WithRequestHook("TestHook", func(ctx context.Context, req *http.Request) error {
+				calls++
+				req.Header.Set("X-Dynamic", req.Method)
+				return nil
+			}),
+		},
+	}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	if _, err := client.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}); err != nil {
+		t.Fatalf("HeadObject failed: %v", err)
+	}
+	if calls != 1 || got.Get("X-Static") != "one" || got.Get("X-Dynamic") != http.MethodHead {
+		t.Errorf("hooks not applied: calls=%d headers=%v", calls, got)
+	}
+	ua := got.Get("User-Agent")
+	if !strings.HasPrefix(ua, "ostresser/") || !strings.Contains(ua, " run/run1 team-qa aws-sdk-go-v2/") {
+		t.Errorf("unexpected User-Agent %q", ua)
+	}
+}
+
+func TestHeaderListYAML(t *testing.T) {
+	for doc, want := range map[string][]string{
+		"headers: ['X-Tenant: qa', 'X-Route: blue']": {"X-Tenant: qa", "X-Route: blue"},
+		"headers: {X-Tenant: qa, X-Route: blue}":     {"X-Tenant: qa", "X-Route: blue"},
+		"headers:\n  X-Debug: 1\n":                   {"X-Debug: 1"},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(doc), &cfg); err != nil {
+			t.Errorf("%q: %v", doc, err)
+			continue
+		}
+		if !slices.Equal([]string(cfg.Headers), want) {
+			t.Errorf("%q: got %q, expected %q", doc, cfg.Headers, want)
+		}
+	}
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("headers: {X-Tenant: [a, b]}"), &cfg); err == nil {
+		t.Error("Expected an error for a header value that isn't a string")
+	}
+}
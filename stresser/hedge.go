@@ -0,0 +1,166 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	hedgeWindow     = 1000 // Recent GET latencies the hedge delay is computed from
+	hedgeMinSamples = 100  // Don't hedge until this many latencies have been observed
+	hedgeRecompute  = 50   // Recompute the delay after this many new observations
+)
+
+// hedger decides when a slow GET gets a second, speculative request. The delay is the
+// configured quantile of recently observed time-to-first-byte, so only the slowest
+// requests are hedged. It is safe for concurrent use and shared by all workers.
+type hedger struct {
+	quantile float64 // Percent, e.g. 95
+
+	mu      sync.Mutex
+	samples []time.Duration // Ring buffer of the last hedgeWindow latencies
+	next    int
+	pending int // Observations since the delay was last computed
+	delay   time.Duration
+}
+
+func newHedger(quantile float64) *hedger {
+	return &hedger{quantile: quantile, samples: make([]time.Duration, 0, hedgeWindow)}
+}
+
+// observe records the client-perceived time to first byte of a successful GET.
+func (h *hedger) observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) < hedgeWindow {
+		h.samples = append(h.samples, d)
+	} else {
+		h.samples[h.next] = d
+		h.next = (h.next + 1) % hedgeWindow
+	}
+	h.pending++
+	if len(h.samples) >= hedgeMinSamples && (h.delay == 0 || h.pending >= hedgeRecompute) {
+		sorted := append([]time.Duration(nil), h.samples...)
+		sortDurations(sorted)
+		// Nearest rank like percentileDuration, but fractional quantiles such as 99.9 are allowed
+		h.delay = sorted[min(int(h.quantile*float64(len(sorted))/100), len(sorted)-1)]
+		h.pending = 0
+	}
+}
+
+// Delay returns how long to wait for the first response before hedging, and false while
+// too few latencies have been observed to know.
+func (h *hedger) Delay() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.delay, h.delay > 0
+}
+
+// racedGet is the outcome of one of the requests of a hedged GET.
+type racedGet struct {
+	attempt *getAttempt
+	hedge   bool
+}
+
+// performHedgedGet sends a GET and, if no response has arrived after the hedge delay, a
+// second identical GET. The first successful response wins and the other request is
+// cancelled. Latencies are measured from the start of the first request, as a client
+// using hedging would experience them. With verify, the winning body is checked against
+// the stored checksum.
+func performHedgedGet(ctx context.Context, s3Client S3ClientAPI, h *hedger, bucket, key string, verify bool) Result {
+	start := time.Now()
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	hedgeCtx, cancelHedge := context.WithCancel(ctx)
+	defer cancelHedge()
+
+	raced := make(chan racedGet, 2)
+	go func() { raced <- racedGet{attempt: startGet(primaryCtx, s3Client, bucket, key)} }()
+
+	var timeout <-chan time.Time
+	if delay, ok := h.Delay(); ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case r := <-raced:
+		// Answered before the hedge delay, or hedging isn't warmed up yet
+		return finishRacedGet(r, start, false, h, verify)
+	case <-timeout:
+	}
+
+	go func() { raced <- racedGet{attempt: startGet(hedgeCtx, s3Client, bucket, key), hedge: true} }()
+	winner := <-raced
+	outstanding := 1
+	if winner.attempt.resp == nil {
+		// First one failed, give the other one a chance
+		if other := <-raced; other.attempt.resp != nil {
+			winner = other
+		}
+		outstanding = 0
+	}
+	if winner.hedge {
+		cancelPrimary()
+	} else {
+		cancelHedge()
+	}
+	if outstanding > 0 {
+		go func() { (<-raced).attempt.discard() }()
+	}
+	return finishRacedGet(winner, start, true, h, verify)
+}
+
+// finishRacedGet reads the winning response and marks the result as hedged or not.
+func finishRacedGet(r racedGet, start time.Time, hedged bool, h *hedger, verify bool) Result {
+	if r.attempt.resp != nil {
+		h.observe(r.attempt.headersAt.Sub(start))
+	}
+	result := r.attempt.finish(start, verify)
+	result.Timestamp = start
+	result.Hedged = hedged
+	result.HedgeWon = r.hedge
+	return result
+}
+
+// HedgeReport summarizes hedged GETs.
+type HedgeReport struct {
+	Gets     int64 `json:"gets" yaml:"gets"`         // All GETs, hedged or not
+	Hedged   int64 `json:"hedged" yaml:"hedged"`     // GETs that sent a second request, each one extra request
+	HedgeWon int64 `json:"hedgeWon" yaml:"hedgeWon"` // Hedged GETs answered first by the second request
+}
+
+// addHedgeResult counts hedged GETs. Called from AddResult.
+func (s *Stats) addHedgeResult(r Result) {
+	if r.Hedged {
+		s.hedged++
+	}
+	if r.HedgeWon {
+		s.hedgeWon++
+	}
+}
+
+// Hedging returns the hedge counts, or nil if no GET was hedged.
+func (s *Stats) Hedging() *HedgeReport {
+	if s.hedged == 0 {
+		return nil
+	}
+	return &HedgeReport{Gets: s.TotalGets, Hedged: s.hedged, HedgeWon: s.hedgeWon}
+}
+
+// printHedgeSummary prints hedging effectiveness as part of PrintSummary.
+func (s *Stats) printHedgeSummary(w io.Writer) {
+	hr := s.Hedging()
+	if hr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nHedged GETs:\n")
+	fmt.Fprintf(w, "  Hedge Rate:     %d of %d GETs (%.1f%%)\n", hr.Hedged, hr.Gets, 100*float64(hr.Hedged)/float64(hr.Gets))
+	fmt.Fprintf(w, "  Won by Hedge:   %d (%.1f%% of hedged)\n", hr.HedgeWon, 100*float64(hr.HedgeWon)/float64(hr.Hedged))
+	fmt.Fprintf(w, "  Extra Requests: %d (+%.1f%% GET load, responses of the losing request are discarded)\n",
+		hr.Hedged, 100*float64(hr.Hedged)/float64(hr.Gets))
+}
@@ -0,0 +1,82 @@
+package stresser
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// delayedGetClient answers the n-th GetObject after delays[n], or immediately past the end.
+type delayedGetClient struct {
+	stubS3Client
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (c *delayedGetClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	c.mu.Lock()
+	var delay time.Duration
+	if c.calls < len(c.delays) {
+		delay = c.delays[c.calls]
+	}
+	c.calls++
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+		return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader("data"))}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestHedgerDelay(t *testing.T) {
+	h := newHedger(95)
+	for i := 1; i < hedgeMinSamples; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+	if _, ok := h.Delay(); ok {
+		t.Fatal("hedger should not hedge before warming up")
+	}
+	h.observe(hedgeMinSamples * time.Millisecond)
+	if delay, ok := h.Delay(); !ok || delay != 96*time.Millisecond {
+		t.Errorf("expected a 96ms delay, got %v (ok=%v)", delay, ok)
+	}
+}
+
+func TestPerformHedgedGet(t *testing.T) {
+	h := newHedger(50)
+	for i := 0; i < hedgeMinSamples; i++ {
+		h.observe(time.Millisecond)
+	}
+
+	// The first request hangs, the hedge answers right away
+	client := &delayedGetClient{delays: []time.Duration{time.Minute, 0}}
+	result := performHedgedGet(context.Background(), client, h, "bucket", "key", false)
+	if result.Error != "" || !result.Hedged || !result.HedgeWon || result.BytesDownloaded != 4 {
+		t.Fatalf("unexpected hedged result: %+v", result)
+	}
+	if result.TTFB < time.Millisecond || result.TTFB > 10*time.Second {
+		t.Errorf("TTFB should be measured from the first request, got %v", result.TTFB)
+	}
+
+	// Fast responses are not hedged
+	client = &delayedGetClient{}
+	result = performHedgedGet(context.Background(), client, h, "bucket", "key", false)
+	if result.Hedged || client.calls != 1 {
+		t.Errorf("fast GET should not be hedged: %+v (calls=%d)", result, client.calls)
+	}
+
+	stats := NewStats()
+	stats.AddResult(Result{Operation: "GET", TTLB: time.Millisecond, Hedged: true, HedgeWon: true})
+	stats.AddResult(Result{Operation: "GET", TTLB: time.Millisecond, Hedged: true})
+	stats.AddResult(Result{Operation: "GET", TTLB: time.Millisecond})
+	if hr := stats.Hedging(); hr == nil || hr.Gets != 3 || hr.Hedged != 2 || hr.HedgeWon != 1 {
+		t.Errorf("unexpected hedge report: %+v", hr)
+	}
+}
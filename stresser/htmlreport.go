@@ -0,0 +1,416 @@
+package stresser
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Layout of the charts in the HTML report, in SVG user units.
+const (
+	chartWidth      = 960
+	chartHeight     = 240
+	chartPadLeft    = 64
+	chartPadRight   = 16
+	chartPadTop     = 16
+	chartPadBottom  = 32
+	heatmapRows     = 24  // Latency bands of the heatmap
+	heatmapMaxCols  = 300 // Intervals are merged beyond this many columns
+	chartGridLines  = 4
+	chartXAxisTicks = 6
+)
+
+// chartSeries is one line of a line chart.
+type chartSeries struct {
+	name   string
+	color  string
+	values []float64
+}
+
+// chartPlot maps data coordinates to the plotting area of a chart.
+type chartPlot struct {
+	xMax, yMax float64
+	b          strings.Builder
+}
+
+// newChartPlot starts an SVG chart for x values from 0 to xMax and y values from 0 to yMax.
+func newChartPlot(xMax, yMax float64) *chartPlot {
+	p := &chartPlot{xMax: xMax, yMax: yMax}
+	if p.xMax <= 0 {
+		p.xMax = 1
+	}
+	if p.yMax <= 0 {
+		p.yMax = 1
+	}
+	fmt.Fprintf(&p.b, `<svg viewBox="0 0 %d %d" class="chart" xmlns="http://www.w3.org/2000/svg">`, chartWidth, chartHeight)
+	return p
+}
+
+func (p *chartPlot) x(v float64) float64 {
+	return chartPadLeft + v/p.xMax*(chartWidth-chartPadLeft-chartPadRight)
+}
+
+func (p *chartPlot) y(v float64) float64 {
+	return chartHeight - chartPadBottom - v/p.yMax*(chartHeight-chartPadTop-chartPadBottom)
+}
+
+// xAxis draws the time axis, labelled in seconds since the start of the run.
+func (p *chartPlot) xAxis() {
+	fmt.Fprintf(&p.b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="axis"/>`, chartPadLeft, p.y(0), chartWidth-chartPadRight, p.y(0))
+	for i := 0; i <= chartXAxisTicks; i++ {
+		v := p.xMax * float64(i) / chartXAxisTicks
+		fmt.Fprintf(&p.b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, p.x(v), chartHeight-10, formatOffset(v))
+	}
+}
+
+// yGrid draws horizontal grid lines labelled with their value in unit.
+func (p *chartPlot) yGrid(unit string) {
+	for i := 0; i <= chartGridLines; i++ {
+		v := p.yMax * float64(i) / chartGridLines
+		fmt.Fprintf(&p.b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`, chartPadLeft, p.y(v), chartWidth-chartPadRight, p.y(v))
+		fmt.Fprintf(&p.b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s%s</text>`, chartPadLeft-6, p.y(v)+4, formatTick(v), unit)
+	}
+}
+
+// legend names the series in the top right corner.
+func (p *chartPlot) legend(series []chartSeries) {
+	x := float64(chartWidth - chartPadRight - 110*len(series))
+	for _, s := range series {
+		fmt.Fprintf(&p.b, `<rect x="%.1f" y="%d" width="12" height="12" fill="%s"/>`, x, chartPadTop, s.color)
+		fmt.Fprintf(&p.b, `<text x="%.1f" y="%d" class="tick">%s</text>`, x+16, chartPadTop+10, template.HTMLEscapeString(s.name))
+		x += 110
+	}
+}
+
+// svg ends the chart and returns its markup.
+func (p *chartPlot) svg() template.HTML {
+	p.b.WriteString(`</svg>`)
+	return template.HTML(p.b.String())
+}
+
+// lineChart draws series over the interval offsets xs, with the y axis labelled in unit.
+func lineChart(xs []float64, unit string, series ...chartSeries) template.HTML {
+	if len(xs) == 0 {
+		return ""
+	}
+	var yMax float64
+	for _, s := range series {
+		for _, v := range s.values {
+			yMax = math.Max(yMax, v)
+		}
+	}
+	p := newChartPlot(xs[len(xs)-1], niceCeil(yMax))
+	p.yGrid(unit)
+	p.xAxis()
+	for _, s := range series {
+		var points strings.Builder
+		for i, v := range s.values {
+			fmt.Fprintf(&points, "%.1f,%.1f ", p.x(xs[i]), p.y(v))
+		}
+		fmt.Fprintf(&p.b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, strings.TrimSpace(points.String()), s.color)
+	}
+	p.legend(series)
+	return p.svg()
+}
+
+// barChart draws one bar per interval, for counts such as errors per second.
+func barChart(xs []float64, unit, color string, values []float64) template.HTML {
+	if len(xs) == 0 {
+		return ""
+	}
+	var yMax float64
+	for _, v := range values {
+		yMax = math.Max(yMax, v)
+	}
+	p := newChartPlot(xs[len(xs)-1]+TimeSeriesInterval.Seconds(), niceCeil(yMax))
+	p.yGrid(unit)
+	p.xAxis()
+	width := math.Max(p.x(TimeSeriesInterval.Seconds())-p.x(0), 1)
+	for i, v := range values {
+		if v > 0 {
+			fmt.Fprintf(&p.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, p.x(xs[i]), p.y(v), width, p.y(0)-p.y(v), color)
+		}
+	}
+	return p.svg()
+}
+
+// latencyHeatmap draws how the latencies of successful requests were distributed over time:
+// one column per interval, one row per latency band on a log scale, darker cells holding
+// more requests. Bimodal latencies and slow tails show up as separate bands.
+func (s *Stats) latencyHeatmap(points []TimeSeriesPoint) template.HTML {
+	var lo, hi time.Duration
+	for _, pt := range points {
+		s.intervalLatencies(pt).Each(func(d time.Duration, _ int64) {
+			if d <= 0 {
+				return
+			}
+			if lo == 0 || d < lo {
+				lo = d
+			}
+			hi = max(hi, d)
+		})
+	}
+	if lo == 0 {
+		return ""
+	}
+	if float64(hi) < float64(lo)*1.1 {
+		hi = lo * 2 // Nearly constant latency, still spread it over a few bands
+	}
+	span := math.Log(float64(hi) / float64(lo))
+	band := func(d time.Duration) int {
+		return min(int(float64(heatmapRows)*math.Log(float64(d)/float64(lo))/span), heatmapRows-1)
+	}
+	edge := func(row int) time.Duration {
+		return time.Duration(float64(lo) * math.Exp(span*float64(row)/heatmapRows))
+	}
+
+	cols := min(len(points), heatmapMaxCols)
+	counts := make([][heatmapRows]int, cols)
+	var maxCount int
+	for i, pt := range points {
+		col := i * cols / len(points)
+		s.intervalLatencies(pt).Each(func(d time.Duration, n int64) {
+			if d <= 0 {
+				return
+			}
+			row := band(d)
+			counts[col][row] += int(n)
+			maxCount = max(maxCount, counts[col][row])
+		})
+	}
+
+	p := newChartPlot(points[len(points)-1].Offset+TimeSeriesInterval.Seconds(), heatmapRows)
+	p.xAxis()
+	for row := 0; row <= heatmapRows; row += heatmapRows / chartGridLines {
+		fmt.Fprintf(&p.b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%sms</text>`, chartPadLeft-6, p.y(float64(row))+4, formatTick(ms(edge(row))))
+	}
+	cellWidth := (chartWidth - chartPadLeft - chartPadRight) / float64(cols)
+	cellHeight := p.y(0) - p.y(1)
+	for col := range counts {
+		for row, n := range counts[col] {
+			if n == 0 {
+				continue
+			}
+			t := math.Log1p(float64(n)) / math.Log1p(float64(maxCount))
+			fmt.Fprintf(&p.b, `<rect x="%.1f" y="%.1f" width="%.2f" height="%.2f" fill="hsl(%.0f,85%%,%.0f%%)"><title>%d requests, %s-%s ms</title></rect>`,
+				chartPadLeft+float64(col)*cellWidth, p.y(float64(row+1)), cellWidth, cellHeight, 60-60*t, 88-50*t,
+				n, formatTick(ms(edge(row))), formatTick(ms(edge(row+1))))
+		}
+	}
+	return p.svg()
+}
+
+// intervalLatencies returns the histogram of the TTLBs of the successful requests that
+// completed in the interval of pt, empty if there were none.
+func (s *Stats) intervalLatencies(pt TimeSeriesPoint) *Histogram {
+	if b, ok := s.timeSeries[pt.Start.UnixNano()/int64(TimeSeriesInterval)]; ok {
+		return b.ttlbs
+	}
+	return newCoarseHistogram()
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of ten, for readable axis labels.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	pow := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if v <= m*pow {
+			return m * pow
+		}
+	}
+	return 10 * pow
+}
+
+// formatTick formats an axis value with as few digits as it needs.
+func formatTick(v float64) string {
+	switch {
+	case v >= 100 || v == 0:
+		return fmt.Sprintf("%.0f", v)
+	case v >= 10:
+		return fmt.Sprintf("%.1f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// formatOffset formats seconds since the start of the run as an axis label.
+func formatOffset(secs float64) string {
+	return (time.Duration(secs) * time.Second).String()
+}
+
+// htmlLatencyRow is one row of the percentile table of the HTML report.
+type htmlLatencyRow struct {
+	Operation string
+	Metric    string
+	Total     int64
+	Success   int64
+	MiBps     float64
+	Latency   *LatencySummary
+}
+
+// htmlReport is what the HTML report template renders.
+type htmlReport struct {
+	Generated  string
+	Summary    Summary
+	Latencies  []htmlLatencyRow
+	Throughput template.HTML
+	Requests   template.HTML
+	Latency    template.HTML
+	Heatmap    template.HTML
+	Errors     template.HTML
+}
+
+// latencyRows returns the percentile table rows for the operations the run performed.
+func latencyRows(sum Summary) []htmlLatencyRow {
+	var rows []htmlLatencyRow
+	add := func(name string, op *OperationSummary) {
+		if op == nil || op.Total == 0 {
+			return
+		}
+		if op.TTFB != nil {
+			rows = append(rows, htmlLatencyRow{name, "TTFB", op.Total, op.Success, op.ThroughputMiB, op.TTFB})
+		}
+		rows = append(rows, htmlLatencyRow{name, "TTLB", op.Total, op.Success, op.ThroughputMiB, op.TTLB})
+	}
+	add("GET", &sum.Get)
+	add("PUT", &sum.Put)
+	add("HEAD", sum.Head)
+	add("LIST", sum.List)
+	add("COPY", sum.Copy)
+	add("TAGGING", sum.Tagging)
+	add("DELETE", sum.Delete)
+	return rows
+}
+
+// WriteHTMLReport writes a calculated Stats as a self-contained HTML page: the headline
+// figures, a percentile table and charts of throughput, request rate, latency, the
+// latency distribution and errors over time. The charts are inline SVG, so the page
+// needs no network access and can be mailed around as a single file.
+func WriteHTMLReport(w io.Writer, s *Stats) error {
+	points := s.TimeSeries()
+	xs := make([]float64, len(points))
+	mibps := make([]float64, len(points))
+	rps := make([]float64, len(points))
+	errs := make([]float64, len(points))
+	p50 := make([]float64, len(points))
+	p99 := make([]float64, len(points))
+	for i, pt := range points {
+		xs[i], mibps[i], rps[i], errs[i], p99[i] = pt.Offset, pt.MiBPerS, pt.RequestsPerS, pt.ErrorsPerS, pt.P99TTLBMs
+		p50[i] = ms(s.intervalLatencies(pt).Percentile(50))
+	}
+
+	report := htmlReport{
+		Generated:  time.Now().Format(time.RFC1123),
+		Summary:    s.Summary(),
+		Throughput: lineChart(xs, "", chartSeries{"MiB/s", "#1f77b4", mibps}),
+		Requests:   lineChart(xs, "", chartSeries{"requests/s", "#2ca02c", rps}),
+		Latency:    lineChart(xs, "ms", chartSeries{"P50 TTLB", "#1f77b4", p50}, chartSeries{"P99 TTLB", "#d62728", p99}),
+		Heatmap:    s.latencyHeatmap(points),
+		Errors:     barChart(xs, "", "#d62728", errs),
+	}
+	report.Latencies = latencyRows(report.Summary)
+	if err := htmlReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTMLReportFile writes the HTML report to path, "-" for stdout.
+func WriteHTMLReportFile(s *Stats, path string) error {
+	out, err := OpenOutput(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteHTMLReport(out, s); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"f2":         func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"statusText": StatusText,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ostresser report{{with .Summary.RunID}} {{.}}{{end}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1000px; color: #222; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: 0.2em; }
+.meta { color: #666; }
+.headline { display: flex; flex-wrap: wrap; gap: 1em; margin: 1.5em 0; }
+.headline div { background: #f5f7fa; border-radius: 6px; padding: 0.6em 1em; min-width: 8em; }
+.headline b { display: block; font-size: 1.4em; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { padding: 0.3em 0.6em; text-align: right; border-bottom: 1px solid #eee; }
+th:first-child, td:first-child, th:nth-child(2), td:nth-child(2) { text-align: left; }
+th { background: #f5f7fa; }
+.chart { width: 100%; height: auto; }
+.chart .axis { stroke: #888; }
+.chart .grid { stroke: #eee; }
+.chart .tick { font-size: 11px; fill: #666; }
+.errors { color: #d62728; }
+</style>
+</head>
+<body>
+<h1>ostresser report{{with .Summary.RunID}} <span class="meta">{{.}}</span>{{end}}</h1>
+<div class="meta">Generated {{.Generated}}</div>
+
+<div class="headline">
+<div>Duration<b>{{f2 .Summary.DurationSeconds}} s</b></div>
+<div>Concurrency<b>{{.Summary.Concurrency}}</b></div>
+<div>Requests<b>{{.Summary.TotalRequests}}</b></div>
+<div>Requests/s<b>{{f2 .Summary.RequestsPerSec}}</b></div>
+<div>Errors<b{{if .Summary.TotalErrors}} class="errors"{{end}}>{{.Summary.TotalErrors}}</b></div>
+</div>
+
+{{if .Latencies}}
+<h2>Latency percentiles (ms)</h2>
+<table>
+<tr><th>Operation</th><th>Metric</th><th>Total</th><th>Success</th><th>MiB/s</th><th>Min</th><th>Avg</th><th>P50</th><th>P90</th><th>P99</th><th>P99.9</th><th>Max</th></tr>
+{{range .Latencies}}<tr><td>{{.Operation}}</td><td>{{.Metric}}</td><td>{{.Total}}</td><td>{{.Success}}</td><td>{{f2 .MiBps}}</td>
+{{with .Latency}}<td>{{f2 .Min}}</td><td>{{f2 .Avg}}</td><td>{{f2 .P50}}</td><td>{{f2 .P90}}</td><td>{{f2 .P99}}</td><td>{{f2 .P999}}</td><td>{{f2 .Max}}</td>{{else}}<td colspan="7">no successful requests</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+
+{{if .Throughput}}
+<h2>Throughput (MiB/s)</h2>
+{{.Throughput}}
+<h2>Requests per second</h2>
+{{.Requests}}
+<h2>Latency over time</h2>
+{{.Latency}}
+{{end}}
+{{if .Heatmap}}
+<h2>Latency heatmap (TTLB)</h2>
+{{.Heatmap}}
+{{end}}
+
+{{if .Summary.TotalErrors}}
+<h2>Errors per second</h2>
+{{.Errors}}
+{{with .Summary.ErrorsByClass}}
+<table>
+<tr><th>Operation</th><th>Class</th><th>Count</th><th>Per second</th><th>% of requests</th></tr>
+{{range .}}<tr><td>{{.Operation}}</td><td>{{.Class}}</td><td>{{.Count}}</td><td>{{f2 .PerSec}}</td><td>{{f2 .Percent}}</td></tr>
+{{end}}</table>
+{{end}}
+{{end}}
+
+{{with .Summary.StatusCodes}}
+<h2>HTTP status codes</h2>
+<table>
+<tr><th>Operation</th><th>Status</th><th>Count</th><th>% of requests</th></tr>
+{{range .}}<tr><td>{{.Operation}}</td><td>{{statusText .Status}}</td><td>{{.Count}}</td><td>{{f2 .Percent}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
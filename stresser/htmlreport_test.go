@@ -0,0 +1,54 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	var results []Result
+	for i := range 30 {
+		ts := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		results = append(results, Result{Timestamp: ts, Operation: "GET", BytesDownloaded: 1 << 20, TTFB: time.Millisecond, TTLB: time.Duration(1+i%5) * time.Millisecond})
+	}
+	results = append(results, Result{Timestamp: start.Add(2 * time.Second), Operation: "PUT", Error: "SlowDown: please reduce your request rate", TTFB: -1, TTLB: -1})
+	stats := NewStats()
+	stats.RunID = "run-<42>"
+	stats.CalculateFromResults(results)
+
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, stats); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "Latency percentiles", "<td>GET</td><td>TTFB</td>", "Latency heatmap", "Errors per second", "<polyline", "run-&lt;42&gt;"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Report missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script") || strings.Contains(page, "<link") {
+		t.Error("Expected a self-contained report without scripts or linked resources")
+	}
+
+	// A run without results still renders, without charts
+	buf.Reset()
+	empty := NewStats()
+	empty.Calculate(start, start)
+	if err := WriteHTMLReport(&buf, empty); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "<svg") {
+		t.Error("Expected no charts for an empty run")
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	for v, want := range map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 7: 10, 12: 20, 180: 200, 4500: 5000} {
+		if got := niceCeil(v); got != want {
+			t.Errorf("niceCeil(%v) = %v, want %v", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// HTTP protocol selection for the S3 client (-http-version).
+const (
+	HTTPVersionAuto = "auto" // HTTP/2 when the server offers it in the TLS handshake, HTTP/1.1 otherwise
+	HTTPVersion1    = "1.1"  // HTTP/1.1 only
+	HTTPVersion2    = "2"    // HTTP/2 only: over TLS, or unencrypted with prior knowledge (h2c) for http:// endpoints
+)
+
+// applyHTTPVersion restricts the protocols transport speaks to version. With HTTP/2 the
+// requests of all workers are multiplexed over few connections per host, so MaxConnsPerHost
+// and the idle pool limits matter less.
+func applyHTTPVersion(transport *http.Transport, version string) {
+	var protocols http.Protocols
+	switch version {
+	case HTTPVersion1:
+		protocols.SetHTTP1(true)
+	case HTTPVersion2:
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return
+	}
+	transport.Protocols = &protocols
+}
+
+// ProtocolStats aggregates results of requests sent over one HTTP protocol version.
+type ProtocolStats struct {
+	Protocol    string
+	Requests    int64
+	Errors      int64
+	Connections int64 // New connections opened for requests of this protocol
+	P50TTLB     time.Duration
+	P99TTLB     time.Duration
+
+	ttlbs *Histogram // Latencies of successful requests
+}
+
+// addProtocolResult records r against the protocol its response came over. Called from AddResult.
+func (s *Stats) addProtocolResult(r Result) {
+	if r.Protocol == "" {
+		return
+	}
+	if s.protocols == nil {
+		s.protocols = make(map[string]*ProtocolStats)
+	}
+	ps, ok := s.protocols[r.Protocol]
+	if !ok {
+		ps = &ProtocolStats{Protocol: r.Protocol, ttlbs: NewHistogram()}
+		s.protocols[r.Protocol] = ps
+	}
+	ps.Requests++
+	if !r.ConnReused && r.RemoteAddr != "" {
+		ps.Connections++
+	}
+	if r.Error != "" {
+		ps.Errors++
+		return
+	}
+	ps.ttlbs.Record(r.TTLB)
+}
+
+// calculateProtocolStats computes per-protocol latency figures. Called from Calculate.
+func (s *Stats) calculateProtocolStats() {
+	for _, ps := range s.protocols {
+		if ps.ttlbs.Count() == 0 {
+			continue
+		}
+		ps.P50TTLB = ps.ttlbs.Percentile(50)
+		ps.P99TTLB = ps.ttlbs.Percentile(99)
+	}
+}
+
+// ProtocolStats returns the per-protocol aggregates, HTTP/1.1 first.
+func (s *Stats) ProtocolStats() []*ProtocolStats {
+	list := make([]*ProtocolStats, 0, len(s.protocols))
+	for _, ps := range s.protocols {
+		list = append(list, ps)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Protocol < list[j].Protocol })
+	return list
+}
+
+// printProtocolSummary prints per-protocol figures as part of PrintSummary. It is shown when
+// both protocols were used or a version was selected explicitly.
+func (s *Stats) printProtocolSummary(w io.Writer) {
+	selected := s.HTTPVersion == HTTPVersion1 || s.HTTPVersion == HTTPVersion2
+	if len(s.protocols) == 0 || (len(s.protocols) == 1 && !selected) {
+		return
+	}
+	fmt.Fprintf(w, "\nHTTP Protocols:\n")
+	fmt.Fprintf(w, "  Protocol | Requests | Errors | Conns  | P50 (ms) | P99 (ms)\n")
+	fmt.Fprintf(w, "  ---------|----------|--------|--------|----------|----------\n")
+	for _, ps := range s.ProtocolStats() {
+		fmt.Fprintf(w, "  %-8s | %8d | %6d | %6d | %8.2f | %8.2f\n",
+			ps.Protocol, ps.Requests, ps.Errors, ps.Connections, ms(ps.P50TTLB), ms(ps.P99TTLB))
+	}
+}
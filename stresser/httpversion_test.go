@@ -0,0 +1,79 @@
+package stresser
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyHTTPVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = &protocols
+	srv.Start()
+	defer srv.Close()
+
+	for version, want := range map[string]string{HTTPVersionAuto: "HTTP/1.1", HTTPVersion1: "HTTP/1.1", HTTPVersion2: "HTTP/2.0"} {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		applyHTTPVersion(transport, version)
+		resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		resp.Body.Close()
+		if resp.Proto != want {
+			t.Errorf("%s: got %s, expected %s", version, resp.Proto, want)
+		}
+		transport.CloseIdleConnections()
+	}
+}
+
+func TestValidateHTTPVersion(t *testing.T) {
+	base := Config{Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "read"}
+	for version, want := range map[string]string{"": HTTPVersionAuto, "AUTO": HTTPVersionAuto, "1.1": HTTPVersion1, "2": HTTPVersion2} {
+		cfg := base
+		cfg.HTTPVersion = version
+		if err := cfg.Validate(); err != nil || cfg.HTTPVersion != want {
+			t.Errorf("%q: got %q (%v), expected %q", version, cfg.HTTPVersion, err, want)
+		}
+	}
+	cfg := base
+	cfg.HTTPVersion = "3"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for HTTP/3")
+	}
+}
+
+func TestProtocolStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: 10 * time.Millisecond, Protocol: "HTTP/2.0", RemoteAddr: "10.0.0.1:443"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: 20 * time.Millisecond, Protocol: "HTTP/2.0", RemoteAddr: "10.0.0.1:443", ConnReused: true})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: -1, Error: "StatusCode: 503", Protocol: "HTTP/1.1", RemoteAddr: "10.0.0.2:443"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: -1, Error: "dial failed"}) // No response
+	stats.Calculate(now, now.Add(time.Second))
+
+	protocols := stats.ProtocolStats()
+	if len(protocols) != 2 {
+		t.Fatalf("expected 2 protocols, got %d", len(protocols))
+	}
+	h1, h2 := protocols[0], protocols[1]
+	if h1.Protocol != "HTTP/1.1" || h1.Requests != 1 || h1.Errors != 1 || h1.Connections != 1 {
+		t.Errorf("unexpected HTTP/1.1 stats: %+v", h1)
+	}
+	if h2.Protocol != "HTTP/2.0" || h2.Requests != 2 || h2.Connections != 1 || h2.P99TTLB != 20*time.Millisecond {
+		t.Errorf("unexpected HTTP/2.0 stats: %+v", h2)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "HTTP Protocols:") {
+		t.Errorf("Summary lacks the protocol table:\n%s", buf.String())
+	}
+}
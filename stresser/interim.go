@@ -0,0 +1,47 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// notifyInterimSummary returns a channel for Runner.InterimSummary that receives a value
+// whenever the process gets SIGUSR1, and a function that stops the notifications. Signals
+// arriving while a summary is still pending are coalesced. On platforms without SIGUSR1
+// the channel never receives.
+func notifyInterimSummary() (<-chan struct{}, func()) {
+	requests := make(chan struct{}, 1)
+	if len(interimSummarySignals) == 0 {
+		return requests, func() {}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, interimSummarySignals...)
+	go func() {
+		for range signals {
+			select {
+			case requests <- struct{}{}:
+			default: // A summary is already pending
+			}
+		}
+	}()
+	return requests, func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
+
+// writeInterimSummary calculates s over the results collected between measureStart and now
+// and prints its summary to w. The run continues; the final Calculate replaces the figures.
+func writeInterimSummary(w io.Writer, s *Stats, measureStart, now time.Time) {
+	if now.Before(measureStart) {
+		fmt.Fprintf(w, "\n--- Interim Summary: still warming up, measurement starts in %s ---\n", measureStart.Sub(now).Round(time.Second))
+		return
+	}
+	s.Calculate(measureStart, now)
+	fmt.Fprintf(w, "\n=== Interim Summary after %s, the test continues ===\n", now.Sub(measureStart).Round(time.Second))
+	s.PrintSummary(w)
+	fmt.Fprintf(w, "=== End of Interim Summary ===\n")
+}
@@ -0,0 +1,67 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterimCalculate(t *testing.T) {
+	start := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: start, Operation: "PUT", BytesUploaded: 1024, TTFB: -1, TTLB: 3 * time.Millisecond})
+
+	var buf bytes.Buffer
+	writeInterimSummary(&buf, stats, start, start.Add(time.Second))
+	if !strings.Contains(buf.String(), "Interim Summary after 1s") || !strings.Contains(buf.String(), "Total Requests: 1") {
+		t.Errorf("Unexpected interim summary:\n%s", buf.String())
+	}
+
+	// The interim Calculate zeroed the GET minimums; the first GET must still set them
+	stats.AddResult(Result{Timestamp: start, Operation: "GET", BytesDownloaded: 1024, TTFB: 5 * time.Millisecond, TTLB: 8 * time.Millisecond})
+	stats.Calculate(start, start.Add(2*time.Second))
+	if stats.MinGetTTFB != 5*time.Millisecond || stats.MinGetTTLB != 8*time.Millisecond || stats.MinPutTTLB != 3*time.Millisecond {
+		t.Errorf("Unexpected minimums GET TTFB %v TTLB %v, PUT %v", stats.MinGetTTFB, stats.MinGetTTLB, stats.MinPutTTLB)
+	}
+
+	buf.Reset()
+	writeInterimSummary(&buf, stats, start.Add(time.Minute), start)
+	if !strings.Contains(buf.String(), "still warming up") {
+		t.Errorf("Expected a warm-up notice, got:\n%s", buf.String())
+	}
+}
+
+func TestRunnerInterimSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	cfg := &Config{
+		Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret",
+		Duration: "200ms", Concurrency: 2, OutputFile: "unused.csv", OperationType: "write", PutObjectSizeKB: 1,
+		Progress: "0",
+	}
+	runner, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	requests := make(chan struct{}, 1)
+	requests <- struct{}{}
+	var progress bytes.Buffer
+	runner.Progress = &progress
+	runner.InterimSummary = requests
+	if _, _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(progress.String(), "Interim Summary") {
+		t.Errorf("Expected an interim summary in the progress output, got:\n%s", progress.String())
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package stresser
+
+import "os"
+
+// interimSummarySignals is empty on this platform, which has no SIGUSR1.
+var interimSummarySignals []os.Signal
@@ -0,0 +1,11 @@
+//go:build unix
+
+package stresser
+
+import (
+	"os"
+	"syscall"
+)
+
+// interimSummarySignals request an interim summary from a running test.
+var interimSummarySignals = []os.Signal{syscall.SIGUSR1}
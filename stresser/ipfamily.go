@@ -0,0 +1,142 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"time"
+)
+
+// IP family selection for connections (-ip-family).
+const (
+	IPFamilyBoth = "both" // Dial whatever the resolver returns, racing families (happy eyeballs)
+	IPFamilyV4   = "ipv4"
+	IPFamilyV6   = "ipv6"
+)
+
+// familyDialContext restricts dial to one IP family by narrowing the network passed to it.
+func familyDialContext(family string, dial dialFunc) dialFunc {
+	var suffix string
+	switch family {
+	case IPFamilyV4:
+		suffix = "4"
+	case IPFamilyV6:
+		suffix = "6"
+	default:
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network == "tcp" {
+			network += suffix
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// familyMatches reports whether ip can be dialed on network ("tcp", "tcp4" or "tcp6").
+func familyMatches(network, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	switch network {
+	case "tcp4":
+		return parsed.To4() != nil
+	case "tcp6":
+		return parsed.To4() == nil
+	}
+	return true
+}
+
+// addrFamily returns "IPv4" or "IPv6" for a host:port address, or "" if it can't be parsed.
+func addrFamily(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return "IPv4"
+	default:
+		return "IPv6"
+	}
+}
+
+// FamilyStats aggregates results of requests sent over one IP family.
+type FamilyStats struct {
+	Family      string
+	Requests    int64
+	Errors      int64
+	Connections int64 // New connections opened over this family
+	P50TTLB     time.Duration
+	P99TTLB     time.Duration
+
+	ttlbs *Histogram // Latencies of successful requests
+}
+
+// addFamilyResult records r against the IP family of the connection it used. Called from AddResult.
+func (s *Stats) addFamilyResult(r Result) {
+	family := addrFamily(r.RemoteAddr)
+	if family == "" {
+		return
+	}
+	if s.families == nil {
+		s.families = make(map[string]*FamilyStats)
+	}
+	fs, ok := s.families[family]
+	if !ok {
+		fs = &FamilyStats{Family: family, ttlbs: NewHistogram()}
+		s.families[family] = fs
+	}
+	fs.Requests++
+	if !r.ConnReused {
+		fs.Connections++
+	}
+	if r.Error != "" {
+		fs.Errors++
+		return
+	}
+	fs.ttlbs.Record(r.TTLB)
+}
+
+// calculateFamilyStats computes per-family latency figures. Called from Calculate.
+func (s *Stats) calculateFamilyStats() {
+	for _, fs := range s.families {
+		if fs.ttlbs.Count() == 0 {
+			continue
+		}
+		fs.P50TTLB = fs.ttlbs.Percentile(50)
+		fs.P99TTLB = fs.ttlbs.Percentile(99)
+	}
+}
+
+// FamilyStats returns the per-family aggregates, IPv4 first.
+func (s *Stats) FamilyStats() []*FamilyStats {
+	list := make([]*FamilyStats, 0, len(s.families))
+	for _, fs := range s.families {
+		list = append(list, fs)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Family < list[j].Family })
+	return list
+}
+
+// printFamilySummary prints per-family figures as part of PrintSummary. It is shown when
+// both families were used or a family was selected explicitly.
+func (s *Stats) printFamilySummary(w io.Writer) {
+	restricted := s.IPFamily == IPFamilyV4 || s.IPFamily == IPFamilyV6
+	if len(s.families) == 0 || (len(s.families) == 1 && !restricted) {
+		return
+	}
+	fmt.Fprintf(w, "\nIP Families:\n")
+	fmt.Fprintf(w, "  Family | Requests | Errors | Conns  | P50 (ms) | P99 (ms)\n")
+	fmt.Fprintf(w, "  -------|----------|--------|--------|----------|----------\n")
+	for _, fs := range s.FamilyStats() {
+		fmt.Fprintf(w, "  %-6s | %8d | %6d | %6d | %8.2f | %8.2f\n",
+			fs.Family, fs.Requests, fs.Errors, fs.Connections, ms(fs.P50TTLB), ms(fs.P99TTLB))
+	}
+}
@@ -0,0 +1,65 @@
+package stresser
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFamilyDialContext(t *testing.T) {
+	var networks []string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		networks = append(networks, network)
+		return nil, nil
+	}
+	familyDialContext(IPFamilyV4, dial)(context.Background(), "tcp", "s3.example.com:443")
+	familyDialContext(IPFamilyV6, dial)(context.Background(), "tcp", "s3.example.com:443")
+	familyDialContext(IPFamilyBoth, dial)(context.Background(), "tcp", "s3.example.com:443")
+
+	if strings.Join(networks, ",") != "tcp4,tcp6,tcp" {
+		t.Errorf("unexpected networks %v", networks)
+	}
+}
+
+func TestDNSCacheFamilyFilter(t *testing.T) {
+	cache := newDNSCache(time.Hour)
+	cache.entries["s3.example.com"] = &dnsEntry{addrs: []string{"2001:db8::1", "10.0.0.1"}, resolved: time.Now()}
+
+	var dialed []string
+	dial := cache.dialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, nil
+	})
+	dial(context.Background(), "tcp4", "s3.example.com:443")
+	dial(context.Background(), "tcp6", "s3.example.com:443")
+
+	expected := []string{"10.0.0.1:443", "[2001:db8::1]:443"}
+	if strings.Join(dialed, ",") != strings.Join(expected, ",") {
+		t.Errorf("dialed %v, expected %v", dialed, expected)
+	}
+}
+
+func TestFamilyStats(t *testing.T) {
+	stats := NewStats()
+	stats.IPFamily = IPFamilyBoth
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: 10 * time.Millisecond, RemoteAddr: "10.0.0.1:443"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: 20 * time.Millisecond, RemoteAddr: "10.0.0.1:443", ConnReused: true})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: -1, Error: "reset", RemoteAddr: "[2001:db8::1]:443"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTLB: -1, Error: "dial failed"}) // No connection
+	stats.Calculate(now, now.Add(time.Second))
+
+	families := stats.FamilyStats()
+	if len(families) != 2 {
+		t.Fatalf("expected 2 families, got %d", len(families))
+	}
+	v4, v6 := families[0], families[1]
+	if v4.Family != "IPv4" || v4.Requests != 2 || v4.Connections != 1 || v4.P99TTLB != 20*time.Millisecond {
+		t.Errorf("unexpected IPv4 stats: %+v", v4)
+	}
+	if v6.Family != "IPv6" || v6.Requests != 1 || v6.Errors != 1 {
+		t.Errorf("unexpected IPv6 stats: %+v", v6)
+	}
+}
@@ -0,0 +1,129 @@
+package stresser
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Key distributions for reads (-distribution).
+const (
+	KeyDistSequential = "sequential"
+	KeyDistUniform    = "uniform"
+	KeyDistZipf       = "zipf"
+	KeyDistWeighted   = "weighted"
+)
+
+// keyDistribution describes how workers pick manifest keys, parsed from a spec such as
+// "sequential", "uniform" or "zipf:<s>":
+//   - sequential  every worker walks the manifest in order, starting at its own offset
+//   - uniform     every key is equally likely (same as -r)
+//   - zipf:<s>    key i is picked with probability proportional to 1/(i+1)^s, so the first
+//     keys of the manifest get most reads; s must be greater than 1
+//   - weighted    every key is picked with probability proportional to its manifest weight
+type keyDistribution struct {
+	kind string
+	s    float64 // Zipf exponent
+}
+
+// parseKeyDistribution parses a key distribution spec. An empty spec is sequential, or
+// uniform if randomize (-r) is set.
+func parseKeyDistribution(spec string, randomize bool) (keyDistribution, error) {
+	if spec == "" {
+		if randomize {
+			return keyDistribution{kind: KeyDistUniform}, nil
+		}
+		return keyDistribution{kind: KeyDistSequential}, nil
+	}
+	kind, value, found := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+	switch kind {
+	case KeyDistSequential, KeyDistUniform, KeyDistWeighted:
+		if found {
+			return keyDistribution{}, fmt.Errorf("invalid key distribution %q: %s takes no parameter", spec, kind)
+		}
+		return keyDistribution{kind: kind}, nil
+	case KeyDistZipf:
+		if !found {
+			return keyDistribution{}, fmt.Errorf("invalid key distribution %q: expected zipf:<exponent>, e.g. zipf:1.1", spec)
+		}
+		s, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || s <= 1 {
+			return keyDistribution{}, fmt.Errorf("invalid key distribution %q: zipf exponent must be a number greater than 1", spec)
+		}
+		return keyDistribution{kind: kind, s: s}, nil
+	}
+	return keyDistribution{}, fmt.Errorf("invalid key distribution %q: unknown distribution %q (use sequential, uniform, zipf:<s> or weighted)", spec, kind)
+}
+
+// String renders the distribution in the same form it was parsed from.
+func (d keyDistribution) String() string {
+	if d.kind == KeyDistZipf {
+		return fmt.Sprintf("%s:%g", d.kind, d.s)
+	}
+	return d.kind
+}
+
+// keyWeights are the cumulative read weights of the manifest keys, shared by all workers.
+type keyWeights struct {
+	cumulative []float64
+}
+
+// newKeyWeights returns the cumulative form of weights, nil if there are none. Weights
+// must be positive.
+func newKeyWeights(weights []float64) *keyWeights {
+	if len(weights) == 0 {
+		return nil
+	}
+	w := &keyWeights{cumulative: make([]float64, len(weights))}
+	var total float64
+	for i, weight := range weights {
+		total += weight
+		w.cumulative[i] = total
+	}
+	return w
+}
+
+// pick returns a key index with probability proportional to its weight.
+func (w *keyWeights) pick(r *rand.Rand) int {
+	x := r.Float64() * w.cumulative[len(w.cumulative)-1]
+	i := sort.Search(len(w.cumulative), func(i int) bool { return w.cumulative[i] > x })
+	return min(i, len(w.cumulative)-1) // Guards against rounding at the very top
+}
+
+// keyPicker returns manifest indexes for one worker following a keyDistribution.
+type keyPicker struct {
+	dist    keyDistribution
+	n       int
+	next    int // Next index for sequential access
+	r       *rand.Rand
+	zipf    *rand.Zipf
+	weights *keyWeights // Weighted access
+}
+
+// newPicker creates a picker over n keys using the worker's random source. Sequential
+// pickers start at offset so workers spread over the manifest. Weighted pickers need the
+// weights of the n keys.
+func (d keyDistribution) newPicker(r *rand.Rand, n, offset int, weights *keyWeights) *keyPicker {
+	p := &keyPicker{dist: d, n: n, next: offset % max(n, 1), r: r, weights: weights}
+	if d.kind == KeyDistZipf && n > 0 {
+		p.zipf = rand.NewZipf(r, d.s, 1, uint64(n-1))
+	}
+	return p
+}
+
+// pick returns the index of the next key to access. n must be greater than 0.
+func (p *keyPicker) pick() int {
+	switch p.dist.kind {
+	case KeyDistUniform:
+		return p.r.Intn(p.n)
+	case KeyDistZipf:
+		return int(p.zipf.Uint64())
+	case KeyDistWeighted:
+		return p.weights.pick(p.r)
+	}
+	i := p.next % p.n
+	p.next++
+	return i
+}
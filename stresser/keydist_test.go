@@ -0,0 +1,81 @@
+package stresser
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParseKeyDistribution(t *testing.T) {
+	tests := []struct {
+		spec        string
+		randomize   bool
+		expectError bool
+		expected    string
+	}{
+		{spec: "", expected: "sequential"},
+		{spec: "", randomize: true, expected: "uniform"},
+		{spec: "Uniform", expected: "uniform"},
+		{spec: "zipf:1.1", randomize: true, expected: "zipf:1.1"},
+		{spec: "zipf", expectError: true},
+		{spec: "zipf:1", expectError: true},
+		{spec: "zipf:abc", expectError: true},
+		{spec: "uniform:2", expectError: true},
+		{spec: "Weighted", expected: "weighted"},
+		{spec: "weighted:2", expectError: true},
+		{spec: "gaussian", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			d, err := parseKeyDistribution(tt.spec, tt.randomize)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseKeyDistribution(%q) error = %v, expectError %v", tt.spec, err, tt.expectError)
+			}
+			if !tt.expectError && d.String() != tt.expected {
+				t.Errorf("parseKeyDistribution(%q) = %q, expected %q", tt.spec, d.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestKeyPickerSequential(t *testing.T) {
+	d, _ := parseKeyDistribution("", false)
+	p := d.newPicker(rand.New(rand.NewSource(1)), 3, 1, nil)
+	for i, expected := range []int{1, 2, 0, 1} {
+		if got := p.pick(); got != expected {
+			t.Errorf("Pick %d: expected index %d, got %d", i, expected, got)
+		}
+	}
+}
+
+func TestKeyPickerZipf(t *testing.T) {
+	d, _ := parseKeyDistribution("zipf:1.5", false)
+	p := d.newPicker(rand.New(rand.NewSource(1)), 1000, 0, nil)
+	counts := make([]int, 1000)
+	for i := 0; i < 10000; i++ {
+		idx := p.pick()
+		if idx < 0 || idx >= 1000 {
+			t.Fatalf("Index %d out of range", idx)
+		}
+		counts[idx]++
+	}
+	// With s=1.5 the first key alone gets roughly 38% of all picks
+	if counts[0] < 3000 || counts[0] <= counts[1] || counts[1] <= counts[10] {
+		t.Errorf("Expected reads concentrated on the first keys, got %d, %d, %d", counts[0], counts[1], counts[10])
+	}
+}
+
+func TestKeyPickerWeighted(t *testing.T) {
+	d, err := parseKeyDistribution("weighted", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := d.newPicker(rand.New(rand.NewSource(1)), 3, 0, newKeyWeights([]float64{1, 8, 1}))
+	counts := make([]int, 3)
+	for i := 0; i < 10000; i++ {
+		counts[p.pick()]++
+	}
+	// The second key carries 80% of the weight
+	if counts[1] < 7600 || counts[1] > 8400 || counts[0] == 0 || counts[2] == 0 {
+		t.Errorf("Expected picks proportional to the weights 1:8:1, got %v", counts)
+	}
+}
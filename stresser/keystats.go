@@ -0,0 +1,56 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteReport describes the objects successful PUTs left behind, for reasoning about
+// space consumption and verifying cleanup. Only writes made during this run are known:
+// overwriting an object that existed before the run counts as a new object.
+type WriteReport struct {
+	UniqueObjects int64 `json:"uniqueObjects" yaml:"uniqueObjects"` // Distinct keys written, i.e. objects expected to exist after the run
+	Overwrites    int64 `json:"overwrites" yaml:"overwrites"`       // PUTs to a key already written during this run
+	NetNewBytes   int64 `json:"netNewBytes" yaml:"netNewBytes"`     // Size of the objects expected to exist after the run
+}
+
+// addWrittenKey records a successful PUT so overwrites can be detected. Called from AddResult
+// with TrackWrites only, as it keeps every key written.
+func (s *Stats) addWrittenKey(r Result) {
+	if r.Operation != "PUT" || r.Error != "" {
+		return
+	}
+	if s.writtenKeys == nil {
+		s.writtenKeys = make(map[string]int64)
+	}
+	if prev, ok := s.writtenKeys[r.ObjectKey]; ok {
+		s.overwrites++
+		s.netNewBytes -= prev // The older version is replaced
+	}
+	s.writtenKeys[r.ObjectKey] = r.BytesUploaded
+	s.netNewBytes += r.BytesUploaded
+}
+
+// Writes returns the write summary, or nil if no PUT succeeded or TrackWrites is off.
+func (s *Stats) Writes() *WriteReport {
+	if len(s.writtenKeys) == 0 {
+		return nil
+	}
+	return &WriteReport{
+		UniqueObjects: int64(len(s.writtenKeys)),
+		Overwrites:    s.overwrites,
+		NetNewBytes:   s.netNewBytes,
+	}
+}
+
+// printWriteSummary prints the write summary as part of PrintSummary.
+func (s *Stats) printWriteSummary(w io.Writer) {
+	ws := s.Writes()
+	if ws == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nObjects Written:\n")
+	fmt.Fprintf(w, "  Unique Keys:    %d (expected object count after the run)\n", ws.UniqueObjects)
+	fmt.Fprintf(w, "  Overwrites:     %d\n", ws.Overwrites)
+	fmt.Fprintf(w, "  Net New Data:   %.2f MiB\n", float64(ws.NetNewBytes)/(1024*1024))
+}
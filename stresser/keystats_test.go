@@ -0,0 +1,40 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReport(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "x", TTLB: time.Millisecond, BytesUploaded: 100})
+	if stats.Writes() != nil {
+		t.Fatal("expected no write report without TrackWrites")
+	}
+
+	stats = NewStats()
+	stats.TrackWrites = true
+	if stats.Writes() != nil {
+		t.Fatal("expected no write report before any PUT")
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "a", TTLB: time.Millisecond, BytesUploaded: 100})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "b", TTLB: time.Millisecond, BytesUploaded: 100})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "a", TTLB: time.Millisecond, BytesUploaded: 300})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "c", TTLB: -1, Error: "failed"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "d", TTLB: time.Millisecond, BytesDownloaded: 50})
+
+	ws := stats.Writes()
+	if ws.UniqueObjects != 2 || ws.Overwrites != 1 || ws.NetNewBytes != 400 {
+		t.Errorf("unexpected write report: %+v", ws)
+	}
+
+	stats.Calculate(now, now.Add(time.Second))
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Overwrites:     1") {
+		t.Errorf("summary lacks overwrite count:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,147 @@
+package stresser
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key templates used for generated keys when -key-template is not set. They put the keys of
+// every run under a prefix of its own, see RunPrefix.
+const (
+	defaultWorkerKeyTemplate    = "stresser/{run}/worker{worker}/{ts}-{rand}.dat"
+	defaultGeneratorKeyTemplate = "stresser/{run}/generated/{seq}-{rand}.dat"
+	defaultCopyKeyTemplate      = "stresser/{run}/copy/worker{worker}/{ts}-{rand}.dat"
+)
+
+// RunPrefix returns the prefix the default key templates put the keys of a run under,
+// e.g. "stresser/20250101T120000-3fa9/", for cleaning up exactly one run.
+func RunPrefix(runID string) string {
+	return "stresser/" + runID + "/"
+}
+
+// workerKeyTemplate returns the template of the keys workers write when -key-template is
+// not set: copies go to their own directory.
+func workerKeyTemplate(operationType string) string {
+	if operationType == "copy" {
+		return defaultCopyKeyTemplate
+	}
+	return defaultWorkerKeyTemplate
+}
+
+// keyTemplate generates object keys for PUTs from a template such as
+// "bench/{date}/{worker}/{seq}-{rand}". Placeholders:
+//   - {worker}    index of the worker making the PUT
+//   - {seq}       sequence number: PUTs made by the worker so far, or the file number with -files
+//   - {rand}      8 random alphanumeric characters, {rand:N} for N characters
+//   - {shard:N}   random number below N, zero-padded, to spread keys over exactly N prefixes
+//   - {ts}        Unix time in nanoseconds
+//   - {date}      UTC date as YYYY-MM-DD, {hour} UTC hour as HH
+//   - {run}       the run id
+type keyTemplate []keyTemplatePart
+
+// keyTemplatePart is either literal text or a placeholder with an optional parameter.
+type keyTemplatePart struct {
+	literal string
+	field   string
+	n       int
+}
+
+// keyVars are the per-PUT values substituted into a key template.
+type keyVars struct {
+	worker int
+	seq    int64
+	run    string
+}
+
+// parseKeyTemplate parses a key template. An empty spec returns the default template.
+func parseKeyTemplate(spec, defaultSpec string) (keyTemplate, error) {
+	if spec == "" {
+		spec = defaultSpec
+	}
+	var t keyTemplate
+	rest := spec
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t = append(t, keyTemplatePart{literal: rest})
+			break
+		}
+		if open > 0 {
+			t = append(t, keyTemplatePart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid key template %q: unterminated placeholder", spec)
+		}
+		part, err := parseKeyPlaceholder(rest[open+1 : open+end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key template %q: %w", spec, err)
+		}
+		t = append(t, part)
+		rest = rest[open+end+1:]
+	}
+	if strings.HasPrefix(t.render(rand.New(rand.NewSource(1)), keyVars{}), "/") {
+		return nil, fmt.Errorf("invalid key template %q: keys must not start with '/'", spec)
+	}
+	return t, nil
+}
+
+func parseKeyPlaceholder(placeholder string) (keyTemplatePart, error) {
+	field, param, hasParam := strings.Cut(placeholder, ":")
+	part := keyTemplatePart{field: field}
+	switch field {
+	case "worker", "seq", "ts", "date", "hour", "run":
+		if hasParam {
+			return part, fmt.Errorf("{%s} takes no parameter", field)
+		}
+	case "rand", "shard":
+		if !hasParam {
+			if field == "shard" {
+				return part, fmt.Errorf("{shard:N} needs the number of shards")
+			}
+			part.n = 8
+			break
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 {
+			return part, fmt.Errorf("{%s:%s} needs a positive number", field, param)
+		}
+		part.n = n
+	default:
+		return part, fmt.Errorf("unknown placeholder {%s}", placeholder)
+	}
+	return part, nil
+}
+
+// render returns the key for one PUT.
+func (t keyTemplate) render(r *rand.Rand, v keyVars) string {
+	var b strings.Builder
+	now := time.Now()
+	for _, p := range t {
+		switch p.field {
+		case "":
+			b.WriteString(p.literal)
+		case "worker":
+			b.WriteString(strconv.Itoa(v.worker))
+		case "seq":
+			b.WriteString(strconv.FormatInt(v.seq, 10))
+		case "rand":
+			b.WriteString(randomString(p.n, r))
+		case "shard":
+			width := len(strconv.Itoa(p.n - 1))
+			fmt.Fprintf(&b, "%0*d", width, r.Intn(p.n))
+		case "ts":
+			b.WriteString(strconv.FormatInt(now.UnixNano(), 10))
+		case "date":
+			b.WriteString(now.UTC().Format("2006-01-02"))
+		case "hour":
+			b.WriteString(now.UTC().Format("15"))
+		case "run":
+			b.WriteString(v.run)
+		}
+	}
+	return b.String()
+}
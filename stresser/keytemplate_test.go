@@ -0,0 +1,43 @@
+package stresser
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyTemplate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tmpl, err := parseKeyTemplate("bench/{date}/{shard:16}/w{worker}/{seq}-{rand:4}-{run}", defaultWorkerKeyTemplate)
+	if err != nil {
+		t.Fatalf("parseKeyTemplate failed: %v", err)
+	}
+	key := tmpl.render(r, keyVars{worker: 3, seq: 42, run: "r1"})
+	date := time.Now().UTC().Format("2006-01-02")
+	if !regexp.MustCompile(`^bench/` + date + `/(0\d|1[0-5])/w3/42-[a-zA-Z0-9]{4}-r1$`).MatchString(key) {
+		t.Errorf("Unexpected key %q", key)
+	}
+
+	def, err := parseKeyTemplate("", defaultGeneratorKeyTemplate)
+	if err != nil {
+		t.Fatalf("parseKeyTemplate failed: %v", err)
+	}
+	if key := def.render(r, keyVars{seq: 7, run: "r1"}); !regexp.MustCompile(`^stresser/r1/generated/7-[a-zA-Z0-9]{8}\.dat$`).MatchString(key) {
+		t.Errorf("Unexpected default key %q", key)
+	}
+	// The default keys of every run are under its own prefix
+	for _, spec := range []string{defaultWorkerKeyTemplate, defaultGeneratorKeyTemplate, defaultCopyKeyTemplate} {
+		def, _ := parseKeyTemplate("", spec)
+		if key := def.render(r, keyVars{run: "r1"}); !strings.HasPrefix(key, RunPrefix("r1")) {
+			t.Errorf("Expected %q under the run prefix %s", key, RunPrefix("r1"))
+		}
+	}
+
+	for _, spec := range []string{"a/{nope}", "a/{rand", "{shard}", "{shard:0}", "{worker:2}", "/{rand}"} {
+		if _, err := parseKeyTemplate(spec, defaultWorkerKeyTemplate); err == nil {
+			t.Errorf("Expected error for template %q", spec)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DefaultListPageSize is the number of keys requested per ListObjectsV2 page, the S3 maximum.
+const DefaultListPageSize = 1000
+
+// performListOperation fetches one ListObjectsV2 page and measures how long it took.
+// token continues a listing; it returns the token for the next page, empty once the
+// listing is complete or failed, so the caller starts over.
+func performListOperation(ctx context.Context, s3Client S3ClientAPI, bucket, prefix string, pageSize int, token string) (Result, string) {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "LIST",
+		ObjectKey: prefix,
+		TTFB:      -1, // Not measured, the page is parsed before the SDK returns
+		TTLB:      -1,
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		MaxKeys: aws.Int32(int32(pageSize)),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if token != "" {
+		input.ContinuationToken = aws.String(token)
+	}
+
+	reqStartTime := time.Now()
+	resp, err := s3Client.ListObjectsV2(traceConnection(ctx, &result, reqStartTime), input)
+	if err != nil {
+		result.Error = err.Error()
+		recordErrorRequestID(&result, err)
+		return result, ""
+	}
+	recordResponseMetadata(&result, resp.ResultMetadata)
+	result.TTLB = time.Since(reqStartTime)
+	result.Keys = len(resp.Contents)
+
+	if aws.ToBool(resp.IsTruncated) {
+		return result, aws.ToString(resp.NextContinuationToken)
+	}
+	return result, ""
+}
+
+// printListSummary prints per-page latency and listing rate as part of PrintSummary.
+func (s *Stats) printListSummary(w io.Writer) {
+	if s.TotalLists == 0 {
+		return
+	}
+	h := s.ListTTLBHist
+	fmt.Fprintf(w, "\nLIST Operations (%d pages):\n", s.TotalLists)
+	fmt.Fprintf(w, "  Success:        %d\n", h.Count())
+	if h.Count() == 0 {
+		fmt.Fprintln(w, "  No successful LISTs to calculate latency.")
+		return
+	}
+	keysPerSec := float64(0)
+	if s.actualDuration.Seconds() > 0 {
+		keysPerSec = float64(s.TotalListKeys) / s.actualDuration.Seconds()
+	}
+	fmt.Fprintf(w, "  Keys Listed:    %d (%.2f keys/s, %.1f keys/page)\n", s.TotalListKeys, keysPerSec, float64(s.TotalListKeys)/float64(h.Count()))
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	fmt.Fprintf(w, "  Page          |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+		ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Percentile(99.9)), ms(h.Max()))
+}
+
+// listSummary returns the LIST figures for Summary, nil if no page was listed.
+func (s *Stats) listSummary() *OperationSummary {
+	if s.TotalLists == 0 {
+		return nil
+	}
+	h := s.ListTTLBHist
+	sum := &OperationSummary{Total: s.TotalLists, Success: h.Count(), Keys: s.TotalListKeys}
+	if secs := s.actualDuration.Seconds(); secs > 0 {
+		sum.KeysPerSec = float64(s.TotalListKeys) / secs
+	}
+	if h.Count() > 0 {
+		sum.TTLB = &LatencySummary{ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Max()), ms(h.Percentile(99.9))}
+	}
+	return sum
+}
@@ -0,0 +1,80 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// pagingS3Client lists keys in pages of MaxKeys, using the next key index as continuation token.
+type pagingS3Client struct {
+	stubS3Client
+	keys []string
+	last *s3.ListObjectsV2Input
+}
+
+func (c *pagingS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	c.last = params
+	start := 0
+	if params.ContinuationToken != nil {
+		fmt.Sscan(*params.ContinuationToken, &start)
+	}
+	end := min(start+int(aws.ToInt32(params.MaxKeys)), len(c.keys))
+	out := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(end < len(c.keys))}
+	for _, k := range c.keys[start:end] {
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(k)})
+	}
+	if end < len(c.keys) {
+		out.NextContinuationToken = aws.String(fmt.Sprint(end))
+	}
+	return out, nil
+}
+
+func TestPerformListOperation(t *testing.T) {
+	client := &pagingS3Client{keys: []string{"a/1", "a/2", "a/3", "a/4", "a/5"}}
+	ctx := context.Background()
+	stats := NewStats()
+
+	var pages []int
+	token := ""
+	for {
+		var result Result
+		result, token = performListOperation(ctx, client, "bucket", "a/", 2, token)
+		if result.Error != "" || result.Operation != "LIST" || result.TTLB < 0 {
+			t.Fatalf("Unexpected result: %+v", result)
+		}
+		stats.AddResult(result)
+		pages = append(pages, result.Keys)
+		if token == "" {
+			break
+		}
+	}
+	if fmt.Sprint(pages) != "[2 2 1]" {
+		t.Errorf("Expected pages of [2 2 1] keys, got %v", pages)
+	}
+	if aws.ToString(client.last.Prefix) != "a/" || aws.ToInt32(client.last.MaxKeys) != 2 {
+		t.Errorf("Unexpected request: prefix %q, max keys %d", aws.ToString(client.last.Prefix), aws.ToInt32(client.last.MaxKeys))
+	}
+
+	start := time.Now()
+	stats.Calculate(start, start.Add(time.Second))
+	if stats.TotalLists != 3 || stats.TotalListKeys != 5 {
+		t.Errorf("Expected 3 pages and 5 keys, got %d and %d", stats.TotalLists, stats.TotalListKeys)
+	}
+	sum := stats.Summary()
+	if sum.List == nil || sum.List.Keys != 5 || sum.List.KeysPerSec != 5 || sum.List.TTLB == nil {
+		t.Errorf("Unexpected LIST summary: %+v", sum.List)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "LIST Operations (3 pages)") || !strings.Contains(buf.String(), "5.00 keys/s") {
+		t.Errorf("Summary lacks LIST section:\n%s", buf.String())
+	}
+}
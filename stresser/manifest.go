@@ -2,35 +2,139 @@ package stresser
 
 import (
 	"bufio"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"strconv"
 	"strings" // Import the strings package
 	"sync"
 )
 
+// ManifestEntry is a manifest line: an object key, optionally followed by a tab and the
+// id of a specific version of the object, and optionally by a tab and the relative weight
+// of the key for weighted reads ("key\tweight" or "key\tversionId\tweight"). Extended
+// CSV and JSON manifests can also carry the expected size and ETag of the object.
+type ManifestEntry struct {
+	Key       string
+	VersionID string  // Empty for unversioned entries
+	Weight    float64 // Relative read weight, 0 if the line has none
+	Size      int64   // Expected object size in bytes, 0 if unknown (extended manifests only)
+	ETag      string  // Expected ETag, or MD5 of the content, empty if unknown (extended manifests only)
+}
+
+// String returns the manifest line of e, without the newline.
+func (e ManifestEntry) String() string {
+	line := e.Key
+	if e.VersionID != "" {
+		line += "\t" + e.VersionID
+	}
+	if e.Weight > 0 {
+		line += "\t" + strconv.FormatFloat(e.Weight, 'g', -1, 64)
+	}
+	return line
+}
+
+// parseManifestLine parses a trimmed, non-empty manifest line. A second field that is a
+// number is a weight, anything else a version id.
+func parseManifestLine(line string) (ManifestEntry, error) {
+	fields := strings.Split(line, "\t")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	e := ManifestEntry{Key: fields[0]}
+	var weight string
+	switch len(fields) {
+	case 1:
+		return e, nil
+	case 2:
+		if _, err := strconv.ParseFloat(fields[1], 64); err != nil {
+			e.VersionID = fields[1]
+			return e, nil
+		}
+		weight = fields[1]
+	case 3:
+		e.VersionID, weight = fields[1], fields[2]
+	default:
+		return e, fmt.Errorf("expected at most 3 tab-separated fields (key, version id, weight), got %d", len(fields))
+	}
+	w, err := strconv.ParseFloat(weight, 64)
+	if err != nil || w <= 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+		return e, fmt.Errorf("invalid weight %q: must be a positive number", weight)
+	}
+	e.Weight = w
+	return e, nil
+}
+
 // LoadManifest reads object keys from the specified file path.
-// It skips empty lines and trims whitespace from each key.
+// It skips empty lines and trims whitespace from each key. Version ids of versioned
+// entries are dropped, so every mode but 'versioned' reads the latest version.
 func LoadManifest(filePath string) ([]string, error) {
+	entries, err := LoadManifestEntries(filePath)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	return keys, nil
+}
+
+// splitManifest returns the keys of entries and their read weights. The weights are nil if
+// no entry has one, otherwise keys without a weight get 1.
+func splitManifest(entries []ManifestEntry) ([]string, []float64) {
+	keys := make([]string, len(entries))
+	var weights []float64
+	for i, e := range entries {
+		keys[i] = e.Key
+		if e.Weight == 0 {
+			continue
+		}
+		if weights == nil {
+			weights = make([]float64, len(entries))
+			for j := range weights {
+				weights[j] = 1
+			}
+		}
+		weights[i] = e.Weight
+	}
+	return keys, weights
+}
+
+// manifestExpectations returns entries if any of them has an expected size or ETag for
+// reads to validate, nil otherwise.
+func manifestExpectations(entries []ManifestEntry) []ManifestEntry {
+	for _, e := range entries {
+		if e.Size > 0 || e.ETag != "" {
+			return entries
+		}
+	}
+	return nil
+}
+
+// LoadManifestEntries reads the manifest at filePath including the version ids of
+// "key\tversionId" lines and the weights of weighted lines. Paths ending in .csv and
+// .json/.jsonl are read as extended manifests (see readCSVManifest and readJSONManifest).
+func LoadManifestEntries(filePath string) ([]ManifestEntry, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open manifest file %s: %w", filePath, err)
 	}
 	defer file.Close() // Ensure file is closed
 
-	var keys []string
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		// Basic trim, potentially add more validation if needed
-		if trimmed := strings.TrimSpace(line); trimmed != "" {
-			keys = append(keys, trimmed)
-		}
+	var keys []ManifestEntry
+	switch manifestFormat(filePath) {
+	case FormatCSV:
+		keys, err = readCSVManifest(file)
+	case FormatJSONL:
+		keys, err = readJSONManifest(file)
+	default:
+		keys, err = readTextManifest(file)
 	}
-
-	// Check for errors during scanning (e.g., read errors)
-	if err := scanner.Err(); err != nil {
+	if err != nil {
 		return nil, fmt.Errorf("error reading manifest file %s: %w", filePath, err)
 	}
 
@@ -42,11 +146,35 @@ func LoadManifest(filePath string) ([]string, error) {
 	return keys, nil
 }
 
-// ManifestWriter allows for concurrent writing to a manifest file
+// readTextManifest reads a plain manifest with one key per line.
+func readTextManifest(r io.Reader) ([]ManifestEntry, error) {
+	var keys []ManifestEntry
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		// Basic trim, potentially add more validation if needed
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			entry, err := parseManifestLine(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			keys = append(keys, entry)
+		}
+	}
+	// Check for errors during scanning (e.g., read errors)
+	return keys, scanner.Err()
+}
+
+// ManifestWriter allows for concurrent writing to a manifest file. Paths ending in .csv
+// and .json/.jsonl get an extended manifest with the sizes and ETags of the entries.
 type ManifestWriter struct {
 	filePath string
 	file     *os.File
 	writer   *bufio.Writer
+	format   string      // manifestFormat of filePath
+	csv      *csv.Writer // FormatCSV
 	mu       sync.Mutex
 }
 
@@ -58,20 +186,46 @@ func NewManifestWriter(filePath string) (*ManifestWriter, error) {
 		return nil, fmt.Errorf("failed to create manifest file %s: %w", filePath, err)
 	}
 
-	return &ManifestWriter{
+	mw := &ManifestWriter{
 		filePath: filePath,
 		file:     file,
 		writer:   bufio.NewWriter(file),
-	}, nil
+		format:   manifestFormat(filePath),
+	}
+	if mw.format == FormatCSV {
+		mw.csv = csv.NewWriter(mw.writer)
+		if err := mw.csv.Write(manifestCSVHeader); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write manifest header: %w", err)
+		}
+		mw.csv.Flush()
+	}
+	return mw, nil
 }
 
 // AddKey adds a key to the manifest file
 func (mw *ManifestWriter) AddKey(key string) error {
+	return mw.AddEntry(ManifestEntry{Key: key})
+}
+
+// AddEntry adds a key, and its version id if it has one, to the manifest file. Extended
+// manifests also get its size and ETag.
+func (mw *ManifestWriter) AddEntry(e ManifestEntry) error {
 	mw.mu.Lock()
 	defer mw.mu.Unlock()
 
-	// Write the key with a newline
-	_, err := mw.writer.WriteString(key + "\n")
+	var err error
+	switch mw.format {
+	case FormatCSV:
+		mw.csv.Write(e.csvRow())
+		mw.csv.Flush()
+		err = mw.csv.Error()
+	case FormatJSONL:
+		err = json.NewEncoder(mw.writer).Encode(e.record())
+	default:
+		// Write the key with a newline
+		_, err = mw.writer.WriteString(e.String() + "\n")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write key to manifest: %w", err)
 	}
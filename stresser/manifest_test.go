@@ -186,3 +186,72 @@ func TestManifestWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadManifestEntries(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "versions.txt")
+	writer, err := NewManifestWriter(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []ManifestEntry{{Key: "a", VersionID: "v1"}, {Key: "a", VersionID: "v2"}, {Key: "b"}} {
+		if err := writer.AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadManifestEntries(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[1] != (ManifestEntry{Key: "a", VersionID: "v2"}) || entries[2] != (ManifestEntry{Key: "b"}) {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	keys, err := LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "a,a,b" {
+		t.Errorf("Expected LoadManifest to drop the version ids, got %v", keys)
+	}
+}
+
+func TestManifestWeights(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "weights.txt")
+	os.WriteFile(manifestPath, []byte("hot.dat\t50\ncold.dat\nwarm.dat\tv1\t2.5\nnull.dat\tnull\n"), 0644)
+
+	entries, err := LoadManifestEntries(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, weights := splitManifest(entries)
+	if strings.Join(keys, ",") != "hot.dat,cold.dat,warm.dat,null.dat" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	if len(weights) != 4 || weights[0] != 50 || weights[1] != 1 || weights[2] != 2.5 || weights[3] != 1 {
+		t.Errorf("Expected weights 50, 1, 2.5, 1, got %v", weights)
+	}
+	if entries[2] != (ManifestEntry{Key: "warm.dat", VersionID: "v1", Weight: 2.5}) || entries[3].VersionID != "null" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	if entries[0].String() != "hot.dat\t50" || entries[2].String() != "warm.dat\tv1\t2.5" {
+		t.Errorf("Entries don't round-trip: %q, %q", entries[0].String(), entries[2].String())
+	}
+
+	// Manifests without weights don't need them
+	os.WriteFile(manifestPath, []byte("a\nb\tv1\n"), 0644)
+	entries, err = LoadManifestEntries(manifestPath)
+	if _, weights := splitManifest(entries); err != nil || weights != nil {
+		t.Errorf("Expected no weights, got %v (%v)", weights, err)
+	}
+
+	for _, content := range []string{"a\t0\n", "a\t-1\n", "a\tv1\theavy\n", "a\tv1\t1\textra\n"} {
+		os.WriteFile(manifestPath, []byte(content), 0644)
+		if _, err := LoadManifestEntries(manifestPath); err == nil {
+			t.Errorf("Expected an error for manifest %q", content)
+		}
+	}
+}
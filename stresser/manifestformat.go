@@ -0,0 +1,183 @@
+package stresser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// manifestFormat returns the format of the manifest at path from its extension: FormatCSV
+// and FormatJSONL for extended manifests, FormatText for plain key lists.
+func manifestFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json", ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return FormatText
+}
+
+// manifestRecord is an entry of a JSON manifest.
+type manifestRecord struct {
+	Key       string  `json:"key"`
+	VersionID string  `json:"versionId,omitempty"`
+	Size      int64   `json:"size,omitempty"`
+	ETag      string  `json:"etag,omitempty"`
+	MD5       string  `json:"md5,omitempty"` // Alternative to ETag, the hex MD5 of the content
+	Weight    float64 `json:"weight,omitempty"`
+}
+
+// record returns e as an entry of a JSON manifest.
+func (e ManifestEntry) record() manifestRecord {
+	return manifestRecord{Key: e.Key, VersionID: e.VersionID, Size: e.Size, ETag: e.ETag, Weight: e.Weight}
+}
+
+// manifestCSVHeader is the header row of the CSV manifests ManifestWriter writes.
+var manifestCSVHeader = []string{"key", "size", "etag", "versionId", "weight"}
+
+// csvRow returns e as a row of a CSV manifest, with empty fields for unknown values.
+func (e ManifestEntry) csvRow() []string {
+	row := []string{e.Key, "", e.ETag, e.VersionID, ""}
+	if e.Size > 0 {
+		row[1] = strconv.FormatInt(e.Size, 10)
+	}
+	if e.Weight > 0 {
+		row[4] = strconv.FormatFloat(e.Weight, 'g', -1, 64)
+	}
+	return row
+}
+
+// entry validates r and returns it as a ManifestEntry.
+func (r manifestRecord) entry() (ManifestEntry, error) {
+	e := ManifestEntry{Key: strings.TrimSpace(r.Key), VersionID: r.VersionID, Size: r.Size, ETag: r.ETag, Weight: r.Weight}
+	if e.ETag == "" {
+		e.ETag = r.MD5
+	}
+	if e.Key == "" {
+		return e, fmt.Errorf("missing key")
+	}
+	if e.Size < 0 {
+		return e, fmt.Errorf("invalid size %d: must not be negative", e.Size)
+	}
+	if e.Weight < 0 || math.IsInf(e.Weight, 0) || math.IsNaN(e.Weight) {
+		return e, fmt.Errorf("invalid weight %v: must be a positive number", e.Weight)
+	}
+	return e, nil
+}
+
+// readCSVManifest reads a CSV manifest. The header row names the columns: "key" is
+// required, "size", "etag" (or "md5"), "versionId" and "weight" are optional and other
+// columns are ignored, so exports of other tools can be used as they are.
+func readCSVManifest(r io.Reader) ([]ManifestEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["key"]; !ok {
+		return nil, fmt.Errorf("CSV manifest has no key column in its header %q", strings.Join(header, ","))
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var entries []ManifestEntry
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rec := manifestRecord{Key: field(row, "key"), VersionID: field(row, "versionid"), ETag: field(row, "etag"), MD5: field(row, "md5")}
+		if rec.Key == "" {
+			continue // Skipped like the blank lines of plain manifests
+		}
+		if size := field(row, "size"); size != "" {
+			if rec.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid size %q", line, size)
+			}
+		}
+		if weight := field(row, "weight"); weight != "" {
+			if rec.Weight, err = strconv.ParseFloat(weight, 64); err != nil || rec.Weight == 0 {
+				return nil, fmt.Errorf("line %d: invalid weight %q: must be a positive number", line, weight)
+			}
+		}
+		e, err := rec.entry()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// readJSONManifest reads a JSON manifest: a JSON array of manifestRecord objects, or one
+// object per line.
+func readJSONManifest(r io.Reader) ([]ManifestEntry, error) {
+	br := bufio.NewReader(r)
+	var records []manifestRecord
+	first, err := firstNonSpace(br)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		if err := dec.Decode(&records); err != nil {
+			return nil, err
+		}
+	} else {
+		for {
+			var rec manifestRecord
+			if err := dec.Decode(&rec); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+			}
+			records = append(records, rec)
+		}
+	}
+	entries := make([]ManifestEntry, len(records))
+	for i, rec := range records {
+		if entries[i], err = rec.entry(); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	return entries, nil
+}
+
+// firstNonSpace returns the first byte of br that isn't white space without consuming it.
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, br.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package stresser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtendedManifests(t *testing.T) {
+	dir := t.TempDir()
+	want := []ManifestEntry{
+		{Key: "a.dat", Size: 1024, ETag: `"9e107d9d372bb6826bd81d3542a419d6"`},
+		{Key: "b.dat", Size: 2048, ETag: "e4d909c290d0fb1ca068ffaddf22cbd0", Weight: 3},
+		{Key: "c.dat", VersionID: "v1"},
+	}
+	for name, content := range map[string]string{
+		"keys.csv": "Key,Size,ETag,LastModified,Weight,VersionId\n" +
+			`a.dat,1024,"""9e107d9d372bb6826bd81d3542a419d6""",2024-01-01,,` + "\n" +
+			"b.dat,2048,e4d909c290d0fb1ca068ffaddf22cbd0,2024-01-01,3,\n\n" +
+			"c.dat,,,,,v1\n",
+		"keys.jsonl": `{"key":"a.dat","size":1024,"etag":"\"9e107d9d372bb6826bd81d3542a419d6\""}` + "\n" +
+			`{"key":"b.dat","size":2048,"md5":"e4d909c290d0fb1ca068ffaddf22cbd0","weight":3}` + "\n" +
+			`{"key":"c.dat","versionId":"v1"}` + "\n",
+		"keys.json": ` [{"key":"a.dat","size":1024,"etag":"\"9e107d9d372bb6826bd81d3542a419d6\""},
+			{"key":"b.dat","size":2048,"etag":"e4d909c290d0fb1ca068ffaddf22cbd0","weight":3},
+			{"key":"c.dat","versionId":"v1"}]`,
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		entries, err := LoadManifestEntries(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(entries) != len(want) {
+			t.Fatalf("%s: expected %d entries, got %+v", name, len(want), entries)
+		}
+		for i := range want {
+			if entries[i] != want[i] {
+				t.Errorf("%s: entry %d is %+v, expected %+v", name, i, entries[i], want[i])
+			}
+		}
+		if manifestExpectations(entries) == nil {
+			t.Errorf("%s: expected the entries to carry expectations", name)
+		}
+	}
+
+	for name, content := range map[string]string{
+		"nokey.csv":    "name,size\na.dat,1\n",
+		"size.csv":     "key,size\na.dat,big\n",
+		"weight.csv":   "key,weight\na.dat,0\n",
+		"negative.csv": "key,size\na.dat,-1\n",
+		"nokey.jsonl":  `{"size":1}`,
+		"broken.jsonl": `{"key":"a.dat"`,
+		"empty.json":   "[]",
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := LoadManifestEntries(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestManifestFormat(t *testing.T) {
+	for path, want := range map[string]string{"keys.txt": FormatText, "keys": FormatText, "keys.CSV": FormatCSV, "keys.json": FormatJSONL, "keys.ndjson": FormatJSONL} {
+		if got := manifestFormat(path); got != want {
+			t.Errorf("%s: got %q, expected %q", path, got, want)
+		}
+	}
+}
@@ -1,61 +1,168 @@
 package stresser
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
-	"os"
 	"sort"
 	"sync"
 	"time"
 )
 
-// Result holds the metrics for a single S3 operation (GET or PUT).
+// Result holds the metrics for a single S3 operation (GET, PUT, HEAD, LIST, COPY, TAGGING or DELETE).
 type Result struct {
 	Timestamp       time.Time
-	Operation       string // "GET" or "PUT"
+	Operation       string // "GET", "PUT", "HEAD", "LIST", "COPY", "TAGGING" or "DELETE"
+	Bucket          string // Bucket of the request in multi-bucket runs, empty otherwise
+	RunID           string // Run that made the request (see Config.RunID)
+	Group           string // Scenario group of the request in 'scenario' mode, empty otherwise
+	Endpoint        string // Endpoint of the request in multi-endpoint runs, empty otherwise
 	ObjectKey       string
+	VersionID       string        // Version written by a PUT to a versioned bucket, or read or deleted in 'versioned' mode
+	ETag            string        // ETag of the object returned by a GET or HEAD, or written by a PUT, empty otherwise
 	TTFB            time.Duration // GET: Time To First Byte (proxy: time until headers received) | PUT: N/A (-1)
+	BodyTTFB        time.Duration // GET: time until the first byte of the body was read, 0 for empty bodies
+	Windows         int           // GET: full throughput windows the body was read over, 0 for faster bodies
+	MinWindowMiBps  float64       // GET: throughput of the slowest of those windows
 	TTLB            time.Duration // GET: Time To Last Byte (body read) | PUT: Time until PutObject returns
-	BytesDownloaded int64         // Bytes read for GET
+	BytesDownloaded int64         // Bytes read for GET, as sent on the wire
+	BytesDecoded    int64         // GET: size of a compressed body after decoding it, 0 if the body was not compressed
 	BytesUploaded   int64         // Bytes written for PUT
+	BytesCopied     int64         // Bytes copied server-side by COPY
 	Error           string        // Empty if successful
+	RequestID       string        // S3 request id (x-amz-request-id), empty if none was returned
+	HostID          string        // S3 extended request id (x-amz-id-2), empty if none was returned
+	StatusCode      int           // HTTP status of the response, 0 if none was received or the backend doesn't report it
+	Protocol        string        // HTTP protocol of the response, e.g. "HTTP/1.1" or "HTTP/2.0", empty if unknown
+	Attempts        int           // HTTP attempts made by the SDK including retries, 0 if unknown
+	RemoteAddr      string        // Server address of the connection used for the last attempt
+	ConnReused      bool          // Whether that connection came from the idle pool
+	ConnWait        time.Duration // Time until a connection was obtained (includes DNS, dial and TLS for new connections)
+	DNS             time.Duration // New connections: DNS lookup time, 0 if reused or not resolved
+	Connect         time.Duration // New connections: TCP connect time, 0 if reused
+	TLSHandshake    time.Duration // New connections: TLS handshake time, 0 if reused or plain HTTP
+	FirstByte       time.Duration // Time until the first response byte arrived, 0 if none did
+	Hedged          bool          // GET sent a second, speculative request because the first was slow
+	HedgeWon        bool          // The speculative request answered first
+	Integrity       string        // GET with -verify: IntegrityOK, IntegrityCorrupt or IntegrityUnchecked
+	Checksum        string        // GET: ChecksumOK or ChecksumMismatch if the response carried an x-amz-checksum-*, empty otherwise
+	Overwrite       string        // Successful PUT with -write-mode overwrite: OverwriteNew or OverwriteReplaced
+	Disconnect      string        // PUT cut by -disconnect-at: DisconnectAborted or DisconnectVisible
+	Append          string        // Successful PUT of 'append' mode: AppendCreated, AppendAppended or AppendRolled
+	RMW             time.Duration // Successful PUT of 'append' mode: from the start of the GET of the log until the PUT completed
+	Conditional     string        // Successful conditional GET ('revalidate' mode): ConditionalNotModified or ConditionalModified | conditional PUT ('contention' mode): ConditionalWon, ConditionalPreconditionFailed or ConditionalConflict
+	Keys            int           // LIST: keys returned in the page | TAGGING: tags returned
+	Consistency     string        // Read-after-write probe ('consistency' mode): ConsistencyMatch, ConsistencyStale, ConsistencyMissing or ConsistencyFailed
+	ProbeDelay      time.Duration // Read-after-write probe: when it was due after the PUT completed
+	VisibleAfter    time.Duration // Last probe of an object: time from the PUT until a probe first saw the content, -1 if none did, 0 on other results
+	Warmup          bool          // Started during the warm-up period, excluded from stats
+	IntendedStart   time.Time     // With a target rate: when the operation was due to start, zero otherwise
 }
 
 // Stats aggregates results from multiple operations.
 type Stats struct {
-	TotalRequests  int64
-	TotalGets      int64
-	TotalPuts      int64
-	TotalErrors    int64
-	TotalBytesDown int64
-	TotalBytesUp   int64
-	Concurrency    int             // Number of concurrent workers used in the test
-	GetTTFBs       []time.Duration // Latencies only for successful GETs
-	GetTTLBs       []time.Duration // Latencies only for successful GETs
-	PutTTLBs       []time.Duration // Latencies only for successful PUTs (TTLB represents full PUT duration)
-	MinGetTTFB     time.Duration
-	MaxGetTTFB     time.Duration
-	AvgGetTTFB     time.Duration
-	P50GetTTFB     time.Duration
-	P90GetTTFB     time.Duration
-	P99GetTTFB     time.Duration
-	MinGetTTLB     time.Duration
-	MaxGetTTLB     time.Duration
-	AvgGetTTLB     time.Duration
-	P50GetTTLB     time.Duration
-	P90GetTTLB     time.Duration
-	P99GetTTLB     time.Duration
-	MinPutTTLB     time.Duration // Min time for a PUT operation
-	MaxPutTTLB     time.Duration // Max time for a PUT operation
-	AvgPutTTLB     time.Duration // Avg time for a PUT operation
-	P50PutTTLB     time.Duration
-	P90PutTTLB     time.Duration
-	P99PutTTLB     time.Duration
-	mu             sync.Mutex // Protects updates if AddResult were concurrent (currently sequential)
-	startTime      time.Time
-	endTime        time.Time
-	actualDuration time.Duration
+	TotalRequests    int64
+	TotalGets        int64
+	TotalPuts        int64
+	TotalHeads       int64
+	TotalLists       int64 // LIST pages requested
+	TotalListKeys    int64 // Keys returned by successful LIST pages
+	TotalCopies      int64
+	TotalTaggings    int64 // GetObjectTagging requests
+	TotalDeletes     int64 // DeleteObject requests ('versioned' mode)
+	TotalErrors      int64
+	TotalBytesDown   int64
+	TotalBytesObject int64 // Bytes of the objects successful GETs read, after decoding compressed bodies
+	EncodedGets      int64 // Successful GETs whose body was compressed (see Config.AcceptEncoding)
+	TotalBytesUp     int64
+	TotalBytesCopied int64              // Bytes copied server-side by successful COPYs
+	RunID            string             // Identifier of the run (see Config.RunID)
+	Concurrency      int                // Number of concurrent workers used in the test
+	Agents           int                // Agents that generated the load in a distributed run (0 for a local run)
+	PrefixDepth      int                // Key path segments used to group per-prefix stats (0 disables)
+	OutlierCount     int                // Number of slowest requests retained with full context (0 disables)
+	TrackWrites      bool               // Remember the written keys for the write report, see addWrittenKey
+	IPFamily         string             // IP family connections were restricted to ("both" if not restricted)
+	HTTPVersion      string             // HTTP version the client was restricted to ("auto" if not restricted)
+	ThinkTime        string             // Distribution of the pause of each worker between operations (empty for none)
+	SLOs             map[string]float64 // Thresholds evaluated by SLOResults (see Config.SLOs)
+	Client           *ClientReport      // Load generator health during the run (nil if not monitored)
+	NIC              *NICReport         // Host network interface throughput (nil unless an interface was set)
+	ScalingEvents    []ScalingEvent     // Active worker count changes made by the CPU autoscaler
+	Tuning           *TuningReport      // Outcome of -target-p99 (nil without it)
+	Failover         *FailoverReport    // Endpoint failover measurements (nil unless a secondary endpoint was set)
+	Arrivals         *ArrivalReport     // Open-loop arrival counts (nil in closed-loop runs)
+	GetTTFBHist      *Histogram         // Latencies only for successful GETs
+	GetBodyTTFBHist  *Histogram         // First body byte of successful GETs with a body
+	GetTTLBHist      *Histogram         // Latencies only for successful GETs
+	PutTTLBHist      *Histogram         // Latencies only for successful PUTs (TTLB represents full PUT duration)
+	HeadTTLBHist     *Histogram         // Latencies only for successful HEADs
+	ListTTLBHist     *Histogram         // Per-page latencies only for successful LISTs
+	CopyTTLBHist     *Histogram         // Latencies only for successful COPYs
+	TaggingTTLBHist  *Histogram         // Latencies only for successful GetObjectTagging requests
+	DeleteTTLBHist   *Histogram         // Latencies only for successful DeleteObject requests
+	MinGetTTFB       time.Duration
+	MaxGetTTFB       time.Duration
+	AvgGetTTFB       time.Duration
+	P50GetTTFB       time.Duration
+	P90GetTTFB       time.Duration
+	P99GetTTFB       time.Duration
+	P999GetTTFB      time.Duration
+	MinGetTTLB       time.Duration
+	MaxGetTTLB       time.Duration
+	AvgGetTTLB       time.Duration
+	P50GetTTLB       time.Duration
+	P90GetTTLB       time.Duration
+	P99GetTTLB       time.Duration
+	P999GetTTLB      time.Duration
+	MinPutTTLB       time.Duration // Min time for a PUT operation
+	MaxPutTTLB       time.Duration // Max time for a PUT operation
+	AvgPutTTLB       time.Duration // Avg time for a PUT operation
+	P50PutTTLB       time.Duration
+	P90PutTTLB       time.Duration
+	P99PutTTLB       time.Duration
+	P999PutTTLB      time.Duration
+	MinHeadTTLB      time.Duration
+	MaxHeadTTLB      time.Duration
+	AvgHeadTTLB      time.Duration
+	P50HeadTTLB      time.Duration
+	P90HeadTTLB      time.Duration
+	P99HeadTTLB      time.Duration
+	P999HeadTTLB     time.Duration
+	mu               sync.Mutex // Protects updates if AddResult were concurrent (currently sequential)
+	startTime        time.Time
+	endTime          time.Time
+	actualDuration   time.Duration
+	prefixes         map[string]*PrefixStats     // Per-prefix aggregates, keyed by prefix
+	outliers         outlierHeap                 // Slowest results, see addOutlier
+	connSetups       *Histogram                  // Connection wait of requests that opened a new connection
+	families         map[string]*FamilyStats     // Per IP family aggregates, keyed by "IPv4"/"IPv6"
+	protocols        map[string]*ProtocolStats   // Per HTTP protocol aggregates, keyed by response protocol
+	buckets          map[string]*BucketStats     // Per-bucket aggregates of multi-bucket runs, keyed by bucket
+	groups           map[string]*GroupStats      // Per-group aggregates of scenarios, keyed by group name
+	endpoints        map[string]*EndpointStats   // Per-endpoint aggregates of multi-endpoint runs, keyed by URL
+	writtenKeys      map[string]int64            // Size of the last successful PUT per key
+	overwrites       int64                       // Successful PUTs to a key already in writtenKeys
+	netNewBytes      int64                       // Sum of writtenKeys sizes
+	hedged           int64                       // GETs that sent a hedge request
+	hedgeWon         int64                       // Hedged GETs won by the hedge request
+	stages           []*StageStats               // Per load stage aggregates, see SetStages
+	integrity        IntegrityReport             // Outcomes of verified GETs
+	checksums        ChecksumReport              // GETs checked against the store's checksum
+	revalidation     *revalidationStats          // Conditional GETs by outcome ('revalidate' mode)
+	contention       *contentionStats            // Conditional PUTs by outcome ('contention' mode)
+	overwritePuts    *overwriteStats             // PUTs by outcome with -write-mode overwrite
+	appends          *appendStats                // Read-modify-write latencies ('append' mode)
+	throughput       *throughputStats            // Per-GET throughput of sampled bodies
+	consistency      *consistencyStats           // Read-after-write probes by delay ('consistency' mode)
+	corrected        *correctedStats             // Latencies of paced operations, raw and from their intended start
+	sizeClasses      []*SizeClassStats           // Successful GETs per object size class, see sizeClasses
+	putSizeClasses   []*SizeClassStats           // Successful PUTs per object size class
+	timeSeries       map[int64]*timeSeriesBucket // Results per TimeSeriesInterval, keyed by interval number
+	errorClasses     map[string]map[string]int64 // Failed requests per operation and ClassifyError class
+	statusCodes      map[string]map[int]int64    // Requests per operation and HTTP status, see addStatusCodeResult
+	retries          RetryReport                 // SDK attempts beyond the first, see addRetryResult
+	disconnects      DisconnectReport            // PUTs cut by -disconnect-at, see addDisconnectResult
+	phases           *phaseStats                 // DNS, connect, TLS and first byte latencies, see addPhaseResult
 }
 
 // NewStats initializes a Stats object.
@@ -63,30 +170,91 @@ func NewStats() *Stats {
 	// Initialize Min values high and Max values low/negative for comparison
 	largeDuration := time.Hour * 24
 	return &Stats{
-		GetTTFBs:   make([]time.Duration, 0),
-		GetTTLBs:   make([]time.Duration, 0),
-		PutTTLBs:   make([]time.Duration, 0),
-		MinGetTTFB: largeDuration,
-		MinGetTTLB: largeDuration,
-		MinPutTTLB: largeDuration,
-		MaxGetTTFB: -1,
-		MaxGetTTLB: -1,
-		MaxPutTTLB: -1,
+		GetTTFBHist:     NewHistogram(),
+		GetBodyTTFBHist: NewHistogram(),
+		GetTTLBHist:     NewHistogram(),
+		PutTTLBHist:     NewHistogram(),
+		HeadTTLBHist:    NewHistogram(),
+		ListTTLBHist:    NewHistogram(),
+		CopyTTLBHist:    NewHistogram(),
+		TaggingTTLBHist: NewHistogram(),
+		DeleteTTLBHist:  NewHistogram(),
+		MinGetTTFB:      largeDuration,
+		MinGetTTLB:      largeDuration,
+		MinPutTTLB:      largeDuration,
+		MinHeadTTLB:     largeDuration,
+		MaxGetTTFB:      -1,
+		MaxGetTTLB:      -1,
+		MaxPutTTLB:      -1,
+		MaxHeadTTLB:     -1,
 	}
 }
 
-// AddResult incorporates a single result into the aggregate statistics.
-// This should be called sequentially after all results are collected.
+// AddResult incorporates a single result into the aggregate statistics. Calls must not be
+// concurrent; Calculate may be called in between for an interim summary. The first sample
+// of each histogram always sets the minimum, which an interim Calculate may have zeroed.
 func (s *Stats) AddResult(r Result) {
+	s.addDisconnectResult(r)
+	if r.Disconnect == DisconnectAborted {
+		return // Cut on purpose and nothing left behind: neither a successful nor a failed PUT
+	}
 	s.TotalRequests++
 	isGet := r.Operation == "GET"
 	isPut := r.Operation == "PUT"
+	isHead := r.Operation == "HEAD"
+	isList := r.Operation == "LIST"
+	isCopy := r.Operation == "COPY"
+	isTagging := r.Operation == "TAGGING"
+	isDelete := r.Operation == "DELETE"
 
 	if isGet {
 		s.TotalGets++
 	} else if isPut {
 		s.TotalPuts++
+	} else if isHead {
+		s.TotalHeads++
+	} else if isList {
+		s.TotalLists++
+	} else if isCopy {
+		s.TotalCopies++
+	} else if isTagging {
+		s.TotalTaggings++
+	} else if isDelete {
+		s.TotalDeletes++
+	}
+
+	if s.PrefixDepth > 0 {
+		s.addPrefixResult(r)
+	}
+	if s.OutlierCount > 0 {
+		s.addOutlier(r)
+	}
+	s.addConnSetup(r)
+	s.addPhaseResult(r)
+	s.addFamilyResult(r)
+	s.addProtocolResult(r)
+	s.addBucketResult(r)
+	s.addGroupResult(r)
+	s.addEndpointResult(r)
+	if s.TrackWrites {
+		s.addWrittenKey(r)
 	}
+	s.addHedgeResult(r)
+	s.addStageResult(r)
+	s.addIntegrityResult(r)
+	s.addChecksumResult(r)
+	s.addRevalidationResult(r)
+	s.addContentionResult(r)
+	s.addOverwriteResult(r)
+	s.addAppendResult(r)
+	s.addThroughputResult(r)
+	s.addConsistencyResult(r)
+	s.addCorrectedResult(r)
+	s.addSizeClassResult(r)
+	s.addTimeSeriesResult(r)
+	s.addErrorClassResult(r)
+	s.addStatusCodeResult(r)
+	s.addRetryResult(r)
 
 	if r.Error != "" {
 		s.TotalErrors++
@@ -96,16 +264,23 @@ func (s *Stats) AddResult(r Result) {
 	// Process successful requests
 	if isGet {
 		s.TotalBytesDown += r.BytesDownloaded
-		s.GetTTFBs = append(s.GetTTFBs, r.TTFB)
-		s.GetTTLBs = append(s.GetTTLBs, r.TTLB)
+		s.TotalBytesObject += r.objectBytes()
+		if r.BytesDecoded > 0 {
+			s.EncodedGets++
+		}
+		s.GetTTFBHist.Record(r.TTFB)
+		s.GetTTLBHist.Record(r.TTLB)
+		if r.BodyTTFB > 0 {
+			s.GetBodyTTFBHist.Record(r.BodyTTFB)
+		}
 
-		if r.TTFB < s.MinGetTTFB {
+		if r.TTFB < s.MinGetTTFB || s.GetTTFBHist.Count() == 1 {
 			s.MinGetTTFB = r.TTFB
 		}
 		if r.TTFB > s.MaxGetTTFB {
 			s.MaxGetTTFB = r.TTFB
 		}
-		if r.TTLB < s.MinGetTTLB {
+		if r.TTLB < s.MinGetTTLB || s.GetTTLBHist.Count() == 1 {
 			s.MinGetTTLB = r.TTLB
 		}
 		if r.TTLB > s.MaxGetTTLB {
@@ -113,14 +288,33 @@ func (s *Stats) AddResult(r Result) {
 		}
 	} else if isPut {
 		s.TotalBytesUp += r.BytesUploaded
-		s.PutTTLBs = append(s.PutTTLBs, r.TTLB) // Use TTLB for PUT duration
+		s.PutTTLBHist.Record(r.TTLB) // Use TTLB for PUT duration
 
-		if r.TTLB < s.MinPutTTLB {
+		if r.TTLB < s.MinPutTTLB || s.PutTTLBHist.Count() == 1 {
 			s.MinPutTTLB = r.TTLB
 		}
 		if r.TTLB > s.MaxPutTTLB {
 			s.MaxPutTTLB = r.TTLB
 		}
+	} else if isHead {
+		s.HeadTTLBHist.Record(r.TTLB)
+
+		if r.TTLB < s.MinHeadTTLB || s.HeadTTLBHist.Count() == 1 {
+			s.MinHeadTTLB = r.TTLB
+		}
+		if r.TTLB > s.MaxHeadTTLB {
+			s.MaxHeadTTLB = r.TTLB
+		}
+	} else if isList {
+		s.ListTTLBHist.Record(r.TTLB)
+		s.TotalListKeys += int64(r.Keys)
+	} else if isCopy {
+		s.CopyTTLBHist.Record(r.TTLB)
+		s.TotalBytesCopied += r.BytesCopied
+	} else if isTagging {
+		s.TaggingTTLBHist.Record(r.TTLB)
+	} else if isDelete {
+		s.DeleteTTLBHist.Record(r.TTLB)
 	}
 }
 
@@ -132,7 +326,7 @@ func (s *Stats) Calculate(startTime, endTime time.Time) {
 
 	// Reset unrealistic min/max if no successful operations of that type occurred
 	largeDuration := time.Hour * 24
-	if len(s.GetTTFBs) == 0 {
+	if s.GetTTFBHist.Count() == 0 {
 		if s.MinGetTTFB == largeDuration {
 			s.MinGetTTFB = 0
 		}
@@ -140,7 +334,7 @@ func (s *Stats) Calculate(startTime, endTime time.Time) {
 			s.MaxGetTTFB = 0
 		}
 	}
-	if len(s.GetTTLBs) == 0 {
+	if s.GetTTLBHist.Count() == 0 {
 		if s.MinGetTTLB == largeDuration {
 			s.MinGetTTLB = 0
 		}
@@ -148,7 +342,7 @@ func (s *Stats) Calculate(startTime, endTime time.Time) {
 			s.MaxGetTTLB = 0
 		}
 	}
-	if len(s.PutTTLBs) == 0 {
+	if s.PutTTLBHist.Count() == 0 {
 		if s.MinPutTTLB == largeDuration {
 			s.MinPutTTLB = 0
 		}
@@ -156,29 +350,55 @@ func (s *Stats) Calculate(startTime, endTime time.Time) {
 			s.MaxPutTTLB = 0
 		}
 	}
+	if s.HeadTTLBHist.Count() == 0 {
+		if s.MinHeadTTLB == largeDuration {
+			s.MinHeadTTLB = 0
+		}
+		if s.MaxHeadTTLB == -1 {
+			s.MaxHeadTTLB = 0
+		}
+	}
 
 	// Calculate GET stats
-	if len(s.GetTTFBs) > 0 {
-		sortDurations(s.GetTTFBs)
-		sortDurations(s.GetTTLBs)
-		s.AvgGetTTFB = averageDuration(s.GetTTFBs)
-		s.AvgGetTTLB = averageDuration(s.GetTTLBs)
-		s.P50GetTTFB = percentileDuration(s.GetTTFBs, 50)
-		s.P90GetTTFB = percentileDuration(s.GetTTFBs, 90)
-		s.P99GetTTFB = percentileDuration(s.GetTTFBs, 99)
-		s.P50GetTTLB = percentileDuration(s.GetTTLBs, 50)
-		s.P90GetTTLB = percentileDuration(s.GetTTLBs, 90)
-		s.P99GetTTLB = percentileDuration(s.GetTTLBs, 99)
+	if s.GetTTFBHist.Count() > 0 {
+		s.AvgGetTTFB = s.GetTTFBHist.Mean()
+		s.AvgGetTTLB = s.GetTTLBHist.Mean()
+		s.P50GetTTFB = s.GetTTFBHist.Percentile(50)
+		s.P90GetTTFB = s.GetTTFBHist.Percentile(90)
+		s.P99GetTTFB = s.GetTTFBHist.Percentile(99)
+		s.P999GetTTFB = s.GetTTFBHist.Percentile(99.9)
+		s.P50GetTTLB = s.GetTTLBHist.Percentile(50)
+		s.P90GetTTLB = s.GetTTLBHist.Percentile(90)
+		s.P99GetTTLB = s.GetTTLBHist.Percentile(99)
+		s.P999GetTTLB = s.GetTTLBHist.Percentile(99.9)
 	}
 
 	// Calculate PUT stats
-	if len(s.PutTTLBs) > 0 {
-		sortDurations(s.PutTTLBs)
-		s.AvgPutTTLB = averageDuration(s.PutTTLBs)
-		s.P50PutTTLB = percentileDuration(s.PutTTLBs, 50)
-		s.P90PutTTLB = percentileDuration(s.PutTTLBs, 90)
-		s.P99PutTTLB = percentileDuration(s.PutTTLBs, 99)
+	if s.PutTTLBHist.Count() > 0 {
+		s.AvgPutTTLB = s.PutTTLBHist.Mean()
+		s.P50PutTTLB = s.PutTTLBHist.Percentile(50)
+		s.P90PutTTLB = s.PutTTLBHist.Percentile(90)
+		s.P99PutTTLB = s.PutTTLBHist.Percentile(99)
+		s.P999PutTTLB = s.PutTTLBHist.Percentile(99.9)
+	}
+
+	// Calculate HEAD stats
+	if s.HeadTTLBHist.Count() > 0 {
+		s.AvgHeadTTLB = s.HeadTTLBHist.Mean()
+		s.P50HeadTTLB = s.HeadTTLBHist.Percentile(50)
+		s.P90HeadTTLB = s.HeadTTLBHist.Percentile(90)
+		s.P99HeadTTLB = s.HeadTTLBHist.Percentile(99)
+		s.P999HeadTTLB = s.HeadTTLBHist.Percentile(99.9)
 	}
+
+	s.calculatePrefixStats()
+	s.calculateFamilyStats()
+	s.calculateProtocolStats()
+	s.calculateBucketStats()
+	s.calculateGroupStats()
+	s.calculateEndpointStats()
+	s.calculateStageStats()
+	s.calculateSizeClassStats()
 }
 
 // --- Helper functions for stats calculation ---
@@ -246,7 +466,18 @@ func (s *Stats) PrintSummary(w io.Writer) {
 
 	fmt.Fprintf(w, "\n--- Stress Test Summary --- (%s) ---\n", s.actualDuration.Round(time.Millisecond))
 	fmt.Fprintf(w, "Overall:\n")
-	fmt.Fprintf(w, "  Concurrency:    %d\n", s.Concurrency)
+	if s.RunID != "" {
+		fmt.Fprintf(w, "  Run ID:         %s\n", s.RunID)
+	}
+	if s.Concurrency > 0 { // Unknown when reporting saved results
+		fmt.Fprintf(w, "  Concurrency:    %d\n", s.Concurrency)
+	}
+	if s.Agents > 0 {
+		fmt.Fprintf(w, "  Agents:         %d\n", s.Agents)
+	}
+	if s.ThinkTime != "" {
+		fmt.Fprintf(w, "  Think Time:     %s\n", s.ThinkTime)
+	}
 	fmt.Fprintf(w, "  Total Requests: %d (%.2f req/s)\n", s.TotalRequests, requestsPerSec)
 	fmt.Fprintf(w, "  Total Success:  %d\n", totalSuccess)
 	fmt.Fprintf(w, "  Total Errors:   %d\n", s.TotalErrors)
@@ -254,14 +485,23 @@ func (s *Stats) PrintSummary(w io.Writer) {
 	fmt.Fprintf(w, "  Success:        %d\n", successGets) // Placeholder count
 	fmt.Fprintf(w, "  Bytes D/L:      %d (%.2f MiB)\n", s.TotalBytesDown, float64(s.TotalBytesDown)/(1024*1024))
 	fmt.Fprintf(w, "  Avg Throughput: %.2f MiB/s\n", throughputDownMBps)
+	if s.EncodedGets > 0 {
+		fmt.Fprintf(w, "  Encoded Bodies: %d (decoded %d bytes, %.2fx the bytes on the wire)\n",
+			s.EncodedGets, s.TotalBytesObject, float64(s.TotalBytesObject)/float64(max(s.TotalBytesDown, 1)))
+		fmt.Fprintf(w, "  Decoded Throughput: %.2f MiB/s\n", s.rate(float64(s.TotalBytesObject)/(1024*1024)))
+	}
 
 	if successGets > 0 {
-		fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |   Max  \n")
-		fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------\n")
-		fmt.Fprintf(w, "  TTFB (proxy)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
-			ms(s.MinGetTTFB), ms(s.AvgGetTTFB), ms(s.P50GetTTFB), ms(s.P90GetTTFB), ms(s.P99GetTTFB), ms(s.MaxGetTTFB))
-		fmt.Fprintf(w, "  TTLB (body)   |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
-			ms(s.MinGetTTLB), ms(s.AvgGetTTLB), ms(s.P50GetTTLB), ms(s.P90GetTTLB), ms(s.P99GetTTLB), ms(s.MaxGetTTLB))
+		fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+		fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+		fmt.Fprintf(w, "  TTFB (proxy)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			ms(s.MinGetTTFB), ms(s.AvgGetTTFB), ms(s.P50GetTTFB), ms(s.P90GetTTFB), ms(s.P99GetTTFB), ms(s.P999GetTTFB), ms(s.MaxGetTTFB))
+		if l := histogramSummary(s.GetBodyTTFBHist); l != nil {
+			fmt.Fprintf(w, "  TTFB (body)   |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+				l.Min, l.Avg, l.P50, l.P90, l.P99, l.P999, l.Max)
+		}
+		fmt.Fprintf(w, "  TTLB (body)   |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			ms(s.MinGetTTLB), ms(s.AvgGetTTLB), ms(s.P50GetTTLB), ms(s.P90GetTTLB), ms(s.P99GetTTLB), ms(s.P999GetTTLB), ms(s.MaxGetTTLB))
 	} else {
 		fmt.Fprintln(w, "  No successful GETs to calculate latency.")
 	}
@@ -276,13 +516,66 @@ func (s *Stats) PrintSummary(w io.Writer) {
 	fmt.Fprintf(w, "  Avg Throughput: %.2f MiB/s\n", throughputUpMBps)
 
 	if successPuts > 0 {
-		fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |   Max  \n")
-		fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------\n")
-		fmt.Fprintf(w, "  TTLB (total)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
-			ms(s.MinPutTTLB), ms(s.AvgPutTTLB), ms(s.P50PutTTLB), ms(s.P90PutTTLB), ms(s.P99PutTTLB), ms(s.MaxPutTTLB))
+		fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+		fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+		fmt.Fprintf(w, "  TTLB (total)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			ms(s.MinPutTTLB), ms(s.AvgPutTTLB), ms(s.P50PutTTLB), ms(s.P90PutTTLB), ms(s.P99PutTTLB), ms(s.P999PutTTLB), ms(s.MaxPutTTLB))
 	} else {
 		fmt.Fprintln(w, "  No successful PUTs to calculate latency.")
 	}
+
+	// HEAD only happens in 'head' mode and replays, so skip the section otherwise
+	if s.TotalHeads > 0 {
+		successHeads := s.TotalHeads - s.countErrorsForOp("HEAD")
+		fmt.Fprintf(w, "\nHEAD Operations (%d total):\n", s.TotalHeads)
+		fmt.Fprintf(w, "  Success:        %d\n", successHeads)
+		if successHeads > 0 {
+			fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+			fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+			fmt.Fprintf(w, "  TTLB (total)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+				ms(s.MinHeadTTLB), ms(s.AvgHeadTTLB), ms(s.P50HeadTTLB), ms(s.P90HeadTTLB), ms(s.P99HeadTTLB), ms(s.P999HeadTTLB), ms(s.MaxHeadTTLB))
+		} else {
+			fmt.Fprintln(w, "  No successful HEADs to calculate latency.")
+		}
+	}
+
+	s.printSLOSummary(w)
+	s.printErrorClassSummary(w)
+	s.printStatusCodeSummary(w)
+	s.printRetrySummary(w)
+	s.printSizeClassSummary(w)
+	s.printListSummary(w)
+	s.printCopySummary(w)
+	s.printTaggingSummary(w)
+	s.printDeleteSummary(w)
+	s.printWriteSummary(w)
+	s.printHedgeSummary(w)
+	s.printIntegritySummary(w)
+	s.printChecksumSummary(w)
+	s.printRevalidationSummary(w)
+	s.printContentionSummary(w)
+	s.printOverwriteSummary(w)
+	s.printDisconnectSummary(w)
+	s.printAppendSummary(w)
+	s.printThroughputSummary(w)
+	s.printConsistencySummary(w)
+	s.printConnSetupSummary(w)
+	s.printPhaseSummary(w)
+	s.printFamilySummary(w)
+	s.printProtocolSummary(w)
+	s.printGroupSummary(w)
+	s.printBucketSummary(w)
+	s.printEndpointSummary(w)
+	s.printPrefixSummary(w)
+	s.printOutlierSummary(w)
+	s.printStageSummary(w)
+	s.printScalingSummary(w)
+	s.printTuningSummary(w)
+	s.printFailoverSummary(w)
+	s.printArrivalSummary(w)
+	s.printCorrectedSummary(w)
+	s.printClientSummary(w)
+	s.printNICSummary(w)
 	fmt.Fprintf(w, "----------------------------------------\n")
 }
 
@@ -297,10 +590,25 @@ func (s *Stats) countErrorsForOp(opType string) int64 {
 		// Estimate: Total Errors might be distributed proportionally? Not accurate.
 		// Best approach is to calculate success = total - errors during AddResult
 		// Returning placeholder:
-		return s.TotalGets - int64(len(s.GetTTLBs)) // Number of successful GETs is length of GetTTLBs
+		return s.TotalGets - s.GetTTLBHist.Count() // Number of successful GETs is the GetTTLBHist count
 	}
 	if opType == "PUT" {
-		return s.TotalPuts - int64(len(s.PutTTLBs)) // Number of successful PUTs is length of PutTTLBs
+		return s.TotalPuts - s.PutTTLBHist.Count() // Number of successful PUTs is the PutTTLBHist count
+	}
+	if opType == "HEAD" {
+		return s.TotalHeads - s.HeadTTLBHist.Count()
+	}
+	if opType == "LIST" {
+		return s.TotalLists - s.ListTTLBHist.Count()
+	}
+	if opType == "COPY" {
+		return s.TotalCopies - s.CopyTTLBHist.Count()
+	}
+	if opType == "TAGGING" {
+		return s.TotalTaggings - s.TaggingTTLBHist.Count()
+	}
+	if opType == "DELETE" {
+		return s.TotalDeletes - s.DeleteTTLBHist.Count()
 	}
 	return 0
 }
@@ -312,49 +620,3 @@ func ms(d time.Duration) float64 {
 	}
 	return float64(d.Nanoseconds()) / 1e6
 }
-
-// WriteResultsCSV writes the collected results to a CSV file.
-func WriteResultsCSV(results []Result, filePath string) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create output csv file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush() // Ensure all buffered data is written
-
-	// Write header
-	header := []string{"Timestamp", "Operation", "ObjectKey", "TTFB(ms)", "TTLB(ms)", "BytesDownloaded", "BytesUploaded", "Error"}
-	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write csv header: %w", err)
-	}
-
-	// Write data rows
-	for _, r := range results {
-		row := []string{
-			r.Timestamp.Format(time.RFC3339Nano),
-			r.Operation,
-			r.ObjectKey,
-			fmt.Sprintf("%.3f", ms(r.TTFB)), // TTFB (ms) - will be 0.000 for PUTs or errors
-			fmt.Sprintf("%.3f", ms(r.TTLB)), // TTLB (ms)
-			fmt.Sprintf("%d", r.BytesDownloaded),
-			fmt.Sprintf("%d", r.BytesUploaded),
-			r.Error,
-		}
-		if err := writer.Write(row); err != nil {
-			// Log error but attempt to continue writing other rows
-			fmt.Fprintf(os.Stderr, "Warning: failed to write csv row: %v (data: %v)\n", err, row)
-			// Decide whether to return immediately or try to continue
-			// return fmt.Errorf("failed to write csv row: %w", err)
-		}
-	}
-
-	// Check for errors that might have occurred during flushing
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error during csv writing/flushing: %w", err)
-	}
-
-	fmt.Printf("Detailed results written to %s\n", filePath)
-	return nil
-}
@@ -2,6 +2,9 @@ package stresser
 
 import (
 	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -149,8 +152,8 @@ func TestStatsAddAndCalculate(t *testing.T) {
 	}
 
 	// Test percentiles
-	// P50 for 3 values should be the middle value when sorted
-	if stats.P50GetTTFB != 50*time.Millisecond {
+	// P50 for 3 values should be the middle value when sorted, within histogram precision (0.1%)
+	if d := stats.P50GetTTFB - 50*time.Millisecond; d < 0 || d > 50*time.Microsecond {
 		t.Errorf("Expected P50GetTTFB=50ms, got %v", stats.P50GetTTFB)
 	}
 
@@ -207,6 +210,72 @@ func TestPrintSummary(t *testing.T) {
 	if !strings.Contains(output, "Total Requests: 2") {
 		t.Error("Summary output missing or incorrect total requests count")
 	}
+	if strings.Contains(output, "HEAD Operations") {
+		t.Error("Summary output should omit the HEAD section without HEADs")
+	}
+}
+
+func TestStatsHeadOperations(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "HEAD", ObjectKey: "a", TTFB: -1, TTLB: 4 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "HEAD", ObjectKey: "b", TTFB: -1, TTLB: 8 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "HEAD", ObjectKey: "c", TTFB: -1, TTLB: -1, Error: "not found"})
+	stats.Calculate(now, now.Add(time.Second))
+
+	if stats.TotalHeads != 3 || stats.TotalGets != 0 || stats.HeadTTLBHist.Count() != 2 {
+		t.Errorf("unexpected HEAD counts: total=%d gets=%d success=%d", stats.TotalHeads, stats.TotalGets, stats.HeadTTLBHist.Count())
+	}
+	if stats.MinHeadTTLB != 4*time.Millisecond || stats.MaxHeadTTLB != 8*time.Millisecond || stats.AvgHeadTTLB != 6*time.Millisecond {
+		t.Errorf("unexpected HEAD latencies: min=%v avg=%v max=%v", stats.MinHeadTTLB, stats.AvgHeadTTLB, stats.MaxHeadTTLB)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "HEAD Operations (3 total)") {
+		t.Error("Summary output missing HEAD Operations section")
+	}
+	if sum := stats.Summary(); sum.Head == nil || sum.Head.Success != 2 || sum.Head.TTLB == nil {
+		t.Errorf("unexpected HEAD summary: %+v", sum.Head)
+	}
+}
+
+func TestGetBodyTTFB(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	// The headers go out at once, the body only after a stall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "4")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", RunID: "test"}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	get := performGetOperation(context.Background(), client, cfg.Bucket, "key", false)
+	if get.Error != "" {
+		t.Fatalf("GET failed: %s", get.Error)
+	}
+	if get.BodyTTFB < get.TTFB+40*time.Millisecond || get.BodyTTFB > get.TTLB {
+		t.Errorf("Expected the body TTFB to include the stall, got TTFB %v, body TTFB %v, TTLB %v", get.TTFB, get.BodyTTFB, get.TTLB)
+	}
+
+	stats := NewStats()
+	stats.AddResult(get)
+	stats.AddResult(Result{Timestamp: get.Timestamp, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond}) // Empty body
+	stats.Calculate(get.Timestamp, get.Timestamp.Add(time.Second))
+	if sum := stats.Summary(); sum.Get.BodyTTFB == nil || stats.GetBodyTTFBHist.Count() != 1 {
+		t.Errorf("Expected one body TTFB, got %+v", sum.Get.BodyTTFB)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "TTFB (body)") || !strings.Contains(buf.String(), "TTFB (proxy)") {
+		t.Errorf("Summary lacks both TTFB rows:\n%s", buf.String())
+	}
 }
 
 func TestWriteResultsCSV(t *testing.T) {
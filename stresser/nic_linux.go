@@ -0,0 +1,53 @@
+//go:build linux
+
+package stresser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysfsNICReader reads interface counters from /sys/class/net.
+type sysfsNICReader struct {
+	root string // Normally /sys/class/net, overridable for tests
+}
+
+func newNICStatsReader() nicStatsReader {
+	return sysfsNICReader{root: "/sys/class/net"}
+}
+
+func (r sysfsNICReader) readUint(iface, name string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(r.root, iface, name))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s for interface %s: %w", name, iface, err)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s value %q for interface %s", name, strings.TrimSpace(string(data)), iface)
+	}
+	return uint64(value), nil
+}
+
+func (r sysfsNICReader) Counters(iface string) (uint64, uint64, error) {
+	rx, err := r.readUint(iface, "statistics/rx_bytes")
+	if err != nil {
+		return 0, 0, err
+	}
+	tx, err := r.readUint(iface, "statistics/tx_bytes")
+	if err != nil {
+		return 0, 0, err
+	}
+	return rx, tx, nil
+}
+
+func (r sysfsNICReader) LinkSpeed(iface string) (uint64, error) {
+	// The kernel reports Mb/s, or -1 for virtual interfaces without a link speed
+	mbps, err := r.readUint(iface, "speed")
+	if err != nil {
+		return 0, err
+	}
+	return mbps * 1000 * 1000, nil
+}
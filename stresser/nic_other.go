@@ -0,0 +1,23 @@
+//go:build !linux
+
+package stresser
+
+import (
+	"errors"
+	"runtime"
+)
+
+// unsupportedNICReader is used on platforms without a NIC counter implementation.
+type unsupportedNICReader struct{}
+
+func newNICStatsReader() nicStatsReader {
+	return unsupportedNICReader{}
+}
+
+func (unsupportedNICReader) Counters(string) (uint64, uint64, error) {
+	return 0, 0, errors.New("NIC counters are not supported on " + runtime.GOOS)
+}
+
+func (unsupportedNICReader) LinkSpeed(string) (uint64, error) {
+	return 0, errors.New("NIC link speed is not supported on " + runtime.GOOS)
+}
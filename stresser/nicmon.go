@@ -0,0 +1,137 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// nicUtilizationWarnPercent is the link utilization above which a run is flagged as NIC-bound.
+const nicUtilizationWarnPercent = 90.0
+
+// nicStatsReader reads cumulative byte counters and the link speed of a network interface.
+// Implementations are platform-specific; unsupported platforms return errors.
+type nicStatsReader interface {
+	Counters(iface string) (rxBytes, txBytes uint64, err error)
+	LinkSpeed(iface string) (bitsPerSec uint64, err error)
+}
+
+// NICReport summarizes network interface throughput during a run.
+type NICReport struct {
+	Interface      string
+	LinkSpeedBits  uint64  // Link capacity in bits/s, 0 if unknown
+	AvgRxMbps      float64 // Average receive rate over the run
+	AvgTxMbps      float64 // Average transmit rate over the run
+	PeakRxMbps     float64 // Highest per-interval receive rate
+	PeakTxMbps     float64 // Highest per-interval transmit rate
+	PeakUtilPct    float64 // Peak per-direction utilization of the link, 0 if link speed unknown
+	LikelyNICBound bool
+}
+
+// nicMonitor samples interface counters while a test runs.
+type nicMonitor struct {
+	reader  nicStatsReader
+	iface   string
+	stop    chan struct{}
+	done    chan struct{}
+	start   time.Time
+	startRx uint64
+	startTx uint64
+	report  NICReport
+}
+
+// startNICMonitor begins sampling iface every interval. It returns an error if the
+// interface counters can't be read, so misconfiguration is caught before the run.
+func startNICMonitor(reader nicStatsReader, iface string, interval time.Duration) (*nicMonitor, error) {
+	rx, tx, err := reader.Counters(iface)
+	if err != nil {
+		return nil, err
+	}
+	m := &nicMonitor{
+		reader:  reader,
+		iface:   iface,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		start:   time.Now(),
+		startRx: rx,
+		startTx: tx,
+		report:  NICReport{Interface: iface},
+	}
+	if speed, err := reader.LinkSpeed(iface); err == nil {
+		m.report.LinkSpeedBits = speed
+	} else {
+		slog.Warn("Link speed unknown, NIC utilization will not be reported", "interface", iface, "error", err)
+	}
+
+	go m.loop(interval)
+	return m, nil
+}
+
+func (m *nicMonitor) loop(interval time.Duration) {
+	defer close(m.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastWall, lastRx, lastTx := m.start, m.startRx, m.startTx
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			rx, tx, err := m.reader.Counters(m.iface)
+			if err != nil {
+				continue
+			}
+			secs := now.Sub(lastWall).Seconds()
+			m.report.PeakRxMbps = max(m.report.PeakRxMbps, megabits(rx-lastRx, secs))
+			m.report.PeakTxMbps = max(m.report.PeakTxMbps, megabits(tx-lastTx, secs))
+			lastWall, lastRx, lastTx = now, rx, tx
+		}
+	}
+}
+
+// megabits converts a byte count over secs seconds into Mbit/s.
+func megabits(bytes uint64, secs float64) float64 {
+	if secs <= 0 {
+		return 0
+	}
+	return float64(bytes) * 8 / secs / 1e6
+}
+
+// Stop ends sampling and computes average and peak utilization.
+func (m *nicMonitor) Stop() NICReport {
+	close(m.stop)
+	<-m.done
+
+	if rx, tx, err := m.reader.Counters(m.iface); err == nil {
+		secs := time.Since(m.start).Seconds()
+		m.report.AvgRxMbps = megabits(rx-m.startRx, secs)
+		m.report.AvgTxMbps = megabits(tx-m.startTx, secs)
+	}
+	if m.report.LinkSpeedBits > 0 {
+		linkMbps := float64(m.report.LinkSpeedBits) / 1e6
+		m.report.PeakUtilPct = max(m.report.PeakRxMbps, m.report.PeakTxMbps) / linkMbps * 100
+		m.report.LikelyNICBound = m.report.PeakUtilPct > nicUtilizationWarnPercent
+	}
+	return m.report
+}
+
+// printNICSummary prints interface throughput as part of PrintSummary.
+func (s *Stats) printNICSummary(w io.Writer) {
+	if s.NIC == nil {
+		return
+	}
+	n := s.NIC
+	fmt.Fprintf(w, "\nNetwork Interface (%s):\n", n.Interface)
+	fmt.Fprintf(w, "  RX:             %.1f Mbit/s avg, %.1f Mbit/s peak\n", n.AvgRxMbps, n.PeakRxMbps)
+	fmt.Fprintf(w, "  TX:             %.1f Mbit/s avg, %.1f Mbit/s peak\n", n.AvgTxMbps, n.PeakTxMbps)
+	if n.LinkSpeedBits > 0 {
+		fmt.Fprintf(w, "  Link:           %.0f Mbit/s, %.1f%% peak utilization\n", float64(n.LinkSpeedBits)/1e6, n.PeakUtilPct)
+	} else {
+		fmt.Fprintf(w, "  Link:           speed unknown\n")
+	}
+	if n.LikelyNICBound {
+		fmt.Fprintf(w, "  WARNING: the network interface was saturated; results are likely NIC-bound.\n")
+	}
+}
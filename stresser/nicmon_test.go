@@ -0,0 +1,56 @@
+package stresser
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNICReader reports counters that grow by a fixed amount on every read.
+type fakeNICReader struct {
+	mu        sync.Mutex
+	rx, tx    uint64
+	step      uint64
+	linkSpeed uint64
+}
+
+func (f *fakeNICReader) Counters(iface string) (uint64, uint64, error) {
+	if iface != "eth0" {
+		return 0, 0, errors.New("no such interface")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rx += f.step
+	f.tx += f.step / 10
+	return f.rx, f.tx, nil
+}
+
+func (f *fakeNICReader) LinkSpeed(string) (uint64, error) {
+	return f.linkSpeed, nil
+}
+
+func TestNICMonitor(t *testing.T) {
+	if _, err := startNICMonitor(&fakeNICReader{}, "missing0", time.Millisecond); err == nil {
+		t.Fatal("Expected error for unknown interface")
+	}
+
+	// 1 Mbit/s link; every 10ms sample adds 1MB, far above capacity
+	reader := &fakeNICReader{step: 1000 * 1000, linkSpeed: 1000 * 1000}
+	mon, err := startNICMonitor(reader, "eth0", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("startNICMonitor failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	report := mon.Stop()
+
+	if report.Interface != "eth0" {
+		t.Errorf("Expected interface eth0, got %q", report.Interface)
+	}
+	if report.PeakRxMbps <= report.PeakTxMbps || report.PeakRxMbps == 0 {
+		t.Errorf("Expected RX peak above TX peak, got rx=%.1f tx=%.1f", report.PeakRxMbps, report.PeakTxMbps)
+	}
+	if !report.LikelyNICBound {
+		t.Errorf("Expected run to be flagged as NIC-bound, utilization %.1f%%", report.PeakUtilPct)
+	}
+}
@@ -0,0 +1,84 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ArrivalReport describes the arrivals of an open-loop run (see Config.ArrivalRate).
+type ArrivalReport struct {
+	Rate      float64 `json:"rate" yaml:"rate"`           // Target arrivals per second
+	Scheduled int64   `json:"scheduled" yaml:"scheduled"` // Arrivals due during the run
+	Issued    int64   `json:"issued" yaml:"issued"`       // Arrivals a worker picked up
+	Dropped   int64   `json:"dropped" yaml:"dropped"`     // Arrivals that found all workers busy
+}
+
+// arrivalScheduler drives open-loop load: operations start at a constant rate whether or
+// not earlier ones have completed. Each arrival is handed to an idle worker, so the workers
+// bound the number of outstanding requests. An arrival that finds every worker busy is
+// dropped and counted rather than queued, since a queue would just delay it behind the
+// slow requests it is meant to expose.
+type arrivalScheduler struct {
+	rate     float64
+	arrivals chan time.Time // Unbuffered: a send only succeeds if a worker is waiting
+	issued   atomic.Int64
+	dropped  atomic.Int64
+}
+
+func newArrivalScheduler(rate float64) *arrivalScheduler {
+	return &arrivalScheduler{rate: rate, arrivals: make(chan time.Time)}
+}
+
+// run schedules arrivals until ctx is done. Arrival times are fixed in advance, so a late
+// wake-up is caught up with immediately rather than shifting the schedule.
+func (a *arrivalScheduler) run(ctx context.Context) {
+	interval := time.Duration(float64(time.Second) / a.rate)
+	next := time.Now()
+	for {
+		next = next.Add(interval)
+		if !sleepContext(ctx, time.Until(next)) {
+			return
+		}
+		select {
+		case a.arrivals <- next:
+			a.issued.Add(1)
+		default:
+			a.dropped.Add(1)
+		}
+	}
+}
+
+// Wait blocks until the worker is handed an arrival or ctx is done, and returns when the
+// arrival was due. It returns false if the context ended first.
+func (a *arrivalScheduler) Wait(ctx context.Context) (time.Time, bool) {
+	select {
+	case due := <-a.arrivals:
+		return due, true
+	case <-ctx.Done():
+		return time.Time{}, false
+	}
+}
+
+// Report returns the arrival counts so far.
+func (a *arrivalScheduler) Report() ArrivalReport {
+	issued, dropped := a.issued.Load(), a.dropped.Load()
+	return ArrivalReport{Rate: a.rate, Scheduled: issued + dropped, Issued: issued, Dropped: dropped}
+}
+
+// printArrivalSummary prints the open-loop arrival counts as part of PrintSummary.
+func (s *Stats) printArrivalSummary(w io.Writer) {
+	ar := s.Arrivals
+	if ar == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nOpen-Loop Arrivals (%.2f/s):\n", ar.Rate)
+	fmt.Fprintf(w, "  Scheduled:      %d\n", ar.Scheduled)
+	fmt.Fprintf(w, "  Issued:         %d\n", ar.Issued)
+	fmt.Fprintf(w, "  Dropped:        %d (%.2f%%)\n", ar.Dropped, percentOf(ar.Dropped, ar.Scheduled))
+	if ar.Dropped > 0 {
+		fmt.Fprintf(w, "  Warning: all %d workers were busy when arrivals were due; the offered load was not reached. Raise -c.\n", s.Concurrency)
+	}
+}
@@ -0,0 +1,43 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArrivalScheduler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	// One worker taking 20ms per operation can serve about a quarter of 200 arrivals/s
+	a := newArrivalScheduler(200)
+	go a.run(ctx)
+	served := 0
+	for {
+		if _, ok := a.Wait(ctx); !ok {
+			break
+		}
+		served++
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	r := a.Report()
+	if r.Scheduled < 80 || r.Scheduled > 110 {
+		t.Errorf("Expected about 100 arrivals in 500ms, got %d", r.Scheduled)
+	}
+	if r.Issued != int64(served) || r.Dropped < r.Scheduled/2 {
+		t.Errorf("Expected most arrivals dropped with one busy worker: %+v, served %d", r, served)
+	}
+
+	stats := NewStats()
+	stats.Concurrency = 1
+	stats.Arrivals = &r
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Open-Loop Arrivals (200.00/s):") || !strings.Contains(buf.String(), "Raise -c") {
+		t.Errorf("Summary is missing the arrival section:\n%s", buf.String())
+	}
+}
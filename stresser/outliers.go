@@ -0,0 +1,160 @@
+package stresser
+
+import (
+	"container/heap"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"sort"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// traceConnection returns a context that records which connection a request used, how long
+// it took to obtain it, and how that time split into DNS lookup, TCP connect and TLS
+// handshake, into r. It also records when the first response byte arrived. With retries,
+// the last attempt's connection wins. GotConn and GotFirstResponseByte complete before the
+// SDK call returns; the dial phases are copied into r only once the request gets the new
+// connection, since a dial may still be running in the background when it gets another one.
+func traceConnection(ctx context.Context, r *Result, start time.Time) context.Context {
+	var phases dialPhases
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { phases.begin(&phases.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { phases.end(&phases.dns, phases.dnsStart) },
+		ConnectStart:      func(string, string) { phases.begin(&phases.connectStart) },
+		ConnectDone:       func(_, _ string, err error) { phases.endConnect(err) },
+		TLSHandshakeStart: func() { phases.begin(&phases.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { phases.end(&phases.tls, phases.tlsStart) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.ConnWait = time.Since(start)
+			r.ConnReused = info.Reused
+			r.DNS, r.Connect, r.TLSHandshake = 0, 0, 0
+			if !info.Reused {
+				r.DNS, r.Connect, r.TLSHandshake = phases.durations()
+			}
+			if info.Conn != nil {
+				r.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		GotFirstResponseByte: func() {
+			r.FirstByte = time.Since(start)
+		},
+	})
+}
+
+// recordResponseMetadata copies the request ids, the HTTP status and protocol and the number
+// of attempts made by the SDK into r.
+func recordResponseMetadata(r *Result, md middleware.Metadata) {
+	if resp := metadataResponse(md); resp != nil {
+		r.StatusCode = resp.StatusCode
+		r.Protocol = resp.Proto
+	}
+	if id, ok := awsmiddleware.GetRequestIDMetadata(md); ok {
+		r.RequestID = id
+	}
+	if id, ok := s3.GetHostIDMetadata(md); ok {
+		r.HostID = id
+	}
+	if attempts, ok := retry.GetAttemptResults(md); ok {
+		r.Attempts = len(attempts.Results)
+	}
+}
+
+// recordErrorRequestID copies the request ids and the HTTP status and protocol of a failed call
+// into r, if the service returned a response.
+func recordErrorRequestID(r *Result, err error) {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		r.RequestID = respErr.ServiceRequestID()
+		// The SDK keeps the host id in an internal error type, so read it from the response
+		if respErr.ResponseError != nil && respErr.Response != nil && respErr.Response.Response != nil {
+			r.HostID = respErr.Response.Header.Get("X-Amz-Id-2")
+			r.StatusCode = respErr.Response.StatusCode
+			r.Protocol = respErr.Response.Proto
+		}
+	}
+}
+
+// outlierHeap is a min-heap on TTLB, so the fastest retained result is evicted first.
+type outlierHeap []Result
+
+func (h outlierHeap) Len() int           { return len(h) }
+func (h outlierHeap) Less(i, j int) bool { return h[i].TTLB < h[j].TTLB }
+func (h outlierHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *outlierHeap) Push(x any)        { *h = append(*h, x.(Result)) }
+func (h *outlierHeap) Pop() any {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
+
+// addOutlier keeps r if it is among the OutlierCount slowest results seen so far.
+// Failed requests are included as long as their duration was measured.
+func (s *Stats) addOutlier(r Result) {
+	if r.TTLB < 0 {
+		return
+	}
+	if len(s.outliers) < s.OutlierCount {
+		heap.Push(&s.outliers, r)
+		return
+	}
+	if r.TTLB > s.outliers[0].TTLB {
+		s.outliers[0] = r
+		heap.Fix(&s.outliers, 0)
+	}
+}
+
+// Outliers returns the slowest retained results, slowest first.
+func (s *Stats) Outliers() []Result {
+	list := append([]Result(nil), s.outliers...)
+	sort.Slice(list, func(i, j int) bool { return list[i].TTLB > list[j].TTLB })
+	return list
+}
+
+// printOutlierSummary prints the slowest requests with their full context as part of PrintSummary.
+func (s *Stats) printOutlierSummary(w io.Writer) {
+	if len(s.outliers) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nOutliers (%d slowest requests):\n", len(s.outliers))
+	for i, r := range s.Outliers() {
+		fmt.Fprintf(w, "  %2d. %-4s %s\n", i+1, r.Operation, r.ObjectKey)
+		fmt.Fprintf(w, "      At:         +%s (%s)\n", r.Timestamp.Sub(s.startTime).Round(time.Millisecond), r.Timestamp.Format(time.RFC3339Nano))
+		if r.TTFB >= 0 {
+			fmt.Fprintf(w, "      Timings:    conn %.2f ms, TTFB %.2f ms, TTLB %.2f ms\n", ms(r.ConnWait), ms(r.TTFB), ms(r.TTLB))
+		} else {
+			fmt.Fprintf(w, "      Timings:    conn %.2f ms, total %.2f ms\n", ms(r.ConnWait), ms(r.TTLB))
+		}
+		fmt.Fprintf(w, "      Bytes:      %d down, %d up\n", r.BytesDownloaded, r.BytesUploaded)
+		if r.RemoteAddr != "" {
+			fmt.Fprintf(w, "      Connection: %s (reused: %t)\n", r.RemoteAddr, r.ConnReused)
+		}
+		if r.Protocol != "" {
+			fmt.Fprintf(w, "      Protocol:   %s\n", r.Protocol)
+		}
+		if r.Attempts > 0 {
+			fmt.Fprintf(w, "      Attempts:   %d\n", r.Attempts)
+		}
+		if r.StatusCode != 0 {
+			fmt.Fprintf(w, "      Status:     %s\n", StatusText(r.StatusCode))
+		}
+		if r.RequestID != "" {
+			fmt.Fprintf(w, "      Request ID: %s\n", r.RequestID)
+		}
+		if r.HostID != "" {
+			fmt.Fprintf(w, "      Host ID:    %s\n", r.HostID)
+		}
+		if r.Error != "" {
+			fmt.Fprintf(w, "      Error:      %s\n", r.Error)
+		}
+	}
+}
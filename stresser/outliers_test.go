@@ -0,0 +1,85 @@
+package stresser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestOutliersKeepsSlowest(t *testing.T) {
+	stats := NewStats()
+	stats.OutlierCount = 3
+	now := time.Now()
+
+	for _, millis := range []int{5, 50, 1, 20, 100, 3, 70} {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "k", TTFB: time.Millisecond, TTLB: time.Duration(millis) * time.Millisecond})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "missing", TTFB: -1, TTLB: -1, Error: "not found"})
+
+	got := stats.Outliers()
+	expected := []time.Duration{100 * time.Millisecond, 70 * time.Millisecond, 50 * time.Millisecond}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d outliers, got %d", len(expected), len(got))
+	}
+	for i, r := range got {
+		if r.TTLB != expected[i] {
+			t.Errorf("outlier %d: expected TTLB %v, got %v", i, expected[i], r.TTLB)
+		}
+	}
+}
+
+func TestOutlierSummary(t *testing.T) {
+	stats := NewStats()
+	stats.OutlierCount = 1
+	now := time.Now()
+	stats.AddResult(Result{
+		Timestamp: now, Operation: "PUT", ObjectKey: "slow.dat", TTFB: -1, TTLB: 2 * time.Second,
+		BytesUploaded: 1024, RequestID: "REQ123", HostID: "HOST456", Attempts: 3, RemoteAddr: "10.0.0.1:443", ConnWait: time.Millisecond,
+	})
+	stats.Calculate(now, now.Add(time.Second))
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	out := buf.String()
+	for _, want := range []string{"Outliers (1 slowest requests)", "slow.dat", "Request ID: REQ123", "Host ID:    HOST456", "Attempts:   3", "10.0.0.1:443"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+
+	disabled := NewStats()
+	disabled.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "k", TTLB: time.Second})
+	if len(disabled.Outliers()) != 0 {
+		t.Error("expected no outliers when disabled")
+	}
+}
+
+func TestRecordErrorRequestID(t *testing.T) {
+	err := &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: 500, Header: http.Header{"X-Amz-Id-2": {"HOST"}}}},
+			Err:      errors.New("internal error"),
+		},
+		RequestID: "REQ",
+	}
+	var r Result
+	recordErrorRequestID(&r, fmt.Errorf("operation error S3: GetObject: %w", err))
+	if r.RequestID != "REQ" || r.HostID != "HOST" {
+		t.Errorf("Expected request id REQ and host id HOST, got %q and %q", r.RequestID, r.HostID)
+	}
+
+	// Errors without a response leave the ids empty
+	r = Result{}
+	recordErrorRequestID(&r, statusError(503))
+	recordErrorRequestID(&r, errors.New("connection reset"))
+	if r.RequestID != "" || r.HostID != "" {
+		t.Errorf("Expected no ids, got %q and %q", r.RequestID, r.HostID)
+	}
+}
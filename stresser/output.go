@@ -0,0 +1,718 @@
+package stresser
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats for detailed results (-format) and summaries (-summary-format).
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+	FormatSQL     = "sql"
+	FormatSQLite  = "sqlite"
+	FormatText    = "text"
+	FormatJSON    = "json"
+	FormatYAML    = "yaml"
+
+	DefaultOutputFormat  = FormatCSV
+	DefaultSummaryFormat = FormatText
+
+	// StdoutPath as an output path (-o, -summary) writes to standard output.
+	StdoutPath = "-"
+)
+
+// resultEncoders maps a detailed results format to its encoder. Adding a format only
+// requires registering an encoder here. Encoders that embed the run summary get the
+// calculated stats, which may be nil.
+var resultEncoders = map[string]func(w io.Writer, results []Result, s *Stats) error{
+	FormatCSV:     func(w io.Writer, results []Result, _ *Stats) error { return encodeResultsCSV(w, results) },
+	FormatJSONL:   func(w io.Writer, results []Result, _ *Stats) error { return encodeResultsJSONL(w, results) },
+	FormatJSON:    encodeResultsJSON,
+	FormatSQL:     encodeResultsSQL,
+	FormatParquet: func(w io.Writer, results []Result, _ *Stats) error { return encodeResultsParquet(w, results) },
+}
+
+// resultFileWriters maps the detailed results formats that need a file rather than a
+// stream, such as a database, to their writer.
+var resultFileWriters = map[string]func(path string, results []Result, s *Stats) error{
+	FormatSQLite: writeResultsSQLite,
+}
+
+// summaryEncoders maps a summary format to its encoder.
+var summaryEncoders = map[string]func(w io.Writer, s *Stats) error{
+	FormatText: func(w io.Writer, s *Stats) error { s.PrintSummary(w); return nil },
+	FormatJSON: encodeSummaryJSON,
+	FormatYAML: encodeSummaryYAML,
+}
+
+// InferResultFormat picks the detailed results format from the extension of path,
+// falling back to DefaultOutputFormat.
+func InferResultFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".sql":
+		return FormatSQL
+	case ".parquet":
+		return FormatParquet
+	case ".db", ".sqlite", ".sqlite3":
+		return FormatSQLite
+	}
+	return DefaultOutputFormat
+}
+
+// checkResultFormat reports whether detailed results can be written in format.
+func checkResultFormat(format string) error {
+	if _, ok := resultEncoders[format]; ok {
+		return nil
+	}
+	if _, ok := resultFileWriters[format]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown results format %q, must be one of %s, %s", format, formatNames(resultEncoders), formatNames(resultFileWriters))
+}
+
+// checkSummaryFormat reports whether summaries can be written in format.
+func checkSummaryFormat(format string) error {
+	if _, ok := summaryEncoders[format]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown summary format %q, must be one of %s", format, formatNames(summaryEncoders))
+}
+
+func formatNames[T any](encoders map[string]T) string {
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// OpenOutput creates the file at path for writing, or returns standard output if path is StdoutPath.
+// Closing the returned writer never closes standard output.
+func OpenOutput(path string) (io.WriteCloser, error) {
+	if path == StdoutPath {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// WriteResults writes the collected results to filePath in the given format.
+func WriteResults(results []Result, filePath, format string) error {
+	return WriteResultsWithStats(results, nil, filePath, format)
+}
+
+// WriteResultsWithStats is WriteResults for formats that can also hold the run summary,
+// such as FormatSQL and FormatSQLite. s must have been calculated; it is ignored by the other formats.
+func WriteResultsWithStats(results []Result, s *Stats, filePath, format string) error {
+	if err := checkResultFormat(format); err != nil {
+		return err
+	}
+	if write, ok := resultFileWriters[format]; ok {
+		if err := write(filePath, results, s); err != nil {
+			return err
+		}
+		slog.Info("Detailed results written", "file", filePath, "format", format)
+		return nil
+	}
+	out, err := OpenOutput(filePath)
+	if err != nil {
+		return err
+	}
+	if err := resultEncoders[format](out, results, s); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close output file %s: %w", filePath, err)
+	}
+
+	slog.Info("Detailed results written", "file", filePath, "format", format)
+	return nil
+}
+
+// WriteResultsCSV writes the collected results to a CSV file.
+func WriteResultsCSV(results []Result, filePath string) error {
+	return WriteResults(results, filePath, FormatCSV)
+}
+
+// WriteSummary writes the summary of stats to w in the given format.
+func WriteSummary(w io.Writer, s *Stats, format string) error {
+	if err := checkSummaryFormat(format); err != nil {
+		return err
+	}
+	return summaryEncoders[format](w, s)
+}
+
+// SummaryOutput opens the summary destination: SummaryFile if set, otherwise stdout, or
+// stderr when detailed results are streamed to stdout so the two don't mix.
+func (c *Config) SummaryOutput() (io.WriteCloser, error) {
+	switch {
+	case c.SummaryFile != "":
+		return OpenOutput(c.SummaryFile)
+	case c.OutputFile == StdoutPath:
+		return nopWriteCloser{os.Stderr}, nil
+	default:
+		return nopWriteCloser{os.Stdout}, nil
+	}
+}
+
+// ResultLine holds the headline numbers of a run for -quiet mode.
+type ResultLine struct {
+	Status      string  `json:"status"` // "pass" if all SLOs were met, or without SLOs if every request succeeded, otherwise "fail"
+	Requests    int64   `json:"requests"`
+	Errors      int64   `json:"errors"`
+	DurationSec float64 `json:"duration_s"`
+	ReqPerSec   float64 `json:"req_per_s"`
+	MiBPerSec   float64 `json:"mib_per_s"`
+	GetP50Ms    float64 `json:"get_p50_ms"`
+	GetP99Ms    float64 `json:"get_p99_ms"`
+	PutP50Ms    float64 `json:"put_p50_ms"`
+	PutP99Ms    float64 `json:"put_p99_ms"`
+}
+
+// ResultLine returns the headline numbers of a calculated Stats.
+func (s *Stats) ResultLine() ResultLine {
+	sum := s.Summary()
+	line := ResultLine{
+		Status:      "pass",
+		Requests:    sum.TotalRequests,
+		Errors:      sum.TotalErrors,
+		DurationSec: sum.DurationSeconds,
+		ReqPerSec:   sum.RequestsPerSec,
+		MiBPerSec:   sum.Get.ThroughputMiB + sum.Put.ThroughputMiB,
+		GetP50Ms:    ms(s.P50GetTTLB),
+		GetP99Ms:    ms(s.P99GetTTLB),
+		PutP50Ms:    ms(s.P50PutTTLB),
+		PutP99Ms:    ms(s.P99PutTTLB),
+	}
+	if len(s.SLOs) > 0 {
+		if s.CheckSLOs() != nil || s.TotalRequests == 0 {
+			line.Status = "fail"
+		}
+	} else if s.TotalErrors > 0 || s.TotalRequests == 0 {
+		line.Status = "fail"
+	}
+	return line
+}
+
+// WriteResultLine writes the headline numbers as exactly one line: a JSON object if format
+// is "json", otherwise space-separated key=value pairs.
+func WriteResultLine(w io.Writer, s *Stats, format string) error {
+	line := s.ResultLine()
+	if format == FormatJSON {
+		return json.NewEncoder(w).Encode(line)
+	}
+	_, err := fmt.Fprintf(w, "status=%s requests=%d errors=%d duration_s=%.3f req_per_s=%.2f mib_per_s=%.2f get_p50_ms=%.3f get_p99_ms=%.3f put_p50_ms=%.3f put_p99_ms=%.3f\n",
+		line.Status, line.Requests, line.Errors, line.DurationSec, line.ReqPerSec, line.MiBPerSec,
+		line.GetP50Ms, line.GetP99Ms, line.PutP50Ms, line.PutP99Ms)
+	return err
+}
+
+func encodeResultsCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	cols := resultCSVColumns(results)
+	if err := writer.Write(cols.header()); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	// Write data rows
+	for _, r := range results {
+		row := cols.row(r)
+		if err := writer.Write(row); err != nil {
+			// Log error but attempt to continue writing other rows
+			slog.Warn("Failed to write csv row", "error", err, "row", row)
+		}
+	}
+
+	// Check for errors that might have occurred during flushing
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error during csv writing/flushing: %w", err)
+	}
+	return nil
+}
+
+// csvColumns selects the optional columns of the CSV output.
+type csvColumns struct {
+	runs, copies, decoded, versions, groups, buckets, endpoints, warmup, requestIDs, statuses, bodyTTFB, windows bool
+}
+
+// allCSVColumns includes every optional column, for results that are written before it is
+// known which of them are needed.
+var allCSVColumns = csvColumns{runs: true, copies: true, decoded: true, versions: true, groups: true, buckets: true, endpoints: true, warmup: true, requestIDs: true, statuses: true, bodyTTFB: true, windows: true}
+
+// resultCSVColumns returns the optional columns results need: RunId for results of a run,
+// BytesCopied only for copies, BytesDecoded for compressed GET bodies,
+// VersionId for versioned objects, Group for scenarios, Bucket and Endpoint for multi-bucket
+// and multi-endpoint runs, Warmup when warm-up results were kept, RequestId and HostId when
+// the server returned request ids, Status when HTTP statuses were recorded, BodyTTFB
+// when GETs read a body and Windows and MinWindow when GET throughput was sampled.
+func resultCSVColumns(results []Result) csvColumns {
+	return csvColumns{
+		runs:       slices.ContainsFunc(results, func(r Result) bool { return r.RunID != "" }),
+		copies:     slices.ContainsFunc(results, func(r Result) bool { return r.Operation == "COPY" }),
+		decoded:    slices.ContainsFunc(results, func(r Result) bool { return r.BytesDecoded > 0 }),
+		versions:   slices.ContainsFunc(results, func(r Result) bool { return r.VersionID != "" }),
+		groups:     slices.ContainsFunc(results, func(r Result) bool { return r.Group != "" }),
+		buckets:    slices.ContainsFunc(results, func(r Result) bool { return r.Bucket != "" }),
+		endpoints:  slices.ContainsFunc(results, func(r Result) bool { return r.Endpoint != "" }),
+		warmup:     slices.ContainsFunc(results, func(r Result) bool { return r.Warmup }),
+		requestIDs: slices.ContainsFunc(results, func(r Result) bool { return r.RequestID != "" || r.HostID != "" }),
+		statuses:   slices.ContainsFunc(results, func(r Result) bool { return r.StatusCode != 0 }),
+		bodyTTFB:   slices.ContainsFunc(results, func(r Result) bool { return r.BodyTTFB > 0 }),
+		windows:    slices.ContainsFunc(results, func(r Result) bool { return r.Windows > 0 }),
+	}
+}
+
+func (c csvColumns) header() []string {
+	header := []string{"Timestamp", "Operation", "ObjectKey", "TTFB(ms)", "TTLB(ms)", "BytesDownloaded", "BytesUploaded", "Error",
+		"DNS(ms)", "Connect(ms)", "TLS(ms)", "FirstByte(ms)"}
+	if c.runs {
+		header = append(header, "RunId")
+	}
+	if c.copies {
+		header = append(header, "BytesCopied")
+	}
+	if c.decoded {
+		header = append(header, "BytesDecoded")
+	}
+	if c.versions {
+		header = append(header, "VersionId")
+	}
+	if c.groups {
+		header = append(header, "Group")
+	}
+	if c.buckets {
+		header = append(header, "Bucket")
+	}
+	if c.endpoints {
+		header = append(header, "Endpoint")
+	}
+	if c.warmup {
+		header = append(header, "Warmup")
+	}
+	if c.requestIDs {
+		header = append(header, "RequestId", "HostId")
+	}
+	if c.statuses {
+		header = append(header, "Status")
+	}
+	if c.bodyTTFB {
+		header = append(header, "BodyTTFB(ms)")
+	}
+	if c.windows {
+		header = append(header, "Windows", "MinWindow(MiB/s)")
+	}
+	return header
+}
+
+func (c csvColumns) row(r Result) []string {
+	row := []string{
+		r.Timestamp.Format(time.RFC3339Nano),
+		r.Operation,
+		r.ObjectKey,
+		fmt.Sprintf("%.3f", ms(r.TTFB)), // TTFB (ms) - will be 0.000 for PUTs or errors
+		fmt.Sprintf("%.3f", ms(r.TTLB)), // TTLB (ms)
+		fmt.Sprintf("%d", r.BytesDownloaded),
+		fmt.Sprintf("%d", r.BytesUploaded),
+		r.Error,
+		fmt.Sprintf("%.3f", ms(r.DNS)), // DNS, connect and TLS are 0.000 unless a new connection was opened
+		fmt.Sprintf("%.3f", ms(r.Connect)),
+		fmt.Sprintf("%.3f", ms(r.TLSHandshake)),
+		fmt.Sprintf("%.3f", ms(r.FirstByte)), // True time to first response byte
+	}
+	if c.runs {
+		row = append(row, r.RunID)
+	}
+	if c.copies {
+		row = append(row, strconv.FormatInt(r.BytesCopied, 10))
+	}
+	if c.decoded {
+		row = append(row, strconv.FormatInt(r.BytesDecoded, 10))
+	}
+	if c.versions {
+		row = append(row, r.VersionID)
+	}
+	if c.groups {
+		row = append(row, r.Group)
+	}
+	if c.buckets {
+		row = append(row, r.Bucket)
+	}
+	if c.endpoints {
+		row = append(row, r.Endpoint)
+	}
+	if c.warmup {
+		row = append(row, strconv.FormatBool(r.Warmup))
+	}
+	if c.requestIDs {
+		row = append(row, r.RequestID, r.HostID)
+	}
+	if c.statuses {
+		row = append(row, strconv.Itoa(r.StatusCode))
+	}
+	if c.bodyTTFB {
+		row = append(row, fmt.Sprintf("%.3f", ms(r.BodyTTFB)))
+	}
+	if c.windows {
+		row = append(row, strconv.Itoa(r.Windows), fmt.Sprintf("%.3f", r.MinWindowMiBps))
+	}
+	return row
+}
+
+// ResultRecord is the JSON, YAML and Parquet representation of a Result. Durations are in
+// milliseconds, with 0 meaning not measured, as in the CSV output.
+type ResultRecord struct {
+	Timestamp       time.Time `json:"timestamp" yaml:"timestamp" parquet:"timestamp"`
+	Operation       string    `json:"operation" yaml:"operation" parquet:"operation"`
+	RunID           string    `json:"runId,omitempty" yaml:"runId,omitempty" parquet:"runId,optional"`
+	Group           string    `json:"group,omitempty" yaml:"group,omitempty" parquet:"group,optional"`
+	Bucket          string    `json:"bucket,omitempty" yaml:"bucket,omitempty" parquet:"bucket,optional"`
+	Endpoint        string    `json:"endpoint,omitempty" yaml:"endpoint,omitempty" parquet:"endpoint,optional"`
+	ObjectKey       string    `json:"key" yaml:"key" parquet:"key"`
+	VersionID       string    `json:"versionId,omitempty" yaml:"versionId,omitempty" parquet:"versionId,optional"`
+	ETag            string    `json:"etag,omitempty" yaml:"etag,omitempty" parquet:"etag,optional"`
+	TTFBMs          float64   `json:"ttfbMs" yaml:"ttfbMs" parquet:"ttfbMs"`
+	BodyTTFBMs      float64   `json:"bodyTtfbMs,omitempty" yaml:"bodyTtfbMs,omitempty" parquet:"bodyTtfbMs,optional"`
+	TTLBMs          float64   `json:"ttlbMs" yaml:"ttlbMs" parquet:"ttlbMs"`
+	Windows         int       `json:"windows,omitempty" yaml:"windows,omitempty" parquet:"windows,optional"`
+	MinWindowMiBps  float64   `json:"minWindowMiBps,omitempty" yaml:"minWindowMiBps,omitempty" parquet:"minWindowMiBps,optional"`
+	BytesDownloaded int64     `json:"bytesDownloaded" yaml:"bytesDownloaded" parquet:"bytesDownloaded"`
+	BytesUploaded   int64     `json:"bytesUploaded" yaml:"bytesUploaded" parquet:"bytesUploaded"`
+	BytesCopied     int64     `json:"bytesCopied,omitempty" yaml:"bytesCopied,omitempty" parquet:"bytesCopied,optional"`
+	BytesDecoded    int64     `json:"bytesDecoded,omitempty" yaml:"bytesDecoded,omitempty" parquet:"bytesDecoded,optional"`
+	Error           string    `json:"error,omitempty" yaml:"error,omitempty" parquet:"error,optional"`
+	RequestID       string    `json:"requestId,omitempty" yaml:"requestId,omitempty" parquet:"requestId,optional"`
+	HostID          string    `json:"hostId,omitempty" yaml:"hostId,omitempty" parquet:"hostId,optional"`
+	StatusCode      int       `json:"statusCode,omitempty" yaml:"statusCode,omitempty" parquet:"statusCode,optional"`
+	Protocol        string    `json:"protocol,omitempty" yaml:"protocol,omitempty" parquet:"protocol,optional"`
+	Attempts        int       `json:"attempts,omitempty" yaml:"attempts,omitempty" parquet:"attempts,optional"`
+	RemoteAddr      string    `json:"remoteAddr,omitempty" yaml:"remoteAddr,omitempty" parquet:"remoteAddr,optional"`
+	ConnReused      bool      `json:"connReused,omitempty" yaml:"connReused,omitempty" parquet:"connReused,optional"`
+	ConnWaitMs      float64   `json:"connWaitMs,omitempty" yaml:"connWaitMs,omitempty" parquet:"connWaitMs,optional"`
+	DNSMs           float64   `json:"dnsMs,omitempty" yaml:"dnsMs,omitempty" parquet:"dnsMs,optional"`
+	ConnectMs       float64   `json:"connectMs,omitempty" yaml:"connectMs,omitempty" parquet:"connectMs,optional"`
+	TLSMs           float64   `json:"tlsMs,omitempty" yaml:"tlsMs,omitempty" parquet:"tlsMs,optional"`
+	FirstByteMs     float64   `json:"firstByteMs,omitempty" yaml:"firstByteMs,omitempty" parquet:"firstByteMs,optional"`
+	Hedged          bool      `json:"hedged,omitempty" yaml:"hedged,omitempty" parquet:"hedged,optional"`
+	HedgeWon        bool      `json:"hedgeWon,omitempty" yaml:"hedgeWon,omitempty" parquet:"hedgeWon,optional"`
+	Keys            int       `json:"keys,omitempty" yaml:"keys,omitempty" parquet:"keys,optional"`
+	Warmup          bool      `json:"warmup,omitempty" yaml:"warmup,omitempty" parquet:"warmup,optional"`
+	Integrity       string    `json:"integrity,omitempty" yaml:"integrity,omitempty" parquet:"integrity,optional"`
+	Checksum        string    `json:"checksum,omitempty" yaml:"checksum,omitempty" parquet:"checksum,optional"`
+	Overwrite       string    `json:"overwrite,omitempty" yaml:"overwrite,omitempty" parquet:"overwrite,optional"`
+	Disconnect      string    `json:"disconnect,omitempty" yaml:"disconnect,omitempty" parquet:"disconnect,optional"`
+	Append          string    `json:"append,omitempty" yaml:"append,omitempty" parquet:"append,optional"`
+	RMWMs           float64   `json:"rmwMs,omitempty" yaml:"rmwMs,omitempty" parquet:"rmwMs,optional"`
+	Conditional     string    `json:"conditional,omitempty" yaml:"conditional,omitempty" parquet:"conditional,optional"`
+	Consistency     string    `json:"consistency,omitempty" yaml:"consistency,omitempty" parquet:"consistency,optional"`
+	ProbeDelayMs    float64   `json:"probeDelayMs,omitempty" yaml:"probeDelayMs,omitempty" parquet:"probeDelayMs,optional"`
+	VisibleAfterMs  float64   `json:"visibleAfterMs,omitempty" yaml:"visibleAfterMs,omitempty" parquet:"visibleAfterMs,optional"` // -1 if the object never became visible
+	ScheduleDelayMs float64   `json:"scheduleDelayMs,omitempty" yaml:"scheduleDelayMs,omitempty" parquet:"scheduleDelayMs,optional"`
+}
+
+// NewResultRecord converts r for machine-readable output.
+func NewResultRecord(r Result) ResultRecord {
+	rec := ResultRecord{
+		Timestamp:       r.Timestamp,
+		Operation:       r.Operation,
+		RunID:           r.RunID,
+		Group:           r.Group,
+		Bucket:          r.Bucket,
+		Endpoint:        r.Endpoint,
+		ObjectKey:       r.ObjectKey,
+		VersionID:       r.VersionID,
+		ETag:            r.ETag,
+		TTFBMs:          ms(r.TTFB),
+		BodyTTFBMs:      ms(r.BodyTTFB),
+		TTLBMs:          ms(r.TTLB),
+		Windows:         r.Windows,
+		MinWindowMiBps:  r.MinWindowMiBps,
+		BytesDownloaded: r.BytesDownloaded,
+		BytesUploaded:   r.BytesUploaded,
+		BytesCopied:     r.BytesCopied,
+		BytesDecoded:    r.BytesDecoded,
+		Error:           r.Error,
+		RequestID:       r.RequestID,
+		HostID:          r.HostID,
+		StatusCode:      r.StatusCode,
+		Protocol:        r.Protocol,
+		Attempts:        r.Attempts,
+		RemoteAddr:      r.RemoteAddr,
+		ConnReused:      r.ConnReused,
+		ConnWaitMs:      ms(r.ConnWait),
+		DNSMs:           ms(r.DNS),
+		ConnectMs:       ms(r.Connect),
+		TLSMs:           ms(r.TLSHandshake),
+		FirstByteMs:     ms(r.FirstByte),
+		Hedged:          r.Hedged,
+		HedgeWon:        r.HedgeWon,
+		Keys:            r.Keys,
+		Warmup:          r.Warmup,
+		Integrity:       r.Integrity,
+		Checksum:        r.Checksum,
+		Overwrite:       r.Overwrite,
+		Disconnect:      r.Disconnect,
+		Append:          r.Append,
+		RMWMs:           ms(r.RMW),
+		Conditional:     r.Conditional,
+		Consistency:     r.Consistency,
+		ProbeDelayMs:    ms(r.ProbeDelay),
+		VisibleAfterMs:  ms(r.VisibleAfter),
+		ScheduleDelayMs: ms(r.scheduleDelay()),
+	}
+	if r.VisibleAfter < 0 {
+		rec.VisibleAfterMs = -1
+	}
+	return rec
+}
+
+func encodeResultsJSONL(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(NewResultRecord(r)); err != nil {
+			return fmt.Errorf("failed to write jsonl record: %w", err)
+		}
+	}
+	return nil
+}
+
+// encodeResultsJSON writes results as JSON lines like FormatJSONL, followed by one
+// {"summary": {...}} line holding the run summary when stats are available. Consumers
+// can tell the lines apart by the presence of the "summary" key.
+func encodeResultsJSON(w io.Writer, results []Result, s *Stats) error {
+	if err := encodeResultsJSONL(w, results); err != nil {
+		return err
+	}
+	if s == nil {
+		return nil
+	}
+	return encodeSummaryLine(w, s)
+}
+
+// encodeSummaryLine writes the {"summary": {...}} line ending FormatJSON results.
+func encodeSummaryLine(w io.Writer, s *Stats) error {
+	line := struct {
+		Summary Summary `json:"summary"`
+	}{s.Summary()}
+	if err := json.NewEncoder(w).Encode(line); err != nil {
+		return fmt.Errorf("failed to write json summary: %w", err)
+	}
+	return nil
+}
+
+// LatencySummary holds latency figures in milliseconds.
+type LatencySummary struct {
+	Min float64 `json:"min" yaml:"min"`
+	Avg float64 `json:"avg" yaml:"avg"`
+	P50 float64 `json:"p50" yaml:"p50"`
+	P90 float64 `json:"p90" yaml:"p90"`
+	P99 float64 `json:"p99" yaml:"p99"`
+	Max float64 `json:"max" yaml:"max"`
+	// P999 is only reported for request latencies, which are kept in histograms
+	P999 float64 `json:"p999,omitempty" yaml:"p999,omitempty"`
+}
+
+// OperationSummary holds the figures for one operation type.
+type OperationSummary struct {
+	Total         int64           `json:"total" yaml:"total"`
+	Success       int64           `json:"success" yaml:"success"`
+	Bytes         int64           `json:"bytes" yaml:"bytes"`
+	ThroughputMiB float64         `json:"throughputMiBps" yaml:"throughputMiBps"`
+	TTFB          *LatencySummary `json:"ttfbMs,omitempty" yaml:"ttfbMs,omitempty"`
+	BodyTTFB      *LatencySummary `json:"bodyTtfbMs,omitempty" yaml:"bodyTtfbMs,omitempty"` // GET only: until the first body byte was read
+	TTLB          *LatencySummary `json:"ttlbMs,omitempty" yaml:"ttlbMs,omitempty"`
+	Keys          int64           `json:"keys,omitempty" yaml:"keys,omitempty"`             // LIST only
+	KeysPerSec    float64         `json:"keysPerSec,omitempty" yaml:"keysPerSec,omitempty"` // LIST only
+	// GET only, with -accept-encoding: object bytes after decoding compressed bodies
+	DecodedBytes         int64   `json:"decodedBytes,omitempty" yaml:"decodedBytes,omitempty"`
+	DecodedThroughputMiB float64 `json:"decodedThroughputMiBps,omitempty" yaml:"decodedThroughputMiBps,omitempty"`
+}
+
+// Summary is the machine-readable form of PrintSummary.
+type Summary struct {
+	RunID           string              `json:"runId,omitempty" yaml:"runId,omitempty"`
+	DurationSeconds float64             `json:"durationSeconds" yaml:"durationSeconds"`
+	Concurrency     int                 `json:"concurrency" yaml:"concurrency"`
+	Agents          int                 `json:"agents,omitempty" yaml:"agents,omitempty"`
+	ThinkTime       string              `json:"thinkTime,omitempty" yaml:"thinkTime,omitempty"`
+	TotalRequests   int64               `json:"totalRequests" yaml:"totalRequests"`
+	TotalSuccess    int64               `json:"totalSuccess" yaml:"totalSuccess"`
+	TotalErrors     int64               `json:"totalErrors" yaml:"totalErrors"`
+	RequestsPerSec  float64             `json:"requestsPerSec" yaml:"requestsPerSec"`
+	ErrorsByClass   []ErrorClassStats   `json:"errorsByClass,omitempty" yaml:"errorsByClass,omitempty"`
+	StatusCodes     []StatusCodeStats   `json:"statusCodes,omitempty" yaml:"statusCodes,omitempty"`
+	Retries         *RetryReport        `json:"retries,omitempty" yaml:"retries,omitempty"`
+	SLOs            []SLOResult         `json:"slos,omitempty" yaml:"slos,omitempty"`
+	Get             OperationSummary    `json:"get" yaml:"get"`
+	Put             OperationSummary    `json:"put" yaml:"put"`
+	GetBySize       []*SizeClassStats   `json:"getBySize,omitempty" yaml:"getBySize,omitempty"`
+	PutBySize       []*SizeClassStats   `json:"putBySize,omitempty" yaml:"putBySize,omitempty"`
+	Head            *OperationSummary   `json:"head,omitempty" yaml:"head,omitempty"`
+	List            *OperationSummary   `json:"list,omitempty" yaml:"list,omitempty"`
+	Copy            *OperationSummary   `json:"copy,omitempty" yaml:"copy,omitempty"`
+	Tagging         *OperationSummary   `json:"tagging,omitempty" yaml:"tagging,omitempty"`
+	Delete          *OperationSummary   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Groups          []*GroupStats       `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Buckets         []*BucketStats      `json:"buckets,omitempty" yaml:"buckets,omitempty"`
+	Endpoints       []*EndpointStats    `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Writes          *WriteReport        `json:"writes,omitempty" yaml:"writes,omitempty"`
+	Hedging         *HedgeReport        `json:"hedging,omitempty" yaml:"hedging,omitempty"`
+	Integrity       *IntegrityReport    `json:"integrity,omitempty" yaml:"integrity,omitempty"`
+	Checksums       *ChecksumReport     `json:"checksums,omitempty" yaml:"checksums,omitempty"`
+	Revalidation    *RevalidationReport `json:"revalidation,omitempty" yaml:"revalidation,omitempty"`
+	Contention      *ContentionReport   `json:"contention,omitempty" yaml:"contention,omitempty"`
+	Overwrites      *OverwriteReport    `json:"overwrites,omitempty" yaml:"overwrites,omitempty"`
+	Disconnects     *DisconnectReport   `json:"disconnects,omitempty" yaml:"disconnects,omitempty"`
+	Appends         *AppendReport       `json:"appends,omitempty" yaml:"appends,omitempty"`
+	GetThroughput   *ThroughputReport   `json:"getThroughput,omitempty" yaml:"getThroughput,omitempty"`
+	Consistency     *ConsistencyReport  `json:"consistency,omitempty" yaml:"consistency,omitempty"`
+	Arrivals        *ArrivalReport      `json:"arrivals,omitempty" yaml:"arrivals,omitempty"`
+	Tuning          *TuningReport       `json:"tuning,omitempty" yaml:"tuning,omitempty"`
+	ScheduleDelay   *LatencySummary     `json:"scheduleDelayMs,omitempty" yaml:"scheduleDelayMs,omitempty"`
+	Corrected       []CorrectedLatency  `json:"correctedLatency,omitempty" yaml:"correctedLatency,omitempty"`
+	Stages          []*StageStats       `json:"stages,omitempty" yaml:"stages,omitempty"`
+	ConnSetup       *LatencySummary     `json:"connSetupMs,omitempty" yaml:"connSetupMs,omitempty"`
+	Phases          []PhaseLatency      `json:"phases,omitempty" yaml:"phases,omitempty"`
+	ClientWarnings  []string            `json:"clientWarnings,omitempty" yaml:"clientWarnings,omitempty"`
+	NICBound        bool                `json:"nicBound,omitempty" yaml:"nicBound,omitempty"`
+	Outliers        []ResultRecord      `json:"outliers,omitempty" yaml:"outliers,omitempty"`
+}
+
+// Summary returns the headline figures of a calculated Stats.
+func (s *Stats) Summary() Summary {
+	secs := s.actualDuration.Seconds()
+	perSec := func(v float64) float64 {
+		if secs <= 0 {
+			return 0
+		}
+		return v / secs
+	}
+
+	sum := Summary{
+		DurationSeconds: secs,
+		RunID:           s.RunID,
+		Concurrency:     s.Concurrency,
+		Agents:          s.Agents,
+		ThinkTime:       s.ThinkTime,
+		TotalRequests:   s.TotalRequests,
+		TotalSuccess:    s.TotalRequests - s.TotalErrors,
+		TotalErrors:     s.TotalErrors,
+		RequestsPerSec:  perSec(float64(s.TotalRequests)),
+		Get: OperationSummary{
+			Total:         s.TotalGets,
+			Success:       s.GetTTLBHist.Count(),
+			Bytes:         s.TotalBytesDown,
+			ThroughputMiB: perSec(float64(s.TotalBytesDown) / (1024 * 1024)),
+		},
+		Put: OperationSummary{
+			Total:         s.TotalPuts,
+			Success:       s.PutTTLBHist.Count(),
+			Bytes:         s.TotalBytesUp,
+			ThroughputMiB: perSec(float64(s.TotalBytesUp) / (1024 * 1024)),
+		},
+	}
+	if s.EncodedGets > 0 {
+		sum.Get.DecodedBytes = s.TotalBytesObject
+		sum.Get.DecodedThroughputMiB = perSec(float64(s.TotalBytesObject) / (1024 * 1024))
+	}
+	if s.GetTTLBHist.Count() > 0 {
+		sum.Get.TTFB = &LatencySummary{ms(s.MinGetTTFB), ms(s.AvgGetTTFB), ms(s.P50GetTTFB), ms(s.P90GetTTFB), ms(s.P99GetTTFB), ms(s.MaxGetTTFB), ms(s.P999GetTTFB)}
+		sum.Get.BodyTTFB = histogramSummary(s.GetBodyTTFBHist)
+		sum.Get.TTLB = &LatencySummary{ms(s.MinGetTTLB), ms(s.AvgGetTTLB), ms(s.P50GetTTLB), ms(s.P90GetTTLB), ms(s.P99GetTTLB), ms(s.MaxGetTTLB), ms(s.P999GetTTLB)}
+	}
+	if s.PutTTLBHist.Count() > 0 {
+		sum.Put.TTLB = &LatencySummary{ms(s.MinPutTTLB), ms(s.AvgPutTTLB), ms(s.P50PutTTLB), ms(s.P90PutTTLB), ms(s.P99PutTTLB), ms(s.MaxPutTTLB), ms(s.P999PutTTLB)}
+	}
+	if s.TotalHeads > 0 {
+		sum.Head = &OperationSummary{Total: s.TotalHeads, Success: s.HeadTTLBHist.Count()}
+		if s.HeadTTLBHist.Count() > 0 {
+			sum.Head.TTLB = &LatencySummary{ms(s.MinHeadTTLB), ms(s.AvgHeadTTLB), ms(s.P50HeadTTLB), ms(s.P90HeadTTLB), ms(s.P99HeadTTLB), ms(s.MaxHeadTTLB), ms(s.P999HeadTTLB)}
+		}
+	}
+	sum.ErrorsByClass = s.ErrorClasses()
+	sum.StatusCodes = s.StatusCodes()
+	sum.Retries = s.Retries()
+	sum.SLOs = s.SLOResults()
+	sum.GetBySize = s.SizeClasses()
+	sum.PutBySize = s.PutSizeClasses()
+	sum.List = s.listSummary()
+	sum.Copy = s.copySummary()
+	sum.Tagging = s.taggingSummary()
+	sum.Delete = s.deleteSummary()
+	sum.Groups = s.GroupStats()
+	sum.Buckets = s.BucketStats()
+	sum.Endpoints = s.EndpointStats()
+	sum.Writes = s.Writes()
+	sum.Hedging = s.Hedging()
+	sum.Integrity = s.Integrity()
+	sum.Checksums = s.Checksums()
+	sum.Revalidation = s.Revalidation()
+	sum.Contention = s.Contention()
+	sum.Overwrites = s.Overwrites()
+	sum.Disconnects = s.Disconnects()
+	sum.Appends = s.Appends()
+	sum.GetThroughput = s.GetThroughput()
+	sum.Consistency = s.Consistency()
+	sum.Arrivals = s.Arrivals
+	sum.Tuning = s.Tuning
+	sum.ScheduleDelay = s.ScheduleDelay()
+	sum.Corrected = s.CorrectedLatencies()
+	sum.Stages = s.Stages()
+	sum.ConnSetup = s.connSetupLatency()
+	sum.Phases = s.Phases()
+	if s.Client != nil {
+		sum.ClientWarnings = s.Client.Warnings
+	}
+	if s.NIC != nil {
+		sum.NICBound = s.NIC.LikelyNICBound
+	}
+	for _, r := range s.Outliers() {
+		sum.Outliers = append(sum.Outliers, NewResultRecord(r))
+	}
+	return sum
+}
+
+func encodeSummaryJSON(w io.Writer, s *Stats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.Summary()); err != nil {
+		return fmt.Errorf("failed to write json summary: %w", err)
+	}
+	return nil
+}
+
+func encodeSummaryYAML(w io.Writer, s *Stats) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+	if err := enc.Encode(s.Summary()); err != nil {
+		return fmt.Errorf("failed to write yaml summary: %w", err)
+	}
+	return nil
+}
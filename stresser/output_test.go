@@ -0,0 +1,264 @@
+package stresser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteResultsJSONL(t *testing.T) {
+	now := time.Now()
+	results := []Result{
+		{Timestamp: now, Operation: "GET", ObjectKey: "key1.txt", TTFB: 50 * time.Millisecond, TTLB: 100 * time.Millisecond, BytesDownloaded: 1024, RequestID: "REQ1"},
+		{Timestamp: now, Operation: "PUT", ObjectKey: "key2.txt", TTFB: -1, TTLB: -1, Error: "access denied"},
+	}
+
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	if err := WriteResults(results, path, FormatJSONL); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+
+	var records []ResultRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec ResultRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].ObjectKey != "key1.txt" || records[0].TTLBMs != 100 || records[0].RequestID != "REQ1" {
+		t.Errorf("Unexpected first record: %+v", records[0])
+	}
+	if records[1].Error != "access denied" || records[1].TTLBMs != 0 {
+		t.Errorf("Unexpected second record: %+v", records[1])
+	}
+}
+
+func TestWriteResultsJSONWithSummary(t *testing.T) {
+	now := time.Now()
+	results := []Result{
+		{Timestamp: now, Operation: "GET", ObjectKey: "key1.txt", TTFB: 50 * time.Millisecond, TTLB: 100 * time.Millisecond, BytesDownloaded: 1024},
+		{Timestamp: now, Operation: "GET", ObjectKey: "key2.txt", TTFB: 60 * time.Millisecond, TTLB: 120 * time.Millisecond, BytesDownloaded: 1024},
+	}
+	stats := NewStats()
+	for _, r := range results {
+		stats.AddResult(r)
+	}
+	stats.Calculate(now, now.Add(time.Second))
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := WriteResultsWithStats(results, stats, path, InferResultFormat(path)); err != nil {
+		t.Fatalf("WriteResultsWithStats failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 2 records and a summary line, got %d lines", len(lines))
+	}
+	var rec ResultRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil || rec.ObjectKey != "key1.txt" {
+		t.Errorf("Unexpected first record %q: %v", lines[0], err)
+	}
+	var last struct {
+		Summary *Summary `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil || last.Summary == nil {
+		t.Fatalf("Last line %q is not a summary: %v", lines[2], err)
+	}
+	if last.Summary.TotalRequests != 2 || last.Summary.Get.Total != 2 {
+		t.Errorf("Unexpected summary: %+v", last.Summary)
+	}
+}
+
+func TestInferResultFormat(t *testing.T) {
+	cases := map[string]string{
+		"results.json":    FormatJSON,
+		"RESULTS.JSONL":   FormatJSONL,
+		"results.sql":     FormatSQL,
+		"results.db":      FormatSQLite,
+		"results.parquet": FormatParquet,
+		"results.csv":     FormatCSV,
+		"-":               FormatCSV,
+	}
+	for path, want := range cases {
+		if got := InferResultFormat(path); got != want {
+			t.Errorf("InferResultFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestWriteResultsCSVWarmupColumn(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	measured := Result{Timestamp: now, Operation: "GET", ObjectKey: "key1.txt", TTLB: time.Millisecond}
+	warm := Result{Timestamp: now, Operation: "GET", ObjectKey: "key2.txt", TTLB: time.Millisecond, Warmup: true}
+
+	plain := filepath.Join(dir, "plain.csv")
+	if err := WriteResults([]Result{measured}, plain, FormatCSV); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+	if data, _ := os.ReadFile(plain); strings.Contains(string(data), "Warmup") {
+		t.Errorf("Warmup column should only be written when warm-up results are present:\n%s", data)
+	}
+
+	flagged := filepath.Join(dir, "flagged.csv")
+	if err := WriteResults([]Result{warm, measured}, flagged, FormatCSV); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+	data, _ := os.ReadFile(flagged)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], ",Warmup") || !strings.HasSuffix(lines[1], ",true") || !strings.HasSuffix(lines[2], ",false") {
+		t.Errorf("Unexpected CSV with warm-up results:\n%s", data)
+	}
+}
+
+func TestWriteResultsUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.out")
+	for _, format := range []string{"xml", "avro"} {
+		if err := WriteResults(nil, path, format); err == nil {
+			t.Errorf("Expected error for format %q", format)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Output file should not be created for an unsupported format")
+	}
+}
+
+func TestWriteSummaryFormats(t *testing.T) {
+	stats := NewStats()
+	stats.Concurrency = 4
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "a", TTFB: 10 * time.Millisecond, TTLB: 20 * time.Millisecond, BytesDownloaded: 1024})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "b", TTFB: -1, TTLB: -1, Error: "failed"})
+	stats.Calculate(now, now.Add(2*time.Second))
+
+	var buf bytes.Buffer
+	if err := WriteSummary(&buf, stats, FormatJSON); err != nil {
+		t.Fatalf("json summary failed: %v", err)
+	}
+	var sum Summary
+	if err := json.Unmarshal(buf.Bytes(), &sum); err != nil {
+		t.Fatalf("Invalid json summary: %v", err)
+	}
+	if sum.TotalRequests != 2 || sum.TotalErrors != 1 || sum.Concurrency != 4 || sum.RequestsPerSec != 1 {
+		t.Errorf("Unexpected summary: %+v", sum)
+	}
+	if sum.Get.Success != 1 || sum.Get.TTLB == nil || sum.Get.TTLB.Max != 20 {
+		t.Errorf("Unexpected GET summary: %+v", sum.Get)
+	}
+	if sum.Put.TTLB != nil {
+		t.Error("PUT latency should be omitted without successful PUTs")
+	}
+
+	buf.Reset()
+	if err := WriteSummary(&buf, stats, FormatYAML); err != nil {
+		t.Fatalf("yaml summary failed: %v", err)
+	}
+	var yamlSum Summary
+	if err := yaml.Unmarshal(buf.Bytes(), &yamlSum); err != nil {
+		t.Fatalf("Invalid yaml summary: %v", err)
+	}
+	if yamlSum.TotalRequests != 2 {
+		t.Errorf("Unexpected yaml summary: %+v", yamlSum)
+	}
+
+	buf.Reset()
+	if err := WriteSummary(&buf, stats, FormatText); err != nil || !strings.Contains(buf.String(), "Stress Test Summary") {
+		t.Errorf("text summary failed: %v", err)
+	}
+	if err := WriteSummary(&buf, stats, "html"); err == nil {
+		t.Error("Expected error for unknown summary format")
+	}
+}
+
+func TestSummaryOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected *os.File
+	}{
+		{name: "default", cfg: Config{OutputFile: "results.csv"}, expected: os.Stdout},
+		{name: "results on stdout", cfg: Config{OutputFile: StdoutPath}, expected: os.Stderr},
+		{name: "explicit stdout", cfg: Config{OutputFile: "results.csv", SummaryFile: StdoutPath}, expected: os.Stdout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.cfg.SummaryOutput()
+			if err != nil {
+				t.Fatalf("SummaryOutput failed: %v", err)
+			}
+			if nop, ok := out.(nopWriteCloser); !ok || nop.Writer != tt.expected {
+				t.Errorf("unexpected summary destination %v", out)
+			}
+			if err := out.Close(); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	cfg := Config{OutputFile: StdoutPath, SummaryFile: path}
+	out, err := cfg.SummaryOutput()
+	if err != nil {
+		t.Fatalf("SummaryOutput failed: %v", err)
+	}
+	if err := WriteSummary(out, NewStats(), FormatJSON); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	out.Close()
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Errorf("summary file not written: %v", err)
+	}
+}
+
+func TestWriteResultLine(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "a", TTFB: 10 * time.Millisecond, TTLB: 20 * time.Millisecond, BytesDownloaded: 1024})
+	stats.Calculate(now, now.Add(time.Second))
+
+	var buf bytes.Buffer
+	if err := WriteResultLine(&buf, stats, FormatText); err != nil {
+		t.Fatalf("WriteResultLine failed: %v", err)
+	}
+	out := buf.String()
+	if strings.Count(out, "\n") != 1 || !strings.HasPrefix(out, "status=pass requests=1 errors=0 ") {
+		t.Errorf("unexpected key=value line: %q", out)
+	}
+
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "b", TTFB: -1, TTLB: -1, Error: "not found"})
+	buf.Reset()
+	if err := WriteResultLine(&buf, stats, FormatJSON); err != nil {
+		t.Fatalf("WriteResultLine failed: %v", err)
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("expected a single JSON line, got %q", buf.String())
+	}
+	var line ResultLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if line.Status != "fail" || line.Requests != 2 || line.Errors != 1 {
+		t.Errorf("unexpected result line: %+v", line)
+	}
+}
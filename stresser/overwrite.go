@@ -0,0 +1,173 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Write modes of 'write' mode (Config.WriteMode).
+const (
+	WriteModeUnique    = "unique"    // Every PUT goes to a new key (the default)
+	WriteModeOverwrite = "overwrite" // PUTs replace the objects of a fixed pool of keys
+)
+
+// DefaultOverwriteKeys is the number of keys -write-mode overwrite writes to when -keypool is not set.
+const DefaultOverwriteKeys = 1000
+
+// Outcomes of a PUT with -write-mode overwrite, stored in Result.Overwrite.
+const (
+	OverwriteNew      = "new"      // First write of the key in the run
+	OverwriteReplaced = "replaced" // The key was written earlier in the run
+)
+
+// validateWriteMode checks -write-mode. Called from Validate, after validateContention.
+func (c *Config) validateWriteMode() error {
+	c.WriteMode = strings.ToLower(c.WriteMode)
+	switch c.WriteMode {
+	case "", WriteModeUnique:
+		return nil
+	case WriteModeOverwrite:
+	default:
+		return fmt.Errorf("invalid write mode (-write-mode): %s. Must be '%s' or '%s'", c.WriteMode, WriteModeUnique, WriteModeOverwrite)
+	}
+	if c.OperationType != "write" {
+		return fmt.Errorf("-write-mode %s only applies to 'write' mode", WriteModeOverwrite)
+	}
+	if c.KeyTemplate != "" {
+		return fmt.Errorf("-write-mode %s writes to a pool of keys (-keypool) and cannot be combined with -key-template", WriteModeOverwrite)
+	}
+	if c.KeyPool == 0 {
+		c.KeyPool = DefaultOverwriteKeys
+	}
+	c.FileCount = 0 // The workers overwrite the pool for the duration of the run
+	return nil
+}
+
+// overwriteTracker tells the first write of a key of the pool from the overwrites after it,
+// and keeps the last successful write of each key for the manifest. Shared by the workers.
+type overwriteTracker struct {
+	mu      sync.Mutex
+	written map[string]ManifestEntry
+}
+
+func newOverwriteTracker() *overwriteTracker {
+	return &overwriteTracker{written: make(map[string]ManifestEntry)}
+}
+
+// record notes a successful PUT and returns its outcome, OverwriteNew or OverwriteReplaced.
+func (t *overwriteTracker) record(e ManifestEntry) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, seen := t.written[e.Key]
+	t.written[e.Key] = e
+	if seen {
+		return OverwriteReplaced
+	}
+	return OverwriteNew
+}
+
+// writeManifest adds the last write of every key written to the manifest, in key order, so
+// each key is listed once with the size and ETag it ended up with.
+func (t *overwriteTracker) writeManifest(mw *ManifestWriter) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := make([]string, 0, len(t.written))
+	for key := range t.written {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if err := mw.AddEntry(t.written[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OverwriteReport summarizes the PUTs of -write-mode overwrite. They are also counted in the
+// PUT figures.
+type OverwriteReport struct {
+	Puts          int64           `json:"puts" yaml:"puts"`
+	NewKeys       int64           `json:"newKeys" yaml:"newKeys"`       // Keys written for the first time in the run
+	Overwrites    int64           `json:"overwrites" yaml:"overwrites"` // PUTs that replaced an object written earlier in the run
+	OverwritePct  float64         `json:"overwritePct" yaml:"overwritePct"`
+	Versions      int64           `json:"versions,omitempty" yaml:"versions,omitempty"`               // PUTs that returned a version id, each a version the bucket keeps
+	VersionBytes  int64           `json:"versionBytes,omitempty" yaml:"versionBytes,omitempty"`       // Bytes of those versions
+	NewTTLB       *LatencySummary `json:"newTtlbMs,omitempty" yaml:"newTtlbMs,omitempty"`             // Latency of first writes
+	OverwriteTTLB *LatencySummary `json:"overwriteTtlbMs,omitempty" yaml:"overwriteTtlbMs,omitempty"` // Latency of overwrites
+}
+
+// overwriteStats holds the latencies of PUTs by outcome and the versions they created.
+type overwriteStats struct {
+	fresh        *Histogram
+	replaced     *Histogram
+	versions     int64
+	versionBytes int64
+}
+
+// addOverwriteResult records a PUT of -write-mode overwrite. Called from AddResult.
+func (s *Stats) addOverwriteResult(r Result) {
+	if r.Operation != "PUT" || r.Overwrite == "" {
+		return
+	}
+	if s.overwritePuts == nil {
+		s.overwritePuts = &overwriteStats{fresh: NewHistogram(), replaced: NewHistogram()}
+	}
+	if r.Overwrite == OverwriteReplaced {
+		s.overwritePuts.replaced.Record(r.TTLB)
+	} else {
+		s.overwritePuts.fresh.Record(r.TTLB)
+	}
+	if r.VersionID != "" {
+		s.overwritePuts.versions++
+		s.overwritePuts.versionBytes += r.BytesUploaded
+	}
+}
+
+// Overwrites returns the figures of -write-mode overwrite, or nil if it was not used.
+func (s *Stats) Overwrites() *OverwriteReport {
+	if s.overwritePuts == nil {
+		return nil
+	}
+	fresh, replaced := s.overwritePuts.fresh, s.overwritePuts.replaced
+	or := &OverwriteReport{
+		Puts:          fresh.Count() + replaced.Count(),
+		NewKeys:       fresh.Count(),
+		Overwrites:    replaced.Count(),
+		Versions:      s.overwritePuts.versions,
+		VersionBytes:  s.overwritePuts.versionBytes,
+		NewTTLB:       histogramSummary(fresh),
+		OverwriteTTLB: histogramSummary(replaced),
+	}
+	or.OverwritePct = percentOf(or.Overwrites, or.Puts)
+	return or
+}
+
+// printOverwriteSummary prints how many PUTs replaced existing objects, the versions they
+// created and the latencies of first writes and overwrites as part of PrintSummary.
+func (s *Stats) printOverwriteSummary(w io.Writer) {
+	or := s.Overwrites()
+	if or == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nKey Overwrites:\n")
+	fmt.Fprintf(w, "  PUTs:             %d (new keys: %d, overwrites: %d = %.2f%%)\n", or.Puts, or.NewKeys, or.Overwrites, or.OverwritePct)
+	if or.Versions > 0 {
+		fmt.Fprintf(w, "  Versions Created: %d (%.2f MiB kept by the versioned bucket)\n", or.Versions, float64(or.VersionBytes)/(1024*1024))
+	}
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	for _, row := range []struct {
+		label string
+		l     *LatencySummary
+	}{{"New Key", or.NewTTLB}, {"Overwrite", or.OverwriteTTLB}} {
+		if row.l == nil {
+			continue
+		}
+		fmt.Fprintf(w, "  %-13s |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			row.label, row.l.Min, row.l.Avg, row.l.P50, row.l.P90, row.l.P99, row.l.P999, row.l.Max)
+	}
+}
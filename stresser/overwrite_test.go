@@ -0,0 +1,82 @@
+package stresser
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateWriteMode(t *testing.T) {
+	c := &Config{OperationType: "write", WriteMode: "Overwrite", FileCount: DefaultFileCount}
+	if err := c.validateWriteMode(); err != nil {
+		t.Fatalf("validateWriteMode failed: %v", err)
+	}
+	if c.WriteMode != WriteModeOverwrite || c.KeyPool != DefaultOverwriteKeys || c.FileCount != 0 {
+		t.Errorf("Expected the default pool and continuous workers, got %+v", c)
+	}
+	if c := (&Config{OperationType: "write", WriteMode: "overwrite", KeyPool: 10}); c.validateWriteMode() != nil || c.KeyPool != 10 {
+		t.Errorf("Expected -keypool to be kept, got %d", c.KeyPool)
+	}
+	if c := (&Config{OperationType: "read", WriteMode: WriteModeUnique}); c.validateWriteMode() != nil {
+		t.Error("Expected 'unique' to be accepted in any mode")
+	}
+	for _, c := range []*Config{
+		{OperationType: "write", WriteMode: "append"},
+		{OperationType: "mixed", WriteMode: "overwrite"},
+		{OperationType: "write", WriteMode: "overwrite", KeyTemplate: "x/{seq}"},
+	} {
+		if err := c.validateWriteMode(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+}
+
+func TestOverwriteTracker(t *testing.T) {
+	tracker := newOverwriteTracker()
+	keys := poolKeys("test", "overwrite", 2)
+	for i, want := range []string{OverwriteNew, OverwriteNew, OverwriteReplaced, OverwriteReplaced} {
+		if got := tracker.record(ManifestEntry{Key: keys[i%2], VersionID: strings.Repeat("v", i+1)}); got != want {
+			t.Errorf("Write %d: expected %q, got %q", i, want, got)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "manifest.txt")
+	mw, err := NewManifestWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.writeManifest(mw); err != nil {
+		t.Fatalf("writeManifest failed: %v", err)
+	}
+	mw.Close()
+	entries, err := LoadManifestEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Key != keys[0] || entries[0].VersionID != "vvv" || entries[1].VersionID != "vvvv" {
+		t.Errorf("Expected each key once with its last version, got %+v", entries)
+	}
+}
+
+func TestOverwriteStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: 2 * time.Millisecond, BytesUploaded: 1 << 20, Overwrite: OverwriteNew, VersionID: "a"})
+	for range 3 {
+		stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: 4 * time.Millisecond, BytesUploaded: 1 << 20, Overwrite: OverwriteReplaced, VersionID: "b"})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: 4 * time.Millisecond, BytesUploaded: 1 << 20})
+	stats.Calculate(now, now.Add(time.Second))
+
+	or := stats.Summary().Overwrites
+	if or == nil || or.Puts != 4 || or.NewKeys != 1 || or.Overwrites != 3 || or.OverwritePct != 75 || or.Versions != 4 || or.VersionBytes != 4<<20 {
+		t.Fatalf("Unexpected overwrite report %+v", or)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Key Overwrites:") || !strings.Contains(buf.String(), "Versions Created: 4 (4.00 MiB") {
+		t.Errorf("Summary lacks the overwrite figures:\n%s", buf.String())
+	}
+}
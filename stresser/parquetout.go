@@ -0,0 +1,53 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupSize is the number of results buffered per Parquet row group.
+const parquetRowGroupSize = 64 * 1024
+
+// encodeResultsParquet writes results as a Parquet file with one row per result and the
+// columns of ResultRecord. Fields that are omitted from empty JSON records are optional
+// columns, null where the JSON record lacks them.
+func encodeResultsParquet(w io.Writer, results []Result) error {
+	pw := parquet.NewGenericWriter[ResultRecord](w, parquet.Compression(&parquet.Zstd))
+	rows := make([]ResultRecord, 0, min(len(results), parquetRowGroupSize))
+	for i, r := range results {
+		rows = append(rows, NewResultRecord(r))
+		if len(rows) == parquetRowGroupSize || i == len(results)-1 {
+			if _, err := pw.Write(rows); err != nil {
+				return fmt.Errorf("failed to write parquet rows: %w", err)
+			}
+			if err := pw.Flush(); err != nil {
+				return fmt.Errorf("failed to write parquet row group: %w", err)
+			}
+			rows = rows[:0]
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return fmt.Errorf("failed to write parquet footer: %w", err)
+	}
+	return nil
+}
+
+// decodeResultsParquet reads the results of a file written by encodeResultsParquet.
+func decodeResultsParquet(f *os.File) ([]Result, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	rows, err := parquet.Read[ResultRecord](f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet results: %w", err)
+	}
+	results := make([]Result, len(rows))
+	for i, rec := range rows {
+		results[i] = rec.Result()
+	}
+	return results, nil
+}
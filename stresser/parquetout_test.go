@@ -0,0 +1,39 @@
+package stresser
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteResultsParquet(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	results := []Result{
+		{Timestamp: start, Operation: "GET", ObjectKey: "a", RunID: "run1", TTFB: 5 * time.Millisecond, TTLB: 10 * time.Millisecond, BytesDownloaded: 1024, Attempts: 1, ConnReused: true},
+		{Timestamp: start.Add(time.Second), Operation: "PUT", ObjectKey: "b", RunID: "run1", TTFB: -1, TTLB: -1, Error: "denied"},
+	}
+	path := filepath.Join(t.TempDir(), "results.parquet")
+	if err := WriteResults(results, path, InferResultFormat(path)); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+
+	loaded, err := LoadResults(path, "")
+	if err != nil {
+		t.Fatalf("LoadResults failed: %v", err)
+	}
+	if len(loaded) != len(results) {
+		t.Fatalf("Expected %d results, got %d", len(results), len(loaded))
+	}
+	rows := make([]ResultRecord, len(loaded))
+	for i, r := range loaded {
+		rows[i] = NewResultRecord(r)
+	}
+	for i, r := range results {
+		want := NewResultRecord(r)
+		got := rows[i]
+		if !got.Timestamp.Equal(want.Timestamp) || got.Operation != want.Operation || got.ObjectKey != want.ObjectKey || got.RunID != want.RunID ||
+			got.TTLBMs != want.TTLBMs || got.BytesDownloaded != want.BytesDownloaded || got.Error != want.Error || got.ConnReused != want.ConnReused {
+			t.Errorf("Row %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package stresser
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// Payload generation parameters. Every PUT body is a view of one shared block of random
+// words, XORed with a key that changes every payloadChunk bytes and depends on a per-body
+// seed. Bodies are unique per PUT and per chunk, so stores cannot deduplicate or compress
+// them, yet they cost no allocation and only one XOR per eight bytes to produce. Other
+// data patterns are derived from the same block, see dataPattern.
+const (
+	payloadBlockWords = 128 * 1024 // 1 MiB shared block
+	payloadChunk      = 64 * 1024
+)
+
+var (
+	payloadBlockOnce sync.Once
+	payloadBlock     []uint64
+)
+
+// sharedPayloadBlock returns the block all payloads are derived from, generating it once.
+func sharedPayloadBlock() []uint64 {
+	payloadBlockOnce.Do(func() {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		payloadBlock = make([]uint64, payloadBlockWords)
+		for i := range payloadBlock {
+			payloadBlock[i] = r.Uint64()
+		}
+	})
+	return payloadBlock
+}
+
+// payloadReader streams a pseudo-random PUT body of a fixed size. It implements
+// io.ReadSeeker, so the SDK can determine the length and rewind the body for retries.
+type payloadReader struct {
+	block   []uint64
+	seed    uint64
+	start   int // Word of the block the body starts at
+	size    int64
+	off     int64
+	pattern dataPattern // Random unless set by dataPattern.payload
+}
+
+// newPayload returns a body of size bytes that differs from every other body created with
+// different values from r.
+func newPayload(size int64, r *rand.Rand) *payloadReader {
+	return &payloadReader{
+		block: sharedPayloadBlock(),
+		seed:  r.Uint64(),
+		start: r.Intn(payloadBlockWords),
+		size:  size,
+	}
+}
+
+// word returns the i-th eight bytes of the body.
+func (p *payloadReader) word(i int64) uint64 {
+	if p.pattern.kind != "" {
+		return p.patternWord(i)
+	}
+	return p.randomWord(i)
+}
+
+// randomWord returns the i-th eight bytes of a random body.
+func (p *payloadReader) randomWord(i int64) uint64 {
+	chunkKey := splitmix64(p.seed ^ uint64(i*8/payloadChunk))
+	return p.block[(int64(p.start)+i)%payloadBlockWords] ^ chunkKey
+}
+
+// splitmix64 scrambles x into a well distributed 64-bit value.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func (p *payloadReader) Read(b []byte) (int, error) {
+	if p.off >= p.size {
+		return 0, io.EOF
+	}
+	if remaining := p.size - p.off; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+	n := 0
+	// Leading bytes up to a word boundary
+	if skip := int(p.off % 8); skip != 0 {
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], p.word(p.off/8))
+		n = copy(b, buf[skip:])
+	}
+	// Whole words
+	for ; len(b)-n >= 8; n += 8 {
+		binary.LittleEndian.PutUint64(b[n:], p.word((p.off+int64(n))/8))
+	}
+	// Trailing bytes
+	if n < len(b) {
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], p.word((p.off+int64(n))/8))
+		n += copy(b[n:], buf[:])
+	}
+	p.off += int64(n)
+	return n, nil
+}
+
+func (p *payloadReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += p.off
+	case io.SeekEnd:
+		offset += p.size
+	default:
+		return 0, errors.New("payload seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("payload seek: negative position %d", offset)
+	}
+	p.off = offset
+	return offset, nil
+}
+
+// bodySize returns the length of a PUT body and rewinds it.
+func bodySize(body io.ReadSeeker) (int64, error) {
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = body.Seek(0, io.SeekStart)
+	return size, err
+}
@@ -0,0 +1,54 @@
+package stresser
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestPayloadReader(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	size := int64(3*payloadChunk + 13)
+	body := newPayload(size, r)
+
+	full, err := io.ReadAll(body)
+	if err != nil || int64(len(full)) != size {
+		t.Fatalf("Expected %d bytes, got %d (%v)", size, len(full), err)
+	}
+
+	// Reading again after a rewind, in odd-sized pieces, yields the same bytes
+	if n, err := bodySize(body); err != nil || n != size {
+		t.Fatalf("bodySize = %d, %v; expected %d", n, err, size)
+	}
+	var pieces bytes.Buffer
+	buf := make([]byte, 7)
+	for {
+		n, err := body.Read(buf)
+		pieces.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+	}
+	if !bytes.Equal(full, pieces.Bytes()) {
+		t.Error("Payload differs between reads")
+	}
+
+	// Seeking into the middle of a word
+	if _, err := body.Seek(payloadChunk+5, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	tail, _ := io.ReadAll(body)
+	if !bytes.Equal(tail, full[payloadChunk+5:]) {
+		t.Error("Payload differs after seeking")
+	}
+
+	// Chunks within a body and bodies from different seeds differ
+	if bytes.Equal(full[:payloadChunk], full[payloadChunk:2*payloadChunk]) {
+		t.Error("Consecutive chunks are identical")
+	}
+	other, _ := io.ReadAll(newPayload(size, r))
+	if bytes.Equal(full[:64], other[:64]) {
+		t.Error("Two payloads start with the same bytes")
+	}
+}
@@ -0,0 +1,137 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// dialPhases collects the DNS, connect and TLS timings of the dial made for a request.
+// httptrace calls the dial hooks from the dialing goroutine, and with happy eyeballs from
+// several at once, so access is locked.
+type dialPhases struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	dns          time.Duration
+	connect      time.Duration
+	tls          time.Duration
+}
+
+// begin records the start of a phase; a phase that was already started keeps its start.
+func (p *dialPhases) begin(start *time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if start.IsZero() {
+		*start = time.Now()
+	}
+}
+
+// end records the duration of a phase started at start.
+func (p *dialPhases) end(d *time.Duration, start time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !start.IsZero() {
+		*d = time.Since(start)
+	}
+}
+
+// endConnect records the connect time of the first address that accepted the connection.
+func (p *dialPhases) endConnect(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil && p.connect == 0 && !p.connectStart.IsZero() {
+		p.connect = time.Since(p.connectStart)
+	}
+}
+
+// durations returns the DNS, connect and TLS handshake times recorded so far.
+func (p *dialPhases) durations() (dns, connect, tls time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dns, p.connect, p.tls
+}
+
+// phaseStats aggregates request phase timings for the summary.
+type phaseStats struct {
+	dns       *Histogram // New connections that resolved the endpoint name
+	connect   *Histogram // New connections
+	tls       *Histogram // New TLS connections
+	firstByte *Histogram // Successful GETs, from sending the request to the first response byte
+}
+
+// addPhaseResult records the phase timings of r. Called from AddResult.
+func (s *Stats) addPhaseResult(r Result) {
+	if s.phases == nil {
+		s.phases = &phaseStats{dns: NewHistogram(), connect: NewHistogram(), tls: NewHistogram(), firstByte: NewHistogram()}
+	}
+	if r.DNS > 0 {
+		s.phases.dns.Record(r.DNS)
+	}
+	if r.Connect > 0 {
+		s.phases.connect.Record(r.Connect)
+	}
+	if r.TLSHandshake > 0 {
+		s.phases.tls.Record(r.TLSHandshake)
+	}
+	if r.Operation == "GET" && r.Error == "" && r.FirstByte > 0 {
+		s.phases.firstByte.Record(r.FirstByte)
+	}
+}
+
+// PhaseLatency is the latency of one request phase.
+type PhaseLatency struct {
+	Phase   string         `json:"phase" yaml:"phase"`
+	Count   int64          `json:"count" yaml:"count"`
+	Latency LatencySummary `json:"latencyMs" yaml:"latencyMs"`
+}
+
+// Phases returns the latency of each request phase that was observed: DNS lookup, TCP
+// connect and TLS handshake of new connections, and the true time to first response byte
+// of successful GETs.
+func (s *Stats) Phases() []PhaseLatency {
+	if s.phases == nil {
+		return nil
+	}
+	var list []PhaseLatency
+	for _, p := range []struct {
+		name string
+		h    *Histogram
+	}{
+		{"DNS", s.phases.dns},
+		{"Connect", s.phases.connect},
+		{"TLS", s.phases.tls},
+		{"GET first byte", s.phases.firstByte},
+	} {
+		if p.h.Count() == 0 {
+			continue
+		}
+		list = append(list, PhaseLatency{Phase: p.name, Count: p.h.Count(), Latency: LatencySummary{
+			Min:  ms(p.h.Min()),
+			Avg:  ms(p.h.Mean()),
+			P50:  ms(p.h.Percentile(50)),
+			P90:  ms(p.h.Percentile(90)),
+			P99:  ms(p.h.Percentile(99)),
+			Max:  ms(p.h.Max()),
+			P999: ms(p.h.Percentile(99.9)),
+		}})
+	}
+	return list
+}
+
+// printPhaseSummary prints the request phase latencies as part of PrintSummary.
+func (s *Stats) printPhaseSummary(w io.Writer) {
+	phases := s.Phases()
+	if len(phases) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nRequest Phases:\n")
+	fmt.Fprintf(w, "  Latency (ms):  |  Count  |   Min  |   Avg  |   P50  |   P90  |   P99  |   Max  \n")
+	fmt.Fprintf(w, "  ---------------|---------|--------|--------|--------|--------|--------|--------\n")
+	for _, p := range phases {
+		l := p.Latency
+		fmt.Fprintf(w, "  %-14s |%8d |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n", p.Phase, p.Count, l.Min, l.Avg, l.P50, l.P90, l.P99, l.Max)
+	}
+}
@@ -0,0 +1,73 @@
+package stresser
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceConnectionPhases(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	client := server.Client()
+
+	send := func() Result {
+		var r Result
+		start := time.Now()
+		req, _ := http.NewRequestWithContext(traceConnection(t.Context(), &r, start), http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return r
+	}
+
+	first := send()
+	if first.ConnReused || first.Connect <= 0 || first.TLSHandshake <= 0 {
+		t.Errorf("new connection phases not recorded: %+v", first)
+	}
+	if first.FirstByte < 5*time.Millisecond || first.FirstByte < first.ConnWait {
+		t.Errorf("first byte %v should include the handler delay and the connection wait %v", first.FirstByte, first.ConnWait)
+	}
+
+	second := send()
+	if !second.ConnReused || second.DNS != 0 || second.Connect != 0 || second.TLSHandshake != 0 {
+		t.Errorf("reused connection should have no dial phases: %+v", second)
+	}
+	if second.FirstByte < 5*time.Millisecond {
+		t.Errorf("first byte %v on reused connection not recorded", second.FirstByte)
+	}
+}
+
+func TestPhaseStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "a", TTFB: 5 * time.Millisecond, TTLB: 6 * time.Millisecond,
+		DNS: time.Millisecond, Connect: 2 * time.Millisecond, TLSHandshake: 4 * time.Millisecond, FirstByte: 3 * time.Millisecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "b", TTFB: 2 * time.Millisecond, TTLB: 3 * time.Millisecond,
+		ConnReused: true, FirstByte: time.Millisecond})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "c", TTLB: 3 * time.Millisecond, FirstByte: 3 * time.Millisecond})
+	stats.Calculate(now, now.Add(time.Second))
+
+	phases := stats.Phases()
+	counts := make(map[string]int64)
+	for _, p := range phases {
+		counts[p.Phase] = p.Count
+	}
+	if counts["DNS"] != 1 || counts["Connect"] != 1 || counts["TLS"] != 1 || counts["GET first byte"] != 2 {
+		t.Errorf("unexpected phase counts: %v", counts)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Request Phases:") {
+		t.Errorf("summary missing request phases section:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,215 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Phase is one step of a test plan. Besides its own fields, a phase may set any field of the
+// YAML configuration, e.g. operationType or fileCount, which then apply to this phase only.
+type Phase struct {
+	Name        string // Names the phase in logs, reports and its output files
+	Duration    string // Duration of the phase (default: -d)
+	Concurrency int    // Workers of the phase (default: -c)
+	Manifest    string // Manifest the phase reads or writes (default: the manifest argument)
+
+	settings *yaml.Node // Configuration fields set by the phase
+	Config   *Config    // Configuration of the phase, set by Config.Validate
+}
+
+// planFile is the format of the file -plan names.
+type planFile struct {
+	Phases []Phase `yaml:"phases"`
+}
+
+// UnmarshalYAML separates the phase's own fields from the configuration fields it sets.
+func (p *Phase) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: a phase must be a mapping", node.Line)
+	}
+	p.settings = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch key.Value {
+		case "name":
+			err = value.Decode(&p.Name)
+		case "duration":
+			err = value.Decode(&p.Duration)
+		case "concurrency":
+			err = value.Decode(&p.Concurrency)
+		case "manifest":
+			err = value.Decode(&p.Manifest)
+		default:
+			p.settings.Content = append(p.settings.Content, key, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadPlan reads the phases of a test plan file.
+func LoadPlan(path string) ([]Phase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test plan %s: %w", path, err)
+	}
+	var f planFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse test plan %s: %w", path, err)
+	}
+	if len(f.Phases) == 0 {
+		return nil, fmt.Errorf("test plan %s has no phases", path)
+	}
+	return f.Phases, nil
+}
+
+// validatePlan loads the phases of -plan and validates the configuration of every phase.
+// A plan replaces the validation of the base configuration, which only provides the
+// defaults of its phases. Called first thing from Validate.
+func (c *Config) validatePlan() error {
+	if c.Plan != "" {
+		phases, err := LoadPlan(c.Plan)
+		if err != nil {
+			return fmt.Errorf("%w (-plan)", err)
+		}
+		c.Phases = phases
+	}
+	if c.SizeSweep != "" || c.ConcurrencySweep != "" {
+		return fmt.Errorf("test plans (-plan) cannot be combined with sweeps")
+	}
+	seen := make(map[string]bool)
+	for i := range c.Phases {
+		p := &c.Phases[i]
+		if !groupName.MatchString(p.Name) {
+			return fmt.Errorf("phase %d of the test plan (-plan) needs a name of letters, digits, '.', '-' and '_', got %q", i+1, p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate phase %s in the test plan (-plan)", p.Name)
+		}
+		seen[p.Name] = true
+		cfg, err := c.phaseConfig(*p)
+		if err != nil {
+			return fmt.Errorf("phase %s of the test plan (-plan): %w", p.Name, err)
+		}
+		p.Config = cfg
+	}
+	return nil
+}
+
+// phaseConfig returns the validated configuration of a phase: the base configuration with
+// the phase's fields applied. Output files get the phase name appended, e.g. results-load.csv,
+// so phases do not overwrite each other's results unless the phase names its own files.
+func (c *Config) phaseConfig(p Phase) (*Config, error) {
+	cfg := *c
+	cfg.Plan, cfg.Phases = "", nil
+	// Decoding YAML into a map adds to it, which would leak into the other phases
+	cfg.Metadata, cfg.Tags, cfg.SLOs = maps.Clone(c.Metadata), maps.Clone(c.Tags), maps.Clone(c.SLOs)
+	for _, path := range []*string{&cfg.OutputFile, &cfg.SummaryFile, &cfg.HDRLog, &cfg.TimeSeries, &cfg.HTMLReport, &cfg.SlowLog, &cfg.TraceFile} {
+		*path = phasePath(*path, p.Name)
+	}
+
+	if p.settings != nil {
+		data, err := yaml.Marshal(p.settings)
+		if err != nil {
+			return nil, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true) // A misspelled field would silently run the phase with the base value
+		if err := dec.Decode(&cfg); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	if cfg.Plan != "" || len(cfg.Phases) > 0 {
+		return nil, fmt.Errorf("a phase cannot contain another test plan")
+	}
+	if p.Duration != "" {
+		cfg.Duration = p.Duration
+	}
+	if p.Concurrency != 0 {
+		cfg.Concurrency = p.Concurrency
+	}
+	if p.Manifest != "" {
+		cfg.ManifestPath = p.Manifest
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// phasePath inserts the phase name before the extension of an output path.
+// Empty paths and standard output are returned unchanged.
+func phasePath(path, phase string) string {
+	if path == "" || path == StdoutPath {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + phase + ext
+}
+
+// PhaseResult holds the outcome of one phase of a test plan.
+type PhaseResult struct {
+	Name  string // Name of the phase
+	Stats *Stats // Aggregate statistics of the phase
+}
+
+// RunPlan runs the phases of cfg one after another, each with run, which also reports the
+// phase, and returns the statistics of every phase that ran. A phase that misses its SLOs
+// does not stop the plan, but the plan then fails with ErrSLOViolation once it completes.
+func RunPlan(ctx context.Context, cfg *Config, run func(context.Context, *Config) (*Stats, error)) ([]PhaseResult, error) {
+	var results []PhaseResult
+	var missed []string
+	for i, p := range cfg.Phases {
+		if ctx.Err() != nil {
+			slog.Info("Test plan interrupted", "completedPhases", i, "reason", ctx.Err())
+			break
+		}
+
+		slog.Info("Starting plan phase", "phase", p.Name, "step", i+1, "of", len(cfg.Phases),
+			"operation", p.Config.OperationType, "duration", p.Config.Duration, "concurrency", p.Config.Concurrency)
+		stats, err := run(ctx, p.Config)
+		if stats != nil {
+			results = append(results, PhaseResult{Name: p.Name, Stats: stats})
+		}
+		if errors.Is(err, ErrSLOViolation) {
+			missed = append(missed, p.Name)
+		} else if err != nil {
+			return results, fmt.Errorf("phase %s failed: %w", p.Name, err)
+		}
+	}
+	if len(missed) > 0 {
+		return results, fmt.Errorf("%w in phases %s", ErrSLOViolation, strings.Join(missed, ", "))
+	}
+	return results, nil
+}
+
+// PrintPlanTable prints one row per phase of a test plan to the given writer.
+func PrintPlanTable(w io.Writer, phases []PhaseResult) {
+	fmt.Fprintf(w, "\n--- Test Plan Summary (%d phases) ---\n", len(phases))
+	for _, col := range append([]string{"Phase"}, statsHeader()...) {
+		fmt.Fprintf(w, "%12s ", col)
+	}
+	fmt.Fprintln(w)
+	for _, p := range phases {
+		for _, col := range append([]string{p.Name}, statsRow(p.Stats)...) {
+			fmt.Fprintf(w, "%12s ", col)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "----------------------------------------\n")
+}
@@ -0,0 +1,160 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePlan(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func planConfig(plan string) *Config {
+	return &Config{Endpoint: "https://test-endpoint.com", Region: "us-east-1", Bucket: "bucket", Duration: "1m", Concurrency: 4,
+		OutputFile: "results.csv", OperationType: "read", PutObjectSizeKB: 1, FileCount: 1000, ListPageSize: 1000,
+		Tags: map[string]string{"team": "storage"}, Plan: plan}
+}
+
+func TestValidatePlan(t *testing.T) {
+	c := planConfig(writePlan(t, `
+phases:
+  - name: load
+    operationType: write
+    fileCount: 100000
+    concurrency: 64
+    manifest: objects.txt
+    tags: {phase: load}
+  - {name: read, duration: 10m, manifest: objects.txt, htmlReport: read.html}
+`))
+	c.HTMLReport = "report.html"
+	c.RunID = "run1"
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Phases) != 2 {
+		t.Fatalf("Expected 2 phases, got %d", len(c.Phases))
+	}
+
+	load, read := c.Phases[0].Config, c.Phases[1].Config
+	if load.OperationType != "write" || load.FileCount != 100000 || load.Concurrency != 64 || load.Duration != "1m" || load.ManifestPath != "objects.txt" {
+		t.Errorf("Unexpected load phase %+v", load)
+	}
+	if read.OperationType != "read" || read.FileCount != 1000 || read.Concurrency != 4 || read.Duration != "10m" {
+		t.Errorf("Unexpected read phase %+v", read)
+	}
+	if load.OutputFile != "results-load.csv" || load.HTMLReport != "report-load.html" || read.HTMLReport != "read.html" {
+		t.Errorf("Expected the phase name in the output paths, got %s, %s and %s", load.OutputFile, load.HTMLReport, read.HTMLReport)
+	}
+	// A phase's settings do not leak into the base configuration or the other phases
+	if len(load.Tags) != 2 || len(read.Tags) != 1 || len(c.Tags) != 1 {
+		t.Errorf("Unexpected tags %v, %v and %v", load.Tags, read.Tags, c.Tags)
+	}
+	if load.Phases != nil || read.Plan != "" {
+		t.Error("Expected the phase configurations without a plan")
+	}
+	if load.RunID != c.RunID || read.RunID != c.RunID {
+		t.Errorf("Expected the phases to share run id %q, got %q and %q", c.RunID, load.RunID, read.RunID)
+	}
+
+	for _, plan := range []string{
+		"phases:\n  - {name: a, operationType: read, manifest: m.txt}\n  - {name: a, operationType: read, manifest: m.txt}\n",
+		"phases:\n  - {operationType: read, manifest: m.txt}\n",
+		"phases:\n  - {name: a, operationType: read, manifets: m.txt}\n",
+		"phases:\n  - {name: a, operationType: read}\n",
+		"phases:\n  - {name: a, plan: other.yaml}\n",
+		"phases:\n  - {name: a, concurrency: lots}\n",
+		"phases: []\n",
+	} {
+		if err := planConfig(writePlan(t, plan)).Validate(); err == nil {
+			t.Errorf("Expected an error for %q", plan)
+		}
+	}
+	c = planConfig(writePlan(t, "phases:\n  - {name: a, operationType: list}\n"))
+	c.ConcurrencySweep = "1,2"
+	if err := c.Validate(); err == nil {
+		t.Error("Expected an error for a plan with a sweep")
+	}
+}
+
+func TestPhasePath(t *testing.T) {
+	for path, want := range map[string]string{
+		"results.csv":     "results-load.csv",
+		"out/run.json":    "out/run-load.json",
+		"results":         "results-load",
+		"":                "",
+		StdoutPath:        StdoutPath,
+		"dir.d/results.x": "dir.d/results-load.x",
+	} {
+		if got := phasePath(path, "load"); got != want {
+			t.Errorf("phasePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRunPlan(t *testing.T) {
+	cfg := planConfig(writePlan(t, `
+phases:
+  - {name: load, operationType: write, manifest: objects.txt}
+  - {name: read, manifest: objects.txt, slos: {errorRatePct: 0}}
+  - {name: list, operationType: list}
+`))
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var ran []string
+	run := func(ctx context.Context, c *Config) (*Stats, error) {
+		ran = append(ran, c.OperationType)
+		stats := NewStats()
+		if c.OperationType == "read" {
+			return stats, fmt.Errorf("%w: errorRatePct", ErrSLOViolation)
+		}
+		return stats, nil
+	}
+	phases, err := RunPlan(context.Background(), cfg, run)
+	if !errors.Is(err, ErrSLOViolation) || !strings.Contains(err.Error(), "phases read") {
+		t.Errorf("Expected the plan to miss the SLOs of phase read, got %v", err)
+	}
+	if strings.Join(ran, ",") != "write,read,list" || len(phases) != 3 || phases[2].Name != "list" {
+		t.Errorf("Expected all phases to run, ran %v with results %+v", ran, phases)
+	}
+
+	// Other errors end the plan
+	ran = nil
+	phases, err = RunPlan(context.Background(), cfg, func(ctx context.Context, c *Config) (*Stats, error) {
+		ran = append(ran, c.OperationType)
+		return nil, errors.New("boom")
+	})
+	if err == nil || len(ran) != 1 || len(phases) != 0 {
+		t.Errorf("Expected the plan to stop after the first phase, ran %v (%v)", ran, err)
+	}
+
+	// An interrupt ends the plan after the current phase
+	ctx, cancel := context.WithCancel(context.Background())
+	ran = nil
+	phases, err = RunPlan(ctx, cfg, func(ctx context.Context, c *Config) (*Stats, error) {
+		ran = append(ran, c.OperationType)
+		cancel()
+		return NewStats(), nil
+	})
+	if err != nil || len(ran) != 1 || len(phases) != 1 {
+		t.Errorf("Expected the plan to stop after the interrupted phase, ran %v (%v)", ran, err)
+	}
+
+	var buf bytes.Buffer
+	PrintPlanTable(&buf, phases)
+	if !strings.Contains(buf.String(), "Test Plan Summary (1 phases)") || !strings.Contains(buf.String(), "load") {
+		t.Errorf("Unexpected plan table:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,60 @@
+package stresser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// validatePprof checks the -pprof-addr address. Like the control API it must be on a
+// loopback interface: heap profiles can hold the credentials of the run.
+func (c *Config) validatePprof() error {
+	if c.PprofAddr == "" {
+		return nil
+	}
+	if c.Agents != "" {
+		return fmt.Errorf("the pprof server (-pprof-addr) is not supported with -agents")
+	}
+	loopback, err := isLoopbackAddr(c.PprofAddr)
+	if err != nil {
+		return fmt.Errorf("invalid pprof address (-pprof-addr) %q: %w", c.PprofAddr, err)
+	}
+	if !loopback {
+		return fmt.Errorf("pprof address (-pprof-addr) %q must be on a loopback interface, e.g. 127.0.0.1:6060; use an SSH tunnel to profile remotely", c.PprofAddr)
+	}
+	return nil
+}
+
+// pprofHandler serves the net/http/pprof profiles under /debug/pprof/. It has a mux of its
+// own rather than relying on what importing net/http/pprof registers on the default mux.
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// startPprofServer listens on addr and serves the profiles of the process until the
+// returned server is closed.
+func startPprofServer(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for pprof on %s: %w", addr, err)
+	}
+	// No write timeout: CPU profiles and traces stream for as long as they were asked for
+	server := &http.Server{Handler: pprofHandler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("pprof server failed", "error", err)
+		}
+	}()
+	slog.Info("Serving pprof profiles", "url", "http://"+listener.Addr().String()+"/debug/pprof/")
+	return server, nil
+}
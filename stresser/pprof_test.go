@@ -0,0 +1,49 @@
+package stresser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidatePprof(t *testing.T) {
+	for addr, valid := range map[string]bool{
+		"":               true,
+		"127.0.0.1:6060": true,
+		"localhost:6060": true,
+		"[::1]:6060":     true,
+		":6060":          false,
+		"0.0.0.0:6060":   false,
+		"127.0.0.1":      false,
+	} {
+		cfg := Config{PprofAddr: addr}
+		if err := cfg.validatePprof(); (err == nil) != valid {
+			t.Errorf("%q: valid %v, got error %v", addr, valid, err)
+		}
+	}
+	cfg := Config{PprofAddr: "127.0.0.1:6060", Agents: "host1"}
+	if err := cfg.validatePprof(); err == nil {
+		t.Error("Expected an error with -agents")
+	}
+}
+
+func TestPprofHandler(t *testing.T) {
+	for path, want := range map[string]string{
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile",
+		"/debug/pprof/cmdline":           "",
+	} {
+		rec := httptest.NewRecorder()
+		pprofHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("%s: expected %q, got status %d: %.200s", path, want, rec.Code, rec.Body.String())
+		}
+	}
+
+	server, err := startPprofServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("startPprofServer failed: %v", err)
+	}
+	server.Close()
+}
@@ -0,0 +1,116 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxReportedPrefixes limits how many prefixes PrintSummary lists.
+const maxReportedPrefixes = 10
+
+// PrefixStats aggregates results for all keys sharing a prefix.
+type PrefixStats struct {
+	Prefix   string
+	Requests int64
+	Errors   int64
+	Bytes    int64 // Bytes transferred in either direction by successful requests
+	AvgTTLB  time.Duration
+	P50TTLB  time.Duration
+	P99TTLB  time.Duration
+
+	ttlbs *Histogram // Latencies of successful requests (GET body read / PUT total)
+}
+
+// keyPrefix returns the first depth path segments of key including the trailing slash.
+// Keys with fewer segments are grouped under their parent directory; keys without
+// any directory map to the empty prefix.
+func keyPrefix(key string, depth int) string {
+	end := 0
+	for i := 0; i < depth; i++ {
+		next := strings.IndexByte(key[end:], '/')
+		if next < 0 {
+			break
+		}
+		end += next + 1
+	}
+	return key[:end]
+}
+
+// addPrefixResult records r against its key prefix. Called from AddResult when prefix stats are enabled.
+func (s *Stats) addPrefixResult(r Result) {
+	if s.prefixes == nil {
+		s.prefixes = make(map[string]*PrefixStats)
+	}
+	prefix := keyPrefix(r.ObjectKey, s.PrefixDepth)
+	ps, ok := s.prefixes[prefix]
+	if !ok {
+		ps = &PrefixStats{Prefix: prefix, ttlbs: newCoarseHistogram()} // A run may touch many prefixes
+		s.prefixes[prefix] = ps
+	}
+
+	ps.Requests++
+	if r.Error != "" {
+		ps.Errors++
+		return
+	}
+	ps.Bytes += r.BytesDownloaded + r.BytesUploaded
+	ps.ttlbs.Record(r.TTLB)
+}
+
+// calculatePrefixStats computes per-prefix latency figures. Called from Calculate.
+func (s *Stats) calculatePrefixStats() {
+	for _, ps := range s.prefixes {
+		if ps.ttlbs.Count() == 0 {
+			continue
+		}
+		ps.AvgTTLB = ps.ttlbs.Mean()
+		ps.P50TTLB = ps.ttlbs.Percentile(50)
+		ps.P99TTLB = ps.ttlbs.Percentile(99)
+	}
+}
+
+// WorstPrefixes returns up to n prefixes ordered by descending P99 latency, then error count.
+func (s *Stats) WorstPrefixes(n int) []*PrefixStats {
+	list := make([]*PrefixStats, 0, len(s.prefixes))
+	for _, ps := range s.prefixes {
+		list = append(list, ps)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].P99TTLB != list[j].P99TTLB {
+			return list[i].P99TTLB > list[j].P99TTLB
+		}
+		if list[i].Errors != list[j].Errors {
+			return list[i].Errors > list[j].Errors
+		}
+		return list[i].Prefix < list[j].Prefix
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// printPrefixSummary prints the worst prefixes as part of PrintSummary.
+func (s *Stats) printPrefixSummary(w io.Writer) {
+	if s.PrefixDepth <= 0 || len(s.prefixes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nWorst Prefixes (depth %d, %d total, by P99 latency):\n", s.PrefixDepth, len(s.prefixes))
+	fmt.Fprintf(w, "  Requests | Errors |  MiB/s  | Avg (ms) | P50 (ms) | P99 (ms) | Prefix\n")
+	fmt.Fprintf(w, "  ---------|--------|---------|----------|----------|----------|--------\n")
+	for _, ps := range s.WorstPrefixes(maxReportedPrefixes) {
+		throughput := float64(0)
+		if s.actualDuration.Seconds() > 0 {
+			throughput = (float64(ps.Bytes) / (1024 * 1024)) / s.actualDuration.Seconds()
+		}
+		prefix := ps.Prefix
+		if prefix == "" {
+			prefix = "(root)"
+		}
+		fmt.Fprintf(w, "  %8d | %6d | %7.2f | %8.2f | %8.2f | %8.2f | %s\n",
+			ps.Requests, ps.Errors, throughput, ms(ps.AvgTTLB), ms(ps.P50TTLB), ms(ps.P99TTLB), prefix)
+	}
+}
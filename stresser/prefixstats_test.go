@@ -0,0 +1,59 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key      string
+		depth    int
+		expected string
+	}{
+		{key: "a/b/c/d.dat", depth: 1, expected: "a/"},
+		{key: "a/b/c/d.dat", depth: 2, expected: "a/b/"},
+		{key: "a/d.dat", depth: 2, expected: "a/"},
+		{key: "d.dat", depth: 2, expected: ""},
+	}
+	for _, tt := range tests {
+		if got := keyPrefix(tt.key, tt.depth); got != tt.expected {
+			t.Errorf("keyPrefix(%q, %d) = %q, expected %q", tt.key, tt.depth, got, tt.expected)
+		}
+	}
+}
+
+func TestPrefixStats(t *testing.T) {
+	stats := NewStats()
+	stats.PrefixDepth = 1
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "fast/obj.dat", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 1024})
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "slow/obj.dat", TTFB: time.Millisecond, TTLB: 200 * time.Millisecond, BytesDownloaded: 1024})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "slow/missing.dat", TTFB: -1, TTLB: -1, Error: "not found"})
+	stats.Calculate(now, now.Add(time.Second))
+
+	worst := stats.WorstPrefixes(1)
+	if len(worst) != 1 {
+		t.Fatalf("Expected 1 prefix, got %d", len(worst))
+	}
+	if worst[0].Prefix != "slow/" {
+		t.Errorf("Expected worst prefix 'slow/', got %q", worst[0].Prefix)
+	}
+	if worst[0].Requests != 11 || worst[0].Errors != 1 {
+		t.Errorf("Expected 11 requests and 1 error, got %d and %d", worst[0].Requests, worst[0].Errors)
+	}
+	if worst[0].P99TTLB != 200*time.Millisecond {
+		t.Errorf("Expected P99 of 200ms, got %v", worst[0].P99TTLB)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Worst Prefixes (depth 1, 2 total") {
+		t.Errorf("Summary is missing the prefix section:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,125 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// preflightTimeout bounds all pre-flight requests together.
+const preflightTimeout = 30 * time.Second
+
+// preflightClient is the part of the S3 API the pre-flight checks use. The workers never
+// need these operations, so they are not part of S3ClientAPI.
+type preflightClient interface {
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// runPreflightBuckets runs the pre-flight checks against every bucket of a multi-bucket run,
+// or against Bucket.
+func runPreflightBuckets(ctx context.Context, client preflightClient, cfg *Config) error {
+	if len(cfg.Buckets) == 0 {
+		return runPreflight(ctx, client, cfg)
+	}
+	for _, bucket := range cfg.Buckets {
+		bucketCfg := *cfg
+		bucketCfg.Bucket = bucket
+		if err := runPreflight(ctx, client, &bucketCfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPreflight checks that the endpoint is reachable, the credentials are accepted and the
+// bucket exists before any worker starts, creating the bucket with CreateBucket. Workloads
+// that write also PUT and DELETE a tiny canary object to check write permission. A
+// misconfiguration then fails the run with one explanation instead of an error per request.
+func runPreflight(ctx context.Context, client preflightClient, cfg *Config) error {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	_, err := client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(cfg.Bucket)})
+	switch {
+	case err == nil:
+		slog.Info("Pre-flight: bucket is reachable", "bucket", cfg.Bucket)
+	case responseStatus(err) == http.StatusNotFound && cfg.CreateBucket:
+		input := &s3.CreateBucketInput{Bucket: aws.String(cfg.Bucket)}
+		if cfg.Region != "" && cfg.Region != "us-east-1" {
+			// us-east-1 is the default location and must not be named explicitly
+			input.CreateBucketConfiguration = &types.CreateBucketConfiguration{LocationConstraint: types.BucketLocationConstraint(cfg.Region)}
+		}
+		if _, err := client.CreateBucket(ctx, input); err != nil {
+			return fmt.Errorf("pre-flight: failed to create bucket %q: %w", cfg.Bucket, err)
+		}
+		slog.Info("Pre-flight: created bucket", "bucket", cfg.Bucket, "region", cfg.Region)
+	default:
+		return preflightError(err, cfg)
+	}
+
+	if cfg.OperationType != "write" && cfg.OperationType != "mixed" && cfg.OperationType != "copy" && cfg.OperationType != "versioned" && cfg.OperationType != "consistency" && cfg.OperationType != "contention" && cfg.OperationType != "append" {
+		return nil
+	}
+	key := preflightKey(cfg)
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(cfg.Bucket), Key: aws.String(key), Body: bytes.NewReader([]byte("ostresser"))})
+	if err != nil {
+		return fmt.Errorf("pre-flight: canary PUT of %q failed, check write permission on bucket %q: %w", key, cfg.Bucket, err)
+	}
+	if _, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(cfg.Bucket), Key: aws.String(key)}); err != nil {
+		return fmt.Errorf("pre-flight: failed to delete canary object %q: %w", key, err)
+	}
+	slog.Info("Pre-flight: canary PUT and DELETE succeeded", "key", key)
+	return nil
+}
+
+// preflightError explains a failed HeadBucket by its status code.
+func preflightError(err error, cfg *Config) error {
+	switch responseStatus(err) {
+	case 0:
+		return fmt.Errorf("pre-flight: cannot reach endpoint %q: %w", cfg.Endpoint, err)
+	case http.StatusNotFound:
+		return fmt.Errorf("pre-flight: bucket %q does not exist (use -create-bucket to create it): %w", cfg.Bucket, err)
+	case http.StatusForbidden:
+		return fmt.Errorf("pre-flight: access to bucket %q denied, check the credentials: %w", cfg.Bucket, err)
+	case http.StatusMovedPermanently:
+		return fmt.Errorf("pre-flight: bucket %q is not in region %q: %w", cfg.Bucket, cfg.Region, err)
+	default:
+		return fmt.Errorf("pre-flight: HeadBucket on %q failed: %w", cfg.Bucket, err)
+	}
+}
+
+// responseStatus returns the HTTP status code of an error response, or 0 if no response was received.
+func responseStatus(err error) int {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+	return 0
+}
+
+// preflightKey returns the key of the canary object: in the fixed leading directory of the
+// key template, so prefix-scoped write permissions are checked where the workers write.
+func preflightKey(cfg *Config) string {
+	prefix := cfg.KeyTemplate
+	if prefix == "" {
+		prefix = workerKeyTemplate(cfg.OperationType)
+	}
+	if i := strings.IndexByte(prefix, '{'); i >= 0 {
+		prefix = prefix[:i]
+	}
+	prefix = prefix[:strings.LastIndexByte(prefix, '/')+1]
+	return prefix + "ostresser-preflight-" + cfg.RunID
+}
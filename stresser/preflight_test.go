@@ -0,0 +1,116 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// preflightS3Client records the pre-flight calls and fails them with the configured errors.
+type preflightS3Client struct {
+	headErr, putErr error
+	calls           []string
+	created         *s3.CreateBucketInput
+}
+
+func (c *preflightS3Client) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	c.calls = append(c.calls, "HeadBucket")
+	return &s3.HeadBucketOutput{}, c.headErr
+}
+
+func (c *preflightS3Client) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	c.calls = append(c.calls, "CreateBucket")
+	c.created = params
+	return &s3.CreateBucketOutput{}, nil
+}
+
+func (c *preflightS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.calls = append(c.calls, "PutObject "+aws.ToString(params.Key))
+	return &s3.PutObjectOutput{}, c.putErr
+}
+
+func (c *preflightS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	c.calls = append(c.calls, "DeleteObject "+aws.ToString(params.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestRunPreflight(t *testing.T) {
+	ctx := context.Background()
+
+	client := &preflightS3Client{}
+	cfg := &Config{Bucket: "b", OperationType: "read", RunID: "r1"}
+	if err := runPreflight(ctx, client, cfg); err != nil {
+		t.Fatalf("read run: %v", err)
+	}
+	if strings.Join(client.calls, ",") != "HeadBucket" {
+		t.Errorf("read run made calls %v, want only HeadBucket", client.calls)
+	}
+
+	client = &preflightS3Client{}
+	cfg = &Config{Bucket: "b", OperationType: "write", RunID: "r1", KeyTemplate: "bench/{date}/{seq}"}
+	if err := runPreflight(ctx, client, cfg); err != nil {
+		t.Fatalf("write run: %v", err)
+	}
+	want := "HeadBucket,PutObject bench/ostresser-preflight-r1,DeleteObject bench/ostresser-preflight-r1"
+	if got := strings.Join(client.calls, ","); got != want {
+		t.Errorf("write run made calls %s, want %s", got, want)
+	}
+
+	client = &preflightS3Client{putErr: statusError(403)}
+	if err := runPreflight(ctx, client, cfg); err == nil || !strings.Contains(err.Error(), "write permission") {
+		t.Errorf("failed canary PUT: got %v", err)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{statusError(404), "does not exist"},
+		{statusError(403), "denied"},
+		{statusError(301), "region"},
+		{errors.New("dial tcp: connection refused"), "cannot reach"},
+	} {
+		client = &preflightS3Client{headErr: tc.err}
+		err := runPreflight(ctx, client, &Config{Bucket: "b", OperationType: "read"})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("HeadBucket error %v: got %v, want it to mention %q", tc.err, err, tc.want)
+		}
+	}
+}
+
+func TestRunPreflightCreateBucket(t *testing.T) {
+	client := &preflightS3Client{headErr: statusError(404)}
+	cfg := &Config{Bucket: "b", Region: "eu-west-1", OperationType: "read", CreateBucket: true}
+	if err := runPreflight(context.Background(), client, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if client.created == nil || string(client.created.CreateBucketConfiguration.LocationConstraint) != "eu-west-1" {
+		t.Errorf("bucket not created in eu-west-1: %+v", client.created)
+	}
+
+	client = &preflightS3Client{headErr: statusError(404)}
+	cfg.Region = "us-east-1"
+	if err := runPreflight(context.Background(), client, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if client.created == nil || client.created.CreateBucketConfiguration != nil {
+		t.Errorf("us-east-1 bucket should be created without a location constraint: %+v", client.created)
+	}
+}
+
+func TestPreflightKey(t *testing.T) {
+	for template, want := range map[string]string{
+		"":                    "stresser/ostresser-preflight-r1",
+		"bench/{date}/{seq}":  "bench/ostresser-preflight-r1",
+		"a/b/c-{seq}":         "a/b/ostresser-preflight-r1",
+		"{shard:4}/obj-{seq}": "ostresser-preflight-r1",
+	} {
+		if got := preflightKey(&Config{KeyTemplate: template, RunID: "r1"}); got != want {
+			t.Errorf("preflightKey(%q) = %q, want %q", template, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeObjectServer is a minimal S3 stand-in storing PUT bodies and user metadata in memory.
+func fakeObjectServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	metadata := make(map[string]http.Header)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("X-Amz-Signature") == "" {
+			t.Errorf("Request to %s is not presigned", r.URL.Path)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			if r.ContentLength < 0 {
+				http.Error(w, "missing content length", http.StatusLengthRequired)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+			metadata[r.URL.Path] = r.Header.Clone()
+			w.Header().Set("ETag", `"etag"`)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.Header().Set("X-Amz-Request-Id", "req-404")
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			for name, values := range metadata[r.URL.Path] {
+				if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+					w.Header()[name] = values
+				}
+			}
+			w.Write(body)
+		}
+	}))
+}
+
+func TestPresignedClient(t *testing.T) {
+	server := fakeObjectServer(t)
+	defer server.Close()
+	t.Setenv("AWS_CA_BUNDLE", "") // The custom HTTP client can't take extra root CAs
+
+	cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", RunID: "test"}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	presigned := newPresignedClient(client, cfg.UserAgentString())
+	data := []byte("hello presigned world")
+
+	put := performPutOperation(context.Background(), presigned, cfg.Bucket, "a/key.dat", bytes.NewReader(data), true, putAttributes{})
+	if put.Error != "" {
+		t.Fatalf("Presigned PUT failed: %s", put.Error)
+	}
+	get := performGetOperation(context.Background(), presigned, cfg.Bucket, "a/key.dat", true)
+	if get.Error != "" {
+		t.Fatalf("Presigned GET failed: %s", get.Error)
+	}
+	if get.BytesDownloaded != int64(len(data)) || get.Integrity != IntegrityOK {
+		t.Errorf("Expected %d verified bytes, got %d with integrity %q", len(data), get.BytesDownloaded, get.Integrity)
+	}
+
+	missing := performGetOperation(context.Background(), presigned, cfg.Bucket, "missing", false)
+	if missing.Error == "" || missing.RequestID != "req-404" {
+		t.Errorf("Expected a failed GET with request id req-404, got error %q and id %q", missing.Error, missing.RequestID)
+	}
+}
@@ -0,0 +1,118 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval is how often interim stats are printed during a run.
+const DefaultProgressInterval = "5s"
+
+// progressReporter prints interim throughput, errors and latency percentiles while a run
+// is in progress. On a terminal it redraws a single status line, otherwise it prints one
+// line per interval so the output stays readable in log files.
+type progressReporter struct {
+	w     io.Writer
+	tty   bool
+	start time.Time
+
+	mu          sync.Mutex
+	total       int64
+	errors      int64
+	last        time.Time       // Time of the previous report
+	intRequests int64           // Requests completed since the previous report
+	intErrors   int64           // Errors since the previous report
+	intBytes    int64           // Bytes moved since the previous report
+	intTTLBs    []time.Duration // Latencies of successful requests since the previous report
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// newProgressReporter creates a reporter for a run that started at start. Call run to start printing.
+func newProgressReporter(w io.Writer, start time.Time) *progressReporter {
+	return &progressReporter{
+		w:       w,
+		tty:     isTerminal(w),
+		start:   start,
+		last:    start,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// isTerminal reports whether w is a character device such as an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// add records a completed operation.
+func (p *progressReporter) add(r Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total++
+	p.intRequests++
+	p.intBytes += r.BytesDownloaded + r.BytesUploaded
+	if r.Error != "" {
+		p.errors++
+		p.intErrors++
+		return
+	}
+	p.intTTLBs = append(p.intTTLBs, r.TTLB)
+}
+
+// run prints a report every interval until Stop is called.
+func (p *progressReporter) run(interval time.Duration) {
+	defer close(p.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			if p.tty {
+				fmt.Fprint(p.w, "\r\033[K") // Clear the status line before the summary
+			}
+			return
+		case now := <-ticker.C:
+			p.report(now)
+		}
+	}
+}
+
+// Stop ends reporting and waits for the reporter to finish writing.
+func (p *progressReporter) Stop() {
+	close(p.done)
+	<-p.stopped
+}
+
+// report prints the stats of the interval ending at now and starts a new interval.
+func (p *progressReporter) report(now time.Time) {
+	p.mu.Lock()
+	secs := now.Sub(p.last).Seconds()
+	line := fmt.Sprintf("[%6s] %d requests, %d errors | last %.0fs: %.1f req/s, %.2f MiB/s, %d errors",
+		now.Sub(p.start).Round(time.Second), p.total, p.errors, secs,
+		float64(p.intRequests)/secs, float64(p.intBytes)/(1024*1024)/secs, p.intErrors)
+	if len(p.intTTLBs) > 0 {
+		sortDurations(p.intTTLBs)
+		line += fmt.Sprintf(", p50 %.2f ms, p99 %.2f ms",
+			ms(percentileDuration(p.intTTLBs, 50)), ms(percentileDuration(p.intTTLBs, 99)))
+	}
+	p.last = now
+	p.intRequests, p.intErrors, p.intBytes = 0, 0, 0
+	p.intTTLBs = p.intTTLBs[:0]
+	p.mu.Unlock()
+
+	if p.tty {
+		fmt.Fprintf(p.w, "\r\033[K%s", line)
+	} else {
+		fmt.Fprintln(p.w, line)
+	}
+}
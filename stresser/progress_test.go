@@ -0,0 +1,37 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReport(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+	p := newProgressReporter(&buf, start)
+	if p.tty {
+		t.Fatal("A buffer should not be treated as a terminal")
+	}
+
+	for i := 1; i <= 10; i++ {
+		p.add(Result{Operation: "GET", TTLB: time.Duration(i) * time.Millisecond, BytesDownloaded: 1024 * 1024})
+	}
+	p.add(Result{Operation: "GET", Error: "boom"})
+	p.report(start.Add(2 * time.Second))
+
+	line := buf.String()
+	for _, want := range []string{"[    2s]", "11 requests, 1 errors", "5.5 req/s", "5.00 MiB/s", "p50 6.00 ms", "p99 10.00 ms"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Progress line %q does not contain %q", line, want)
+		}
+	}
+
+	// The next interval starts empty but keeps the running totals
+	buf.Reset()
+	p.report(start.Add(4 * time.Second))
+	if line := buf.String(); !strings.Contains(line, "11 requests, 1 errors") || !strings.Contains(line, "0.0 req/s") || strings.Contains(line, "p50") {
+		t.Errorf("Unexpected progress line for an idle interval: %q", line)
+	}
+}
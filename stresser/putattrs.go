@@ -0,0 +1,183 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3 limits on what a written object may carry.
+const (
+	maxMetadataBytes = 2048 // User metadata keys and values together
+	maxObjectTags    = 10
+	maxTagKeyLength  = 128
+	maxTagValueLen   = 256
+)
+
+// putAttributes are the metadata, headers and tags every PUT sets, so written objects
+// look like production writes.
+type putAttributes struct {
+	metadata     map[string]string // User metadata, sent as x-amz-meta-* headers
+	contentType  string
+	cacheControl string
+	tagging      string // Object tags, URL query encoded as S3 expects them
+}
+
+// putAttributes returns the attributes of the objects written with c.
+func (c *Config) putAttributes() putAttributes {
+	a := putAttributes{metadata: c.Metadata, contentType: c.ContentType, cacheControl: c.CacheControl}
+	if len(c.Tags) > 0 {
+		tags := url.Values{}
+		for k, v := range c.Tags {
+			tags.Set(k, v)
+		}
+		a.tagging = tags.Encode()
+	}
+	return a
+}
+
+// apply sets the attributes on input. Metadata already on input, such as the -verify
+// checksum, is kept.
+func (a putAttributes) apply(input *s3.PutObjectInput) {
+	if len(a.metadata) > 0 {
+		md := maps.Clone(a.metadata)
+		maps.Copy(md, input.Metadata)
+		input.Metadata = md
+	}
+	if a.contentType != "" {
+		input.ContentType = aws.String(a.contentType)
+	}
+	if a.cacheControl != "" {
+		input.CacheControl = aws.String(a.cacheControl)
+	}
+	if a.tagging != "" {
+		input.Tagging = aws.String(a.tagging)
+	}
+}
+
+// ParseKeyValues parses "name=value,..." pairs as given to -metadata and -tags.
+func ParseKeyValues(spec string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid pair %q: expected name=value", item)
+		}
+		pairs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return pairs, nil
+}
+
+// validateMetadata checks user metadata against the S3 limits. Called from Validate.
+func validateMetadata(md map[string]string) error {
+	size := 0
+	for k, v := range md {
+		if k == "" || strings.ContainsAny(k, " :\t\r\n") {
+			return fmt.Errorf("invalid metadata key %q", k)
+		}
+		if strings.EqualFold(k, verifyMetadataKey) {
+			return fmt.Errorf("metadata key %q is reserved for -verify", k)
+		}
+		size += len(k) + len(v)
+	}
+	if size > maxMetadataBytes {
+		return fmt.Errorf("metadata is %d bytes, S3 allows at most %d", size, maxMetadataBytes)
+	}
+	return nil
+}
+
+// validateTags checks object tags against the S3 limits. Called from Validate.
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("%d tags given, S3 allows at most %d per object", len(tags), maxObjectTags)
+	}
+	for k, v := range tags {
+		if k == "" || len(k) > maxTagKeyLength {
+			return fmt.Errorf("tag key %q must be 1 to %d characters", k, maxTagKeyLength)
+		}
+		if len(v) > maxTagValueLen {
+			return fmt.Errorf("value of tag %q must be at most %d characters", k, maxTagValueLen)
+		}
+	}
+	return nil
+}
+
+// taggingClient is the part of the S3 API 'tagging' mode uses on top of S3ClientAPI.
+type taggingClient interface {
+	S3ClientAPI
+	GetObjectTagging(ctx context.Context, params *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error)
+}
+
+// performTaggingOperation reads the tags of key with GetObjectTagging and measures how
+// long it took. The number of tags returned is stored in Result.Keys.
+func performTaggingOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string) Result {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "TAGGING",
+		ObjectKey: key,
+		TTFB:      -1, // Not measured, the tag set is parsed before the SDK returns
+		TTLB:      -1,
+	}
+	client, ok := s3Client.(taggingClient)
+	if !ok {
+		result.Error = fmt.Sprintf("%T does not support GetObjectTagging", s3Client)
+		return result
+	}
+
+	reqStartTime := time.Now()
+	resp, err := client.GetObjectTagging(traceConnection(ctx, &result, reqStartTime), &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		result.Error = err.Error()
+		recordErrorRequestID(&result, err)
+		return result
+	}
+	recordResponseMetadata(&result, resp.ResultMetadata)
+	result.TTLB = time.Since(reqStartTime)
+	result.Keys = len(resp.TagSet)
+	return result
+}
+
+// printTaggingSummary prints GetObjectTagging latency as part of PrintSummary.
+func (s *Stats) printTaggingSummary(w io.Writer) {
+	if s.TotalTaggings == 0 {
+		return
+	}
+	h := s.TaggingTTLBHist
+	fmt.Fprintf(w, "\nTAGGING Operations (%d total):\n", s.TotalTaggings)
+	fmt.Fprintf(w, "  Success:        %d\n", h.Count())
+	if h.Count() == 0 {
+		fmt.Fprintln(w, "  No successful GetObjectTagging requests to calculate latency.")
+		return
+	}
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	fmt.Fprintf(w, "  TTLB (total)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+		ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Percentile(99.9)), ms(h.Max()))
+}
+
+// taggingSummary returns the TAGGING figures for Summary, nil if no tags were read.
+func (s *Stats) taggingSummary() *OperationSummary {
+	if s.TotalTaggings == 0 {
+		return nil
+	}
+	h := s.TaggingTTLBHist
+	sum := &OperationSummary{Total: s.TotalTaggings, Success: h.Count()}
+	if h.Count() > 0 {
+		sum.TTLB = &LatencySummary{ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Max()), ms(h.Percentile(99.9))}
+	}
+	return sum
+}
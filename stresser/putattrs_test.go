@@ -0,0 +1,124 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// recordingPutClient keeps the last PutObject input.
+type recordingPutClient struct {
+	stubS3Client
+	last *s3.PutObjectInput
+}
+
+func (c *recordingPutClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.last = params
+	return &s3.PutObjectOutput{}, nil
+}
+
+// taggedS3Client returns two tags for every object.
+type taggedS3Client struct {
+	stubS3Client
+}
+
+func (c *taggedS3Client) GetObjectTagging(ctx context.Context, params *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return &s3.GetObjectTaggingOutput{TagSet: []types.Tag{{Key: aws.String("a"), Value: aws.String("1")}, {Key: aws.String("b"), Value: aws.String("2")}}}, nil
+}
+
+func TestParseKeyValues(t *testing.T) {
+	pairs, err := ParseKeyValues(" owner=etl , stage=raw,empty=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 3 || pairs["owner"] != "etl" || pairs["stage"] != "raw" || pairs["empty"] != "" {
+		t.Errorf("Unexpected pairs: %v", pairs)
+	}
+	for _, spec := range []string{"owner", "=value"} {
+		if _, err := ParseKeyValues(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestPutAttributes(t *testing.T) {
+	cfg := &Config{
+		Metadata:     map[string]string{"owner": "etl"},
+		ContentType:  "application/json",
+		CacheControl: "max-age=60",
+		Tags:         map[string]string{"tier": "hot", "team": "data & ml"},
+	}
+	client := &recordingPutClient{}
+	r := performPutOperation(context.Background(), client, "bucket", "key", bytes.NewReader([]byte("body")), true, cfg.putAttributes())
+	if r.Error != "" {
+		t.Fatalf("PUT failed: %s", r.Error)
+	}
+	in := client.last
+	if in.Metadata["owner"] != "etl" || in.Metadata[verifyMetadataKey] == "" {
+		t.Errorf("Expected configured and checksum metadata, got %v", in.Metadata)
+	}
+	if aws.ToString(in.ContentType) != "application/json" || aws.ToString(in.CacheControl) != "max-age=60" {
+		t.Errorf("Unexpected headers: Content-Type %q, Cache-Control %q", aws.ToString(in.ContentType), aws.ToString(in.CacheControl))
+	}
+	if got := aws.ToString(in.Tagging); got != "team=data+%26+ml&tier=hot" {
+		t.Errorf("Unexpected tagging %q", got)
+	}
+	if _, ok := cfg.Metadata[verifyMetadataKey]; ok {
+		t.Error("The checksum leaked into the configured metadata")
+	}
+}
+
+func TestValidatePutAttributes(t *testing.T) {
+	base := Config{Endpoint: "https://test-endpoint.com", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "write", PutObjectSizeKB: 256}
+
+	cfg := base
+	cfg.Metadata = map[string]string{"owner": "yaml", "stage": "raw"}
+	cfg.MetadataSpec = "owner=flag"
+	cfg.TagSpec = "tier=hot"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if cfg.Metadata["owner"] != "flag" || cfg.Metadata["stage"] != "raw" || cfg.Tags["tier"] != "hot" {
+		t.Errorf("Expected flags merged over YAML, got metadata %v, tags %v", cfg.Metadata, cfg.Tags)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"too many tags":   func(c *Config) { c.TagSpec = "a=1,b=2,c=3,d=4,e=5,f=6,g=7,h=8,i=9,j=10,k=11" },
+		"metadata size":   func(c *Config) { c.Metadata = map[string]string{"big": strings.Repeat("x", 2100)} },
+		"reserved key":    func(c *Config) { c.MetadataSpec = verifyMetadataKey + "=x" },
+		"tags on gcs":     func(c *Config) { c.Backend = BackendGCS; c.TagSpec = "a=1" },
+		"tagging on gcs":  func(c *Config) { c.Backend = BackendGCS; c.OperationType = "tagging" },
+		"malformed pairs": func(c *Config) { c.MetadataSpec = "novalue" },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestPerformTaggingOperation(t *testing.T) {
+	ctx := context.Background()
+	r := performTaggingOperation(ctx, &taggedS3Client{}, "bucket", "key")
+	if r.Error != "" || r.Operation != "TAGGING" || r.Keys != 2 || r.TTLB < 0 {
+		t.Fatalf("Unexpected result: %+v", r)
+	}
+	if r := performTaggingOperation(ctx, &stubS3Client{}, "bucket", "key"); r.Error == "" {
+		t.Error("Expected an error from a client without GetObjectTagging")
+	}
+
+	stats := NewStats()
+	stats.AddResult(r)
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "TAGGING Operations (1 total)") {
+		t.Errorf("Summary missing tagging section:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,200 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rampTick is how often the active worker count is updated while ramping.
+const rampTick = 100 * time.Millisecond
+
+// Stage is one segment of a load shape: the active worker count moves linearly
+// from From to To over Duration.
+type Stage struct {
+	From     int    `yaml:"from"`
+	To       int    `yaml:"to"`
+	Duration string `yaml:"duration"` // e.g. "5m"
+}
+
+func (st Stage) String() string {
+	return fmt.Sprintf("%d..%d/%s", st.From, st.To, st.Duration)
+}
+
+// ParseRamp parses a comma-separated list of "from..to/duration" stages,
+// e.g. "0..100/5m" or "10..100/5m,100..100/2m,100..0/1m".
+func ParseRamp(s string) ([]Stage, error) {
+	var stages []Stage
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		span, duration, ok := strings.Cut(part, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid stage %q: expected from..to/duration", part)
+		}
+		fromStr, toStr, ok := strings.Cut(span, "..")
+		if !ok {
+			return nil, fmt.Errorf("invalid stage %q: expected from..to/duration", part)
+		}
+		from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage %q: bad start worker count: %w", part, err)
+		}
+		to, err := strconv.Atoi(strings.TrimSpace(toStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage %q: bad end worker count: %w", part, err)
+		}
+		stages = append(stages, Stage{From: from, To: to, Duration: strings.TrimSpace(duration)})
+	}
+	return stages, nil
+}
+
+// validateStages checks a load shape and returns the peak worker count and total duration.
+func validateStages(stages []Stage) (int, time.Duration, error) {
+	peak := 0
+	var total time.Duration
+	for _, st := range stages {
+		d, err := time.ParseDuration(st.Duration)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("stage %s: duration must be positive", st)
+		}
+		if st.From < 0 || st.To < 0 {
+			return 0, 0, fmt.Errorf("stage %s: worker counts must not be negative", st)
+		}
+		peak = max(peak, st.From, st.To)
+		total += d
+	}
+	if peak == 0 {
+		return 0, 0, fmt.Errorf("no stage has any active workers")
+	}
+	return peak, total, nil
+}
+
+// stageWindow is a stage placed on the run's timeline.
+type stageWindow struct {
+	Stage
+	start, end time.Time
+}
+
+// stageWindows lays stages out back to back from start. Durations are checked by Validate.
+func stageWindows(stages []Stage, start time.Time) []stageWindow {
+	windows := make([]stageWindow, len(stages))
+	at := start
+	for i, st := range stages {
+		d, _ := time.ParseDuration(st.Duration)
+		windows[i] = stageWindow{Stage: st, start: at, end: at.Add(d)}
+		at = at.Add(d)
+	}
+	return windows
+}
+
+// workersAt returns the active worker count at time t: linear interpolation within the
+// current stage, the last stage's end count once all stages are over.
+func workersAt(windows []stageWindow, t time.Time) int {
+	for _, w := range windows {
+		if t.Before(w.end) {
+			frac := float64(t.Sub(w.start)) / float64(w.end.Sub(w.start))
+			return w.From + int(math.Round(frac*float64(w.To-w.From)))
+		}
+	}
+	return windows[len(windows)-1].To
+}
+
+// runRamp moves the active worker limit along the stages until ctx is done.
+func runRamp(ctx context.Context, limit *workerLimit, windows []stageWindow) {
+	ticker := time.NewTicker(rampTick)
+	defer ticker.Stop()
+	current := -1
+	stage := -1
+	for {
+		now := time.Now()
+		for stage+1 < len(windows) && !now.Before(windows[stage+1].start) {
+			stage++
+			slog.Info("Load stage started", "stage", stage+1, "shape", windows[stage].Stage.String())
+		}
+		if n := workersAt(windows, now); n != current {
+			limit.Set(n)
+			current = n
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StageStats aggregates the requests started during one load stage.
+type StageStats struct {
+	Stage    string        `json:"stage" yaml:"stage"` // "from..to/duration"
+	Requests int64         `json:"requests" yaml:"requests"`
+	Errors   int64         `json:"errors" yaml:"errors"`
+	ReqPerS  float64       `json:"reqPerSec" yaml:"reqPerSec"`
+	P50TTLB  time.Duration `json:"-" yaml:"-"`
+	P99TTLB  time.Duration `json:"-" yaml:"-"`
+	P50Ms    float64       `json:"p50Ms" yaml:"p50Ms"`
+	P99Ms    float64       `json:"p99Ms" yaml:"p99Ms"`
+	window   stageWindow
+	ttlbs    *Histogram
+}
+
+// SetStages enables per-stage statistics for a run that started at start. Must be called
+// before results are added.
+func (s *Stats) SetStages(stages []Stage, start time.Time) {
+	s.stages = nil
+	for _, w := range stageWindows(stages, start) {
+		s.stages = append(s.stages, &StageStats{Stage: w.Stage.String(), window: w, ttlbs: NewHistogram()})
+	}
+}
+
+// addStageResult records r against the stage it started in. Called from AddResult.
+func (s *Stats) addStageResult(r Result) {
+	for _, st := range s.stages {
+		if r.Timestamp.Before(st.window.end) {
+			st.Requests++
+			if r.Error != "" {
+				st.Errors++
+			} else {
+				st.ttlbs.Record(r.TTLB)
+			}
+			return
+		}
+	}
+}
+
+// calculateStageStats computes per-stage rates and latency percentiles. Called from Calculate.
+func (s *Stats) calculateStageStats() {
+	for _, st := range s.stages {
+		if secs := st.window.end.Sub(st.window.start).Seconds(); secs > 0 {
+			st.ReqPerS = float64(st.Requests) / secs
+		}
+		if st.ttlbs.Count() > 0 {
+			st.P50TTLB = st.ttlbs.Percentile(50)
+			st.P99TTLB = st.ttlbs.Percentile(99)
+			st.P50Ms, st.P99Ms = ms(st.P50TTLB), ms(st.P99TTLB)
+		}
+	}
+}
+
+// Stages returns the per-stage statistics, nil unless the run used a load shape.
+func (s *Stats) Stages() []*StageStats {
+	return s.stages
+}
+
+// printStageSummary prints per-stage statistics as part of PrintSummary.
+func (s *Stats) printStageSummary(w io.Writer) {
+	if len(s.stages) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nLoad Stages:\n")
+	fmt.Fprintf(w, "  Stage            | Requests | Errors |   Req/s  | P50 (ms) | P99 (ms)\n")
+	fmt.Fprintf(w, "  -----------------|----------|--------|----------|----------|----------\n")
+	for _, st := range s.stages {
+		fmt.Fprintf(w, "  %-16s | %8d | %6d | %8.2f | %8.2f | %8.2f\n",
+			st.Stage, st.Requests, st.Errors, st.ReqPerS, st.P50Ms, st.P99Ms)
+	}
+}
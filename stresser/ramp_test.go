@@ -0,0 +1,86 @@
+package stresser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRamp(t *testing.T) {
+	stages, err := ParseRamp("0..100/5m, 100..0/1m")
+	if err != nil {
+		t.Fatalf("ParseRamp failed: %v", err)
+	}
+	if len(stages) != 2 || stages[0] != (Stage{From: 0, To: 100, Duration: "5m"}) || stages[1] != (Stage{From: 100, To: 0, Duration: "1m"}) {
+		t.Errorf("Unexpected stages: %+v", stages)
+	}
+
+	for _, bad := range []string{"0..100", "100/5m", "a..100/5m", "0..b/5m"} {
+		if _, err := ParseRamp(bad); err == nil {
+			t.Errorf("Expected error for ramp %q", bad)
+		}
+	}
+}
+
+func TestValidateStagesSetsConcurrencyAndDuration(t *testing.T) {
+	cfg := &Config{Bucket: "b", Endpoint: "http://localhost", Region: "r", Concurrency: 5, Duration: "10s",
+		OperationType: "read", ManifestPath: "manifest.txt", OutputFile: "results.csv", Ramp: "10..40/1m,40..0/30s"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.Concurrency != 40 || cfg.Duration != "1m30s" {
+		t.Errorf("Expected concurrency 40 and duration 1m30s, got %d and %s", cfg.Concurrency, cfg.Duration)
+	}
+
+	for _, stages := range [][]Stage{{{From: 0, To: 0, Duration: "1m"}}, {{From: -1, To: 5, Duration: "1m"}}, {{From: 1, To: 5, Duration: "0s"}}} {
+		if _, _, err := validateStages(stages); err == nil {
+			t.Errorf("Expected error for stages %+v", stages)
+		}
+	}
+}
+
+func TestWorkersAt(t *testing.T) {
+	start := time.Now()
+	windows := stageWindows([]Stage{{From: 0, To: 100, Duration: "10s"}, {From: 100, To: 20, Duration: "10s"}}, start)
+
+	cases := []struct {
+		at   time.Duration
+		want int
+	}{
+		{0, 0},
+		{5 * time.Second, 50},
+		{10 * time.Second, 100},
+		{15 * time.Second, 60},
+		{30 * time.Second, 20}, // After the last stage: hold its end count
+	}
+	for _, c := range cases {
+		if got := workersAt(windows, start.Add(c.at)); got != c.want {
+			t.Errorf("workersAt(+%s) = %d, want %d", c.at, got, c.want)
+		}
+	}
+}
+
+func TestStatsPerStage(t *testing.T) {
+	start := time.Now()
+	s := NewStats()
+	s.SetStages([]Stage{{From: 1, To: 1, Duration: "1s"}, {From: 2, To: 2, Duration: "2s"}}, start)
+
+	s.AddResult(Result{Operation: "GET", Timestamp: start.Add(100 * time.Millisecond), TTLB: 10 * time.Millisecond})
+	s.AddResult(Result{Operation: "GET", Timestamp: start.Add(1500 * time.Millisecond), TTLB: 20 * time.Millisecond})
+	s.AddResult(Result{Operation: "GET", Timestamp: start.Add(2 * time.Second), TTLB: 30 * time.Millisecond})
+	s.AddResult(Result{Operation: "GET", Timestamp: start.Add(2500 * time.Millisecond), Error: "boom"})
+	s.Calculate(start, start.Add(3*time.Second))
+
+	stages := s.Stages()
+	if len(stages) != 2 {
+		t.Fatalf("Expected 2 stages, got %d", len(stages))
+	}
+	if stages[0].Requests != 1 || stages[0].Errors != 0 || stages[0].ReqPerS != 1 {
+		t.Errorf("Unexpected first stage: %+v", stages[0])
+	}
+	if stages[1].Requests != 3 || stages[1].Errors != 1 || stages[1].ReqPerS != 1.5 {
+		t.Errorf("Unexpected second stage: %+v", stages[1])
+	}
+	if stages[1].P99TTLB != 30*time.Millisecond {
+		t.Errorf("Expected second stage P99 of 30ms, got %s", stages[1].P99TTLB)
+	}
+}
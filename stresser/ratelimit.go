@@ -0,0 +1,70 @@
+package stresser
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a token-bucket rate limiter. Tokens refill continuously at rate
+// per second up to burst. It is safe for concurrent use, so one bucket can pace a
+// single worker or be shared by many.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64 // Maximum tokens stored
+	tokens float64 // Tokens currently available; negative when waiters have reserved future tokens
+	last   time.Time
+	due    time.Time // Slot of the next token on a fixed schedule at rate, see WaitScheduled
+}
+
+// newTokenBucket creates a bucket that starts full.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		due:    time.Now(),
+	}
+}
+
+// reserve takes n tokens and returns how long the caller must wait before using them.
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reserveLocked(n)
+}
+
+func (b *tokenBucket) reserveLocked(n float64) time.Duration {
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Wait blocks until a token is available or ctx is done. It returns false if the context ended first.
+func (b *tokenBucket) Wait(ctx context.Context) bool {
+	return sleepContext(ctx, b.reserve(1))
+}
+
+// WaitScheduled is Wait that also returns when the request was due: the token's slot on a
+// fixed schedule at the bucket's rate, starting when the bucket was created. The bucket
+// forgets tokens nobody was ready to take, the schedule does not, so when the callers fall
+// behind the rate the due times fall further and further behind the actual starts.
+func (b *tokenBucket) WaitScheduled(ctx context.Context) (time.Time, bool) {
+	b.mu.Lock()
+	due := b.due
+	b.due = b.due.Add(time.Duration(float64(time.Second) / b.rate))
+	wait := b.reserveLocked(1)
+	b.mu.Unlock()
+	return due, sleepContext(ctx, wait)
+}
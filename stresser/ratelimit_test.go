@@ -0,0 +1,123 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	b := newTokenBucket(10, 2) // 10/s, burst of 2
+
+	// The burst is available immediately
+	if wait := b.reserve(1); wait != 0 {
+		t.Errorf("Expected no wait for first token, got %v", wait)
+	}
+	if wait := b.reserve(1); wait != 0 {
+		t.Errorf("Expected no wait for second token, got %v", wait)
+	}
+	// Subsequent tokens are spaced at 100ms
+	if wait := b.reserve(1); wait < 90*time.Millisecond || wait > 100*time.Millisecond {
+		t.Errorf("Expected ~100ms wait for third token, got %v", wait)
+	}
+	if wait := b.reserve(1); wait < 190*time.Millisecond || wait > 200*time.Millisecond {
+		t.Errorf("Expected ~200ms wait for fourth token, got %v", wait)
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	b := newTokenBucket(100, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if !b.Wait(ctx) {
+			t.Fatal("Wait returned false without cancellation")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("Expected 6 tokens at 100/s to take ~50ms, took %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	slow := newTokenBucket(0.1, 1)
+	slow.reserve(1)
+	if slow.Wait(cancelled) {
+		t.Error("Expected Wait to return false for a cancelled context")
+	}
+}
+
+func TestTokenBucketWaitScheduled(t *testing.T) {
+	b := newTokenBucket(100, 1)
+	ctx := context.Background()
+	start := time.Now()
+
+	first, _ := b.WaitScheduled(ctx)
+	if first.Sub(start) > 5*time.Millisecond {
+		t.Errorf("First token should be due at creation, due after %v", first.Sub(start))
+	}
+
+	// A caller busy for 100ms misses ten slots; the schedule keeps them
+	time.Sleep(100 * time.Millisecond)
+	second, _ := b.WaitScheduled(ctx)
+	if d := second.Sub(first); d != 10*time.Millisecond {
+		t.Errorf("Expected the second token due 10ms after the first, got %v", d)
+	}
+	if behind := time.Since(second); behind < 90*time.Millisecond {
+		t.Errorf("Expected the second token to be ~100ms overdue, got %v", behind)
+	}
+}
+
+func TestWorkerRPSPacesGenerationAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	path := filepath.Join(t.TempDir(), "workload.csv")
+	content := "op,key,size,timestamp\n"
+	for i := 0; i < 6; i++ {
+		content += fmt.Sprintf("PUT,data/%d.dat,100,2024-01-01T00:00:00Z\n", i)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create replay file: %v", err)
+	}
+
+	base := Config{
+		Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret",
+		Duration: "10s", Concurrency: 2, OutputFile: "unused.csv", PutObjectSizeKB: 1, WorkerRPS: 5,
+		ReplaySpeed: 1, ReplayTiming: "asap",
+	}
+	for name, mutate := range map[string]func(*Config){
+		"files":  func(c *Config) { c.OperationType = "write"; c.FileCount = 6 },
+		"replay": func(c *Config) { c.OperationType = "replay"; c.ReplayFile = path },
+	} {
+		cfg := base
+		mutate(&cfg)
+		runner, err := New(&cfg)
+		if err != nil {
+			t.Fatalf("%s: New failed: %v", name, err)
+		}
+		start := time.Now()
+		results, _, err := runner.Run(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Run failed: %v", name, err)
+		}
+		if len(results) != 6 {
+			t.Fatalf("%s: expected 6 results, got %d", name, len(results))
+		}
+		// Each worker makes at least three PUTs: one at once, the others 200ms apart
+		if elapsed := time.Since(start); elapsed < 350*time.Millisecond {
+			t.Errorf("%s: expected -worker-rps to pace the workers, 6 PUTs took %v", name, elapsed)
+		}
+	}
+}
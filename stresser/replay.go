@@ -0,0 +1,355 @@
+package stresser
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReplayOp is a single operation read from a replay source.
+type ReplayOp struct {
+	Offset    time.Duration // Time since the first operation in the source
+	Operation string        // "GET" or "PUT"
+	ObjectKey string
+	Size      int64 // Object size in bytes, used as the body size for PUTs
+}
+
+// s3AccessLogTime is the timestamp layout used in S3 server access logs, e.g. [06/Feb/2019:00:00:38 +0000]
+const s3AccessLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// LoadReplayOps reads operations from a replay source and returns them ordered by offset.
+// Supported formats are "csv" (op,key,size,timestamp), "s3log" (S3 server access logs) and
+// "trace" (files recorded by TraceWriter). An empty format is inferred from the file extension:
+// .csv is CSV, .trace a trace, anything else an access log.
+func LoadReplayOps(filePath, format string) ([]ReplayOp, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".csv":
+			format = "csv"
+		case ".trace":
+			format = "trace"
+		default:
+			format = "s3log"
+		}
+	}
+
+	var ops []ReplayOp
+	var skipped int
+	switch format {
+	case "csv":
+		ops, skipped, err = parseReplayCSV(file)
+	case "s3log":
+		ops, skipped, err = parseS3AccessLog(file)
+	case "trace":
+		ops, skipped, err = parseTrace(file)
+	default:
+		return nil, fmt.Errorf("unknown replay format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading replay file %s: %w", filePath, err)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("replay file %s contains no replayable operations", filePath)
+	}
+	if skipped > 0 {
+		slog.Warn("Skipped unsupported operations in replay file", "path", filePath, "skipped", skipped)
+	}
+
+	return ops, nil
+}
+
+// timedReplayOp pairs an operation with its absolute time while a source is being parsed.
+type timedReplayOp struct {
+	at time.Time
+	op ReplayOp
+}
+
+// normalizeReplayOps sorts operations by time and rebases offsets on the first one.
+func normalizeReplayOps(timed []timedReplayOp) []ReplayOp {
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].at.Before(timed[j].at) })
+	ops := make([]ReplayOp, len(timed))
+	for i, t := range timed {
+		ops[i] = t.op
+		ops[i].Offset = t.at.Sub(timed[0].at)
+	}
+	return ops
+}
+
+// parseReplayCSV reads "op,key,size,timestamp" records. The timestamp is either RFC 3339
+// or fractional Unix seconds. A leading header row is ignored.
+func parseReplayCSV(r io.Reader) ([]ReplayOp, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var timed []timedReplayOp
+	skipped := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "op") {
+			continue // Header row
+		}
+
+		op := replayOperation(record[0])
+		if op == "" {
+			skipped++
+			continue
+		}
+		size, err := strconv.ParseInt(record[2], 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid size %q", line, record[2])
+		}
+		ts, err := parseReplayTimestamp(record[3])
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		timed = append(timed, timedReplayOp{at: ts, op: ReplayOp{Operation: op, ObjectKey: record[1], Size: size}})
+	}
+
+	return normalizeReplayOps(timed), skipped, nil
+}
+
+// parseS3AccessLog reads S3 server access log records. Only object GETs and PUTs are replayed.
+func parseS3AccessLog(r io.Reader) ([]ReplayOp, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // Log lines with long user agents exceed the default
+
+	var timed []timedReplayOp
+	skipped := 0
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// Fields: owner bucket [time] ip requester request-id operation key uri status error bytes-sent object-size ...
+		fields := splitAccessLogFields(line)
+		if len(fields) < 13 {
+			return nil, 0, fmt.Errorf("line %d: expected at least 13 fields, got %d", lineNum, len(fields))
+		}
+
+		var op string
+		switch fields[6] {
+		case "REST.GET.OBJECT":
+			op = "GET"
+		case "REST.PUT.OBJECT":
+			op = "PUT"
+		case "REST.HEAD.OBJECT":
+			op = "HEAD"
+		}
+		if op == "" || fields[7] == "-" {
+			skipped++
+			continue
+		}
+
+		ts, err := time.Parse(s3AccessLogTime, strings.Trim(fields[2], "[]"))
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid time %q: %w", lineNum, fields[2], err)
+		}
+		key, err := url.QueryUnescape(fields[7])
+		if err != nil {
+			key = fields[7]
+		}
+		var size int64
+		if fields[12] != "-" {
+			size, _ = strconv.ParseInt(fields[12], 10, 64)
+		}
+
+		timed = append(timed, timedReplayOp{at: ts, op: ReplayOp{Operation: op, ObjectKey: key, Size: size}})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return normalizeReplayOps(timed), skipped, nil
+}
+
+// splitAccessLogFields splits an access log line on spaces, keeping [bracketed] and "quoted" fields intact.
+func splitAccessLogFields(line string) []string {
+	var fields []string
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+		end := byte(' ')
+		start := i
+		switch line[i] {
+		case '[':
+			end = ']'
+		case '"':
+			end = '"'
+			start++ // Strip the quotes
+		}
+		j := strings.IndexByte(line[i+1:], end)
+		if j < 0 {
+			fields = append(fields, line[start:])
+			break
+		}
+		stop := i + 1 + j
+		if end == ']' {
+			stop++ // Keep the closing bracket
+		}
+		fields = append(fields, line[start:stop])
+		i = stop
+		if end == '"' {
+			i++ // Skip the closing quote
+		}
+	}
+	return fields
+}
+
+// replayOperation maps an operation name from a replay source to GET, PUT or HEAD, or "" if unsupported.
+func replayOperation(op string) string {
+	switch strings.ToUpper(strings.TrimSpace(op)) {
+	case "GET", "READ":
+		return "GET"
+	case "PUT", "WRITE":
+		return "PUT"
+	case "HEAD":
+		return "HEAD"
+	}
+	return ""
+}
+
+// parseReplayTimestamp accepts RFC 3339 timestamps or (fractional) Unix seconds.
+func parseReplayTimestamp(value string) (time.Time, error) {
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ts, nil
+	}
+	secs, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return time.Unix(0, int64(secs*float64(time.Second))), nil
+}
+
+// replayDue returns when an operation is due, relative to the replay start, for the given timing mode:
+// "original" honors the recorded offsets, "scaled" divides them by speed, and "asap" ignores timing
+// entirely so operations are issued as fast as the workers can take them.
+func replayDue(offset time.Duration, timing string, speed float64) time.Duration {
+	switch timing {
+	case "original":
+		return offset
+	case "asap":
+		return 0
+	default:
+		return time.Duration(float64(offset) / speed)
+	}
+}
+
+// runReplay re-issues the loaded operations on the schedule selected by cfg.ReplayTiming.
+// A pool of cfg.Concurrency workers executes them; if all workers are busy, dispatch falls behind schedule.
+func runReplay(ctx context.Context, wg *sync.WaitGroup, deps *workerDeps) {
+	defer wg.Done()
+	cfg, ops := deps.cfg, deps.replayOps
+	slog.Info("Replay started", "operations", len(ops), "timing", cfg.ReplayTiming, "speed", cfg.ReplaySpeed)
+
+	opsChan := make(chan timedReplayOp) // With -rps, at is when the operation was due to start
+	var workerWg sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		workerWg.Add(1)
+		go func(workerId int) {
+			defer workerWg.Done()
+			localRand := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerId)))
+			pattern, _ := parseDataPattern(cfg.DataPattern) // Already validated in Config.Validate
+			var workerBucket *tokenBucket
+			if cfg.WorkerRPS > 0 {
+				workerBucket = newTokenBucket(cfg.WorkerRPS, 1)
+			}
+
+			for timed := range opsChan {
+				op, due := timed.op, timed.at
+				if workerBucket != nil {
+					workerDue, ok := workerBucket.WaitScheduled(ctx)
+					if !ok {
+						slog.Info("Replay worker stopping", "workerId", workerId, "reason", ctx.Err())
+						return
+					}
+					due = later(due, workerDue)
+				}
+				var result Result
+				bucket := deps.buckets.pick(localRand, op.ObjectKey)
+				target := deps.endpoints.pick(localRand)
+				s3Client := target.client
+				switch op.Operation {
+				case "GET":
+					result = fetchObject(ctx, s3Client, cfg, deps.hedge, bucket, op.ObjectKey)
+				case "PUT":
+					result = uploadObject(ctx, s3Client, cfg, bucket, op.ObjectKey, pattern.payload(op.Size, localRand))
+				case "HEAD":
+					result = performHeadOperation(ctx, s3Client, bucket, op.ObjectKey)
+				}
+				result.IntendedStart = due
+				if deps.buckets.multiple() {
+					result.Bucket = bucket
+				}
+				if deps.endpoints.multiple() {
+					result.Endpoint = target.url
+				}
+
+				select {
+				case deps.resultsChan <- result:
+				case <-ctx.Done():
+					slog.Info("Replay worker context cancelled while sending result", "workerId", workerId, "reason", ctx.Err())
+					return
+				}
+			}
+		}(i)
+	}
+
+	startTime := time.Now()
+dispatch:
+	for i, op := range ops {
+		due := startTime.Add(replayDue(op.Offset, cfg.ReplayTiming, cfg.ReplaySpeed))
+		if !sleepContext(ctx, time.Until(due)) {
+			break
+		}
+		// -rps caps the rate on top of the recorded timing, in 'asap' mode too
+		var rateDue time.Time
+		if deps.rate != nil {
+			var ok bool
+			if rateDue, ok = deps.rate.WaitScheduled(ctx); !ok {
+				break
+			}
+		}
+		select {
+		case opsChan <- timedReplayOp{at: rateDue, op: op}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		if i > 0 && i%progressCount == 0 {
+			slog.Info("Replay progress", "current", i, "total", len(ops), "lag", time.Since(due).Round(time.Millisecond))
+		}
+	}
+	close(opsChan)
+
+	workerWg.Wait()
+	slog.Info("Replay completed", "operations", len(ops))
+}
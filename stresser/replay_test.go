@@ -0,0 +1,147 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadReplayOpsCSV(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workload.csv")
+	content := `op,key,size,timestamp
+PUT,data/b.dat,2048,2024-01-01T00:00:02Z
+GET,data/a.dat,1024,2024-01-01T00:00:00Z
+DELETE,data/c.dat,0,2024-01-01T00:00:01Z
+GET,data/b.dat,2048,2024-01-01T00:00:03.5Z
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create replay file: %v", err)
+	}
+
+	ops, err := LoadReplayOps(path, "")
+	if err != nil {
+		t.Fatalf("LoadReplayOps failed: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("Expected 3 replayable operations, got %d", len(ops))
+	}
+
+	expected := []ReplayOp{
+		{Offset: 0, Operation: "GET", ObjectKey: "data/a.dat", Size: 1024},
+		{Offset: 2 * time.Second, Operation: "PUT", ObjectKey: "data/b.dat", Size: 2048},
+		{Offset: 3500 * time.Millisecond, Operation: "GET", ObjectKey: "data/b.dat", Size: 2048},
+	}
+	for i, op := range ops {
+		if op != expected[i] {
+			t.Errorf("Operation %d: expected %+v, got %+v", i, expected[i], op)
+		}
+	}
+}
+
+func TestLoadReplayOpsS3AccessLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	content := `79a5 mybucket [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a5 3E57427F3EXAMPLE REST.GET.OBJECT photos/cat%20one.jpg "GET /mybucket/photos/cat%20one.jpg HTTP/1.1" 200 - 113 113 7 6 "-" "S3Console/0.4" - s9lz= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader mybucket.s3.amazonaws.com TLSV1.2
+79a5 mybucket [06/Feb/2019:00:00:40 +0000] 192.0.2.3 79a5 891CE47D2EXAMPLE REST.GET.BUCKET - "GET /mybucket?list-type=2 HTTP/1.1" 200 - 242 - 11 - "-" "S3Console/0.4" - 9vKB= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader mybucket.s3.amazonaws.com TLSV1.2
+79a5 mybucket [06/Feb/2019:00:00:41 +0000] 192.0.2.3 79a5 A1206F460EXAMPLE REST.PUT.OBJECT uploads/new.bin "PUT /mybucket/uploads/new.bin HTTP/1.1" 200 - - 4096 18 17 "-" "aws-cli/2.0" - Ke1b= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader mybucket.s3.amazonaws.com TLSV1.2
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create replay file: %v", err)
+	}
+
+	ops, err := LoadReplayOps(path, "")
+	if err != nil {
+		t.Fatalf("LoadReplayOps failed: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("Expected 2 replayable operations, got %d", len(ops))
+	}
+	if ops[0].Operation != "GET" || ops[0].ObjectKey != "photos/cat one.jpg" || ops[0].Size != 113 {
+		t.Errorf("Unexpected first operation: %+v", ops[0])
+	}
+	if ops[1].Operation != "PUT" || ops[1].ObjectKey != "uploads/new.bin" || ops[1].Size != 4096 || ops[1].Offset != 3*time.Second {
+		t.Errorf("Unexpected second operation: %+v", ops[1])
+	}
+}
+
+func TestLoadReplayOpsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(path, []byte("op,key,size,timestamp\n"), 0644); err != nil {
+		t.Fatalf("Failed to create replay file: %v", err)
+	}
+	if _, err := LoadReplayOps(path, "csv"); err == nil {
+		t.Error("Expected error for replay file without operations")
+	}
+}
+
+func TestReplayDue(t *testing.T) {
+	offset := 10 * time.Second
+	tests := []struct {
+		timing   string
+		speed    float64
+		expected time.Duration
+	}{
+		{timing: "original", speed: 4, expected: 10 * time.Second},
+		{timing: "scaled", speed: 4, expected: 2500 * time.Millisecond},
+		{timing: "scaled", speed: 0.5, expected: 20 * time.Second},
+		{timing: "asap", speed: 1, expected: 0},
+	}
+	for _, tt := range tests {
+		if got := replayDue(offset, tt.timing, tt.speed); got != tt.expected {
+			t.Errorf("replayDue(%v, %q, %v) = %v, expected %v", offset, tt.timing, tt.speed, got, tt.expected)
+		}
+	}
+}
+
+func TestReplayRespectsRPS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	path := filepath.Join(t.TempDir(), "workload.csv")
+	content := "op,key,size,timestamp\n"
+	for i := 0; i < 10; i++ {
+		content += fmt.Sprintf("PUT,data/%d.dat,100,2024-01-01T00:00:00Z\n", i)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create replay file: %v", err)
+	}
+
+	cfg := &Config{
+		Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret",
+		Duration: "10s", Concurrency: 4, OutputFile: "unused.csv", OperationType: "replay", ReplayFile: path,
+		ReplayTiming: "asap", ReplaySpeed: 1, RPS: 20,
+	}
+	runner, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	start := time.Now()
+	results, _, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 10 {
+		t.Fatalf("Expected 10 results, got %d", len(results))
+	}
+	// The first token is available at once, the other nine follow at 20 per second
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("Expected -rps to pace the replay, 10 operations took %v", elapsed)
+	}
+	for _, r := range results {
+		if r.IntendedStart.IsZero() {
+			t.Fatal("Expected rate-limited operations to record when they were due")
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package stresser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// LoadResults reads detailed results written with the csv, jsonl, json or parquet format, so the
+// summary of an earlier run can be reported again. An empty format is inferred from the
+// file extension. Only the columns the format holds are restored: CSV files lack attempt
+// counts, connection details and the other fields of the JSON records.
+func LoadResults(path, format string) ([]Result, error) {
+	if format == "" {
+		format = InferResultFormat(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	defer f.Close()
+
+	switch format {
+	case FormatCSV:
+		return decodeResultsCSV(f)
+	case FormatJSONL, FormatJSON:
+		return decodeResultsJSONL(f)
+	case FormatParquet:
+		return decodeResultsParquet(f)
+	default:
+		return nil, fmt.Errorf("cannot read results in %q format, only %s, %s, %s and %s", format, FormatCSV, FormatJSONL, FormatJSON, FormatParquet)
+	}
+}
+
+func decodeResultsCSV(r io.Reader) ([]Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // The RunId, BytesCopied, BytesDecoded, VersionId, Group, Bucket, Endpoint, Warmup, RequestId, HostId, Status, BodyTTFB, Windows and MinWindow columns are optional
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	if len(header) < 8 || header[0] != "Timestamp" {
+		return nil, fmt.Errorf("not an ostresser results csv: unexpected header %v", header)
+	}
+	// The columns after Error were added over time, so look them up by name
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[name] = i
+	}
+	optional := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	optionalMs := func(row []string, name string) (time.Duration, error) {
+		v := optional(row, name)
+		if v == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		return fromMs(f), err
+	}
+
+	var results []Result
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv line %d: %w", line, err)
+		}
+		if len(row) < 8 {
+			return nil, fmt.Errorf("csv line %d has %d columns, want at least 8", line, len(row))
+		}
+		ts, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: invalid timestamp: %w", line, err)
+		}
+		ttfb, err1 := strconv.ParseFloat(row[3], 64)
+		ttlb, err2 := strconv.ParseFloat(row[4], 64)
+		down, err3 := strconv.ParseInt(row[5], 10, 64)
+		up, err4 := strconv.ParseInt(row[6], 10, 64)
+		dns, err5 := optionalMs(row, "DNS(ms)")
+		connect, err6 := optionalMs(row, "Connect(ms)")
+		tlsTime, err7 := optionalMs(row, "TLS(ms)")
+		firstByte, err8 := optionalMs(row, "FirstByte(ms)")
+		bodyTTFB, err12 := optionalMs(row, "BodyTTFB(ms)")
+		var copied int64
+		var err9 error
+		if v := optional(row, "BytesCopied"); v != "" {
+			copied, err9 = strconv.ParseInt(v, 10, 64)
+		}
+		var decoded int64
+		var err10 error
+		if v := optional(row, "BytesDecoded"); v != "" {
+			decoded, err10 = strconv.ParseInt(v, 10, 64)
+		}
+		var status int
+		var err11 error
+		if v := optional(row, "Status"); v != "" {
+			status, err11 = strconv.Atoi(v)
+		}
+		var windows int
+		var minWindow float64
+		var err13, err14 error
+		if v := optional(row, "Windows"); v != "" {
+			windows, err13 = strconv.Atoi(v)
+		}
+		if v := optional(row, "MinWindow(MiB/s)"); v != "" {
+			minWindow, err14 = strconv.ParseFloat(v, 64)
+		}
+		if err := errors.Join(err1, err2, err3, err4, err5, err6, err7, err8, err9, err10, err11, err12, err13, err14); err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		results = append(results, Result{
+			Timestamp:       ts,
+			Operation:       row[1],
+			RunID:           optional(row, "RunId"),
+			Group:           optional(row, "Group"),
+			Bucket:          optional(row, "Bucket"),
+			Endpoint:        optional(row, "Endpoint"),
+			ObjectKey:       row[2],
+			VersionID:       optional(row, "VersionId"),
+			TTFB:            fromMs(ttfb),
+			BodyTTFB:        bodyTTFB,
+			TTLB:            fromMs(ttlb),
+			Windows:         windows,
+			MinWindowMiBps:  minWindow,
+			BytesDownloaded: down,
+			BytesUploaded:   up,
+			BytesCopied:     copied,
+			BytesDecoded:    decoded,
+			Error:           row[7],
+			RequestID:       optional(row, "RequestId"),
+			HostID:          optional(row, "HostId"),
+			StatusCode:      status,
+			DNS:             dns,
+			Connect:         connect,
+			TLSHandshake:    tlsTime,
+			FirstByte:       firstByte,
+			Warmup:          optional(row, "Warmup") == "true",
+		})
+	}
+}
+
+// decodeResultsJSONL reads JSON lines records; the summary line of the json format is skipped.
+func decodeResultsJSONL(r io.Reader) ([]Result, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var results []Result
+	for line := 1; scanner.Scan(); line++ {
+		var rec struct {
+			ResultRecord
+			Summary json.RawMessage `json:"summary"`
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Summary != nil {
+			continue
+		}
+		results = append(results, rec.Result())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	return results, nil
+}
+
+// Result converts a record back; the inverse of NewResultRecord.
+func (rec ResultRecord) Result() Result {
+	r := Result{
+		Timestamp:       rec.Timestamp,
+		Operation:       rec.Operation,
+		RunID:           rec.RunID,
+		Group:           rec.Group,
+		Bucket:          rec.Bucket,
+		Endpoint:        rec.Endpoint,
+		ObjectKey:       rec.ObjectKey,
+		VersionID:       rec.VersionID,
+		ETag:            rec.ETag,
+		TTFB:            fromMs(rec.TTFBMs),
+		BodyTTFB:        fromMs(rec.BodyTTFBMs),
+		TTLB:            fromMs(rec.TTLBMs),
+		Windows:         rec.Windows,
+		MinWindowMiBps:  rec.MinWindowMiBps,
+		BytesDownloaded: rec.BytesDownloaded,
+		BytesUploaded:   rec.BytesUploaded,
+		BytesCopied:     rec.BytesCopied,
+		BytesDecoded:    rec.BytesDecoded,
+		Error:           rec.Error,
+		RequestID:       rec.RequestID,
+		HostID:          rec.HostID,
+		StatusCode:      rec.StatusCode,
+		Protocol:        rec.Protocol,
+		Attempts:        rec.Attempts,
+		RemoteAddr:      rec.RemoteAddr,
+		ConnReused:      rec.ConnReused,
+		ConnWait:        fromMs(rec.ConnWaitMs),
+		DNS:             fromMs(rec.DNSMs),
+		Connect:         fromMs(rec.ConnectMs),
+		TLSHandshake:    fromMs(rec.TLSMs),
+		FirstByte:       fromMs(rec.FirstByteMs),
+		Hedged:          rec.Hedged,
+		HedgeWon:        rec.HedgeWon,
+		Keys:            rec.Keys,
+		Warmup:          rec.Warmup,
+		Integrity:       rec.Integrity,
+		Checksum:        rec.Checksum,
+		Overwrite:       rec.Overwrite,
+		Disconnect:      rec.Disconnect,
+		Append:          rec.Append,
+		RMW:             fromMs(rec.RMWMs),
+		Conditional:     rec.Conditional,
+		Consistency:     rec.Consistency,
+		ProbeDelay:      fromMs(rec.ProbeDelayMs),
+		VisibleAfter:    fromMs(rec.VisibleAfterMs),
+	}
+	if rec.VisibleAfterMs < 0 {
+		r.VisibleAfter = -1
+	}
+	if rec.ScheduleDelayMs > 0 {
+		r.IntendedStart = rec.Timestamp.Add(-fromMs(rec.ScheduleDelayMs))
+	}
+	return r
+}
+
+// fromMs converts milliseconds as written by ms back to a duration.
+func fromMs(v float64) time.Duration {
+	return time.Duration(v * float64(time.Millisecond))
+}
+
+// CalculateFromResults adds loaded results and calculates the stats. Warm-up results are
+// left out, as during the run; the run is taken to span from the first request to the last response.
+func (s *Stats) CalculateFromResults(results []Result) {
+	var start, end time.Time
+	for _, r := range results {
+		if r.Warmup {
+			continue
+		}
+		s.AddResult(r)
+		if start.IsZero() || r.Timestamp.Before(start) {
+			start = r.Timestamp
+		}
+		if done := r.Timestamp.Add(max(r.TTLB, 0)); done.After(end) {
+			end = done
+		}
+	}
+	s.Calculate(start, end)
+}
@@ -0,0 +1,79 @@
+package stresser
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func reportResults() []Result {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []Result{
+		{Timestamp: start, Operation: "GET", ObjectKey: "a", TTFB: time.Millisecond, TTLB: 5 * time.Millisecond, BytesDownloaded: 1024, Warmup: true},
+		{Timestamp: start.Add(time.Second), Operation: "GET", ObjectKey: "b", TTFB: 2 * time.Millisecond, TTLB: 7 * time.Millisecond, BytesDownloaded: 2048, RunID: "r1", RequestID: "req-1", HostID: "host-1", StatusCode: 200,
+			DNS: time.Millisecond, Connect: 2 * time.Millisecond, TLSHandshake: 3 * time.Millisecond, FirstByte: 2 * time.Millisecond},
+		{Timestamp: start.Add(2 * time.Second), Operation: "PUT", ObjectKey: "c", TTLB: 9 * time.Millisecond, BytesUploaded: 4096,
+			IntendedStart: start.Add(2*time.Second - 3*time.Millisecond)},
+		{Timestamp: start.Add(2 * time.Second), Operation: "GET", ObjectKey: "d", TTLB: 3 * time.Millisecond, Error: "StatusCode: 503", StatusCode: 503},
+	}
+}
+
+func TestLoadResultsRoundTrip(t *testing.T) {
+	results := reportResults()
+	for _, format := range []string{FormatCSV, FormatJSONL, FormatJSON} {
+		path := filepath.Join(t.TempDir(), "results."+format)
+		s := NewStats()
+		s.CalculateFromResults(results)
+		if err := WriteResultsWithStats(results, s, path, format); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadResults(path, "")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(loaded) != len(results) {
+			t.Fatalf("%s: loaded %d results, want %d", format, len(loaded), len(results))
+		}
+		for i, want := range results {
+			got := loaded[i]
+			if !got.Timestamp.Equal(want.Timestamp) || got.Operation != want.Operation || got.ObjectKey != want.ObjectKey ||
+				got.TTFB != want.TTFB || got.TTLB != want.TTLB || got.BytesDownloaded != want.BytesDownloaded ||
+				got.BytesUploaded != want.BytesUploaded || got.Error != want.Error || got.Warmup != want.Warmup ||
+				got.DNS != want.DNS || got.Connect != want.Connect || got.TLSHandshake != want.TLSHandshake || got.FirstByte != want.FirstByte ||
+				got.RunID != want.RunID || got.RequestID != want.RequestID || got.HostID != want.HostID || got.StatusCode != want.StatusCode {
+				t.Errorf("%s: result %d = %+v, want %+v", format, i, got, want)
+			}
+		}
+		if format != FormatCSV {
+			if loaded[2].scheduleDelay() != 3*time.Millisecond {
+				t.Errorf("%s: JSON fields not restored: %+v", format, loaded[1:3])
+			}
+		}
+	}
+}
+
+func TestCalculateFromResults(t *testing.T) {
+	s := NewStats()
+	s.CalculateFromResults(reportResults())
+	if s.TotalRequests != 3 || s.TotalErrors != 1 || s.TotalGets != 2 || s.TotalPuts != 1 {
+		t.Errorf("counts: requests %d, errors %d, gets %d, puts %d", s.TotalRequests, s.TotalErrors, s.TotalGets, s.TotalPuts)
+	}
+	// From the first measured request to the end of the slowest last one
+	if want := time.Second + 9*time.Millisecond; s.actualDuration != want {
+		t.Errorf("duration = %v, want %v", s.actualDuration, want)
+	}
+}
+
+func TestLoadResultsRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.csv")
+	if err := WriteResults(nil, path, FormatSQL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadResults(path, FormatCSV); err == nil {
+		t.Error("expected an error for a file that is not a results CSV")
+	}
+	if _, err := LoadResults(path, FormatSQL); err == nil {
+		t.Error("expected an error for the sql format")
+	}
+}
@@ -0,0 +1,114 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// SDK retry modes (-retry-mode).
+const (
+	RetryModeStandard = "standard" // Exponential backoff with jitter, the SDK default
+	RetryModeAdaptive = "adaptive" // Standard, plus client-side rate limiting when throttled
+	RetryModeOff      = "off"      // Every request is sent exactly once
+)
+
+// retryConfigured reports whether any retry setting differs from the SDK defaults.
+func (c *Config) retryConfigured() bool {
+	return c.RetryMode != "" || c.RetryMaxAttempts > 0 || c.RetryMaxBackoff != ""
+}
+
+// validateRetry checks the retry settings. Called from Validate.
+func (c *Config) validateRetry() error {
+	switch c.RetryMode {
+	case "", RetryModeStandard, RetryModeAdaptive, RetryModeOff:
+	default:
+		return fmt.Errorf("invalid retry mode (-retry-mode) %q: must be '%s', '%s' or '%s'", c.RetryMode, RetryModeStandard, RetryModeAdaptive, RetryModeOff)
+	}
+	if c.RetryMaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts (-retry-max-attempts) must not be negative")
+	}
+	if c.RetryMode == RetryModeOff && c.RetryMaxAttempts > 1 {
+		return fmt.Errorf("retry max attempts (-retry-max-attempts) cannot be set with retries turned off (-retry-mode off)")
+	}
+	if c.RetryMaxBackoff != "" {
+		if d, err := time.ParseDuration(c.RetryMaxBackoff); err != nil || d <= 0 {
+			return fmt.Errorf("invalid retry max backoff (-retry-max-backoff) %q: must be a positive duration", c.RetryMaxBackoff)
+		}
+	}
+	return nil
+}
+
+// newRetryer returns the SDK retryer for the retry settings in cfg.
+func newRetryer(cfg *Config) aws.Retryer {
+	if cfg.RetryMode == RetryModeOff || cfg.RetryMaxAttempts == 1 {
+		return aws.NopRetryer{}
+	}
+	standard := func(o *retry.StandardOptions) {
+		if cfg.RetryMaxAttempts > 0 {
+			o.MaxAttempts = cfg.RetryMaxAttempts
+		}
+		if cfg.RetryMaxBackoff != "" {
+			o.MaxBackoff, _ = time.ParseDuration(cfg.RetryMaxBackoff) // Checked by Validate
+		}
+	}
+	if cfg.RetryMode == RetryModeAdaptive {
+		return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+			o.StandardOptions = append(o.StandardOptions, standard)
+		})
+	}
+	return retry.NewStandard(standard)
+}
+
+// RetryReport summarizes the SDK retries of a run.
+type RetryReport struct {
+	Requests        int64 `json:"requests" yaml:"requests"`               // Requests with a known attempt count
+	RetriedRequests int64 `json:"retriedRequests" yaml:"retriedRequests"` // Requests that needed more than one attempt
+	Retries         int64 `json:"retries" yaml:"retries"`                 // Attempts beyond the first, over all requests
+	FailedRetried   int64 `json:"failedAfterRetries" yaml:"failedAfterRetries"`
+}
+
+// Retries returns the number of attempts the SDK made for r beyond the first.
+func (r Result) Retries() int {
+	return max(r.Attempts-1, 0)
+}
+
+// addRetryResult counts the SDK attempts of r. Called from AddResult.
+func (s *Stats) addRetryResult(r Result) {
+	if r.Attempts == 0 {
+		return // Not sent through the SDK, e.g. presigned requests
+	}
+	s.retries.Requests++
+	if n := r.Retries(); n > 0 {
+		s.retries.RetriedRequests++
+		s.retries.Retries += int64(n)
+		if r.Error != "" {
+			s.retries.FailedRetried++
+		}
+	}
+}
+
+// Retries returns the retry accounting of the run, or nil if no attempt counts were recorded.
+func (s *Stats) Retries() *RetryReport {
+	if s.retries.Requests == 0 {
+		return nil
+	}
+	report := s.retries
+	return &report
+}
+
+// printRetrySummary prints the retry accounting as part of PrintSummary. Successful retries
+// hide server errors behind higher latency, so they are reported even when nothing failed.
+func (s *Stats) printRetrySummary(w io.Writer) {
+	rr := s.Retries()
+	if rr == nil || rr.RetriedRequests == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nSDK Retries:\n")
+	fmt.Fprintf(w, "  Retried:        %d of %d requests (%.2f%%)\n", rr.RetriedRequests, rr.Requests, 100*float64(rr.RetriedRequests)/float64(rr.Requests))
+	fmt.Fprintf(w, "  Extra Attempts: %d\n", rr.Retries)
+	fmt.Fprintf(w, "  Still Failed:   %d (after retrying)\n", rr.FailedRetried)
+}
@@ -0,0 +1,65 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+func TestNewRetryer(t *testing.T) {
+	if _, ok := newRetryer(&Config{RetryMode: RetryModeOff}).(aws.NopRetryer); !ok {
+		t.Error("Expected no retries with -retry-mode off")
+	}
+	if r := newRetryer(&Config{RetryMaxAttempts: 1}); r.MaxAttempts() != 1 {
+		t.Errorf("Expected a single attempt, got %d", r.MaxAttempts())
+	}
+	if r := newRetryer(&Config{RetryMaxAttempts: 7, RetryMaxBackoff: "1s"}); r.MaxAttempts() != 7 {
+		t.Errorf("Expected 7 attempts, got %d", r.MaxAttempts())
+	}
+	if _, ok := newRetryer(&Config{RetryMode: RetryModeAdaptive}).(*retry.AdaptiveMode); !ok {
+		t.Error("Expected the adaptive retryer")
+	}
+}
+
+func TestValidateRetry(t *testing.T) {
+	tests := []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{Config{}, false},
+		{Config{RetryMode: RetryModeAdaptive, RetryMaxAttempts: 5, RetryMaxBackoff: "2s"}, false},
+		{Config{RetryMode: "sometimes"}, true},
+		{Config{RetryMaxAttempts: -1}, true},
+		{Config{RetryMode: RetryModeOff, RetryMaxAttempts: 3}, true},
+		{Config{RetryMaxBackoff: "soon"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validateRetry(); (err != nil) != tt.wantErr {
+			t.Errorf("validateRetry(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRetryReport(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond, Attempts: 1})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: 900 * time.Millisecond, Attempts: 3})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: -1, Attempts: 3, Error: "StatusCode: 503"})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond}) // Attempts unknown
+	stats.Calculate(now, now.Add(time.Second))
+
+	rr := stats.Retries()
+	if rr == nil || rr.Requests != 3 || rr.RetriedRequests != 2 || rr.Retries != 4 || rr.FailedRetried != 1 {
+		t.Fatalf("Unexpected retry report: %+v", rr)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Retried:        2 of 3 requests") {
+		t.Errorf("Summary lacks the retry section:\n%s", buf.String())
+	}
+}
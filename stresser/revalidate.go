@@ -0,0 +1,154 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Outcomes of a conditional GET in 'revalidate' mode, stored in Result.Conditional.
+const (
+	ConditionalNotModified = "not-modified" // 304, the cached copy is still valid
+	ConditionalModified    = "modified"     // 200 with the full object
+)
+
+// staleETag is sent as If-None-Match by revalidations that play a client holding an outdated copy.
+const staleETag = `"ostresser-stale"`
+
+// performRevalidateOperation models a web cache revalidating an object: a HEAD, then a GET
+// conditional on the ETag and Last-Modified the HEAD returned (If-None-Match and
+// If-Modified-Since). An unchanged object answers 304 without a body. With stale, the
+// validators are those of an older copy, so the full object comes back.
+// It returns both results; ok is false if the HEAD failed and no GET was sent.
+func performRevalidateOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string, stale, verify bool) (head, get Result, ok bool) {
+	head, resp, _ := headObject(ctx, s3Client, bucket, key)
+	if resp == nil {
+		return head, Result{}, false
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		IfNoneMatch:     resp.ETag,
+		IfModifiedSince: resp.LastModified,
+	}
+	if stale {
+		input.IfNoneMatch = aws.String(staleETag)
+		if resp.LastModified != nil {
+			input.IfModifiedSince = aws.Time(resp.LastModified.Add(-time.Hour))
+		}
+	}
+	return head, performConditionalGet(ctx, s3Client, input, verify), true
+}
+
+// performConditionalGet sends a GET with conditional headers. A 304 is a success with
+// no body, its latency measured until the response headers arrived.
+func performConditionalGet(ctx context.Context, s3Client S3ClientAPI, input *s3.GetObjectInput, verify bool) Result {
+	attempt := sendGet(ctx, s3Client, input)
+	if isNotModified(attempt.err) {
+		result := attempt.result
+		result.Error = ""
+		result.TTFB = attempt.headersAt.Sub(attempt.start)
+		result.TTLB = result.TTFB
+		result.Conditional = ConditionalNotModified
+		return result
+	}
+	result := attempt.finish(attempt.start, verify)
+	if result.Error == "" {
+		result.Conditional = ConditionalModified
+	}
+	return result
+}
+
+// isNotModified reports whether err is a 304 Not Modified response, which the SDK returns as an error.
+func isNotModified(err error) bool {
+	var respErr *awshttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotModified
+}
+
+// RevalidationReport summarizes the conditional GETs of 'revalidate' mode. The HEADs and
+// GETs are also counted in the HEAD and GET figures.
+type RevalidationReport struct {
+	Requests        int64           `json:"requests" yaml:"requests"`
+	NotModified     int64           `json:"notModified" yaml:"notModified"`
+	Modified        int64           `json:"modified" yaml:"modified"`
+	NotModifiedPct  float64         `json:"notModifiedPct" yaml:"notModifiedPct"`
+	NotModifiedTTLB *LatencySummary `json:"notModifiedTtlbMs,omitempty" yaml:"notModifiedTtlbMs,omitempty"`
+	ModifiedTTLB    *LatencySummary `json:"modifiedTtlbMs,omitempty" yaml:"modifiedTtlbMs,omitempty"`
+}
+
+// revalidationStats holds the latencies of successful conditional GETs by outcome.
+type revalidationStats struct {
+	notModified *Histogram
+	modified    *Histogram
+}
+
+// addRevalidationResult records a successful conditional GET. Called from AddResult.
+func (s *Stats) addRevalidationResult(r Result) {
+	if r.Operation != "GET" || r.Conditional == "" {
+		return
+	}
+	if s.revalidation == nil {
+		s.revalidation = &revalidationStats{notModified: NewHistogram(), modified: NewHistogram()}
+	}
+	if r.Conditional == ConditionalNotModified {
+		s.revalidation.notModified.Record(r.TTLB)
+	} else {
+		s.revalidation.modified.Record(r.TTLB)
+	}
+}
+
+// Revalidation returns the conditional GET figures, or nil if none succeeded.
+func (s *Stats) Revalidation() *RevalidationReport {
+	if s.revalidation == nil {
+		return nil
+	}
+	nm, m := s.revalidation.notModified, s.revalidation.modified
+	rr := &RevalidationReport{
+		NotModified:     nm.Count(),
+		Modified:        m.Count(),
+		Requests:        nm.Count() + m.Count(),
+		NotModifiedTTLB: histogramSummary(nm),
+		ModifiedTTLB:    histogramSummary(m),
+	}
+	rr.NotModifiedPct = percentOf(rr.NotModified, rr.Requests)
+	return rr
+}
+
+// histogramSummary returns the latency summary of h, or nil if it is empty.
+func histogramSummary(h *Histogram) *LatencySummary {
+	if h.Count() == 0 {
+		return nil
+	}
+	return &LatencySummary{ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Max()), ms(h.Percentile(99.9))}
+}
+
+// printRevalidationSummary prints the 304 rate and latencies by outcome as part of PrintSummary.
+func (s *Stats) printRevalidationSummary(w io.Writer) {
+	rr := s.Revalidation()
+	if rr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nRevalidation (HEAD + conditional GET):\n")
+	fmt.Fprintf(w, "  Conditional GETs: %d (304 Not Modified: %d = %.2f%%, 200 Modified: %d)\n",
+		rr.Requests, rr.NotModified, rr.NotModifiedPct, rr.Modified)
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	for _, row := range []struct {
+		label string
+		l     *LatencySummary
+	}{{"304 (headers)", rr.NotModifiedTTLB}, {"200 (TTLB)", rr.ModifiedTTLB}} {
+		if row.l == nil {
+			continue
+		}
+		fmt.Fprintf(w, "  %-13s |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			row.label, row.l.Min, row.l.Avg, row.l.P50, row.l.P90, row.l.P99, row.l.P999, row.l.Max)
+	}
+}
@@ -0,0 +1,80 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// etagS3Client serves one unchanging object and honors If-None-Match like S3.
+type etagS3Client struct {
+	stubS3Client
+	etag string
+	body []byte
+}
+
+func (c *etagS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{ETag: aws.String(c.etag), LastModified: aws.Time(time.Now().Add(-time.Minute))}, nil
+}
+
+func (c *etagS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if aws.ToString(params.IfNoneMatch) == c.etag {
+		resp := &http.Response{StatusCode: http.StatusNotModified, Header: http.Header{}}
+		return nil, &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{Response: &smithyhttp.Response{Response: resp}, Err: errors.New("not modified")},
+		}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(c.body))}, nil
+}
+
+func TestRevalidate(t *testing.T) {
+	client := &etagS3Client{etag: `"abc"`, body: []byte(strings.Repeat("x", 4096))}
+	ctx := context.Background()
+	stats := NewStats()
+
+	for i, stale := range []bool{false, false, false, true} {
+		head, get, ok := performRevalidateOperation(ctx, client, "bucket", "key", stale, false)
+		if !ok || head.Operation != "HEAD" || head.Error != "" {
+			t.Fatalf("Revalidation %d: unexpected HEAD %+v", i, head)
+		}
+		if get.Operation != "GET" || get.Error != "" {
+			t.Fatalf("Revalidation %d: unexpected GET %+v", i, get)
+		}
+		switch {
+		case !stale && (get.Conditional != ConditionalNotModified || get.BytesDownloaded != 0):
+			t.Errorf("Expected a 304 without body, got %+v", get)
+		case stale && (get.Conditional != ConditionalModified || get.BytesDownloaded != 4096):
+			t.Errorf("Expected the full object for a stale copy, got %+v", get)
+		}
+		stats.AddResult(head)
+		stats.AddResult(get)
+	}
+	stats.Calculate(time.Now().Add(-time.Second), time.Now())
+
+	rr := stats.Revalidation()
+	if rr == nil || rr.Requests != 4 || rr.NotModified != 3 || rr.Modified != 1 || rr.NotModifiedPct != 75 {
+		t.Fatalf("Unexpected revalidation report: %+v", rr)
+	}
+	if rr.NotModifiedTTLB == nil || rr.ModifiedTTLB == nil {
+		t.Error("Expected latencies for both outcomes")
+	}
+	if stats.TotalErrors != 0 || stats.TotalHeads != 4 || stats.TotalGets != 4 {
+		t.Errorf("304s must not count as errors: %d errors, %d HEADs, %d GETs", stats.TotalErrors, stats.TotalHeads, stats.TotalGets)
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "304 Not Modified: 3 = 75.00%") {
+		t.Errorf("Summary is missing the revalidation section:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,60 @@
+package stresser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// Runner runs a stress test from another Go program. Unlike the command line tool it writes
+// nothing to standard output or standard error by itself: results are streamed to OnResult,
+// interim stats go to Progress if set, and log messages go to the default slog logger.
+//
+//	runner, err := stresser.New(cfg)
+//	if err != nil { ... }
+//	runner.OnResult = func(r stresser.Result) { ... }
+//	results, stats, err := runner.Run(ctx)
+//
+// With Config.Checkpoint set, Run writes the detailed results to Config.OutputFile as they
+// are collected and returns none.
+type Runner struct {
+	cfg *Config
+
+	// OnResult, if set, is called with every completed operation as soon as it is collected,
+	// warm-up results included (flagged with Warmup). Calls come from a single goroutine; a
+	// slow callback holds up the workers once the results buffer is full.
+	OnResult func(Result)
+
+	// Progress receives the interim stats lines, or the dashboard with Config.TUI, while the
+	// test runs. Nil disables both.
+	Progress io.Writer
+
+	// InterimSummary, if set, makes the runner print the summary of the results so far to
+	// Progress whenever a value is received, without stopping the test.
+	InterimSummary <-chan struct{}
+}
+
+// New validates cfg and returns a Runner for it, generating Config.RunID if it is empty.
+// Distributed runs (Config.Agents) are driven by RunDistributed instead.
+func New(cfg *Config) (*Runner, error) {
+	if cfg.Agents != "" {
+		return nil, errors.New("distributed runs (Agents) are not supported by Runner, use RunDistributed")
+	}
+	if cfg.RunID == "" {
+		cfg.RunID = NewRunID()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Runner{cfg: cfg}, nil
+}
+
+// RunStressTest runs the test described by an already validated cfg, printing interim stats
+// to standard error as the command line tool does, and an interim summary on SIGUSR1.
+func RunStressTest(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
+	interim, stop := notifyInterimSummary()
+	defer stop()
+	r := &Runner{cfg: cfg, Progress: os.Stderr, InterimSummary: interim}
+	return r.Run(ctx)
+}
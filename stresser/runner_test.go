@@ -0,0 +1,57 @@
+package stresser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunnerStreamsResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	cfg := &Config{
+		Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret",
+		Duration: "200ms", Concurrency: 2, OutputFile: "unused.csv", OperationType: "write", PutObjectSizeKB: 1,
+		Progress: "10ms",
+	}
+	runner, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var streamed int
+	runner.OnResult = func(r Result) {
+		streamed++
+		if r.Operation != "PUT" {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+	results, stats, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) == 0 || streamed != len(results) {
+		t.Errorf("%d results returned, %d streamed", len(results), streamed)
+	}
+	if stats.TotalPuts != int64(len(results)) {
+		t.Errorf("stats count %d PUTs, want %d", stats.TotalPuts, len(results))
+	}
+	if cfg.RunID == "" || results[0].RunID != cfg.RunID {
+		t.Errorf("expected New to generate the run id of the results, got %q and %q", cfg.RunID, results[0].RunID)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(&Config{}); err == nil {
+		t.Error("expected an error for an empty config")
+	}
+	if _, err := New(&Config{Agents: "host1"}); err == nil {
+		t.Error("expected an error for a distributed config")
+	}
+}
@@ -2,15 +2,17 @@ package stresser
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
 )
 
 // S3ClientAPI defines the interface for the S3 operations we need.
@@ -18,21 +20,88 @@ import (
 type S3ClientAPI interface {
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
-	// Add other S3 operations here if needed (e.g., DeleteObject, HeadObject)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	// Add other S3 operations here if needed (e.g., DeleteObject)
+}
+
+// applyConnectionPool sets the connection pool limits of cfg on transport; zero values keep
+// the Go defaults.
+func applyConnectionPool(transport *http.Transport, cfg *Config) {
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout != "" {
+		transport.IdleConnTimeout, _ = time.ParseDuration(cfg.IdleConnTimeout) // Checked by Validate
+	}
+	idlePerHost := transport.MaxIdleConnsPerHost
+	if idlePerHost == 0 {
+		idlePerHost = http.DefaultMaxIdleConnsPerHost
+	}
+	if idlePerHost < cfg.Concurrency && !cfg.ClientPerWorker {
+		slog.Info("Fewer idle connections are kept per host than there are workers, so connections may be closed and reopened between requests; raise -max-idle-conns-per-host to reuse them",
+			"maxIdleConnsPerHost", idlePerHost, "concurrency", cfg.Concurrency)
+	}
+	slog.Debug("HTTP connection pool", "maxIdleConns", transport.MaxIdleConns, "maxIdleConnsPerHost", idlePerHost,
+		"maxConnsPerHost", transport.MaxConnsPerHost, "idleConnTimeout", transport.IdleConnTimeout)
 }
 
 // NewS3Client creates a new S3 client configured according to the application config.
 func NewS3Client(ctx context.Context, cfg *Config) (*s3.Client, error) {
 
 	// --- Custom HTTP Client Setup ---
+	// Clone default transport to avoid modifying global state
+	customTransport := http.DefaultTransport.(*http.Transport).Clone()
+	// Count dialed connections so client-side bottlenecks can be reported
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	dial := dialFunc(dialer.DialContext)
+	if cfg.DNSRefresh != "" {
+		refresh, _ := time.ParseDuration(cfg.DNSRefresh) // Checked by Validate
+		dial = newDNSCache(refresh).dialContext(dial)
+		slog.Info("Re-resolving endpoint DNS periodically, spreading connections over all addresses", "interval", refresh)
+	}
+	if cfg.IPFamily != "" && cfg.IPFamily != IPFamilyBoth {
+		dial = familyDialContext(cfg.IPFamily, dial)
+		slog.Info("Restricting connections to one IP family", "family", cfg.IPFamily)
+	}
+	if cfg.BandwidthLimit > 0 {
+		dial = throttledDialContext(newBandwidthLimiter(cfg.BandwidthLimit), dial)
+		slog.Info("Bandwidth limit enabled, shared by all connections", "MiBps", cfg.BandwidthLimit)
+	}
+	customTransport.DialContext = trackingDialContext(dial)
+	applyConnectionPool(customTransport, cfg)
+	// Without keep-alive every request pays the full TCP and TLS setup
+	if cfg.DisableKeepAlive {
+		customTransport.DisableKeepAlives = true
+		slog.Info("HTTP keep-alive disabled, opening a new connection for every request")
+	}
+	if cfg.HTTPVersion != "" && cfg.HTTPVersion != HTTPVersionAuto {
+		applyHTTPVersion(customTransport, cfg.HTTPVersion)
+		slog.Info("Restricting the HTTP protocol version", "version", cfg.HTTPVersion)
+	}
 	// Allows for options like disabling TLS verification (use cautiously!)
-	httpClient := &http.Client{}
-	if cfg.InsecureSkipVerify {
-		slog.Warn("Disabling TLS certificate verification for S3 client")
-		// Clone default transport to avoid modifying global state
-		customTransport := http.DefaultTransport.(*http.Transport).Clone()
-		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		httpClient.Transport = customTransport
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		customTransport.TLSClientConfig = tlsConfig
+		if cfg.InsecureSkipVerify {
+			slog.Warn("Disabling TLS certificate verification for S3 client")
+		}
+		slog.Info("Using custom TLS settings", "caFile", cfg.TLSCAFile, "clientCert", cfg.TLSCertFile, "minVersion", cfg.TLSMinVersion)
+	}
+	httpClient := &http.Client{Transport: customTransport}
+	if cfg.ConnMaxRequests > 0 || cfg.ConnMaxAge != "" {
+		maxAge, _ := time.ParseDuration(cfg.ConnMaxAge) // Checked by Validate
+		httpClient.Transport = &recyclingTransport{base: customTransport, maxRequests: int64(cfg.ConnMaxRequests), maxAge: maxAge}
+		slog.Info("Connection recycling enabled", "maxRequests", cfg.ConnMaxRequests, "maxAge", maxAge)
 	}
 
 	// --- AWS SDK Configuration Options ---
@@ -57,10 +126,19 @@ func NewS3Client(ctx context.Context, cfg *Config) (*s3.Client, error) {
 	// 3. Custom HTTP Client
 	sdkOpts = append(sdkOpts, config.WithHTTPClient(httpClient))
 
-	// 4. Credentials Provider
+	// 4. Retry policy, the SDK defaults unless configured
+	if cfg.retryConfigured() {
+		sdkOpts = append(sdkOpts, config.WithRetryer(func() aws.Retryer { return newRetryer(cfg) }))
+		slog.Info("Using custom retry policy", "mode", cfg.RetryMode, "maxAttempts", cfg.RetryMaxAttempts, "maxBackoff", cfg.RetryMaxBackoff)
+	}
+
+	// 5. Credentials Provider
 	// Use static credentials ONLY if both key and secret are provided in config.
 	// Otherwise, let the SDK's default credential chain handle it (env vars, shared config, IAM role).
-	if cfg.AccessKey != "" && cfg.SecretKey != "" {
+	if cfg.Signing == SigningNone {
+		sdkOpts = append(sdkOpts, config.WithCredentialsProvider(aws.AnonymousCredentials{}))
+		slog.Info("Sending unsigned, anonymous requests")
+	} else if cfg.AccessKey != "" && cfg.SecretKey != "" {
 		staticProvider := credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, "")
 		sdkOpts = append(sdkOpts, config.WithCredentialsProvider(staticProvider))
 		slog.Info("Using static credentials provided in configuration")
@@ -74,15 +152,51 @@ func NewS3Client(ctx context.Context, cfg *Config) (*s3.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS SDK config: %w", err)
 	}
+	if cfg.RoleARN != "" {
+		awsCfg.Credentials = assumeRoleCredentials(awsCfg, cfg)
+		slog.Info("Assuming role, refreshing its credentials before they expire", "role", cfg.RoleARN)
+	}
 
 	// --- Create S3 Client ---
 	// UsePathStyle is often required for S3-compatible storage like MinIO or Ceph.
 	// It might need to be configurable depending on the target system.
+	ua := cfg.UserAgentString()
+	apiOptions := []func(*middleware.Stack) error{WithRequestHook("StresserUserAgent", userAgentHook(ua))}
+	if len(cfg.Headers) > 0 {
+		hook, err := staticHeaders(cfg.Headers)
+		if err != nil {
+			return nil, err
+		}
+		apiOptions = append(apiOptions, WithRequestHook("StresserStaticHeaders", hook))
+		slog.Info("Adding custom headers to every request", "headers", cfg.Headers)
+	}
+	if cfg.SSE != "" && cfg.SSE != SSENone {
+		sse, err := withServerSideEncryption(cfg)
+		if err != nil {
+			return nil, err
+		}
+		apiOptions = append(apiOptions, sse)
+		slog.Info("Encrypting written objects server-side", "mode", cfg.SSE, "kmsKeyId", cfg.SSEKMSKeyID)
+	}
+	if algorithm, ok := checksumAlgorithms[cfg.Checksum]; ok {
+		apiOptions = append(apiOptions, withChecksums(algorithm))
+		slog.Info("Adding checksums to PUTs and checking them on GETs", "algorithm", algorithm)
+	}
+	if cfg.AcceptEncoding != "" {
+		apiOptions = append(apiOptions, withAcceptEncoding(cfg.AcceptEncoding))
+		slog.Info("Requesting compressed GET responses", "acceptEncoding", cfg.AcceptEncoding)
+	}
+	if cfg.Signing == SigningV2 {
+		apiOptions = append(apiOptions, withSigV2(awsCfg.Credentials))
+		slog.Info("Signing requests with legacy signature version 2")
+	}
+	apiOptions = append(apiOptions, cfg.APIOptions...)
 	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.UsePathStyle = true // Force path-style addressing
-		// Consider adding o.RetryMaxAttempts or other retry options if needed
+		checksumOptions(cfg.Checksum, o)
+		o.APIOptions = append(o.APIOptions, apiOptions...)
 	})
-	slog.Info("S3 client created successfully", "endpoint", cfg.Endpoint, "region", cfg.Region, "user", cfg.AccessKey, "bucket", cfg.Bucket)
+	slog.Info("S3 client created successfully", "endpoint", cfg.Endpoint, "region", cfg.Region, "user", cfg.AccessKey, "bucket", cfg.Bucket, "userAgent", ua)
 
 	return s3Client, nil
 }
@@ -2,7 +2,9 @@ package stresser
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 )
 
 // TestNewS3Client_ValidationChecks tests validation checks in NewS3Client
@@ -75,3 +77,18 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestApplyConnectionPool(t *testing.T) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	applyConnectionPool(transport, &Config{Concurrency: 4})
+	if transport.MaxIdleConns != 100 || transport.MaxIdleConnsPerHost != 0 || transport.MaxConnsPerHost != 0 || transport.IdleConnTimeout != 90*time.Second {
+		t.Errorf("zero values changed the Go defaults: %+v", transport)
+	}
+
+	cfg := &Config{Concurrency: 64, MaxIdleConns: 500, MaxIdleConnsPerHost: 64, MaxConnsPerHost: 128, IdleConnTimeout: "15s"}
+	applyConnectionPool(transport, cfg)
+	if transport.MaxIdleConns != 500 || transport.MaxIdleConnsPerHost != 64 || transport.MaxConnsPerHost != 128 || transport.IdleConnTimeout != 15*time.Second {
+		t.Errorf("pool limits not applied: idle %d, idle per host %d, per host %d, timeout %v",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost, transport.IdleConnTimeout)
+	}
+}
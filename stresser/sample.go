@@ -0,0 +1,76 @@
+package stresser
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// resultSampler thins the detailed results of long runs so they fit in memory and on disk.
+// It only decides which raw results are kept; the stats still see every result.
+type resultSampler struct {
+	rate    float64 // Keep each result with this probability (0 keeps all)
+	max     int     // Keep at most this many, a uniform sample of those passing the rate (0: no limit)
+	rand    *rand.Rand
+	offered int64 // Results that passed the rate, the population of the reservoir
+	evicted bool  // A kept result was replaced, so the kept results are out of order
+}
+
+// newResultSampler returns the sampler configured by cfg, or nil if it keeps every result.
+func newResultSampler(cfg *Config) *resultSampler {
+	if cfg.SampleRate == 0 && cfg.SampleMax == 0 {
+		return nil
+	}
+	return &resultSampler{rate: cfg.SampleRate, max: cfg.SampleMax, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// sampled reports whether r passes the sample rate. Nil samplers keep everything.
+func (s *resultSampler) sampled() bool {
+	return s == nil || s.rate == 0 || s.rand.Float64() < s.rate
+}
+
+// add offers r to the kept results and returns them. Once max results are kept, r replaces
+// a random one with the probability that keeps the sample uniform (reservoir sampling).
+func (s *resultSampler) add(results []Result, r Result) []Result {
+	if !s.sampled() {
+		return results
+	}
+	if s == nil || s.max == 0 {
+		return append(results, r)
+	}
+	s.offered++
+	if len(results) < s.max {
+		return append(results, r)
+	}
+	if i := s.rand.Int63n(s.offered); i < int64(s.max) {
+		results[i] = r
+		s.evicted = true
+	}
+	return results
+}
+
+// finish returns the kept results in the order they were collected.
+func (s *resultSampler) finish(results []Result) []Result {
+	if s != nil && s.evicted {
+		slices.SortStableFunc(results, func(a, b Result) int { return a.Timestamp.Compare(b.Timestamp) })
+	}
+	return results
+}
+
+// validateSampling checks -sample-rate and -sample-max.
+func (c *Config) validateSampling() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("sample rate (-sample-rate) must be in the range [0, 1], got %v", c.SampleRate)
+	}
+	if c.SampleMax < 0 {
+		return fmt.Errorf("sample size (-sample-max) must not be negative")
+	}
+	if (c.SampleRate > 0 || c.SampleMax > 0) && c.Agents != "" {
+		return fmt.Errorf("sampling (-sample-rate, -sample-max) is not supported with -agents, whose stats are calculated from all results")
+	}
+	if c.SampleMax > 0 && c.Checkpoint != "" {
+		return fmt.Errorf("a bounded sample (-sample-max) can't be streamed with -checkpoint, which writes results before the sample is final; use -sample-rate")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package stresser
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestResultSampler(t *testing.T) {
+	start := time.Now()
+	results := make([]Result, 10000)
+	for i := range results {
+		results[i] = Result{Timestamp: start.Add(time.Duration(i) * time.Millisecond), Operation: "GET"}
+	}
+
+	// Without sampling every result is kept
+	var none *resultSampler
+	var kept []Result
+	for _, r := range results {
+		kept = none.add(kept, r)
+	}
+	if len(none.finish(kept)) != len(results) || newResultSampler(&Config{}) != nil {
+		t.Fatalf("Expected every result to be kept, got %d", len(kept))
+	}
+
+	rate := &resultSampler{rate: 0.1, rand: rand.New(rand.NewSource(1))}
+	kept = nil
+	for _, r := range results {
+		kept = rate.add(kept, r)
+	}
+	if len(kept) < 800 || len(kept) > 1200 {
+		t.Errorf("Expected about 10%% of the results, got %d", len(kept))
+	}
+
+	reservoir := &resultSampler{max: 100, rand: rand.New(rand.NewSource(1))}
+	kept = nil
+	for _, r := range results {
+		kept = reservoir.add(kept, r)
+	}
+	kept = reservoir.finish(kept)
+	if len(kept) != 100 {
+		t.Fatalf("Expected the reservoir to hold 100 results, got %d", len(kept))
+	}
+	late := 0
+	for i, r := range kept {
+		if i > 0 && r.Timestamp.Before(kept[i-1].Timestamp) {
+			t.Fatal("Expected the sample in time order")
+		}
+		if r.Timestamp.Sub(start) >= 5*time.Second {
+			late++
+		}
+	}
+	// A uniform sample draws about half from the second half of the run
+	if late < 30 || late > 70 {
+		t.Errorf("Expected a uniform sample of the run, got %d of 100 from its second half", late)
+	}
+}
+
+func TestValidateSampling(t *testing.T) {
+	base := Config{Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "read", SampleRate: 0.01, SampleMax: 1000}
+
+	cfg := base
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for name, mutate := range map[string]func(*Config){
+		"rate above 1":       func(c *Config) { c.SampleRate = 1.5 },
+		"negative max":       func(c *Config) { c.SampleMax = -1 },
+		"agents":             func(c *Config) { c.Agents = "host1" },
+		"reservoir streamed": func(c *Config) { c.Checkpoint = "10s" },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+// TestStatsMemoryFlat feeds the stats the results of a long run and checks that their memory
+// does not grow with the number of requests, so sampling the detailed output bounds the run.
+func TestStatsMemoryFlat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("soak test")
+	}
+	stats := NewStats()
+	stats.PrefixDepth = 1
+	stats.OutlierCount = 10
+	start := time.Now()
+	n := 0
+	feed := func(count int) {
+		for i := 0; i < count; i++ {
+			n++
+			r := Result{
+				Timestamp: start.Add(time.Duration(n%10000) * time.Millisecond), // Ten seconds of time series
+				Operation: "GET", ObjectKey: fmt.Sprintf("p%d/%d", n%4, n),
+				TTFB: time.Duration(n%5000) * time.Microsecond, TTLB: time.Duration(n%9000+1) * time.Microsecond,
+				BytesDownloaded: 4096, ConnWait: time.Duration(n%300) * time.Microsecond, ConnReused: n%10 != 0,
+				RemoteAddr: "192.0.2.1:443", Protocol: "HTTP/1.1", Windows: 2, MinWindowMiBps: float64(n%100) + 0.5,
+			}
+			if n%50 == 0 {
+				r.Operation = "PUT"
+				r.BytesDownloaded, r.BytesUploaded = 0, 8192
+			}
+			stats.AddResult(r)
+		}
+	}
+	heap := func() uint64 {
+		runtime.GC()
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+
+	feed(100000) // Let every histogram grow to its range
+	before := heap()
+	feed(1000000)
+	after := heap()
+	stats.Calculate(start, start.Add(time.Hour))
+	if stats.TotalRequests != int64(n) {
+		t.Fatalf("Expected %d requests, got %d", n, stats.TotalRequests)
+	}
+	// Keeping even one 8 byte value per request would add 8 MB
+	if after > before && after-before > 1<<20 {
+		t.Errorf("Stats grew by %d bytes over %d requests", after-before, 1000000)
+	}
+}
@@ -0,0 +1,100 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// scanPageSize is the number of keys requested per ListObjectsV2 call by scans.
+const scanPageSize = 1000
+
+// ScanOptions selects what 'ostresser scan' lists into a manifest.
+type ScanOptions struct {
+	Prefix   string        // List only the keys under this prefix (default: the whole bucket)
+	Limit    int           // Stop after this many keys (0: no limit)
+	MinSize  int64         // Leave out objects smaller than this many bytes, e.g. 1 to skip empty objects
+	Progress time.Duration // Log progress this often (0 disables)
+}
+
+// ScanReport summarizes a scan.
+type ScanReport struct {
+	Objects  int64 // Keys written to the manifest
+	Bytes    int64 // Total size of those objects
+	Skipped  int64 // Keys left out: directory markers and objects smaller than MinSize
+	Duration time.Duration
+}
+
+// RunScan lists the objects of the bucket, or of a prefix, and writes them to the manifest
+// at cfg.ManifestPath, so read runs can use an existing dataset. The manifest is written in
+// the format of its extension: .csv and .json/.jsonl manifests also get the size and ETag
+// of every object, for reads to validate.
+func RunScan(ctx context.Context, cfg *Config, opts ScanOptions) (ScanReport, error) {
+	client, closeClient, err := newBucketClient(ctx, cfg)
+	if err != nil {
+		return ScanReport{}, err
+	}
+	defer closeClient()
+
+	mw, err := NewManifestWriter(cfg.ManifestPath)
+	if err != nil {
+		return ScanReport{}, err
+	}
+	slog.Info("Listing objects into the manifest", "bucket", cfg.Bucket, "prefix", opts.Prefix, "path", cfg.ManifestPath,
+		"format", manifestFormat(cfg.ManifestPath))
+	report, err := scan(ctx, client, cfg.Bucket, mw, opts)
+	if cerr := mw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && report.Objects == 0 {
+		err = fmt.Errorf("no objects found in bucket %s under prefix %q", cfg.Bucket, opts.Prefix)
+	}
+	return report, err
+}
+
+// scan pages through the keys under opts.Prefix and adds them to mw. Keys ending in a
+// slash are directory markers created by consoles and file gateways and are left out.
+func scan(ctx context.Context, client cleanupClient, bucket string, mw *ManifestWriter, opts ScanOptions) (ScanReport, error) {
+	start := time.Now()
+	var report ScanReport
+	nextProgress := start.Add(opts.Progress)
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(opts.Prefix), MaxKeys: aws.Int32(scanPageSize)}
+	for {
+		resp, err := client.ListObjectsV2(ctx, input)
+		if err != nil {
+			report.Duration = time.Since(start)
+			return report, fmt.Errorf("failed to list keys under %q: %w", opts.Prefix, err)
+		}
+		for _, o := range resp.Contents {
+			key, size := aws.ToString(o.Key), aws.ToInt64(o.Size)
+			if strings.HasSuffix(key, "/") || size < opts.MinSize {
+				report.Skipped++
+				continue
+			}
+			if err := mw.AddEntry(ManifestEntry{Key: key, Size: size, ETag: aws.ToString(o.ETag)}); err != nil {
+				report.Duration = time.Since(start)
+				return report, err
+			}
+			report.Objects++
+			report.Bytes += size
+			if opts.Limit > 0 && report.Objects >= int64(opts.Limit) {
+				report.Duration = time.Since(start)
+				return report, nil
+			}
+		}
+		if opts.Progress > 0 && time.Now().After(nextProgress) {
+			slog.Info("Scan progress", "objects", report.Objects, "bytes", report.Bytes, "skipped", report.Skipped)
+			nextProgress = time.Now().Add(opts.Progress)
+		}
+		if !aws.ToBool(resp.IsTruncated) || ctx.Err() != nil {
+			report.Duration = time.Since(start)
+			return report, ctx.Err()
+		}
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
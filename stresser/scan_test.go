@@ -0,0 +1,139 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// sizedS3Client lists the keys of a deletingS3Client with a size and ETag derived from the key.
+type sizedS3Client struct {
+	*deletingS3Client
+	sizes map[string]int64
+}
+
+func (c *sizedS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	out, err := c.deletingS3Client.ListObjectsV2(ctx, params, optFns...)
+	for i, o := range out.Contents {
+		out.Contents[i].Size = aws.Int64(c.sizes[aws.ToString(o.Key)])
+		out.Contents[i].ETag = aws.String(fmt.Sprintf("%q", "etag-"+aws.ToString(o.Key)))
+	}
+	return out, err
+}
+
+func newSizedS3Client(keys []string) *sizedS3Client {
+	c := &sizedS3Client{deletingS3Client: newDeletingS3Client(keys), sizes: make(map[string]int64)}
+	for i, k := range keys {
+		c.sizes[k] = int64(i + 1)
+	}
+	return c
+}
+
+func TestScan(t *testing.T) {
+	// More keys than a listing page holds, plus a directory marker and an empty object
+	keys := cleanupKeys("data/", scanPageSize+500)
+	client := newSizedS3Client(append(keys, "data/sub/", "other/x.dat"))
+	client.sizes["data/sub/"] = 0
+	client.sizes[keys[10]] = 0
+
+	path := filepath.Join(t.TempDir(), "scan.csv")
+	mw, err := NewManifestWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := scan(context.Background(), client, "bucket", mw, ScanOptions{Prefix: "data/", MinSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if report.Objects != int64(len(keys)-1) || report.Skipped != 2 {
+		t.Errorf("Expected %d objects and 2 skipped, got %+v", len(keys)-1, report)
+	}
+
+	entries, err := LoadManifestEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(keys)-1 {
+		t.Fatalf("Expected %d manifest entries, got %d", len(keys)-1, len(entries))
+	}
+	var bytes int64
+	for _, e := range entries {
+		if e.Size != client.sizes[e.Key] || e.ETag != fmt.Sprintf("%q", "etag-"+e.Key) {
+			t.Errorf("Unexpected entry %+v", e)
+		}
+		if e.Key == keys[10] || e.Key == "data/sub/" || e.Key == "other/x.dat" {
+			t.Errorf("Unexpected key %s in the manifest", e.Key)
+		}
+		bytes += e.Size
+	}
+	if report.Bytes != bytes {
+		t.Errorf("Expected %d bytes, reported %d", bytes, report.Bytes)
+	}
+}
+
+func TestScanLimit(t *testing.T) {
+	client := newSizedS3Client(cleanupKeys("data/", 50))
+	path := filepath.Join(t.TempDir(), "scan.txt")
+	mw, err := NewManifestWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := scan(context.Background(), client, "bucket", mw, ScanOptions{Limit: 20})
+	mw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Objects != 20 || len(keys) != 20 || keys[0] != "data/0000.dat" {
+		t.Errorf("Expected the first 20 keys, got %d (%v)", report.Objects, keys)
+	}
+}
+
+func TestManifestWriterFormats(t *testing.T) {
+	want := []ManifestEntry{
+		{Key: "a.dat", Size: 1024, ETag: `"abc"`},
+		{Key: "b,with comma.dat", VersionID: "v1", Weight: 2.5},
+		{Key: "c.dat"},
+	}
+	for _, name := range []string{"m.txt", "m.csv", "m.jsonl"} {
+		path := filepath.Join(t.TempDir(), name)
+		mw, err := NewManifestWriter(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range want {
+			if err := mw.AddEntry(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := mw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadManifestEntries(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d entries, got %+v", name, len(want), got)
+		}
+		for i := range want {
+			expected := want[i]
+			if manifestFormat(path) == FormatText {
+				expected.Size, expected.ETag = 0, "" // Plain manifests carry keys, versions and weights only
+			}
+			if got[i] != expected {
+				t.Errorf("%s: expected %+v, got %+v", name, expected, got[i])
+			}
+		}
+	}
+}
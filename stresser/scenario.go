@@ -0,0 +1,391 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Operations of scenario groups.
+const (
+	GroupRead   = "read"   // GET a key of the group's key space
+	GroupWrite  = "write"  // PUT a new object
+	GroupHead   = "head"   // HEAD a key of the group's key space
+	GroupList   = "list"   // Request the next page of a listing
+	GroupDelete = "delete" // DELETE a key written by a write group
+)
+
+// groupName matches the names of scenario groups, which end up in default keys and reports.
+var groupName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// OperationGroup is one weighted group of operations of a scenario, with its own key space
+// and object sizes.
+type OperationGroup struct {
+	Name             string  `yaml:"name"`
+	Weight           float64 `yaml:"weight"`           // Relative share of the operations, e.g. 70
+	Operation        string  `yaml:"operation"`        // "read", "write", "head", "list" or "delete"
+	Manifest         string  `yaml:"manifest"`         // Keys of read and head groups
+	KeysFrom         string  `yaml:"keysFrom"`         // Instead of Manifest: use the keys this write group has written during the run (read, head and delete groups)
+	KeyTemplate      string  `yaml:"keyTemplate"`      // Keys of write groups (default: stresser/{run}/<name>/worker{worker}/{ts}-{rand}.dat)
+	SizeDistribution string  `yaml:"sizeDistribution"` // Object sizes of write groups, as -putsize-dist (default: -putsize)
+	Prefix           string  `yaml:"prefix"`           // Prefix listed by list groups (default: -list-prefix)
+}
+
+// scenarioFile is the format of the file -scenario names.
+type scenarioFile struct {
+	Groups []OperationGroup `yaml:"groups"`
+}
+
+// LoadScenario reads the operation groups of a scenario file.
+func LoadScenario(path string) ([]OperationGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file %s: %w", path, err)
+	}
+	var f scenarioFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true) // A misspelled field would silently change the workload
+	if err := dec.Decode(&f); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse scenario file %s: %w", path, err)
+	}
+	if len(f.Groups) == 0 {
+		return nil, fmt.Errorf("scenario file %s has no groups", path)
+	}
+	return f.Groups, nil
+}
+
+// defaultKeyTemplate returns the template of the keys a write group writes without a keyTemplate.
+func (g OperationGroup) defaultKeyTemplate() string {
+	return "stresser/{run}/" + g.Name + "/worker{worker}/{ts}-{rand}.dat"
+}
+
+// validateScenario loads the groups of -scenario and checks them. A run with groups is a
+// 'scenario' mode run. Called from Validate before the operation type is checked.
+func (c *Config) validateScenario() error {
+	if c.Scenario != "" {
+		groups, err := LoadScenario(c.Scenario)
+		if err != nil {
+			return fmt.Errorf("%w (-scenario)", err)
+		}
+		c.Groups = groups
+	}
+	if len(c.Groups) == 0 {
+		if c.OperationType == "scenario" {
+			return fmt.Errorf("'scenario' mode requires a scenario file (-scenario) or groups in the config file")
+		}
+		return nil
+	}
+	c.OperationType = "scenario"
+
+	byName := make(map[string]OperationGroup)
+	for _, g := range c.Groups {
+		if !groupName.MatchString(g.Name) {
+			return fmt.Errorf("invalid scenario group name %q: use letters, digits, '.', '_' and '-'", g.Name)
+		}
+		if _, ok := byName[g.Name]; ok {
+			return fmt.Errorf("scenario group %q is defined twice", g.Name)
+		}
+		byName[g.Name] = g
+	}
+	for _, g := range c.Groups {
+		if err := g.validate(c, byName); err != nil {
+			return fmt.Errorf("scenario group %q: %w", g.Name, err)
+		}
+	}
+	if c.Agents != "" || c.SizeSweep != "" || c.ConcurrencySweep != "" {
+		return fmt.Errorf("scenarios cannot be combined with -agents or sweeps")
+	}
+	return nil
+}
+
+// validate checks g, a group of a scenario with the groups in byName.
+func (g OperationGroup) validate(c *Config, byName map[string]OperationGroup) error {
+	if g.Weight <= 0 {
+		return fmt.Errorf("weight must be greater than 0")
+	}
+	if g.Operation != GroupWrite && (g.KeyTemplate != "" || g.SizeDistribution != "") {
+		return fmt.Errorf("keyTemplate and sizeDistribution only apply to write groups")
+	}
+	if g.Operation != GroupList && g.Prefix != "" {
+		return fmt.Errorf("prefix only applies to list groups")
+	}
+	switch g.Operation {
+	case GroupRead, GroupHead, GroupDelete:
+		if g.Operation == GroupDelete && g.Manifest != "" {
+			return fmt.Errorf("delete groups delete the keys of a write group (keysFrom), not of a manifest")
+		}
+		if (g.Manifest == "") == (g.KeysFrom == "") {
+			return fmt.Errorf("%s groups need either a manifest or keysFrom", g.Operation)
+		}
+		if g.KeysFrom != "" {
+			if src, ok := byName[g.KeysFrom]; !ok || src.Operation != GroupWrite {
+				return fmt.Errorf("keysFrom %q is not a write group of the scenario", g.KeysFrom)
+			}
+		}
+	case GroupWrite:
+		if g.Manifest != "" || g.KeysFrom != "" {
+			return fmt.Errorf("write groups generate their keys, manifest and keysFrom do not apply")
+		}
+		if _, err := parseKeyTemplate(g.KeyTemplate, g.defaultKeyTemplate()); err != nil {
+			return fmt.Errorf("%w (keyTemplate)", err)
+		}
+		if _, err := parseSizeDistribution(g.SizeDistribution, c.PutObjectSizeKB); err != nil {
+			return fmt.Errorf("invalid sizeDistribution: %w", err)
+		}
+		if g.SizeDistribution == "" && c.PutObjectSizeKB <= 0 {
+			return fmt.Errorf("put object size (-putsize) must be greater than 0 KB without a sizeDistribution")
+		}
+	case GroupList:
+		if g.Manifest != "" || g.KeysFrom != "" {
+			return fmt.Errorf("list groups list a prefix, manifest and keysFrom do not apply")
+		}
+	default:
+		return fmt.Errorf("invalid operation %q: must be read, write, head, list or delete", g.Operation)
+	}
+	return nil
+}
+
+// scenarioGroup is a group of a scenario with its loaded key space.
+type scenarioGroup struct {
+	OperationGroup
+	keys     []string         // Manifest keys of read and head groups
+	expected []ManifestEntry  // Expected sizes and ETags of keys, nil if the manifest has none
+	source   *scenarioGroup   // Group of KeysFrom
+	written  *versionPool     // Keys written by a write group that other groups use, nil if none do
+	tmpl     keyTemplate      // Write groups
+	sizes    sizeDistribution // Write groups
+}
+
+// scenario is the run-time state of the groups of a 'scenario' mode run, shared by all workers.
+type scenario struct {
+	groups []*scenarioGroup
+	total  float64 // Sum of the weights
+}
+
+// newScenario loads the manifests of the groups of cfg.
+func newScenario(cfg *Config) (*scenario, error) {
+	if cfg.KeyDistribution == KeyDistWeighted {
+		return nil, fmt.Errorf("scenarios do not support the weighted key distribution (-distribution weighted)")
+	}
+	s := &scenario{}
+	byName := make(map[string]*scenarioGroup)
+	for _, og := range cfg.Groups {
+		g := &scenarioGroup{OperationGroup: og}
+		if og.Manifest != "" {
+			entries, err := LoadManifestEntries(og.Manifest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load manifest of scenario group %q: %w", og.Name, err)
+			}
+			g.keys, _ = splitManifest(entries)
+			g.expected = manifestExpectations(entries)
+		}
+		g.tmpl, _ = parseKeyTemplate(og.KeyTemplate, og.defaultKeyTemplate())        // Already validated in Config.Validate
+		g.sizes, _ = parseSizeDistribution(og.SizeDistribution, cfg.PutObjectSizeKB) // Already validated in Config.Validate
+		if g.Prefix == "" {
+			g.Prefix = cfg.ListPrefix
+		}
+		s.groups = append(s.groups, g)
+		s.total += og.Weight
+		byName[og.Name] = g
+	}
+	for _, g := range s.groups {
+		if g.KeysFrom != "" {
+			g.source = byName[g.KeysFrom]
+			if g.source.written == nil {
+				g.source.written = &versionPool{}
+			}
+		}
+		slog.Info("Scenario group", "name", g.Name, "operation", g.Operation, "share", fmt.Sprintf("%.1f%%", 100*g.Weight/s.total),
+			"keys", len(g.keys), "keysFrom", g.KeysFrom, "sizes", g.sizes.String())
+	}
+	return s, nil
+}
+
+// pick returns a group at random according to the weights.
+func (s *scenario) pick(r *rand.Rand) *scenarioGroup {
+	n := r.Float64() * s.total
+	for _, g := range s.groups {
+		if n < g.Weight {
+			return g
+		}
+		n -= g.Weight
+	}
+	return s.groups[len(s.groups)-1]
+}
+
+// scenarioWorker holds a worker's position in the key spaces and listings of a scenario.
+type scenarioWorker struct {
+	*scenario
+	id          int
+	r           *rand.Rand
+	pickers     map[*scenarioGroup]*keyPicker
+	listTokens  map[*scenarioGroup]string // Continuation tokens of the listings in progress
+	listBuckets map[*scenarioGroup]string // Buckets of those listings
+	listTargets map[*scenarioGroup]*endpointTarget
+}
+
+// worker returns the state of worker id, which picks manifest keys with dist.
+func (s *scenario) worker(r *rand.Rand, id int, dist keyDistribution) *scenarioWorker {
+	w := &scenarioWorker{scenario: s, id: id, r: r, pickers: make(map[*scenarioGroup]*keyPicker),
+		listTokens: make(map[*scenarioGroup]string), listBuckets: make(map[*scenarioGroup]string), listTargets: make(map[*scenarioGroup]*endpointTarget)}
+	for _, g := range s.groups {
+		if len(g.keys) > 0 {
+			w.pickers[g] = dist.newPicker(r, len(g.keys), id, nil)
+		}
+	}
+	return w
+}
+
+// next performs the operation of a randomly picked group and returns its result and the
+// bucket it went to. Until a write group has written keys, the operations of the groups
+// that use them are writes of that group. Listings stay in their bucket and on their
+// endpoint, so target may be replaced.
+func (w *scenarioWorker) next(ctx context.Context, cfg *Config, hedge *hedger, buckets *bucketPicker, target **endpointTarget, pattern dataPattern, putSeq *int64) (Result, string) {
+	g := w.pick(w.r)
+	var entry ManifestEntry
+	if g.source != nil {
+		var ok bool
+		if g.Operation == GroupDelete {
+			entry, ok = g.source.written.take(w.r)
+		} else {
+			entry, ok = g.source.written.pick(w.r)
+		}
+		if !ok {
+			g = g.source
+		}
+	}
+
+	var result Result
+	var bucket string
+	client := (*target).client
+	switch g.Operation {
+	case GroupRead, GroupHead:
+		idx := -1
+		if g.source == nil {
+			idx = w.pickers[g].pick()
+			entry.Key = g.keys[idx]
+		}
+		bucket = buckets.pick(w.r, entry.Key)
+		if g.Operation == GroupHead {
+			result = performHeadOperation(ctx, client, bucket, entry.Key)
+		} else {
+			result = fetchObject(ctx, client, cfg, hedge, bucket, entry.Key)
+			if idx < 0 {
+				checkExpected(&result, entry) // Size and ETag of the PUT
+			} else if g.expected != nil {
+				checkExpected(&result, g.expected[idx])
+			}
+		}
+	case GroupDelete:
+		bucket = buckets.pick(w.r, entry.Key)
+		result = performDeleteOperation(ctx, client, bucket, entry)
+		if result.Error != "" {
+			g.source.written.add(w.r, entry) // The object may still exist
+		}
+	case GroupWrite:
+		key := g.tmpl.render(w.r, keyVars{worker: w.id, seq: *putSeq, run: cfg.RunID})
+		*putSeq++
+		bucket = buckets.pick(w.r, key)
+		result = uploadObject(ctx, client, cfg, bucket, key, pattern.payload(g.sizes.sample(w.r), w.r))
+		if result.Error == "" && g.written != nil && cfg.DisconnectFraction == 0 {
+			g.written.add(w.r, ManifestEntry{Key: key, Size: result.BytesUploaded, ETag: result.ETag})
+		}
+	case GroupList:
+		token := w.listTokens[g]
+		if token == "" {
+			w.listBuckets[g] = buckets.pick(w.r, "")
+			w.listTargets[g] = *target
+		}
+		bucket, *target = w.listBuckets[g], w.listTargets[g]
+		result, w.listTokens[g] = performListOperation(ctx, (*target).client, bucket, g.Prefix, cfg.ListPageSize, token)
+	}
+	result.Group = g.Name
+	return result, bucket
+}
+
+// GroupStats aggregates the results of one group of a scenario.
+type GroupStats struct {
+	Group          string  `json:"group" yaml:"group"`
+	Requests       int64   `json:"requests" yaml:"requests"`
+	Errors         int64   `json:"errors" yaml:"errors"`
+	Bytes          int64   `json:"bytes" yaml:"bytes"` // Bytes transferred in either direction by successful requests
+	RequestsPerSec float64 `json:"requestsPerSec" yaml:"requestsPerSec"`
+	ThroughputMiB  float64 `json:"throughputMiBps" yaml:"throughputMiBps"`
+	P50TTLBMs      float64 `json:"p50TtlbMs" yaml:"p50TtlbMs"`
+	P99TTLBMs      float64 `json:"p99TtlbMs" yaml:"p99TtlbMs"`
+	ttlbs          *Histogram
+}
+
+// addGroupResult records r against its scenario group. Called from AddResult; results of
+// other modes carry no group and are skipped.
+func (s *Stats) addGroupResult(r Result) {
+	if r.Group == "" {
+		return
+	}
+	if s.groups == nil {
+		s.groups = make(map[string]*GroupStats)
+	}
+	gs, ok := s.groups[r.Group]
+	if !ok {
+		gs = &GroupStats{Group: r.Group, ttlbs: NewHistogram()}
+		s.groups[r.Group] = gs
+	}
+	gs.Requests++
+	if r.Error != "" {
+		gs.Errors++
+		return
+	}
+	gs.Bytes += r.BytesDownloaded + r.BytesUploaded
+	gs.ttlbs.Record(r.TTLB)
+}
+
+// calculateGroupStats computes per-group rates and latency. Called from Calculate.
+func (s *Stats) calculateGroupStats() {
+	secs := s.actualDuration.Seconds()
+	for _, gs := range s.groups {
+		if secs > 0 {
+			gs.RequestsPerSec = float64(gs.Requests) / secs
+			gs.ThroughputMiB = float64(gs.Bytes) / (1024 * 1024) / secs
+		}
+		if gs.ttlbs.Count() > 0 {
+			gs.P50TTLBMs = ms(gs.ttlbs.Percentile(50))
+			gs.P99TTLBMs = ms(gs.ttlbs.Percentile(99))
+		}
+	}
+}
+
+// GroupStats returns the per-group aggregates ordered by group name, nil unless the run
+// was a scenario.
+func (s *Stats) GroupStats() []*GroupStats {
+	if len(s.groups) == 0 {
+		return nil
+	}
+	list := make([]*GroupStats, 0, len(s.groups))
+	for _, gs := range s.groups {
+		list = append(list, gs)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Group < list[j].Group })
+	return list
+}
+
+// printGroupSummary prints per-group figures of a scenario as part of PrintSummary.
+func (s *Stats) printGroupSummary(w io.Writer) {
+	if len(s.groups) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nScenario Groups (%d):\n", len(s.groups))
+	fmt.Fprintf(w, "  Requests |  Req/s  | Errors |  MiB/s  | P50 (ms) | P99 (ms) | Group\n")
+	fmt.Fprintf(w, "  ---------|---------|--------|---------|----------|----------|-------\n")
+	for _, gs := range s.GroupStats() {
+		fmt.Fprintf(w, "  %8d | %7.2f | %6d | %7.2f | %8.2f | %8.2f | %s\n",
+			gs.Requests, gs.RequestsPerSec, gs.Errors, gs.ThroughputMiB, gs.P50TTLBMs, gs.P99TTLBMs, gs.Group)
+	}
+}
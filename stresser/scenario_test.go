@@ -0,0 +1,219 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// deletingMemS3Client is a memS3Client that also deletes objects.
+type deletingMemS3Client struct {
+	memS3Client
+	deletes int
+}
+
+func (c *deletingMemS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if params.VersionId != nil {
+		return nil, fmt.Errorf("unexpected version id %q", *params.VersionId)
+	}
+	c.deletes++
+	delete(c.bodies, *params.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func writeScenario(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func scenarioConfig(groups []OperationGroup) *Config {
+	return &Config{Endpoint: "https://test-endpoint.com", Region: "us-east-1", Bucket: "bucket", Duration: "10s", Concurrency: 1,
+		OutputFile: "results.csv", OperationType: "read", PutObjectSizeKB: 1, ListPageSize: 2, Groups: groups}
+}
+
+func TestLoadScenario(t *testing.T) {
+	path := writeScenario(t, `
+groups:
+  - name: small-gets
+    weight: 70
+    operation: read
+    manifest: small.txt
+  - {name: uploads, weight: 30, operation: write, sizeDistribution: "4K:50%,1M:50%"}
+`)
+	groups, err := LoadScenario(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Manifest != "small.txt" || groups[1].Weight != 30 || groups[1].SizeDistribution != "4K:50%,1M:50%" {
+		t.Errorf("Unexpected groups %+v", groups)
+	}
+
+	if _, err := LoadScenario(writeScenario(t, "groups:\n  - {name: a, weight: 1, operation: read, manifets: x.txt}\n")); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if _, err := LoadScenario(writeScenario(t, "")); err == nil {
+		t.Error("Expected an error for a scenario without groups")
+	}
+}
+
+func TestValidateScenario(t *testing.T) {
+	c := scenarioConfig(nil)
+	c.Scenario = writeScenario(t, "groups:\n  - {name: up, weight: 1, operation: write}\n  - {name: del, weight: 1, operation: delete, keysFrom: up}\n")
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if c.OperationType != "scenario" || len(c.Groups) != 2 {
+		t.Errorf("Expected scenario mode with 2 groups, got %s with %d", c.OperationType, len(c.Groups))
+	}
+
+	for _, groups := range [][]OperationGroup{
+		{{Name: "bad name", Weight: 1, Operation: GroupWrite}},
+		{{Name: "a", Weight: 0, Operation: GroupWrite}},
+		{{Name: "a", Weight: 1, Operation: "copy"}},
+		{{Name: "a", Weight: 1, Operation: GroupWrite}, {Name: "a", Weight: 1, Operation: GroupWrite}},
+		{{Name: "a", Weight: 1, Operation: GroupRead}},
+		{{Name: "a", Weight: 1, Operation: GroupRead, Manifest: "m.txt", KeysFrom: "b"}, {Name: "b", Weight: 1, Operation: GroupWrite}},
+		{{Name: "a", Weight: 1, Operation: GroupDelete, Manifest: "m.txt"}},
+		{{Name: "a", Weight: 1, Operation: GroupHead, KeysFrom: "b"}, {Name: "b", Weight: 1, Operation: GroupList}},
+		{{Name: "a", Weight: 1, Operation: GroupWrite, SizeDistribution: "huge"}},
+		{{Name: "a", Weight: 1, Operation: GroupRead, Manifest: "m.txt", Prefix: "p/"}},
+	} {
+		if err := scenarioConfig(groups).Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", groups)
+		}
+	}
+	c = scenarioConfig([]OperationGroup{{Name: "a", Weight: 1, Operation: GroupWrite}})
+	c.Agents = "host1,host2"
+	if err := c.Validate(); err == nil {
+		t.Error("Expected an error for a scenario with agents")
+	}
+}
+
+func TestScenarioPick(t *testing.T) {
+	cfg := scenarioConfig([]OperationGroup{
+		{Name: "big", Weight: 70, Operation: GroupList},
+		{Name: "medium", Weight: 20, Operation: GroupWrite},
+		{Name: "small", Weight: 10, Operation: GroupList},
+	})
+	s, err := newScenario(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	for range 10000 {
+		counts[s.pick(r).Name]++
+	}
+	for name, want := range map[string]int{"big": 7000, "medium": 2000, "small": 1000} {
+		if c := counts[name]; c < want*9/10 || c > want*11/10 {
+			t.Errorf("Expected about %d %s, got %d", want, name, c)
+		}
+	}
+}
+
+func TestScenarioWorker(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "m.txt")
+	if err := os.WriteFile(manifest, []byte("seed/a\nseed/b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := scenarioConfig([]OperationGroup{
+		{Name: "seeds", Weight: 1, Operation: GroupRead, Manifest: manifest},
+		{Name: "uploads", Weight: 1, Operation: GroupWrite, SizeDistribution: "2K:100%"},
+		{Name: "reads", Weight: 1, Operation: GroupRead, KeysFrom: "uploads"},
+		{Name: "deletes", Weight: 1, Operation: GroupDelete, KeysFrom: "uploads"},
+	})
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newScenario(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &deletingMemS3Client{memS3Client: memS3Client{bodies: map[string][]byte{"seed/a": []byte("a"), "seed/b": []byte("b")}, metadata: map[string]map[string]string{}}}
+	target := &endpointTarget{weight: 1, client: client}
+	pattern, _ := parseDataPattern("")
+	r := rand.New(rand.NewSource(1))
+	w := s.worker(r, 0, keyDistribution{kind: KeyDistSequential})
+
+	stats := NewStats()
+	var putSeq int64
+	now := time.Now()
+	for range 400 {
+		result, bucket := w.next(context.Background(), cfg, nil, newBucketPicker(cfg), &target, pattern, &putSeq)
+		if result.Error != "" || bucket != "bucket" {
+			t.Fatalf("Unexpected result %+v in bucket %q", result, bucket)
+		}
+		if result.Integrity == IntegrityCorrupt {
+			t.Errorf("Unexpected corrupt read %+v", result)
+		}
+		if result.Group == "uploads" && (result.BytesUploaded != 2048 || !strings.HasPrefix(result.ObjectKey, RunPrefix(cfg.RunID)+"uploads/worker0/")) {
+			t.Errorf("Unexpected upload %+v", result)
+		}
+		if result.Group == "seeds" && !strings.HasPrefix(result.ObjectKey, "seed/") {
+			t.Errorf("Unexpected seed read %+v", result)
+		}
+		stats.AddResult(result)
+	}
+	stats.Calculate(now, now.Add(time.Second))
+
+	groups := stats.GroupStats()
+	if len(groups) != 4 {
+		t.Fatalf("Expected stats of 4 groups, got %+v", groups)
+	}
+	var puts int64
+	for _, gs := range groups {
+		if gs.Requests < 50 {
+			t.Errorf("Expected group %s to get its share of 400 operations, got %d", gs.Group, gs.Requests)
+		}
+		if gs.Group == "uploads" {
+			puts = gs.Requests
+		}
+	}
+	if want := puts - int64(client.deletes) + 2; int64(len(client.bodies)) != want {
+		t.Errorf("Expected %d objects after %d PUTs and %d deletes, found %d", want, puts, client.deletes, len(client.bodies))
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "Scenario Groups (4):") {
+		t.Errorf("Summary lacks the group table:\n%s", buf.String())
+	}
+	if sum := stats.Summary(); len(sum.Groups) != 4 || sum.Groups[0].Group != "deletes" {
+		t.Errorf("Unexpected group summary %+v", sum.Groups)
+	}
+}
+
+func TestScenarioListGroup(t *testing.T) {
+	cfg := scenarioConfig([]OperationGroup{{Name: "listing", Weight: 1, Operation: GroupList, Prefix: "a/"}})
+	s, err := newScenario(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &pagingS3Client{keys: []string{"a/1", "a/2", "a/3", "a/4", "a/5"}}
+	target := &endpointTarget{weight: 1, client: client}
+	r := rand.New(rand.NewSource(1))
+	w := s.worker(r, 0, keyDistribution{kind: KeyDistSequential})
+	var pages []int
+	for range 4 {
+		result, _ := w.next(context.Background(), cfg, nil, newBucketPicker(cfg), &target, dataPattern{}, new(int64))
+		pages = append(pages, result.Keys)
+	}
+	if fmt.Sprint(pages) != "[2 2 1 2]" {
+		t.Errorf("Expected the listing to page through and start over, got pages of %v", pages)
+	}
+	if p := *client.last.Prefix; p != "a/" {
+		t.Errorf("Expected the group prefix, listed %q", p)
+	}
+}
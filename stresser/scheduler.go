@@ -0,0 +1,115 @@
+package stresser
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// goMaxThreads is the Go runtime's default limit on OS threads, beyond which it crashes.
+const goMaxThreads = 10000
+
+// lockedThreadHeadroom is the number of OS threads left for the runtime, the HTTP transport
+// and blocking system calls next to the locked threads of -lock-threads.
+const lockedThreadHeadroom = 1000
+
+// MaxThreadPool is the largest pool of locked threads (-thread-pool). It keeps the process
+// within the Go thread limit, which is therefore never raised.
+const MaxThreadPool = goMaxThreads - lockedThreadHeadroom
+
+// validateScheduler checks -gomaxprocs and -thread-pool. Called from Validate.
+func (c *Config) validateScheduler() error {
+	if c.GoMaxProcs < 0 {
+		return fmt.Errorf("GOMAXPROCS (-gomaxprocs) must not be negative, got %d", c.GoMaxProcs)
+	}
+	if c.ThreadPool < 0 || c.ThreadPool > MaxThreadPool {
+		return fmt.Errorf("thread pool size (-thread-pool) must be in the range [0, %d], got %d", MaxThreadPool, c.ThreadPool)
+	}
+	if c.ThreadPool > 0 && !c.LockThreads {
+		return fmt.Errorf("thread pool size (-thread-pool) requires -lock-threads")
+	}
+	return nil
+}
+
+// applyScheduler sets GOMAXPROCS from -gomaxprocs for a run. The returned function restores
+// it once the run is over.
+func applyScheduler(cfg *Config) (restore func()) {
+	restore = func() {}
+	if cfg.GoMaxProcs > 0 {
+		prev := runtime.GOMAXPROCS(cfg.GoMaxProcs)
+		restore = func() { runtime.GOMAXPROCS(prev) }
+	}
+	if cfg.GoMaxProcs > 0 || cfg.LockThreads {
+		slog.Info("Scheduler configured", "gomaxprocs", runtime.GOMAXPROCS(0), "lockThreads", cfg.LockThreads, "threadPool", threadPoolSize(cfg))
+	}
+	return restore
+}
+
+// threadPoolSize returns the number of locked threads of a run: -thread-pool, by default one
+// per worker up to MaxThreadPool. It is 0 without -lock-threads.
+func threadPoolSize(cfg *Config) int {
+	switch {
+	case !cfg.LockThreads:
+		return 0
+	case cfg.ThreadPool > 0:
+		return cfg.ThreadPool
+	default:
+		return min(cfg.Concurrency, MaxThreadPool)
+	}
+}
+
+// threadPool runs the operations of the workers on a fixed set of goroutines, each wired to
+// an OS thread of its own (-lock-threads), so the kernel rather than the Go scheduler decides
+// which operation runs when. A worker waits for its operation, so with more workers than
+// threads the workers take turns and at most size operations are in flight.
+type threadPool struct {
+	ops  chan func()
+	wg   sync.WaitGroup
+	size int
+}
+
+// newThreadPool starts the locked threads of cfg, or returns nil without -lock-threads.
+func newThreadPool(cfg *Config) *threadPool {
+	size := threadPoolSize(cfg)
+	if size == 0 {
+		return nil
+	}
+	p := &threadPool{ops: make(chan func()), size: size}
+	p.wg.Add(size)
+	for range size {
+		go func() {
+			defer p.wg.Done()
+			runtime.LockOSThread() // Never unlocked: the thread exits with the goroutine
+			for op := range p.ops {
+				op()
+			}
+		}()
+	}
+	return p
+}
+
+// run runs op on a thread of the pool and returns once it is done. A nil pool runs op on the
+// calling goroutine.
+func (p *threadPool) run(op func()) {
+	if p == nil {
+		op()
+		return
+	}
+	done := make(chan struct{})
+	p.ops <- func() {
+		defer close(done)
+		op()
+	}
+	<-done
+}
+
+// close stops the threads once the operations handed to them are done. Calls to run must
+// have returned.
+func (p *threadPool) close() {
+	if p == nil {
+		return
+	}
+	close(p.ops)
+	p.wg.Wait()
+}
@@ -0,0 +1,96 @@
+package stresser
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidateScheduler(t *testing.T) {
+	if err := (&Config{GoMaxProcs: 4}).validateScheduler(); err != nil {
+		t.Errorf("validateScheduler failed: %v", err)
+	}
+	if err := (&Config{GoMaxProcs: -1}).validateScheduler(); err == nil {
+		t.Error("Expected an error for a negative GOMAXPROCS")
+	}
+}
+
+func TestApplyScheduler(t *testing.T) {
+	before := runtime.GOMAXPROCS(0)
+	restore := applyScheduler(&Config{GoMaxProcs: before + 1})
+	if got := runtime.GOMAXPROCS(0); got != before+1 {
+		t.Errorf("Expected GOMAXPROCS %d during the run, got %d", before+1, got)
+	}
+	restore()
+	if got := runtime.GOMAXPROCS(0); got != before {
+		t.Errorf("Expected GOMAXPROCS %d after the run, got %d", before, got)
+	}
+	applyScheduler(&Config{})() // Nothing to set or restore
+}
+
+func TestThreadPool(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  Config
+		size int
+	}{
+		{Config{Concurrency: 50}, 0},
+		{Config{Concurrency: 50, LockThreads: true}, 50},
+		{Config{Concurrency: 20000, LockThreads: true}, MaxThreadPool},
+		{Config{Concurrency: 50, LockThreads: true, ThreadPool: 8}, 8},
+	} {
+		if got := threadPoolSize(&tc.cfg); got != tc.size {
+			t.Errorf("Expected a pool of %d threads for %+v, got %d", tc.size, tc.cfg, got)
+		}
+	}
+	if newThreadPool(&Config{Concurrency: 50}) != nil {
+		t.Error("Expected no pool without -lock-threads")
+	}
+	for _, cfg := range []Config{{ThreadPool: 4}, {LockThreads: true, ThreadPool: -1}, {LockThreads: true, ThreadPool: MaxThreadPool + 1}} {
+		if err := cfg.validateScheduler(); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+
+	// Ten workers take turns on two threads: all operations run, at most two at a time
+	pool := newThreadPool(&Config{Concurrency: 10, LockThreads: true, ThreadPool: 2})
+	var mu sync.Mutex
+	running, peak, ran := 0, 0, 0
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				pool.run(func() {
+					mu.Lock()
+					running++
+					peak = max(peak, running)
+					mu.Unlock()
+					time.Sleep(time.Millisecond)
+					mu.Lock()
+					running--
+					ran++
+					mu.Unlock()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	pool.close()
+	if ran != 50 || peak != 2 {
+		t.Errorf("Expected 50 operations at most 2 at a time, got %d with a peak of %d", ran, peak)
+	}
+}
+
+func TestPrintClientScheduler(t *testing.T) {
+	stats := NewStats()
+	stats.Client = &ClientReport{CPUAvailable: true, GOMAXPROCS: 3, LockedThreads: 3}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "(3 cores)") || !strings.Contains(buf.String(), "pool of 3 locked OS threads") {
+		t.Errorf("Client summary lacks the scheduler settings:\n%s", buf.String())
+	}
+}
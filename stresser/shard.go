@@ -0,0 +1,59 @@
+package stresser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// validateShard checks -shard.
+func (c *Config) validateShard() error {
+	if c.Shard == "" {
+		return nil
+	}
+	if _, _, err := parseShard(c.Shard); err != nil {
+		return fmt.Errorf("invalid manifest shard (-shard) %q: %w", c.Shard, err)
+	}
+	switch c.OperationType {
+	case "read", "mixed", "head", "revalidate", "copy", "tagging", "versioned":
+		return nil
+	}
+	return fmt.Errorf("a manifest shard (-shard) only applies to modes that read a manifest, not %s", c.OperationType)
+}
+
+// parseShard parses a shard spec "index/count", e.g. "3/8" for the third of eight shards.
+// Shards are numbered from 1.
+func parseShard(spec string) (index, count int, err error) {
+	i, n, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected index/count, e.g. 3/8")
+	}
+	if index, err = strconv.Atoi(strings.TrimSpace(i)); err != nil {
+		return 0, 0, fmt.Errorf("invalid shard index %q", i)
+	}
+	if count, err = strconv.Atoi(strings.TrimSpace(n)); err != nil || count < 1 {
+		return 0, 0, fmt.Errorf("invalid shard count %q: must be a positive integer", n)
+	}
+	if index < 1 || index > count {
+		return 0, 0, fmt.Errorf("shard index %d out of range 1-%d", index, count)
+	}
+	return index, count, nil
+}
+
+// manifestShard returns the entries of the shard selected by spec, the same round-robin
+// slice agents of a distributed run get, so independent instances given the same manifest
+// and different shard indexes read disjoint keys. An empty spec selects all entries.
+func manifestShard(entries []ManifestEntry, spec string) ([]ManifestEntry, error) {
+	if spec == "" {
+		return entries, nil
+	}
+	index, count, err := parseShard(spec) // Already validated in Config.Validate
+	if err != nil {
+		return nil, err
+	}
+	shard := shardKeys(entries, count)[index-1]
+	if len(shard) == 0 {
+		return nil, fmt.Errorf("shard %s of the manifest has no keys: the manifest has only %d", spec, len(entries))
+	}
+	return shard, nil
+}
@@ -0,0 +1,70 @@
+package stresser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	index, count, err := parseShard("3/8")
+	if err != nil || index != 3 || count != 8 {
+		t.Errorf("Expected shard 3 of 8, got %d/%d (%v)", index, count, err)
+	}
+	for _, spec := range []string{"3", "0/8", "9/8", "1/0", "a/8", "3/b", "3/8/2", "-1/8"} {
+		if _, _, err := parseShard(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestManifestShard(t *testing.T) {
+	entries := make([]ManifestEntry, 20)
+	for i := range entries {
+		entries[i] = ManifestEntry{Key: fmt.Sprintf("key%02d", i)}
+	}
+	// The shards of all instances are disjoint and cover the manifest
+	seen := make(map[string]int)
+	for i := 1; i <= 3; i++ {
+		shard, err := manifestShard(entries, fmt.Sprintf("%d/3", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range shard {
+			seen[e.Key]++
+		}
+		if shard[0] != entries[i-1] {
+			t.Errorf("Expected shard %d/3 to start with %s, got %s", i, entries[i-1].Key, shard[0].Key)
+		}
+	}
+	if len(seen) != len(entries) {
+		t.Errorf("Expected the shards to cover %d keys, got %d", len(entries), len(seen))
+	}
+	for k, n := range seen {
+		if n != 1 {
+			t.Errorf("Key %s is in %d shards", k, n)
+		}
+	}
+
+	if all, err := manifestShard(entries, ""); err != nil || len(all) != len(entries) {
+		t.Errorf("Expected all entries without a shard, got %d (%v)", len(all), err)
+	}
+	if _, err := manifestShard(entries[:2], "3/4"); err == nil {
+		t.Error("Expected an error for an empty shard")
+	}
+}
+
+func TestValidateShard(t *testing.T) {
+	c := Config{OperationType: "read", Shard: "2/4"}
+	if err := c.validateShard(); err != nil {
+		t.Error(err)
+	}
+	for _, c := range []Config{
+		{OperationType: "read", Shard: "5/4"},
+		{OperationType: "write", Shard: "1/4"},
+		{OperationType: "replay", Shard: "1/4"},
+	} {
+		if err := c.validateShard(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package stresser
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Request signing modes (-signing).
+const (
+	SigningV4   = "v4"   // AWS Signature Version 4, the SDK default
+	SigningV2   = "v2"   // Legacy AWS Signature Version 2, for old gateways that don't speak SigV4
+	SigningNone = "none" // Unsigned, anonymous requests, for public buckets
+)
+
+// sigV2SubResources are the query parameters that SigV2 includes in the canonical resource.
+var sigV2SubResources = map[string]bool{
+	"acl": true, "cors": true, "delete": true, "lifecycle": true, "location": true, "logging": true,
+	"notification": true, "partNumber": true, "policy": true, "requestPayment": true, "restore": true,
+	"tagging": true, "torrent": true, "uploadId": true, "uploads": true, "versionId": true,
+	"versioning": true, "versions": true, "website": true,
+	"response-cache-control": true, "response-content-disposition": true, "response-content-encoding": true,
+	"response-content-language": true, "response-content-type": true, "response-expires": true,
+}
+
+// validateSigning checks -signing.
+func (c *Config) validateSigning() error {
+	switch strings.ToLower(c.Signing) {
+	case "":
+		c.Signing = SigningV4
+	case SigningV4, SigningV2, SigningNone:
+		c.Signing = strings.ToLower(c.Signing)
+	default:
+		return fmt.Errorf("invalid signing mode (-signing): %s. Must be 'v4', 'v2' or 'none'", c.Signing)
+	}
+	if c.Signing == SigningV4 {
+		return nil
+	}
+	if c.Backend == BackendGCS {
+		return fmt.Errorf("signing mode %s (-signing, -no-sign) is not supported by the gcs backend", c.Signing)
+	}
+	if c.Presign {
+		return fmt.Errorf("presigned requests (-presign) are always signed with SigV4 and cannot be combined with -signing %s", c.Signing)
+	}
+	return nil
+}
+
+// withSigV2 returns an SDK API option that signs requests with SigV2 instead of SigV4. The
+// SDK's signer is swapped out in place, so retries are signed again like before.
+func withSigV2(creds aws.CredentialsProvider) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		_, err := stack.Finalize.Swap("Signing", &sigV2Middleware{creds: creds})
+		return err
+	}
+}
+
+// sigV2Middleware signs requests with SigV2 in place of the SDK's signing middleware.
+type sigV2Middleware struct {
+	creds aws.CredentialsProvider
+}
+
+func (*sigV2Middleware) ID() string { return "Signing" }
+
+func (m *sigV2Middleware) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+	middleware.FinalizeOutput, middleware.Metadata, error,
+) {
+	req, ok := in.Request.(*smithyhttp.Request)
+	if !ok {
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, fmt.Errorf("unexpected transport type %T", in.Request)
+	}
+	creds, err := m.creds.Retrieve(ctx)
+	if err != nil {
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+	req.Header.Del("X-Amz-Date") // SigV2 signs the Date header instead
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	signV2(req.Request, creds)
+	return next.HandleFinalize(ctx, in)
+}
+
+// signV2 sets the SigV2 Authorization header of r, which must have its Date header set.
+func signV2(r *http.Request, creds aws.Credentials) {
+	if creds.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	mac := hmac.New(sha1.New, []byte(creds.SecretAccessKey))
+	mac.Write([]byte(sigV2StringToSign(r)))
+	r.Header.Set("Authorization", "AWS "+creds.AccessKeyID+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// sigV2StringToSign returns the string SigV2 signs: the method, Content-MD5, Content-Type
+// and Date headers, the x-amz-* headers and the path-style resource with its sub-resources.
+func sigV2StringToSign(r *http.Request) string {
+	var b strings.Builder
+	for _, s := range []string{r.Method, r.Header.Get("Content-MD5"), r.Header.Get("Content-Type"), r.Header.Get("Date")} {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+
+	amz := make(map[string]string)
+	for name, values := range r.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, v := range values {
+				trimmed[i] = strings.TrimSpace(v)
+			}
+			amz[lower] = strings.Join(trimmed, ",")
+		}
+	}
+	names := make([]string, 0, len(amz))
+	for name := range amz {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name + ":" + amz[name] + "\n")
+	}
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+	var sub []string
+	for name, values := range r.URL.Query() {
+		if !sigV2SubResources[name] {
+			continue
+		}
+		if len(values) == 0 || values[0] == "" {
+			sub = append(sub, name)
+		} else {
+			sub = append(sub, name+"="+values[0])
+		}
+	}
+	sort.Strings(sub)
+	if len(sub) > 0 {
+		b.WriteString("?" + strings.Join(sub, "&"))
+	}
+	return b.String()
+}
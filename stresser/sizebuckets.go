@@ -0,0 +1,144 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+)
+
+// sizeClasses are the upper bounds (exclusive) of the object size classes GET and PUT stats
+// are bucketed by. Objects of at least the last bound fall into a final open-ended class.
+var sizeClasses = []struct {
+	limit int64
+	label string
+}{
+	{128 * 1024, "<128KiB"},
+	{1024 * 1024, "128KiB-1MiB"},
+	{16 * 1024 * 1024, "1-16MiB"},
+	{0, ">=16MiB"},
+}
+
+// SizeClassStats aggregates successful GETs or PUTs of objects in one size class. PUTs have
+// no TTFB figures.
+type SizeClassStats struct {
+	Class         string  `json:"class" yaml:"class"`
+	Requests      int64   `json:"requests" yaml:"requests"`
+	Bytes         int64   `json:"bytes" yaml:"bytes"`
+	ThroughputMiB float64 `json:"throughputMiBps" yaml:"throughputMiBps"`
+	AvgTTFBMs     float64 `json:"avgTtfbMs,omitempty" yaml:"avgTtfbMs,omitempty"`
+	P99TTFBMs     float64 `json:"p99TtfbMs,omitempty" yaml:"p99TtfbMs,omitempty"`
+	AvgTTLBMs     float64 `json:"avgTtlbMs" yaml:"avgTtlbMs"`
+	P50TTLBMs     float64 `json:"p50TtlbMs" yaml:"p50TtlbMs"`
+	P99TTLBMs     float64 `json:"p99TtlbMs" yaml:"p99TtlbMs"`
+	ttfbs         *Histogram
+	ttlbs         *Histogram
+}
+
+// sizeClass returns the index in sizeClasses of an object of size bytes.
+func sizeClass(size int64) int {
+	for i, c := range sizeClasses[:len(sizeClasses)-1] {
+		if size < c.limit {
+			return i
+		}
+	}
+	return len(sizeClasses) - 1
+}
+
+func newSizeClassStats() []*SizeClassStats {
+	classes := make([]*SizeClassStats, len(sizeClasses))
+	for i, c := range sizeClasses {
+		classes[i] = &SizeClassStats{Class: c.label, ttfbs: NewHistogram(), ttlbs: NewHistogram()}
+	}
+	return classes
+}
+
+// addSizeClassResult records a successful GET or PUT against the size class of its object.
+// Called from AddResult.
+func (s *Stats) addSizeClassResult(r Result) {
+	if r.Error != "" {
+		return
+	}
+	switch r.Operation {
+	case "GET":
+		if s.sizeClasses == nil {
+			s.sizeClasses = newSizeClassStats()
+		}
+		sc := s.sizeClasses[sizeClass(r.BytesDownloaded)]
+		sc.Requests++
+		sc.Bytes += r.BytesDownloaded
+		sc.ttfbs.Record(r.TTFB)
+		sc.ttlbs.Record(r.TTLB)
+	case "PUT":
+		if s.putSizeClasses == nil {
+			s.putSizeClasses = newSizeClassStats()
+		}
+		sc := s.putSizeClasses[sizeClass(r.BytesUploaded)]
+		sc.Requests++
+		sc.Bytes += r.BytesUploaded
+		sc.ttlbs.Record(r.TTLB)
+	}
+}
+
+// calculateSizeClassStats computes per-class throughput and latency. Called from Calculate.
+func (s *Stats) calculateSizeClassStats() {
+	for _, sc := range append(s.sizeClasses, s.putSizeClasses...) {
+		if sc.Requests == 0 {
+			continue
+		}
+		if secs := s.actualDuration.Seconds(); secs > 0 {
+			sc.ThroughputMiB = (float64(sc.Bytes) / (1024 * 1024)) / secs
+		}
+		if sc.ttfbs.Count() > 0 {
+			sc.AvgTTFBMs = ms(sc.ttfbs.Mean())
+			sc.P99TTFBMs = ms(sc.ttfbs.Percentile(99))
+		}
+		sc.AvgTTLBMs = ms(sc.ttlbs.Mean())
+		sc.P50TTLBMs = ms(sc.ttlbs.Percentile(50))
+		sc.P99TTLBMs = ms(sc.ttlbs.Percentile(99))
+	}
+}
+
+// SizeClasses returns GET statistics per object size class, or nil when all successful GETs
+// fell into a single class and the overall GET figures already describe them.
+func (s *Stats) SizeClasses() []*SizeClassStats {
+	return usedSizeClasses(s.sizeClasses)
+}
+
+// PutSizeClasses is SizeClasses for PUTs, which only differ in size with -putsize-dist.
+func (s *Stats) PutSizeClasses() []*SizeClassStats {
+	return usedSizeClasses(s.putSizeClasses)
+}
+
+func usedSizeClasses(classes []*SizeClassStats) []*SizeClassStats {
+	var used []*SizeClassStats
+	for _, sc := range classes {
+		if sc.Requests > 0 {
+			used = append(used, sc)
+		}
+	}
+	if len(used) < 2 {
+		return nil
+	}
+	return used
+}
+
+// printSizeClassSummary prints GET and PUT statistics per object size class as part of PrintSummary.
+func (s *Stats) printSizeClassSummary(w io.Writer) {
+	if classes := s.SizeClasses(); classes != nil {
+		fmt.Fprintf(w, "\nGET by Object Size:\n")
+		fmt.Fprintf(w, "  Size         | Requests |  MiB/s  | TTFB Avg | TTFB P99 | TTLB Avg | TTLB P50 | TTLB P99 (ms)\n")
+		fmt.Fprintf(w, "  -------------|----------|---------|----------|----------|----------|----------|--------------\n")
+		for _, sc := range classes {
+			fmt.Fprintf(w, "  %-12s | %8d | %7.2f | %8.2f | %8.2f | %8.2f | %8.2f | %8.2f\n",
+				sc.Class, sc.Requests, sc.ThroughputMiB, sc.AvgTTFBMs, sc.P99TTFBMs, sc.AvgTTLBMs, sc.P50TTLBMs, sc.P99TTLBMs)
+		}
+	}
+	if classes := s.PutSizeClasses(); classes != nil {
+		fmt.Fprintf(w, "\nPUT by Object Size:\n")
+		fmt.Fprintf(w, "  Size         | Requests |  MiB/s  | TTLB Avg | TTLB P50 | TTLB P99 (ms)\n")
+		fmt.Fprintf(w, "  -------------|----------|---------|----------|----------|--------------\n")
+		for _, sc := range classes {
+			fmt.Fprintf(w, "  %-12s | %8d | %7.2f | %8.2f | %8.2f | %8.2f\n",
+				sc.Class, sc.Requests, sc.ThroughputMiB, sc.AvgTTLBMs, sc.P50TTLBMs, sc.P99TTLBMs)
+		}
+	}
+}
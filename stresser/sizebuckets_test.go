@@ -0,0 +1,89 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSizeClass(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{size: 0, expected: "<128KiB"},
+		{size: 128*1024 - 1, expected: "<128KiB"},
+		{size: 128 * 1024, expected: "128KiB-1MiB"},
+		{size: 4 * 1024 * 1024, expected: "1-16MiB"},
+		{size: 16 * 1024 * 1024, expected: ">=16MiB"},
+	}
+	for _, tt := range tests {
+		if got := sizeClasses[sizeClass(tt.size)].label; got != tt.expected {
+			t.Errorf("sizeClass(%d) = %q, expected %q", tt.size, got, tt.expected)
+		}
+	}
+}
+
+func TestSizeClassStats(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	for i := 0; i < 5; i++ {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "small", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 4096})
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "large", TTFB: time.Millisecond, TTLB: 300 * time.Millisecond, BytesDownloaded: 32 * 1024 * 1024})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "missing", TTFB: -1, TTLB: -1, Error: "not found"})
+	stats.Calculate(now, now.Add(time.Second))
+
+	classes := stats.SizeClasses()
+	if len(classes) != 2 {
+		t.Fatalf("Expected 2 size classes with GETs, got %d", len(classes))
+	}
+	if classes[0].Class != "<128KiB" || classes[0].Requests != 5 || classes[0].P99TTLBMs > 3 {
+		t.Errorf("Unexpected small object stats: %+v", classes[0])
+	}
+	if classes[1].Class != ">=16MiB" || classes[1].Bytes != 5*32*1024*1024 || classes[1].ThroughputMiB != 160 {
+		t.Errorf("Unexpected large object stats: %+v", classes[1])
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "GET by Object Size:") {
+		t.Errorf("Summary is missing the size class section:\n%s", buf.String())
+	}
+}
+
+func TestSizeClassesSingleClass(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", ObjectKey: "k", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 4096})
+	stats.Calculate(now, now.Add(time.Second))
+	if classes := stats.SizeClasses(); classes != nil {
+		t.Errorf("Expected no size class breakdown for uniform sizes, got %d classes", len(classes))
+	}
+}
+
+func TestPutSizeClassStats(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "small", TTFB: 2 * time.Millisecond, TTLB: 2 * time.Millisecond, BytesUploaded: 4096})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "large", TTFB: 80 * time.Millisecond, TTLB: 80 * time.Millisecond, BytesUploaded: 4 * 1024 * 1024})
+	stats.Calculate(now, now.Add(time.Second))
+
+	classes := stats.PutSizeClasses()
+	if len(classes) != 2 || classes[0].Class != "<128KiB" || classes[1].Class != "1-16MiB" {
+		t.Fatalf("Unexpected PUT size classes: %+v", classes)
+	}
+	if classes[1].AvgTTFBMs != 0 || classes[1].AvgTTLBMs < 79 {
+		t.Errorf("Unexpected large PUT stats: %+v", classes[1])
+	}
+	if stats.SizeClasses() != nil {
+		t.Error("PUTs should not show up in the GET size classes")
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "PUT by Object Size:") {
+		t.Errorf("Summary is missing the PUT size class section:\n%s", buf.String())
+	}
+}
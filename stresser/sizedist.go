@@ -0,0 +1,163 @@
+package stresser
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Object size distributions for PUTs (-putsize-dist).
+const (
+	SizeDistFixed     = "fixed"
+	SizeDistUniform   = "uniform"
+	SizeDistLognormal = "lognormal"
+	SizeDistWeighted  = "weighted"
+)
+
+// maxPutSize is the largest object a single S3 PutObject accepts, 5 GiB.
+const maxPutSize = 5 * 1024 * 1024 * 1024
+
+// sizeDistribution draws PUT object sizes, parsed from a spec such as:
+//   - ""                          every object is -putsize KB
+//   - "uniform:4K-64M"            sizes uniformly distributed between the bounds
+//   - "lognormal:1M:1.5"          lognormal with the given median and sigma, the usual shape
+//     of real object size populations; capped at 5 GiB
+//   - "4K:50%,1M:40%,64M:10%"     a weighted list of sizes; weights need not add up to 100
+//
+// Sizes use the suffixes of -size-sweep: K, M and G, bare numbers are KB.
+type sizeDistribution struct {
+	kind     string
+	min, max int64     // Bytes; the fixed size is min
+	sigma    float64   // Lognormal sigma; min holds the median
+	sizes    []int64   // Weighted sizes in bytes
+	weights  []float64 // Percentages of sizes, as given
+	cum      []float64 // Cumulative weights, normalized to 1
+}
+
+// parseSizeDistribution parses a size distribution spec. An empty spec returns a fixed
+// distribution of fixedKB.
+func parseSizeDistribution(spec string, fixedKB int) (sizeDistribution, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return sizeDistribution{kind: SizeDistFixed, min: int64(fixedKB) * 1024}, nil
+	}
+	kind, value, _ := strings.Cut(spec, ":")
+	switch strings.ToLower(kind) {
+	case SizeDistUniform:
+		lo, hi, ok := strings.Cut(value, "-")
+		if !ok {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: expected uniform:<min>-<max>, e.g. uniform:4K-64M", spec)
+		}
+		minSize, err := parseSizeBytes(lo)
+		if err != nil {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: %w", spec, err)
+		}
+		maxSize, err := parseSizeBytes(hi)
+		if err != nil {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: %w", spec, err)
+		}
+		if maxSize < minSize {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: maximum is below minimum", spec)
+		}
+		return sizeDistribution{kind: SizeDistUniform, min: minSize, max: maxSize}, nil
+	case SizeDistLognormal:
+		median, sigma, ok := strings.Cut(value, ":")
+		if !ok {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: expected lognormal:<median>:<sigma>, e.g. lognormal:1M:1.5", spec)
+		}
+		m, err := parseSizeBytes(median)
+		if err != nil {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: %w", spec, err)
+		}
+		s, err := strconv.ParseFloat(strings.TrimSpace(sigma), 64)
+		if err != nil || s <= 0 {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: sigma must be a positive number", spec)
+		}
+		return sizeDistribution{kind: SizeDistLognormal, min: m, sigma: s}, nil
+	}
+
+	d := sizeDistribution{kind: SizeDistWeighted}
+	total := 0.0
+	for _, item := range strings.Split(spec, ",") {
+		size, weight, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: expected uniform:, lognormal: or a list like 4K:50%%,1M:50%%", spec)
+		}
+		b, err := parseSizeBytes(size)
+		if err != nil {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: %w", spec, err)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(weight), "%"), 64)
+		if err != nil || w <= 0 {
+			return sizeDistribution{}, fmt.Errorf("invalid size distribution %q: weight of %s must be a positive number", spec, size)
+		}
+		total += w
+		d.sizes = append(d.sizes, b)
+		d.weights = append(d.weights, w)
+		d.cum = append(d.cum, total)
+	}
+	for i := range d.cum {
+		d.cum[i] /= total
+	}
+	return d, nil
+}
+
+// parseSizeBytes parses one size in the -size-sweep notation and returns it in bytes.
+func parseSizeBytes(s string) (int64, error) {
+	kb, err := ParseSizeList(s)
+	if err != nil || len(kb) != 1 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	b := int64(kb[0]) * 1024
+	if b > maxPutSize {
+		return 0, fmt.Errorf("size %q exceeds the 5 GiB limit of a single PUT", s)
+	}
+	return b, nil
+}
+
+// sample returns the size in bytes of the next object.
+func (d sizeDistribution) sample(r *rand.Rand) int64 {
+	switch d.kind {
+	case SizeDistUniform:
+		return d.min + r.Int63n(d.max-d.min+1)
+	case SizeDistLognormal:
+		size := math.Exp(math.Log(float64(d.min)) + d.sigma*r.NormFloat64())
+		return int64(math.Max(1, math.Min(size, maxPutSize)))
+	case SizeDistWeighted:
+		i := sort.SearchFloat64s(d.cum, r.Float64())
+		return d.sizes[min(i, len(d.sizes)-1)] // Rounding can leave the last cumulative weight just below 1
+	}
+	return d.min
+}
+
+// String renders the distribution in a normalized form of the spec it was parsed from.
+func (d sizeDistribution) String() string {
+	switch d.kind {
+	case SizeDistUniform:
+		return fmt.Sprintf("uniform:%s-%s", formatSizeKB(d.min), formatSizeKB(d.max))
+	case SizeDistLognormal:
+		return fmt.Sprintf("lognormal:%s:%g", formatSizeKB(d.min), d.sigma)
+	case SizeDistWeighted:
+		items := make([]string, len(d.sizes))
+		for i, size := range d.sizes {
+			items[i] = fmt.Sprintf("%s:%g%%", formatSizeKB(size), d.weights[i])
+		}
+		return strings.Join(items, ",")
+	}
+	return ""
+}
+
+// formatSizeKB formats a size in bytes with the largest suffix that divides it.
+func formatSizeKB(b int64) string {
+	switch kb := b / 1024; {
+	case kb%(1024*1024) == 0:
+		return fmt.Sprintf("%dG", kb/(1024*1024))
+	case kb%1024 == 0:
+		return fmt.Sprintf("%dM", kb/1024)
+	default:
+		return fmt.Sprintf("%dK", kb)
+	}
+}
@@ -0,0 +1,63 @@
+package stresser
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParseSizeDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	fixed, err := parseSizeDistribution("", 256)
+	if err != nil || fixed.sample(r) != 256*1024 {
+		t.Errorf("Expected a fixed 256 KB distribution, got %+v (%v)", fixed, err)
+	}
+
+	uniform, err := parseSizeDistribution("uniform:4K-64M", 0)
+	if err != nil {
+		t.Fatalf("parseSizeDistribution failed: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if n := uniform.sample(r); n < 4*1024 || n > 64*1024*1024 {
+			t.Fatalf("Uniform sample %d out of range", n)
+		}
+	}
+
+	weighted, err := parseSizeDistribution("4k:50%, 1M:40%, 64m:10%", 0)
+	if err != nil {
+		t.Fatalf("parseSizeDistribution failed: %v", err)
+	}
+	if s := weighted.String(); s != "4K:50%,1M:40%,64M:10%" {
+		t.Errorf("Unexpected normalized spec %q", s)
+	}
+	counts := map[int64]int{}
+	for i := 0; i < 10000; i++ {
+		counts[weighted.sample(r)]++
+	}
+	if c := counts[4*1024]; c < 4700 || c > 5300 {
+		t.Errorf("Expected about 5000 4K samples, got %d", c)
+	}
+	if c := counts[64*1024*1024]; c < 850 || c > 1150 {
+		t.Errorf("Expected about 1000 64M samples, got %d", c)
+	}
+
+	lognormal, err := parseSizeDistribution("lognormal:1M:1.5", 0)
+	if err != nil {
+		t.Fatalf("parseSizeDistribution failed: %v", err)
+	}
+	below := 0
+	for i := 0; i < 10000; i++ {
+		if lognormal.sample(r) < 1024*1024 {
+			below++
+		}
+	}
+	if below < 4700 || below > 5300 {
+		t.Errorf("Expected about half of the lognormal samples below the median, got %d", below)
+	}
+
+	for _, spec := range []string{"uniform:64M-4K", "uniform:4K", "lognormal:1M", "lognormal:1M:0", "4K:50%,1M", "4K:-1", "6G:100%", "bogus"} {
+		if _, err := parseSizeDistribution(spec, 0); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package stresser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrSLOViolation is returned by CheckSLOs when at least one SLO was not met.
+var ErrSLOViolation = errors.New("SLO violated")
+
+// sloMetric is a figure of a calculated Stats that an SLO can bound.
+type sloMetric struct {
+	value   func(s *Stats) float64
+	minimum bool // The threshold is a lower bound, e.g. for throughput
+}
+
+// sloMetrics are the SLO names accepted in the slos config section and -slo.
+var sloMetrics = map[string]sloMetric{
+	"p50GetTtfbMs":          {value: func(s *Stats) float64 { return ms(s.P50GetTTFB) }},
+	"p90GetTtfbMs":          {value: func(s *Stats) float64 { return ms(s.P90GetTTFB) }},
+	"p99GetTtfbMs":          {value: func(s *Stats) float64 { return ms(s.P99GetTTFB) }},
+	"p999GetTtfbMs":         {value: func(s *Stats) float64 { return ms(s.P999GetTTFB) }},
+	"p50GetTtlbMs":          {value: func(s *Stats) float64 { return ms(s.P50GetTTLB) }},
+	"p90GetTtlbMs":          {value: func(s *Stats) float64 { return ms(s.P90GetTTLB) }},
+	"p99GetTtlbMs":          {value: func(s *Stats) float64 { return ms(s.P99GetTTLB) }},
+	"p999GetTtlbMs":         {value: func(s *Stats) float64 { return ms(s.P999GetTTLB) }},
+	"p50PutTtlbMs":          {value: func(s *Stats) float64 { return ms(s.P50PutTTLB) }},
+	"p90PutTtlbMs":          {value: func(s *Stats) float64 { return ms(s.P90PutTTLB) }},
+	"p99PutTtlbMs":          {value: func(s *Stats) float64 { return ms(s.P99PutTTLB) }},
+	"p999PutTtlbMs":         {value: func(s *Stats) float64 { return ms(s.P999PutTTLB) }},
+	"p99HeadTtlbMs":         {value: func(s *Stats) float64 { return ms(s.P99HeadTTLB) }},
+	"errorRatePct":          {value: func(s *Stats) float64 { return percentOf(s.TotalErrors, s.TotalRequests) }},
+	"minRequestsPerSec":     {value: func(s *Stats) float64 { return s.rate(float64(s.TotalRequests)) }, minimum: true},
+	"minGetThroughputMiBps": {value: func(s *Stats) float64 { return s.rate(float64(s.TotalBytesDown) / (1024 * 1024)) }, minimum: true},
+	"minPutThroughputMiBps": {value: func(s *Stats) float64 { return s.rate(float64(s.TotalBytesUp) / (1024 * 1024)) }, minimum: true},
+}
+
+func percentOf(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// rate returns v per second of the measured run.
+func (s *Stats) rate(v float64) float64 {
+	if secs := s.actualDuration.Seconds(); secs > 0 {
+		return v / secs
+	}
+	return 0
+}
+
+// SLOResult is the outcome of one SLO.
+type SLOResult struct {
+	Name      string  `json:"name" yaml:"name"`
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+	Actual    float64 `json:"actual" yaml:"actual"`
+	Pass      bool    `json:"pass" yaml:"pass"`
+}
+
+// ParseSLOs parses a comma-separated list of name=threshold pairs, as given to -slo.
+func ParseSLOs(spec string) (map[string]float64, error) {
+	slos := make(map[string]float64)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid SLO %q: expected name=threshold", item)
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLO threshold in %q: %w", item, err)
+		}
+		slos[strings.TrimSpace(name)] = threshold
+	}
+	return slos, nil
+}
+
+// validateSLOs checks that every SLO names a known metric. Called from Validate.
+func validateSLOs(slos map[string]float64) error {
+	for name, threshold := range slos {
+		if _, ok := sloMetrics[name]; !ok {
+			names := make([]string, 0, len(sloMetrics))
+			for n := range sloMetrics {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown SLO %q (supported: %s)", name, strings.Join(names, ", "))
+		}
+		if threshold < 0 {
+			return fmt.Errorf("SLO %s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// SLOResults evaluates the SLOs of the run, ordered by name. It returns nil if none are set.
+func (s *Stats) SLOResults() []SLOResult {
+	if len(s.SLOs) == 0 {
+		return nil
+	}
+	results := make([]SLOResult, 0, len(s.SLOs))
+	for name, threshold := range s.SLOs {
+		metric, ok := sloMetrics[name]
+		if !ok {
+			continue // Rejected by Validate
+		}
+		r := SLOResult{Name: name, Threshold: threshold, Actual: metric.value(s)}
+		if metric.minimum {
+			r.Pass = r.Actual >= threshold
+		} else {
+			r.Pass = r.Actual <= threshold
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// CheckSLOs returns an error wrapping ErrSLOViolation listing the SLOs the run missed.
+func (s *Stats) CheckSLOs() error {
+	var failed []string
+	for _, r := range s.SLOResults() {
+		if !r.Pass {
+			failed = append(failed, fmt.Sprintf("%s %.3f (limit %g)", r.Name, r.Actual, r.Threshold))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", ErrSLOViolation, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// printSLOSummary prints the outcome of every SLO as part of PrintSummary.
+func (s *Stats) printSLOSummary(w io.Writer) {
+	results := s.SLOResults()
+	if results == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nSLOs:\n")
+	for _, r := range results {
+		status, op := "PASS", "<="
+		if !r.Pass {
+			status = "FAIL"
+		}
+		if sloMetrics[r.Name].minimum {
+			op = ">="
+		}
+		fmt.Fprintf(w, "  %s  %-22s %10.3f %s %g\n", status, r.Name, r.Actual, op, r.Threshold)
+	}
+}
@@ -0,0 +1,90 @@
+package stresser
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSLOs(t *testing.T) {
+	slos, err := ParseSLOs("p99GetTtfbMs=100, errorRatePct=0.5")
+	if err != nil {
+		t.Fatalf("ParseSLOs failed: %v", err)
+	}
+	if len(slos) != 2 || slos["p99GetTtfbMs"] != 100 || slos["errorRatePct"] != 0.5 {
+		t.Errorf("Unexpected SLOs: %v", slos)
+	}
+	for _, spec := range []string{"p99GetTtfbMs", "p99GetTtfbMs=fast"} {
+		if _, err := ParseSLOs(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+	if err := validateSLOs(map[string]float64{"p99Magic": 1}); err == nil {
+		t.Error("Expected error for unknown SLO")
+	}
+}
+
+func TestValidateMergesSLOSpec(t *testing.T) {
+	cfg := &Config{
+		Endpoint:      "https://test-endpoint.com",
+		Region:        "us-east-1",
+		Bucket:        "test-bucket",
+		Duration:      "30s",
+		Concurrency:   5,
+		ManifestPath:  "manifest.txt",
+		OutputFile:    "results.csv",
+		OperationType: "read",
+		SLOs:          map[string]float64{"p99GetTtfbMs": 200, "errorRatePct": 1},
+		SLOSpec:       "p99GetTtfbMs=100",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if cfg.SLOs["p99GetTtfbMs"] != 100 || cfg.SLOs["errorRatePct"] != 1 {
+		t.Errorf("Expected -slo to override YAML SLOs, got %v", cfg.SLOs)
+	}
+}
+
+func TestSLOResults(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	stats.SLOs = map[string]float64{"p99GetTtfbMs": 50, "errorRatePct": 10, "minRequestsPerSec": 5}
+	for i := 0; i < 9; i++ {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: 80 * time.Millisecond, TTLB: 90 * time.Millisecond})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: -1, TTLB: -1, Error: "timeout"})
+	stats.Calculate(now, now.Add(time.Second))
+
+	results := stats.SLOResults()
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 SLO results, got %+v", results)
+	}
+	pass := map[string]bool{}
+	for _, r := range results {
+		pass[r.Name] = r.Pass
+	}
+	if !pass["errorRatePct"] || pass["p99GetTtfbMs"] || !pass["minRequestsPerSec"] {
+		t.Errorf("Unexpected SLO outcomes: %+v", results)
+	}
+	err := stats.CheckSLOs()
+	if !errors.Is(err, ErrSLOViolation) || !strings.Contains(err.Error(), "p99GetTtfbMs") {
+		t.Errorf("Expected a p99GetTtfbMs violation, got %v", err)
+	}
+	if stats.ResultLine().Status != "fail" {
+		t.Error("Expected the result line to fail on a missed SLO")
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "FAIL  p99GetTtfbMs") {
+		t.Errorf("Summary lacks the SLO section:\n%s", buf.String())
+	}
+
+	// Errors within the error budget don't fail the result line when SLOs are set
+	stats.SLOs = map[string]float64{"errorRatePct": 10}
+	if stats.ResultLine().Status != "pass" {
+		t.Error("Expected the result line to pass when all SLOs are met")
+	}
+}
@@ -0,0 +1,140 @@
+package stresser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// validateSlowLog checks -slow-threshold and -slow-log.
+func (c *Config) validateSlowLog() error {
+	if c.SlowThreshold == "" {
+		if c.SlowLog != "" {
+			return fmt.Errorf("a slow log (-slow-log) requires a slow request threshold (-slow-threshold)")
+		}
+		return nil
+	}
+	if d, err := time.ParseDuration(c.SlowThreshold); err != nil || d <= 0 {
+		return fmt.Errorf("invalid slow request threshold (-slow-threshold) %q: must be a positive duration", c.SlowThreshold)
+	}
+	if c.SlowLog != "" && (c.SizeSweep != "" || c.ConcurrencySweep != "") {
+		return fmt.Errorf("a slow log (-slow-log) is not supported with sweeps")
+	}
+	return nil
+}
+
+// slowLog reports requests that took longer than a threshold with their full context as
+// they are collected, so tail outliers can be investigated while the run is still going.
+// They are logged as warnings and optionally written to a file as JSON lines in the
+// format of the detailed results. It is only used from the collecting goroutine.
+type slowLog struct {
+	threshold time.Duration
+	log       bool // Log slow requests, not only write them to the file
+	path      string
+	out       io.WriteCloser
+	buf       *bufio.Writer
+	json      *json.Encoder
+	count     int
+}
+
+// newSlowLog returns the slow log of cfg, nil without a threshold. The file, if any, is
+// created right away.
+func newSlowLog(cfg *Config) (*slowLog, error) {
+	if cfg.SlowThreshold == "" {
+		return nil, nil
+	}
+	threshold, _ := time.ParseDuration(cfg.SlowThreshold) // Already validated in Config.Validate
+	l := &slowLog{threshold: threshold, log: true, path: cfg.SlowLog}
+	if cfg.SlowLog != "" {
+		out, err := OpenOutput(cfg.SlowLog)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create slow log: %w", err)
+		}
+		l.out = out
+		l.buf = bufio.NewWriter(out)
+		l.json = json.NewEncoder(l.buf)
+	}
+	return l, nil
+}
+
+// add reports r if it took longer than the threshold.
+func (l *slowLog) add(r Result) {
+	if l == nil || r.TTLB <= l.threshold {
+		return
+	}
+	l.count++
+	if l.log {
+		slog.Warn("Slow request", slowRequestAttrs(r)...)
+	}
+	if l.json == nil {
+		return
+	}
+	if err := l.json.Encode(NewResultRecord(r)); err != nil {
+		slog.Error("Failed to write slow log", "error", err, "file", l.path)
+		return
+	}
+	// Written through right away, the point is to see slow requests during the run
+	if err := l.buf.Flush(); err != nil {
+		slog.Error("Failed to write slow log", "error", err, "file", l.path)
+	}
+}
+
+// close closes the file of the slow log and logs how many slow requests it reported.
+func (l *slowLog) close() error {
+	if l == nil {
+		return nil
+	}
+	slog.Info("Slow requests", "count", l.count, "threshold", l.threshold, "file", l.path)
+	if l.out == nil {
+		return nil
+	}
+	err := l.buf.Flush()
+	if cerr := l.out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// slowRequestAttrs returns the log attributes of a slow request, leaving out the ones that
+// don't apply to it.
+func slowRequestAttrs(r Result) []any {
+	attrs := []any{"op", r.Operation, "key", r.ObjectKey, "ttlbMs", ms(r.TTLB)}
+	if r.Bucket != "" {
+		attrs = append(attrs, "bucket", r.Bucket)
+	}
+	if r.Endpoint != "" {
+		attrs = append(attrs, "endpoint", r.Endpoint)
+	}
+	if r.TTFB >= 0 {
+		attrs = append(attrs, "ttfbMs", ms(r.TTFB))
+	}
+	attrs = append(attrs, "connWaitMs", ms(r.ConnWait))
+	if !r.ConnReused {
+		attrs = append(attrs, "dnsMs", ms(r.DNS), "connectMs", ms(r.Connect), "tlsMs", ms(r.TLSHandshake))
+	}
+	if r.FirstByte > 0 {
+		attrs = append(attrs, "firstByteMs", ms(r.FirstByte))
+	}
+	if r.RemoteAddr != "" {
+		attrs = append(attrs, "remoteAddr", r.RemoteAddr, "reused", r.ConnReused)
+	}
+	if r.Attempts > 0 {
+		attrs = append(attrs, "attempts", r.Attempts)
+	}
+	if r.StatusCode != 0 {
+		attrs = append(attrs, "status", r.StatusCode)
+	}
+	if r.RequestID != "" {
+		attrs = append(attrs, "requestId", r.RequestID)
+	}
+	if r.HostID != "" {
+		attrs = append(attrs, "hostId", r.HostID)
+	}
+	if r.Error != "" {
+		attrs = append(attrs, "error", r.Error)
+	}
+	return attrs
+}
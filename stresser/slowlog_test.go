@@ -0,0 +1,80 @@
+package stresser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.jsonl")
+	slow, err := newSlowLog(&Config{SlowThreshold: "2s", SlowLog: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow.add(Result{Operation: "GET", ObjectKey: "fast.dat", TTFB: time.Second, TTLB: 2 * time.Second})
+	slow.add(Result{Operation: "PUT", ObjectKey: "slow.dat", TTFB: -1, TTLB: 3 * time.Second, RequestID: "REQ1", HostID: "HOST1", StatusCode: 503, Error: "SlowDown"})
+
+	// Written through before the log is closed
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the request slower than the threshold, got %q", data)
+	}
+	var rec ResultRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.ObjectKey != "slow.dat" || rec.TTLBMs != 3000 || rec.RequestID != "REQ1" || rec.HostID != "HOST1" || rec.StatusCode != 503 {
+		t.Errorf("Unexpected slow log record %+v", rec)
+	}
+	if err := slow.close(); err != nil {
+		t.Error(err)
+	}
+	if slow.count != 1 {
+		t.Errorf("Expected 1 slow request, counted %d", slow.count)
+	}
+
+	if slow, err := newSlowLog(&Config{}); slow != nil || err != nil {
+		t.Errorf("Expected no slow log without a threshold, got %v (%v)", slow, err)
+	}
+}
+
+func TestSlowRequestAttrs(t *testing.T) {
+	attrs := slowRequestAttrs(Result{Operation: "PUT", ObjectKey: "k", TTFB: -1, TTLB: time.Second, ConnReused: true, RequestID: "REQ1"})
+	got := make(map[string]any)
+	for i := 0; i < len(attrs); i += 2 {
+		got[attrs[i].(string)] = attrs[i+1]
+	}
+	for _, absent := range []string{"ttfbMs", "dnsMs", "hostId", "error"} {
+		if _, ok := got[absent]; ok {
+			t.Errorf("Unexpected attribute %s in %v", absent, attrs)
+		}
+	}
+	if got["key"] != "k" || got["ttlbMs"] != 1000.0 || got["requestId"] != "REQ1" {
+		t.Errorf("Unexpected attributes %v", attrs)
+	}
+}
+
+func TestValidateSlowLog(t *testing.T) {
+	for _, c := range []Config{
+		{SlowLog: "slow.jsonl"},
+		{SlowThreshold: "slow"},
+		{SlowThreshold: "0s"},
+		{SlowThreshold: "2s", SlowLog: "slow.jsonl", SizeSweep: "4K,1M"},
+	} {
+		if err := c.validateSlowLog(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+	c := Config{SlowThreshold: "500ms", SlowLog: "slow.jsonl"}
+	if err := c.validateSlowLog(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,299 @@
+package stresser
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" driver
+)
+
+// sqlSchema creates the tables of a results database. Every row carries the run id, so
+// several runs can be loaded into one database and compared with SQL.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS runs (
+  run_id TEXT PRIMARY KEY,
+  started_at TEXT,
+  duration_s REAL,
+  concurrency INTEGER,
+  total_requests INTEGER,
+  total_errors INTEGER,
+  requests_per_sec REAL,
+  summary_json TEXT
+);
+CREATE TABLE IF NOT EXISTS results (
+  run_id TEXT NOT NULL,
+  timestamp TEXT NOT NULL,
+  operation TEXT NOT NULL,
+  object_key TEXT NOT NULL,
+  ttfb_ms REAL,
+  ttlb_ms REAL,
+  bytes_downloaded INTEGER,
+  bytes_uploaded INTEGER,
+  error TEXT,
+  request_id TEXT,
+  attempts INTEGER,
+  remote_addr TEXT,
+  conn_reused INTEGER,
+  conn_wait_ms REAL,
+  hedged INTEGER,
+  hedge_won INTEGER
+);
+CREATE INDEX IF NOT EXISTS results_run_op ON results (run_id, operation);
+CREATE INDEX IF NOT EXISTS results_run_time ON results (run_id, timestamp);
+CREATE INDEX IF NOT EXISTS results_key ON results (object_key);
+CREATE TABLE IF NOT EXISTS intervals (
+  run_id TEXT NOT NULL,
+  interval_start TEXT NOT NULL,
+  operation TEXT NOT NULL,
+  requests INTEGER,
+  errors INTEGER,
+  bytes INTEGER,
+  p50_ttlb_ms REAL,
+  p99_ttlb_ms REAL,
+  PRIMARY KEY (run_id, interval_start, operation)
+);
+`
+
+// sqlInterval is the length of the interval aggregates in the intervals table.
+const sqlInterval = time.Second
+
+// encodeResultsSQL writes a SQLite script that creates the results database schema and
+// inserts the run summary, per-second interval aggregates and every result. There is no
+// SQLite driver in the standard library, so the script is meant to be piped into sqlite3.
+func encodeResultsSQL(w io.Writer, results []Result, s *Stats) error {
+	bw := bufio.NewWriter(w)
+	runID := ""
+	if s != nil {
+		runID = s.RunID
+	}
+
+	fmt.Fprintf(bw, "BEGIN TRANSACTION;\n%s", sqlSchema)
+
+	if s != nil {
+		sum := s.Summary()
+		summaryJSON, err := json.Marshal(sum)
+		if err != nil {
+			return fmt.Errorf("failed to encode summary: %w", err)
+		}
+		fmt.Fprintf(bw, "INSERT OR REPLACE INTO runs VALUES (%s, %s, %s, %d, %d, %d, %s, %s);\n",
+			sqlText(runID), sqlTime(s.startTime), sqlFloat(sum.DurationSeconds), sum.Concurrency,
+			sum.TotalRequests, sum.TotalErrors, sqlFloat(sum.RequestsPerSec), sqlText(string(summaryJSON)))
+	}
+
+	for _, iv := range intervalAggregates(results) {
+		fmt.Fprintf(bw, "INSERT OR REPLACE INTO intervals VALUES (%s, %s, %s, %d, %d, %d, %s, %s);\n",
+			sqlText(runID), sqlTime(iv.start), sqlText(iv.operation), iv.requests, iv.errors, iv.bytes,
+			sqlLatency(iv.p50), sqlLatency(iv.p99))
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(bw, "INSERT INTO results VALUES (%s, %s, %s, %s, %s, %s, %d, %d, %s, %s, %d, %s, %s, %s, %s, %s);\n",
+			sqlText(runID), sqlTime(r.Timestamp), sqlText(r.Operation), sqlText(r.ObjectKey),
+			sqlLatency(r.TTFB), sqlLatency(r.TTLB), r.BytesDownloaded, r.BytesUploaded,
+			sqlNullText(r.Error), sqlNullText(r.RequestID), r.Attempts, sqlNullText(r.RemoteAddr),
+			sqlBool(r.ConnReused), sqlLatency(r.ConnWait), sqlBool(r.Hedged), sqlBool(r.HedgeWon))
+	}
+
+	fmt.Fprintf(bw, "COMMIT;\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write sql output: %w", err)
+	}
+	return nil
+}
+
+// writeResultsSQLite writes the results database with the same tables and rows as
+// encodeResultsSQL directly to the SQLite file at path. An existing database is added to,
+// replacing the rows of an earlier run with the same run id, so one file can collect runs.
+func writeResultsSQLite(path string, results []Result, s *Stats) error {
+	if path == StdoutPath {
+		return fmt.Errorf("sqlite output needs a file, use %q to write a script to stdout", FormatSQL)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+	defer tx.Rollback()
+
+	runID := ""
+	if s != nil {
+		runID = s.RunID
+	}
+	if _, err := tx.Exec(sqlSchema); err != nil {
+		return fmt.Errorf("failed to create sqlite schema: %w", err)
+	}
+	for _, table := range []string{"results", "intervals"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE run_id = ?", runID); err != nil {
+			return fmt.Errorf("failed to replace earlier rows of run %q: %w", runID, err)
+		}
+	}
+
+	if s != nil {
+		sum := s.Summary()
+		summaryJSON, err := json.Marshal(sum)
+		if err != nil {
+			return fmt.Errorf("failed to encode summary: %w", err)
+		}
+		if _, err := tx.Exec("INSERT OR REPLACE INTO runs VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			runID, sqliteTime(s.startTime), sum.DurationSeconds, sum.Concurrency,
+			sum.TotalRequests, sum.TotalErrors, sum.RequestsPerSec, string(summaryJSON)); err != nil {
+			return fmt.Errorf("failed to insert run: %w", err)
+		}
+	}
+
+	for _, iv := range intervalAggregates(results) {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO intervals VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			runID, sqliteTime(iv.start), iv.operation, iv.requests, iv.errors, iv.bytes,
+			sqliteLatency(iv.p50), sqliteLatency(iv.p99)); err != nil {
+			return fmt.Errorf("failed to insert interval: %w", err)
+		}
+	}
+
+	insert, err := tx.Prepare("INSERT INTO results VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare result insert: %w", err)
+	}
+	defer insert.Close()
+	for _, r := range results {
+		if _, err := insert.Exec(runID, sqliteTime(r.Timestamp), r.Operation, r.ObjectKey,
+			sqliteLatency(r.TTFB), sqliteLatency(r.TTLB), r.BytesDownloaded, r.BytesUploaded,
+			sqliteNullText(r.Error), sqliteNullText(r.RequestID), r.Attempts, sqliteNullText(r.RemoteAddr),
+			r.ConnReused, sqliteLatency(r.ConnWait), r.Hedged, r.HedgeWon); err != nil {
+			return fmt.Errorf("failed to insert result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write sqlite database %s: %w", path, err)
+	}
+	return nil
+}
+
+// intervalAggregate summarizes the requests of one operation that started in one interval.
+type intervalAggregate struct {
+	start     time.Time
+	operation string
+	requests  int64
+	errors    int64
+	bytes     int64
+	ttlbs     []time.Duration
+	p50, p99  time.Duration
+}
+
+// intervalAggregates groups results into sqlInterval buckets per operation, ordered by time.
+func intervalAggregates(results []Result) []*intervalAggregate {
+	type bucketKey struct {
+		start     time.Time
+		operation string
+	}
+	buckets := make(map[bucketKey]*intervalAggregate)
+	for _, r := range results {
+		key := bucketKey{r.Timestamp.UTC().Truncate(sqlInterval), r.Operation}
+		iv, ok := buckets[key]
+		if !ok {
+			iv = &intervalAggregate{start: key.start, operation: key.operation, p50: -1, p99: -1}
+			buckets[key] = iv
+		}
+		iv.requests++
+		iv.bytes += r.BytesDownloaded + r.BytesUploaded
+		if r.Error != "" {
+			iv.errors++
+			continue
+		}
+		iv.ttlbs = append(iv.ttlbs, r.TTLB)
+	}
+
+	list := make([]*intervalAggregate, 0, len(buckets))
+	for _, iv := range buckets {
+		if len(iv.ttlbs) > 0 {
+			sortDurations(iv.ttlbs)
+			iv.p50 = percentileDuration(iv.ttlbs, 50)
+			iv.p99 = percentileDuration(iv.ttlbs, 99)
+		}
+		list = append(list, iv)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].start.Equal(list[j].start) {
+			return list[i].start.Before(list[j].start)
+		}
+		return list[i].operation < list[j].operation
+	})
+	return list
+}
+
+// sqlText quotes s as a SQL string literal.
+func sqlText(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlNullText quotes s, or returns NULL if it is empty.
+func sqlNullText(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return sqlText(s)
+}
+
+// sqlTime formats t as an ISO 8601 string, which SQLite's date functions understand.
+func sqlTime(t time.Time) string {
+	if t.IsZero() {
+		return "NULL"
+	}
+	return sqlText(isoTime(t))
+}
+
+func isoTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
+
+// sqlLatency returns d in milliseconds, or NULL for unmeasured (negative) latencies.
+func sqlLatency(d time.Duration) string {
+	if d < 0 {
+		return "NULL"
+	}
+	return sqlFloat(ms(d))
+}
+
+func sqlFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func sqlBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// sqliteTime, sqliteLatency and sqliteNullText are the query arguments matching sqlTime,
+// sqlLatency and sqlNullText, nil standing for NULL.
+func sqliteTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return isoTime(t)
+}
+
+func sqliteLatency(d time.Duration) any {
+	if d < 0 {
+		return nil
+	}
+	return ms(d)
+}
+
+func sqliteNullText(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
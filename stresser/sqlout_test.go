@@ -0,0 +1,106 @@
+package stresser
+
+import (
+	"bytes"
+	"database/sql"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sqlTestRun returns the results and calculated stats of a small run with id runID.
+func sqlTestRun(runID string) ([]Result, *Stats) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	results := []Result{
+		{Timestamp: start, Operation: "GET", ObjectKey: "it's.txt", TTFB: 5 * time.Millisecond, TTLB: 10 * time.Millisecond, BytesDownloaded: 1024},
+		{Timestamp: start.Add(100 * time.Millisecond), Operation: "GET", ObjectKey: "b", TTFB: 5 * time.Millisecond, TTLB: 30 * time.Millisecond, BytesDownloaded: 1024},
+		{Timestamp: start.Add(1500 * time.Millisecond), Operation: "PUT", ObjectKey: "c", TTFB: -1, TTLB: -1, Error: "denied"},
+	}
+	stats := NewStats()
+	stats.RunID = runID
+	for _, r := range results {
+		stats.AddResult(r)
+	}
+	stats.Calculate(start, start.Add(2*time.Second))
+	return results, stats
+}
+
+func TestEncodeResultsSQL(t *testing.T) {
+	results, stats := sqlTestRun("run1")
+
+	var buf bytes.Buffer
+	if err := encodeResultsSQL(&buf, results, stats); err != nil {
+		t.Fatalf("encodeResultsSQL failed: %v", err)
+	}
+	script := buf.String()
+	for _, want := range []string{
+		"INSERT INTO results VALUES ('run1', '2025-01-01T12:00:00.000000Z', 'GET', 'it''s.txt', 5, 10, 1024,",
+		"INSERT OR REPLACE INTO intervals VALUES ('run1', '2025-01-01T12:00:00.000000Z', 'GET', 2, 0, 2048, 10, 30);",
+		"INSERT OR REPLACE INTO intervals VALUES ('run1', '2025-01-01T12:00:01.000000Z', 'PUT', 1, 1, 0, NULL, NULL);",
+		"'c', NULL, NULL, 0, 0, 'denied',",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script lacks %q", want)
+		}
+	}
+
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 not installed, not loading the script")
+	}
+	cmd := exec.Command(sqlite, ":memory:")
+	cmd.Stdin = strings.NewReader(script + "SELECT count(*) FROM results; SELECT total_requests FROM runs WHERE run_id = 'run1';\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("sqlite3 rejected the script: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(string(out)) != "3\n3" {
+		t.Errorf("unexpected query output %q", out)
+	}
+}
+
+func TestWriteResultsSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.db")
+	results, stats := sqlTestRun("run1")
+	// Writing a run twice replaces it, a second run is added next to it
+	for _, runID := range []string{"run1", "run1", "run2"} {
+		stats.RunID = runID
+		if err := WriteResultsWithStats(results, stats, path, FormatSQLite); err != nil {
+			t.Fatalf("WriteResultsWithStats failed: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count, runs int
+	if err := db.QueryRow("SELECT count(*) FROM results WHERE run_id = 'run1'").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SELECT count(*) FROM runs").Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || runs != 2 {
+		t.Errorf("Expected 3 results of run1 and 2 runs, got %d and %d", count, runs)
+	}
+	var requests, errors int
+	var p99 sql.NullFloat64
+	if err := db.QueryRow("SELECT requests, errors, p99_ttlb_ms FROM intervals WHERE run_id = 'run2' AND operation = 'PUT'").Scan(&requests, &errors, &p99); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || errors != 1 || p99.Valid {
+		t.Errorf("Unexpected PUT interval: %d requests, %d errors, p99 %v", requests, errors, p99)
+	}
+	var index string
+	if err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'index' AND name = 'results_run_op'").Scan(&index); err != nil {
+		t.Errorf("results index missing: %v", err)
+	}
+
+	if err := WriteResultsWithStats(results, stats, StdoutPath, FormatSQLite); err == nil {
+		t.Error("Expected an error writing a database to stdout")
+	}
+}
@@ -0,0 +1,132 @@
+package stresser
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Server-side encryption modes (Config.SSE).
+const (
+	SSENone = "none"
+	SSES3   = "sse-s3"  // Keys managed by the store (AES256)
+	SSEKMS  = "sse-kms" // Keys managed by KMS (aws:kms)
+	SSEC    = "sse-c"   // Key provided by the client with every request
+)
+
+// sseCustomerAlgorithm is the only algorithm S3 accepts for customer-provided keys.
+const sseCustomerAlgorithm = "AES256"
+
+// serverSideEncryption holds the encryption parameters added to requests.
+type serverSideEncryption struct {
+	mode     string
+	kmsKeyID string
+	key      string // Base64-encoded customer key
+	keyMD5   string // Base64-encoded MD5 of the raw customer key
+}
+
+// parseSSECustomerKey checks that key is a base64-encoded 256-bit key and returns the
+// base64-encoded MD5 of it, which S3 uses to check the key was not garbled in transit.
+func parseSSECustomerKey(key string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("customer key must be base64 encoded: %w", err)
+	}
+	if len(raw) != 32 {
+		return "", fmt.Errorf("customer key must be 256 bits, got %d", len(raw)*8)
+	}
+	sum := md5.Sum(raw)
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+// withServerSideEncryption returns an SDK API option that encrypts written objects with
+// the mode of cfg. With SSE-C the customer key is also sent with every request that reads
+// an object, since the store cannot decrypt it otherwise. The parameters are set on the
+// operation input, before serialization, so they are signed like any other header.
+func withServerSideEncryption(cfg *Config) (func(*middleware.Stack) error, error) {
+	sse := serverSideEncryption{mode: cfg.SSE, kmsKeyID: cfg.SSEKMSKeyID}
+	if sse.mode == SSEC {
+		keyMD5, err := parseSSECustomerKey(cfg.SSECustomerKey)
+		if err != nil {
+			return nil, err
+		}
+		sse.key, sse.keyMD5 = cfg.SSECustomerKey, keyMD5
+	}
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("StresserServerSideEncryption",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				in.Parameters = sse.apply(in.Parameters)
+				return next.HandleInitialize(ctx, in)
+			}), middleware.After)
+	}, nil
+}
+
+// apply returns a copy of the operation input params with the encryption parameters set.
+// Inputs of operations that carry no encryption parameters are returned as they are.
+func (e serverSideEncryption) apply(params any) any {
+	switch p := params.(type) {
+	case *s3.PutObjectInput:
+		in := *p
+		in.ServerSideEncryption, in.SSEKMSKeyId = e.algorithm(), e.kmsKey()
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customerKey()
+		return &in
+	case *s3.CreateMultipartUploadInput:
+		in := *p
+		in.ServerSideEncryption, in.SSEKMSKeyId = e.algorithm(), e.kmsKey()
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customerKey()
+		return &in
+	case *s3.CopyObjectInput:
+		in := *p
+		in.ServerSideEncryption, in.SSEKMSKeyId = e.algorithm(), e.kmsKey()
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customerKey()
+		in.CopySourceSSECustomerAlgorithm, in.CopySourceSSECustomerKey, in.CopySourceSSECustomerKeyMD5 = e.customerKey()
+		return &in
+	case *s3.UploadPartCopyInput:
+		in := *p
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customerKey()
+		in.CopySourceSSECustomerAlgorithm, in.CopySourceSSECustomerKey, in.CopySourceSSECustomerKeyMD5 = e.customerKey()
+		return &in
+	case *s3.GetObjectInput:
+		in := *p
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customerKey()
+		return &in
+	case *s3.HeadObjectInput:
+		in := *p
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customerKey()
+		return &in
+	}
+	return params
+}
+
+// algorithm returns the x-amz-server-side-encryption value, "" for SSE-C.
+func (e serverSideEncryption) algorithm() types.ServerSideEncryption {
+	switch e.mode {
+	case SSES3:
+		return types.ServerSideEncryptionAes256
+	case SSEKMS:
+		return types.ServerSideEncryptionAwsKms
+	}
+	return ""
+}
+
+// kmsKey returns the KMS key id for SSE-KMS, nil for the default key or other modes.
+func (e serverSideEncryption) kmsKey() *string {
+	if e.mode != SSEKMS || e.kmsKeyID == "" {
+		return nil
+	}
+	return aws.String(e.kmsKeyID)
+}
+
+// customerKey returns the algorithm, key and key MD5 for SSE-C, nils for other modes.
+func (e serverSideEncryption) customerKey() (*string, *string, *string) {
+	if e.mode != SSEC {
+		return nil, nil, nil
+	}
+	return aws.String(sseCustomerAlgorithm), aws.String(e.key), aws.String(e.keyMD5)
+}
@@ -0,0 +1,99 @@
+package stresser
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var testCustomerKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+
+func TestParseSSECustomerKey(t *testing.T) {
+	keyMD5, err := parseSSECustomerKey(testCustomerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := base64.StdEncoding.DecodeString(keyMD5); err != nil || len(raw) != 16 {
+		t.Errorf("Expected a base64-encoded MD5, got %q", keyMD5)
+	}
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := parseSSECustomerKey(key); err == nil {
+			t.Errorf("Expected an error for %q", key)
+		}
+	}
+}
+
+func TestServerSideEncryptionApply(t *testing.T) {
+	put := &s3.PutObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}
+
+	kms := serverSideEncryption{mode: SSEKMS, kmsKeyID: "key-1"}
+	in := kms.apply(put).(*s3.PutObjectInput)
+	if in.ServerSideEncryption != types.ServerSideEncryptionAwsKms || aws.ToString(in.SSEKMSKeyId) != "key-1" || in.SSECustomerKey != nil {
+		t.Errorf("Unexpected sse-kms input: %+v", in)
+	}
+	if put.ServerSideEncryption != "" {
+		t.Error("apply modified the caller's input")
+	}
+	if got := kms.apply(&s3.GetObjectInput{}).(*s3.GetObjectInput); got.SSECustomerKey != nil {
+		t.Error("Expected no key on GETs with sse-kms")
+	}
+
+	s3Managed := serverSideEncryption{mode: SSES3}
+	if in := s3Managed.apply(put).(*s3.PutObjectInput); in.ServerSideEncryption != types.ServerSideEncryptionAes256 || in.SSEKMSKeyId != nil {
+		t.Errorf("Unexpected sse-s3 input: %+v", in)
+	}
+
+	keyMD5, _ := parseSSECustomerKey(testCustomerKey)
+	customer := serverSideEncryption{mode: SSEC, key: testCustomerKey, keyMD5: keyMD5}
+	in = customer.apply(put).(*s3.PutObjectInput)
+	if in.ServerSideEncryption != "" || aws.ToString(in.SSECustomerAlgorithm) != "AES256" || aws.ToString(in.SSECustomerKeyMD5) != keyMD5 {
+		t.Errorf("Unexpected sse-c input: %+v", in)
+	}
+	get := customer.apply(&s3.GetObjectInput{}).(*s3.GetObjectInput)
+	if aws.ToString(get.SSECustomerKey) != testCustomerKey {
+		t.Error("Expected the customer key on GETs with sse-c")
+	}
+	cp := customer.apply(&s3.CopyObjectInput{}).(*s3.CopyObjectInput)
+	if aws.ToString(cp.CopySourceSSECustomerKey) != testCustomerKey {
+		t.Error("Expected the customer key for the copy source with sse-c")
+	}
+
+	list := &s3.ListObjectsV2Input{}
+	if customer.apply(list) != any(list) {
+		t.Error("Expected inputs without encryption parameters to be returned as they are")
+	}
+}
+
+func TestValidateServerSideEncryption(t *testing.T) {
+	base := Config{Endpoint: "https://test-endpoint.com", Region: "us-east-1", Bucket: "test-bucket", Duration: "30s", Concurrency: 5,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "write", PutObjectSizeKB: 256}
+
+	cfg := base
+	if err := cfg.Validate(); err != nil || cfg.SSE != SSENone {
+		t.Fatalf("Expected SSE to default to none, got %q (%v)", cfg.SSE, err)
+	}
+	cfg = base
+	cfg.SSE, cfg.SSECustomerKey = SSEC, testCustomerKey
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"unknown mode":        func(c *Config) { c.SSE = "aes" },
+		"sse-c without key":   func(c *Config) { c.SSE = SSEC },
+		"short key":           func(c *Config) { c.SSE, c.SSECustomerKey = SSEC, "c2hvcnQ=" },
+		"kms key without kms": func(c *Config) { c.SSE, c.SSEKMSKeyID = SSES3, "key-1" },
+		"key without sse-c":   func(c *Config) { c.SSE, c.SSECustomerKey = SSEKMS, testCustomerKey },
+		"sse on gcs":          func(c *Config) { c.Backend, c.SSE = BackendGCS, SSES3 },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
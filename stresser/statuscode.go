@@ -0,0 +1,107 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// httpResponseKey holds the HTTP response in the result metadata of responses that bypass
+// the SDK's middleware, such as presigned requests. SDK responses keep the raw response instead.
+type httpResponseKey struct{}
+
+// httpResponseMetadata returns result metadata carrying resp, whose body must not be used.
+func httpResponseMetadata(resp *http.Response) middleware.Metadata {
+	var md middleware.Metadata
+	md.Set(httpResponseKey{}, resp)
+	return md
+}
+
+// metadataResponse returns the HTTP response of a successful call from its result metadata,
+// nil if it isn't known.
+func metadataResponse(md middleware.Metadata) *http.Response {
+	if resp, ok := md.Get(httpResponseKey{}).(*http.Response); ok {
+		return resp
+	}
+	if raw, ok := awsmiddleware.GetRawResponse(md).(*smithyhttp.Response); ok {
+		return raw.Response
+	}
+	return nil
+}
+
+// StatusText returns the status code with its reason phrase, e.g. "503 Service Unavailable",
+// or "no response" for 0.
+func StatusText(code int) string {
+	if code == 0 {
+		return "no response"
+	}
+	return fmt.Sprintf("%d %s", code, http.StatusText(code))
+}
+
+// StatusCodeStats counts the requests of one operation type that got one HTTP status.
+type StatusCodeStats struct {
+	Operation string  `json:"operation" yaml:"operation"`
+	Status    int     `json:"status" yaml:"status"` // 0 for failures without a response
+	Count     int64   `json:"count" yaml:"count"`
+	Percent   float64 `json:"percentOfRequests" yaml:"percentOfRequests"` // Of all requests of the operation type
+}
+
+// addStatusCodeResult counts r under its HTTP status. Called from AddResult. Successful
+// results of backends that don't report the status are left out; failures without a status
+// never got a response and are counted under 0.
+func (s *Stats) addStatusCodeResult(r Result) {
+	if r.StatusCode == 0 && r.Error == "" {
+		return
+	}
+	if s.statusCodes == nil {
+		s.statusCodes = make(map[string]map[int]int64)
+	}
+	codes, ok := s.statusCodes[r.Operation]
+	if !ok {
+		codes = make(map[int]int64)
+		s.statusCodes[r.Operation] = codes
+	}
+	codes[r.StatusCode]++
+}
+
+// StatusCodes returns the request counts per operation type and HTTP status, ordered by
+// operation and then by status. It returns nil if no status was recorded.
+func (s *Stats) StatusCodes() []StatusCodeStats {
+	var list []StatusCodeStats
+	for op, codes := range s.statusCodes {
+		total := s.operationTotal(op)
+		for code, count := range codes {
+			sc := StatusCodeStats{Operation: op, Status: code, Count: count}
+			if total > 0 {
+				sc.Percent = 100 * float64(count) / float64(total)
+			}
+			list = append(list, sc)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Operation != list[j].Operation {
+			return list[i].Operation < list[j].Operation
+		}
+		return list[i].Status < list[j].Status
+	})
+	return list
+}
+
+// printStatusCodeSummary prints the status code breakdown as part of PrintSummary.
+func (s *Stats) printStatusCodeSummary(w io.Writer) {
+	codes := s.StatusCodes()
+	if codes == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nHTTP Status Codes:\n")
+	fmt.Fprintf(w, "  Op   | Status                       |  Count | %% of Op\n")
+	fmt.Fprintf(w, "  -----|------------------------------|--------|--------\n")
+	for _, sc := range codes {
+		fmt.Fprintf(w, "  %-4s | %-28s | %6d | %6.2f%%\n", sc.Operation, StatusText(sc.Status), sc.Count, sc.Percent)
+	}
+}
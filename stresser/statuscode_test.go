@@ -0,0 +1,72 @@
+package stresser
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRecordStatusCode(t *testing.T) {
+	var r Result
+	recordResponseMetadata(&r, httpResponseMetadata(&http.Response{StatusCode: http.StatusPartialContent, Proto: "HTTP/2.0"}))
+	if r.StatusCode != http.StatusPartialContent || r.Protocol != "HTTP/2.0" {
+		t.Errorf("Expected 206 over HTTP/2.0 from presigned metadata, got %d over %q", r.StatusCode, r.Protocol)
+	}
+	var md middleware.Metadata
+	if resp := metadataResponse(md); resp != nil {
+		t.Errorf("Expected no response in empty metadata, got %+v", resp)
+	}
+
+	r = Result{}
+	recordErrorRequestID(&r, statusError(http.StatusServiceUnavailable))
+	if r.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the status of the error response, got %d", r.StatusCode)
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	for range 6 {
+		stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond, StatusCode: http.StatusOK})
+	}
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond, StatusCode: http.StatusNotModified})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: -1, TTLB: -1, Error: "StatusCode: 404", StatusCode: http.StatusNotFound})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: -1, TTLB: -1, Error: "i/o timeout"})
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", TTFB: -1, TTLB: -1, Error: "StatusCode: 503", StatusCode: http.StatusServiceUnavailable})
+	// Successes of backends that don't report a status are left out
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: time.Millisecond})
+	stats.Calculate(now, now.Add(time.Second))
+
+	codes := stats.StatusCodes()
+	want := []StatusCodeStats{
+		{Operation: "GET", Status: 0, Count: 1, Percent: 10},
+		{Operation: "GET", Status: 200, Count: 6, Percent: 60},
+		{Operation: "GET", Status: 304, Count: 1, Percent: 10},
+		{Operation: "GET", Status: 404, Count: 1, Percent: 10},
+		{Operation: "PUT", Status: 503, Count: 1, Percent: 100},
+	}
+	if len(codes) != len(want) {
+		t.Fatalf("Expected %d status codes, got %+v", len(want), codes)
+	}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("Status code %d: got %+v, expected %+v", i, codes[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	for _, s := range []string{"HTTP Status Codes:", "304 Not Modified", "503 Service Unavailable", "no response"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("Summary lacks %q:\n%s", s, buf.String())
+		}
+	}
+	if len(stats.Summary().StatusCodes) != len(want) {
+		t.Error("Expected the status codes in the machine-readable summary")
+	}
+}
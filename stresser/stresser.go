@@ -1,13 +1,14 @@
 package stresser
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"math/rand" // Use math/rand for all random operations
+	"strings"
 	"sync"
 	"time"
 
@@ -19,21 +20,75 @@ const (
 	progressCount = 1000
 )
 
-// RunStressTest orchestrates the stress test, launching workers and collecting results.
-func RunStressTest(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
+// Run orchestrates the stress test, launching workers and collecting results.
+func (r *Runner) Run(ctx context.Context) ([]Result, *Stats, error) {
+	cfg := r.cfg
+	// Optionally serve profiles of the process while the test runs
+	if cfg.PprofAddr != "" {
+		server, err := startPprofServer(cfg.PprofAddr)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer server.Close()
+	}
+	defer applyScheduler(cfg)()
+
 	// 1. Load or prepare manifest
 	var objectKeys []string
+	var keyWeights []float64     // Read weights of objectKeys, nil if the manifest has none
+	var expected []ManifestEntry // Expected sizes and ETags of objectKeys, nil if the manifest has none
 	var manifestWriter *ManifestWriter
+	var overwrites *overwriteTracker // Writes to the pool of -write-mode overwrite, nil otherwise
 	var err error
 
-	// For read/mixed mode, load existing manifest
-	if cfg.OperationType == "read" || cfg.OperationType == "mixed" {
-		objectKeys, err = LoadManifest(cfg.ManifestPath)
+	// Versions to read and delete in 'versioned' mode, seeded from the manifest
+	var versions *versionPool
+
+	// For read/mixed/head mode, load existing manifest
+	if len(cfg.Keys) > 0 {
+		// Keys handed over directly, e.g. an agent's shard of the coordinator's manifest
+		objectKeys, keyWeights, expected = cfg.Keys, cfg.KeyWeights, cfg.KeyExpectations
+		slog.Info("Using object keys from configuration", "count", len(objectKeys))
+	} else if cfg.OperationType == "read" || cfg.OperationType == "mixed" || cfg.OperationType == "head" || cfg.OperationType == "revalidate" || cfg.OperationType == "copy" || cfg.OperationType == "tagging" {
+		entries, err := LoadManifestEntries(cfg.ManifestPath)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to load manifest for read/mixed mode: %w", err)
+			return nil, nil, fmt.Errorf("failed to load manifest for %s mode: %w", cfg.OperationType, err)
+		}
+		if entries, err = manifestShard(entries, cfg.Shard); err != nil {
+			return nil, nil, err
 		}
-		slog.Info("Loaded object keys from manifest", "count", len(objectKeys), "path", cfg.ManifestPath)
+		objectKeys, keyWeights = splitManifest(entries)
+		expected = manifestExpectations(entries)
+		slog.Info("Loaded object keys from manifest", "count", len(objectKeys), "weighted", keyWeights != nil, "validated", expected != nil, "shard", cfg.Shard, "path", cfg.ManifestPath)
+	} else if cfg.OperationType == "versioned" {
+		entries, err := LoadManifestEntries(cfg.ManifestPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load manifest for %s mode: %w", cfg.OperationType, err)
+		}
+		if entries, err = manifestShard(entries, cfg.Shard); err != nil {
+			return nil, nil, err
+		}
+		// PUTs go to the distinct keys, GETs and DELETEs to the listed versions
+		objectKeys = manifestKeys(entries)
+		versions = newVersionPool(entries)
+		slog.Info("Loaded object versions from manifest", "keys", len(objectKeys), "versions", versions.size(), "shard", cfg.Shard, "path", cfg.ManifestPath)
+		if versions.size() == 0 {
+			slog.Info("No version ids in the manifest, GETs and DELETEs start once PUTs return versions")
+		}
+	} else if cfg.OperationType == "contention" {
+		// All workers race for the same few keys
+		objectKeys = poolKeys(cfg.RunID, "contention", cfg.KeyPool)
+		slog.Info("Contending for a pool of keys", "keys", len(objectKeys), "prefix", RunPrefix(cfg.RunID)+"contention/")
+	} else if cfg.OperationType == "append" {
+		// Every worker reads, extends and writes back logs of its own
+		slog.Info("Appending to logs", "logsPerWorker", cfg.KeyPool, "chunkKB", cfg.AppendSizeKB, "maxKB", cfg.AppendMaxKB, "prefix", RunPrefix(cfg.RunID)+"append/")
 	} else if cfg.OperationType == "write" {
+		if cfg.WriteMode == WriteModeOverwrite {
+			// PUTs replace the objects of a fixed pool of keys instead of creating new ones
+			objectKeys = poolKeys(cfg.RunID, "overwrite", cfg.KeyPool)
+			overwrites = newOverwriteTracker()
+			slog.Info("Overwriting a pool of keys", "keys", len(objectKeys), "prefix", RunPrefix(cfg.RunID)+"overwrite/")
+		}
 		// For write-only mode with file generation
 		if cfg.GenerateManifest {
 			manifestWriter, err = NewManifestWriter(cfg.ManifestPath)
@@ -48,51 +103,271 @@ func RunStressTest(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
 
 		// If we're in write mode and want to pre-generate specific number of files instead of continuous generation
 		if cfg.FileCount > 0 {
-			slog.Info("Will generate and upload files", "count", cfg.FileCount, "sizeKB", cfg.PutObjectSizeKB)
+			slog.Info("Will generate and upload files", "count", cfg.FileCount, "sizeKB", cfg.PutObjectSizeKB, "sizeDistribution", cfg.PutSizeDistribution)
 		}
 	}
 
-	// 2. Create S3 Client
-	s3Client, err := NewS3Client(ctx, cfg)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create S3 client: %w", err)
+	// Weighted reads pick keys in proportion to the weights of the manifest
+	if cfg.KeyDistribution == KeyDistWeighted && keyWeights == nil && len(objectKeys) > 0 {
+		return nil, nil, fmt.Errorf("weighted key distribution (-distribution weighted) requires a manifest with weights (key<TAB>weight lines) in %s mode", cfg.OperationType)
+	}
+	if cfg.KeyDistribution != KeyDistWeighted && keyWeights != nil {
+		slog.Info("Manifest has key weights, they are only used with -distribution weighted", "distribution", cfg.KeyDistribution)
+	}
+	weights := newKeyWeights(keyWeights)
+
+	// For scenario mode, load the key spaces of the groups
+	var scen *scenario
+	if cfg.OperationType == "scenario" {
+		if scen, err = newScenario(cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// For replay mode, load the operations to re-issue
+	var replayOps []ReplayOp
+	if cfg.OperationType == "replay" {
+		replayOps, err = LoadReplayOps(cfg.ReplayFile, cfg.ReplayFormat)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load replay file: %w", err)
+		}
+		slog.Info("Loaded operations for replay", "count", len(replayOps), "path", cfg.ReplayFile,
+			"span", replayOps[len(replayOps)-1].Offset)
+	}
+
+	// 2. Create the client for the configured backend, one per endpoint with -endpoints
+	var endpoints *endpointPicker
+	var failover *failoverClient
+	if cfg.Backend == BackendGCS {
+		gcsClient, err := NewGCSClient(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gcsClient.Close()
+		if cfg.Preflight || cfg.CreateBucket {
+			if err := runPreflightBuckets(ctx, gcsClient, cfg); err != nil {
+				return nil, nil, err
+			}
+		}
+		endpoints = singleEndpoint(gcsClient)
+	} else {
+		endpoints, failover, err = newS3Workload(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if cfg.OperationType == "contention" {
+		pattern, _ := parseDataPattern(cfg.DataPattern) // Already validated in Config.Validate
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		body := func() io.ReadSeeker { return pattern.payload(int64(cfg.PutObjectSizeKB)*1024, r) }
+		if err := seedContentionKeys(ctx, endpoints.targets[0].client, newBucketPicker(cfg).buckets, objectKeys, body); err != nil {
+			return nil, nil, err
+		}
 	}
-	slog.Info("S3 client configured", "endpoint", cfg.Endpoint, "bucket", cfg.Bucket)
 
 	// 3. Setup Concurrency & Context with Timeout
 	runDuration, err := time.ParseDuration(cfg.Duration)
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid duration format %q: %w", cfg.Duration, err)
 	}
-	runCtx, cancel := context.WithTimeout(ctx, runDuration)
-	defer cancel() // Ensure cancellation propagates when RunStressTest returns
+	var warmup time.Duration
+	if cfg.Warmup != "" {
+		warmup, _ = time.ParseDuration(cfg.Warmup) // Already validated in Config.Validate
+	}
+	// The warm-up runs before the measured duration
+	runCtx, cancel := context.WithTimeout(ctx, warmup+runDuration)
+	defer cancel() // Ensure cancellation propagates when Run returns
 
 	resultsChan := make(chan Result, cfg.Concurrency*20) // Buffered channel
 	var wg sync.WaitGroup
 
 	// Each worker will generate its own unique PUT data to avoid object deduplication
-	slog.Info("Workers will generate unique data for each PUT operation", "sizeKB", cfg.PutObjectSizeKB)
+	slog.Info("Workers will generate unique data for each PUT operation", "sizeKB", cfg.PutObjectSizeKB, "sizeDistribution", cfg.PutSizeDistribution)
 
 	slog.Info("Starting stress test",
 		"concurrency", cfg.Concurrency,
 		"duration", runDuration,
 		"operation", cfg.OperationType,
-		"randomizeRead", cfg.Randomize,
+		"keyDistribution", cfg.KeyDistribution,
 		"putSizeKB", cfg.PutObjectSizeKB)
 
+	if cfg.Jitter != "" {
+		slog.Info("Request pacing jitter enabled", "distribution", cfg.Jitter)
+	}
+	if cfg.ThinkTime != "" {
+		slog.Info("Think time between operations enabled", "distribution", cfg.ThinkTime)
+	}
+	if cfg.WorkerRPS > 0 {
+		slog.Info("Per-worker rate limit enabled", "requestsPerSecond", cfg.WorkerRPS)
+	}
+
+	// A fixed offered load shared by all workers
+	var rate *tokenBucket
+	if cfg.RPS > 0 {
+		rate = newTokenBucket(cfg.RPS, 1)
+		slog.Info("Request rate limit enabled", "requestsPerSecond", cfg.RPS)
+	}
+	// Open loop: operations start on a fixed schedule, the workers only bound how many are outstanding
+	var arrivals *arrivalScheduler
+	if cfg.ArrivalRate > 0 {
+		arrivals = newArrivalScheduler(cfg.ArrivalRate)
+		slog.Info("Open-loop mode enabled", "arrivalsPerSecond", cfg.ArrivalRate, "maxOutstanding", cfg.Concurrency)
+	}
+	if cfg.DisconnectFraction > 0 {
+		slog.Info("Disconnect simulation enabled, PUT connections will be cut mid-upload", "fraction", cfg.DisconnectFraction)
+	}
+
+	// Optionally record every executed operation for later replay
+	var traceWriter *TraceWriter
+	if cfg.TraceFile != "" {
+		traceWriter, err = NewTraceWriter(cfg.TraceFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create trace writer: %w", err)
+		}
+		defer traceWriter.Close()
+		slog.Info("Recording operation trace", "path", cfg.TraceFile)
+	}
+
+	// Optionally sample the host NIC to detect network-bound runs
+	var nicMon *nicMonitor
+	if cfg.NICInterface != "" {
+		nicMon, err = startNICMonitor(newNICStatsReader(), cfg.NICInterface, clientSampleInterval)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to monitor network interface: %w", err)
+		}
+	}
+
+	// All workers start active; the CPU autoscaler may lower the limit during the run
+	limit := newWorkerLimit(cfg.Concurrency)
+	if len(cfg.Stages) > 0 {
+		limit.Set(cfg.Stages[0].From)
+	}
+	var autoscaler *cpuAutoscaler
+	if cfg.CPUBudget > 0 {
+		autoscaler = &cpuAutoscaler{limit: limit, maxActive: cfg.Concurrency, budget: cfg.CPUBudget}
+		go autoscaler.run(runCtx, clientSampleInterval)
+		slog.Info("CPU budget autoscaling enabled", "budgetPercent", cfg.CPUBudget)
+	}
+
+	// Optionally hedge slow GETs with a second request
+	var hedge *hedger
+	if cfg.HedgeQuantile > 0 {
+		hedge = newHedger(cfg.HedgeQuantile)
+		slog.Info("Hedged GETs enabled", "quantile", cfg.HedgeQuantile, "warmupSamples", hedgeMinSamples)
+	}
+
+	// Operations are spread over the buckets of -buckets, if more than one is given
+	buckets := newBucketPicker(cfg)
+	if buckets.multiple() {
+		slog.Info("Spreading operations over multiple buckets", "buckets", len(cfg.Buckets), "selection", cfg.BucketSelection)
+	}
+
+	// Copy sources are looked up once and shared by all workers
+	var sizes *objectSizes
+	if cfg.OperationType == "copy" {
+		sizes = newObjectSizes()
+	}
+
+	// Optionally accept commands to pause, resize or stop the run
+	var control *controlServer
+	if cfg.Control != "" {
+		control = &controlServer{
+			limit:      limit,
+			maxWorkers: cfg.Concurrency,
+			adjustable: len(cfg.Stages) == 0 && cfg.CPUBudget == 0 && cfg.TargetP99 == "",
+			pausable:   cfg.OperationType != "replay" && !(cfg.OperationType == "write" && cfg.FileCount > 0),
+			stop:       cancel,
+			start:      time.Now(),
+		}
+		if err := startControlServer(cfg.Control, control); err != nil {
+			return nil, nil, err
+		}
+		defer control.close()
+	}
+
+	// Optionally write detailed results as they come in instead of returning them
+	var stream *resultStream
+	if cfg.Checkpoint != "" {
+		interval, _ := time.ParseDuration(cfg.Checkpoint) // Already validated in Config.Validate
+		stream, err = newResultStream(cfg.OutputFile, cfg.OutputFormat, interval)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("Streaming detailed results", "file", cfg.OutputFile, "format", cfg.OutputFormat, "checkpoint", interval)
+	}
+
+	// Optionally report slow requests with their full context as they come in
+	slow, err := newSlowLog(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := slow.close(); err != nil {
+			slog.Error("Failed to close slow log", "error", err, "file", cfg.SlowLog)
+		}
+	}()
+
 	startTime := time.Now()
+	monitor := startClientMonitor(clientSampleInterval)
+	measureStart := startTime.Add(warmup) // Results started before this are warm-up
+	if warmup > 0 {
+		slog.Info("Warm-up period enabled, early results are excluded from stats", "warmup", warmup)
+	}
+
+	// Optionally shape the load by moving the active worker limit through stages
+	if len(cfg.Stages) > 0 {
+		go runRamp(runCtx, limit, stageWindows(cfg.Stages, startTime))
+		slog.Info("Load stages enabled", "stages", len(cfg.Stages), "peakWorkers", cfg.Concurrency)
+	}
+
+	// Optionally search for the highest worker count that keeps P99 latency within a target
+	var tuner *latencyTuner
+	if cfg.TargetP99 != "" {
+		target, _ := time.ParseDuration(cfg.TargetP99) // Already validated in Config.Validate
+		tuner = newLatencyTuner(limit, cfg.Concurrency, target, startTime)
+		go tuner.run(runCtx, tuneInterval)
+		slog.Info("Latency target tuning enabled", "targetP99", target, "maxWorkers", cfg.Concurrency, "interval", tuneInterval)
+	}
 
 	// 4. Start Workers
+	threads := newThreadPool(cfg) // Locked OS threads of -lock-threads, nil otherwise
+	deps := &workerDeps{
+		cfg:            cfg,
+		endpoints:      endpoints,
+		resultsChan:    resultsChan,
+		objectKeys:     objectKeys,
+		expected:       expected,
+		weights:        weights,
+		manifestWriter: manifestWriter,
+		limit:          limit,
+		hedge:          hedge,
+		rate:           rate,
+		arrivals:       arrivals,
+		sizes:          sizes,
+		versions:       versions,
+		buckets:        buckets,
+		scen:           scen,
+		overwrites:     overwrites,
+		replayOps:      replayOps,
+		threads:        threads,
+	}
 	if cfg.OperationType == "write" && cfg.FileCount > 0 {
 		// Use fixed file count generation approach
 		wg.Add(1)
-		go generateFiles(runCtx, &wg, s3Client, cfg, resultsChan, manifestWriter)
+		go generateFiles(runCtx, &wg, deps)
+	} else if cfg.OperationType == "replay" {
+		// Re-issue recorded operations on their original schedule
+		wg.Add(1)
+		go runReplay(runCtx, &wg, deps)
 	} else {
 		// Use traditional workers for continuous test
 		for i := 0; i < cfg.Concurrency; i++ {
 			wg.Add(1)
 			// Pass runCtx which has the timeout
-			go runWorker(runCtx, &wg, i, s3Client, cfg, objectKeys, resultsChan, manifestWriter)
+			go runWorker(runCtx, &wg, i, deps)
+		}
+		if arrivals != nil {
+			go arrivals.run(runCtx)
 		}
 	}
 
@@ -100,27 +375,183 @@ func RunStressTest(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
 	// This goroutine ensures close(resultsChan) happens *after* all workers signal Done.
 	go func() {
 		wg.Wait()
+		threads.close()
 		close(resultsChan)
 		slog.Info("All workers finished")
 	}()
 
-	// 6. Collect Results from the channel until it's closed
+	// Print interim stats while the run is in progress, or show the live dashboard instead
+	var progress *progressReporter
+	var dash *dashboard
+	if r.Progress == nil {
+		slog.Debug("No progress writer, interim stats disabled")
+	} else if cfg.TUI && isTerminal(r.Progress) {
+		dash = newDashboard(r.Progress, cfg, startTime, warmup+runDuration, limit, arrivals)
+		go dash.run(dashboardInterval)
+	} else if interval, err := time.ParseDuration(cfg.Progress); err == nil && interval > 0 && !cfg.Quiet {
+		if cfg.TUI {
+			slog.Warn("Standard error is not a terminal, printing progress lines instead of the dashboard (-tui)")
+		}
+		progress = newProgressReporter(r.Progress, startTime)
+		go progress.run(interval)
+	}
+
+	// 6. Collect Results from the channel until it's closed, adding them to the stats as they come
+	stats := NewStats()
+	stats.Concurrency = cfg.Concurrency // Set the concurrency level
+	stats.PrefixDepth = cfg.PrefixDepth
+	stats.OutlierCount = cfg.OutlierCount
+	stats.TrackWrites = cfg.TrackWrites
+	stats.IPFamily = cfg.IPFamily
+	stats.HTTPVersion = cfg.HTTPVersion
+	stats.RunID = cfg.RunID
+	stats.ThinkTime = cfg.ThinkTime
+	stats.SLOs = cfg.SLOs
+	if len(cfg.Stages) > 0 {
+		stats.SetStages(cfg.Stages, startTime)
+	}
+	var checkpoints <-chan time.Time // Ticks every checkpoint interval, nil without -checkpoint
+	if stream != nil {
+		ticker := time.NewTicker(stream.interval)
+		defer ticker.Stop()
+		checkpoints = ticker.C
+	}
 	allResults := make([]Result, 0)
-	for result := range resultsChan {
-		allResults = append(allResults, result)
-		// Optional: Log progress periodically
-		// if len(allResults)%100 == 0 { slog.Info("Collected results progress", "count", len(allResults)) }
+	collected := 0
+	sampler := newResultSampler(cfg) // Thins the detailed results, nil keeps all
+collect:
+	for {
+		var result Result
+		select {
+		case res, ok := <-resultsChan:
+			if !ok {
+				break collect
+			}
+			result = res
+		case <-r.InterimSummary:
+			if r.Progress == nil || dash != nil {
+				slog.Warn("Interim summary requested, but it can only be printed to the progress output without the dashboard")
+			} else {
+				writeInterimSummary(r.Progress, stats, measureStart, time.Now())
+			}
+			continue
+		case <-checkpoints:
+			if err := stream.checkpoint(); err != nil {
+				slog.Error("Failed to checkpoint results", "error", err, "file", cfg.OutputFile)
+			}
+			continue
+		}
+		result.Warmup = result.Timestamp.Before(measureStart)
+		result.RunID = cfg.RunID
+		if !result.Warmup {
+			stats.AddResult(result) // AddResult handles filtering successes/failures for stats
+		}
+		if !result.Warmup || cfg.WarmupResults {
+			collected++
+			if stream == nil {
+				allResults = sampler.add(allResults, result)
+			} else if !sampler.sampled() {
+				// Left out of the detailed results
+			} else if err := stream.write(result); err != nil {
+				slog.Error("Failed to write result", "error", err, "file", cfg.OutputFile)
+			}
+		}
+		slow.add(result)
+		if r.OnResult != nil {
+			r.OnResult(result)
+		}
+		if control != nil {
+			control.add(result)
+		}
+		if tuner != nil {
+			tuner.add(result)
+		}
+		if progress != nil {
+			progress.add(result)
+		}
+		if dash != nil {
+			dash.add(result)
+		}
+		if traceWriter != nil {
+			if err := traceWriter.Record(result, result.Timestamp.Sub(startTime)); err != nil {
+				slog.Error("Failed to record operation trace", "error", err)
+			}
+		}
 	}
 	endTime := time.Now()
-	slog.Info("Collected total results", "count", len(allResults))
+	if overwrites != nil && manifestWriter != nil {
+		if err := overwrites.writeManifest(manifestWriter); err != nil {
+			slog.Error("Failed to write overwritten keys to manifest", "error", err)
+		}
+	}
+	if progress != nil {
+		progress.Stop()
+	}
+	if dash != nil {
+		dash.Stop()
+	}
+	clientReport := monitor.Stop(cfg)
+	slog.Info("Collected total results", "count", collected)
+	if sampler != nil {
+		allResults = sampler.finish(allResults)
+		kept := len(allResults)
+		if stream != nil {
+			kept = stream.written
+		}
+		slog.Info("Sampled detailed results, the stats include every result", "kept", kept, "sampleRate", cfg.SampleRate, "sampleMax", cfg.SampleMax)
+	}
+	for _, warning := range clientReport.Warnings {
+		slog.Warn("Possible client-side bottleneck", "reason", warning)
+	}
+	var nicReport *NICReport
+	if nicMon != nil {
+		report := nicMon.Stop()
+		nicReport = &report
+		if report.LikelyNICBound {
+			slog.Warn("Network interface saturated, results are likely NIC-bound", "interface", report.Interface, "peakUtilization", report.PeakUtilPct)
+		}
+	}
 
 	// 7. Calculate Final Statistics
-	stats := NewStats()
-	stats.Concurrency = cfg.Concurrency // Set the concurrency level
-	for _, res := range allResults {
-		stats.AddResult(res) // AddResult handles filtering successes/failures for stats
+	clientReport.LockedThreads = threadPoolSize(cfg)
+	stats.Client = &clientReport
+	stats.NIC = nicReport
+	if autoscaler != nil {
+		stats.ScalingEvents = autoscaler.Events()
+	}
+	if tuner != nil {
+		report := tuner.Report()
+		stats.Tuning = &report
+	}
+	if failover != nil {
+		report := failover.Report()
+		stats.Failover = &report
+	}
+	if arrivals != nil {
+		report := arrivals.Report()
+		stats.Arrivals = &report
+		if report.Dropped > 0 {
+			slog.Warn("Arrivals dropped because all workers were busy; increase concurrency (-c)",
+				"dropped", report.Dropped, "scheduled", report.Scheduled)
+		}
+	}
+	if measureStart.After(endTime) {
+		measureStart = endTime // Run ended during the warm-up
+	}
+	stats.Calculate(measureStart, endTime) // Calculate averages, percentiles etc.
+	if cfg.RPS > 0 {
+		// In a closed loop the workers can't exceed concurrency / latency, whatever the limit
+		if achieved := float64(stats.TotalRequests) / stats.actualDuration.Seconds(); achieved < 0.9*cfg.RPS {
+			slog.Warn("Target request rate not reached, workers were saturated; increase concurrency (-c)",
+				"targetRps", cfg.RPS, "achievedRps", achieved)
+		}
+	}
+
+	if stream != nil {
+		if err := stream.close(stats); err != nil {
+			slog.Error("Error writing results", "error", err, "file", cfg.OutputFile, "format", cfg.OutputFormat)
+		}
 	}
-	stats.Calculate(startTime, endTime) // Calculate averages, percentiles etc.
 
 	// Check if the test ended due to timeout or external signal rather than an error
 	if runCtx.Err() != nil && !errors.Is(runCtx.Err(), context.Canceled) && !errors.Is(runCtx.Err(), context.DeadlineExceeded) {
@@ -132,17 +563,129 @@ func RunStressTest(ctx context.Context, cfg *Config) ([]Result, *Stats, error) {
 	return allResults, stats, nil // Return collected results, stats, and nil error for normal completion/timeout
 }
 
+// newS3Workload creates the S3 clients the workers use, one per endpoint of -endpoints.
+// With a secondary endpoint the client is wrapped in a failoverClient, which is also
+// returned for reporting.
+func newS3Workload(ctx context.Context, cfg *Config) (*endpointPicker, *failoverClient, error) {
+	if cfg.Presign {
+		slog.Info("Sending GETs and PUTs to presigned URLs over plain HTTP")
+	}
+	if cfg.ClientPerWorker {
+		slog.Info("Creating an S3 client with an HTTP transport of its own for every worker")
+	}
+	if len(cfg.Endpoints) > 1 {
+		targets, err := newEndpointTargets(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("Spreading operations over multiple endpoints", "endpoints", len(targets), "selection", cfg.EndpointSelection)
+		return newEndpointPicker(targets, cfg.EndpointSelection), nil, nil
+	}
+
+	primaryClient, err := NewS3Client(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	slog.Info("S3 client configured", "endpoint", cfg.Endpoint, "bucket", cfg.Bucket)
+	if cfg.Preflight || cfg.CreateBucket {
+		if err := runPreflightBuckets(ctx, primaryClient, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+	client := workloadClient(primaryClient, cfg)
+	if cfg.SecondaryEndpoint == "" {
+		endpoints := singleEndpoint(client)
+		endpoints.targets[0].sdk = primaryClient
+		return endpoints, nil, nil
+	}
+
+	// With a secondary endpoint, traffic switches over once the primary keeps failing
+	secondaryCfg := *cfg
+	secondaryCfg.Endpoint = cfg.SecondaryEndpoint
+	secondaryClient, err := NewS3Client(ctx, &secondaryCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create secondary S3 client: %w", err)
+	}
+	failover := newFailoverClient(client, workloadClient(secondaryClient, cfg), cfg.FailoverThreshold, cfg.Endpoint, cfg.SecondaryEndpoint)
+	slog.Info("Endpoint failover enabled", "secondary", cfg.SecondaryEndpoint, "threshold", cfg.FailoverThreshold)
+	return singleEndpoint(failover), failover, nil
+}
+
+// workloadClient returns the client workers send requests with: the SDK client itself, or
+// with Presign, a client fetching presigned URLs over plain HTTP.
+func workloadClient(client *s3.Client, cfg *Config) S3ClientAPI {
+	if cfg.Presign {
+		presigned := newPresignedClient(client, cfg.UserAgentString())
+		presigned.acceptEncoding = cfg.AcceptEncoding
+		return presigned
+	}
+	return client
+}
+
+// workerDeps is what the workers of a run share. Run builds it once and hands it to
+// runWorker, generateFiles or runReplay; fields that don't apply to the mode are nil.
+type workerDeps struct {
+	cfg            *Config
+	endpoints      *endpointPicker
+	resultsChan    chan<- Result
+	objectKeys     []string          // Keys to read, or the pool of -write-mode overwrite
+	expected       []ManifestEntry   // Expected sizes and ETags of objectKeys, nil if the manifest has none
+	weights        *keyWeights       // Read weights of objectKeys
+	manifestWriter *ManifestWriter   // Records written keys, nil unless a manifest is generated
+	limit          *workerLimit      // Active worker limit of stages, autoscaling and tuning
+	hedge          *hedger           // Hedges slow GETs with -hedge-quantile
+	rate           *tokenBucket      // Run-wide rate limit of -rps
+	arrivals       *arrivalScheduler // Open-loop schedule of -arrival-rate
+	sizes          *objectSizes      // Sizes of copy sources in 'copy' mode
+	versions       *versionPool      // Versions to read and delete in 'versioned' mode
+	buckets        *bucketPicker
+	scen           *scenario         // Groups of 'scenario' mode
+	overwrites     *overwriteTracker // Writes to the pool of -write-mode overwrite
+	replayOps      []ReplayOp        // Operations of 'replay' mode
+	threads        *threadPool       // Runs the operations with -lock-threads, nil otherwise
+}
+
 // runWorker performs S3 operations (GET, PUT, or mixed) until the context is cancelled.
-func runWorker(ctx context.Context, wg *sync.WaitGroup, id int, s3Client S3ClientAPI, cfg *Config, objectKeys []string, resultsChan chan<- Result, manifestWriter *ManifestWriter) {
+func runWorker(ctx context.Context, wg *sync.WaitGroup, id int, deps *workerDeps) {
 	defer wg.Done()
+	cfg := deps.cfg
+	endpoints, release := deps.endpoints.forWorker(cfg)
+	defer release()
 	slog.Info("Worker started", "id", id, "operation", cfg.OperationType)
 
 	// Initialize random source per worker for non-crypto choices (key selection, op type in mixed mode)
 	// Seed with unique value for each worker
 	localRand := rand.New(rand.NewSource(time.Now().UnixNano() + int64(id)))
 
-	keyCount := len(objectKeys)       // Will be 0 in write-only mode
-	keyIndex := id % max(keyCount, 1) // Simple initial distribution for sequential reads (if keyCount > 0)
+	jitter, _ := parseDelayDistribution(cfg.Jitter)   // Already validated in Config.Validate
+	think, _ := parseDelayDistribution(cfg.ThinkTime) // Already validated in Config.Validate
+
+	// Optional per-worker request rate cap, so each worker paces itself like an individual client
+	var workerBucket *tokenBucket
+	if cfg.WorkerRPS > 0 {
+		workerBucket = newTokenBucket(cfg.WorkerRPS, 1)
+	}
+
+	keyCount := len(deps.objectKeys)                                                      // Will be 0 in write-only mode
+	keyDist, _ := parseKeyDistribution(cfg.KeyDistribution, cfg.Randomize)                // Already validated in Config.Validate
+	keys := keyDist.newPicker(localRand, keyCount, id, deps.weights)                      // Sequential reads start at a per-worker offset
+	keyTmpl, _ := parseKeyTemplate(cfg.KeyTemplate, workerKeyTemplate(cfg.OperationType)) // Already validated in Config.Validate
+	putSizes, _ := parseSizeDistribution(cfg.PutSizeDistribution, cfg.PutObjectSizeKB)    // Already validated in Config.Validate
+	pattern, _ := parseDataPattern(cfg.DataPattern)                                       // Already validated in Config.Validate
+	versionOps, _ := parseVersionMix(cfg.VersionMix)                                      // Already validated in Config.Validate
+	consistency := newConsistencyCheck(cfg)                                               // Probe delays of 'consistency' mode
+	var putSeq int64                                                                      // PUTs or copies made by this worker, for {seq}
+	listToken := ""                                                                       // Continuation token of the listing in progress ('list' mode)
+	listBucket := ""                                                                      // Bucket of that listing
+	var listTarget *endpointTarget                                                        // Endpoint of that listing
+	var groups *scenarioWorker                                                            // Key spaces and listings of 'scenario' mode
+	if deps.scen != nil {
+		groups = deps.scen.worker(localRand, id, keyDist)
+	}
+	var appender *logAppender // Logs of this worker in 'append' mode
+	if cfg.OperationType == "append" {
+		appender = newLogAppender(cfg, id, localRand, keyDist)
+	}
 
 	for {
 		// Check for context cancellation *before* starting an operation
@@ -154,7 +697,50 @@ func runWorker(ctx context.Context, wg *sync.WaitGroup, id int, s3Client S3Clien
 			// Continue processing
 		}
 
+		// Idle while this worker is above the active worker limit
+		if !deps.limit.allows(id) {
+			if !sleepContext(ctx, idleWorkerPoll) {
+				slog.Info("Worker stopping", "id", id, "reason", ctx.Err())
+				return
+			}
+			continue
+		}
+
+		// Random pause so workers don't fire in lockstep waves
+		if jitter.enabled() && !sleepContext(ctx, jitter.sample(localRand)) {
+			slog.Info("Worker stopping", "id", id, "reason", ctx.Err())
+			return
+		}
+
+		// With a target rate, note when the operation was due, for coordinated omission correction
+		var due time.Time
+		if workerBucket != nil {
+			var ok bool
+			if due, ok = workerBucket.WaitScheduled(ctx); !ok {
+				slog.Info("Worker stopping", "id", id, "reason", ctx.Err())
+				return
+			}
+		}
+		if deps.rate != nil {
+			rateDue, ok := deps.rate.WaitScheduled(ctx)
+			if !ok {
+				slog.Info("Worker stopping", "id", id, "reason", ctx.Err())
+				return
+			}
+			due = later(due, rateDue)
+		}
+		if deps.arrivals != nil {
+			var ok bool
+			if due, ok = deps.arrivals.Wait(ctx); !ok {
+				slog.Info("Worker stopping", "id", id, "reason", ctx.Err())
+				return
+			}
+		}
+
 		var result Result
+		var bucket string                   // Bucket the operation went to
+		target := endpoints.pick(localRand) // Endpoint the operation goes to
+		s3Client := target.client
 		opType := cfg.OperationType
 
 		// Decide operation type for 'mixed' mode
@@ -166,72 +752,290 @@ func runWorker(ctx context.Context, wg *sync.WaitGroup, id int, s3Client S3Clien
 			}
 		}
 
-		// Perform selected operation
-		switch opType {
-		case "read":
-			if keyCount == 0 {
-				slog.Warn("Skipping READ operation", "workerId", id, "reason", "no keys loaded (write-only mode or empty manifest)")
-				// Avoid busy-looping if manifest is empty in read/mixed mode
-				time.Sleep(100 * time.Millisecond) // Small delay
-				continue
-			}
-			var objectKey string
-			if cfg.Randomize {
-				objectKey = objectKeys[localRand.Intn(keyCount)]
-			} else {
-				objectKey = objectKeys[keyIndex%keyCount]
-				keyIndex++ // Only advance index for sequential reads
-			}
-			result = performGetOperation(ctx, s3Client, cfg.Bucket, objectKey)
-
-		case "write":
-			// Generate a unique key for each PUT to avoid overwrites (or use manifest keys if desired?)
-			// Using unique keys is generally better for write stress tests.
-			objectKey := fmt.Sprintf("stresser/worker%d/%d-%s.dat", id, time.Now().UnixNano(), randomString(8, localRand))
-
-			// Generate unique data for each PUT to avoid object deduplication
-			data := make([]byte, cfg.PutObjectSizeKB*1024)
-			// Use math/rand which is faster and doesn't risk entropy exhaustion
-			for i := range data {
-				data[i] = byte(localRand.Intn(256))
-			}
+		// Perform selected operation, on a thread of the pool with -lock-threads
+		var skip, stop bool // Go on with the next operation, or end the worker
+		deps.threads.run(func() {
+			switch opType {
+			case "read", "head", "revalidate", "tagging":
+				if keyCount == 0 {
+					slog.Warn("Skipping READ operation", "workerId", id, "reason", "no keys loaded (write-only mode or empty manifest)")
+					// Avoid busy-looping if manifest is empty in read/mixed mode
+					time.Sleep(100 * time.Millisecond) // Small delay
+					skip = true
+					return
+				}
+				idx := keys.pick()
+				objectKey := deps.objectKeys[idx]
+				bucket = deps.buckets.pick(localRand, objectKey)
+				if opType == "head" {
+					result = performHeadOperation(ctx, s3Client, bucket, objectKey)
+				} else if opType == "tagging" {
+					result = performTaggingOperation(ctx, s3Client, bucket, objectKey)
+				} else if opType == "revalidate" {
+					stale := localRand.Float64() < cfg.RevalidateStale
+					head, get, ok := performRevalidateOperation(ctx, s3Client, bucket, objectKey, stale, cfg.Verify)
+					result = head
+					if ok {
+						if !due.IsZero() {
+							// The GET inherits the HEAD's delay, not the time the HEAD took
+							head.IntendedStart = due
+							get.IntendedStart = get.Timestamp.Add(-head.scheduleDelay())
+						}
+						if deps.buckets.multiple() {
+							head.Bucket = bucket
+						}
+						if endpoints.multiple() {
+							head.Endpoint = target.url
+						}
+						// The HEAD goes out first; the conditional GET follows below
+						if !sendResult(ctx, deps.resultsChan, id, head) {
+							stop = true
+							return
+						}
+						result = get
+					}
+				} else {
+					result = fetchObject(ctx, s3Client, cfg, deps.hedge, bucket, objectKey)
+					if deps.expected != nil {
+						checkExpected(&result, deps.expected[idx])
+					}
+				}
 
-			result = performPutOperation(ctx, s3Client, cfg.Bucket, objectKey, data)
+			case "copy":
+				if keyCount == 0 {
+					slog.Warn("Skipping COPY operation", "workerId", id, "reason", "no keys loaded (empty manifest)")
+					time.Sleep(100 * time.Millisecond) // Small delay
+					skip = true
+					return
+				}
+				client, ok := s3Client.(copyClient)
+				if !ok {
+					// Should not happen due to config validation, which rejects clients without copy support
+					slog.Error("Client does not support copies", "workerId", id)
+					stop = true
+					return
+				}
+				dst := keyTmpl.render(localRand, keyVars{worker: id, seq: putSeq, run: cfg.RunID})
+				putSeq++
+				src := deps.objectKeys[keys.pick()]
+				bucket = deps.buckets.pick(localRand, src)
+				result = performCopyOperation(ctx, client, deps.sizes, bucket, src, dst, int64(cfg.CopyPartSizeMB)<<20)
 
-			// If successful upload and manifest writing is enabled, add the key to manifest
-			if result.Error == "" && manifestWriter != nil {
-				if err := manifestWriter.AddKey(objectKey); err != nil {
-					slog.Error("Failed to write key to manifest", "workerId", id, "error", err)
+			case "versioned":
+				if keyCount == 0 {
+					slog.Warn("Skipping versioned operation", "workerId", id, "reason", "no keys loaded (empty manifest)")
+					time.Sleep(100 * time.Millisecond) // Small delay
+					skip = true
+					return
+				}
+				var v ManifestEntry
+				var ok bool
+				switch versionOps.pick(localRand) {
+				case "get":
+					if v, ok = deps.versions.pick(localRand); ok {
+						result = performVersionedGetOperation(ctx, s3Client, deps.buckets.pick(localRand, v.Key), v, cfg.Verify)
+					}
+				case "delete":
+					if v, ok = deps.versions.take(localRand); ok {
+						result = performDeleteOperation(ctx, s3Client, deps.buckets.pick(localRand, v.Key), v)
+						if result.Error != "" {
+							deps.versions.add(localRand, v) // The version may still exist
+						}
+					}
+				}
+				if !ok {
+					// Write a new version of a key of the set, also while there are no versions to read or delete
+					objectKey := deps.objectKeys[keys.pick()]
+					result = uploadObject(ctx, s3Client, cfg, deps.buckets.pick(localRand, objectKey), objectKey, pattern.payload(putSizes.sample(localRand), localRand))
+					if result.Error == "" && result.VersionID != "" {
+						deps.versions.add(localRand, ManifestEntry{Key: objectKey, VersionID: result.VersionID})
+					}
 				}
-			}
 
-		default:
-			// Should not happen due to config validation, but handle defensively
-			slog.Error("Invalid operation type encountered", "workerId", id, "operationType", opType)
-			time.Sleep(time.Second) // Prevent fast loop on error
+			case "list":
+				// Page through the listing, starting over once it is complete; a listing stays in its
+				// bucket and on its endpoint
+				if listToken == "" {
+					listBucket = deps.buckets.pick(localRand, "")
+					listTarget = target
+				}
+				bucket, target = listBucket, listTarget
+				result, listToken = performListOperation(ctx, target.client, bucket, cfg.ListPrefix, cfg.ListPageSize, listToken)
+
+			case "write":
+				// Generate a unique key for each PUT to avoid overwrites, unless the key template says otherwise
+				var objectKey string
+				if deps.overwrites != nil {
+					objectKey = deps.objectKeys[keys.pick()] // -write-mode overwrite replaces the objects of the pool
+				} else {
+					objectKey = keyTmpl.render(localRand, keyVars{worker: id, seq: putSeq, run: cfg.RunID})
+					putSeq++
+				}
+
+				// Generate unique data for each PUT to avoid object deduplication, unless the pattern asks for it
+				body := pattern.payload(putSizes.sample(localRand), localRand)
+
+				bucket = deps.buckets.pick(localRand, objectKey)
+				result = uploadObject(ctx, s3Client, cfg, bucket, objectKey, body)
+
+				// If successful upload and manifest writing is enabled, add the key to manifest; overwritten
+				// keys go into it once, after the run
+				if result.Error == "" && deps.overwrites != nil && cfg.DisconnectFraction == 0 {
+					result.Overwrite = deps.overwrites.record(ManifestEntry{Key: objectKey, VersionID: result.VersionID, Size: result.BytesUploaded, ETag: result.ETag})
+				} else if result.Error == "" && deps.manifestWriter != nil && cfg.DisconnectFraction == 0 {
+					if err := deps.manifestWriter.AddEntry(ManifestEntry{Key: objectKey, VersionID: result.VersionID, Size: result.BytesUploaded, ETag: result.ETag}); err != nil {
+						slog.Error("Failed to write key to manifest", "workerId", id, "error", err)
+					}
+				}
+
+			case "scenario":
+				// An operation of a group picked by weight
+				result, bucket = groups.next(ctx, cfg, deps.hedge, deps.buckets, &target, pattern, &putSeq)
+
+			case "contention":
+				// Read the ETag of a contended key and replace the object if no other worker did first
+				objectKey := deps.objectKeys[keys.pick()]
+				bucket = deps.buckets.pick(localRand, objectKey)
+				head, put, ok := performContendedPut(ctx, s3Client, bucket, objectKey, pattern.payload(putSizes.sample(localRand), localRand), cfg.Verify, cfg.putAttributes())
+				result = head
+				if ok {
+					if !due.IsZero() {
+						// The PUT inherits the HEAD's delay, not the time the HEAD took
+						head.IntendedStart = due
+						put.IntendedStart = put.Timestamp.Add(-head.scheduleDelay())
+					}
+					if deps.buckets.multiple() {
+						head.Bucket = bucket
+					}
+					if endpoints.multiple() {
+						head.Endpoint = target.url
+					}
+					// The HEAD goes out first; the conditional PUT follows below
+					if !sendResult(ctx, deps.resultsChan, id, head) {
+						stop = true
+						return
+					}
+					result = put
+				}
+
+			case "append":
+				// Read a log of this worker, add a chunk to its end and write it back
+				objectKey := appender.pick()
+				bucket = deps.buckets.pick(localRand, objectKey)
+				get, put, ok := appender.append(ctx, s3Client, cfg, bucket, objectKey, pattern, localRand)
+				result = get
+				if ok && get.Operation == "" {
+					result = put // A new log, written without reading it first
+				} else if ok {
+					if !due.IsZero() {
+						// The PUT inherits the GET's delay, not the time the GET took
+						get.IntendedStart = due
+						put.IntendedStart = put.Timestamp.Add(-get.scheduleDelay())
+					}
+					if deps.buckets.multiple() {
+						get.Bucket = bucket
+					}
+					if endpoints.multiple() {
+						get.Endpoint = target.url
+					}
+					// The GET goes out first; the PUT of the appended log follows below
+					if !sendResult(ctx, deps.resultsChan, id, get) {
+						stop = true
+						return
+					}
+					result = put
+				}
+
+			case "consistency":
+				// Write a new object and read it back after each probe delay; the PUT and the probes
+				// before the last one are sent from within the check
+				objectKey := keyTmpl.render(localRand, keyVars{worker: id, seq: putSeq, run: cfg.RunID})
+				putSeq++
+				bucket = deps.buckets.pick(localRand, objectKey)
+				pick := func() *endpointTarget { return endpoints.pick(localRand) }
+				var ok bool
+				result, target, ok = consistency.run(ctx, target, pick, bucket, objectKey, pattern.payload(putSizes.sample(localRand), localRand),
+					func(r Result, t *endpointTarget) bool {
+						if r.Operation == "PUT" && !due.IsZero() {
+							r.IntendedStart = due // Only the PUT is paced, the probes follow their delays
+						}
+						if deps.buckets.multiple() {
+							r.Bucket = bucket
+						}
+						if endpoints.multiple() {
+							r.Endpoint = t.url
+						}
+						return sendResult(ctx, deps.resultsChan, id, r)
+					})
+				if !ok {
+					stop = true
+					return
+				}
+				if result.Operation != "PUT" && !due.IsZero() {
+					result.IntendedStart = result.Timestamp // Not paced, see above
+				}
+
+			default:
+				// Should not happen due to config validation, but handle defensively
+				slog.Error("Invalid operation type encountered", "workerId", id, "operationType", opType)
+				time.Sleep(time.Second) // Prevent fast loop on error
+				skip = true
+				return
+			}
+		})
+		if stop {
+			return
+		}
+		if skip {
 			continue
 		}
 
+		if !due.IsZero() && result.IntendedStart.IsZero() {
+			result.IntendedStart = due
+		}
+		if deps.buckets.multiple() {
+			result.Bucket = bucket
+		}
+		if endpoints.multiple() {
+			result.Endpoint = target.url
+		}
+
 		// Send result (even if it's an error result) to the collector
-		// Non-blocking send attempt in case channel is full (shouldn't happen with sufficient buffer)
-		select {
-		case resultsChan <- result:
-			// Result sent successfully
-		case <-ctx.Done():
-			// Context cancelled while trying to send, log and exit worker
-			slog.Info("Context cancelled while sending result", "workerId", id, "reason", ctx.Err())
+		if !sendResult(ctx, deps.resultsChan, id, result) {
+			return
+		}
+
+		// Pause like an application does between operations
+		if think.enabled() && !sleepContext(ctx, think.sample(localRand)) {
+			slog.Info("Worker stopping", "id", id, "reason", ctx.Err())
 			return
-		default:
-			// Should ideally not happen with a buffered channel unless producer is way faster than consumer
-			slog.Warn("Results channel potentially full, dropping result", "workerId", id, "key", result.ObjectKey)
 		}
 	}
 }
 
+// sendResult hands a worker's result to the collector. It returns false if the context
+// was cancelled, in which case the worker should exit.
+func sendResult(ctx context.Context, resultsChan chan<- Result, id int, result Result) bool {
+	// Non-blocking send attempt in case channel is full (shouldn't happen with sufficient buffer)
+	select {
+	case resultsChan <- result:
+		// Result sent successfully
+	case <-ctx.Done():
+		// Context cancelled while trying to send, log and exit worker
+		slog.Info("Context cancelled while sending result", "workerId", id, "reason", ctx.Err())
+		return false
+	default:
+		// Should ideally not happen with a buffered channel unless producer is way faster than consumer
+		slog.Warn("Results channel potentially full, dropping result", "workerId", id, "key", result.ObjectKey)
+	}
+	return true
+}
+
 // generateFiles generates and uploads a specific number of files, then exits.
 // This is used for the fixed file count generation mode.
-func generateFiles(ctx context.Context, wg *sync.WaitGroup, s3Client S3ClientAPI, cfg *Config, resultsChan chan<- Result, manifestWriter *ManifestWriter) {
+func generateFiles(ctx context.Context, wg *sync.WaitGroup, deps *workerDeps) {
 	defer wg.Done()
+	cfg := deps.cfg
 	slog.Info("File generator started", "files", cfg.FileCount, "sizeKB", cfg.PutObjectSizeKB)
 
 	// Create files concurrently using a pool of workers
@@ -248,9 +1052,20 @@ func generateFiles(ctx context.Context, wg *sync.WaitGroup, s3Client S3ClientAPI
 	for i := 0; i < cfg.Concurrency; i++ {
 		workerWg.Add(1)
 		go func(workerId int) {
+			endpoints, release := deps.endpoints.forWorker(cfg)
+			defer release()
 			// Initialize random source for key generation
 			localRand := rand.New(rand.NewSource(time.Now().UnixNano()))
+			jitter, _ := parseDelayDistribution(cfg.Jitter)                                    // Already validated in Config.Validate
+			think, _ := parseDelayDistribution(cfg.ThinkTime)                                  // Already validated in Config.Validate
+			keyTmpl, _ := parseKeyTemplate(cfg.KeyTemplate, defaultGeneratorKeyTemplate)       // Already validated in Config.Validate
+			putSizes, _ := parseSizeDistribution(cfg.PutSizeDistribution, cfg.PutObjectSizeKB) // Already validated in Config.Validate
+			pattern, _ := parseDataPattern(cfg.DataPattern)                                    // Already validated in Config.Validate
 			defer workerWg.Done()
+			var workerBucket *tokenBucket
+			if cfg.WorkerRPS > 0 {
+				workerBucket = newTokenBucket(cfg.WorkerRPS, 1)
+			}
 
 			for fileId := range filesChan {
 				// Check for context cancellation
@@ -262,35 +1077,66 @@ func generateFiles(ctx context.Context, wg *sync.WaitGroup, s3Client S3ClientAPI
 					// Continue processing
 				}
 
+				if jitter.enabled() && !sleepContext(ctx, jitter.sample(localRand)) {
+					slog.Info("Generator worker stopping", "workerId", workerId, "reason", ctx.Err())
+					return
+				}
+				var due time.Time
+				if workerBucket != nil {
+					var ok bool
+					if due, ok = workerBucket.WaitScheduled(ctx); !ok {
+						slog.Info("Generator worker stopping", "workerId", workerId, "reason", ctx.Err())
+						return
+					}
+				}
+				if deps.rate != nil {
+					rateDue, ok := deps.rate.WaitScheduled(ctx)
+					if !ok {
+						slog.Info("Generator worker stopping", "workerId", workerId, "reason", ctx.Err())
+						return
+					}
+					due = later(due, rateDue)
+				}
+
 				// Generate a unique key
-				objectKey := fmt.Sprintf("stresser/generated/%d-%s.dat", fileId, randomString(8, localRand))
+				objectKey := keyTmpl.render(localRand, keyVars{worker: workerId, seq: int64(fileId), run: cfg.RunID})
 
-				// Generate unique data for each file to avoid object deduplication
-				data := make([]byte, cfg.PutObjectSizeKB*1024)
-				// Use math/rand which is faster and doesn't risk entropy exhaustion
-				for i := range data {
-					data[i] = byte(localRand.Intn(256))
-				}
+				// Generate unique data for each file to avoid object deduplication, unless the pattern asks for it
+				body := pattern.payload(putSizes.sample(localRand), localRand)
 
 				// Upload the file with unique data
-				result := performPutOperation(ctx, s3Client, cfg.Bucket, objectKey, data)
+				bucket := deps.buckets.pick(localRand, objectKey)
+				target := endpoints.pick(localRand)
+				var result Result
+				deps.threads.run(func() { result = uploadObject(ctx, target.client, cfg, bucket, objectKey, body) })
+				result.IntendedStart = due
+				if deps.buckets.multiple() {
+					result.Bucket = bucket
+				}
+				if endpoints.multiple() {
+					result.Endpoint = target.url
+				}
 
 				// If successful upload and manifest writing is enabled, add the key to manifest
-				if result.Error == "" && manifestWriter != nil {
-					if err := manifestWriter.AddKey(objectKey); err != nil {
+				if result.Error == "" && deps.manifestWriter != nil && cfg.DisconnectFraction == 0 {
+					if err := deps.manifestWriter.AddEntry(ManifestEntry{Key: objectKey, VersionID: result.VersionID, Size: result.BytesUploaded, ETag: result.ETag}); err != nil {
 						slog.Error("Generator worker failed to write key to manifest", "workerId", workerId, "error", err)
 					}
 				}
 
 				// Send result to result channel
 				select {
-				case resultsChan <- result:
+				case deps.resultsChan <- result:
 					// Result sent successfully
 				case <-ctx.Done():
 					// Context cancelled while trying to send
 					slog.Info("Generator worker context cancelled while sending result", "workerId", workerId, "reason", ctx.Err())
 					return
 				}
+				if think.enabled() && !sleepContext(ctx, think.sample(localRand)) {
+					slog.Info("Generator worker stopping", "workerId", workerId, "reason", ctx.Err())
+					return
+				}
 
 				// Log progress periodically
 				if fileId > 0 && fileId%progressCount == 0 {
@@ -305,70 +1151,216 @@ func generateFiles(ctx context.Context, wg *sync.WaitGroup, s3Client S3ClientAPI
 	slog.Info("File generation completed", "files", cfg.FileCount)
 }
 
-// Helper function to avoid division by zero
-func max(a, b int) int {
-	if a > b {
-		return a
+// fetchObject performs a GET, hedged with a second request when hedging is enabled.
+func fetchObject(ctx context.Context, s3Client S3ClientAPI, cfg *Config, hedge *hedger, bucket, key string) Result {
+	if hedge != nil {
+		return performHedgedGet(ctx, s3Client, hedge, bucket, key, cfg.Verify)
 	}
-	return b
+	return performGetOperation(ctx, s3Client, bucket, key, cfg.Verify)
 }
 
 // performGetOperation executes a single S3 GET request and measures timing.
-func performGetOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string) Result {
-	result := Result{
-		Timestamp: time.Now(),
-		Operation: "GET",
-		ObjectKey: key,
-		TTFB:      -1, // Indicate not measured yet / error
-		TTLB:      -1,
-		Error:     "",
-	}
+// With verify, the body is checked against the checksum stored when the object was written.
+func performGetOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string, verify bool) Result {
+	attempt := startGet(ctx, s3Client, bucket, key)
+	return attempt.finish(attempt.start, verify)
+}
 
-	reqStartTime := time.Now()
-	getObjectInput := &s3.GetObjectInput{
+// getAttempt is a GET whose response headers have arrived (or which failed) but whose body
+// hasn't been read yet. Hedged GETs race several attempts and only finish the winner.
+type getAttempt struct {
+	result    Result
+	resp      *s3.GetObjectOutput // nil if the request failed
+	err       error               // Why the request failed
+	start     time.Time
+	headersAt time.Time
+}
+
+// startGet sends the GetObject request and waits for the response headers.
+func startGet(ctx context.Context, s3Client S3ClientAPI, bucket, key string) *getAttempt {
+	return sendGet(ctx, s3Client, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
+	})
+}
+
+// sendGet is startGet for a prepared request, e.g. one with conditional headers.
+func sendGet(ctx context.Context, s3Client S3ClientAPI, getObjectInput *s3.GetObjectInput) *getAttempt {
+	a := &getAttempt{
+		result: Result{
+			Timestamp: time.Now(),
+			Operation: "GET",
+			ObjectKey: aws.ToString(getObjectInput.Key),
+			TTFB:      -1, // Indicate not measured yet / error
+			TTLB:      -1,
+			Error:     "",
+		},
 	}
 
+	a.start = time.Now()
+
 	// Perform the GetObject call
-	resp, err := s3Client.GetObject(ctx, getObjectInput)
-	timeHeadersReceived := time.Now() // Proxy for first byte (time GetObject returned)
+	resp, err := s3Client.GetObject(traceConnection(ctx, &a.result, a.start), getObjectInput)
+	a.headersAt = time.Now() // Proxy for first byte (time GetObject returned)
 
 	if err != nil {
-		result.Error = err.Error()
+		a.err = err
+		a.result.Error = err.Error()
+		recordErrorRequestID(&a.result, err)
 		// slog.Debug("GET operation failed", "bucket", bucket, "key", key, "error", err) // Optional detailed logging
-		return result // Return error result
+		return a
 	}
-	// IMPORTANT: Ensure response body is closed even if errors occur later
-	defer resp.Body.Close()
+	a.resp = resp
+	a.result.ETag = aws.ToString(resp.ETag)
+	recordResponseMetadata(&a.result, resp.ResultMetadata)
 
 	// TTFB (Proxy): Duration until GetObject call returned successfully
-	result.TTFB = timeHeadersReceived.Sub(reqStartTime)
+	a.result.TTFB = a.headersAt.Sub(a.start)
+	return a
+}
+
+// finish reads the response body and returns the result, with latencies measured from start.
+// With verify, the body is hashed and compared with the checksum in the object metadata.
+func (a *getAttempt) finish(start time.Time, verify bool) Result {
+	result, h := a.read(start, verify)
+	if verify && result.Error == "" && a.resp != nil {
+		checkIntegrity(&result, a.resp.Metadata, h)
+	}
+	return result
+}
+
+// read reads the response body and returns the result, with latencies measured from start,
+// and with hashBody the SHA-256 of the body, nil if it wasn't fully read.
+func (a *getAttempt) read(start time.Time, hashBody bool) (Result, hash.Hash) {
+	result := a.result
+	if a.resp == nil {
+		return result, nil // Return error result
+	}
+	// IMPORTANT: Ensure response body is closed even if errors occur later
+	defer a.resp.Body.Close()
+	result.TTFB = a.headersAt.Sub(start)
 
 	// Read the entire body to measure TTLB and BytesDownloaded
 	// Using io.Copy is efficient for large files.
-	bytesDownloaded, err := io.Copy(io.Discard, resp.Body) // Discard data, just count bytes & ensure it's read
+	dst, h := bodyHasher(hashBody)
+	// Compressed bodies are decoded, counting the bytes on the wire as downloaded
+	sampler := &throughputSampler{r: a.resp.Body}
+	first := &firstByteReader{r: sampler}
+	body, wire, err := decodedBody(first, aws.ToString(a.resp.ContentEncoding))
+	var bytesDownloaded int64
+	if err == nil {
+		bytesDownloaded, err = io.Copy(dst, body) // Discard data, just count bytes & ensure it's read
+		body.Close()
+	}
 	timeBodyRead := time.Now()
+	if !first.at.IsZero() {
+		result.BodyTTFB = first.at.Sub(start)
+	}
+	sampler.record(&result)
+	if wire != nil {
+		result.BytesDecoded, bytesDownloaded = bytesDownloaded, wire.n
+	}
 
 	if err != nil {
 		// Error occurred while reading the body *after* headers were received
 		result.Error = fmt.Sprintf("body read error: %v", err)
+		if strings.Contains(err.Error(), checksumMismatch) {
+			result.Checksum = ChecksumMismatch
+			result.Error = fmt.Sprintf("%v: %v", ErrCorruptObject, err)
+		}
 		result.BytesDownloaded = bytesDownloaded // Record bytes read before error
 		// TTLB is duration until the error occurred during read
-		result.TTLB = timeBodyRead.Sub(reqStartTime)
+		result.TTLB = timeBodyRead.Sub(start)
 		// TTFB is still valid as headers were received
-		return result
+		return result, nil
 	}
 
 	// TTLB: Duration until the entire body was successfully read
-	result.TTLB = timeBodyRead.Sub(reqStartTime)
+	result.TTLB = timeBodyRead.Sub(start)
 	result.BytesDownloaded = bytesDownloaded
+	result.Checksum = responseChecksum(a.resp)
 
-	return result // Return success result
+	return result, h // Return success result
+}
+
+// firstByteReader notes when the first byte of a response body was read, which is when
+// the body started to arrive rather than when the SDK returned the response headers.
+type firstByteReader struct {
+	r  io.Reader
+	at time.Time // Zero until a Read returned data
+}
+
+func (f *firstByteReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if n > 0 && f.at.IsZero() {
+		f.at = time.Now()
+	}
+	return n, err
+}
+
+// discard releases the response of an attempt that lost a hedging race.
+func (a *getAttempt) discard() {
+	if a.resp != nil {
+		a.resp.Body.Close()
+	}
+}
+
+// performHeadOperation executes a single S3 HEAD request and measures how long it took.
+func performHeadOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string) Result {
+	result, _, _ := headObject(ctx, s3Client, bucket, key)
+	return result
+}
+
+// headObject is performHeadOperation that also returns the response, nil if the HEAD failed,
+// and why it failed.
+func headObject(ctx context.Context, s3Client S3ClientAPI, bucket, key string) (Result, *s3.HeadObjectOutput, error) {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "HEAD",
+		ObjectKey: key,
+		TTFB:      -1, // Not applicable, a HEAD response has no body
+		TTLB:      -1,
+	}
+
+	reqStartTime := time.Now()
+	resp, err := s3Client.HeadObject(traceConnection(ctx, &result, reqStartTime), &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		result.Error = err.Error()
+		recordErrorRequestID(&result, err)
+		return result, nil, err
+	}
+	recordResponseMetadata(&result, resp.ResultMetadata)
+	result.TTLB = time.Since(reqStartTime)
+	result.ETag = aws.ToString(resp.ETag)
+	return result, resp, nil
+}
+
+// uploadObject performs a PUT, or a deliberately interrupted PUT when disconnect simulation is enabled.
+// Interrupted uploads never leave an object behind, so their keys must not go into the manifest.
+func uploadObject(ctx context.Context, s3Client S3ClientAPI, cfg *Config, bucket, key string, body io.ReadSeeker) Result {
+	if cfg.DisconnectFraction > 0 {
+		return performDisconnectedPutOperation(ctx, s3Client, bucket, key, body, cfg.DisconnectFraction)
+	}
+	return performPutOperation(ctx, s3Client, bucket, key, body, cfg.Verify, cfg.putAttributes())
+}
+
+// performPutOperation executes a single S3 PUT request and measures timing. The body is
+// streamed, so objects of any size are sent without holding them in memory.
+// With verify, the SHA-256 of the body is stored in the object metadata for later GETs to check.
+// attrs adds the configured metadata, headers and tags.
+func performPutOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string, body io.ReadSeeker, verify bool, attrs putAttributes) Result {
+	return sendPut(ctx, s3Client, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, body, verify, attrs)
 }
 
-// performPutOperation executes a single S3 PUT request and measures timing.
-func performPutOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key string, data []byte) Result {
+// sendPut is performPutOperation for a prepared request, e.g. one with conditional headers.
+func sendPut(ctx context.Context, s3Client S3ClientAPI, putObjectInput *s3.PutObjectInput, body io.ReadSeeker, verify bool, attrs putAttributes) Result {
+	bucket, key := aws.ToString(putObjectInput.Bucket), aws.ToString(putObjectInput.Key)
 	result := Result{
 		Timestamp: time.Now(),
 		Operation: "PUT",
@@ -378,28 +1370,41 @@ func performPutOperation(ctx context.Context, s3Client S3ClientAPI, bucket, key
 		Error:     "",
 	}
 
-	reqStartTime := time.Now()
-	putObjectInput := &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(data), // Create a reader from the data slice
-		// ContentLength: aws.Int64(int64(len(data))), // SDK often infers this, but explicit can be good
-		// ContentType: aws.String("application/octet-stream"), // Optional: set content type
+	size, err := bodySize(body)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid PUT body: %v", err)
+		return result
 	}
+	putObjectInput.Body = body
+	putObjectInput.ContentLength = aws.Int64(size)
+	if verify {
+		if putObjectInput.Metadata, err = verifyMetadata(body); err != nil {
+			result.Error = fmt.Sprintf("failed to checksum PUT body: %v", err)
+			return result
+		}
+	}
+	attrs.apply(putObjectInput)
+
+	reqStartTime := time.Now()
 
 	// Perform the PutObject call
-	_, err := s3Client.PutObject(ctx, putObjectInput)
+	resp, err := s3Client.PutObject(traceConnection(ctx, &result, reqStartTime), putObjectInput)
 	timePutCompleted := time.Now()
 
 	if err != nil {
 		result.Error = err.Error()
+		recordErrorRequestID(&result, err)
 		slog.Debug("PUT operation failed", "bucket", bucket, "key", key, "error", err)
 		return result // Return error result
 	}
 
+	recordResponseMetadata(&result, resp.ResultMetadata)
+
 	// TTLB for PUT represents the total time for the operation to complete
 	result.TTLB = timePutCompleted.Sub(reqStartTime)
-	result.BytesUploaded = int64(len(data))
+	result.BytesUploaded = size
+	result.VersionID = aws.ToString(resp.VersionId) // Empty unless the bucket is versioned
+	result.ETag = aws.ToString(resp.ETag)
 
 	return result // Return success result
 }
@@ -0,0 +1,189 @@
+package stresser
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// SweepPoint holds the outcome of one step of a parameter sweep.
+type SweepPoint struct {
+	Value int    // Swept parameter value for this step (e.g. object size in KB)
+	Stats *Stats // Aggregate statistics of the step
+}
+
+// ParseSizeList parses a comma-separated list of sizes such as "4K,64K,1M,16M" into KB.
+// Bare numbers are taken as KB, matching -putsize.
+func ParseSizeList(list string) ([]int, error) {
+	var sizes []int
+	for _, item := range strings.Split(list, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		multiplier := 1
+		switch {
+		case strings.HasSuffix(item, "K"):
+			item = strings.TrimSuffix(item, "K")
+		case strings.HasSuffix(item, "M"):
+			item, multiplier = strings.TrimSuffix(item, "M"), 1024
+		case strings.HasSuffix(item, "G"):
+			item, multiplier = strings.TrimSuffix(item, "G"), 1024*1024
+		}
+		n, err := strconv.Atoi(item)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid size %q in list %q", item, list)
+		}
+		sizes = append(sizes, n*multiplier)
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("size list %q is empty", list)
+	}
+	return sizes, nil
+}
+
+// ParseIntList parses a comma-separated list of positive integers such as "1,2,4,8,16".
+func ParseIntList(list string) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		n, err := strconv.Atoi(item)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid value %q in list %q", item, list)
+		}
+		values = append(values, n)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("list %q is empty", list)
+	}
+	return values, nil
+}
+
+// RunSizeSweep runs the configured workload once per object size in cfg.SizeSweep,
+// each for cfg.Duration, and returns the statistics of every step.
+func RunSizeSweep(ctx context.Context, cfg *Config) ([]SweepPoint, error) {
+	sizes, err := ParseSizeList(cfg.SizeSweep)
+	if err != nil {
+		return nil, err
+	}
+	return runSweep(ctx, cfg, "SizeKB", sizes, func(c *Config, v int) { c.PutObjectSizeKB = v })
+}
+
+// RunConcurrencySweep runs the configured workload once per worker count in cfg.ConcurrencySweep,
+// each for cfg.Duration, producing the data for a latency/throughput saturation curve.
+func RunConcurrencySweep(ctx context.Context, cfg *Config) ([]SweepPoint, error) {
+	levels, err := ParseIntList(cfg.ConcurrencySweep)
+	if err != nil {
+		return nil, err
+	}
+	return runSweep(ctx, cfg, "Concurrency", levels, func(c *Config, v int) { c.Concurrency = v })
+}
+
+// runSweep executes one stress test per value, applying each value to a copy of cfg.
+// Steps always run for the full duration, so fixed file count generation and manifest
+// writing are disabled; otherwise every step would overwrite the previous step's manifest.
+func runSweep(ctx context.Context, cfg *Config, param string, values []int, apply func(*Config, int)) ([]SweepPoint, error) {
+	points := make([]SweepPoint, 0, len(values))
+	for i, v := range values {
+		if ctx.Err() != nil {
+			slog.Info("Sweep interrupted", "completedSteps", i, "reason", ctx.Err())
+			break
+		}
+
+		stepCfg := *cfg
+		stepCfg.FileCount = 0
+		stepCfg.GenerateManifest = false
+		apply(&stepCfg, v)
+
+		slog.Info("Starting sweep step", "step", i+1, "of", len(values), param, v)
+		_, stats, err := RunStressTest(ctx, &stepCfg)
+		if err != nil {
+			return points, fmt.Errorf("sweep step %s=%d failed: %w", param, v, err)
+		}
+		points = append(points, SweepPoint{Value: v, Stats: stats})
+	}
+	return points, nil
+}
+
+// sweepRow renders the headline figures of a sweep step.
+func sweepRow(p SweepPoint) []string {
+	return append([]string{strconv.Itoa(p.Value)}, statsRow(p.Stats)...)
+}
+
+// statsRow renders the headline figures of a run, as shown by the sweep and test plan tables.
+func statsRow(s *Stats) []string {
+	reqPerSec, mibPerSec := float64(0), float64(0)
+	if secs := s.actualDuration.Seconds(); secs > 0 {
+		reqPerSec = float64(s.TotalRequests) / secs
+		mibPerSec = (float64(s.TotalBytesDown+s.TotalBytesUp) / (1024 * 1024)) / secs
+	}
+	return []string{
+		strconv.FormatInt(s.TotalRequests, 10),
+		strconv.FormatInt(s.TotalErrors, 10),
+		fmt.Sprintf("%.2f", reqPerSec),
+		fmt.Sprintf("%.2f", mibPerSec),
+		fmt.Sprintf("%.2f", ms(s.P50GetTTLB)),
+		fmt.Sprintf("%.2f", ms(s.P99GetTTLB)),
+		fmt.Sprintf("%.2f", ms(s.P50PutTTLB)),
+		fmt.Sprintf("%.2f", ms(s.P99PutTTLB)),
+	}
+}
+
+// sweepHeader returns the column names used by PrintSweepTable and WriteSweepCSV.
+func sweepHeader(param string) []string {
+	return append([]string{param}, statsHeader()...)
+}
+
+// statsHeader returns the column names of statsRow.
+func statsHeader() []string {
+	return []string{"Requests", "Errors", "Req/s", "MiB/s", "GET P50(ms)", "GET P99(ms)", "PUT P50(ms)", "PUT P99(ms)"}
+}
+
+// PrintSweepTable prints one row per sweep step to the given writer.
+func PrintSweepTable(w io.Writer, param string, points []SweepPoint) {
+	fmt.Fprintf(w, "\n--- Sweep Summary (%s) ---\n", param)
+	for _, col := range sweepHeader(param) {
+		fmt.Fprintf(w, "%12s ", col)
+	}
+	fmt.Fprintln(w)
+	for _, p := range points {
+		for _, col := range sweepRow(p) {
+			fmt.Fprintf(w, "%12s ", col)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "----------------------------------------\n")
+}
+
+// WriteSweepCSV writes the sweep table to a CSV file for plotting, or to standard output for StdoutPath.
+func WriteSweepCSV(points []SweepPoint, param, filePath string) error {
+	file, err := OpenOutput(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(sweepHeader(param)); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+	for _, p := range points {
+		if err := writer.Write(sweepRow(p)); err != nil {
+			return fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error during csv writing/flushing: %w", err)
+	}
+
+	slog.Info("Sweep results written", "file", filePath)
+	return nil
+}
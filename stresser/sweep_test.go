@@ -0,0 +1,75 @@
+package stresser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSizeList(t *testing.T) {
+	sizes, err := ParseSizeList("4K, 64k,1M,16M,256,1G")
+	if err != nil {
+		t.Fatalf("ParseSizeList failed: %v", err)
+	}
+	expected := []int{4, 64, 1024, 16 * 1024, 256, 1024 * 1024}
+	if len(sizes) != len(expected) {
+		t.Fatalf("Expected %d sizes, got %d", len(expected), len(sizes))
+	}
+	for i := range expected {
+		if sizes[i] != expected[i] {
+			t.Errorf("Size %d: expected %d KB, got %d KB", i, expected[i], sizes[i])
+		}
+	}
+
+	for _, invalid := range []string{"", "4X", "0K", "-1M", ","} {
+		if _, err := ParseSizeList(invalid); err == nil {
+			t.Errorf("Expected error for size list %q", invalid)
+		}
+	}
+}
+
+func TestWriteSweepCSV(t *testing.T) {
+	now := time.Now()
+	stats := NewStats()
+	stats.AddResult(Result{Timestamp: now, Operation: "PUT", ObjectKey: "k", TTFB: -1, TTLB: 20 * time.Millisecond, BytesUploaded: 4096})
+	stats.Calculate(now, now.Add(time.Second))
+
+	path := filepath.Join(t.TempDir(), "sweep.csv")
+	if err := WriteSweepCSV([]SweepPoint{{Value: 4, Stats: stats}}, "SizeKB", path); err != nil {
+		t.Fatalf("WriteSweepCSV failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read sweep CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header and 1 row, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "SizeKB,Requests,Errors") {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "4,1,0,1.00,") {
+		t.Errorf("Unexpected row: %s", lines[1])
+	}
+}
+
+func TestParseIntList(t *testing.T) {
+	values, err := ParseIntList("1, 2,4,16")
+	if err != nil {
+		t.Fatalf("ParseIntList failed: %v", err)
+	}
+	expected := []int{1, 2, 4, 16}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("Value %d: expected %d, got %d", i, expected[i], values[i])
+		}
+	}
+	for _, invalid := range []string{"", "0", "a,b", "4,-2"} {
+		if _, err := ParseIntList(invalid); err == nil {
+			t.Errorf("Expected error for list %q", invalid)
+		}
+	}
+}
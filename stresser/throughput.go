@@ -0,0 +1,162 @@
+package stresser
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// throughputWindow is the interval over which GET bodies are sampled for their throughput.
+const throughputWindow = 100 * time.Millisecond
+
+// rateResolution is the smallest throughput, in MiB/s, told apart when throughputs are
+// recorded in a Histogram, which counts integer units.
+const rateResolution = 1e-6
+
+// throughputSampler counts the bytes of a response body read in each throughputWindow,
+// starting with the first byte, so a transfer that stalls midway shows up as a slow window
+// even if its average over the whole body looks fine. Only full windows are counted.
+type throughputSampler struct {
+	r           io.Reader
+	windowStart time.Time // Zero until a Read returned data
+	n           int64     // Bytes read in the current window
+	windows     int       // Full windows so far
+	min         int64     // Fewest bytes read in a full window
+}
+
+func (s *throughputSampler) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	now := time.Now()
+	if s.windowStart.IsZero() {
+		if n > 0 {
+			s.windowStart = now
+		}
+	} else {
+		// Close the windows that ended before this read returned; data that arrives after a
+		// stall counts toward the window it arrived in
+		for now.Sub(s.windowStart) >= throughputWindow {
+			if s.windows == 0 || s.n < s.min {
+				s.min = s.n
+			}
+			s.windows++
+			s.n = 0
+			s.windowStart = s.windowStart.Add(throughputWindow)
+		}
+	}
+	s.n += int64(n)
+	return n, err
+}
+
+// record stores the slowest window in result, for bodies read over at least one window.
+func (s *throughputSampler) record(result *Result) {
+	if s.windows == 0 {
+		return
+	}
+	result.Windows = s.windows
+	result.MinWindowMiBps = float64(s.min) / throughputWindow.Seconds() / (1024 * 1024)
+}
+
+// bodyMiBps returns the throughput of a GET from its first to its last body byte.
+func (r Result) bodyMiBps() float64 {
+	d := r.TTLB - r.BodyTTFB
+	if d <= 0 {
+		return 0
+	}
+	return float64(r.BytesDownloaded) / d.Seconds() / (1024 * 1024)
+}
+
+// RateSummary describes the spread of a throughput over requests, in MiB/s. The low
+// percentiles are the slow tail.
+type RateSummary struct {
+	Min float64 `json:"min" yaml:"min"`
+	P1  float64 `json:"p1" yaml:"p1"`
+	P10 float64 `json:"p10" yaml:"p10"`
+	P50 float64 `json:"p50" yaml:"p50"`
+	Avg float64 `json:"avg" yaml:"avg"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+// recordRate adds a throughput in MiB/s to h, in units of rateResolution.
+func recordRate(h *Histogram, mibps float64) {
+	h.Record(time.Duration(math.Round(mibps / rateResolution)))
+}
+
+// rateSummary summarizes the throughputs recorded with recordRate, or returns nil if there
+// are none.
+func rateSummary(h *Histogram) *RateSummary {
+	if h.Count() == 0 {
+		return nil
+	}
+	at := func(d time.Duration) float64 { return float64(d) * rateResolution }
+	return &RateSummary{Min: at(h.Min()), P1: at(h.Percentile(1)), P10: at(h.Percentile(10)), P50: at(h.Percentile(50)), Avg: at(h.Mean()), Max: at(h.Max())}
+}
+
+// ThroughputReport summarizes the throughput of GETs whose body took at least one sampling
+// window to read.
+type ThroughputReport struct {
+	WindowMs   float64      `json:"windowMs" yaml:"windowMs"`
+	Sampled    int64        `json:"sampled" yaml:"sampled"`
+	Stalled    int64        `json:"stalled" yaml:"stalled"` // GETs with a window in which no data arrived
+	StalledPct float64      `json:"stalledPct" yaml:"stalledPct"`
+	MinWindow  *RateSummary `json:"minWindowMiBps,omitempty" yaml:"minWindowMiBps,omitempty"` // Per GET: its slowest window
+	Body       *RateSummary `json:"bodyMiBps,omitempty" yaml:"bodyMiBps,omitempty"`           // Per GET: from the first to the last body byte
+}
+
+// throughputStats holds the sampled throughputs of GETs, see recordRate.
+type throughputStats struct {
+	minWindow *Histogram
+	body      *Histogram
+	stalled   int64
+}
+
+// addThroughputResult records a successful GET that was sampled. Called from AddResult.
+func (s *Stats) addThroughputResult(r Result) {
+	if r.Operation != "GET" || r.Error != "" || r.Windows == 0 {
+		return
+	}
+	if s.throughput == nil {
+		s.throughput = &throughputStats{minWindow: NewHistogram(), body: NewHistogram()}
+	}
+	recordRate(s.throughput.minWindow, r.MinWindowMiBps)
+	recordRate(s.throughput.body, r.bodyMiBps())
+	if r.MinWindowMiBps == 0 {
+		s.throughput.stalled++
+	}
+}
+
+// GetThroughput returns the sampled GET throughputs, or nil if no GET lasted a full window.
+func (s *Stats) GetThroughput() *ThroughputReport {
+	if s.throughput == nil {
+		return nil
+	}
+	tr := &ThroughputReport{
+		WindowMs:  ms(throughputWindow),
+		Sampled:   s.throughput.minWindow.Count(),
+		Stalled:   s.throughput.stalled,
+		MinWindow: rateSummary(s.throughput.minWindow),
+		Body:      rateSummary(s.throughput.body),
+	}
+	tr.StalledPct = percentOf(tr.Stalled, tr.Sampled)
+	return tr
+}
+
+// printThroughputSummary prints the spread of per-GET throughput as part of PrintSummary.
+func (s *Stats) printThroughputSummary(w io.Writer) {
+	tr := s.GetThroughput()
+	if tr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nGET Throughput per Request (%.0f ms windows):\n", tr.WindowMs)
+	fmt.Fprintf(w, "  Sampled GETs: %d (bodies read over at least one window)\n", tr.Sampled)
+	fmt.Fprintf(w, "  Stalled GETs: %d (%.2f%%) with a window without data\n", tr.Stalled, tr.StalledPct)
+	fmt.Fprintf(w, "  MiB/s:        |   Min  |   P1   |   P10  |   P50  |   Avg  |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------\n")
+	for _, row := range []struct {
+		label string
+		r     *RateSummary
+	}{{"Min Window", tr.MinWindow}, {"Whole Body", tr.Body}} {
+		fmt.Fprintf(w, "  %-13s |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+			row.label, row.r.Min, row.r.P1, row.r.P10, row.r.P50, row.r.Avg, row.r.Max)
+	}
+}
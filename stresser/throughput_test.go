@@ -0,0 +1,73 @@
+package stresser
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stallingReader returns its chunks one per Read, sleeping before each for its delay.
+type stallingReader struct {
+	chunks []string
+	delays []time.Duration
+}
+
+func (s *stallingReader) Read(p []byte) (int, error) {
+	if len(s.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(s.delays[0])
+	n := copy(p, s.chunks[0])
+	s.chunks, s.delays = s.chunks[1:], s.delays[1:]
+	return n, nil
+}
+
+func TestThroughputSampler(t *testing.T) {
+	chunk := strings.Repeat("x", 1024)
+	var result Result
+	fast := &throughputSampler{r: strings.NewReader(chunk)}
+	io.Copy(io.Discard, fast)
+	fast.record(&result)
+	if result.Windows != 0 {
+		t.Errorf("Expected no full window for a fast body, got %+v", result)
+	}
+
+	// Data, then a stall of more than two windows, then the rest
+	s := &throughputSampler{r: &stallingReader{
+		chunks: []string{chunk, chunk, chunk},
+		delays: []time.Duration{0, 0, 2*throughputWindow + throughputWindow/2},
+	}}
+	if n, err := io.Copy(io.Discard, s); err != nil || n != 3072 {
+		t.Fatalf("Expected the body to pass through, got %d bytes: %v", n, err)
+	}
+	s.record(&result)
+	if result.Windows < 2 || result.MinWindowMiBps != 0 {
+		t.Errorf("Expected a window without data, got %d windows with a minimum of %.3f MiB/s", result.Windows, result.MinWindowMiBps)
+	}
+}
+
+func TestThroughputStats(t *testing.T) {
+	stats := NewStats()
+	now := time.Now()
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, BodyTTFB: time.Millisecond, TTLB: 1001 * time.Millisecond,
+		BytesDownloaded: 10 << 20, Windows: 10, MinWindowMiBps: 5})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, BodyTTFB: time.Millisecond, TTLB: 2001 * time.Millisecond,
+		BytesDownloaded: 10 << 20, Windows: 20})
+	stats.AddResult(Result{Timestamp: now, Operation: "GET", TTFB: time.Millisecond, TTLB: 2 * time.Millisecond, BytesDownloaded: 1024})
+	stats.Calculate(now, now.Add(time.Second))
+
+	tr := stats.Summary().GetThroughput
+	if tr == nil || tr.Sampled != 2 || tr.Stalled != 1 || tr.StalledPct != 50 {
+		t.Fatalf("Unexpected throughput report %+v", tr)
+	}
+	if tr.MinWindow.Min != 0 || tr.MinWindow.Max != 5 || tr.Body.Min != 5 || tr.Body.Max != 10 || tr.Body.Avg != 7.5 {
+		t.Errorf("Unexpected rates: min window %+v, body %+v", tr.MinWindow, tr.Body)
+	}
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "GET Throughput per Request") || !strings.Contains(buf.String(), "Stalled GETs: 1 (50.00%)") {
+		t.Errorf("Summary lacks the throughput figures:\n%s", buf.String())
+	}
+}
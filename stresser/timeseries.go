@@ -0,0 +1,155 @@
+package stresser
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeSeriesInterval is the length of the buckets in time-series output.
+const TimeSeriesInterval = time.Second
+
+// TimeSeriesPoint aggregates the requests that completed in one interval of the run.
+type TimeSeriesPoint struct {
+	Start        time.Time `json:"start"`
+	Offset       float64   `json:"offsetSeconds"` // Seconds since the start of the measured run
+	Requests     int64     `json:"requests"`
+	Errors       int64     `json:"errors"`
+	Bytes        int64     `json:"bytes"`
+	RequestsPerS float64   `json:"requestsPerSec"`
+	ErrorsPerS   float64   `json:"errorsPerSec"`
+	MiBPerS      float64   `json:"throughputMiBps"`
+	P99TTLBMs    float64   `json:"p99TtlbMs"` // Over successful requests, 0 if there were none
+}
+
+// timeSeriesBucket collects the results of one interval while the run is aggregated.
+type timeSeriesBucket struct {
+	requests int64
+	errors   int64
+	bytes    int64
+	ttlbs    *Histogram // Latencies of successful requests
+}
+
+// addTimeSeriesResult records a result in the interval its request completed in.
+// Called from AddResult.
+func (s *Stats) addTimeSeriesResult(r Result) {
+	if r.Timestamp.IsZero() {
+		return
+	}
+	if s.timeSeries == nil {
+		s.timeSeries = make(map[int64]*timeSeriesBucket)
+	}
+	end := r.Timestamp
+	if r.TTLB > 0 {
+		end = end.Add(r.TTLB)
+	}
+	slot := end.UnixNano() / int64(TimeSeriesInterval)
+	b, ok := s.timeSeries[slot]
+	if !ok {
+		b = &timeSeriesBucket{ttlbs: newCoarseHistogram()}
+		s.timeSeries[slot] = b
+	}
+	b.requests++
+	b.bytes += r.BytesDownloaded + r.BytesUploaded
+	if r.Error != "" {
+		b.errors++
+		return
+	}
+	b.ttlbs.Record(r.TTLB)
+}
+
+// TimeSeries returns one point per interval from the start to the end of the measured run,
+// including intervals without completed requests, so stalls show up as zero rows.
+func (s *Stats) TimeSeries() []TimeSeriesPoint {
+	if len(s.timeSeries) == 0 || s.startTime.IsZero() {
+		return nil
+	}
+	interval := int64(TimeSeriesInterval)
+	first := s.startTime.UnixNano() / interval
+	last := s.endTime.UnixNano() / interval
+	if last < first {
+		last = first
+	}
+
+	secs := TimeSeriesInterval.Seconds()
+	points := make([]TimeSeriesPoint, 0, last-first+1)
+	for slot := first; slot <= last; slot++ {
+		start := time.Unix(0, slot*interval).UTC()
+		p := TimeSeriesPoint{Start: start, Offset: start.Sub(s.startTime.Truncate(TimeSeriesInterval)).Seconds()}
+		if b, ok := s.timeSeries[slot]; ok {
+			p.Requests = b.requests
+			p.Errors = b.errors
+			p.Bytes = b.bytes
+			p.RequestsPerS = float64(b.requests) / secs
+			p.ErrorsPerS = float64(b.errors) / secs
+			p.MiBPerS = (float64(b.bytes) / (1024 * 1024)) / secs
+			if b.ttlbs.Count() > 0 {
+				p.P99TTLBMs = ms(b.ttlbs.Percentile(99))
+			}
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// WriteTimeSeries writes the time series of a calculated Stats as CSV or JSON.
+func WriteTimeSeries(w io.Writer, s *Stats, format string) error {
+	points := s.TimeSeries()
+	switch format {
+	case FormatJSON:
+		if points == nil {
+			points = []TimeSeriesPoint{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(points); err != nil {
+			return fmt.Errorf("failed to write time series: %w", err)
+		}
+		return nil
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"Start", "OffsetSeconds", "Requests", "Errors", "Bytes", "RequestsPerSec", "ErrorsPerSec", "ThroughputMiBps", "P99TTLBMs"})
+		for _, p := range points {
+			cw.Write([]string{
+				p.Start.Format(time.RFC3339),
+				strconv.FormatFloat(p.Offset, 'f', 0, 64),
+				strconv.FormatInt(p.Requests, 10),
+				strconv.FormatInt(p.Errors, 10),
+				strconv.FormatInt(p.Bytes, 10),
+				strconv.FormatFloat(p.RequestsPerS, 'f', 2, 64),
+				strconv.FormatFloat(p.ErrorsPerS, 'f', 2, 64),
+				strconv.FormatFloat(p.MiBPerS, 'f', 3, 64),
+				strconv.FormatFloat(p.P99TTLBMs, 'f', 3, 64),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write time series: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported time series format %q (supported: csv, json)", format)
+}
+
+// WriteTimeSeriesFile writes the time series to path, "-" for stdout. Paths ending in .json
+// get JSON, anything else CSV.
+func WriteTimeSeriesFile(s *Stats, path string) error {
+	format := FormatCSV
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = FormatJSON
+	}
+	out, err := OpenOutput(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTimeSeries(out, s, format); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,53 @@
+package stresser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeSeries(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	stats := NewStats()
+	for i := 0; i < 10; i++ {
+		stats.AddResult(Result{Timestamp: start.Add(100 * time.Millisecond), Operation: "GET", TTFB: time.Millisecond, TTLB: 10 * time.Millisecond, BytesDownloaded: 1024 * 1024})
+	}
+	// Nothing completes in the second interval; the third has a slow request and an error
+	stats.AddResult(Result{Timestamp: start.Add(1900 * time.Millisecond), Operation: "GET", TTFB: time.Millisecond, TTLB: 500 * time.Millisecond, BytesDownloaded: 1024 * 1024})
+	stats.AddResult(Result{Timestamp: start.Add(2500 * time.Millisecond), Operation: "PUT", TTFB: -1, TTLB: -1, Error: "timeout"})
+	stats.Calculate(start, start.Add(3*time.Second))
+
+	points := stats.TimeSeries()
+	if len(points) != 4 {
+		t.Fatalf("Expected 4 points, got %d", len(points))
+	}
+	if points[0].Requests != 10 || points[0].MiBPerS != 10 || points[0].P99TTLBMs != 10 {
+		t.Errorf("Unexpected first point: %+v", points[0])
+	}
+	if points[1].Requests != 0 || points[1].Offset != 1 {
+		t.Errorf("Expected an empty second point, got %+v", points[1])
+	}
+	if points[2].Requests != 2 || points[2].ErrorsPerS != 1 || points[2].P99TTLBMs != 500 {
+		t.Errorf("Unexpected third point: %+v", points[2])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTimeSeries(&buf, stats, FormatCSV); err != nil {
+		t.Fatalf("WriteTimeSeries failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "Start,OffsetSeconds,") {
+		t.Errorf("Unexpected CSV output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteTimeSeries(&buf, stats, FormatJSON); err != nil {
+		t.Fatalf("WriteTimeSeries failed: %v", err)
+	}
+	var decoded []TimeSeriesPoint
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 4 {
+		t.Errorf("Unexpected JSON output (%v):\n%s", err, buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+package stresser
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsVersions maps the values of -tls-min-version to their protocol versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsConfigured reports whether any TLS client setting differs from Go's defaults.
+func (c *Config) tlsConfigured() bool {
+	return c.InsecureSkipVerify || c.TLSCAFile != "" || c.TLSCertFile != "" || c.TLSMinVersion != ""
+}
+
+// validateTLS checks the TLS client settings without reading the files they name.
+func (c *Config) validateTLS() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("a client certificate (-tls-cert-file) and its key (-tls-key-file) must be given together")
+	}
+	if _, ok := tlsVersions[c.TLSMinVersion]; c.TLSMinVersion != "" && !ok {
+		return fmt.Errorf("invalid minimum TLS version (-tls-min-version): %s. Must be '1.0', '1.1', '1.2' or '1.3'", c.TLSMinVersion)
+	}
+	return nil
+}
+
+// newTLSConfig returns the TLS configuration of the S3 client: the CA bundle that server
+// certificates are verified against, the client certificate presented for mutual TLS and the
+// minimum protocol version. It returns nil if all of them are left at Go's defaults.
+func newTLSConfig(cfg *Config) (*tls.Config, error) {
+	if !cfg.tlsConfigured() {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		// The bundle adds to the system roots, so public endpoints keep working
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", cfg.TLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.TLSMinVersion != "" {
+		tlsCfg.MinVersion = tlsVersions[cfg.TLSMinVersion]
+	}
+	return tlsCfg, nil
+}
@@ -0,0 +1,102 @@
+package stresser
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to dir as PEM files.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ostresser"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	var clientCN string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	certFile, keyFile := writeClientCert(t, dir)
+
+	if tlsCfg, err := newTLSConfig(&Config{}); tlsCfg != nil || err != nil {
+		t.Errorf("Expected no TLS config by default, got %v (%v)", tlsCfg, err)
+	}
+	tlsCfg, err := newTLSConfig(&Config{TLSCAFile: caFile, TLSCertFile: certFile, TLSKeyFile: keyFile, TLSMinVersion: "1.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsCfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3 as minimum version, got %x", tlsCfg.MinVersion)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Request with the CA bundle and client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+	if clientCN != "ostresser" {
+		t.Errorf("Expected the server to see the client certificate, got %q", clientCN)
+	}
+
+	if _, err := newTLSConfig(&Config{TLSCAFile: certFile + ".missing"}); err == nil {
+		t.Error("Expected an error for a missing CA bundle")
+	}
+	if _, err := newTLSConfig(&Config{TLSCAFile: keyFile}); err == nil {
+		t.Error("Expected an error for a CA bundle without certificates")
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	for _, c := range []Config{{TLSCertFile: "cert.pem"}, {TLSKeyFile: "key.pem"}, {TLSMinVersion: "1.4"}} {
+		if err := c.validateTLS(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+	c := Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSMinVersion: "1.2"}
+	if err := c.validateTLS(); err != nil {
+		t.Error(err)
+	}
+}
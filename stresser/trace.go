@@ -0,0 +1,116 @@
+package stresser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceHeader identifies trace files and documents the column layout.
+const traceHeader = "# ostresser trace v1: timestamp_us offset_us op size key"
+
+// TraceWriter records every executed operation to a compact, tab-separated trace file
+// that can later be re-executed with the "trace" replay format.
+type TraceWriter struct {
+	filePath string
+	file     *os.File
+	writer   *bufio.Writer
+	mu       sync.Mutex
+}
+
+// NewTraceWriter creates (or truncates) a trace file and writes its header.
+func NewTraceWriter(filePath string) (*TraceWriter, error) {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file %s: %w", filePath, err)
+	}
+
+	tw := &TraceWriter{
+		filePath: filePath,
+		file:     file,
+		writer:   bufio.NewWriter(file),
+	}
+	if _, err := tw.writer.WriteString(traceHeader + "\n"); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write trace header: %w", err)
+	}
+	return tw, nil
+}
+
+// Record appends one operation. offset is the time since the start of the run.
+func (tw *TraceWriter) Record(r Result, offset time.Duration) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	size := r.BytesDownloaded
+	if r.Operation == "PUT" {
+		size = r.BytesUploaded
+	}
+	_, err := fmt.Fprintf(tw.writer, "%d\t%d\t%s\t%d\t%s\n",
+		r.Timestamp.UnixMicro(), offset.Microseconds(), r.Operation, size, r.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("failed to write trace record: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered records and closes the trace file.
+func (tw *TraceWriter) Close() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if err := tw.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush trace writer: %w", err)
+	}
+	if err := tw.file.Close(); err != nil {
+		return fmt.Errorf("failed to close trace file: %w", err)
+	}
+	return nil
+}
+
+// parseTrace reads a trace written by TraceWriter. Offsets are taken from the trace
+// so the replay reproduces the original run's schedule.
+func parseTrace(r io.Reader) ([]ReplayOp, int, error) {
+	scanner := bufio.NewScanner(r)
+
+	var timed []timedReplayOp
+	skipped := 0
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) != 5 {
+			return nil, 0, fmt.Errorf("line %d: expected 5 tab-separated fields, got %d", lineNum, len(fields))
+		}
+		offsetUs, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid offset %q", lineNum, fields[1])
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid size %q", lineNum, fields[3])
+		}
+		op := replayOperation(fields[2])
+		if op == "" {
+			skipped++
+			continue
+		}
+		// The offset is relative to the recorded run start, so use it as the sort time directly
+		at := time.Unix(0, 0).Add(time.Duration(offsetUs) * time.Microsecond)
+		timed = append(timed, timedReplayOp{at: at, op: ReplayOp{Operation: op, ObjectKey: fields[4], Size: size}})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return normalizeReplayOps(timed), skipped, nil
+}
@@ -0,0 +1,58 @@
+package stresser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.trace")
+
+	tw, err := NewTraceWriter(path)
+	if err != nil {
+		t.Fatalf("NewTraceWriter failed: %v", err)
+	}
+
+	start := time.Now()
+	results := []Result{
+		{Timestamp: start.Add(1500 * time.Millisecond), Operation: "GET", ObjectKey: "dir/b.dat", BytesDownloaded: 512},
+		{Timestamp: start.Add(500 * time.Millisecond), Operation: "PUT", ObjectKey: "dir/a.dat", BytesUploaded: 1024},
+	}
+	for _, r := range results {
+		if err := tw.Record(r, r.Timestamp.Sub(start)); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), traceHeader) {
+		t.Errorf("Trace file does not start with header: %q", content)
+	}
+
+	ops, err := LoadReplayOps(path, "")
+	if err != nil {
+		t.Fatalf("LoadReplayOps failed: %v", err)
+	}
+	expected := []ReplayOp{
+		{Offset: 0, Operation: "PUT", ObjectKey: "dir/a.dat", Size: 1024},
+		{Offset: time.Second, Operation: "GET", ObjectKey: "dir/b.dat", Size: 512},
+	}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %d operations, got %d", len(expected), len(ops))
+	}
+	for i, op := range ops {
+		if op != expected[i] {
+			t.Errorf("Operation %d: expected %+v, got %+v", i, expected[i], op)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dashboardInterval = time.Second // How often the dashboard is redrawn
+	dashboardHistory  = 60          // Samples kept for the sparklines
+	dashboardLogLines = 6           // Most recent log lines shown
+	dashboardMaxLogs  = 1000        // Log lines kept to print again once the dashboard closes
+)
+
+// sparkBlocks are the bar heights of a sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboardSample holds the figures of one redraw interval.
+type dashboardSample struct {
+	requestsPerS float64
+	mibPerS      float64
+	errorsPerS   float64
+	p50Ms        float64
+	p99Ms        float64
+}
+
+// dashboard is the live terminal view of -tui: sparklines of the request rate, throughput,
+// latency and errors, the active workers, totals per operation and the most common error
+// classes. It draws on the terminal's alternate screen, so the summary printed after the
+// run ends up in the normal scrollback. Log output is shown in the dashboard while it is
+// open and printed again once it closes.
+type dashboard struct {
+	w        io.Writer
+	start    time.Time
+	length   time.Duration // Planned run length including warm-up
+	runID    string
+	opType   string
+	workers  int
+	limit    *workerLimit
+	arrivals *arrivalScheduler
+
+	mu          sync.Mutex
+	total       int64
+	errors      int64
+	bytes       int64
+	ops         map[string]int64
+	errClasses  map[string]int64
+	last        time.Time
+	intRequests int64
+	intErrors   int64
+	intBytes    int64
+	intTTLBs    []time.Duration
+	history     []dashboardSample
+	logs        []string
+
+	prevLogger *slog.Logger
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+func newDashboard(w io.Writer, cfg *Config, start time.Time, length time.Duration, limit *workerLimit, arrivals *arrivalScheduler) *dashboard {
+	return &dashboard{
+		w:          w,
+		start:      start,
+		length:     length,
+		runID:      cfg.RunID,
+		opType:     cfg.OperationType,
+		workers:    cfg.Concurrency,
+		limit:      limit,
+		arrivals:   arrivals,
+		ops:        make(map[string]int64),
+		errClasses: make(map[string]int64),
+		last:       start,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// add records a completed operation.
+func (d *dashboard) add(r Result) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.total++
+	d.ops[r.Operation]++
+	d.intRequests++
+	bytes := r.BytesDownloaded + r.BytesUploaded
+	d.bytes += bytes
+	d.intBytes += bytes
+	if r.Error != "" {
+		d.errors++
+		d.intErrors++
+		d.errClasses[ClassifyError(r.Error)]++
+		return
+	}
+	d.intTTLBs = append(d.intTTLBs, r.TTLB)
+}
+
+// run takes over the terminal and redraws the dashboard every interval until Stop is called.
+// Log output is captured for the dashboard in the meantime.
+func (d *dashboard) run(interval time.Duration) {
+	defer close(d.stopped)
+	d.prevLogger = slog.Default()
+	slog.SetDefault(slog.New(&dashboardLogHandler{level: d.prevLogger.Handler(), text: slog.NewTextHandler(dashboardLogWriter{d}, nil)}))
+
+	fmt.Fprint(d.w, "\033[?1049h\033[?25l") // Alternate screen, hide the cursor
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			fmt.Fprint(d.w, "\033[?25h\033[?1049l") // Show the cursor, back to the normal screen
+			slog.SetDefault(d.prevLogger)
+			d.mu.Lock()
+			for _, line := range d.logs {
+				fmt.Fprintln(d.w, line)
+			}
+			d.mu.Unlock()
+			return
+		case now := <-ticker.C:
+			d.sample(now)
+			fmt.Fprint(d.w, "\033[H\033[2J"+d.render(now))
+		}
+	}
+}
+
+// Stop restores the terminal and waits for the dashboard to finish writing.
+func (d *dashboard) Stop() {
+	close(d.done)
+	<-d.stopped
+}
+
+// sample closes the interval ending at now and adds it to the history.
+func (d *dashboard) sample(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	secs := now.Sub(d.last).Seconds()
+	if secs <= 0 {
+		return
+	}
+	s := dashboardSample{
+		requestsPerS: float64(d.intRequests) / secs,
+		mibPerS:      float64(d.intBytes) / (1024 * 1024) / secs,
+		errorsPerS:   float64(d.intErrors) / secs,
+	}
+	if len(d.intTTLBs) > 0 {
+		sortDurations(d.intTTLBs)
+		s.p50Ms = ms(percentileDuration(d.intTTLBs, 50))
+		s.p99Ms = ms(percentileDuration(d.intTTLBs, 99))
+	}
+	d.history = append(d.history, s)
+	if len(d.history) > dashboardHistory {
+		d.history = d.history[len(d.history)-dashboardHistory:]
+	}
+	d.last = now
+	d.intRequests, d.intErrors, d.intBytes = 0, 0, 0
+	d.intTTLBs = d.intTTLBs[:0]
+}
+
+// render returns the dashboard as of now.
+func (d *dashboard) render(now time.Time) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var b strings.Builder
+
+	elapsed := now.Sub(d.start).Round(time.Second)
+	fmt.Fprintf(&b, " ostresser  run %s  op %s  elapsed %s / %s\n", d.runID, d.opType, elapsed, d.length.Round(time.Second))
+	workers := fmt.Sprintf("%d", d.workers)
+	if d.limit != nil && d.limit.Get() != d.workers {
+		workers = fmt.Sprintf("%d of %d active", d.limit.Get(), d.workers)
+	}
+	fmt.Fprintf(&b, " Workers: %s", workers)
+	if d.arrivals != nil {
+		ar := d.arrivals.Report()
+		fmt.Fprintf(&b, "  Arrivals: %.0f/s, %d dropped", ar.Rate, ar.Dropped)
+	}
+	fmt.Fprintf(&b, "\n\n")
+
+	fmt.Fprintf(&b, " Requests: %d  Errors: %d (%.2f%%)  Transferred: %.1f MiB\n",
+		d.total, d.errors, percentOf(d.errors, d.total), float64(d.bytes)/(1024*1024))
+	ops := make([]string, 0, len(d.ops))
+	for op := range d.ops {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for i, op := range ops {
+		ops[i] = fmt.Sprintf("%s %d", op, d.ops[op])
+	}
+	fmt.Fprintf(&b, " By operation: %s\n\n", strings.Join(ops, "  "))
+
+	series := func(f func(dashboardSample) float64) []float64 {
+		values := make([]float64, len(d.history))
+		for i, s := range d.history {
+			values[i] = f(s)
+		}
+		return values
+	}
+	for _, row := range []struct {
+		label  string
+		values []float64
+	}{
+		{"req/s", series(func(s dashboardSample) float64 { return s.requestsPerS })},
+		{"MiB/s", series(func(s dashboardSample) float64 { return s.mibPerS })},
+		{"P50 ms", series(func(s dashboardSample) float64 { return s.p50Ms })},
+		{"P99 ms", series(func(s dashboardSample) float64 { return s.p99Ms })},
+		{"errors/s", series(func(s dashboardSample) float64 { return s.errorsPerS })},
+	} {
+		current, peak := 0.0, 0.0
+		if n := len(row.values); n > 0 {
+			current = row.values[n-1]
+		}
+		for _, v := range row.values {
+			peak = max(peak, v)
+		}
+		fmt.Fprintf(&b, " %-9s %10.2f  %-*s  max %.2f\n", row.label, current, dashboardHistory, sparkline(row.values), peak)
+	}
+
+	if len(d.errClasses) > 0 {
+		classes := make([]string, 0, len(d.errClasses))
+		for c := range d.errClasses {
+			classes = append(classes, c)
+		}
+		sort.Slice(classes, func(i, j int) bool {
+			if d.errClasses[classes[i]] != d.errClasses[classes[j]] {
+				return d.errClasses[classes[i]] > d.errClasses[classes[j]]
+			}
+			return classes[i] < classes[j]
+		})
+		fmt.Fprintf(&b, "\n Errors by class:\n")
+		for _, c := range classes[:min(len(classes), 5)] {
+			fmt.Fprintf(&b, "   %-28s %d\n", c, d.errClasses[c])
+		}
+	}
+
+	if len(d.logs) > 0 {
+		fmt.Fprintf(&b, "\n Log:\n")
+		for _, line := range d.logs[max(0, len(d.logs)-dashboardLogLines):] {
+			fmt.Fprintf(&b, "   %s\n", line)
+		}
+	}
+	fmt.Fprintf(&b, "\n Ctrl+C stops the run early; the summary follows.\n")
+	return b.String()
+}
+
+// sparkline draws values as bars scaled to the largest of them.
+func sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if peak > 0 {
+			level = int(v / peak * float64(len(sparkBlocks)-1))
+		}
+		runes[i] = sparkBlocks[level]
+	}
+	return string(runes)
+}
+
+// dashboardLogWriter adds the lines written by the log handler to the dashboard.
+type dashboardLogWriter struct{ d *dashboard }
+
+func (w dashboardLogWriter) Write(p []byte) (int, error) {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.d.logs = append(w.d.logs, line)
+	}
+	if len(w.d.logs) > dashboardMaxLogs {
+		w.d.logs = w.d.logs[len(w.d.logs)-dashboardMaxLogs:]
+	}
+	return len(p), nil
+}
+
+// dashboardLogHandler formats records for the dashboard, keeping the level of the logger it replaces.
+type dashboardLogHandler struct {
+	level slog.Handler // Replaced handler, only consulted for Enabled
+	text  slog.Handler
+}
+
+func (h *dashboardLogHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.level.Enabled(ctx, l)
+}
+
+func (h *dashboardLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.text.Handle(ctx, r)
+}
+
+func (h *dashboardLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dashboardLogHandler{level: h.level, text: h.text.WithAttrs(attrs)}
+}
+
+func (h *dashboardLogHandler) WithGroup(name string) slog.Handler {
+	return &dashboardLogHandler{level: h.level, text: h.text.WithGroup(name)}
+}
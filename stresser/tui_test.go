@@ -0,0 +1,46 @@
+package stresser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 1, 2, 4}); got != "▁▂▄█" {
+		t.Errorf("sparkline = %q, want %q", got, "▁▂▄█")
+	}
+	if got := sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Errorf("sparkline of zeros = %q, want %q", got, "▁▁")
+	}
+	if got := sparkline(nil); got != "" {
+		t.Errorf("sparkline of nothing = %q, want empty", got)
+	}
+}
+
+func TestDashboardRender(t *testing.T) {
+	start := time.Now()
+	cfg := &Config{RunID: "run-1", OperationType: "read", Concurrency: 4}
+	d := newDashboard(nil, cfg, start, time.Minute, nil, nil)
+
+	for i := 0; i < 9; i++ {
+		d.add(Result{Operation: "GET", TTLB: 10 * time.Millisecond, BytesDownloaded: 1024 * 1024})
+	}
+	d.add(Result{Operation: "GET", Error: "operation error S3: GetObject, StatusCode: 503, SlowDown"})
+	d.sample(start.Add(time.Second))
+
+	if len(d.history) != 1 {
+		t.Fatalf("history has %d samples, want 1", len(d.history))
+	}
+	s := d.history[0]
+	if s.requestsPerS != 10 || s.mibPerS != 9 || s.errorsPerS != 1 || s.p50Ms != 10 {
+		t.Errorf("sample = %+v", s)
+	}
+
+	out := d.render(start.Add(time.Second))
+	for _, want := range []string{"run-1", "Requests: 10", "Errors: 1", "GET 10", "req/s", "█", ClassifyError("StatusCode: 503, SlowDown")} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dashboard is missing %q:\n%s", want, out)
+		}
+	}
+}
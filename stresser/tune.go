@@ -0,0 +1,173 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// tuneInterval is how often the latency tuner measures P99 and adjusts the workers.
+	tuneInterval = 5 * time.Second
+	// tuneMinSamples is the number of successful requests an interval needs for its P99
+	// to count; shorter intervals are extended until they have them.
+	tuneMinSamples = 20
+)
+
+// TuningStep is one measurement of the latency tuner.
+type TuningStep struct {
+	OffsetSeconds  float64 `json:"offsetSeconds" yaml:"offsetSeconds"` // End of the interval, since the start of the run
+	Workers        int     `json:"workers" yaml:"workers"`             // Active workers during the interval
+	RequestsPerSec float64 `json:"requestsPerSec" yaml:"requestsPerSec"`
+	MiBPerSec      float64 `json:"mibPerSec" yaml:"mibPerSec"`
+	P99Ms          float64 `json:"p99Ms" yaml:"p99Ms"`
+}
+
+// TuningReport holds the outcome of -target-p99: the highest throughput of an interval
+// that kept P99 within the target, and every measurement made on the way.
+type TuningReport struct {
+	TargetP99Ms    float64      `json:"targetP99Ms" yaml:"targetP99Ms"`
+	Found          bool         `json:"found" yaml:"found"` // False if no interval met the target
+	Workers        int          `json:"workers" yaml:"workers"`
+	RequestsPerSec float64      `json:"requestsPerSec" yaml:"requestsPerSec"`
+	MiBPerSec      float64      `json:"mibPerSec" yaml:"mibPerSec"`
+	P99Ms          float64      `json:"p99Ms" yaml:"p99Ms"`
+	Steps          []TuningStep `json:"steps" yaml:"steps"`
+}
+
+// latencyTuner searches for the highest worker count that keeps P99 latency within a
+// target. It starts with one worker and doubles the count while the target is met; after
+// the first miss it backs off by a quarter on a miss and adds 10% on a hit, so the count
+// settles just below the point where latency breaks down.
+type latencyTuner struct {
+	limit      *workerLimit
+	maxWorkers int
+	target     time.Duration
+	start      time.Time
+
+	mu        sync.Mutex
+	window    *Histogram // TTLB of the successful requests of the current interval
+	requests  int64
+	bytes     int64
+	last      time.Time // Start of the current interval
+	slowStart bool
+	report    TuningReport
+}
+
+// newLatencyTuner sets the limit to one worker and returns a tuner for a run started at start.
+func newLatencyTuner(limit *workerLimit, maxWorkers int, target time.Duration, start time.Time) *latencyTuner {
+	limit.Set(1)
+	return &latencyTuner{
+		limit:      limit,
+		maxWorkers: maxWorkers,
+		target:     target,
+		start:      start,
+		window:     NewHistogram(),
+		last:       start,
+		slowStart:  true,
+		report:     TuningReport{TargetP99Ms: ms(target)},
+	}
+}
+
+// add records a completed operation.
+func (t *latencyTuner) add(r Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+	if r.Error == "" {
+		t.window.Record(r.TTLB)
+		t.bytes += r.BytesDownloaded + r.BytesUploaded
+	}
+}
+
+// run adjusts the workers every interval until ctx is done.
+func (t *latencyTuner) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.step(now)
+		}
+	}
+}
+
+// step measures the interval ending at now and moves the worker limit.
+func (t *latencyTuner) step(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.window.Count() < tuneMinSamples {
+		return // Keep measuring until P99 means something
+	}
+	secs := now.Sub(t.last).Seconds()
+	p99 := t.window.Percentile(99)
+	current := t.limit.Get()
+	step := TuningStep{
+		OffsetSeconds:  now.Sub(t.start).Seconds(),
+		Workers:        current,
+		RequestsPerSec: float64(t.requests) / secs,
+		MiBPerSec:      float64(t.bytes) / (1024 * 1024) / secs,
+		P99Ms:          ms(p99),
+	}
+	t.report.Steps = append(t.report.Steps, step)
+	t.window, t.requests, t.bytes, t.last = NewHistogram(), 0, 0, now
+
+	next := current
+	if p99 <= t.target {
+		if !t.report.Found || step.RequestsPerSec > t.report.RequestsPerSec {
+			t.report.Found = true
+			t.report.Workers, t.report.RequestsPerSec, t.report.MiBPerSec, t.report.P99Ms = step.Workers, step.RequestsPerSec, step.MiBPerSec, step.P99Ms
+		}
+		if t.slowStart {
+			next = min(t.maxWorkers, current*2)
+		} else {
+			next = min(t.maxWorkers, current+max(1, current/10))
+		}
+	} else {
+		t.slowStart = false
+		next = max(1, current*3/4)
+	}
+	if next != current {
+		t.limit.Set(next)
+		slog.Info("Tuned active workers", "from", current, "to", next, "p99", p99.Round(time.Microsecond), "target", t.target)
+	}
+}
+
+// Report returns the outcome so far.
+func (t *latencyTuner) Report() TuningReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := t.report
+	report.Steps = append([]TuningStep(nil), t.report.Steps...)
+	return report
+}
+
+// printTuningSummary prints the outcome of -target-p99 as part of PrintSummary.
+func (s *Stats) printTuningSummary(w io.Writer) {
+	tr := s.Tuning
+	if tr == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nLatency Target Tuning (P99 <= %.2f ms):\n", tr.TargetP99Ms)
+	if tr.Found {
+		fmt.Fprintf(w, "  Max Sustainable: %.2f req/s, %.2f MiB/s at %d workers (P99 %.2f ms)\n",
+			tr.RequestsPerSec, tr.MiBPerSec, tr.Workers, tr.P99Ms)
+	} else if len(tr.Steps) > 0 {
+		fmt.Fprintf(w, "  Target not met, not even with 1 worker\n")
+	} else {
+		fmt.Fprintf(w, "  Too few requests to measure P99; run longer\n")
+	}
+	for _, st := range tr.Steps {
+		verdict := ""
+		if st.P99Ms > tr.TargetP99Ms {
+			verdict = " (over target)"
+		}
+		fmt.Fprintf(w, "  +%-8s %5d workers %10.2f req/s %9.2f MiB/s  P99 %8.2f ms%s\n",
+			time.Duration(st.OffsetSeconds*float64(time.Second)).Round(time.Second), st.Workers, st.RequestsPerSec, st.MiBPerSec, st.P99Ms, verdict)
+	}
+}
@@ -0,0 +1,101 @@
+package stresser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyTuner(t *testing.T) {
+	start := time.Now()
+	limit := newWorkerLimit(16)
+	tuner := newLatencyTuner(limit, 16, 50*time.Millisecond, start)
+	if limit.Get() != 1 {
+		t.Fatalf("Expected the tuner to start with 1 worker, got %d", limit.Get())
+	}
+	feed := func(n int, ttlb time.Duration) {
+		for range n {
+			tuner.add(Result{Operation: "GET", BytesDownloaded: 1024 * 1024, TTLB: ttlb})
+		}
+	}
+	now := start
+	step := func() int {
+		now = now.Add(time.Second)
+		tuner.step(now)
+		return limit.Get()
+	}
+
+	feed(10, 10*time.Millisecond)
+	if step() != 1 || len(tuner.Report().Steps) != 0 {
+		t.Error("Expected an interval with too few samples to be extended")
+	}
+	feed(10, 10*time.Millisecond)
+	if got := step(); got != 2 {
+		t.Errorf("Expected slow start to double the workers, got %d", got)
+	}
+	feed(100, 10*time.Millisecond)
+	if got := step(); got != 4 {
+		t.Errorf("Expected slow start to double the workers, got %d", got)
+	}
+	feed(50, 200*time.Millisecond)
+	if got := step(); got != 3 {
+		t.Errorf("Expected a miss to back off by a quarter, got %d", got)
+	}
+	feed(30, 10*time.Millisecond)
+	if got := step(); got != 4 {
+		t.Errorf("Expected a hit after the first miss to add one worker, got %d", got)
+	}
+
+	report := tuner.Report()
+	if !report.Found || report.Workers != 2 || report.RequestsPerSec != 100 || len(report.Steps) != 4 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	stats := NewStats()
+	stats.Tuning = &report
+	var buf bytes.Buffer
+	stats.printTuningSummary(&buf)
+	if !strings.Contains(buf.String(), "Max Sustainable: 100.00 req/s") || !strings.Contains(buf.String(), "(over target)") {
+		t.Errorf("Unexpected tuning summary:\n%s", buf.String())
+	}
+}
+
+func TestLatencyTunerCap(t *testing.T) {
+	start := time.Now()
+	limit := newWorkerLimit(3)
+	tuner := newLatencyTuner(limit, 3, time.Second, start)
+	for i := 1; i <= 3; i++ {
+		for range tuneMinSamples {
+			tuner.add(Result{Operation: "PUT", TTLB: time.Millisecond})
+		}
+		tuner.step(start.Add(time.Duration(i) * time.Second))
+	}
+	if limit.Get() != 3 {
+		t.Errorf("Expected the workers to be capped at 3, got %d", limit.Get())
+	}
+}
+
+func TestValidateTargetP99(t *testing.T) {
+	base := Config{Endpoint: "http://localhost:9000", Region: "us-east-1", Bucket: "test-bucket", Duration: "5m", Concurrency: 64,
+		ManifestPath: "manifest.txt", OutputFile: "results.csv", OperationType: "read", TargetP99: "50ms"}
+
+	cfg := base
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for name, mutate := range map[string]func(*Config){
+		"invalid":     func(c *Config) { c.TargetP99 = "fast" },
+		"negative":    func(c *Config) { c.TargetP99 = "-5ms" },
+		"cpu budget":  func(c *Config) { c.CPUBudget = 80 },
+		"open loop":   func(c *Config) { c.ArrivalRate = 100 },
+		"file count":  func(c *Config) { c.OperationType = "write"; c.FileCount = 100 },
+		"concurrency": func(c *Config) { c.ConcurrencySweep = "1,2" },
+	} {
+		cfg := base
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ToolName identifies this tool in the User-Agent header.
+const ToolName = "ostresser"
+
+const modulePath = "github.com/perbu/ostresser"
+
+// ToolVersion returns the module version of the stresser package, "(devel)" for local builds.
+func ToolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	// Embedded as a library
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+// NewRunID returns an identifier for a test run: the UTC start time plus a random suffix,
+// e.g. "20250101T120000-3fa9". It sorts by time and is unique enough to tell concurrent runs apart.
+func NewRunID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().UTC().Format("20060102T150405"), rand.Intn(0x10000))
+}
+
+// UserAgentString returns the User-Agent prefix sent with every request:
+// "ostresser/<version> run/<run id>" followed by the optional custom suffix.
+func (c *Config) UserAgentString() string {
+	ua := fmt.Sprintf("%s/%s run/%s", ToolName, ToolVersion(), c.RunID)
+	if suffix := strings.TrimSpace(c.UserAgent); suffix != "" {
+		ua += " " + suffix
+	}
+	return ua
+}
+
+// userAgentHook puts ua in front of the User-Agent the SDK built, so stress traffic is easy
+// to filter in server logs while the SDK identification is kept.
+func userAgentHook(ua string) RequestHook {
+	return func(ctx context.Context, req *http.Request) error {
+		value := ua
+		if sdk := req.Header.Get("User-Agent"); sdk != "" {
+			value += " " + sdk
+		}
+		req.Header.Set("User-Agent", value)
+		return nil
+	}
+}
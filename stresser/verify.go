@@ -0,0 +1,146 @@
+package stresser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// verifyMetadataKey is the user metadata entry (x-amz-meta-ostresser-sha256) that holds the
+// SHA-256 of the object body when it was written with -verify.
+const verifyMetadataKey = "ostresser-sha256"
+
+// Integrity outcomes of a verified GET, stored in Result.Integrity.
+const (
+	IntegrityOK        = "ok"        // Body matched the checksum written with the object
+	IntegrityCorrupt   = "corrupt"   // Body did not match the checksum
+	IntegrityUnchecked = "unchecked" // Object carries no checksum, e.g. it was not written with -verify
+)
+
+// ErrCorruptObject is the error class of GETs whose body did not match the checksum
+// stored when the object was written.
+var ErrCorruptObject = errors.New("data integrity violation")
+
+// verifyMetadata returns the object metadata that lets a later GET verify body.
+// The body is read once to hash it and rewound.
+func verifyMetadata(body io.ReadSeeker) (map[string]string, error) {
+	sum, err := bodySHA256(body)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{verifyMetadataKey: sum}, nil
+}
+
+// bodySHA256 returns the hex SHA-256 of body, which is read once and rewound.
+func bodySHA256(body io.ReadSeeker) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// bodyHasher returns the writer a GET body is copied to: a SHA-256 hash when verifying,
+// io.Discard otherwise.
+func bodyHasher(verify bool) (io.Writer, hash.Hash) {
+	if !verify {
+		return io.Discard, nil
+	}
+	h := sha256.New()
+	return h, h
+}
+
+// checkIntegrity compares the hash of a fully read GET body with the checksum in the
+// object metadata and records the outcome in result.
+func checkIntegrity(result *Result, metadata map[string]string, h hash.Hash) {
+	expected := metadata[verifyMetadataKey]
+	if expected == "" {
+		result.Integrity = IntegrityUnchecked
+		return
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != expected {
+		result.Integrity = IntegrityCorrupt
+		result.Error = fmt.Sprintf("%v: sha256 %s, expected %s (%d bytes read)", ErrCorruptObject, got, expected, result.BytesDownloaded)
+		return
+	}
+	result.Integrity = IntegrityOK
+}
+
+// checkExpected compares a successful GET with the size and ETag the manifest expects of
+// the object, catching truncated reads and reads of the wrong object, and records the
+// outcome in result. Mismatches are integrity errors, like bodies that fail -verify.
+func checkExpected(result *Result, e ManifestEntry) {
+	if result.Error != "" || (e.Size == 0 && e.ETag == "") {
+		return
+	}
+	if size := result.objectBytes(); e.Size > 0 && size != e.Size {
+		result.Integrity = IntegrityCorrupt
+		result.Error = fmt.Sprintf("%v: %d bytes read, expected %d", ErrCorruptObject, size, e.Size)
+		return
+	}
+	checked := e.Size > 0
+	if e.ETag != "" && result.ETag != "" {
+		// ETags are quoted in responses, MD5 sums in manifests usually aren't
+		if got, expected := strings.Trim(result.ETag, `"`), strings.Trim(e.ETag, `"`); !strings.EqualFold(got, expected) {
+			result.Integrity = IntegrityCorrupt
+			result.Error = fmt.Sprintf("%v: ETag %s, expected %s (%d bytes read)", ErrCorruptObject, got, expected, result.BytesDownloaded)
+			return
+		}
+		checked = true
+	}
+	if checked && (result.Integrity == "" || result.Integrity == IntegrityUnchecked) {
+		result.Integrity = IntegrityOK
+	}
+}
+
+// IntegrityReport summarizes verified GETs.
+type IntegrityReport struct {
+	Verified  int64 `json:"verified" yaml:"verified"`   // GETs whose body matched the stored checksum or the manifest
+	Corrupt   int64 `json:"corrupt" yaml:"corrupt"`     // GETs whose body did not match, also counted as errors
+	Unchecked int64 `json:"unchecked" yaml:"unchecked"` // GETs of objects without a stored checksum
+}
+
+// addIntegrityResult counts verification outcomes. Called from AddResult.
+func (s *Stats) addIntegrityResult(r Result) {
+	switch r.Integrity {
+	case IntegrityOK:
+		s.integrity.Verified++
+	case IntegrityCorrupt:
+		s.integrity.Corrupt++
+	case IntegrityUnchecked:
+		s.integrity.Unchecked++
+	}
+}
+
+// Integrity returns the verification counts, or nil if no GET was verified.
+func (s *Stats) Integrity() *IntegrityReport {
+	if s.integrity == (IntegrityReport{}) {
+		return nil
+	}
+	report := s.integrity
+	return &report
+}
+
+// printIntegritySummary prints verification outcomes as part of PrintSummary.
+func (s *Stats) printIntegritySummary(w io.Writer) {
+	ir := s.Integrity()
+	if ir == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nData Integrity:\n")
+	fmt.Fprintf(w, "  Verified GETs:  %d\n", ir.Verified)
+	fmt.Fprintf(w, "  Corrupt GETs:   %d\n", ir.Corrupt)
+	if ir.Unchecked > 0 {
+		fmt.Fprintf(w, "  Unchecked GETs: %d (objects without a checksum, write them with -verify)\n", ir.Unchecked)
+	}
+	if ir.Corrupt > 0 {
+		fmt.Fprintf(w, "  WARNING: %d GETs returned data that differs from what was written\n", ir.Corrupt)
+	}
+}
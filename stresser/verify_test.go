@@ -0,0 +1,112 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// memS3Client stores objects in memory. corrupt flips a byte of every body it returns.
+type memS3Client struct {
+	stubS3Client
+	bodies   map[string][]byte
+	metadata map[string]map[string]string
+	corrupt  bool
+}
+
+func (c *memS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.bodies[*params.Key] = data
+	c.metadata[*params.Key] = params.Metadata
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *memS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data := bytes.Clone(c.bodies[*params.Key])
+	if c.corrupt && len(data) > 0 {
+		data[len(data)/2] ^= 0xff
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data)), Metadata: c.metadata[*params.Key]}, nil
+}
+
+func TestVerifiedGet(t *testing.T) {
+	client := &memS3Client{bodies: map[string][]byte{}, metadata: map[string]map[string]string{}}
+	ctx := context.Background()
+	data := []byte(strings.Repeat("payload", 1000))
+
+	if r := performPutOperation(ctx, client, "bucket", "checked", bytes.NewReader(data), true, putAttributes{}); r.Error != "" {
+		t.Fatalf("PUT failed: %s", r.Error)
+	}
+	if r := performPutOperation(ctx, client, "bucket", "plain", bytes.NewReader(data), false, putAttributes{}); r.Error != "" {
+		t.Fatalf("PUT failed: %s", r.Error)
+	}
+
+	stats := NewStats()
+	ok := performGetOperation(ctx, client, "bucket", "checked", true)
+	if ok.Error != "" || ok.Integrity != IntegrityOK {
+		t.Errorf("Expected a verified GET, got integrity %q, error %q", ok.Integrity, ok.Error)
+	}
+	stats.AddResult(ok)
+
+	unchecked := performGetOperation(ctx, client, "bucket", "plain", true)
+	if unchecked.Error != "" || unchecked.Integrity != IntegrityUnchecked {
+		t.Errorf("Expected an unchecked GET, got integrity %q, error %q", unchecked.Integrity, unchecked.Error)
+	}
+	stats.AddResult(unchecked)
+
+	client.corrupt = true
+	corrupt := performGetOperation(ctx, client, "bucket", "checked", true)
+	if corrupt.Integrity != IntegrityCorrupt || !strings.Contains(corrupt.Error, ErrCorruptObject.Error()) {
+		t.Errorf("Expected a corrupt GET, got integrity %q, error %q", corrupt.Integrity, corrupt.Error)
+	}
+	stats.AddResult(corrupt)
+
+	if plain := performGetOperation(ctx, client, "bucket", "checked", false); plain.Error != "" || plain.Integrity != "" {
+		t.Errorf("GET without verify should not check data, got integrity %q, error %q", plain.Integrity, plain.Error)
+	}
+
+	report := stats.Integrity()
+	if report == nil || *report != (IntegrityReport{Verified: 1, Corrupt: 1, Unchecked: 1}) {
+		t.Errorf("Unexpected integrity report: %+v", report)
+	}
+	if stats.TotalErrors != 1 {
+		t.Errorf("Corrupt GETs should count as errors, got %d errors", stats.TotalErrors)
+	}
+	if NewStats().Integrity() != nil {
+		t.Error("Expected no integrity report without verified GETs")
+	}
+}
+
+func TestCheckExpected(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		result    Result
+		expect    ManifestEntry
+		integrity string
+	}{
+		{"match", Result{BytesDownloaded: 1024, ETag: `"D41D8CD98F00B204E9800998ECF8427E"`}, ManifestEntry{Size: 1024, ETag: "d41d8cd98f00b204e9800998ecf8427e"}, IntegrityOK},
+		{"size only", Result{BytesDownloaded: 1024}, ManifestEntry{Size: 1024}, IntegrityOK},
+		{"truncated", Result{BytesDownloaded: 512, ETag: `"abc"`}, ManifestEntry{Size: 1024, ETag: "abc"}, IntegrityCorrupt},
+		{"wrong object", Result{BytesDownloaded: 1024, ETag: `"def"`}, ManifestEntry{Size: 1024, ETag: `"abc"`}, IntegrityCorrupt},
+		{"no ETag returned", Result{BytesDownloaded: 1024}, ManifestEntry{ETag: "abc"}, ""},
+		{"nothing expected", Result{BytesDownloaded: 1024}, ManifestEntry{Key: "k"}, ""},
+		{"failed GET", Result{Error: "timeout"}, ManifestEntry{Size: 1024}, ""},
+		{"verify unchecked", Result{BytesDownloaded: 1024, Integrity: IntegrityUnchecked}, ManifestEntry{Size: 1024}, IntegrityOK},
+	} {
+		r := tt.result
+		checkExpected(&r, tt.expect)
+		if r.Integrity != tt.integrity {
+			t.Errorf("%s: expected integrity %q, got %q", tt.name, tt.integrity, r.Integrity)
+		}
+		if failed := r.Error != tt.result.Error; failed != (tt.integrity == IntegrityCorrupt) || (failed && !strings.Contains(r.Error, ErrCorruptObject.Error())) {
+			t.Errorf("%s: unexpected error %q", tt.name, r.Error)
+		}
+	}
+}
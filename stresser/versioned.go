@@ -0,0 +1,231 @@
+package stresser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// DefaultVersionMix is the share of each operation in 'versioned' mode when -version-mix is not set.
+	DefaultVersionMix = "put=30,get=60,delete=10"
+	// maxTrackedVersions bounds the versions 'versioned' mode remembers for GETs and DELETEs.
+	// Once reached, new versions replace random old ones.
+	maxTrackedVersions = 100000
+)
+
+// deleteClient is the part of the S3 API 'versioned' mode uses on top of S3ClientAPI.
+type deleteClient interface {
+	S3ClientAPI
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// versionMix holds the relative weights of the PUTs, GETs and DELETEs of 'versioned' mode.
+type versionMix struct {
+	put, get, delete int
+}
+
+// parseVersionMix parses weights such as "put=30,get=60,delete=10". Operations left out get
+// no share; the weights need not add up to 100.
+func parseVersionMix(spec string) (versionMix, error) {
+	if spec == "" {
+		spec = DefaultVersionMix
+	}
+	pairs, err := ParseKeyValues(spec)
+	if err != nil {
+		return versionMix{}, fmt.Errorf("invalid version mix: %w", err)
+	}
+	var m versionMix
+	for op, value := range pairs {
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return versionMix{}, fmt.Errorf("invalid version mix weight %q for %s", value, op)
+		}
+		switch op {
+		case "put":
+			m.put = weight
+		case "get":
+			m.get = weight
+		case "delete":
+			m.delete = weight
+		default:
+			return versionMix{}, fmt.Errorf("invalid version mix operation %q: must be put, get or delete", op)
+		}
+	}
+	if m.put == 0 {
+		return versionMix{}, fmt.Errorf("version mix needs a put weight above 0, versions to read and delete come from PUTs")
+	}
+	return m, nil
+}
+
+// pick returns "put", "get" or "delete" according to the weights.
+func (m versionMix) pick(r *rand.Rand) string {
+	n := r.Intn(m.put + m.get + m.delete)
+	if n < m.put {
+		return "put"
+	}
+	if n < m.put+m.get {
+		return "get"
+	}
+	return "delete"
+}
+
+// versionPool holds the object versions known to exist: those listed in the manifest and
+// those written during the run. Workers read random versions and delete them again.
+type versionPool struct {
+	mu       sync.Mutex
+	versions []ManifestEntry
+}
+
+// newVersionPool returns a pool seeded with the manifest entries that carry a version id.
+func newVersionPool(entries []ManifestEntry) *versionPool {
+	p := &versionPool{}
+	for _, e := range entries {
+		if e.VersionID != "" {
+			p.versions = append(p.versions, e)
+		}
+	}
+	if len(p.versions) > maxTrackedVersions {
+		p.versions = p.versions[:maxTrackedVersions]
+	}
+	return p
+}
+
+// add remembers a written version, replacing a random one once the pool is full.
+func (p *versionPool) add(r *rand.Rand, v ManifestEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.versions) < maxTrackedVersions {
+		p.versions = append(p.versions, v)
+		return
+	}
+	p.versions[r.Intn(len(p.versions))] = v
+}
+
+// pick returns a random version, false if the pool is empty.
+func (p *versionPool) pick(r *rand.Rand) (ManifestEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.versions) == 0 {
+		return ManifestEntry{}, false
+	}
+	return p.versions[r.Intn(len(p.versions))], true
+}
+
+// take removes a random version from the pool and returns it, false if the pool is empty.
+func (p *versionPool) take(r *rand.Rand) (ManifestEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.versions) == 0 {
+		return ManifestEntry{}, false
+	}
+	i := r.Intn(len(p.versions))
+	v := p.versions[i]
+	last := len(p.versions) - 1
+	p.versions[i] = p.versions[last]
+	p.versions = p.versions[:last]
+	return v, true
+}
+
+// size returns the number of versions in the pool.
+func (p *versionPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.versions)
+}
+
+// manifestKeys returns the distinct keys of entries, in manifest order.
+func manifestKeys(entries []ManifestEntry) []string {
+	seen := make(map[string]bool, len(entries))
+	var keys []string
+	for _, e := range entries {
+		if !seen[e.Key] {
+			seen[e.Key] = true
+			keys = append(keys, e.Key)
+		}
+	}
+	return keys
+}
+
+// performVersionedGetOperation reads a specific version of an object.
+func performVersionedGetOperation(ctx context.Context, s3Client S3ClientAPI, bucket string, v ManifestEntry, verify bool) Result {
+	attempt := sendGet(ctx, s3Client, &s3.GetObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(v.Key),
+		VersionId: aws.String(v.VersionID),
+	})
+	result := attempt.finish(attempt.start, verify)
+	result.VersionID = v.VersionID
+	return result
+}
+
+// performDeleteOperation permanently deletes a specific version of an object, or the object
+// itself for entries without a version id, and measures how long it took.
+func performDeleteOperation(ctx context.Context, s3Client S3ClientAPI, bucket string, v ManifestEntry) Result {
+	result := Result{
+		Timestamp: time.Now(),
+		Operation: "DELETE",
+		ObjectKey: v.Key,
+		VersionID: v.VersionID,
+		TTFB:      -1, // Not applicable, there is no body
+		TTLB:      -1,
+	}
+	client, ok := s3Client.(deleteClient)
+	if !ok {
+		result.Error = fmt.Sprintf("%T does not support DeleteObject", s3Client)
+		return result
+	}
+
+	reqStartTime := time.Now()
+	input := &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(v.Key)}
+	if v.VersionID != "" {
+		input.VersionId = aws.String(v.VersionID)
+	}
+	resp, err := client.DeleteObject(traceConnection(ctx, &result, reqStartTime), input)
+	if err != nil {
+		result.Error = err.Error()
+		recordErrorRequestID(&result, err)
+		return result
+	}
+	recordResponseMetadata(&result, resp.ResultMetadata)
+	result.TTLB = time.Since(reqStartTime)
+	return result
+}
+
+// printDeleteSummary prints DeleteObject latency as part of PrintSummary.
+func (s *Stats) printDeleteSummary(w io.Writer) {
+	if s.TotalDeletes == 0 {
+		return
+	}
+	h := s.DeleteTTLBHist
+	fmt.Fprintf(w, "\nDELETE Operations (%d total):\n", s.TotalDeletes)
+	fmt.Fprintf(w, "  Success:        %d\n", h.Count())
+	if h.Count() == 0 {
+		fmt.Fprintln(w, "  No successful DELETEs to calculate latency.")
+		return
+	}
+	fmt.Fprintf(w, "  Latency (ms): |   Min  |   Avg  |   P50  |   P90  |   P99  |  P99.9 |   Max  \n")
+	fmt.Fprintf(w, "  --------------|--------|--------|--------|--------|--------|--------|--------\n")
+	fmt.Fprintf(w, "  TTLB (total)  |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f |%7.2f \n",
+		ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Percentile(99.9)), ms(h.Max()))
+}
+
+// deleteSummary returns the DELETE figures for Summary, nil if nothing was deleted.
+func (s *Stats) deleteSummary() *OperationSummary {
+	if s.TotalDeletes == 0 {
+		return nil
+	}
+	h := s.DeleteTTLBHist
+	sum := &OperationSummary{Total: s.TotalDeletes, Success: h.Count()}
+	if h.Count() > 0 {
+		sum.TTLB = &LatencySummary{ms(h.Min()), ms(h.Mean()), ms(h.Percentile(50)), ms(h.Percentile(90)), ms(h.Percentile(99)), ms(h.Max()), ms(h.Percentile(99.9))}
+	}
+	return sum
+}
@@ -0,0 +1,115 @@
+package stresser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// versionedS3Client serves object versions and records version deletes.
+type versionedS3Client struct {
+	stubS3Client
+	gotVersion string
+	deleted    []string
+}
+
+func (c *versionedS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	c.gotVersion = aws.ToString(params.VersionId)
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader("version body"))}, nil
+}
+
+func (c *versionedS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	return &s3.PutObjectOutput{VersionId: aws.String("v-new")}, nil
+}
+
+func (c *versionedS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	c.deleted = append(c.deleted, aws.ToString(params.Key)+"@"+aws.ToString(params.VersionId))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestParseVersionMix(t *testing.T) {
+	m, err := parseVersionMix("")
+	if err != nil || m != (versionMix{put: 30, get: 60, delete: 10}) {
+		t.Fatalf("Unexpected default mix %+v (%v)", m, err)
+	}
+	m, err = parseVersionMix("put=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for range 10 {
+		if op := m.pick(r); op != "put" {
+			t.Fatalf("Expected only PUTs, got %s", op)
+		}
+	}
+	for _, spec := range []string{"get=1", "put=1,list=1", "put=-1", "put=x"} {
+		if _, err := parseVersionMix(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestVersionPool(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pool := newVersionPool([]ManifestEntry{{Key: "a", VersionID: "v1"}, {Key: "b"}})
+	if pool.size() != 1 {
+		t.Fatalf("Expected only the versioned entry in the pool, got %d", pool.size())
+	}
+	pool.add(r, ManifestEntry{Key: "a", VersionID: "v2"})
+	if v, ok := pool.pick(r); !ok || v.Key != "a" {
+		t.Errorf("Unexpected pick %+v", v)
+	}
+	for range 2 {
+		if _, ok := pool.take(r); !ok {
+			t.Fatal("Expected a version to take")
+		}
+	}
+	if _, ok := pool.take(r); ok || pool.size() != 0 {
+		t.Error("Expected the pool to be empty")
+	}
+
+	if keys := manifestKeys([]ManifestEntry{{Key: "a", VersionID: "v1"}, {Key: "b"}, {Key: "a", VersionID: "v2"}}); strings.Join(keys, ",") != "a,b" {
+		t.Errorf("Unexpected distinct keys %v", keys)
+	}
+}
+
+func TestVersionedOperations(t *testing.T) {
+	ctx := context.Background()
+	client := &versionedS3Client{}
+	v := ManifestEntry{Key: "a", VersionID: "v1"}
+
+	get := performVersionedGetOperation(ctx, client, "bucket", v, false)
+	if get.Error != "" || get.VersionID != "v1" || client.gotVersion != "v1" || get.BytesDownloaded != int64(len("version body")) {
+		t.Fatalf("Unexpected GET result %+v (requested version %q)", get, client.gotVersion)
+	}
+	del := performDeleteOperation(ctx, client, "bucket", v)
+	if del.Error != "" || del.Operation != "DELETE" || del.VersionID != "v1" || len(client.deleted) != 1 || client.deleted[0] != "a@v1" {
+		t.Fatalf("Unexpected DELETE result %+v, deletes %v", del, client.deleted)
+	}
+	if r := performDeleteOperation(ctx, &stubS3Client{}, "bucket", v); r.Error == "" {
+		t.Error("Expected an error from a client without DeleteObject")
+	}
+	put := performPutOperation(ctx, client, "bucket", "a", bytes.NewReader([]byte("x")), false, putAttributes{})
+	if put.VersionID != "v-new" {
+		t.Errorf("Expected the PUT to record the new version, got %q", put.VersionID)
+	}
+
+	stats := NewStats()
+	stats.AddResult(del)
+	stats.Calculate(del.Timestamp, del.Timestamp.Add(time.Second))
+	var buf bytes.Buffer
+	stats.PrintSummary(&buf)
+	if !strings.Contains(buf.String(), "DELETE Operations (1 total)") {
+		t.Errorf("Summary missing delete section:\n%s", buf.String())
+	}
+	if sum := stats.Summary(); sum.Delete == nil || sum.Delete.Success != 1 {
+		t.Errorf("Unexpected delete summary %+v", sum.Delete)
+	}
+}
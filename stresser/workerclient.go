@@ -0,0 +1,75 @@
+package stresser
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// validateClientPerWorker checks -client-per-worker. Called from Validate.
+func (c *Config) validateClientPerWorker() error {
+	if !c.ClientPerWorker {
+		return nil
+	}
+	if c.Backend == BackendGCS {
+		return fmt.Errorf("the gcs backend (-backend) does not support -client-per-worker")
+	}
+	if c.SecondaryEndpoint != "" {
+		return fmt.Errorf("-client-per-worker cannot be combined with failover (-secondary-endpoint)")
+	}
+	if c.OperationType == "replay" {
+		return fmt.Errorf("'replay' mode has no workers of its own and cannot be combined with -client-per-worker")
+	}
+	return nil
+}
+
+// forWorker returns the endpoints a worker sends its requests to. With -client-per-worker
+// every endpoint gets an S3 client whose HTTP transport belongs to the worker alone, so
+// workers neither share connections nor contend for the locks of a shared pool; otherwise
+// it returns p itself. release closes the idle connections of the worker's transports once
+// it is done, since nothing else will reuse them.
+func (p *endpointPicker) forWorker(cfg *Config) (picker *endpointPicker, release func()) {
+	if !cfg.ClientPerWorker {
+		return p, func() {}
+	}
+	targets := make([]*endpointTarget, len(p.targets))
+	var httpClients []*http.Client
+	for i, t := range p.targets {
+		sdk := s3.New(t.sdk.Options(), func(o *s3.Options) {
+			if hc, ok := o.HTTPClient.(*http.Client); ok {
+				hc = isolatedHTTPClient(hc)
+				httpClients = append(httpClients, hc)
+				o.HTTPClient = hc
+			}
+		})
+		targets[i] = &endpointTarget{url: t.url, weight: t.weight, sdk: sdk, client: workloadClient(sdk, cfg)}
+	}
+	selection := EndpointSelectRoundRobin
+	if p.random {
+		selection = EndpointSelectRandom
+	}
+	return newEndpointPicker(targets, selection), func() {
+		for _, hc := range httpClients {
+			hc.CloseIdleConnections()
+		}
+	}
+}
+
+// isolatedHTTPClient returns a copy of client with a transport of its own, configured like
+// the original. The dialer is shared, so connections are still counted, throttled by
+// -bandwidth-limit and spread by -dns-refresh across all workers.
+func isolatedHTTPClient(client *http.Client) *http.Client {
+	isolated := *client
+	switch t := client.Transport.(type) {
+	case *http.Transport:
+		isolated.Transport = t.Clone()
+	case *recyclingTransport:
+		recycling := *t
+		if base, ok := t.base.(*http.Transport); ok {
+			recycling.base = base.Clone()
+		}
+		isolated.Transport = &recycling
+	}
+	return &isolated
+}
@@ -0,0 +1,63 @@
+package stresser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestValidateClientPerWorker(t *testing.T) {
+	if err := (&Config{ClientPerWorker: true, OperationType: "read"}).validateClientPerWorker(); err != nil {
+		t.Errorf("validateClientPerWorker failed: %v", err)
+	}
+	for _, c := range []*Config{
+		{ClientPerWorker: true, Backend: BackendGCS},
+		{ClientPerWorker: true, SecondaryEndpoint: "http://secondary:9000"},
+		{ClientPerWorker: true, OperationType: "replay"},
+	} {
+		if err := c.validateClientPerWorker(); err == nil {
+			t.Errorf("Expected an error for %+v", c)
+		}
+	}
+}
+
+func TestClientPerWorker(t *testing.T) {
+	t.Setenv("AWS_CA_BUNDLE", "") // Not supported together with a custom *http.Client
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	cfg := &Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "bucket", AccessKey: "key", SecretKey: "secret", RunID: "test", ConnMaxRequests: 10}
+	client, err := NewS3Client(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	shared := singleEndpoint(client)
+	shared.targets[0].sdk = client
+
+	if p, release := shared.forWorker(cfg); p != shared {
+		t.Error("Expected the shared client without -client-per-worker")
+	} else {
+		release()
+	}
+
+	cfg.ClientPerWorker = true
+	a, releaseA := shared.forWorker(cfg)
+	defer releaseA()
+	b, releaseB := shared.forWorker(cfg)
+	defer releaseB()
+	transport := func(p *endpointPicker) http.RoundTripper {
+		return p.targets[0].client.(*s3.Client).Options().HTTPClient.(*http.Client).Transport.(*recyclingTransport).base
+	}
+	if transport(a) == transport(b) || transport(a) == transport(shared) {
+		t.Error("Expected every worker to have a transport of its own")
+	}
+	for _, p := range []*endpointPicker{a, b} {
+		if get := performGetOperation(context.Background(), p.pick(nil).client, cfg.Bucket, "key", false); get.Error != "" || get.BytesDownloaded != 4 {
+			t.Errorf("GET through a worker client failed: %+v", get)
+		}
+	}
+}